@@ -0,0 +1,169 @@
+// Command sentinelctl is the operator CLI.
+//
+//	sentinelctl top [flags]
+//	sentinelctl backfill-sources [flags]
+//
+// top is a live dashboard of the execution queue, worker activity,
+// per-language throughput and recent failures, read from the metrics and
+// admin APIs.
+//
+// backfill-sources moves the sources of jobs created before sources were
+// content-addressed into the shared source store, in batches, connecting
+// to Postgres directly.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/monitor"
+)
+
+const usage = `Usage: sentinelctl <command> [flags]
+
+Commands:
+  top               Live dashboard of the queue, workers, throughput and failures
+  backfill-sources  Move older jobs' sources into the content-addressed store
+`
+
+// Terminal control sequences used by top.
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "top":
+		if err := top(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "sentinelctl top:", err)
+			os.Exit(1)
+		}
+	case "backfill-sources":
+		if err := backfillSources(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "sentinelctl backfill-sources:", err)
+			os.Exit(1)
+		}
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "sentinelctl: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+func top(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	apiURL := fs.String("api", envOr("SENTINEL_API_URL", "http://localhost:8080"), "API base URL")
+	workers := fs.String("workers", envOr("SENTINEL_WORKER_METRICS", "http://localhost:9090/metrics"), "comma-separated worker metrics URLs")
+	token := fs.String("token", os.Getenv("SENTINEL_ADMIN_TOKEN"), "admin token, to list recent failures")
+	interval := fs.Duration("interval", 2*time.Second, "refresh interval")
+	window := fs.Duration("window", time.Minute, "span rates are measured over")
+	once := fs.Bool("once", false, "print one frame and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("-interval must be positive")
+	}
+
+	var workerURLs []string
+	for _, u := range strings.Split(*workers, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			workerURLs = append(workerURLs, u)
+		}
+	}
+	source := monitor.NewSource(*apiURL, workerURLs, *token)
+	model := monitor.NewModel(*window)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		model.Update(source.Fetch(ctx))
+		_, err := io.WriteString(out, model.View())
+		return err
+	}
+
+	fmt.Fprint(out, hideCursor)
+	defer fmt.Fprint(out, showCursor)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		model.Update(source.Fetch(ctx))
+		fmt.Fprint(out, clearScreen+model.View()+"\nCtrl-C to quit")
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// backfillSources calls backfill_source_blobs until it has nothing left to
+// move. Each batch commits on its own, so an interrupted run resumes where
+// it stopped.
+func backfillSources(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("backfill-sources", flag.ExitOnError)
+	dbURL := fs.String("database", os.Getenv("DATABASE_URL"), "Postgres connection URL")
+	batch := fs.Int("batch", 1000, "jobs moved per transaction")
+	pause := fs.Duration("pause", 0, "wait between batches, to spare a busy database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dbURL == "" {
+		return fmt.Errorf("-database or DATABASE_URL is required")
+	}
+	if *batch <= 0 {
+		return fmt.Errorf("-batch must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	conn, err := pgx.Connect(ctx, *dbURL)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	total := 0
+	for {
+		var moved int
+		if err := conn.QueryRow(ctx, `SELECT backfill_source_blobs($1)`, *batch).Scan(&moved); err != nil {
+			return fmt.Errorf("after %d jobs: %w", total, err)
+		}
+		total += moved
+		if moved == 0 {
+			fmt.Fprintf(out, "done: moved the sources of %d jobs\n", total)
+			return nil
+		}
+		fmt.Fprintf(out, "moved %d jobs\n", total)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("interrupted after %d jobs", total)
+		case <-time.After(*pause):
+		}
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
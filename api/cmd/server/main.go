@@ -2,26 +2,46 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
+	"github.com/Harsh-BH/Sentinel/api/internal/apiversion"
 	"github.com/Harsh-BH/Sentinel/api/internal/config"
+	grpcapi "github.com/Harsh-BH/Sentinel/api/internal/delivery/grpc"
 	handler "github.com/Harsh-BH/Sentinel/api/internal/delivery/http"
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/github"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+	"github.com/Harsh-BH/Sentinel/api/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/api/internal/oidc"
 	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
 	"github.com/Harsh-BH/Sentinel/api/internal/repository/postgres"
+	redisrepo "github.com/Harsh-BH/Sentinel/api/internal/repository/redis"
 	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+	"github.com/Harsh-BH/Sentinel/api/internal/warehouse"
+	"github.com/Harsh-BH/Sentinel/api/internal/webhook"
 )
 
 func main() {
+	validate := flag.Bool("validate-config", false, "check the configuration and the files it names, then exit")
+	flag.Parse()
+	if *validate {
+		os.Exit(validateConfig())
+	}
+
 	// Initialize logger
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
@@ -39,15 +59,23 @@ func main() {
 
 	// Connect to PostgreSQL
 	ctx := context.Background()
-	dbPool, err := pgxpool.New(ctx, cfg.Database.URL)
+	dbRetry := postgres.RetryPolicy{
+		Attempts:   cfg.Database.RetryAttempts,
+		Backoff:    cfg.Database.RetryBackoff,
+		MaxBackoff: cfg.Database.RetryMaxBackoff,
+	}
+	dbPool, err := postgres.NewPool(ctx, cfg.Database.URL, postgres.PoolConfig{
+		MaxConns:          int32(cfg.Database.MaxConns),
+		MinConns:          int32(cfg.Database.MinConns),
+		ConnectTimeout:    cfg.Database.ConnectTimeout,
+		HealthCheckPeriod: cfg.Database.HealthCheckPeriod,
+		MaxConnLifetime:   cfg.Database.MaxConnLifetime,
+		Retry:             dbRetry,
+	})
 	if err != nil {
 		logger.Fatal("Failed to connect to PostgreSQL", zap.Error(err))
 	}
 	defer dbPool.Close()
-
-	if err := dbPool.Ping(ctx); err != nil {
-		logger.Fatal("Failed to ping PostgreSQL", zap.Error(err))
-	}
 	logger.Info("Connected to PostgreSQL")
 
 	// Connect to Redis
@@ -64,29 +92,272 @@ func main() {
 	logger.Info("Connected to Redis")
 
 	// Initialize RabbitMQ publisher
-	pub, err := publisher.NewRabbitMQPublisher(cfg.RabbitMQ.URL, logger)
+	pub, err := publisher.NewRabbitMQPublisher(cfg.RabbitMQ.URLs(), cfg.RabbitMQ.MaxPriority, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize RabbitMQ publisher", zap.Error(err))
 	}
 	defer pub.Close()
 	logger.Info("Connected to RabbitMQ")
 
-	// Initialize repository
-	jobRepo := postgres.NewPostgresJobRepository(dbPool)
+	// Initialize repositories
+	jobRepo := postgres.NewPostgresJobRepository(dbPool, dbRetry)
+	problemRepo := postgres.NewPostgresProblemRepository(dbPool)
+
+	// Load the language registry shared with the worker
+	languages, err := language.Load(cfg.Server.LanguagesFile)
+	if err != nil {
+		logger.Fatal("Failed to load language registry", zap.Error(err), zap.String("path", cfg.Server.LanguagesFile))
+	}
+	var deprecations *apiversion.Policy
+	if cfg.Server.DeprecationsFile != "" {
+		deprecations, err = apiversion.Load(cfg.Server.DeprecationsFile)
+		if err != nil {
+			logger.Fatal("Failed to load deprecation policy", zap.Error(err), zap.String("path", cfg.Server.DeprecationsFile))
+		}
+	}
 
 	// Initialize use cases
-	submitUC := usecase.NewSubmitJobUsecase(jobRepo, pub, logger)
+	submitUC := usecase.NewSubmitJobUsecase(jobRepo, pub, languages, logger)
+	if cfg.Quota.TenantDailyBudget > 0 {
+		submitUC.SetQuota(redisrepo.NewRedisQuotaStore(rdb), cfg.Quota.TenantDailyBudget)
+		logger.Info("Tenant execution quotas enabled", zap.Duration("daily_budget", cfg.Quota.TenantDailyBudget))
+	}
+	submitUC.SetProblems(problemRepo)
+	if cfg.Source.Normalize {
+		submitUC.SetSourcePolicy(usecase.SourcePolicy{
+			MaxLines:      cfg.Source.MaxLines,
+			MaxLineLength: cfg.Source.MaxLineLength,
+		})
+	}
+	runtimeRepo := postgres.NewPostgresRuntimeRepository(dbPool)
+	submitUC.SetRuntimes(runtimeRepo)
+	if cfg.Server.SandboxTiers != "" {
+		tiers := strings.Split(cfg.Server.SandboxTiers, ",")
+		for i := range tiers {
+			tiers[i] = strings.TrimSpace(tiers[i])
+		}
+		submitUC.SetSandboxTiers(tiers)
+	}
+	submitUC.SetNetworkAllowlist(cfg.Server.NetworkAllowlist)
+	submitUC.SetInteractive(cfg.Server.InteractiveJobs)
+	submitUC.SetScheduling(cfg.Server.SchedulerInterval > 0)
 	getJobUC := usecase.NewGetJobUsecase(jobRepo, logger)
+	cancelUC := usecase.NewCancelJobUsecase(jobRepo, redisrepo.NewRedisCancelSignal(rdb), logger)
+	cancelUC.SetTombstones(redisrepo.NewRedisLockStore(rdb))
+	problemUC := usecase.NewProblemUsecase(problemRepo, jobRepo, pub, languages, logger)
+	appealUC := usecase.NewAppealUsecase(postgres.NewPostgresAppealRepository(dbPool), jobRepo, pub, logger)
+	runtimeUC := usecase.NewRuntimeUsecase(runtimeRepo, languages, logger)
+	if cfg.Server.RuntimeMountRoots != "" {
+		roots := strings.Split(cfg.Server.RuntimeMountRoots, ",")
+		for i := range roots {
+			roots[i] = strings.TrimSpace(roots[i])
+		}
+		runtimeUC.SetMountRoots(roots)
+	}
+	webhookRepo := postgres.NewPostgresWebhookRepository(dbPool)
+	webhookUC := usecase.NewWebhookUsecase(webhookRepo, logger)
+	// Streams of interactive jobs accepted before a restart that disabled
+	// them still get their input.
+	interactiveUC := usecase.NewInteractiveUsecase(redisrepo.NewRedisInteractiveStore(rdb), logger)
+	apiKeyUC := usecase.NewAPIKeyUsecase(postgres.NewPostgresAPIKeyRepository(dbPool), logger)
+	switch cfg.Server.RateLimitAlgorithm {
+	case middleware.SlidingWindow, middleware.TokenBucket:
+	default:
+		logger.Fatal("API_RATE_LIMIT_ALGORITHM must be sliding_window or token_bucket",
+			zap.String("algorithm", cfg.Server.RateLimitAlgorithm))
+	}
+	switch cfg.Server.RateLimitFailMode {
+	case middleware.FailOpen, middleware.FailClosed, middleware.FailLocal:
+	default:
+		logger.Fatal("API_RATE_LIMIT_FAIL_MODE must be open, closed or local",
+			zap.String("mode", cfg.Server.RateLimitFailMode))
+	}
+	if cfg.Server.APIKeysRequired && cfg.Server.AdminToken == "" {
+		logger.Fatal("API_KEYS_REQUIRED needs API_ADMIN_TOKEN to issue keys")
+	}
+	var userUC *usecase.UserUsecase
+	if cfg.Server.Users {
+		var err error
+		userUC, err = usecase.NewUserUsecase(postgres.NewPostgresUserRepository(dbPool),
+			[]byte(cfg.Server.UserTokenSecret), cfg.Server.UserTokenTTL, logger)
+		if err != nil {
+			logger.Fatal("Invalid user settings", zap.Error(err))
+		}
+	}
+	var oidcUC *usecase.OIDCUsecase
+	if cfg.Server.OIDCIssuer != "" {
+		provider, err := oidc.NewProvider(cfg.Server.OIDCIssuer, cfg.Server.OIDCAudience)
+		if err != nil {
+			logger.Fatal("Invalid OIDC settings", zap.Error(err))
+		}
+		oidcUC = usecase.NewOIDCUsecase(provider, postgres.NewPostgresUserRepository(dbPool),
+			cfg.Server.OIDCTenant, cfg.Server.OIDCTenantClaim, logger)
+	}
+	purgeUC := usecase.NewPurgeJobUsecase(jobRepo, logger)
+	var repairUC *usecase.RepairUsecase
+	var queuePauseUC *usecase.QueuePauseUsecase
+	var hardeningUC *usecase.HardeningUsecase
+	var securityEventUC *usecase.SecurityEventUsecase
+	if cfg.Server.AdminToken != "" {
+		repairUC = usecase.NewRepairUsecase(jobRepo, redisrepo.NewRedisLockStore(rdb), pub, logger)
+		hardeningUC = usecase.NewHardeningUsecase(submitUC, getJobUC, logger)
+		securityEventUC = usecase.NewSecurityEventUsecase(postgres.NewPostgresSecurityEventRepository(dbPool), logger)
+		if holder, ok := pub.(publisher.LanguageHolder); ok {
+			queuePauseUC = usecase.NewQueuePauseUsecase(redisrepo.NewRedisQueuePauseStore(rdb), holder, logger)
+		}
+	}
+
+	// Start the Postgres/broker consistency checker
+	reconcileCtx, stopReconcile := context.WithCancel(ctx)
+	defer stopReconcile()
+	if cfg.Server.StreamPush {
+		getJobUC.SetStatusFeed(postgres.NewPostgresStatusFeed(reconcileCtx, dbPool))
+	}
+	queue, canInspect := pub.(publisher.QueueInspector)
+	if canInspect {
+		metrics.RegisterQueueDepth(queue.ReadyMessages)
+	}
+	if cfg.Reconcile.Interval > 0 {
+		if !canInspect {
+			logger.Fatal("Publisher cannot inspect the execution queue")
+		}
+		checker := usecase.NewConsistencyChecker(jobRepo, redisrepo.NewRedisLockStore(rdb), pub, queue, cfg.Reconcile.Grace, logger)
+		checker.SetAutoRepair(cfg.Reconcile.Repair)
+		go checker.Run(reconcileCtx, cfg.Reconcile.Interval)
+		logger.Info("Consistency checker enabled",
+			zap.Duration("interval", cfg.Reconcile.Interval),
+			zap.Duration("grace", cfg.Reconcile.Grace),
+			zap.Bool("auto_repair", cfg.Reconcile.Repair),
+		)
+	}
+
+	// Start the scheduler that queues submissions with a run_at
+	if cfg.Server.SchedulerInterval > 0 {
+		scheduler := usecase.NewScheduler(jobRepo, pub, logger)
+		go scheduler.Run(reconcileCtx, cfg.Server.SchedulerInterval)
+		logger.Info("Scheduler enabled", zap.Duration("interval", cfg.Server.SchedulerInterval))
+	}
+
+	// Start the runner of recurring schedules
+	var scheduleUC *usecase.ScheduleUsecase
+	if cfg.Server.ScheduleInterval > 0 {
+		scheduleUC = usecase.NewScheduleUsecase(postgres.NewPostgresScheduleRepository(dbPool), submitUC, logger)
+		go scheduleUC.Run(reconcileCtx, cfg.Server.ScheduleInterval)
+		logger.Info("Recurring schedules enabled", zap.Duration("interval", cfg.Server.ScheduleInterval))
+	}
+
+	// Start the result webhook dispatcher
+	if cfg.Results.Enabled {
+		if cfg.Results.MaxAttempts <= 0 || cfg.Results.BreakerThreshold <= 0 {
+			logger.Fatal("API_RESULT_WEBHOOK_MAX_ATTEMPTS and API_RESULT_WEBHOOK_BREAKER_THRESHOLD must be positive")
+		}
+		policy := usecase.DeliveryPolicy{
+			MaxAttempts:      cfg.Results.MaxAttempts,
+			Backoff:          cfg.Results.Backoff,
+			Timeout:          cfg.Results.Timeout,
+			BreakerThreshold: cfg.Results.BreakerThreshold,
+			BreakerCooldown:  cfg.Results.BreakerCooldown,
+		}
+		dispatcher := usecase.NewResultDispatcher(webhookRepo, jobRepo, webhook.NewSender(cfg.Results.Timeout), policy, logger)
+		go dispatcher.Run(reconcileCtx, cfg.Results.Interval)
+		logger.Info("Result webhooks enabled",
+			zap.Duration("interval", cfg.Results.Interval),
+			zap.Int("max_attempts", cfg.Results.MaxAttempts),
+			zap.Int("breaker_threshold", cfg.Results.BreakerThreshold),
+		)
+	}
+
+	// Start the GitHub submission intake's status reporter
+	var githubUC *usecase.GitHubUsecase
+	if cfg.GitHub.Enabled {
+		githubUC = usecase.NewGitHubUsecase(postgres.NewPostgresGitHubRepository(dbPool), submitUC,
+			github.NewClient(cfg.GitHub.APIURL), cfg.GitHub.PublicURL, logger)
+		go githubUC.Run(reconcileCtx, cfg.GitHub.ReportInterval)
+		logger.Info("GitHub integration enabled",
+			zap.String("api_url", cfg.GitHub.APIURL),
+			zap.Duration("report_interval", cfg.GitHub.ReportInterval),
+		)
+	}
+
+	// Start the LTI tool's score reporter
+	var ltiUC *usecase.LTIUsecase
+	if cfg.LTI.Enabled {
+		if cfg.LTI.PrivateKeyFile == "" || cfg.LTI.PublicURL == "" {
+			logger.Fatal("LTI integration needs API_LTI_PRIVATE_KEY_FILE and API_PUBLIC_URL")
+		}
+		key, err := lti.LoadPrivateKey(cfg.LTI.PrivateKeyFile)
+		if err != nil {
+			logger.Fatal("Failed to load LTI private key", zap.Error(err))
+		}
+		ltiUC = usecase.NewLTIUsecase(postgres.NewPostgresLTIRepository(dbPool), redisrepo.NewRedisLTILoginStore(rdb),
+			submitUC, lti.NewClient(key), key, cfg.LTI.PublicURL, cfg.LTI.AppURL, cfg.LTI.LaunchTTL, logger)
+		go ltiUC.Run(reconcileCtx, cfg.LTI.ReportInterval)
+		logger.Info("LTI integration enabled",
+			zap.String("key_id", lti.KeyID(&key.PublicKey)),
+			zap.Duration("launch_ttl", cfg.LTI.LaunchTTL),
+			zap.Duration("report_interval", cfg.LTI.ReportInterval),
+		)
+	}
+
+	// Start the data warehouse export
+	if cfg.Export.Sink != "" {
+		sink, err := warehouse.New(cfg.Export.Sink, cfg.Export.URL, cfg.Export.Table, cfg.Export.CredentialsFile)
+		if err != nil {
+			logger.Fatal("Failed to configure warehouse export", zap.Error(err))
+		}
+		if cfg.Export.BatchSize <= 0 {
+			logger.Fatal("API_EXPORT_BATCH_SIZE must be positive")
+		}
+		if cfg.Export.Salt == "" {
+			logger.Warn("API_EXPORT_SALT is empty; source hashes can be matched against hashes of guessed sources")
+		}
+		exportUC := usecase.NewExportUsecase(postgres.NewPostgresExportRepository(dbPool), sink, cfg.Export.Salt, cfg.Export.BatchSize, logger)
+		go exportUC.Run(reconcileCtx, cfg.Export.Interval)
+		logger.Info("Warehouse export enabled",
+			zap.String("sink", sink.Name()),
+			zap.String("table", cfg.Export.Table),
+			zap.Duration("interval", cfg.Export.Interval),
+		)
+	}
 
 	// Initialize router
+	streams := handler.NewStreamShutdown()
 	router := handler.NewRouter(&handler.RouterDeps{
 		SubmitUC:        submitUC,
 		GetJobUC:        getJobUC,
+		CancelUC:        cancelUC,
+		ProblemUC:       problemUC,
+		AppealUC:        appealUC,
+		RuntimeUC:       runtimeUC,
+		WebhookUC:       webhookUC,
+		ScheduleUC:      scheduleUC,
+		ResultWebhooks:  cfg.Results.Enabled,
+		RepairUC:        repairUC,
+		PurgeUC:         purgeUC,
+		GitHubUC:        githubUC,
+		LTIUC:           ltiUC,
+		InteractiveUC:   interactiveUC,
+		Languages:       languages,
 		Logger:          logger,
 		RateLimitPerMin: cfg.Server.RateLimit,
 		DBPool:          dbPool,
-		AmqpURI:         cfg.RabbitMQ.URL,
+		AmqpURIs:        cfg.RabbitMQ.URLs(),
 		Redis:           rdb,
+		StreamShutdown:  streams,
+		Deprecations:    deprecations,
+		AdminToken:      cfg.Server.AdminToken,
+		APIKeyUC:        apiKeyUC,
+		APIKeysRequired: cfg.Server.APIKeysRequired,
+		QueuePauseUC:    queuePauseUC,
+		HardeningUC:     hardeningUC,
+		UserUC:          userUC,
+		OIDCUC:          oidcUC,
+		UI:              cfg.Server.UI,
+
+		RateLimitAlgorithm: cfg.Server.RateLimitAlgorithm,
+		RateLimitBurst:     cfg.Server.RateLimitBurst,
+		RateLimitFailMode:  cfg.Server.RateLimitFailMode,
+		SecurityEventUC:    securityEventUC,
 	})
 
 	// Create HTTP server
@@ -105,19 +376,82 @@ func main() {
 		}
 	}()
 
+	// Serve the gRPC API alongside the REST one
+	var grpcSrv *grpc.Server
+	var grpcAPI *grpcapi.Server
+	if cfg.Server.GRPCPort > 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
+		if err != nil {
+			logger.Fatal("Failed to listen for gRPC", zap.Error(err))
+		}
+		// Calls pass the same rate limit, keys and scopes as REST requests.
+		grpcAPI = grpcapi.NewServer(submitUC, getJobUC, logger)
+		grpcAPI.SetAuth(apiKeyUC, cfg.Server.APIKeysRequired)
+		grpcAPI.SetRateLimit(middleware.NewRateLimit(rdb, cfg.Server.RateLimitAlgorithm,
+			cfg.Server.RateLimitFailMode, cfg.Server.RateLimit, cfg.Server.RateLimitBurst))
+		grpcSrv = grpc.NewServer(grpcAPI.ServerOptions()...)
+		grpcAPI.Register(grpcSrv)
+		go func() {
+			logger.Info("gRPC server listening", zap.Int("port", cfg.Server.GRPCPort))
+			if err := grpcSrv.Serve(lis); err != nil {
+				logger.Fatal("gRPC server failed", zap.Error(err))
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info("Shutting down API server...")
+	stopReconcile()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Tell WebSocket clients to reconnect elsewhere before the listener goes.
+	if err := streams.Close(shutdownCtx); err != nil {
+		logger.Warn("WebSocket streams did not close in time", zap.Error(err))
+	}
+	if grpcSrv != nil {
+		if err := grpcAPI.Shutdown(shutdownCtx, grpcSrv); err != nil {
+			logger.Warn("gRPC calls did not finish in time", zap.Error(err))
+		}
+	}
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
+	// Handlers are done; let their publishes get confirmed before exiting.
+	if err := pub.Close(); err != nil {
+		logger.Warn("RabbitMQ publisher did not drain cleanly", zap.Error(err))
+	}
 
 	logger.Info("API server stopped")
 }
+
+// validateConfig loads the configuration and the files it names as the
+// server would, for --validate-config, and reports what is wrong with
+// them. It returns the exit status.
+func validateConfig() int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if _, err := language.Load(cfg.Server.LanguagesFile); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %s: %v\n", cfg.Server.LanguagesFile, err)
+		return 1
+	}
+	if cfg.Server.DeprecationsFile != "" {
+		if _, err := apiversion.Load(cfg.Server.DeprecationsFile); err != nil {
+			fmt.Fprintf(os.Stderr, "config: %s: %v\n", cfg.Server.DeprecationsFile, err)
+			return 1
+		}
+	}
+	fmt.Println("Configuration is valid")
+	for _, path := range config.Files() {
+		fmt.Println("  read", path)
+	}
+	return 0
+}
@@ -0,0 +1,197 @@
+// Package apiversion holds the deprecation schedule of API versions and
+// individual endpoints.
+package apiversion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Notice is the deprecation schedule of a version or endpoint. Zero times
+// are unset.
+type Notice struct {
+	// Deprecation is when the version or endpoint is (or was) deprecated.
+	Deprecation time.Time
+	// Sunset is when it stops being served.
+	Sunset time.Time
+	// Link points to migration documentation.
+	Link string
+}
+
+func (n Notice) isZero() bool {
+	return n.Deprecation.IsZero() && n.Sunset.IsZero() && n.Link == ""
+}
+
+// merge returns n with the fields set in o taking precedence.
+func (n Notice) merge(o Notice) Notice {
+	if !o.Deprecation.IsZero() {
+		n.Deprecation = o.Deprecation
+	}
+	if !o.Sunset.IsZero() {
+		n.Sunset = o.Sunset
+	}
+	if o.Link != "" {
+		n.Link = o.Link
+	}
+	return n
+}
+
+// Policy maps versions and endpoints to their deprecation notices. A nil
+// Policy deprecates nothing.
+type Policy struct {
+	versions  map[string]Notice
+	endpoints map[string]Notice // keyed by "METHOD /api/<version>/path"
+}
+
+type notice struct {
+	Deprecation any    `mapstructure:"deprecation"`
+	Sunset      any    `mapstructure:"sunset"`
+	Link        string `mapstructure:"link"`
+}
+
+type endpoint struct {
+	Route       string `mapstructure:"route"`
+	Deprecation any    `mapstructure:"deprecation"`
+	Sunset      any    `mapstructure:"sunset"`
+	Link        string `mapstructure:"link"`
+}
+
+// Load reads a policy from a YAML file of the form
+//
+//	versions:
+//	  v1: {deprecation: 2027-01-01, sunset: 2027-07-01, link: https://...}
+//	endpoints:
+//	  - route: GET /api/v1/submissions/:id
+//	    sunset: 2027-03-01
+func Load(path string) (*Policy, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read deprecation policy: %w", err)
+	}
+	var versions map[string]notice
+	if err := v.UnmarshalKey("versions", &versions); err != nil {
+		return nil, fmt.Errorf("decode deprecation policy: %w", err)
+	}
+	var endpoints []endpoint
+	if err := v.UnmarshalKey("endpoints", &endpoints); err != nil {
+		return nil, fmt.Errorf("decode deprecation policy: %w", err)
+	}
+
+	p := &Policy{versions: make(map[string]Notice), endpoints: make(map[string]Notice)}
+	for name, raw := range versions {
+		n, err := raw.parse()
+		if err != nil {
+			return nil, fmt.Errorf("deprecation policy: version %s: %w", name, err)
+		}
+		p.versions[name] = n
+	}
+	for _, e := range endpoints {
+		key, err := routeKey(e.Route)
+		if err != nil {
+			return nil, fmt.Errorf("deprecation policy: %w", err)
+		}
+		if _, dup := p.endpoints[key]; dup {
+			return nil, fmt.Errorf("deprecation policy: route %q listed twice", e.Route)
+		}
+		n, err := notice{Deprecation: e.Deprecation, Sunset: e.Sunset, Link: e.Link}.parse()
+		if err != nil {
+			return nil, fmt.Errorf("deprecation policy: route %s: %w", key, err)
+		}
+		p.endpoints[key] = n
+	}
+	return p, nil
+}
+
+func (n notice) parse() (Notice, error) {
+	var (
+		out Notice
+		err error
+	)
+	if out.Deprecation, err = parseDate(n.Deprecation); err != nil {
+		return Notice{}, fmt.Errorf("deprecation: %w", err)
+	}
+	if out.Sunset, err = parseDate(n.Sunset); err != nil {
+		return Notice{}, fmt.Errorf("sunset: %w", err)
+	}
+	if !out.Deprecation.IsZero() && !out.Sunset.IsZero() && out.Sunset.Before(out.Deprecation) {
+		return Notice{}, fmt.Errorf("sunset %s is before deprecation %s",
+			out.Sunset.Format(time.DateOnly), out.Deprecation.Format(time.DateOnly))
+	}
+	out.Link = n.Link
+	if out.isZero() {
+		return Notice{}, fmt.Errorf("no deprecation, sunset or link")
+	}
+	return out, nil
+}
+
+// parseDate accepts a YAML timestamp or a date or RFC 3339 string.
+func parseDate(v any) (time.Time, error) {
+	switch d := v.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return d.UTC(), nil
+	case string:
+		if d == "" {
+			return time.Time{}, nil
+		}
+		if t, err := time.Parse(time.DateOnly, d); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(time.RFC3339, d)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is not a date (want YYYY-MM-DD or RFC 3339)", d)
+		}
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("%v is not a date", v)
+}
+
+// routeKey normalizes "get  /api/v1/x" to "GET /api/v1/x".
+func routeKey(route string) (string, error) {
+	fields := strings.Fields(route)
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "/api/") {
+		return "", fmt.Errorf("route %q must look like \"GET /api/v1/path\"", route)
+	}
+	return strings.ToUpper(fields[0]) + " " + fields[1], nil
+}
+
+// Lookup returns the notice for method and the full route path served under
+// version: the endpoint's own schedule over its version's.
+func (p *Policy) Lookup(version, method, path string) (Notice, bool) {
+	if p == nil {
+		return Notice{}, false
+	}
+	n, ok := p.versions[version]
+	if e, found := p.endpoints[method+" "+path]; found {
+		n, ok = n.merge(e), true
+	}
+	return n, ok
+}
+
+// Unknown lists the versions and routes in the policy that are not in
+// served, which maps versions and "METHOD /path" route keys to true. They
+// usually are typos.
+func (p *Policy) Unknown(served map[string]bool) []string {
+	if p == nil {
+		return nil
+	}
+	var unknown []string
+	for v := range p.versions {
+		if !served[v] {
+			unknown = append(unknown, v)
+		}
+	}
+	for key := range p.endpoints {
+		if !served[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
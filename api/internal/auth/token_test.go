@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	claims := Claims{Subject: "u1", Tenant: "acme", Email: "a@example.com", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()}
+	token, err := Sign(claims, secret)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	got, err := Verify(token, secret, now)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if *got != claims {
+		t.Errorf("claims = %+v, want %+v", *got, claims)
+	}
+
+	parts := strings.Split(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"u2","tenant":"other","exp":9999999999}`))
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	for name, bad := range map[string]string{
+		"expired":      "",
+		"wrong secret": token,
+		"forged":       parts[0] + "." + forged + "." + parts[2],
+		"alg none":     none + "." + parts[1] + ".",
+		"garbage":      "not-a-token",
+	} {
+		key, at := secret, now
+		switch name {
+		case "expired":
+			bad, at = token, now.Add(time.Hour)
+		case "wrong secret":
+			key = []byte(strings.Repeat("x", MinSecretLen))
+		}
+		if _, err := Verify(bad, key, at); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidToken", name, err)
+		}
+	}
+
+	if _, err := Sign(claims, []byte("short")); err == nil {
+		t.Error("short secret: want an error")
+	}
+}
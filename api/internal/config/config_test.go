@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultsCoverSettings(t *testing.T) {
+	defaults := viper.New()
+	defaults.SetConfigType("yaml")
+	if err := defaults.ReadConfig(strings.NewReader(string(defaultsYAML))); err != nil {
+		t.Fatal(err)
+	}
+	schema := settings()
+	for name := range schema {
+		if !defaults.IsSet(name) {
+			t.Errorf("%s has no default", name)
+		}
+	}
+	if errs := checkSettings("defaults.yaml", schema, defaults.AllSettings(), true); len(errs) > 0 {
+		t.Errorf("defaults do not match the settings: %v", errs)
+	}
+}
+
+func TestLoad_Layers(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "base.yaml", `
+shared:
+  DATABASE_MAX_CONNS: 10
+  RABBITMQ_MAX_PRIORITY: 9
+api:
+  API_RATE_LIMIT: 200
+  API_RECONCILE_INTERVAL: 1m
+worker:
+  WORKER_POOL_SIZE: 16
+`)
+	writeConfig(t, dir, "production.yaml", `
+shared:
+  DATABASE_MAX_CONNS: 40
+api:
+  API_RATE_LIMIT: 500
+  GIN_MODE: release
+`)
+	t.Setenv(ConfigDirEnv, dir)
+	t.Setenv(EnvironmentEnv, "production")
+	t.Setenv("API_RATE_LIMIT", "800")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8080 {
+		t.Errorf("Port = %d, want the default 8080", cfg.Server.Port)
+	}
+	if cfg.Reconcile.Interval != time.Minute || cfg.RabbitMQ.MaxPriority != 9 {
+		t.Errorf("base.yaml not applied: interval %v, max priority %d", cfg.Reconcile.Interval, cfg.RabbitMQ.MaxPriority)
+	}
+	if cfg.Database.MaxConns != 40 || cfg.Server.GinMode != "release" {
+		t.Errorf("overlay not applied: max conns %d, gin mode %q", cfg.Database.MaxConns, cfg.Server.GinMode)
+	}
+	if cfg.Server.RateLimit != 800 {
+		t.Errorf("RateLimit = %d, want 800 from the environment", cfg.Server.RateLimit)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "base.yaml", `
+api:
+  API_RATE_LIMT: 200
+  API_UI: sometimes
+limits:
+  API_PORT: 1
+`)
+	t.Setenv(ConfigDirEnv, dir)
+	t.Setenv(EnvironmentEnv, "staging")
+	t.Setenv("API_READ_TIMEOUT", "10")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load succeeded, want errors")
+	}
+	for _, want := range []string{
+		"unknown setting API_RATE_LIMT",
+		"API_UI: want true or false",
+		`unknown section "limits"`,
+		"staging.yaml",
+		"environment: API_READ_TIMEOUT: want a duration",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	schema := settings()
+	tests := []struct {
+		name  string
+		value any
+		ok    bool
+	}{
+		{"API_PORT", 9000, true},
+		{"API_PORT", "9000", true},
+		{"API_PORT", "ninety", false},
+		{"API_PORT", 9.5, false},
+		{"API_UI", false, true},
+		{"API_UI", "1", true},
+		{"API_UI", 1, false},
+		{"API_READ_TIMEOUT", "5s", true},
+		{"API_READ_TIMEOUT", 0, true},
+		{"API_READ_TIMEOUT", 5, false},
+		{"API_ADMIN_TOKEN", 12345, true},
+		{"API_ADMIN_TOKEN", []any{"a"}, false},
+	}
+	for _, tt := range tests {
+		err := checkValue(schema[tt.name], tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkValue(%s, %#v) = %v, want ok %v", tt.name, tt.value, err, tt.ok)
+		}
+	}
+}
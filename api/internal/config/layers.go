@@ -0,0 +1,269 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// defaultsYAML holds the default of every setting.
+//
+//go:embed defaults.yaml
+var defaultsYAML []byte
+
+const (
+	// ownSection holds this binary's settings in a config file, and
+	// otherSection the worker's, which are left for the worker to check.
+	// Settings under sharedSection apply to both; ownSection overrides
+	// them.
+	ownSection    = "api"
+	otherSection  = "worker"
+	sharedSection = "shared"
+
+	// ConfigDirEnv and EnvironmentEnv locate the config files. They are
+	// only read from the environment, since they say where the rest of the
+	// configuration is.
+	ConfigDirEnv   = "SENTINEL_CONFIG_DIR"
+	EnvironmentEnv = "SENTINEL_ENV"
+
+	defaultConfigDir = "../config"
+)
+
+// Files returns the config files Load reads, in order: base.yaml in the
+// config directory if it exists, then the overlay of the environment named
+// by SENTINEL_ENV, which must exist once it is named.
+func Files() []string {
+	dir := os.Getenv(ConfigDirEnv)
+	if dir == "" {
+		dir = defaultConfigDir
+	}
+	var files []string
+	if base := filepath.Join(dir, "base.yaml"); fileExists(base) {
+		files = append(files, base)
+	}
+	if env := os.Getenv(EnvironmentEnv); env != "" {
+		files = append(files, filepath.Join(dir, env+".yaml"))
+	}
+	return files
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// layered reads the configuration's layers into a viper instance, each
+// overriding the ones before: the defaults, base.yaml, the environment's
+// overlay, the .env file, and the environment. Every layer is checked
+// against the settings of Config, so that an unknown setting in a config
+// file, or a value of the wrong type anywhere, fails the load instead of
+// falling back to a default.
+func layered() (*viper.Viper, error) {
+	schema := settings()
+	var errs []error
+
+	v := viper.New()
+	defaults := viper.New()
+	defaults.SetConfigType("yaml")
+	if err := defaults.ReadConfig(bytes.NewReader(defaultsYAML)); err != nil {
+		return nil, fmt.Errorf("config: read defaults: %w", err)
+	}
+	errs = append(errs, checkSettings("defaults.yaml", schema, defaults.AllSettings(), true)...)
+	for key, value := range defaults.AllSettings() {
+		v.SetDefault(key, value)
+	}
+
+	for _, path := range Files() {
+		settings, err := readConfigFile(path, schema)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := v.MergeConfigMap(settings); err != nil {
+			errs = append(errs, fmt.Errorf("config: %s: %w", path, err))
+		}
+	}
+
+	// The .env file, if any, is shared with the worker and other tools, so
+	// settings this binary does not know are ignored there.
+	dotenv := viper.New()
+	dotenv.SetConfigFile(".env")
+	if err := dotenv.ReadInConfig(); err == nil {
+		errs = append(errs, checkSettings(".env", schema, dotenv.AllSettings(), false)...)
+		if err := v.MergeConfigMap(dotenv.AllSettings()); err != nil {
+			errs = append(errs, fmt.Errorf("config: .env: %w", err))
+		}
+	}
+
+	environ := make(map[string]any)
+	for key := range schema {
+		if value, ok := os.LookupEnv(key); ok {
+			environ[key] = value
+		}
+	}
+	errs = append(errs, checkSettings("environment", schema, environ, false)...)
+	v.AutomaticEnv()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return v, nil
+}
+
+// readConfigFile reads the settings of this binary from a config file,
+// shared ones first so that its own section overrides them.
+func readConfigFile(path string, schema map[string]reflect.Type) (map[string]any, error) {
+	f := viper.New()
+	f.SetConfigFile(path)
+	if err := f.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	var errs []error
+	settings := make(map[string]any)
+	for _, name := range sortedKeys(f.AllSettings()) {
+		if name != ownSection && name != otherSection && name != sharedSection {
+			errs = append(errs, fmt.Errorf("config: %s: unknown section %q; settings go under %q, %q or %q",
+				path, name, ownSection, otherSection, sharedSection))
+		}
+	}
+	for _, name := range []string{sharedSection, ownSection} {
+		raw := f.Get(name)
+		if raw == nil {
+			continue
+		}
+		section, ok := raw.(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Errorf("config: %s: section %q must map settings to values", path, name))
+			continue
+		}
+		errs = append(errs, checkSettings(path+": "+name, schema, section, true)...)
+		for key, value := range section {
+			settings[key] = value
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return settings, nil
+}
+
+// checkSettings checks values read from source against the schema. With
+// strict set, a setting the schema does not know is an error too.
+func checkSettings(source string, schema map[string]reflect.Type, values map[string]any, strict bool) []error {
+	var errs []error
+	for _, key := range sortedKeys(values) {
+		name := strings.ToUpper(key)
+		t, ok := schema[name]
+		if !ok {
+			if strict {
+				errs = append(errs, fmt.Errorf("config: %s: unknown setting %s", source, name))
+			}
+			continue
+		}
+		if err := checkValue(t, values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("config: %s: %s: %w", source, name, err))
+		}
+	}
+	return errs
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// checkValue reports why value, from a config file or the environment,
+// cannot be read as a setting of type t.
+func checkValue(t reflect.Type, value any) error {
+	if s, ok := value.(string); ok {
+		return checkString(t, s)
+	}
+	switch value.(type) {
+	case bool:
+		if t.Kind() == reflect.Bool || t.Kind() == reflect.String {
+			return nil
+		}
+	case int, int64:
+		if t == durationType {
+			if reflect.ValueOf(value).Int() == 0 {
+				return nil
+			}
+			break
+		}
+		switch t.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64, reflect.String:
+			return nil
+		}
+	case float64:
+		if t.Kind() == reflect.Float64 || t.Kind() == reflect.String {
+			return nil
+		}
+	}
+	return wantError(t, value)
+}
+
+func checkString(t reflect.Type, s string) error {
+	s = strings.TrimSpace(s)
+	var err error
+	switch {
+	case t == durationType:
+		_, err = time.ParseDuration(s)
+	case t.Kind() == reflect.Bool:
+		_, err = strconv.ParseBool(s)
+	case t.Kind() == reflect.Int, t.Kind() == reflect.Int32, t.Kind() == reflect.Int64:
+		_, err = strconv.Atoi(s)
+	case t.Kind() == reflect.Float64:
+		_, err = strconv.ParseFloat(s, 64)
+	}
+	if err != nil {
+		return wantError(t, s)
+	}
+	return nil
+}
+
+func wantError(t reflect.Type, value any) error {
+	want := "a string"
+	switch {
+	case t == durationType:
+		want = `a duration such as "30s"`
+	case t.Kind() == reflect.Bool:
+		want = "true or false"
+	case t.Kind() == reflect.Float64:
+		want = "a number"
+	case t.Kind() == reflect.Int, t.Kind() == reflect.Int32, t.Kind() == reflect.Int64:
+		want = "an integer"
+	}
+	return fmt.Errorf("want %s, got %#v", want, value)
+}
+
+// settings returns the type of every setting of Config, by name.
+func settings() map[string]reflect.Type {
+	schema := make(map[string]reflect.Type)
+	cfg := reflect.TypeOf(Config{})
+	for i := 0; i < cfg.NumField(); i++ {
+		section := cfg.Field(i).Type
+		for j := 0; j < section.NumField(); j++ {
+			if name := section.Field(j).Tag.Get("mapstructure"); name != "" {
+				schema[name] = section.Field(j).Type
+			}
+		}
+	}
+	return schema
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
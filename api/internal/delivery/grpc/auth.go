@@ -0,0 +1,186 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	sentinelv1 "github.com/Harsh-BH/Sentinel/api/proto/sentinel/v1"
+)
+
+// authorizationKey is the metadata key carrying "Bearer <key>", as the
+// Authorization header does over REST.
+const authorizationKey = "authorization"
+
+// methodScopes are the scopes a key needs to call each method, as for the
+// matching REST routes.
+var methodScopes = map[string]string{
+	sentinelv1.Sentinel_Submit_FullMethodName: domain.ScopeSubmit,
+}
+
+// APIKeyAuthenticator resolves the API key a call presents.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+}
+
+// apiKeyContextKey is the context key admit stores a call's *domain.APIKey
+// under.
+type apiKeyContextKey struct{}
+
+// SetAuth authenticates calls by the API key they send in their
+// "authorization" metadata, as "Bearer <key>". An unknown or revoked key is
+// rejected with UNAUTHENTICATED, and so is a call without one when
+// required; otherwise such calls act as the tenant in x-tenant-id.
+func (s *Server) SetAuth(keys APIKeyAuthenticator, required bool) {
+	s.keys = keys
+	s.keysRequired = required
+}
+
+// SetRateLimit limits calls by the IP address of their peer, rejecting
+// those over the limit with RESOURCE_EXHAUSTED and a retry-after header.
+func (s *Server) SetRateLimit(limit middleware.RateLimit) {
+	s.rateLimit = limit
+}
+
+// ServerOptions returns the interceptors that apply the rate limit,
+// authentication and scopes to every call; pass them to grpc.NewServer.
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	}
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, header, err := s.admit(ctx, info.FullMethod)
+	if header != nil {
+		_ = grpc.SetHeader(ctx, header)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, header, err := s.admit(ss.Context(), info.FullMethod)
+	if header != nil {
+		_ = ss.SetHeader(header)
+	}
+	if err != nil {
+		return err
+	}
+	return handler(srv, &admittedStream{ServerStream: ss, ctx: ctx})
+}
+
+// admittedStream is a stream whose context carries what admit found.
+type admittedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *admittedStream) Context() context.Context {
+	return s.ctx
+}
+
+// admit checks a call of method against the rate limit, its API key and
+// the method's scope, as the REST middleware does a request. It returns
+// the call's context, holding the key that authenticated it, and the
+// rate limit headers to send.
+func (s *Server) admit(ctx context.Context, method string) (context.Context, metadata.MD, error) {
+	var header metadata.MD
+	if s.rateLimit != nil {
+		res := s.rateLimit(ctx, peerIP(ctx))
+		if res.Limit > 0 {
+			header = metadata.Pairs(
+				"x-ratelimit-limit", strconv.Itoa(res.Limit),
+				"x-ratelimit-remaining", strconv.Itoa(res.Remaining),
+			)
+		}
+		if !res.Allowed {
+			d := max(res.RetryAfter, 0)
+			header = metadata.Join(header, metadata.Pairs("retry-after", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10)))
+			code := codes.ResourceExhausted
+			if res.Status == http.StatusServiceUnavailable {
+				code = codes.Unavailable
+			}
+			return ctx, header, status.Error(code, res.Message)
+		}
+	}
+
+	if s.keys == nil {
+		return ctx, header, nil
+	}
+	var secret string
+	var ok bool
+	if md, found := metadata.FromIncomingContext(ctx); found {
+		if v := md.Get(authorizationKey); len(v) > 0 {
+			secret, ok = strings.CutPrefix(v[0], "Bearer ")
+		}
+	}
+	if !ok {
+		if s.keysRequired {
+			return ctx, header, status.Error(codes.Unauthenticated, "API key required")
+		}
+		return ctx, header, nil
+	}
+	key, err := s.keys.Authenticate(ctx, secret)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAPIKey) {
+			return ctx, header, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		s.logger.Error("API key authentication failed", zap.Error(err))
+		return ctx, header, status.Error(codes.Internal, "internal server error")
+	}
+	if scope := methodScopes[method]; scope != "" && !domain.HasScope(key.Scopes, scope) {
+		return ctx, header, status.Error(codes.PermissionDenied, fmt.Sprintf("the %q scope is required", scope))
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, key), header, nil
+}
+
+// callerKey returns the API key that authenticated the call, or nil.
+func callerKey(ctx context.Context) *domain.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*domain.APIKey)
+	return key
+}
+
+// canRead reports whether the call may read job. A key reads its own
+// tenant's jobs at most, and only the jobs submitted with it unless it has
+// the read:any scope; calls without a key read any job.
+func canRead(ctx context.Context, job *domain.Job) bool {
+	key := callerKey(ctx)
+	if key == nil {
+		return true
+	}
+	if job.TenantID != key.TenantID {
+		return false
+	}
+	return domain.HasScope(key.Scopes, domain.ScopeReadAny) || (job.APIKeyID != nil && *job.APIKeyID == key.KeyID)
+}
+
+// peerIP is the IP address the call came from, which rate limits count
+// calls by.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	sentinelv1 "github.com/Harsh-BH/Sentinel/api/proto/sentinel/v1"
+)
+
+// toSubmitRequest converts a submission to its domain form, which the
+// submit usecase validates just as it does a REST body.
+func toSubmitRequest(req *sentinelv1.SubmitRequest) *domain.SubmitRequest {
+	submit := &domain.SubmitRequest{
+		Language:        domain.Language(req.GetLanguage()),
+		SourceCode:      req.GetSourceCode(),
+		Stdin:           req.GetStdin(),
+		TimeLimitMs:     intPtr(req.TimeLimitMs),
+		MemoryLimitKB:   intPtr(req.MemoryLimitKb),
+		WallTimeLimitMs: intPtr(req.WallTimeLimitMs),
+		CPUTimeLimitMs:  intPtr(req.CpuTimeLimitMs),
+		PidsLimit:       intPtr(req.PidsLimit),
+		CompilerFlags:   req.GetCompilerFlags(),
+		Args:            req.GetArgs(),
+		Env:             req.GetEnv(),
+		ProblemID:       req.GetProblemId(),
+		SandboxTier:     req.GetSandboxTier(),
+		NetworkPolicy:   req.GetNetworkPolicy(),
+		OutputFiles:     req.GetOutputFiles(),
+		ExpectedOutput:  req.ExpectedOutput,
+		CompareMode:     domain.CompareMode(req.GetCompareMode()),
+		Priority:        intPtr(req.Priority),
+	}
+	if req.RunAt != nil {
+		runAt := req.RunAt.AsTime()
+		submit.RunAt = &runAt
+	}
+	return submit
+}
+
+// toJob converts a job to its message. The result is only set once the
+// job has finished.
+func toJob(job *domain.Job) *sentinelv1.Job {
+	msg := &sentinelv1.Job{
+		JobId:           job.JobID.String(),
+		TenantId:        job.TenantID,
+		Language:        string(job.Language),
+		Status:          toStatus(job.Status),
+		SourceCode:      job.SubmittedSource(),
+		Stdin:           job.Stdin,
+		WallTimeLimitMs: int32(job.WallTimeLimitMs),
+		CpuTimeLimitMs:  int32(job.CPUTimeLimitMs),
+		MemoryLimitKb:   int32(job.MemoryLimitKB),
+		PidsLimit:       int32(job.PidsLimit),
+		Priority:        int32(job.Priority),
+		SandboxTier:     job.SandboxTier,
+		NetworkPolicy:   job.NetworkPolicy,
+		ProblemId:       job.ProblemID,
+		JudgeRevision:   int32(job.JudgeRevision),
+		Origin:          string(job.Origin),
+		RunAt:           timestamp(job.RunAt),
+		CreatedAt:       timestamppb.New(job.CreatedAt),
+		UpdatedAt:       timestamppb.New(job.UpdatedAt),
+	}
+	if job.ParentJobID != nil {
+		msg.ParentJobId = job.ParentJobID.String()
+	}
+	if job.Status.IsTerminal() {
+		msg.Result = toResult(job)
+	}
+	return msg
+}
+
+func toResult(job *domain.Job) *sentinelv1.ExecutionResult {
+	result := &sentinelv1.ExecutionResult{
+		Stdout:        job.Stdout,
+		Stderr:        job.Stderr,
+		ExitCode:      int32Ptr(job.ExitCode),
+		TimeUsedMs:    int32Ptr(job.TimeUsedMs),
+		CpuTimeUsedMs: int32Ptr(job.CPUTimeUsedMs),
+		MemoryUsedKb:  int32Ptr(job.MemoryUsedKB),
+		DiskUsedKb:    int32Ptr(job.DiskUsedKB),
+		Score:         job.Score,
+		MaxScore:      job.MaxScore,
+	}
+	for _, tc := range job.TestResults {
+		result.TestResults = append(result.TestResults, &sentinelv1.TestCaseResult{
+			Ordinal:       int32(tc.Ordinal),
+			Status:        toStatus(tc.Status),
+			TimeUsedMs:    int32(tc.TimeUsedMs),
+			MemoryUsedKb:  int32(tc.MemoryUsedKB),
+			Message:       tc.Message,
+			Subtask:       int32(tc.Subtask),
+			TimeLimitMs:   int32(tc.TimeLimitMs),
+			MemoryLimitKb: int32(tc.MemoryLimitKB),
+			OutputHash:    tc.OutputHash,
+			Output:        tc.Output,
+		})
+	}
+	for _, st := range job.SubtaskResults {
+		result.SubtaskResults = append(result.SubtaskResults, &sentinelv1.SubtaskResult{
+			Ordinal: int32(st.Ordinal),
+			Status:  toStatus(st.Status),
+			Points:  st.Points,
+			Score:   st.Score,
+		})
+	}
+	return result
+}
+
+func toEvents(events []domain.JobEvent) []*sentinelv1.JobEvent {
+	msgs := make([]*sentinelv1.JobEvent, 0, len(events))
+	for _, ev := range events {
+		msgs = append(msgs, &sentinelv1.JobEvent{
+			Status:    toStatus(ev.Status),
+			CreatedAt: timestamppb.New(ev.CreatedAt),
+		})
+	}
+	return msgs
+}
+
+// toStatus converts a status by name; the enum's values are the REST
+// statuses prefixed with JOB_STATUS_.
+func toStatus(s domain.ExecutionStatus) sentinelv1.JobStatus {
+	return sentinelv1.JobStatus(sentinelv1.JobStatus_value["JOB_STATUS_"+string(s)])
+}
+
+func intPtr(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
+
+func int32Ptr(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	n := int32(*v)
+	return &n
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
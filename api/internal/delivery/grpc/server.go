@@ -0,0 +1,234 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+	sentinelv1 "github.com/Harsh-BH/Sentinel/api/proto/sentinel/v1"
+)
+
+const (
+	// tenantIDKey is the metadata key naming the submitting tenant, as the
+	// X-Tenant-ID header does over REST. A call's API key overrides it.
+	tenantIDKey = "x-tenant-id"
+
+	// How often Watch polls the database for job updates when no status
+	// feed wakes it.
+	watchPollInterval = 500 * time.Millisecond
+)
+
+// Server serves the Sentinel gRPC service over the usecases behind the
+// REST API.
+type Server struct {
+	sentinelv1.UnimplementedSentinelServer
+
+	submitUC *usecase.SubmitJobUsecase
+	getJobUC *usecase.GetJobUsecase
+	logger   *zap.Logger
+
+	// keys authenticates calls; nil leaves them unauthenticated.
+	keys         APIKeyAuthenticator
+	keysRequired bool
+	rateLimit    middleware.RateLimit
+
+	// closing is closed by Shutdown to end open Watch streams.
+	closing chan struct{}
+}
+
+// NewServer creates a new Server.
+func NewServer(submitUC *usecase.SubmitJobUsecase, getJobUC *usecase.GetJobUsecase, logger *zap.Logger) *Server {
+	return &Server{
+		submitUC: submitUC,
+		getJobUC: getJobUC,
+		logger:   logger,
+		closing:  make(chan struct{}),
+	}
+}
+
+// Register adds the service to g.
+func (s *Server) Register(g *grpc.Server) {
+	sentinelv1.RegisterSentinelServer(g, s)
+}
+
+// Shutdown ends open Watch streams with UNAVAILABLE, so their clients
+// reconnect to another instance, then stops g gracefully. Calls still
+// running when ctx is done are cut off.
+func (s *Server) Shutdown(ctx context.Context, g *grpc.Server) error {
+	close(s.closing)
+	stopped := make(chan struct{})
+	go func() {
+		g.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		g.Stop()
+		return ctx.Err()
+	}
+}
+
+// Submit handles Sentinel.Submit.
+func (s *Server) Submit(ctx context.Context, req *sentinelv1.SubmitRequest) (*sentinelv1.SubmitResponse, error) {
+	submit := toSubmitRequest(req)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(tenantIDKey); len(v) > 0 {
+			submit.TenantID = v[0]
+		}
+	}
+	if key := callerKey(ctx); key != nil {
+		submit.TenantID = key.TenantID
+		submit.APIKeyID = &key.KeyID
+	}
+
+	resp, err := s.submitUC.Execute(ctx, submit)
+	if err != nil {
+		return nil, s.submitError(ctx, err)
+	}
+	return &sentinelv1.SubmitResponse{
+		JobId:  resp.JobID.String(),
+		Status: toStatus(domain.ExecutionStatus(resp.Status)),
+	}, nil
+}
+
+// submitError maps errors of creating a job to statuses.
+func (s *Server) submitError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidLanguage),
+		errors.Is(err, domain.ErrEmptySourceCode),
+		errors.Is(err, domain.ErrInvalidSource),
+		errors.Is(err, domain.ErrInvalidCompilerFlags),
+		errors.Is(err, domain.ErrInvalidArgs),
+		errors.Is(err, domain.ErrInvalidEnv),
+		errors.Is(err, domain.ErrInvalidSandboxTier),
+		errors.Is(err, domain.ErrInvalidNetworkPolicy),
+		errors.Is(err, domain.ErrInvalidOutputFiles),
+		errors.Is(err, domain.ErrInvalidInteractive),
+		errors.Is(err, domain.ErrInvalidExpectedOutput),
+		errors.Is(err, domain.ErrInvalidPriority),
+		errors.Is(err, domain.ErrInvalidRunAt),
+		errors.Is(err, domain.ErrPayloadTooLarge),
+		errors.Is(err, domain.ErrProblemNotFound):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		if resetAt := s.submitUC.QuotaResetAt(time.Now()); !resetAt.IsZero() {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(
+				"retry-after", strconv.Itoa(int(time.Until(resetAt).Seconds())+1),
+				"x-quota-reset", resetAt.Format(time.RFC3339),
+			))
+		}
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrPublishFailed):
+		return status.Error(codes.Unavailable, "service temporarily unavailable")
+	default:
+		s.logger.Error("Submit job failed", zap.Error(err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+// Get handles Sentinel.Get.
+func (s *Server) Get(ctx context.Context, req *sentinelv1.GetRequest) (*sentinelv1.Job, error) {
+	id, err := uuid.Parse(req.GetJobId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid job ID format")
+	}
+	job, err := s.getJobUC.Execute(ctx, id)
+	if err != nil {
+		return nil, s.getError(id, err)
+	}
+	if !canRead(ctx, job) {
+		return nil, s.getError(id, domain.ErrJobNotFound)
+	}
+	return toJob(job), nil
+}
+
+// getError maps errors of reading a job to statuses.
+func (s *Server) getError(id uuid.UUID, err error) error {
+	if errors.Is(err, domain.ErrJobNotFound) {
+		return status.Error(codes.NotFound, "job not found")
+	}
+	s.logger.Error("Get job failed", zap.Error(err), zap.String("job_id", id.String()))
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// Watch handles Sentinel.Watch. Like a non-interactive WebSocket stream,
+// it is woken by the status feed when there is one and polls otherwise.
+func (s *Server) Watch(req *sentinelv1.WatchRequest, stream grpc.ServerStreamingServer[sentinelv1.WatchResponse]) error {
+	id, err := uuid.Parse(req.GetJobId())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid job ID format")
+	}
+	ctx := stream.Context()
+
+	// Watching starts before the first read, so no change slips in
+	// between.
+	updates, stopWatch := s.getJobUC.Watch(id)
+	defer stopWatch()
+
+	var poll <-chan time.Time
+	if updates == nil {
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	var seq int64
+	var lastStatus domain.ExecutionStatus
+	for {
+		job, err := s.getJobUC.Execute(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return s.getError(id, err)
+		}
+		if !canRead(ctx, job) {
+			return s.getError(id, domain.ErrJobNotFound)
+		}
+
+		if job.Status != lastStatus {
+			resp := &sentinelv1.WatchResponse{Job: toJob(job)}
+			// The first message carries the job's history, so late
+			// watchers can show the whole lifecycle.
+			if seq == 0 {
+				events, err := s.getJobUC.Timeline(ctx, id)
+				if err != nil {
+					s.logger.Warn("Failed to load job timeline", zap.String("job_id", id.String()), zap.Error(err))
+				}
+				resp.Timeline = toEvents(events)
+			}
+			seq++
+			lastStatus = job.Status
+			resp.Seq = seq
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+		// The job's final state was the last message.
+		if job.Status.IsTerminal() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.closing:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case <-updates:
+		case <-poll:
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+	mockpub "github.com/Harsh-BH/Sentinel/api/internal/publisher/mock"
+	mockrepo "github.com/Harsh-BH/Sentinel/api/internal/repository/mock"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+	sentinelv1 "github.com/Harsh-BH/Sentinel/api/proto/sentinel/v1"
+)
+
+// registryPath is the language registry shipped with the repository.
+const registryPath = "../../../../sandbox/languages.yaml"
+
+type testServer struct {
+	client sentinelv1.SentinelClient
+	repo   *mockrepo.MockJobRepository
+	feed   *mockrepo.MockStatusFeed
+	api    *Server
+	srv    *grpc.Server
+}
+
+// setupTestServer serves a Server, after applying configure to it.
+func setupTestServer(t *testing.T, configure ...func(*Server)) *testServer {
+	t.Helper()
+	reg, err := language.Load(registryPath)
+	if err != nil {
+		t.Fatalf("load language registry: %v", err)
+	}
+	repo := mockrepo.NewMockJobRepository()
+	feed := mockrepo.NewMockStatusFeed()
+	getJobUC := usecase.NewGetJobUsecase(repo, zap.NewNop())
+	getJobUC.SetStatusFeed(feed)
+	submitUC := usecase.NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), reg, zap.NewNop())
+
+	lis := bufconn.Listen(1 << 20)
+	api := NewServer(submitUC, getJobUC, zap.NewNop())
+	for _, c := range configure {
+		c(api)
+	}
+	srv := grpc.NewServer(api.ServerOptions()...)
+	api.Register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testServer{client: sentinelv1.NewSentinelClient(conn), repo: repo, feed: feed, api: api, srv: srv}
+}
+
+func TestSubmitAndGet(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tenantIDKey, "acme")
+
+	resp, err := ts.client.Submit(ctx, &sentinelv1.SubmitRequest{
+		Language:   "python",
+		SourceCode: "print('hi')",
+		Args:       []string{"-v"},
+	})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if resp.Status != sentinelv1.JobStatus_JOB_STATUS_QUEUED {
+		t.Errorf("status = %v, want QUEUED", resp.Status)
+	}
+
+	job, err := ts.client.Get(ctx, &sentinelv1.GetRequest{JobId: resp.JobId})
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if job.TenantId != "acme" || job.SourceCode != "print('hi')" || job.Language != "python" {
+		t.Errorf("job = %v, want acme's python job", job)
+	}
+	if job.Result != nil {
+		t.Errorf("queued job has result %v", job.Result)
+	}
+}
+
+func TestSubmit_Errors(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *sentinelv1.SubmitRequest
+	}{
+		{"empty source", &sentinelv1.SubmitRequest{Language: "python", SourceCode: "  "}},
+		{"unknown language", &sentinelv1.SubmitRequest{Language: "cobol", SourceCode: "x"}},
+		{"bad priority", &sentinelv1.SubmitRequest{Language: "python", SourceCode: "x", Priority: proto.Int32(42)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ts.client.Submit(ctx, tt.req)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("err = %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestGet_Errors(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := context.Background()
+
+	if _, err := ts.client.Get(ctx, &sentinelv1.GetRequest{JobId: "nope"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("malformed id: err = %v, want InvalidArgument", err)
+	}
+	if _, err := ts.client.Get(ctx, &sentinelv1.GetRequest{JobId: uuid.NewString()}); status.Code(err) != codes.NotFound {
+		t.Errorf("unknown id: err = %v, want NotFound", err)
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	for _, s := range []domain.ExecutionStatus{
+		domain.StatusScheduled, domain.StatusQueued, domain.StatusRunning, domain.StatusSuccess,
+		domain.StatusRuleViolation, domain.StatusOutputViolation, domain.StatusSkipped,
+	} {
+		if got := toStatus(s).String(); got != "JOB_STATUS_"+string(s) {
+			t.Errorf("toStatus(%s) = %s", s, got)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := context.Background()
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusQueued}
+	if err := ts.repo.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	_ = ts.repo.UpdateStatus(ctx, job.JobID, domain.StatusRunning)
+
+	stream, err := ts.client.Watch(ctx, &sentinelv1.WatchRequest{JobId: job.JobID.String()})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil || first.Job.Status != sentinelv1.JobStatus_JOB_STATUS_RUNNING || first.Seq != 1 {
+		t.Fatalf("first message = %v, %v; want RUNNING", first, err)
+	}
+	if len(first.Timeline) == 0 {
+		t.Error("first message has no timeline")
+	}
+
+	exitCode := 0
+	_ = ts.repo.SetResult(ctx, job.JobID, &domain.Job{Status: domain.StatusSuccess, Stdout: "hi\n", ExitCode: &exitCode})
+	ts.feed.Notify(job.JobID)
+	second, err := stream.Recv()
+	if err != nil || second.Job.Status != sentinelv1.JobStatus_JOB_STATUS_SUCCESS || second.Seq != 2 {
+		t.Fatalf("second message = %v, %v; want SUCCESS", second, err)
+	}
+	if r := second.Job.Result; r == nil || r.Stdout != "hi\n" || r.ExitCode == nil || *r.ExitCode != 0 {
+		t.Errorf("result = %v, want stdout and exit code", r)
+	}
+	if second.Timeline != nil {
+		t.Error("later message repeats the timeline")
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("after terminal status: err = %v, want EOF", err)
+	}
+}
+
+func TestShutdown_EndsWatch(t *testing.T) {
+	ts := setupTestServer(t)
+	ctx := context.Background()
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusQueued}
+	if err := ts.repo.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := ts.client.Watch(ctx, &sentinelv1.WatchRequest{JobId: job.JobID.String()})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := ts.api.Shutdown(shutdownCtx, ts.srv); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+}
+
+func TestAuth_KeysScopesAndOwnership(t *testing.T) {
+	ctx := context.Background()
+	keys := usecase.NewAPIKeyUsecase(mockrepo.NewMockAPIKeyRepository(), zap.NewNop())
+	ts := setupTestServer(t, func(s *Server) { s.SetAuth(keys, true) })
+	issue := func(tenant string, scopes ...string) context.Context {
+		issued, err := keys.Issue(ctx, &domain.IssueAPIKeyRequest{TenantID: tenant, Scopes: scopes})
+		if err != nil {
+			t.Fatalf("issue: %v", err)
+		}
+		return metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+issued.Key)
+	}
+	alice, bob := issue("acme"), issue("acme")
+	reader, outsider := issue("acme", domain.ScopeReadAny), issue("globex", domain.ScopeReadAny)
+	submit := &sentinelv1.SubmitRequest{Language: "python", SourceCode: "print(1)"}
+
+	if _, err := ts.client.Submit(ctx, submit); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no key: err = %v, want Unauthenticated", err)
+	}
+	wrong := metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer snt_wrong")
+	if _, err := ts.client.Submit(wrong, submit); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("wrong key: err = %v, want Unauthenticated", err)
+	}
+	if _, err := ts.client.Submit(reader, submit); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("key without the submit scope: err = %v, want PermissionDenied", err)
+	}
+
+	// The key's tenant wins over the metadata.
+	resp, err := ts.client.Submit(metadata.AppendToOutgoingContext(alice, tenantIDKey, "globex"), submit)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	get := &sentinelv1.GetRequest{JobId: resp.JobId}
+	if job, err := ts.client.Get(alice, get); err != nil || job.TenantId != "acme" {
+		t.Errorf("own job = %v, %v; want acme's", job, err)
+	}
+	if _, err := ts.client.Get(reader, get); err != nil {
+		t.Errorf("read:any: %v", err)
+	}
+	for name, caller := range map[string]context.Context{"another key": bob, "another tenant": outsider} {
+		if _, err := ts.client.Get(caller, get); status.Code(err) != codes.NotFound {
+			t.Errorf("Get as %s: err = %v, want NotFound", name, err)
+		}
+		stream, err := ts.client.Watch(caller, &sentinelv1.WatchRequest{JobId: resp.JobId})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Watch as %s: err = %v, want NotFound", name, err)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	ts := setupTestServer(t, func(s *Server) { s.SetRateLimit(middleware.LocalRateLimit(2, 0)) })
+	get := &sentinelv1.GetRequest{JobId: uuid.NewString()}
+
+	for i := 0; i < 2; i++ {
+		if _, err := ts.client.Get(context.Background(), get); status.Code(err) != codes.NotFound {
+			t.Fatalf("call %d: err = %v, want NotFound", i+1, err)
+		}
+	}
+	var header metadata.MD
+	_, err := ts.client.Get(context.Background(), get, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("over the limit: err = %v, want ResourceExhausted", err)
+	}
+	if v := header.Get("retry-after"); len(v) != 1 || v[0] == "0" {
+		t.Errorf("retry-after = %v, want a wait", v)
+	}
+	stream, err := ts.client.Watch(context.Background(), &sentinelv1.WatchRequest{JobId: get.JobId})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Watch over the limit: err = %v, want ResourceExhausted", err)
+	}
+}
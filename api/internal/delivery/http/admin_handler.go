@@ -0,0 +1,367 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// AdminHandler handles operator endpoints. Every request must carry the
+// configured admin token as "Authorization: Bearer <token>", or an API key
+// with the admin scope that middleware.Admin authenticated.
+type AdminHandler struct {
+	repairUC *usecase.RepairUsecase
+	getJobUC *usecase.GetJobUsecase
+	keysUC   *usecase.APIKeyUsecase
+	pauseUC  *usecase.QueuePauseUsecase
+	hardenUC *usecase.HardeningUsecase
+	eventsUC *usecase.SecurityEventUsecase
+	cancelUC *usecase.CancelJobUsecase
+	token    string
+	logger   *zap.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler. An empty token rejects every
+// request.
+func NewAdminHandler(repairUC *usecase.RepairUsecase, token string, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		repairUC: repairUC,
+		token:    token,
+		logger:   logger,
+	}
+}
+
+// SetJobs enables listing recent jobs.
+func (h *AdminHandler) SetJobs(getJobUC *usecase.GetJobUsecase) {
+	h.getJobUC = getJobUC
+}
+
+// SetAPIKeys enables issuing, rotating and revoking API keys.
+func (h *AdminHandler) SetAPIKeys(keysUC *usecase.APIKeyUsecase) {
+	h.keysUC = keysUC
+}
+
+// SetQueuePauses enables pausing and resuming languages.
+func (h *AdminHandler) SetQueuePauses(pauseUC *usecase.QueuePauseUsecase) {
+	h.pauseUC = pauseUC
+}
+
+// SetHardening enables checking the sandbox's protections.
+func (h *AdminHandler) SetHardening(hardenUC *usecase.HardeningUsecase) {
+	h.hardenUC = hardenUC
+}
+
+// SetSecurityEvents enables exporting security events.
+func (h *AdminHandler) SetSecurityEvents(eventsUC *usecase.SecurityEventUsecase) {
+	h.eventsUC = eventsUC
+}
+
+// SetCancels enables cancelling queued jobs in bulk.
+func (h *AdminHandler) SetCancels(cancelUC *usecase.CancelJobUsecase) {
+	h.cancelUC = cancelUC
+}
+
+// Repair handles POST /api/v2/admin/repair
+func (h *AdminHandler) Repair(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.RepairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	report, err := h.repairUC.Repair(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRepair) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Repair failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Cancel handles POST /api/v2/admin/cancel
+func (h *AdminHandler) Cancel(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.BulkCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	report, err := h.cancelUC.CancelMatching(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidBulkCancel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Bulk cancel failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Hardening handles POST /api/v2/admin/sandbox/hardening
+func (h *AdminHandler) Hardening(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.HardeningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	report, err := h.hardenUC.Run(c.Request.Context(), &req)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, report)
+	case errors.Is(err, domain.ErrInvalidLanguage), errors.Is(err, domain.ErrInvalidSandboxTier):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		writeQuotaExceeded(c, h.hardenUC.QuotaResetAt(time.Now()), err)
+	case errors.Is(err, domain.ErrPublishFailed):
+		writeQueueUnavailable(c)
+	default:
+		h.logger.Error("Hardening check failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// Jobs handles GET /api/v2/admin/jobs
+func (h *AdminHandler) Jobs(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	var limit int
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.getJobUC.ListRecent(c.Request.Context(), domain.ExecutionStatus(c.Query("status")), c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidJobFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("List jobs failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// SecurityEvents handles GET /api/v2/admin/security-events, answering in
+// SARIF with format=sarif.
+func (h *AdminHandler) SecurityEvents(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "sarif" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format: must be json or sarif"})
+		return
+	}
+	var limit int
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	filter := domain.SecurityEventFilter{Kind: domain.SecurityEventKind(c.Query("kind")), TenantID: c.Query("tenant_id")}
+	page, err := h.eventsUC.List(c.Request.Context(), filter, c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSecurityEventFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("List security events failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if format == "sarif" {
+		c.Header("Content-Type", "application/sarif+json")
+		c.JSON(http.StatusOK, securityEventsSARIF(page))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// IssueKey handles POST /api/v2/admin/api-keys
+func (h *AdminHandler) IssueKey(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	var req domain.IssueAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	key, err := h.keysUC.Issue(c.Request.Context(), &req)
+	if err != nil {
+		h.writeKeyError(c, "Issue API key failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListKeys handles GET /api/v2/admin/api-keys
+func (h *AdminHandler) ListKeys(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	keys, err := h.keysUC.List(c.Request.Context(), c.Query("tenant_id"))
+	if err != nil {
+		h.writeKeyError(c, "List API keys failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// RotateKey handles POST /api/v2/admin/api-keys/:id/rotate
+func (h *AdminHandler) RotateKey(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key ID format"})
+		return
+	}
+
+	key, err := h.keysUC.Rotate(c.Request.Context(), id)
+	if err != nil {
+		h.writeKeyError(c, "Rotate API key failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+// RevokeKey handles DELETE /api/v2/admin/api-keys/:id
+func (h *AdminHandler) RevokeKey(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key ID format"})
+		return
+	}
+
+	if err := h.keysUC.Revoke(c.Request.Context(), id); err != nil {
+		h.writeKeyError(c, "Revoke API key failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// writeKeyError maps errors of managing API keys to responses.
+func (h *AdminHandler) writeKeyError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case errors.Is(err, domain.ErrInvalidAPIKeyRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// ListPauses handles GET /api/v2/admin/queue/pauses
+func (h *AdminHandler) ListPauses(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	pauses, err := h.pauseUC.List(c.Request.Context())
+	if err != nil {
+		h.writePauseError(c, "List paused languages failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"pauses": pauses})
+}
+
+// Pause handles PUT /api/v2/admin/queue/pauses/:language
+func (h *AdminHandler) Pause(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	pause, err := h.pauseUC.Pause(c.Request.Context(), domain.Language(c.Param("language")))
+	if err != nil {
+		h.writePauseError(c, "Pause language failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, pause)
+}
+
+// Resume handles DELETE /api/v2/admin/queue/pauses/:language
+func (h *AdminHandler) Resume(c *gin.Context) {
+	if !h.authorized(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin token required"})
+		return
+	}
+	resume, err := h.pauseUC.Resume(c.Request.Context(), domain.Language(c.Param("language")))
+	if err != nil {
+		h.writePauseError(c, "Resume language failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, resume)
+}
+
+// writePauseError maps errors of pausing languages to responses.
+func (h *AdminHandler) writePauseError(c *gin.Context, msg string, err error) {
+	if errors.Is(err, domain.ErrInvalidLanguage) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Error(msg, zap.Error(err))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
+
+func (h *AdminHandler) authorized(c *gin.Context) bool {
+	if middleware.HasScope(c, domain.ScopeAdmin) {
+		return true
+	}
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return ok && h.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
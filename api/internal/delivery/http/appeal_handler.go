@@ -0,0 +1,171 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// AppealHandler handles HTTP requests for appeals on judged submissions.
+// Tenants open and read their own appeals; reruns, overrides and closing
+// are admin routes, recorded in the audit trail under middleware.Actor.
+type AppealHandler struct {
+	appealUC *usecase.AppealUsecase
+	logger   *zap.Logger
+}
+
+// NewAppealHandler creates a new AppealHandler.
+func NewAppealHandler(appealUC *usecase.AppealUsecase, logger *zap.Logger) *AppealHandler {
+	return &AppealHandler{
+		appealUC: appealUC,
+		logger:   logger,
+	}
+}
+
+// Open handles POST /api/v1/submissions/:id/appeals
+func (h *AppealHandler) Open(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+	var req domain.OpenAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	appeal, err := h.appealUC.Open(c.Request.Context(), jobID, c.GetHeader(tenantIDHeader), &req)
+	if err != nil {
+		h.writeError(c, "Open appeal failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, appeal)
+}
+
+// GetByID handles GET /api/v1/appeals/:id
+func (h *AppealHandler) GetByID(c *gin.Context) {
+	id, ok := h.appealID(c)
+	if !ok {
+		return
+	}
+	appeal, err := h.appealUC.Get(c.Request.Context(), id, appealTenant(c))
+	if err != nil {
+		h.writeError(c, "Get appeal failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, appeal)
+}
+
+// List handles GET /api/v1/appeals?status=open
+func (h *AppealHandler) List(c *gin.Context) {
+	status := domain.AppealStatus(c.DefaultQuery("status", string(domain.AppealOpen)))
+	appeals, err := h.appealUC.List(c.Request.Context(), appealTenant(c), status)
+	if err != nil {
+		h.writeError(c, "List appeals failed", err)
+		return
+	}
+	if appeals == nil {
+		appeals = []*domain.Appeal{}
+	}
+	c.JSON(http.StatusOK, gin.H{"appeals": appeals})
+}
+
+// Rerun handles POST /api/v1/appeals/:id/rerun
+func (h *AppealHandler) Rerun(c *gin.Context) {
+	id, ok := h.appealID(c)
+	if !ok {
+		return
+	}
+	resp, err := h.appealUC.Rerun(c.Request.Context(), id, middleware.Actor(c))
+	if err != nil {
+		h.writeError(c, "Appeal rerun failed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// Override handles POST /api/v1/appeals/:id/override
+func (h *AppealHandler) Override(c *gin.Context) {
+	id, ok := h.appealID(c)
+	if !ok {
+		return
+	}
+	var req domain.OverrideVerdictRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	appeal, err := h.appealUC.Override(c.Request.Context(), id, middleware.Actor(c), &req)
+	if err != nil {
+		h.writeError(c, "Override verdict failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, appeal)
+}
+
+// Close handles POST /api/v1/appeals/:id/close
+func (h *AppealHandler) Close(c *gin.Context) {
+	id, ok := h.appealID(c)
+	if !ok {
+		return
+	}
+	var req domain.CloseAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	appeal, err := h.appealUC.Close(c.Request.Context(), id, middleware.Actor(c), &req)
+	if err != nil {
+		h.writeError(c, "Close appeal failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, appeal)
+}
+
+// appealTenant is the tenant whose appeals the request may read: its own,
+// or every tenant's ("") for an admin.
+func appealTenant(c *gin.Context) string {
+	if middleware.IsAdmin(c) {
+		return ""
+	}
+	if tenant := c.GetHeader(tenantIDHeader); tenant != "" {
+		return tenant
+	}
+	return domain.DefaultTenantID
+}
+
+func (h *AppealHandler) appealID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appeal ID format"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *AppealHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAppealNotFound), errors.Is(err, domain.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrAppealExists), errors.Is(err, domain.ErrAppealClosed),
+		errors.Is(err, domain.ErrNotAppealable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidAppeal):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPublishFailed):
+		writeQueueUnavailable(c)
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
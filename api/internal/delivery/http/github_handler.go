@@ -0,0 +1,110 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// GitHubHandler handles the GitHub submission intake: tenants' repository
+// mappings and the webhook the repositories deliver to. Mappings are scoped
+// to the tenant from the X-Tenant-ID header; deliveries are authenticated by
+// their signature instead.
+type GitHubHandler struct {
+	githubUC *usecase.GitHubUsecase
+	logger   *zap.Logger
+}
+
+// NewGitHubHandler creates a new GitHubHandler.
+func NewGitHubHandler(githubUC *usecase.GitHubUsecase, logger *zap.Logger) *GitHubHandler {
+	return &GitHubHandler{
+		githubUC: githubUC,
+		logger:   logger,
+	}
+}
+
+// SetRepo handles PUT /api/v2/integrations/github/repos/:owner/:name
+func (h *GitHubHandler) SetRepo(c *gin.Context) {
+	var req domain.SetGitHubRepoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	repo, err := h.githubUC.SetRepo(c.Request.Context(), c.GetHeader(tenantIDHeader), repoParam(c), &req)
+	if err != nil {
+		h.writeError(c, "Set GitHub repository failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, repo)
+}
+
+// GetRepo handles GET /api/v2/integrations/github/repos/:owner/:name
+func (h *GitHubHandler) GetRepo(c *gin.Context) {
+	repo, err := h.githubUC.GetRepo(c.Request.Context(), c.GetHeader(tenantIDHeader), repoParam(c))
+	if err != nil {
+		h.writeError(c, "Get GitHub repository failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, repo)
+}
+
+// DeleteRepo handles DELETE /api/v2/integrations/github/repos/:owner/:name
+func (h *GitHubHandler) DeleteRepo(c *gin.Context) {
+	if err := h.githubUC.DeleteRepo(c.Request.Context(), c.GetHeader(tenantIDHeader), repoParam(c)); err != nil {
+		h.writeError(c, "Delete GitHub repository failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Webhook handles POST /api/v2/integrations/github/webhook
+func (h *GitHubHandler) Webhook(c *gin.Context) {
+	// The body limit middleware caps the payload, as for any request.
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload too large"})
+		return
+	}
+
+	delivery, err := h.githubUC.HandleEvent(c.Request.Context(),
+		c.GetHeader("X-GitHub-Event"), c.GetHeader("X-Hub-Signature-256"), body)
+	if err != nil {
+		h.writeError(c, "GitHub delivery failed", err)
+		return
+	}
+	if delivery.JobID != nil {
+		c.JSON(http.StatusAccepted, delivery)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+func (h *GitHubHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrGitHubRepoNotFound), errors.Is(err, domain.ErrProblemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrGitHubRepoTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidGitHubRepo), errors.Is(err, domain.ErrInvalidLanguage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPublishFailed):
+		writeQueueUnavailable(c)
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// repoParam joins the :owner and :name path parameters into a full name.
+func repoParam(c *gin.Context) string {
+	return c.Param("owner") + "/" + c.Param("name")
+}
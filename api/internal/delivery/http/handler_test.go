@@ -2,30 +2,71 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/Harsh-BH/Sentinel/api/internal/apiversion"
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
 	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	mockgh "github.com/Harsh-BH/Sentinel/api/internal/github/mock"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+	mocklti "github.com/Harsh-BH/Sentinel/api/internal/lti/mock"
+	"github.com/Harsh-BH/Sentinel/api/internal/oidc"
 	mockpub "github.com/Harsh-BH/Sentinel/api/internal/publisher/mock"
 	mockrepo "github.com/Harsh-BH/Sentinel/api/internal/repository/mock"
 	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
 )
 
+// registryPath is the language registry shipped with the repository.
+const registryPath = "../../../../sandbox/languages.yaml"
+
+func testLanguages(t testing.TB) *language.Registry {
+	t.Helper()
+	reg, err := language.Load(registryPath)
+	if err != nil {
+		t.Fatalf("load language registry: %v", err)
+	}
+	return reg
+}
+
 func init() {
 	gin.SetMode(gin.TestMode)
 }
 
-func setupTestRouter() (*gin.Engine, *mockrepo.MockJobRepository, *mockpub.MockPublisher) {
+func setupTestRouter(t testing.TB) (*gin.Engine, *mockrepo.MockJobRepository, *mockpub.MockPublisher) {
 	repo := mockrepo.NewMockJobRepository()
 	pub := mockpub.NewMockPublisher()
 	logger := zap.NewNop()
 
-	submitUC := usecase.NewSubmitJobUsecase(repo, pub, logger)
+	submitUC := usecase.NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 	getJobUC := usecase.NewGetJobUsecase(repo, logger)
 
 	router := gin.New()
@@ -33,12 +74,18 @@ func setupTestRouter() (*gin.Engine, *mockrepo.MockJobRepository, *mockpub.MockP
 
 	router.POST("/api/v1/submissions", subHandler.Submit)
 	router.GET("/api/v1/submissions/:id", subHandler.GetByID)
+	router.GET("/api/v2/submissions/:id/stdout", subHandler.Stdout)
+	router.GET("/api/v2/submissions/:id/stderr", subHandler.Stderr)
+	router.GET("/api/v2/submissions/:id/artifacts/:name", subHandler.Artifact)
+	router.POST("/api/v2/submissions/:id/rerun", subHandler.Rerun)
+	router.GET("/api/v2/submissions/:id/lineage", subHandler.Lineage)
+	router.GET("/api/v2/submissions/by-external-id/:id", subHandler.GetByExternalID)
 
 	return router, repo, pub
 }
 
 func TestSubmitHandler_Success(t *testing.T) {
-	router, _, pub := setupTestRouter()
+	router, _, pub := setupTestRouter(t)
 
 	body := map[string]interface{}{
 		"language":    "python",
@@ -70,7 +117,7 @@ func TestSubmitHandler_Success(t *testing.T) {
 }
 
 func TestSubmitHandler_InvalidLanguage(t *testing.T) {
-	router, _, _ := setupTestRouter()
+	router, _, _ := setupTestRouter(t)
 
 	body := map[string]interface{}{
 		"language":    "ruby",
@@ -89,8 +136,50 @@ func TestSubmitHandler_InvalidLanguage(t *testing.T) {
 	}
 }
 
+func TestSubmitHandler_InvalidCompilerFlags(t *testing.T) {
+	router, _, _ := setupTestRouter(t)
+
+	body := map[string]interface{}{
+		"language":       "cpp",
+		"source_code":    "int main() {}",
+		"compiler_flags": []string{"-O0 -fplugin=x.so"},
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSubmitHandler_InteractiveUnavailable(t *testing.T) {
+	router, _, _ := setupTestRouter(t)
+
+	body := map[string]interface{}{
+		"language":    "python",
+		"source_code": "print(input())",
+		"interactive": true,
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestSubmitHandler_EmptyBody(t *testing.T) {
-	router, _, _ := setupTestRouter()
+	router, _, _ := setupTestRouter(t)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions", bytes.NewBuffer([]byte("{}")))
 	req.Header.Set("Content-Type", "application/json")
@@ -104,7 +193,7 @@ func TestSubmitHandler_EmptyBody(t *testing.T) {
 }
 
 func TestSubmitHandler_MissingSourceCode(t *testing.T) {
-	router, _, _ := setupTestRouter()
+	router, _, _ := setupTestRouter(t)
 
 	body := map[string]interface{}{
 		"language": "python",
@@ -123,7 +212,7 @@ func TestSubmitHandler_MissingSourceCode(t *testing.T) {
 }
 
 func TestGetByIDHandler_Success(t *testing.T) {
-	router, _, _ := setupTestRouter()
+	router, _, _ := setupTestRouter(t)
 
 	// First submit a job
 	body := map[string]interface{}{
@@ -161,8 +250,222 @@ func TestGetByIDHandler_Success(t *testing.T) {
 	}
 }
 
+func TestGetByIDHandler_SparseFields(t *testing.T) {
+	router, repo, _ := setupTestRouter(t)
+
+	var fields []string
+	repo.GetFieldsFunc = func(ctx context.Context, id uuid.UUID, f []string) (*domain.Job, error) {
+		fields = f
+		return &domain.Job{JobID: id, Status: domain.StatusSuccess, Stdout: "hi\n", SourceCode: "print('hi')", TimeLimitMs: 5000}, nil
+	}
+	id := uuid.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/submissions/"+id.String()+"?fields=status,stdout,time_used_ms", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if want := []string{"job_id", "status", "stdout", "time_used_ms"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("repository asked for %v, want %v", fields, want)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	// time_used_ms is unset, so it is omitted like in the full response.
+	want := map[string]any{"job_id": id.String(), "status": "SUCCESS", "stdout": "hi\n"}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("body = %v, want %v", body, want)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/submissions/"+id.String()+"?fields=status,debug", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSubmissionHandler_Base64Encoded(t *testing.T) {
+	router, repo, pub := setupTestRouter(t)
+	b64 := base64.StdEncoding.EncodeToString
+
+	body, _ := json.Marshal(map[string]any{
+		"language":        "python",
+		"source_code":     b64([]byte("print(input())\n")),
+		"stdin":           b64([]byte("caf\u00e9\r\n\t")),
+		"expected_output": b64([]byte("caf\u00e9\n")),
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions?base64_encoded=true", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	job := pub.Published[0]
+	if job.SourceCode != "print(input())\n" || job.Stdin != "caf\u00e9\r\n\t" || *job.ExpectedOutput != "caf\u00e9\n" {
+		t.Errorf("queued source %q, stdin %q, expected output %q; want them decoded", job.SourceCode, job.Stdin, *job.ExpectedOutput)
+	}
+
+	for name, fields := range map[string]map[string]any{
+		"not base64":  {"language": "python", "source_code": "print(1)"},
+		"not UTF-8":   {"language": "python", "source_code": b64([]byte("print(1)")), "stdin": b64([]byte{0xff, 0xfe})},
+		"NUL byte":    {"language": "python", "source_code": b64([]byte("print(1)")), "stdin": b64([]byte("a\x00b"))},
+		"bad boolean": {"language": "python", "source_code": "print(1)"},
+	} {
+		query := "?base64_encoded=true"
+		if name == "bad boolean" {
+			query = "?base64_encoded=maybe"
+		}
+		body, _ := json.Marshal(fields)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions"+query, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	id := uuid.New()
+	expected := "hi\n"
+	repo.Create(context.Background(), &domain.Job{
+		JobID:          id,
+		Status:         domain.StatusWrongAnswer,
+		SourceCode:     "print('hi')",
+		Stdout:         "hi\r\n",
+		ExpectedOutput: &expected,
+		TestResults:    []domain.TestCaseResult{{Ordinal: 1, Output: "hi\r\n"}},
+	})
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/submissions/"+id.String()+"?base64_encoded=true&fields=stdout,stderr,expected_output,test_results", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got struct {
+		Stdout         string                  `json:"stdout"`
+		Stderr         *string                 `json:"stderr"`
+		ExpectedOutput string                  `json:"expected_output"`
+		TestResults    []domain.TestCaseResult `json:"test_results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	if got.Stdout != b64([]byte("hi\r\n")) || got.ExpectedOutput != b64([]byte(expected)) || got.TestResults[0].Output != b64([]byte("hi\r\n")) {
+		t.Errorf("body = %s, want base64-encoded stdout, expected output and test case output", w.Body.String())
+	}
+	if got.Stderr != nil {
+		t.Errorf("empty stderr answered as %q, want it omitted", *got.Stderr)
+	}
+
+	// The stored job is not changed by encoding a response.
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/submissions/"+id.String(), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var plain domain.Job
+	json.Unmarshal(w.Body.Bytes(), &plain)
+	if plain.Stdout != "hi\r\n" || plain.TestResults[0].Output != "hi\r\n" {
+		t.Errorf("plain read after an encoded one: stdout %q, test output %q", plain.Stdout, plain.TestResults[0].Output)
+	}
+}
+
+func TestOutputHandler_RangeRequests(t *testing.T) {
+	router, repo, _ := setupTestRouter(t)
+	id := uuid.New()
+	repo.Create(context.Background(), &domain.Job{
+		JobID:     id,
+		Status:    domain.StatusSuccess,
+		Stdout:    "0123456789abcdef",
+		Stderr:    "warning: unused",
+		UpdatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+id.String()+"/stdout", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789abcdef" {
+		t.Fatalf("full stdout: got %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if w.Header().Get("Accept-Ranges") != "bytes" || w.Header().Get("Last-Modified") == "" {
+		t.Errorf("expected Accept-Ranges and Last-Modified, got %v", w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+id.String()+"/stdout", nil)
+	req.Header.Set("Range", "bytes=10-")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "abcdef" || w.Header().Get("Content-Range") != "bytes 10-15/16" {
+		t.Errorf("range: got %d %q (Content-Range %q)", w.Code, w.Body.String(), w.Header().Get("Content-Range"))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+id.String()+"/stderr", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "warning: unused" {
+		t.Errorf("stderr: got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+uuid.NewString()+"/stdout", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: expected 404, got %d", w.Code)
+	}
+}
+
+func TestArtifactHandler(t *testing.T) {
+	router, repo, _ := setupTestRouter(t)
+	id := uuid.New()
+	repo.Create(context.Background(), &domain.Job{JobID: id, Status: domain.StatusSuccess, OutputFiles: []string{"out.csv", "plot.png"}})
+	repo.Artifacts[id] = map[string]*domain.Artifact{
+		"out.csv":  {Name: "out.csv", Content: []byte("a,b\n1,2\n"), SizeBytes: 8},
+		"plot.png": {Name: "plot.png", Content: []byte("\x89PNG"), SizeBytes: 4 << 20, Truncated: true},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+id.String()+"/artifacts/out.csv", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "a,b\n1,2\n" {
+		t.Fatalf("artifact: got %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename=out.csv` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if w.Header().Get("X-Artifact-Truncated") != "" {
+		t.Error("complete artifact marked truncated")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+id.String()+"/artifacts/plot.png", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Header().Get("X-Artifact-Truncated") != "true" || w.Header().Get("X-Artifact-Size") != "4194304" {
+		t.Errorf("truncated artifact headers: %v", w.Header())
+	}
+
+	for path, want := range map[string]int{
+		"/api/v2/submissions/" + id.String() + "/artifacts/missing.txt":  http.StatusNotFound,
+		"/api/v2/submissions/" + uuid.NewString() + "/artifacts/out.csv": http.StatusNotFound,
+		"/api/v2/submissions/not-a-uuid/artifacts/out.csv":               http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
 func TestGetByIDHandler_NotFound(t *testing.T) {
-	router, _, _ := setupTestRouter()
+	router, _, _ := setupTestRouter(t)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/submissions/00000000-0000-0000-0000-000000000001", nil)
 	w := httptest.NewRecorder()
@@ -174,7 +477,7 @@ func TestGetByIDHandler_NotFound(t *testing.T) {
 }
 
 func TestGetByIDHandler_InvalidUUID(t *testing.T) {
-	router, _, _ := setupTestRouter()
+	router, _, _ := setupTestRouter(t)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/submissions/not-a-uuid", nil)
 	w := httptest.NewRecorder()
@@ -186,7 +489,7 @@ func TestGetByIDHandler_InvalidUUID(t *testing.T) {
 }
 
 func TestSubmitHandler_CppLanguage(t *testing.T) {
-	router, repo, _ := setupTestRouter()
+	router, repo, _ := setupTestRouter(t)
 
 	body := map[string]interface{}{
 		"language":    "cpp",
@@ -214,8 +517,36 @@ func TestSubmitHandler_CppLanguage(t *testing.T) {
 	}
 }
 
+func TestSubmitHandler_GoLanguage(t *testing.T) {
+	router, repo, _ := setupTestRouter(t)
+
+	body := map[string]interface{}{
+		"language":    "go",
+		"source_code": "package main\n\nfunc main() {}",
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	jobs := repo.GetAll()
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	if jobs[0].Language != domain.LangGo {
+		t.Errorf("expected language go, got %s", jobs[0].Language)
+	}
+}
+
 func TestLanguageHandler(t *testing.T) {
-	handler := NewLanguageHandler()
+	handler := NewLanguageHandler(testLanguages(t))
 
 	router := gin.New()
 	router.GET("/api/v1/languages", handler.List)
@@ -233,7 +564,2244 @@ func TestLanguageHandler(t *testing.T) {
 		t.Fatalf("failed to unmarshal: %v", err)
 	}
 	languages := resp["languages"]
-	if len(languages) != 2 {
-		t.Errorf("expected 2 languages, got %d", len(languages))
+	if len(languages) != 5 {
+		t.Errorf("expected 5 languages, got %d", len(languages))
+	}
+	for _, l := range languages {
+		if l.SupportsCompileArgs != (l.Name == domain.LangCpp) {
+			t.Errorf("%s: supports_compile_args = %v", l.Name, l.SupportsCompileArgs)
+		}
+		if l.SupportsMultiFile || l.MaxTimeMs != 30000 || l.MaxMemoryKB != 524288 {
+			t.Errorf("%s: unexpected capabilities %+v", l.Name, l)
+		}
+		if len(l.Versions) != 1 || l.Versions[0] != l.Version {
+			t.Errorf("%s: versions = %v, want [%s]", l.Name, l.Versions, l.Version)
+		}
+	}
+}
+
+func TestSubmitHandler_QuotaExceeded(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	quota := mockrepo.NewMockQuotaStore()
+	quota.SetUsage("acme", 2*time.Hour)
+
+	submitUC := usecase.NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	submitUC.SetQuota(quota, time.Hour)
+	subHandler := NewSubmissionHandler(submitUC, usecase.NewGetJobUsecase(repo, zap.NewNop()), zap.NewNop())
+
+	router := gin.New()
+	router.POST("/api/v1/submissions", subHandler.Submit)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"language":    "python",
+		"source_code": "print('hello')",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", "acme")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header on quota rejection")
+	}
+	if w.Header().Get("X-Quota-Reset") == "" {
+		t.Error("expected X-Quota-Reset header on quota rejection")
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if resp.Reason != middleware.RetryQuotaExceeded || resp.RetryAfterMs <= 0 {
+		t.Errorf("expected a quota-exceeded retry hint, got %+v", resp)
+	}
+}
+
+func TestProblemHandler_CreateUpdateRejudge(t *testing.T) {
+	problems := mockrepo.NewMockProblemRepository()
+	problemUC := usecase.NewProblemUsecase(problems, mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	h := NewProblemHandler(problemUC, zap.NewNop())
+
+	router := gin.New()
+	router.POST("/api/v1/problems", h.Create)
+	router.PUT("/api/v1/problems/:id/testdata", h.UpdateTestData)
+	router.POST("/api/v1/problems/:id/rejudge", h.Rejudge)
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	cases := []map[string]string{{"input": "1\n", "expected_output": "1\n"}}
+	if w := do(http.MethodPost, "/api/v1/problems", map[string]interface{}{"problem_id": "echo", "test_cases": cases}); w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/v1/problems", map[string]interface{}{"problem_id": "echo", "test_cases": cases}); w.Code != http.StatusConflict {
+		t.Errorf("duplicate create: expected 409, got %d", w.Code)
+	}
+
+	w := do(http.MethodPut, "/api/v1/problems/echo/testdata", map[string]interface{}{"test_cases": cases})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var problem domain.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if problem.TestDataVersion != 2 {
+		t.Errorf("expected test data version 2, got %d", problem.TestDataVersion)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/problems/echo/rejudge", nil); w.Code != http.StatusAccepted {
+		t.Errorf("rejudge: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/v1/problems/missing/rejudge", nil); w.Code != http.StatusNotFound {
+		t.Errorf("rejudge unknown problem: expected 404, got %d", w.Code)
+	}
+}
+
+func TestRuntimeHandler_CRUD(t *testing.T) {
+	runtimeUC := usecase.NewRuntimeUsecase(mockrepo.NewMockRuntimeRepository(), testLanguages(t), zap.NewNop())
+	runtimeUC.SetMountRoots([]string{"/opt/runtimes"})
+	h := NewRuntimeHandler(runtimeUC, zap.NewNop())
+
+	router := gin.New()
+	router.POST("/api/v1/runtimes", h.Create)
+	router.GET("/api/v1/runtimes", h.List)
+	router.GET("/api/v1/runtimes/:name", h.GetByName)
+	router.PUT("/api/v1/runtimes/:name", h.Update)
+	router.DELETE("/api/v1/runtimes/:name", h.Delete)
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	spec := map[string]interface{}{
+		"source_file":   "main.lua",
+		"nsjail_config": "python.cfg",
+		"run":           []string{"/opt/lua/bin/lua", "{source}"},
+		"mounts":        []map[string]string{{"source": "/opt/runtimes/lua", "target": "/opt/lua"}},
+	}
+	create := map[string]interface{}{"name": "lua"}
+	for k, v := range spec {
+		create[k] = v
+	}
+	if w := do(http.MethodPost, "/api/v1/runtimes", create); w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/v1/runtimes", create); w.Code != http.StatusConflict {
+		t.Errorf("duplicate create: expected 409, got %d", w.Code)
+	}
+	create["name"] = "python"
+	if w := do(http.MethodPost, "/api/v1/runtimes", create); w.Code != http.StatusBadRequest {
+		t.Errorf("built-in name: expected 400, got %d", w.Code)
+	}
+
+	spec["version"] = "5.4"
+	w := do(http.MethodPut, "/api/v1/runtimes/lua", spec)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var rt domain.Runtime
+	if err := json.Unmarshal(w.Body.Bytes(), &rt); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if rt.Name != "lua" || rt.Version != "5.4" || len(rt.Mounts) != 1 {
+		t.Errorf("unexpected runtime after update: %+v", rt)
+	}
+
+	if w := do(http.MethodGet, "/api/v1/runtimes", nil); w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"name":"lua"`)) {
+		t.Errorf("list: expected lua, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/api/v1/runtimes/lua", nil); w.Code != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/runtimes/lua", nil); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted runtime: expected 404, got %d", w.Code)
+	}
+}
+
+func TestSubmissionHandler_Rerun(t *testing.T) {
+	router, repo, pub := setupTestRouter(t)
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, SourceCode: "print(1)", Status: domain.StatusInternalError, TimeLimitMs: 5000}
+	if err := repo.Create(context.Background(), job); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	rerun := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v2/submissions/"+id+"/rerun", nil))
+		return w
+	}
+
+	w := rerun(job.JobID.String())
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp domain.SubmitResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.JobID == job.JobID || len(pub.Published) != 1 || pub.Published[0].JobID != resp.JobID {
+		t.Errorf("expected a new job to be published, got %+v", resp)
+	}
+
+	if w := rerun(uuid.NewString()); w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: expected 404, got %d", w.Code)
+	}
+	if w := rerun("not-a-uuid"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id: expected 400, got %d", w.Code)
+	}
+}
+
+func TestSubmissionHandler_Lineage(t *testing.T) {
+	router, repo, _ := setupTestRouter(t)
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, SourceCode: "print(1)", Status: domain.StatusInternalError, TimeLimitMs: 5000}
+	if err := repo.Create(context.Background(), job); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v2/submissions/"+job.JobID.String()+"/rerun")
+	var rerun domain.SubmitResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &rerun); err != nil {
+		t.Fatalf("failed to unmarshal rerun: %v", err)
+	}
+
+	w = do(http.MethodGet, "/api/v2/submissions/"+rerun.JobID.String()+"/lineage")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var lineage domain.Lineage
+	if err := json.Unmarshal(w.Body.Bytes(), &lineage); err != nil {
+		t.Fatalf("failed to unmarshal lineage: %v", err)
+	}
+	if len(lineage.Chain) != 2 || lineage.Chain[0].JobID != job.JobID || lineage.Chain[1].Origin != domain.OriginResubmit {
+		t.Errorf("chain = %+v, want the original then the resubmission", lineage.Chain)
+	}
+
+	if w := do(http.MethodGet, "/api/v2/submissions/"+uuid.NewString()+"/lineage"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/submissions/not-a-uuid/lineage"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id: expected 400, got %d", w.Code)
+	}
+}
+
+func TestSubmissionHandler_GetByExternalID(t *testing.T) {
+	router, _, _ := setupTestRouter(t)
+	do := func(method, path, tenant, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set(tenantIDHeader, tenant)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	const submission = `{"language": "python", "source_code": "print(1)", "external_id": "attempt-42"}`
+
+	w := do(http.MethodPost, "/api/v1/submissions", "acme", submission)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("submit: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp domain.SubmitResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	w = do(http.MethodGet, "/api/v2/submissions/by-external-id/attempt-42", "acme", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var job domain.Job
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("failed to unmarshal job: %v", err)
+	}
+	if job.JobID != resp.JobID || job.ExternalID != "attempt-42" {
+		t.Errorf("job = %s with external_id %q, want %s", job.JobID, job.ExternalID, resp.JobID)
+	}
+
+	if w := do(http.MethodPost, "/api/v1/submissions", "acme", submission); w.Code != http.StatusConflict {
+		t.Errorf("duplicate: expected 409, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v1/submissions", "", submission); w.Code != http.StatusAccepted {
+		t.Errorf("another tenant: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/v2/submissions/by-external-id/attempt-42", "globex", ""); w.Code != http.StatusNotFound {
+		t.Errorf("other tenant's lookup: expected 404, got %d", w.Code)
+	}
+	bad := `{"language": "python", "source_code": "print(1)", "external_id": "has space"}`
+	if w := do(http.MethodPost, "/api/v1/submissions", "acme", bad); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid external_id: expected 400, got %d", w.Code)
+	}
+}
+
+func TestSubmissionHandler_Cancel(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	h := NewSubmissionHandler(nil, usecase.NewGetJobUsecase(jobs, zap.NewNop()), zap.NewNop())
+	h.SetCancel(usecase.NewCancelJobUsecase(jobs, mockrepo.NewMockCancelSignal(), zap.NewNop()))
+
+	router := gin.New()
+	router.POST("/api/v2/submissions/:id/cancel", h.Cancel)
+	cancel := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v2/submissions/"+id+"/cancel", nil))
+		return w
+	}
+
+	queued := &domain.Job{JobID: uuid.New(), Status: domain.StatusQueued}
+	running := &domain.Job{JobID: uuid.New(), Status: domain.StatusRunning}
+	for _, job := range []*domain.Job{queued, running} {
+		if err := jobs.Create(context.Background(), job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+	}
+
+	if w := cancel(queued.JobID.String()); w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"CANCELLED"`)) {
+		t.Errorf("queued job: expected 200 CANCELLED, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := cancel(running.JobID.String()); w.Code != http.StatusAccepted {
+		t.Errorf("running job: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := cancel(queued.JobID.String()); w.Code != http.StatusConflict {
+		t.Errorf("cancelled job: expected 409, got %d", w.Code)
+	}
+	if w := cancel(uuid.NewString()); w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: expected 404, got %d", w.Code)
+	}
+	if w := cancel("not-a-uuid"); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id: expected 400, got %d", w.Code)
+	}
+}
+
+func TestWebhookHandler_DLQ(t *testing.T) {
+	webhookUC := usecase.NewWebhookUsecase(mockrepo.NewMockWebhookRepository(), zap.NewNop())
+	h := NewWebhookHandler(webhookUC, zap.NewNop())
+
+	router := gin.New()
+	router.PUT("/api/v2/webhooks/dlq", h.SetDLQ)
+	router.GET("/api/v2/webhooks/dlq", h.GetDLQ)
+	router.DELETE("/api/v2/webhooks/dlq", h.DeleteDLQ)
+
+	do := func(method, tenant string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, "/api/v2/webhooks/dlq", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(tenantIDHeader, tenant)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPut, "acme", map[string]string{"url": "ftp://example.com/hook"}); w.Code != http.StatusBadRequest {
+		t.Errorf("non-http url: expected 400, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "acme", map[string]string{"url": "https://example.com/hook", "secret": "short"}); w.Code != http.StatusBadRequest {
+		t.Errorf("short secret: expected 400, got %d", w.Code)
+	}
+
+	w := do(http.MethodPut, "acme", map[string]string{"url": "https://example.com/hook"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var hook domain.DLQWebhook
+	if err := json.Unmarshal(w.Body.Bytes(), &hook); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if hook.TenantID != "acme" || len(hook.Secret) != 64 {
+		t.Errorf("expected acme's webhook with a generated secret, got %+v", hook)
+	}
+
+	w = do(http.MethodGet, "acme", nil)
+	if w.Code != http.StatusOK || bytes.Contains(w.Body.Bytes(), []byte("secret")) {
+		t.Errorf("get: expected 200 without the secret, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "other", nil); w.Code != http.StatusNotFound {
+		t.Errorf("other tenant: expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "acme", nil); w.Code != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "acme", nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete again: expected 404, got %d", w.Code)
+	}
+}
+
+func TestWebhookHandler_ResultDeliveries(t *testing.T) {
+	hooks := mockrepo.NewMockWebhookRepository()
+	h := NewWebhookHandler(usecase.NewWebhookUsecase(hooks, zap.NewNop()), zap.NewNop())
+
+	router := gin.New()
+	router.PUT("/api/v2/webhooks/results", h.SetResults)
+	router.GET("/api/v2/webhooks/results/deliveries", h.ListDeliveries)
+	router.GET("/api/v2/webhooks/results/deliveries/:id", h.GetDelivery)
+	router.POST("/api/v2/webhooks/results/deliveries/:id/redrive", h.RedriveDelivery)
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(tenantIDHeader, "acme")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPut, "/api/v2/webhooks/results", map[string]string{"url": "https://example.com/results"}); w.Code != http.StatusOK {
+		t.Fatalf("set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	delivery := hooks.RecordDelivery(&domain.Job{JobID: uuid.New(), TenantID: "acme", Status: domain.StatusAccepted})
+	path := fmt.Sprintf("/api/v2/webhooks/results/deliveries/%d", delivery.ID)
+
+	w := do(http.MethodGet, "/api/v2/webhooks/results/deliveries?status=pending", nil)
+	var page domain.DeliveryPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || w.Code != http.StatusOK || len(page.Deliveries) != 1 {
+		t.Errorf("list: expected the pending delivery, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/v2/webhooks/results/deliveries?status=lost", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown status: expected 400, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, path, nil); w.Code != http.StatusOK {
+		t.Errorf("get: expected 200, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/webhooks/results/deliveries/abc", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id: expected 400, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/webhooks/results/deliveries/999", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown id: expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, path+"/redrive", nil); w.Code != http.StatusConflict {
+		t.Errorf("redrive pending: expected 409, got %d", w.Code)
+	}
+}
+
+func TestScheduleHandler(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	submitUC := usecase.NewSubmitJobUsecase(jobs, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	h := NewScheduleHandler(usecase.NewScheduleUsecase(mockrepo.NewMockScheduleRepository(jobs), submitUC, zap.NewNop()), zap.NewNop())
+
+	router := gin.New()
+	router.POST("/api/v2/schedules", h.Create)
+	router.GET("/api/v2/schedules", h.List)
+	router.GET("/api/v2/schedules/:id", h.GetByID)
+	router.DELETE("/api/v2/schedules/:id", h.Delete)
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(tenantIDHeader, "acme")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	job := map[string]string{"language": "python", "source_code": "print(1)"}
+
+	if w := do(http.MethodPost, "/api/v2/schedules", map[string]interface{}{"cron": "every day", "job": job}); w.Code != http.StatusBadRequest {
+		t.Errorf("bad cron: expected 400, got %d", w.Code)
+	}
+	w := do(http.MethodPost, "/api/v2/schedules", map[string]interface{}{"name": "hourly", "cron": "@hourly", "job": job})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var s domain.Schedule
+	if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+		t.Fatalf("failed to unmarshal schedule: %v", err)
+	}
+	if s.TenantID != "acme" || s.Job.SourceCode != "print(1)" || s.NextRunAt.Minute() != 0 {
+		t.Errorf("schedule = %+v, want acme's hourly template", s)
+	}
+
+	w = do(http.MethodGet, "/api/v2/schedules", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), s.ScheduleID.String()) {
+		t.Errorf("list: expected the schedule, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/v2/schedules/"+s.ScheduleID.String(), nil); w.Code != http.StatusOK {
+		t.Errorf("get: expected 200, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/schedules/not-a-uuid", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id: expected 400, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/api/v2/schedules/"+s.ScheduleID.String(), nil); w.Code != http.StatusNoContent {
+		t.Errorf("delete: expected 204, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/schedules/"+s.ScheduleID.String(), nil); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: expected 404, got %d", w.Code)
+	}
+}
+
+func TestGitHubHandler_RepoAndWebhook(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	submitUC := usecase.NewSubmitJobUsecase(jobs, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	client := mockgh.NewMockClient()
+	githubUC := usecase.NewGitHubUsecase(mockrepo.NewMockGitHubRepository(jobs), submitUC, client, "", zap.NewNop())
+	h := NewGitHubHandler(githubUC, zap.NewNop())
+
+	router := gin.New()
+	router.PUT("/api/v2/integrations/github/repos/:owner/:name", h.SetRepo)
+	router.GET("/api/v2/integrations/github/repos/:owner/:name", h.GetRepo)
+	router.POST("/api/v2/integrations/github/webhook", h.Webhook)
+
+	do := func(method, path, tenant string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(tenantIDHeader, tenant)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	const repoPath = "/api/v2/integrations/github/repos/octo/hello"
+	mapping := map[string]string{"language": "python", "path": "main.py", "token": "ghp_token"}
+
+	w := do(http.MethodPut, repoPath, "acme", mapping)
+	if w.Code != http.StatusOK {
+		t.Fatalf("set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var repo domain.GitHubRepo
+	if err := json.Unmarshal(w.Body.Bytes(), &repo); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if repo.Repo != "octo/hello" || bytes.Contains(w.Body.Bytes(), []byte("ghp_token")) {
+		t.Errorf("expected the mapping without its token, got %s", w.Body.String())
+	}
+	if w := do(http.MethodPut, repoPath, "other", mapping); w.Code != http.StatusConflict {
+		t.Errorf("another tenant's repository: expected 409, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, repoPath, "acme", nil); w.Code != http.StatusOK || bytes.Contains(w.Body.Bytes(), []byte("secret")) {
+		t.Errorf("get: expected 200 without the secret, got %d: %s", w.Code, w.Body.String())
+	}
+
+	deliver := func(signature, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/integrations/github/webhook", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", signature)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	const sha = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	client.Files["main.py@"+sha] = "print(42)"
+	body := `{"repository":{"full_name":"octo/hello"},"after":"` + sha + `"}`
+	if w := deliver("sha256=00", body); w.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: expected 401, got %d", w.Code)
+	}
+	mac := hmac.New(sha256.New, []byte(repo.Secret))
+	mac.Write([]byte(body))
+	if w := deliver("sha256="+hex.EncodeToString(mac.Sum(nil)), body); w.Code != http.StatusAccepted || !bytes.Contains(w.Body.Bytes(), []byte(`"job_id"`)) {
+		t.Errorf("signed push: expected 202 with a job, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func newTestLTIUsecase(t *testing.T, jobs *mockrepo.MockJobRepository, submitUC *usecase.SubmitJobUsecase, appURL string) *usecase.LTIUsecase {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return usecase.NewLTIUsecase(mockrepo.NewMockLTIRepository(jobs), mockrepo.NewMockLTILoginStore(), submitUC,
+		mocklti.NewMockClient(), key, "https://sentinel.example", appURL, time.Hour, zap.NewNop())
+}
+
+func TestLTIHandler_PlatformsAndLaunch(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	submitUC := usecase.NewSubmitJobUsecase(jobs, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	h := NewLTIHandler(newTestLTIUsecase(t, jobs, submitUC, ""), zap.NewNop())
+
+	router := gin.New()
+	router.POST("/api/v2/integrations/lti/platforms", h.RegisterPlatform)
+	router.GET("/api/v2/integrations/lti/platforms/:id", h.GetPlatform)
+	router.GET("/api/v2/integrations/lti/jwks", h.JWKS)
+	router.GET("/api/v2/integrations/lti/login", h.Login)
+	router.POST("/api/v2/integrations/lti/launch", h.Launch)
+	router.POST("/api/v2/integrations/lti/launches/:id/submissions", h.Submit)
+
+	do := func(method, path, tenant string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(tenantIDHeader, tenant)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	platform := map[string]string{
+		"issuer":         "https://lms.example.edu",
+		"client_id":      "tool-1",
+		"auth_login_url": "https://lms.example.edu/auth",
+		"token_url":      "https://lms.example.edu/token",
+		"jwks_url":       "https://lms.example.edu/jwks",
+	}
+
+	w := do(http.MethodPost, "/api/v2/integrations/lti/platforms", "acme", platform)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var registered domain.LTIPlatform
+	if err := json.Unmarshal(w.Body.Bytes(), &registered); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if registered.TenantMapping != domain.LTITenantPlatform {
+		t.Errorf("tenant mapping = %q, want the platform default", registered.TenantMapping)
+	}
+	if w := do(http.MethodPost, "/api/v2/integrations/lti/platforms", "other", platform); w.Code != http.StatusConflict {
+		t.Errorf("duplicate registration: expected 409, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/integrations/lti/platforms/"+registered.ID.String(), "other", nil); w.Code != http.StatusNotFound {
+		t.Errorf("another tenant's platform: expected 404, got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, "/api/v2/integrations/lti/jwks", "", nil)
+	var jwks lti.JWKS
+	if err := json.Unmarshal(w.Body.Bytes(), &jwks); err != nil || len(jwks.Keys) != 1 || jwks.Keys[0].Alg != "RS256" {
+		t.Errorf("jwks: got %d %s", w.Code, w.Body.String())
+	}
+
+	query := url.Values{"iss": {"https://lms.example.edu"}, "client_id": {"tool-1"}, "login_hint": {"u-1"}}
+	w = do(http.MethodGet, "/api/v2/integrations/lti/login?"+query.Encode(), "", nil)
+	if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), "https://lms.example.edu/auth?") {
+		t.Errorf("login: expected a redirect to the platform, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	form := url.Values{"id_token": {"not.a.token"}, "state": {"unknown"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/integrations/lti/launch", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("launch with an unknown state: expected 401, got %d", w.Code)
+	}
+
+	submission := map[string]string{"language": "python", "source_code": "print(42)"}
+	if w := do(http.MethodPost, "/api/v2/integrations/lti/launches/"+uuid.NewString()+"/submissions", "", submission); w.Code != http.StatusNotFound {
+		t.Errorf("unknown launch: expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v2/integrations/lti/launches/nope/submissions", "", submission); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed launch ID: expected 400, got %d", w.Code)
+	}
+}
+
+func TestAdminHandler_RepairRequiresToken(t *testing.T) {
+	repairUC := usecase.NewRepairUsecase(mockrepo.NewMockJobRepository(), mockrepo.NewMockLockStore(), mockpub.NewMockPublisher(), zap.NewNop())
+	h := NewAdminHandler(repairUC, "s3cret", zap.NewNop())
+
+	router := gin.New()
+	router.POST("/api/v2/admin/repair", h.Repair)
+
+	do := func(auth string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/admin/repair", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	dryRun := map[string]interface{}{"actions": []string{"requeue_stuck"}, "dry_run": true}
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		if w := do(auth, dryRun); w.Code != http.StatusUnauthorized {
+			t.Errorf("auth %q: expected 401, got %d", auth, w.Code)
+		}
+	}
+	if w := do("Bearer s3cret", map[string]interface{}{"actions": []string{"drop_tables"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown action: expected 400, got %d", w.Code)
+	}
+	w := do("Bearer s3cret", dryRun)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report domain.RepairReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if !report.DryRun || report.OlderThanSeconds != 900 || len(report.Actions) != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestAdminHandler_Jobs(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	h := NewAdminHandler(nil, "s3cret", zap.NewNop())
+	h.SetJobs(usecase.NewGetJobUsecase(jobs, zap.NewNop()))
+
+	router := gin.New()
+	router.GET("/api/v2/admin/jobs", h.Jobs)
+	list := func(query, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/admin/jobs"+query, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var ids []uuid.UUID
+	for _, status := range []domain.ExecutionStatus{domain.StatusSuccess, domain.StatusInternalError, domain.StatusInternalError} {
+		job := &domain.Job{JobID: uuid.Must(uuid.NewV7()), Status: status, SourceCode: "secret"}
+		if err := jobs.Create(context.Background(), job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		ids = append(ids, job.JobID)
+	}
+
+	if w := list("", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: expected 401, got %d", w.Code)
+	}
+	for _, query := range []string{"?status=BOGUS", "?limit=0x", "?limit=1000", "?cursor=%21"} {
+		if w := list(query, "Bearer s3cret"); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+
+	w := list("?status=INTERNAL_ERROR&limit=1", "Bearer s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("secret")) {
+		t.Error("listed jobs must not carry their source")
+	}
+	var page domain.JobPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(page.Jobs) != 1 || page.Jobs[0].JobID != ids[2] || page.NextCursor == "" {
+		t.Fatalf("expected the newest INTERNAL_ERROR job and a cursor, got %+v", page)
+	}
+
+	w = list("?status=INTERNAL_ERROR&limit=1&cursor="+page.NextCursor, "Bearer s3cret")
+	page = domain.JobPage{}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(page.Jobs) != 1 || page.Jobs[0].JobID != ids[1] || page.NextCursor != "" {
+		t.Errorf("expected the older INTERNAL_ERROR job as the last page, got %+v", page)
+	}
+
+	w = list("", "Bearer s3cret")
+	page = domain.JobPage{}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || len(page.Jobs) != 3 || page.NextCursor != "" {
+		t.Errorf("all jobs: got %+v, %v", page, err)
+	}
+}
+
+func TestAdminHandler_QueuePauses(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	deps.AdminToken = "s3cret"
+	router := NewRouter(deps)
+	do := func(method, path, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPut, "/api/v2/admin/queue/pauses/cpp", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("pause without token: expected 401, got %d", w.Code)
+	}
+	if w := do(http.MethodPut, "/api/v2/admin/queue/pauses/C++", "s3cret"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid language: expected 400, got %d", w.Code)
+	}
+	w := do(http.MethodPut, "/api/v2/admin/queue/pauses/cpp", "s3cret")
+	var pause domain.QueuePause
+	if err := json.Unmarshal(w.Body.Bytes(), &pause); w.Code != http.StatusOK || err != nil || pause.Language != domain.LangCpp {
+		t.Fatalf("pause: got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/v2/admin/queue/pauses", "s3cret"); w.Code != http.StatusOK ||
+		!strings.Contains(w.Body.String(), `"pauses":[{"language":"cpp"`) {
+		t.Errorf("list: got %d: %s", w.Code, w.Body.String())
+	}
+	w = do(http.MethodDelete, "/api/v2/admin/queue/pauses/cpp", "s3cret")
+	if w.Code != http.StatusOK || w.Body.String() != `{"language":"cpp","released":0}` {
+		t.Errorf("resume: got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/v2/admin/queue/pauses", "s3cret"); w.Body.String() != `{"pauses":[]}` {
+		t.Errorf("list after resume: %s", w.Body.String())
+	}
+}
+
+func TestAdminHandler_Hardening(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	deps.AdminToken = "s3cret"
+	router := NewRouter(deps)
+	do := func(body, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/admin/sandbox/hardening", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(`{}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("without token: expected 401, got %d", w.Code)
+	}
+	if w := do(`{"sandbox_tier":"nope"}`, "s3cret"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown tier: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdminHandler_SecurityEvents(t *testing.T) {
+	events := mockrepo.NewMockSecurityEventRepository()
+	jobID := uuid.New()
+	events.AddEvent(&domain.SecurityEvent{JobID: jobID, TenantID: "acme", Kind: domain.SecuritySeccompViolation,
+		Detail: "killed by SIGSYS", CreatedAt: time.Now().Add(-time.Hour)})
+	h := NewAdminHandler(nil, "s3cret", zap.NewNop())
+	h.SetSecurityEvents(usecase.NewSecurityEventUsecase(events, zap.NewNop()))
+
+	router := gin.New()
+	router.GET("/api/v2/admin/security-events", h.SecurityEvents)
+	list := func(query, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/admin/security-events"+query, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := list("", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: expected 401, got %d", w.Code)
+	}
+	for _, query := range []string{"?format=xml", "?kind=bogus", "?limit=0x", "?cursor=abc"} {
+		if w := list(query, "Bearer s3cret"); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+
+	w := list("", "Bearer s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var page domain.SecurityEventPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(page.Events) != 1 || page.Events[0].JobID != jobID || page.NextCursor != "1" {
+		t.Fatalf("expected the seccomp event and cursor 1, got %+v", page)
+	}
+
+	w = list("?format=sarif", "Bearer s3cret")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/sarif+json" {
+		t.Fatalf("sarif: got %d, %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID     string                `json:"ruleId"`
+				Level      string                `json:"level"`
+				Message    struct{ Text string } `json:"message"`
+				Properties domain.SecurityEvent  `json:"properties"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &log); err != nil {
+		t.Fatalf("failed to unmarshal SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != len(domain.SecurityEventKinds) {
+		t.Fatalf("unexpected SARIF log: %s", w.Body.String())
+	}
+	results := log.Runs[0].Results
+	if len(results) != 1 || results[0].RuleID != "seccomp_violation" || results[0].Level != "error" ||
+		results[0].Message.Text != "killed by SIGSYS" || results[0].Properties.JobID != jobID {
+		t.Errorf("unexpected SARIF results: %+v", results)
+	}
+}
+
+func TestRouter_ServesUI(t *testing.T) {
+	router := NewRouter(fullRouterDeps(t))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<title>Sentinel</title>") {
+		t.Errorf("/ui/: got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/ui/" {
+		t.Errorf("/ui: got %d to %q, want a redirect to /ui/", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestAdminHandler_Cancel(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	cancelUC := usecase.NewCancelJobUsecase(jobs, mockrepo.NewMockCancelSignal(), zap.NewNop())
+	h := NewAdminHandler(nil, "s3cret", zap.NewNop())
+	h.SetCancels(cancelUC)
+
+	router := gin.New()
+	router.POST("/api/v2/admin/cancel", h.Cancel)
+	do := func(auth string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/admin/cancel", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	queued := &domain.Job{JobID: uuid.New(), TenantID: "flood", Status: domain.StatusQueued, CreatedAt: time.Now().Add(-time.Hour)}
+	jobs.Create(context.Background(), queued)
+
+	if w := do("", `{"tenant_id": "flood"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("without token: expected 401, got %d", w.Code)
+	}
+	if w := do("Bearer s3cret", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("without filter: expected 400, got %d", w.Code)
+	}
+	if w := do("Bearer s3cret", `{"tenant_id": "flood", "created_before": "yesterday"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad created_before: expected 400, got %d", w.Code)
+	}
+
+	w := do("Bearer s3cret", `{"tenant_id": "flood", "status": "QUEUED", "created_before": "`+time.Now().UTC().Format(time.RFC3339)+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report domain.BulkCancelReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if report.Cancelled != 1 || len(report.JobIDs) != 1 || report.JobIDs[0] != queued.JobID {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if got, _ := jobs.GetByID(context.Background(), queued.JobID); got.Status != domain.StatusCancelled {
+		t.Errorf("job stored as %s, want CANCELLED", got.Status)
+	}
+}
+
+func TestRouter_Purge(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	deps.AdminToken = "s3cret"
+	deps.APIKeyUC = usecase.NewAPIKeyUsecase(mockrepo.NewMockAPIKeyRepository(), deps.Logger)
+	router := NewRouter(deps)
+	do := func(method, path, auth, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	issue := func(body string) string {
+		w := do(http.MethodPost, "/api/v2/admin/api-keys", "s3cret", body)
+		var issued domain.IssuedAPIKey
+		if err := json.Unmarshal(w.Body.Bytes(), &issued); w.Code != http.StatusCreated || err != nil {
+			t.Fatalf("issue %s: expected 201, got %d: %s", body, w.Code, w.Body.String())
+		}
+		return issued.Key
+	}
+	alice, bob := issue(`{"tenant_id": "acme"}`), issue(`{"tenant_id": "acme"}`)
+	reader := issue(`{"tenant_id": "acme", "scopes": ["read:any"]}`)
+	submit := func(key string) string {
+		w := do(http.MethodPost, "/api/v2/submissions", key, `{"language": "python", "source_code": "print(1)"}`)
+		var resp domain.SubmitResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); w.Code != http.StatusAccepted || err != nil {
+			t.Fatalf("submit: expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+		return resp.JobID.String()
+	}
+	purge := func(id, auth string) *httptest.ResponseRecorder {
+		return do(http.MethodDelete, "/api/v2/submissions/"+id+"?purge=true", auth, "")
+	}
+	alicesJob, bobsJob := submit(alice), submit(bob)
+
+	if w := purge(alicesJob, alice); w.Code != http.StatusConflict {
+		t.Errorf("queued job: expected 409, got %d", w.Code)
+	}
+	for job, key := range map[string]string{alicesJob: alice, bobsJob: bob} {
+		if w := do(http.MethodPost, "/api/v2/submissions/"+job+"/cancel", key, ""); w.Code != http.StatusOK {
+			t.Fatalf("cancel: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	if w := purge(alicesJob, ""); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("anonymous: expected 401 with a challenge, got %d", w.Code)
+	}
+	if w := purge(alicesJob, bob); w.Code != http.StatusNotFound {
+		t.Errorf("another key's job: expected 404, got %d", w.Code)
+	}
+	if w := purge(alicesJob, reader); w.Code != http.StatusForbidden {
+		t.Errorf("read:any without submit: expected 403, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/api/v2/submissions/"+alicesJob, alice, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("without purge=true: expected 400, got %d", w.Code)
+	}
+	if w := purge("not-a-uuid", alice); w.Code != http.StatusBadRequest {
+		t.Errorf("bad id: expected 400, got %d", w.Code)
+	}
+	if w := purge(alicesJob, alice); w.Code != http.StatusNoContent {
+		t.Fatalf("own job: expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := purge(alicesJob, alice); w.Code != http.StatusNotFound {
+		t.Errorf("purged job: expected 404, got %d", w.Code)
+	}
+
+	// Operators purge any tenant's jobs.
+	if w := purge(bobsJob, "s3cret"); w.Code != http.StatusNoContent {
+		t.Errorf("admin: expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAppealHandler_Workflow(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	appealUC := usecase.NewAppealUsecase(mockrepo.NewMockAppealRepository(jobs), jobs, mockpub.NewMockPublisher(), zap.NewNop())
+	h := NewAppealHandler(appealUC, zap.NewNop())
+
+	router := gin.New()
+	optionalAdmin := middleware.Admin("admin-token", nil, false, zap.NewNop())
+	requireAdmin := middleware.Admin("admin-token", nil, true, zap.NewNop())
+	router.POST("/api/v1/submissions/:id/appeals", h.Open)
+	router.GET("/api/v1/appeals/:id", optionalAdmin, h.GetByID)
+	router.POST("/api/v1/appeals/:id/override", requireAdmin, h.Override)
+	router.POST("/api/v1/appeals/:id/close", requireAdmin, h.Close)
+
+	// do sends body as tenant, or as the operator when tenant is "admin".
+	do := func(method, path, tenant string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		if tenant == "admin" {
+			req.Header.Set("Authorization", "Bearer admin-token")
+		} else if tenant != "" {
+			req.Header.Set(tenantIDHeader, tenant)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	job := &domain.Job{JobID: uuid.New(), Status: domain.StatusWrongAnswer, ProblemID: "sum"}
+	_ = jobs.Create(context.Background(), job)
+
+	w := do(http.MethodPost, "/api/v1/submissions/"+job.JobID.String()+"/appeals", "", map[string]string{"note": "please recheck"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("open: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var appeal domain.Appeal
+	if err := json.Unmarshal(w.Body.Bytes(), &appeal); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if w := do(http.MethodPost, "/api/v1/submissions/"+job.JobID.String()+"/appeals", "", map[string]string{"note": "again"}); w.Code != http.StatusConflict {
+		t.Errorf("second open: expected 409, got %d", w.Code)
+	}
+
+	path := "/api/v1/appeals/" + appeal.AppealID.String()
+	override := map[string]string{"status": "ACCEPTED", "reason": "checker bug"}
+	if w := do(http.MethodPost, path+"/override", "", override); w.Code != http.StatusUnauthorized {
+		t.Errorf("override without the admin token: expected 401, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, path+"/override", "admin", override); w.Code != http.StatusOK {
+		t.Errorf("override: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, path+"/close", "admin", map[string]string{"response": "Fixed."}); w.Code != http.StatusOK {
+		t.Errorf("close: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, path+"/close", "admin", map[string]string{"response": "Fixed."}); w.Code != http.StatusConflict {
+		t.Errorf("second close: expected 409, got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, path, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: expected 200, got %d", w.Code)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &appeal); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if appeal.Response != "Fixed." || len(appeal.Overrides) != 1 {
+		t.Errorf("expected the response and one override to be visible, got %+v", appeal)
+	} else if appeal.Overrides[0].Actor != "admin" {
+		t.Errorf("expected the override recorded against the admin, got %q", appeal.Overrides[0].Actor)
+	}
+	if w := do(http.MethodGet, path, "globex", nil); w.Code != http.StatusNotFound {
+		t.Errorf("get as another tenant: expected 404, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, path, "admin", nil); w.Code != http.StatusOK {
+		t.Errorf("get as admin: expected 200, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/appeals/"+uuid.NewString(), "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("get unknown appeal: expected 404, got %d", w.Code)
+	}
+}
+
+func TestWSOutbox_CollapsesStatusAndClosesSlowClients(t *testing.T) {
+	// A peer that records every message it receives, then the close frame.
+	received := make(chan []byte, 64)
+	closed := make(chan *websocket.CloseError, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				var ce *websocket.CloseError
+				errors.As(err, &ce)
+				closed <- ce
+				close(received)
+				return
+			}
+			received <- data
+		}
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Hold the writer back so every message stays queued.
+	o := &wsOutbox{conn: conn, wake: make(chan struct{}, 1), done: make(chan struct{}), stop: make(chan struct{})}
+	o.sendJSON(map[string]string{"timeline": "first"})
+	for i := 1; i < wsOutboxSize+4; i++ {
+		o.sendStatus(map[string]int{"seq": i})
+	}
+	if len(o.queue) != wsOutboxSize {
+		t.Fatalf("expected queue capped at %d, got %d", wsOutboxSize, len(o.queue))
+	}
+	if head, next := string(o.queue[0].data), string(o.queue[1].data); head != `{"timeline":"first"}` || next != `{"seq":5}` {
+		t.Errorf("expected the timeline kept and the oldest updates collapsed, head is %s, %s", head, next)
+	}
+	if lag := o.lag(time.Now().Add(wsSlowClientTimeout)); lag < wsSlowClientTimeout {
+		t.Errorf("expected lag of at least %v, got %v", wsSlowClientTimeout, lag)
+	}
+
+	// Output takes the place of superseded updates, down to the newest.
+	for i := 0; i < wsOutboxSize-2; i++ {
+		if !o.sendJSON(wsOutput{Output: fmt.Sprintf("line %d", i)}) {
+			t.Fatalf("output %d refused with superseded updates queued", i)
+		}
+	}
+	if o.sendJSON(wsOutput{Output: "overflow"}) {
+		t.Fatal("expected output refused once nothing can be dropped")
+	}
+	if o.sendStatus(map[string]int{"seq": 99}) {
+		t.Error("expected messages after the slow-client close to be refused")
+	}
+
+	go o.run()
+	o.wait(5 * time.Second)
+	select {
+	case <-o.done:
+	default:
+		t.Fatal("expected the writer to exit after the close frame")
+	}
+	var delivered []string
+	for data := range received {
+		delivered = append(delivered, string(data))
+	}
+	want := []string{`{"timeline":"first"}`, fmt.Sprintf(`{"seq":%d}`, wsOutboxSize+3)}
+	for i := 0; i < wsOutboxSize-2; i++ {
+		want = append(want, fmt.Sprintf(`{"output":"line %d"}`, i))
+	}
+	if strings.Join(delivered, "\n") != strings.Join(want, "\n") {
+		t.Errorf("delivered:\n%s\nwant:\n%s", strings.Join(delivered, "\n"), strings.Join(want, "\n"))
+	}
+	if ce := <-closed; ce == nil || ce.Code != websocket.CloseTryAgainLater || !strings.HasPrefix(ce.Text, middleware.RetrySlowClient) {
+		t.Errorf("expected a slow-client close with 1013, got %v", ce)
+	}
+}
+
+func TestWebSocket_ResumeAndCloseCodes(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusSuccess}
+	if err := repo.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	wsHandler := NewWebSocketHandler(usecase.NewGetJobUsecase(repo, zap.NewNop()), zap.NewNop())
+	streams := NewStreamShutdown()
+	wsHandler.SetShutdown(streams)
+	router := gin.New()
+	router.GET("/api/v1/submissions/:id/stream", wsHandler.Stream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	dial := func(id uuid.UUID, token string) (*websocket.Conn, *http.Response, error) {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/submissions/" + id.String() + "/stream"
+		if token != "" {
+			url += "?resume_token=" + token
+		}
+		return websocket.DefaultDialer.Dial(url, nil)
+	}
+	// readAll returns the events received before the close frame and its code.
+	readAll := func(conn *websocket.Conn) ([]wsEvent, int) {
+		defer conn.Close()
+		var events []wsEvent
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				var ce *websocket.CloseError
+				if !errors.As(err, &ce) {
+					t.Fatalf("read: %v", err)
+				}
+				return events, ce.Code
+			}
+			var ev wsEvent
+			if err := json.Unmarshal(data, &ev); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			events = append(events, ev)
+		}
+	}
+
+	conn, _, err := dial(job.JobID, "")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	events, code := readAll(conn)
+	if len(events) != 1 || events[0].Seq != 1 || events[0].Status != domain.StatusSuccess || events[0].ResumeToken == "" {
+		t.Fatalf("expected one final event, got %+v", events)
+	}
+	if code != wsCloseJobCompleted {
+		t.Errorf("expected close code %d, got %d", wsCloseJobCompleted, code)
+	}
+
+	// Resuming after the final event only closes the stream.
+	conn, _, err = dial(job.JobID, events[0].ResumeToken)
+	if err != nil {
+		t.Fatalf("dial with resume token: %v", err)
+	}
+	if events, code := readAll(conn); len(events) != 0 || code != wsCloseJobCompleted {
+		t.Errorf("resumed stream: expected no events and code %d, got %d events and code %d", wsCloseJobCompleted, len(events), code)
+	}
+
+	// Tokens are bound to their job.
+	other := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusSuccess}
+	if err := repo.Create(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	if _, resp, err := dial(other.JobID, events[0].ResumeToken); err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for another job's resume token, got %v", err)
+	}
+
+	// A client over its stream limit is refused with a close code. Wait for
+	// the earlier streams to be released first.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		wsHandler.mu.Lock()
+		n := len(wsHandler.perClient)
+		if n == 0 {
+			wsHandler.perClient["127.0.0.1"] = wsMaxStreamsPerClient
+		}
+		wsHandler.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("earlier streams were not released")
+		}
+	}
+	conn, _, err = dial(job.JobID, "")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	_, _, err = conn.ReadMessage()
+	conn.Close()
+	var ce *websocket.CloseError
+	if !errors.As(err, &ce) || ce.Code != wsCloseRateLimited || ce.Text != "rate-limited; retry_after_ms=5000" {
+		t.Errorf("expected close code %d with a retry hint, got %v", wsCloseRateLimited, err)
+	}
+	wsHandler.mu.Lock()
+	delete(wsHandler.perClient, "127.0.0.1")
+	wsHandler.mu.Unlock()
+
+	// Shutdown closes running streams.
+	running := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusRunning}
+	if err := repo.Create(context.Background(), running); err != nil {
+		t.Fatal(err)
+	}
+	conn, _, err = dial(running.JobID, "")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("read first event: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := streams.Close(ctx); err != nil {
+		t.Fatalf("close streams: %v", err)
+	}
+	if _, code := readAll(conn); code != wsCloseServerShutdown {
+		t.Errorf("expected close code %d, got %d", wsCloseServerShutdown, code)
+	}
+}
+
+func TestWebSocket_StatusFeed(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	ctx := context.Background()
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusRunning}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	feed := mockrepo.NewMockStatusFeed()
+	getJobUC := usecase.NewGetJobUsecase(repo, zap.NewNop())
+	getJobUC.SetStatusFeed(feed)
+	wsHandler := NewWebSocketHandler(getJobUC, zap.NewNop())
+	router := gin.New()
+	router.GET("/api/v1/submissions/:id/stream", wsHandler.Stream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(
+		"ws"+strings.TrimPrefix(srv.URL, "http")+"/api/v1/submissions/"+job.JobID.String()+"/stream", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	var ev wsEvent
+	if err := conn.ReadJSON(&ev); err != nil || ev.Job.Status != domain.StatusRunning {
+		t.Fatalf("first event = %v, %v; want RUNNING", ev.Job, err)
+	}
+
+	_ = repo.SetResult(ctx, job.JobID, &domain.Job{Status: domain.StatusSuccess})
+	feed.Notify(job.JobID)
+	if err := conn.ReadJSON(&ev); err != nil || ev.Job.Status != domain.StatusSuccess {
+		t.Fatalf("second event = %v, %v; want SUCCESS", ev.Job, err)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, wsCloseJobCompleted) {
+		t.Fatalf("expected close %d, got %v", wsCloseJobCompleted, err)
+	}
+	// The stream stops watching once it closes.
+	deadline := time.Now().Add(time.Second)
+	for feed.Subscribers(job.JobID) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream still subscribed after closing")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebSocket_FirstEventCarriesTimeline(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	ctx := context.Background()
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusQueued}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	_ = repo.UpdateStatus(ctx, job.JobID, domain.StatusRunning)
+	_ = repo.SetResult(ctx, job.JobID, &domain.Job{Status: domain.StatusSuccess})
+
+	wsHandler := NewWebSocketHandler(usecase.NewGetJobUsecase(repo, zap.NewNop()), zap.NewNop())
+	router := gin.New()
+	router.GET("/api/v1/submissions/:id/stream", wsHandler.Stream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(
+		"ws"+strings.TrimPrefix(srv.URL, "http")+"/api/v1/submissions/"+job.JobID.String()+"/stream", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	var ev wsEvent
+	if err := conn.ReadJSON(&ev); err != nil {
+		t.Fatalf("read first event: %v", err)
+	}
+	var statuses []domain.ExecutionStatus
+	for _, e := range ev.Timeline {
+		statuses = append(statuses, e.Status)
+	}
+	want := []domain.ExecutionStatus{domain.StatusQueued, domain.StatusRunning, domain.StatusSuccess}
+	if fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("timeline = %v, want %v", statuses, want)
+	}
+}
+
+func TestWebSocket_Interactive(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	ctx := context.Background()
+	job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusRunning, Interactive: true}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	store := mockrepo.NewMockInteractiveStore()
+	wsHandler := NewWebSocketHandler(usecase.NewGetJobUsecase(repo, zap.NewNop()), zap.NewNop())
+	wsHandler.SetInteractive(usecase.NewInteractiveUsecase(store, zap.NewNop()))
+	router := gin.New()
+	router.GET("/api/v1/submissions/:id/stream", wsHandler.Stream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(
+		"ws"+strings.TrimPrefix(srv.URL, "http")+"/api/v1/submissions/"+job.JobID.String()+"/stream", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	var ev wsEvent
+	if err := conn.ReadJSON(&ev); err != nil || ev.Status != domain.StatusRunning {
+		t.Fatalf("read first event: %+v, %v", ev, err)
+	}
+
+	if err := conn.WriteJSON(wsClientMessage{Stdin: "2 3\n", StdinEOF: true}); err != nil {
+		t.Fatalf("send stdin: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if sent, closed := store.Stdin(job.JobID); closed {
+			if fmt.Sprint(sent) != "[2 3\n]" {
+				t.Errorf("stdin = %q, want the message", sent)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stdin was not sent")
+		}
+	}
+
+	// The program's output arrives live, before its final event.
+	store.WriteOutput(job.JobID, "5\n")
+	var output wsOutput
+	if err := conn.ReadJSON(&output); err != nil || output.Output != "5\n" {
+		t.Fatalf("read output: %+v, %v", output, err)
+	}
+	_ = repo.SetResult(ctx, job.JobID, &domain.Job{Status: domain.StatusSuccess, Stdout: "5\n"})
+	if err := conn.ReadJSON(&ev); err != nil || ev.Status != domain.StatusSuccess {
+		t.Fatalf("read final event: %+v, %v", ev, err)
+	}
+}
+
+func fullRouterDeps(t *testing.T) *RouterDeps {
+	t.Helper()
+	jobs := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	logger := zap.NewNop()
+	langs := testLanguages(t)
+	submitUC := usecase.NewSubmitJobUsecase(jobs, pub, langs, logger)
+	getJobUC := usecase.NewGetJobUsecase(jobs, logger)
+	return &RouterDeps{
+		SubmitUC:     submitUC,
+		GetJobUC:     getJobUC,
+		CancelUC:     usecase.NewCancelJobUsecase(jobs, mockrepo.NewMockCancelSignal(), logger),
+		ProblemUC:    usecase.NewProblemUsecase(mockrepo.NewMockProblemRepository(), jobs, pub, langs, logger),
+		AppealUC:     usecase.NewAppealUsecase(mockrepo.NewMockAppealRepository(jobs), jobs, pub, logger),
+		RuntimeUC:    usecase.NewRuntimeUsecase(mockrepo.NewMockRuntimeRepository(), langs, logger),
+		WebhookUC:    usecase.NewWebhookUsecase(mockrepo.NewMockWebhookRepository(), logger),
+		RepairUC:     usecase.NewRepairUsecase(jobs, mockrepo.NewMockLockStore(), pub, logger),
+		QueuePauseUC: usecase.NewQueuePauseUsecase(mockrepo.NewMockQueuePauseStore(), pub, logger),
+		HardeningUC:  usecase.NewHardeningUsecase(submitUC, getJobUC, logger),
+		UserUC:       newTestUserUsecase(t),
+		PurgeUC:      usecase.NewPurgeJobUsecase(jobs, logger),
+		UI:           true,
+		GitHubUC:     usecase.NewGitHubUsecase(mockrepo.NewMockGitHubRepository(jobs), submitUC, mockgh.NewMockClient(), "", logger),
+		LTIUC:        newTestLTIUsecase(t, jobs, submitUC, ""),
+		Languages:    langs,
+		Logger:       logger,
+
+		SecurityEventUC: usecase.NewSecurityEventUsecase(mockrepo.NewMockSecurityEventRepository(), logger),
+	}
+}
+
+// TestRouter_V1RoutesFrozen guards v1: changing this list breaks existing
+// clients. Add breaking changes as v2-only routes instead.
+func TestRouter_V1RoutesFrozen(t *testing.T) {
+	router := NewRouter(fullRouterDeps(t))
+
+	var v1, v2 []string
+	for _, r := range router.Routes() {
+		switch {
+		case strings.HasPrefix(r.Path, "/api/v1/"):
+			v1 = append(v1, r.Method+" "+strings.TrimPrefix(r.Path, "/api/v1"))
+		case strings.HasPrefix(r.Path, "/api/v2/"):
+			v2 = append(v2, r.Method+" "+strings.TrimPrefix(r.Path, "/api/v2"))
+		}
+	}
+	sort.Strings(v1)
+	sort.Strings(v2)
+
+	want := []string{
+		"DELETE /runtimes/:name",
+		"GET /appeals",
+		"GET /appeals/:id",
+		"GET /health",
+		"GET /languages",
+		"GET /openapi.json",
+		"GET /problems/:id",
+		"GET /runtimes",
+		"GET /runtimes/:name",
+		"GET /submissions/:id",
+		"GET /submissions/:id/stream",
+		"POST /appeals/:id/close",
+		"POST /appeals/:id/override",
+		"POST /appeals/:id/rerun",
+		"POST /problems",
+		"POST /problems/:id/rejudge",
+		"POST /runtimes",
+		"POST /submissions",
+		"POST /submissions/:id/appeals",
+		"PUT /problems/:id/testdata",
+		"PUT /runtimes/:name",
+	}
+	if strings.Join(v1, "\n") != strings.Join(want, "\n") {
+		t.Errorf("v1 routes changed:\n%s\nwant:\n%s", strings.Join(v1, "\n"), strings.Join(want, "\n"))
+	}
+	if len(v2) == 0 {
+		t.Error("expected v2 to be mounted")
+	}
+}
+
+func TestRouter_DeprecationHeadersAndSunset(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "deprecations.yaml")
+	policy := `
+versions:
+  v1:
+    deprecation: 2026-01-01
+    sunset: 2999-01-01
+    link: https://example.com/migrate
+endpoints:
+  - route: GET /api/v1/languages
+    sunset: 2020-01-01
+`
+	if err := os.WriteFile(policyPath, []byte(policy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := apiversion.Load(policyPath)
+	if err != nil {
+		t.Fatalf("load policy: %v", err)
+	}
+
+	deps := fullRouterDeps(t)
+	deps.Deprecations = p
+	router := NewRouter(deps)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/api/v1/submissions/" + uuid.NewString() + "/stream")
+	if w.Header().Get("Deprecation") != fmt.Sprintf("@%d", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Unix()) {
+		t.Errorf("Deprecation = %q", w.Header().Get("Deprecation"))
+	}
+	if w.Header().Get("Sunset") != "Tue, 01 Jan 2999 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", w.Header().Get("Sunset"))
+	}
+	if !strings.Contains(w.Header().Get("Link"), "<https://example.com/migrate>") {
+		t.Errorf("Link = %q", w.Header().Get("Link"))
+	}
+
+	if w := get("/api/v1/languages"); w.Code != http.StatusGone {
+		t.Errorf("sunset endpoint: expected 410, got %d", w.Code)
+	}
+	if w := get("/api/v2/languages"); w.Code != http.StatusOK || w.Header().Get("Deprecation") != "" {
+		t.Errorf("v2: expected 200 without deprecation, got %d %q", w.Code, w.Header().Get("Deprecation"))
+	}
+}
+
+func TestRouter_OpenAPI(t *testing.T) {
+	router := NewRouter(fullRouterDeps(t))
+	spec := func(version string) map[string]map[string]any {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/"+version+"/openapi.json", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", version, w.Code)
+		}
+		var doc struct {
+			OpenAPI string                               `json:"openapi"`
+			Paths   map[string]map[string]any            `json:"paths"`
+			Comps   map[string]map[string]map[string]any `json:"components"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		if doc.OpenAPI != "3.0.3" || doc.Comps["schemas"]["SubmitRequest"] == nil {
+			t.Fatalf("%s: unexpected document %s", version, w.Body.String()[:200])
+		}
+		return doc.Paths
+	}
+
+	// Every mounted route is documented, under its version only.
+	for _, version := range apiVersions {
+		paths := spec(version)
+		documented := 0
+		for _, ops := range paths {
+			documented += len(ops)
+		}
+		mounted := 0
+		for _, r := range router.Routes() {
+			if strings.HasPrefix(r.Path, "/api/"+version+"/") && r.Path != "/api/"+version+"/openapi.json" {
+				mounted++
+			}
+		}
+		if documented != mounted {
+			t.Errorf("%s: %d operations documented, %d routes mounted", version, documented, mounted)
+		}
+	}
+	if paths := spec("v1"); paths["/schedules"] != nil || paths["/submissions"]["post"] == nil {
+		t.Error("v1 document does not match v1's routes")
+	}
+	if op, ok := spec("v2")["/submissions/{id}/lineage"]["get"].(map[string]any); !ok || op["operationId"] != "getSubmissionsIdLineage" {
+		t.Errorf("lineage operation = %v", op)
+	}
+	if op, ok := spec("v2")["/submissions/by-external-id/{id}"]["get"].(map[string]any); !ok || op["operationId"] != "getSubmissionsByExternalIdId" {
+		t.Errorf("external ID operation = %v", op)
+	}
+}
+
+func TestRouter_APIKeys(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	deps.AdminToken = "s3cret"
+	deps.APIKeyUC = usecase.NewAPIKeyUsecase(mockrepo.NewMockAPIKeyRepository(), deps.Logger)
+	router := NewRouter(deps)
+	do := func(method, path, auth, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/api/v2/admin/api-keys", "", `{"tenant_id": "acme"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("issue without admin token: expected 401, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v2/admin/api-keys", "s3cret", `{"tenant_id": " acme"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid tenant: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	w := do(http.MethodPost, "/api/v2/admin/api-keys", "s3cret", `{"tenant_id": "acme", "name": "ci"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("issue: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var issued domain.IssuedAPIKey
+	if err := json.Unmarshal(w.Body.Bytes(), &issued); err != nil || issued.Key == "" {
+		t.Fatalf("issued = %s, %v", w.Body.String(), err)
+	}
+
+	// A key's tenant wins over the header, and its jobs record the key.
+	submit := `{"language": "python", "source_code": "print(1)"}`
+	w = do(http.MethodPost, "/api/v2/submissions", issued.Key, submit, "X-Tenant-ID", "globex")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("submit with key: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp domain.SubmitResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	var job domain.Job
+	json.Unmarshal(do(http.MethodGet, "/api/v2/submissions/"+resp.JobID.String(), "", "").Body.Bytes(), &job)
+	if job.TenantID != "acme" || job.APIKeyID == nil || *job.APIKeyID != issued.KeyID {
+		t.Errorf("job tenant %q, key %v; want acme and %s", job.TenantID, job.APIKeyID, issued.KeyID)
+	}
+
+	// Keys are optional until required, but a wrong key is never ignored.
+	if w := do(http.MethodPost, "/api/v2/submissions", "", submit); w.Code != http.StatusAccepted {
+		t.Errorf("submit without key: expected 202, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v2/submissions", "snt_wrong", submit); w.Code != http.StatusUnauthorized {
+		t.Errorf("submit with wrong key: expected 401, got %d", w.Code)
+	}
+
+	w = do(http.MethodPost, "/api/v2/admin/api-keys/"+issued.KeyID.String()+"/rotate", "s3cret", "")
+	var rotated domain.IssuedAPIKey
+	if err := json.Unmarshal(w.Body.Bytes(), &rotated); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("rotate: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/api/v2/submissions/"+resp.JobID.String(), issued.Key, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("old key after rotation: expected 401, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/admin/api-keys?tenant_id=acme", "s3cret", ""); w.Code != http.StatusOK ||
+		!strings.Contains(w.Body.String(), `"prefix":"`+rotated.Prefix+`"`) || strings.Contains(w.Body.String(), rotated.Key) {
+		t.Errorf("list: got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/api/v2/admin/api-keys/"+issued.KeyID.String(), "s3cret", ""); w.Code != http.StatusNoContent {
+		t.Errorf("revoke: expected 204, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/submissions/"+resp.JobID.String(), rotated.Key, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: expected 401, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v2/admin/api-keys/"+uuid.NewString()+"/rotate", "s3cret", ""); w.Code != http.StatusNotFound {
+		t.Errorf("rotate unknown key: expected 404, got %d", w.Code)
+	}
+
+	deps.APIKeysRequired = true
+	router = NewRouter(deps)
+	w = do(http.MethodPost, "/api/v2/submissions", "", submit)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("required key missing: expected 401 with a challenge, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/languages", "", ""); w.Code != http.StatusOK {
+		t.Errorf("unauthenticated route: expected 200, got %d", w.Code)
+	}
+	if spec := do(http.MethodGet, "/api/v2/openapi.json", "", "").Body.String(); !strings.Contains(spec, `"securitySchemes":{"apiKey":`) ||
+		!strings.Contains(spec, `"security":[{"apiKey":[]},{"userToken":[]}]`) {
+		t.Error("OpenAPI document does not require an API key or user token")
+	}
+}
+
+func TestRouter_APIKeyScopes(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	deps.AdminToken = "s3cret"
+	deps.APIKeyUC = usecase.NewAPIKeyUsecase(mockrepo.NewMockAPIKeyRepository(), deps.Logger)
+	router := NewRouter(deps)
+	do := func(method, path, auth, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+auth)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	issue := func(auth, body string) string {
+		w := do(http.MethodPost, "/api/v2/admin/api-keys", auth, body)
+		var issued domain.IssuedAPIKey
+		if err := json.Unmarshal(w.Body.Bytes(), &issued); w.Code != http.StatusCreated || err != nil {
+			t.Fatalf("issue %s: expected 201, got %d: %s", body, w.Code, w.Body.String())
+		}
+		return issued.Key
+	}
+	if w := do(http.MethodPost, "/api/v2/admin/api-keys", "s3cret", `{"tenant_id": "acme", "scopes": ["root"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown scope: expected 400, got %d", w.Code)
+	}
+	alice, bob := issue("s3cret", `{"tenant_id": "acme"}`), issue("s3cret", `{"tenant_id": "acme"}`)
+	reader := issue("s3cret", `{"tenant_id": "acme", "scopes": ["read:any"]}`)
+	admin := issue("s3cret", `{"tenant_id": "acme", "scopes": ["admin"]}`)
+	outsider := issue("s3cret", `{"tenant_id": "globex", "scopes": ["read:any"]}`)
+
+	submit := func(key string) string {
+		w := do(http.MethodPost, "/api/v2/submissions", key, `{"language": "python", "source_code": "print(1)"}`)
+		var resp domain.SubmitResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); w.Code != http.StatusAccepted || err != nil {
+			t.Fatalf("submit: expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+		return resp.JobID.String()
+	}
+	alicesJob, bobsJob := submit(alice), submit(bob)
+
+	// A submit key reads only its own jobs; others look missing.
+	if w := do(http.MethodGet, "/api/v2/submissions/"+alicesJob, alice, ""); w.Code != http.StatusOK {
+		t.Errorf("own job: expected 200, got %d", w.Code)
+	}
+	for _, path := range []string{"", "/stdout", "/lineage"} {
+		if w := do(http.MethodGet, "/api/v2/submissions/"+bobsJob+path, alice, ""); w.Code != http.StatusNotFound {
+			t.Errorf("another key's job%s: expected 404, got %d", path, w.Code)
+		}
+	}
+	if w := do(http.MethodPost, "/api/v2/submissions/"+bobsJob+"/cancel", alice, ""); w.Code != http.StatusNotFound {
+		t.Errorf("cancel another key's job: expected 404, got %d", w.Code)
+	}
+	list := func(key string) int {
+		w := do(http.MethodGet, "/api/v2/submissions", key, "")
+		var page domain.JobPage
+		if err := json.Unmarshal(w.Body.Bytes(), &page); w.Code != http.StatusOK || err != nil {
+			t.Fatalf("list: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		return len(page.Jobs)
+	}
+	if n := list(alice); n != 1 {
+		t.Errorf("alice's submissions = %d, want 1", n)
+	}
+
+	// read:any reads the tenant's jobs, but cannot submit.
+	if w := do(http.MethodGet, "/api/v2/submissions/"+bobsJob, reader, ""); w.Code != http.StatusOK {
+		t.Errorf("read:any: expected 200, got %d", w.Code)
+	}
+	if n := list(reader); n != 2 {
+		t.Errorf("read:any submissions = %d, want 2", n)
+	}
+	if w := do(http.MethodPost, "/api/v2/submissions", reader, `{"language": "python", "source_code": "print(1)"}`); w.Code != http.StatusForbidden {
+		t.Errorf("submit without the submit scope: expected 403, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/submissions/"+alicesJob, outsider, ""); w.Code != http.StatusNotFound {
+		t.Errorf("another tenant's job: expected 404, got %d", w.Code)
+	}
+
+	// An admin key stands in for the admin token; other keys are refused.
+	if w := do(http.MethodGet, "/api/v2/admin/jobs", admin, ""); w.Code != http.StatusOK {
+		t.Errorf("admin key: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	issue(admin, `{"tenant_id": "acme"}`)
+	if w := do(http.MethodGet, "/api/v2/admin/jobs", alice, ""); w.Code != http.StatusForbidden {
+		t.Errorf("submit key on an admin endpoint: expected 403, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v2/admin/jobs", "snt_wrong", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown key on an admin endpoint: expected 401, got %d", w.Code)
+	}
+
+	// Operator routes outside /admin refuse submit keys the same way.
+	appeal := "/api/v2/appeals/" + uuid.NewString()
+	for _, route := range []struct{ method, path, body string }{
+		{http.MethodPut, "/api/v2/problems/sum/testdata", `{"test_cases": [{"input": "1", "expected_output": "1"}]}`},
+		{http.MethodPost, "/api/v2/problems/sum/rejudge", ""},
+		{http.MethodPost, appeal + "/rerun", ""},
+		{http.MethodPost, appeal + "/override", `{"status": "ACCEPTED", "reason": "checker bug"}`},
+		{http.MethodPost, appeal + "/close", `{"response": "Fixed."}`},
+		{http.MethodPost, "/api/v2/runtimes", `{"name": "lua"}`},
+		{http.MethodPut, "/api/v2/runtimes/lua", `{}`},
+		{http.MethodDelete, "/api/v2/runtimes/lua", ""},
+	} {
+		if w := do(route.method, route.path, alice, route.body); w.Code != http.StatusForbidden {
+			t.Errorf("submit key on %s %s: expected 403, got %d", route.method, route.path, w.Code)
+		}
+		if w := do(route.method, route.path, admin, route.body); w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden {
+			t.Errorf("admin key on %s %s: got %d", route.method, route.path, w.Code)
+		}
+	}
+}
+
+func newTestUserUsecase(t *testing.T) *usecase.UserUsecase {
+	t.Helper()
+	uc, err := usecase.NewUserUsecase(mockrepo.NewMockUserRepository(), []byte(strings.Repeat("k", 32)), time.Hour, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewUserUsecase: %v", err)
+	}
+	return uc
+}
+
+func TestRouter_Users(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	router := NewRouter(deps)
+	do := func(method, path, auth, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Users register in the request's tenant.
+	if w := do(http.MethodPost, "/api/v2/users", "", `{"email": "ada@example.com", "password": "short"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("short password: expected 400, got %d", w.Code)
+	}
+	for _, email := range []string{"ada@example.com", "bob@example.com"} {
+		body := `{"email": "` + email + `", "password": "correct horse"}`
+		if w := do(http.MethodPost, "/api/v2/users", "", body, "X-Tenant-ID", "acme"); w.Code != http.StatusCreated {
+			t.Fatalf("register %s: expected 201, got %d: %s", email, w.Code, w.Body.String())
+		}
+	}
+	if w := do(http.MethodPost, "/api/v2/users", "", `{"email": "Ada@example.com", "password": "correct horse"}`); w.Code != http.StatusConflict {
+		t.Errorf("register taken email: expected 409, got %d", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/api/v2/users/login", "", `{"email": "ada@example.com", "password": "wrong horse"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password: expected 401, got %d", w.Code)
+	}
+	login := func(email string) string {
+		w := do(http.MethodPost, "/api/v2/users/login", "", `{"email": "`+email+`", "password": "correct horse"}`)
+		var token domain.UserToken
+		if err := json.Unmarshal(w.Body.Bytes(), &token); w.Code != http.StatusOK || err != nil || token.Token == "" {
+			t.Fatalf("login %s: expected 200 with a token, got %d: %s", email, w.Code, w.Body.String())
+		}
+		if token.User.TenantID != "acme" || token.TokenType != "Bearer" {
+			t.Errorf("login %s: token = %+v", email, token)
+		}
+		return token.Token
+	}
+	ada, bob := login("ada@example.com"), login("bob@example.com")
+
+	// A user's tenant wins over the header, and their jobs record them.
+	submit := `{"language": "python", "source_code": "print(1)"}`
+	w := do(http.MethodPost, "/api/v2/submissions", ada, submit, "X-Tenant-ID", "globex")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("submit as ada: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp domain.SubmitResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	var job domain.Job
+	json.Unmarshal(do(http.MethodGet, "/api/v2/submissions/"+resp.JobID.String(), "", "").Body.Bytes(), &job)
+	if job.TenantID != "acme" || job.UserID == nil {
+		t.Errorf("job tenant %q, user %v; want acme and ada", job.TenantID, job.UserID)
+	}
+	do(http.MethodPost, "/api/v2/submissions", bob, submit)
+	do(http.MethodPost, "/api/v2/submissions", "", submit, "X-Tenant-ID", "acme")
+
+	// Users list their own submissions; the tenant lists all of them.
+	list := func(auth string, header ...string) []*domain.JobSummary {
+		w := do(http.MethodGet, "/api/v2/submissions", auth, "", header...)
+		var page domain.JobPage
+		if err := json.Unmarshal(w.Body.Bytes(), &page); w.Code != http.StatusOK || err != nil {
+			t.Fatalf("list: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		return page.Jobs
+	}
+	if jobs := list(ada); len(jobs) != 1 || jobs[0].JobID != resp.JobID {
+		t.Errorf("ada's submissions = %v, want only %s", jobs, resp.JobID)
+	}
+	if jobs := list("", "X-Tenant-ID", "acme"); len(jobs) != 3 {
+		t.Errorf("acme's submissions = %d, want 3", len(jobs))
+	}
+	if jobs := list("", "X-Tenant-ID", "globex"); len(jobs) != 0 {
+		t.Errorf("globex's submissions = %d, want none", len(jobs))
+	}
+
+	if w := do(http.MethodGet, "/api/v2/submissions", ada+"x", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("forged token: expected 401, got %d", w.Code)
+	}
+	if spec := do(http.MethodGet, "/api/v2/openapi.json", "", "").Body.String(); !strings.Contains(spec, `"userToken":{"type":"http","scheme":"bearer","bearerFormat":"JWT"`) {
+		t.Error("OpenAPI document does not describe user tokens")
+	}
+}
+
+func TestRouter_OIDC(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	mux := http.NewServeMux()
+	provider := httptest.NewServer(mux)
+	defer provider.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": provider.URL, "jwks_uri": provider.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(lti.JWKS{Keys: []lti.JWK{lti.PublicJWK(&key.PublicKey)}})
+	})
+
+	p, err := oidc.NewProvider(provider.URL, "sentinel")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	deps.OIDCUC = usecase.NewOIDCUsecase(p, mockrepo.NewMockUserRepository(), "", "org", zap.NewNop())
+	router := NewRouter(deps)
+	token := func(sub string, claims map[string]any) string {
+		c := map[string]any{"iss": provider.URL, "sub": sub, "aud": "sentinel", "exp": time.Now().Add(time.Hour).Unix()}
+		for k, v := range claims {
+			c[k] = v
+		}
+		signed, err := lti.Sign(c, key)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		return signed
+	}
+	do := func(method, path, auth, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+auth)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A provider user acts as the tenant its claim names, and is the same
+	// user on every request.
+	ada := token("ada", map[string]any{"org": "acme", "email": "ada@example.com"})
+	submit := `{"language": "python", "source_code": "print(1)"}`
+	var jobs []uuid.UUID
+	for i := 0; i < 2; i++ {
+		w := do(http.MethodPost, "/api/v2/submissions", ada, submit)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("submit: expected 202, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp domain.SubmitResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		jobs = append(jobs, resp.JobID)
+	}
+	var job domain.Job
+	json.Unmarshal(do(http.MethodGet, "/api/v2/submissions/"+jobs[0].String(), ada, "").Body.Bytes(), &job)
+	if job.TenantID != "acme" || job.UserID == nil {
+		t.Fatalf("job tenant %q, user %v; want acme and ada", job.TenantID, job.UserID)
+	}
+	do(http.MethodPost, "/api/v2/submissions", token("bob", map[string]any{"org": "acme"}), submit)
+
+	w := do(http.MethodGet, "/api/v2/submissions", ada, "")
+	var page domain.JobPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("list: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(page.Jobs) != 2 {
+		t.Errorf("ada's submissions = %d, want 2", len(page.Jobs))
+	}
+
+	for name, bad := range map[string]string{
+		"no tenant claim": token("ada", nil),
+		"wrong audience":  token("ada", map[string]any{"org": "acme", "aud": "other"}),
+	} {
+		if w := do(http.MethodGet, "/api/v2/submissions", bad, ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, w.Code)
+		}
+	}
+	if spec := do(http.MethodGet, "/api/v2/openapi.json", "", "").Body.String(); !strings.Contains(spec,
+		`"oidc":{"type":"openIdConnect","openIdConnectUrl":"`+provider.URL+`/.well-known/openid-configuration"`) {
+		t.Error("OpenAPI document does not describe the OIDC provider")
+	}
+}
+
+// unreachableRedis returns a client for the rate limiter, which fails open
+// while Redis is unreachable, so tests can call rate-limited routes. With a
+// single connection, the client stops dialing after the first failure.
+func unreachableRedis(t *testing.T) *redis.Client {
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, PoolSize: 1})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+func TestRouter_RateLimitFailModes(t *testing.T) {
+	for _, tt := range []struct {
+		mode  string
+		codes []int
+	}{
+		{middleware.FailOpen, []int{http.StatusNotFound, http.StatusNotFound, http.StatusNotFound}},
+		{middleware.FailClosed, []int{http.StatusServiceUnavailable}},
+		{middleware.FailLocal, []int{http.StatusNotFound, http.StatusNotFound, http.StatusTooManyRequests}},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			deps := fullRouterDeps(t)
+			deps.Redis = unreachableRedis(t)
+			deps.RateLimitPerMin = 2
+			deps.RateLimitFailMode = tt.mode
+			router := NewRouter(deps)
+
+			for i, want := range tt.codes {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+uuid.NewString(), nil))
+				if w.Code != want {
+					t.Fatalf("request %d: expected %d, got %d: %s", i+1, want, w.Code, w.Body.String())
+				}
+				if want == http.StatusServiceUnavailable && !strings.Contains(w.Body.String(), `"reason":"limiter-unavailable"`) {
+					t.Errorf("expected a limiter-unavailable retry hint, got %s", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_ValidatesBodies(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	router := NewRouter(deps)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/api/v2/submissions", `{"language": "python", "time_limit_ms": "5s", "compare_mode": "fuzzy", "env": {"X": 1}}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var fields []string
+	for _, f := range resp.Fields {
+		fields = append(fields, f.Field)
+	}
+	if want := []string{"compare_mode", "env.X", "source_code", "time_limit_ms"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	if !strings.HasPrefix(resp.Error, "Invalid request body: compare_mode must be one of") {
+		t.Errorf("error = %q", resp.Error)
+	}
+
+	// A valid body still reaches the handler.
+	if w := post("/api/v1/submissions", `{"language": "python", "source_code": "print(1)", "compare_mode": ""}`); w.Code != http.StatusAccepted {
+		t.Errorf("valid body: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := post("/api/v2/problems", `{"problem_id": "p", "test_cases": [{"input": 1}]}`); w.Code != http.StatusBadRequest ||
+		!strings.Contains(w.Body.String(), `"field":"test_cases[0].input"`) {
+		t.Errorf("nested field: got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRouter_Gzip(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	router := NewRouter(deps)
+	gzipped := func(p []byte) *bytes.Buffer {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(p)
+		zw.Close()
+		return &buf
+	}
+	submit := func(body io.Reader, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/submissions", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	source := "print('hi')\n" + strings.Repeat("# padding\n", 1000)
+	body, _ := json.Marshal(map[string]any{"language": "python", "source_code": source})
+	w := submit(gzipped(body), "gzip")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("gzipped submission: expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp domain.SubmitResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	// The limit applies to the decompressed body: 2 MB of source compresses
+	// to a few KB but is still refused.
+	bomb, _ := json.Marshal(map[string]any{"language": "python", "source_code": strings.Repeat("#", 2<<20)})
+	if w := submit(gzipped(bomb), "gzip"); w.Code != http.StatusBadRequest {
+		t.Errorf("gzipped body over the limit: expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := submit(bytes.NewReader(body), "gzip"); w.Code != http.StatusBadRequest {
+		t.Errorf("body that is not gzip: expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := submit(bytes.NewReader(body), "br"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("brotli body: expected status 415, got %d: %s", w.Code, w.Body.String())
+	}
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	path := "/api/v2/submissions/" + resp.JobID.String()
+	w = get(path, "gzip, deflate")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("large response: got %d, Content-Encoding %q, want gzip", w.Code, w.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	var job domain.Job
+	if err := json.NewDecoder(zr).Decode(&job); err != nil || job.SourceCode != source {
+		t.Errorf("decompressed response: source %d bytes, err %v; want the submitted source", len(job.SourceCode), err)
+	}
+	if !strings.Contains(w.Header().Get("Vary"), "Accept-Encoding") {
+		t.Errorf("Vary = %q, want Accept-Encoding", w.Header().Get("Vary"))
+	}
+
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"gzip not accepted": get(path, "identity"),
+		"gzip refused":      get(path, "gzip;q=0"),
+		"small response":    get(path+"?fields=status", "gzip"),
+	} {
+		if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "" || !json.Valid(w.Body.Bytes()) {
+			t.Errorf("%s: got %d, Content-Encoding %q, want plain JSON", name, w.Code, w.Header().Get("Content-Encoding"))
+		}
+	}
+}
+
+func TestRouter_SubmitUpload(t *testing.T) {
+	deps := fullRouterDeps(t)
+	deps.Redis = unreachableRedis(t)
+	router := NewRouter(deps)
+
+	upload := func(files map[string][2]string, request string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for part, file := range files {
+			fw, err := mw.CreateFormFile(part, file[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write([]byte(file[1]))
+		}
+		if request != "" {
+			mw.WriteField("request", request)
+		}
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/submissions", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := upload(map[string][2]string{
+		"source": {"solution.py", "print(input())\n"},
+		"stdin":  {"input.txt", "42\n"},
+	}, `{"time_limit_ms": 2000}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp domain.SubmitResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	get := httptest.NewRecorder()
+	router.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/api/v2/submissions/"+resp.JobID.String(), nil))
+	var job domain.Job
+	if err := json.Unmarshal(get.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if job.Language != domain.LangPython || job.SourceCode != "print(input())\n" || job.Stdin != "42\n" {
+		t.Errorf("job = %s %q %q, want the uploaded python job", job.Language, job.SourceCode, job.Stdin)
+	}
+
+	if w := upload(map[string][2]string{"source": {"main.txt", "print(1)"}}, `{"language": "python"}`); w.Code != http.StatusAccepted {
+		t.Errorf("named language: expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string][2]string
+		request string
+		want    string
+	}{
+		{"no source", map[string][2]string{"stdin": {"in.txt", "1"}}, "", "exactly one source file"},
+		{"unknown extension", map[string][2]string{"source": {"main.txt", "x"}}, "", `cannot tell the language of \"main.txt\"`},
+		{"source in request", map[string][2]string{"source": {"a.py", "x"}}, `{"source_code": "y"}`, "source_code is read from the source file"},
+		{"stdin twice", map[string][2]string{"source": {"a.py", "x"}, "stdin": {"in.txt", "1"}}, `{"stdin": "2"}`, "stdin is set more than once"},
+		{"bad request part", map[string][2]string{"source": {"a.py", "x"}}, `[1]`, "request must be a JSON object"},
+		{"not text", map[string][2]string{"source": {"a.py", "\xff\xfe"}}, "", "a.py is not UTF-8 text"},
+		{"invalid field", map[string][2]string{"source": {"a.py", "x"}}, `{"priority": "high"}`, `"field":"priority"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := upload(tt.files, tt.request)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("expected 400 mentioning %q, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
 	}
 }
@@ -14,19 +14,20 @@ import (
 
 // HealthHandler handles health check requests.
 type HealthHandler struct {
-	logger  *zap.Logger
-	dbPool  *pgxpool.Pool
-	amqpURI string
-	rdb     *redis.Client
+	logger   *zap.Logger
+	dbPool   *pgxpool.Pool
+	amqpURIs []string
+	rdb      *redis.Client
 }
 
-// NewHealthHandler creates a new HealthHandler.
-func NewHealthHandler(logger *zap.Logger, dbPool *pgxpool.Pool, amqpURI string, rdb *redis.Client) *HealthHandler {
+// NewHealthHandler creates a new HealthHandler. RabbitMQ counts as healthy
+// while any of amqpURIs, the nodes of one cluster, can be reached.
+func NewHealthHandler(logger *zap.Logger, dbPool *pgxpool.Pool, amqpURIs []string, rdb *redis.Client) *HealthHandler {
 	return &HealthHandler{
-		logger:  logger,
-		dbPool:  dbPool,
-		amqpURI: amqpURI,
-		rdb:     rdb,
+		logger:   logger,
+		dbPool:   dbPool,
+		amqpURIs: amqpURIs,
+		rdb:      rdb,
 	}
 }
 
@@ -41,13 +42,19 @@ func (h *HealthHandler) Health(c *gin.Context) {
 		h.logger.Warn("PostgreSQL health check failed", zap.Error(err))
 	}
 
-	rabbitStatus := "ok"
-	conn, err := amqp.Dial(h.amqpURI)
-	if err != nil {
-		rabbitStatus = "error: " + err.Error()
-		h.logger.Warn("RabbitMQ health check failed", zap.Error(err))
-	} else {
+	rabbitStatus := "error: no broker URL"
+	for _, uri := range h.amqpURIs {
+		conn, err := amqp.Dial(uri)
+		if err != nil {
+			rabbitStatus = "error: " + err.Error()
+			continue
+		}
 		conn.Close()
+		rabbitStatus = "ok"
+		break
+	}
+	if rabbitStatus != "ok" {
+		h.logger.Warn("RabbitMQ health check failed", zap.String("status", rabbitStatus))
 	}
 
 	redisStatus := "ok"
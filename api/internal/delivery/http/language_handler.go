@@ -5,32 +5,22 @@ import (
 
 	"github.com/gin-gonic/gin"
 
-	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
 )
 
 // LanguageHandler handles language listing requests.
-type LanguageHandler struct{}
+type LanguageHandler struct {
+	languages *language.Registry
+}
 
-// NewLanguageHandler creates a new LanguageHandler.
-func NewLanguageHandler() *LanguageHandler {
-	return &LanguageHandler{}
+// NewLanguageHandler creates a new LanguageHandler backed by the language registry.
+func NewLanguageHandler(languages *language.Registry) *LanguageHandler {
+	return &LanguageHandler{languages: languages}
 }
 
 // List handles GET /api/v1/languages
 func (h *LanguageHandler) List(c *gin.Context) {
-	languages := []domain.LanguageInfo{
-		{
-			Name:    domain.LangPython,
-			Version: "3.12",
-		},
-		{
-			Name:     domain.LangCpp,
-			Version:  "17",
-			Compiler: "g++ (GCC 13)",
-		},
-	}
-
 	c.JSON(http.StatusOK, gin.H{
-		"languages": languages,
+		"languages": h.languages.List(),
 	})
 }
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// LTIHandler handles the LTI 1.3 tool: tenants' platform registrations, and
+// the login, launch and submission endpoints platforms and their users
+// reach. Registrations are scoped to the tenant from the X-Tenant-ID
+// header; launches are authenticated by the platform's signature, and
+// submissions by the launch ID they return.
+type LTIHandler struct {
+	ltiUC  *usecase.LTIUsecase
+	logger *zap.Logger
+}
+
+// NewLTIHandler creates a new LTIHandler.
+func NewLTIHandler(ltiUC *usecase.LTIUsecase, logger *zap.Logger) *LTIHandler {
+	return &LTIHandler{
+		ltiUC:  ltiUC,
+		logger: logger,
+	}
+}
+
+// RegisterPlatform handles POST /api/v2/integrations/lti/platforms
+func (h *LTIHandler) RegisterPlatform(c *gin.Context) {
+	var req domain.RegisterLTIPlatformRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	platform, err := h.ltiUC.RegisterPlatform(c.Request.Context(), c.GetHeader(tenantIDHeader), &req)
+	if err != nil {
+		h.writeError(c, "Register LTI platform failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, platform)
+}
+
+// GetPlatform handles GET /api/v2/integrations/lti/platforms/:id
+func (h *LTIHandler) GetPlatform(c *gin.Context) {
+	id, ok := idParam(c, "Invalid platform ID format")
+	if !ok {
+		return
+	}
+	platform, err := h.ltiUC.GetPlatform(c.Request.Context(), c.GetHeader(tenantIDHeader), id)
+	if err != nil {
+		h.writeError(c, "Get LTI platform failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, platform)
+}
+
+// DeletePlatform handles DELETE /api/v2/integrations/lti/platforms/:id
+func (h *LTIHandler) DeletePlatform(c *gin.Context) {
+	id, ok := idParam(c, "Invalid platform ID format")
+	if !ok {
+		return
+	}
+	if err := h.ltiUC.DeletePlatform(c.Request.Context(), c.GetHeader(tenantIDHeader), id); err != nil {
+		h.writeError(c, "Delete LTI platform failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListUsers handles GET /api/v2/integrations/lti/platforms/:id/users
+func (h *LTIHandler) ListUsers(c *gin.Context) {
+	id, ok := idParam(c, "Invalid platform ID format")
+	if !ok {
+		return
+	}
+	users, err := h.ltiUC.ListUsers(c.Request.Context(), c.GetHeader(tenantIDHeader), id)
+	if err != nil {
+		h.writeError(c, "List LTI users failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// JWKS handles GET /api/v2/integrations/lti/jwks
+func (h *LTIHandler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.ltiUC.JWKS())
+}
+
+// Login handles GET and POST /api/v2/integrations/lti/login, the OIDC
+// login initiation, by redirecting to the platform's authentication
+// endpoint.
+func (h *LTIHandler) Login(c *gin.Context) {
+	redirect, err := h.ltiUC.Login(c.Request.Context(), &usecase.LTILoginRequest{
+		Issuer:         c.Request.FormValue("iss"),
+		ClientID:       c.Request.FormValue("client_id"),
+		LoginHint:      c.Request.FormValue("login_hint"),
+		LTIMessageHint: c.Request.FormValue("lti_message_hint"),
+	})
+	if err != nil {
+		h.writeError(c, "LTI login failed", err)
+		return
+	}
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// Launch handles POST /api/v2/integrations/lti/launch, where the platform
+// posts the launch's id_token. The browser is redirected to the configured
+// app with the launch ID, or gets the launch as JSON.
+func (h *LTIHandler) Launch(c *gin.Context) {
+	launch, err := h.ltiUC.Launch(c.Request.Context(), c.PostForm("id_token"), c.PostForm("state"))
+	if err != nil {
+		h.writeError(c, "LTI launch failed", err)
+		return
+	}
+	if redirect := h.ltiUC.AppURL(launch); redirect != "" {
+		c.Redirect(http.StatusSeeOther, redirect)
+		return
+	}
+	c.JSON(http.StatusCreated, launch)
+}
+
+// GetLaunch handles GET /api/v2/integrations/lti/launches/:id
+func (h *LTIHandler) GetLaunch(c *gin.Context) {
+	id, ok := idParam(c, "Invalid launch ID format")
+	if !ok {
+		return
+	}
+	launch, err := h.ltiUC.GetLaunch(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, "Get LTI launch failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, launch)
+}
+
+// Submit handles POST /api/v2/integrations/lti/launches/:id/submissions
+func (h *LTIHandler) Submit(c *gin.Context) {
+	id, ok := idParam(c, "Invalid launch ID format")
+	if !ok {
+		return
+	}
+	var req domain.LTISubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	resp, err := h.ltiUC.Submit(c.Request.Context(), id, &req)
+	if err != nil {
+		h.writeError(c, "LTI submission failed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+func (h *LTIHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrLTIPlatformNotFound), errors.Is(err, domain.ErrLTILaunchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrLTIPlatformTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidLTILaunch):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidLTIPlatform), errors.Is(err, domain.ErrProblemNotFound),
+		errors.Is(err, domain.ErrInvalidLanguage), errors.Is(err, domain.ErrEmptySourceCode),
+		errors.Is(err, domain.ErrInvalidSource):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPayloadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		writeQuotaExceeded(c, h.ltiUC.QuotaResetAt(time.Now()), err)
+	case errors.Is(err, domain.ErrPublishFailed):
+		writeQueueUnavailable(c)
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// idParam parses the :id path parameter, answering 400 with msg if it is
+// not a UUID.
+func idParam(c *gin.Context, msg string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// APIKeyContextKey is the Gin context key APIKey stores the request's
+// *domain.APIKey under.
+const APIKeyContextKey = "sentinel.api_key"
+
+// tenantHeader names the tenant a request acts as.
+const tenantHeader = "X-Tenant-ID"
+
+// APIKeyAuthenticator resolves the API key a request presents.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+}
+
+// APIKey authenticates a request by the API key it sends as
+// "Authorization: Bearer <key>", rejecting an unknown or revoked key with
+// 401 Unauthorized. The key's tenant replaces any X-Tenant-ID header, so the
+// request acts as that tenant. A request without a key is rejected when
+// required, and otherwise passes as it did before keys existed. Requests
+// UserToken authenticated pass as well.
+func APIKey(auth APIKeyAuthenticator, required bool, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(UserContextKey); ok {
+			c.Next()
+			return
+		}
+		secret, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			if required {
+				c.Header("WWW-Authenticate", "Bearer")
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		key, err := auth.Authenticate(c.Request.Context(), secret)
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+				return
+			}
+			logger.Error("API key authentication failed", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		c.Request.Header.Set(tenantHeader, key.TenantID)
+		c.Set(APIKeyContextKey, key)
+		c.Next()
+	}
+}
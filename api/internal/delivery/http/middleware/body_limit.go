@@ -1,13 +1,21 @@
 package middleware
 
 import (
+	"compress/gzip"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // BodySizeLimit returns a middleware that limits the maximum request body size.
 // If the body exceeds maxBytes, a 413 Payload Too Large response is returned.
+//
+// Bodies sent with Content-Encoding: gzip are decompressed for the handler,
+// and maxBytes then applies to both the compressed body and what it
+// decompresses to, so a small body cannot expand past the limit. Other
+// encodings are refused with 415 Unsupported Media Type.
 func BodySizeLimit(maxBytes int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.ContentLength > maxBytes {
@@ -18,6 +26,38 @@ func BodySizeLimit(maxBytes int64) gin.HandlerFunc {
 		}
 
 		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		switch encoding := strings.TrimSpace(c.GetHeader("Content-Encoding")); {
+		case encoding == "" || strings.EqualFold(encoding, "identity"):
+		case strings.EqualFold(encoding, "gzip"):
+			zr, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid gzip request body",
+				})
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, gzipBody{zr, c.Request.Body}, maxBytes)
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.Header.Del("Content-Length")
+			c.Request.ContentLength = -1
+		default:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+				"error": "Unsupported Content-Encoding " + encoding + "; send gzip or an uncompressed body",
+			})
+			return
+		}
 		c.Next()
 	}
 }
+
+// gzipBody reads the decompressed request body and closes the compressed one
+// with it.
+type gzipBody struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (b gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
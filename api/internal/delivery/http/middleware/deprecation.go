@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/apiversion"
+)
+
+// Deprecation announces a deprecated endpoint with the Deprecation (RFC 9745),
+// Sunset (RFC 8594) and Link headers, and answers 410 Gone once the sunset
+// date has passed.
+func Deprecation(n apiversion.Notice) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !n.Sunset.IsZero() && !time.Now().Before(n.Sunset) {
+			c.AbortWithStatusJSON(http.StatusGone, gin.H{
+				"error": "This endpoint was retired on " + n.Sunset.Format(time.DateOnly),
+				"link":  n.Link,
+			})
+			return
+		}
+		if !n.Deprecation.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(n.Deprecation.Unix(), 10))
+		}
+		if !n.Sunset.IsZero() {
+			c.Header("Sunset", n.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if n.Link != "" {
+			c.Header("Link", "<"+n.Link+`>; rel="deprecation"; type="text/html"`)
+		}
+		c.Next()
+	}
+}
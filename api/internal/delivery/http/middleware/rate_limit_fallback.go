@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/metrics"
+)
+
+// What the rate limiters do when Redis cannot be reached, as named by
+// API_RATE_LIMIT_FAIL_MODE.
+const (
+	// FailOpen lets every request through.
+	FailOpen = "open"
+	// FailClosed rejects every request with 503 Service Unavailable.
+	FailClosed = "closed"
+	// FailLocal limits each API instance on its own, with a token bucket
+	// in memory at the configured rate.
+	FailLocal = "local"
+)
+
+// LimiterRetryAfter is how long clients are asked to wait when the rate
+// limiter fails closed.
+const LimiterRetryAfter = 5 * time.Second
+
+// RateLimitFallback returns the rate limit a Redis one hands a request to
+// when Redis cannot be reached, for mode, one of FailOpen, FailClosed and
+// FailLocal. maxRequests and burst configure FailLocal's buckets as for
+// TokenBucketRateLimit.
+func RateLimitFallback(mode string, maxRequests, burst int) RateLimit {
+	var fallback RateLimit
+	switch mode {
+	case FailClosed:
+		fallback = func(context.Context, string) RateResult {
+			return RateResult{
+				Status:     http.StatusServiceUnavailable,
+				Reason:     RetryLimiterUnavailable,
+				RetryAfter: LimiterRetryAfter,
+				Message:    "Rate limiting is unavailable. Try again later.",
+			}
+		}
+	case FailLocal:
+		fallback = LocalRateLimit(maxRequests, burst)
+	default:
+		mode = FailOpen
+		fallback = func(context.Context, string) RateResult { return RateResult{Allowed: true} }
+	}
+	fallbacks := metrics.RateLimitFallbacks.WithLabelValues(mode)
+	return func(ctx context.Context, client string) RateResult {
+		fallbacks.Inc()
+		return fallback(ctx, client)
+	}
+}
+
+// localBucket is a client's token bucket in LocalRateLimiter.
+type localBucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// LocalRateLimit returns a rate limit that keeps a token bucket in memory
+// per client, refilled at maxRequests per minute and holding up to burst
+// requests, maxRequests if burst is not positive. Each API instance counts
+// only the requests it serves.
+func LocalRateLimit(maxRequests, burst int) RateLimit {
+	if burst <= 0 {
+		burst = maxRequests
+	}
+	perSec := float64(maxRequests) / time.Minute.Seconds()
+	// A bucket left alone this long is full again and can be forgotten.
+	refill := time.Duration(float64(burst) / perSec * float64(time.Second))
+
+	var mu sync.Mutex
+	buckets := make(map[string]*localBucket)
+	pruned := time.Now()
+
+	return func(_ context.Context, client string) RateResult {
+		now := time.Now()
+		mu.Lock()
+		if now.Sub(pruned) > refill {
+			for ip, b := range buckets {
+				if now.Sub(b.at) > refill {
+					delete(buckets, ip)
+				}
+			}
+			pruned = now
+		}
+		b, ok := buckets[client]
+		if !ok {
+			b = &localBucket{tokens: float64(burst), at: now}
+			buckets[client] = b
+		}
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.at).Seconds()*perSec)
+		b.at = now
+		allowed := b.tokens >= 1
+		var wait time.Duration
+		if allowed {
+			b.tokens--
+		} else {
+			wait = time.Duration((1 - b.tokens) / perSec * float64(time.Second))
+		}
+		remaining := int(b.tokens)
+		mu.Unlock()
+
+		result := RateResult{Allowed: allowed, Limit: burst, Remaining: remaining}
+		if !allowed {
+			result.Status = http.StatusTooManyRequests
+			result.Reason = RetryRateLimited
+			result.RetryAfter = wait
+			result.Message = fmt.Sprintf("Rate limit exceeded. Maximum %d requests per minute, in bursts of up to %d.", maxRequests, burst)
+		}
+		return result
+	}
+}
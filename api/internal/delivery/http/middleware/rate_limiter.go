@@ -10,21 +10,67 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// RateLimiter returns a middleware that enforces per-IP rate limiting
-// using a Redis sliding window log algorithm.
-// maxRequests is the maximum number of requests allowed per minute per IP.
-func RateLimiter(rdb *redis.Client, maxRequests int) gin.HandlerFunc {
-	window := time.Minute
+// RateResult is a rate limit's decision on one request.
+type RateResult struct {
+	// Allowed lets the request through.
+	Allowed bool
+	// Limit and Remaining describe the client's budget; Limit is zero when
+	// nothing was counted.
+	Limit     int
+	Remaining int
+	// Status, Reason and Message say why a request was turned away, and
+	// RetryAfter when the client may try again.
+	Status     int
+	Reason     string
+	RetryAfter time.Duration
+	Message    string
+}
+
+// A RateLimit takes one request from the budget of client, the IP address
+// it came from.
+type RateLimit func(ctx context.Context, client string) RateResult
+
+// NewRateLimit returns the per-IP rate limit of maxRequests a minute, by
+// algorithm, SlidingWindow or TokenBucket with buckets of burst. It falls
+// back to failMode while Redis cannot be reached. REST and gRPC share it,
+// so a client has one budget across both.
+func NewRateLimit(rdb *redis.Client, algorithm, failMode string, maxRequests, burst int) RateLimit {
+	fallback := RateLimitFallback(failMode, maxRequests, burst)
+	if algorithm == TokenBucket {
+		return TokenBucketRateLimit(rdb, maxRequests, burst, fallback)
+	}
+	return SlidingWindowRateLimit(rdb, maxRequests, fallback)
+}
 
+// RateLimiter returns a middleware that enforces limit on each request's
+// client IP, rejecting requests over it with a hint to retry later.
+func RateLimiter(limit RateLimit) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		key := fmt.Sprintf("sentinel:ratelimit:%s", ip)
+		res := limit(c.Request.Context(), c.ClientIP())
+		if res.Limit > 0 {
+			c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", res.Limit))
+			c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", res.Remaining))
+		}
+		if !res.Allowed {
+			RetryLater(c, res.Status, res.Reason, res.RetryAfter, res.Message)
+			return
+		}
+		c.Next()
+	}
+}
+
+// SlidingWindowRateLimit returns a rate limit that allows maxRequests per
+// minute per client, using a Redis sliding window log algorithm. Requests
+// are handed to fallback while Redis cannot be reached.
+func SlidingWindowRateLimit(rdb *redis.Client, maxRequests int, fallback RateLimit) RateLimit {
+	window := time.Minute
+
+	return func(ctx context.Context, client string) RateResult {
+		key := fmt.Sprintf("sentinel:ratelimit:%s", client)
 		now := time.Now()
 		nowUnixNano := float64(now.UnixNano())
 		windowStart := float64(now.Add(-window).UnixNano())
 
-		ctx := context.Background()
-
 		// Use a pipeline for atomicity
 		pipe := rdb.Pipeline()
 
@@ -42,9 +88,7 @@ func RateLimiter(rdb *redis.Client, maxRequests int) gin.HandlerFunc {
 
 		_, err := pipe.Exec(ctx)
 		if err != nil {
-			// If Redis is down, allow the request (fail-open)
-			c.Next()
-			return
+			return fallback(ctx, client)
 		}
 
 		count := countCmd.Val()
@@ -52,29 +96,30 @@ func RateLimiter(rdb *redis.Client, maxRequests int) gin.HandlerFunc {
 			// Remove the entry we just added since we're rejecting
 			rdb.ZRemRangeByScore(ctx, key, fmt.Sprintf("%f", nowUnixNano), fmt.Sprintf("%f", nowUnixNano))
 
-			remaining := 0
-			c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", maxRequests))
-			c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-			c.Header("Retry-After", "60")
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"error": fmt.Sprintf("Rate limit exceeded. Maximum %d requests per minute.", maxRequests),
-			})
-			return
+			// A slot frees up when the oldest request leaves the window.
+			retryAfter := window
+			if oldest, err := rdb.ZRangeWithScores(ctx, key, 0, 0).Result(); err == nil && len(oldest) == 1 {
+				retryAfter = time.Unix(0, int64(oldest[0].Score)).Add(window).Sub(now)
+			}
+			return RateResult{
+				Limit:      maxRequests,
+				Status:     http.StatusTooManyRequests,
+				Reason:     RetryRateLimited,
+				RetryAfter: retryAfter,
+				Message:    fmt.Sprintf("Rate limit exceeded. Maximum %d requests per minute.", maxRequests),
+			}
 		}
 
 		remaining := int64(maxRequests) - count - 1
 		if remaining < 0 {
 			remaining = 0
 		}
-		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", maxRequests))
-		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-
-		c.Next()
+		return RateResult{Allowed: true, Limit: maxRequests, Remaining: int(remaining)}
 	}
 }
 
 // RateLimiterInMemory returns a simple in-memory rate limiter fallback
 // for environments without Redis (e.g. testing).
 func RateLimiterInMemory(maxRequests int) gin.HandlerFunc {
-	return RateLimiter(nil, maxRequests)
+	return RateLimiter(LocalRateLimit(maxRequests, 0))
 }
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reasons a request, or a submission stream, was turned away for now. They
+// are sent with a retry_after_ms hint on 429 and 503 responses and in the
+// reason of the matching stream close, so clients can back off alike.
+const (
+	// RetryRateLimited: the client sent too many requests, or has too many
+	// streams open.
+	RetryRateLimited = "rate-limited"
+	// RetryQuotaExceeded: the tenant used up its execution quota; the hint
+	// is when it resets.
+	RetryQuotaExceeded = "quota-exceeded"
+	// RetryQueueUnavailable: the job could not be queued.
+	RetryQueueUnavailable = "queue-unavailable"
+	// RetryServerShutdown: this API instance is going away.
+	RetryServerShutdown = "server-shutdown"
+	// RetrySlowClient: a stream fell too far behind.
+	RetrySlowClient = "slow-client"
+	// RetryLimiterUnavailable: the rate limiter fails closed and cannot
+	// reach Redis.
+	RetryLimiterUnavailable = "limiter-unavailable"
+)
+
+// QueueRetryAfter is how long clients are asked to wait when a job could
+// not be queued.
+const QueueRetryAfter = 5 * time.Second
+
+// RetryLater aborts c with status, msg and a hint to retry after d for
+// reason. Retry-After carries the hint in whole seconds, rounded up.
+func RetryLater(c *gin.Context, status int, reason string, d time.Duration, msg string) {
+	d = max(d, 0)
+	c.Header("Retry-After", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10))
+	c.AbortWithStatusJSON(status, gin.H{
+		"error":          msg,
+		"reason":         reason,
+		"retry_after_ms": d.Milliseconds(),
+	})
+}
+
+// RetryCloseReason is the reason of a stream close frame that asks the
+// client to reconnect after d: the reason code, then the hint, as in
+// "rate-limited; retry_after_ms=5000".
+func RetryCloseReason(reason string, d time.Duration) string {
+	return fmt.Sprintf("%s; retry_after_ms=%d", reason, max(d, 0).Milliseconds())
+}
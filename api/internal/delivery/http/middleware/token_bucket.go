@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Rate limiting algorithms, as named by API_RATE_LIMIT_ALGORITHM.
+const (
+	// SlidingWindow keeps a log of each client's requests in the last
+	// minute: exact, but a sorted set entry per request.
+	SlidingWindow = "sliding_window"
+	// TokenBucket keeps two numbers per client, refilling its bucket at the
+	// per-minute rate up to a burst capacity.
+	TokenBucket = "token_bucket"
+)
+
+// tokenBucketScript takes a token from the bucket at KEYS[1], refilled at
+// ARGV[1] tokens per millisecond up to ARGV[2], on Redis's clock so every
+// API instance agrees. It returns whether a token was taken, the whole
+// tokens left and, if none was, the milliseconds until one is.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed, wait = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(capacity / rate) + 1000)
+return {allowed, math.floor(tokens), wait}
+`)
+
+// TokenBucketRateLimit returns a rate limit that keeps a token bucket in
+// Redis per client. Buckets refill at maxRequests per minute and hold up to
+// burst requests, maxRequests if burst is not positive. Requests are handed
+// to fallback while Redis cannot be reached.
+func TokenBucketRateLimit(rdb *redis.Client, maxRequests, burst int, fallback RateLimit) RateLimit {
+	if burst <= 0 {
+		burst = maxRequests
+	}
+	perMs := float64(maxRequests) / float64(time.Minute.Milliseconds())
+
+	return func(ctx context.Context, client string) RateResult {
+		key := fmt.Sprintf("sentinel:ratelimit:bucket:%s", client)
+		res, err := tokenBucketScript.Run(ctx, rdb, []string{key}, perMs, burst).Int64Slice()
+		if err != nil || len(res) != 3 {
+			return fallback(ctx, client)
+		}
+
+		result := RateResult{Allowed: res[0] == 1, Limit: burst, Remaining: int(res[1])}
+		if !result.Allowed {
+			result.Status = http.StatusTooManyRequests
+			result.Reason = RetryRateLimited
+			result.RetryAfter = time.Duration(res[2]) * time.Millisecond
+			result.Message = fmt.Sprintf("Rate limit exceeded. Maximum %d requests per minute, in bursts of up to %d.", maxRequests, burst)
+		}
+		return result
+	}
+}
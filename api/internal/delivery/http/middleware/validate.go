@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/openapi"
+)
+
+// ValidateBody rejects a request whose JSON body does not match schema
+// with 400 Bad Request, listing every field that does not match, before
+// the handler binds it. The body is left for the handler to read again.
+func ValidateBody(g *openapi.Generator, schema *openapi.Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		fields, err := g.Validate(schema, body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+		if len(fields) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":  "Invalid request body: " + fields[0].Error(),
+				"fields": fields,
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
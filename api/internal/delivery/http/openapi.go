@@ -0,0 +1,184 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/openapi"
+)
+
+// ErrorResponse is the body of every error response. Fields lists the
+// fields of a request body that did not match its schema. Reason and
+// RetryAfterMs tell clients turned away with 429 or 503 why, and when to
+// try again.
+type ErrorResponse struct {
+	Error        string               `json:"error"`
+	Fields       []openapi.FieldError `json:"fields,omitempty"`
+	Reason       string               `json:"reason,omitempty"`
+	RetryAfterMs int64                `json:"retry_after_ms,omitempty"`
+}
+
+// newSchemaGenerator returns a generator that knows the values of the
+// domain's string enums. Languages are left open, since runtimes can be
+// registered at any time.
+func newSchemaGenerator() *openapi.Generator {
+	g := openapi.NewGenerator()
+	openapi.Enum(g,
+		domain.StatusScheduled, domain.StatusQueued, domain.StatusCompiling, domain.StatusRunning,
+		domain.StatusSuccess, domain.StatusCompilationError, domain.StatusRuntimeError,
+		domain.StatusTimeout, domain.StatusMemoryLimitExceeded, domain.StatusInternalError,
+		domain.StatusAccepted, domain.StatusWrongAnswer, domain.StatusCancelled,
+		domain.StatusRuleViolation, domain.StatusOutputViolation, domain.StatusSkipped)
+	openapi.Enum(g, domain.OriginUser, domain.OriginResubmit, domain.OriginSchedule)
+	openapi.Enum(g, domain.CompareExact, domain.CompareTrailingWhitespace, domain.CompareTokens)
+	openapi.Enum(g, domain.AppealOpen, domain.AppealClosed)
+	openapi.Enum(g, domain.TerminateRunAll, domain.TerminateFirstFailure, domain.TerminatePerSubtask)
+	openapi.Enum(g, domain.ScoreAllOrNothing, domain.ScorePerTest)
+	openapi.Enum(g, domain.LTITenantPlatform, domain.LTITenantContext, domain.LTITenantUser)
+	openapi.Enum(g, domain.RepairActions...)
+	openapi.Enum(g,
+		domain.RepairOutcomePurged, domain.RepairOutcomeRequeued, domain.RepairOutcomeWouldPurge,
+		domain.RepairOutcomeWouldRequeue, domain.RepairOutcomeLocked, domain.RepairOutcomeChanged,
+		domain.RepairOutcomePublishFailed)
+	openapi.Enum(g, domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryFailed)
+	return g
+}
+
+// apiKeyScheme, userTokenScheme and oidcScheme name the security schemes
+// in the OpenAPI document.
+const (
+	apiKeyScheme    = "apiKey"
+	userTokenScheme = "userToken"
+	oidcScheme      = "oidc"
+)
+
+// openAPIDocument describes the routes a version serves, as deps configures
+// them, with the schemas of g, which validates their bodies.
+func openAPIDocument(g *openapi.Generator, version string, routes []route, deps *RouterDeps) *openapi.Document {
+	errorSchema := g.SchemaOf(ErrorResponse{})
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title: "Sentinel API",
+			Description: "Generated from the routes the server mounts. Request bodies are validated " +
+				"against these schemas; see docs/api.md for each endpoint's semantics.",
+			Version: version,
+		},
+		Servers: []openapi.Server{{URL: "/api/" + version}},
+		Paths:   make(map[string]openapi.PathItem),
+	}
+	for _, r := range routes {
+		if !r.servedBy(version) {
+			continue
+		}
+		path, params := openAPIPath(r.path)
+		for _, name := range r.query {
+			params = append(params, openapi.Parameter{Name: name, In: "query", Schema: &openapi.Schema{Type: "string"}})
+		}
+
+		status := r.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := openapi.Response{Description: http.StatusText(status)}
+		if r.response != nil {
+			success.Content = openapi.JSON(g.SchemaOf(r.response))
+		}
+		op := &openapi.Operation{
+			OperationID: operationID(r.method, r.path),
+			Tags:        []string{strings.Split(strings.TrimPrefix(r.path, "/"), "/")[0]},
+			Parameters:  params,
+			Responses: map[string]openapi.Response{
+				strconv.Itoa(status): success,
+				"default":            {Description: "Error", Content: openapi.JSON(errorSchema)},
+			},
+		}
+		if r.body != nil {
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JSON(g.SchemaOf(r.body))}
+			if r.formBody != nil {
+				op.RequestBody.Content["multipart/form-data"] = openapi.MediaType{Schema: g.SchemaOf(r.formBody)}
+			}
+			op.Responses[strconv.Itoa(http.StatusBadRequest)] = openapi.Response{
+				Description: "Invalid request body", Content: openapi.JSON(errorSchema),
+			}
+		}
+		if _, ok := deps.Deprecations.Lookup(version, r.method, "/api/"+version+r.path); ok {
+			op.Deprecated = true
+		}
+		if r.authenticated && (deps.APIKeyUC != nil || deps.UserUC != nil || deps.OIDCUC != nil) {
+			if deps.APIKeyUC != nil {
+				op.Security = append(op.Security, openapi.SecurityRequirement{apiKeyScheme: {}})
+			}
+			if deps.UserUC != nil {
+				op.Security = append(op.Security, openapi.SecurityRequirement{userTokenScheme: {}})
+			}
+			if deps.OIDCUC != nil {
+				op.Security = append(op.Security, openapi.SecurityRequirement{oidcScheme: {}})
+			}
+			if deps.APIKeyUC == nil || !deps.APIKeysRequired {
+				op.Security = append(op.Security, openapi.SecurityRequirement{})
+			}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(openapi.PathItem)
+		}
+		doc.Paths[path][strings.ToLower(r.method)] = op
+	}
+	doc.Components = g.Components()
+	if deps.APIKeyUC != nil || deps.UserUC != nil || deps.OIDCUC != nil {
+		doc.Components.SecuritySchemes = make(map[string]openapi.SecurityScheme)
+	}
+	if deps.APIKeyUC != nil {
+		doc.Components.SecuritySchemes[apiKeyScheme] = openapi.SecurityScheme{
+			Type: "http", Scheme: "bearer", Description: "An API key issued by an admin; requests act as its tenant.",
+		}
+	}
+	if deps.UserUC != nil {
+		doc.Components.SecuritySchemes[userTokenScheme] = openapi.SecurityScheme{
+			Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+			Description: "A token from POST /users/login; requests act as the user and its tenant.",
+		}
+	}
+	if deps.OIDCUC != nil {
+		doc.Components.SecuritySchemes[oidcScheme] = openapi.SecurityScheme{
+			Type: "openIdConnect", OpenIDConnectURL: deps.OIDCUC.DiscoveryURL(),
+			Description: "A token from the organisation's OpenID Connect provider, sent as a bearer token; requests act as its user.",
+		}
+	}
+	return doc
+}
+
+// openAPIPath turns a Gin path into an OpenAPI path template and its
+// parameters.
+func openAPIPath(path string) (string, []openapi.Parameter) {
+	var params []openapi.Parameter
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			name := s[1:]
+			segments[i] = "{" + name + "}"
+			params = append(params, openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID names a route after its method and path, such as
+// getSubmissionsIdLineage for GET /submissions/:id/lineage and
+// getSubmissionsByExternalIdId for GET /submissions/by-external-id/:id.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	words := strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' })
+	for _, s := range words {
+		s = strings.TrimLeft(s, ":*")
+		if s == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(s[:1]) + s[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// hotPathBudgets bound what one request of each hot-path handler may cost,
+// against the in-memory fakes. A change that breaks a budget should be
+// reconsidered before the budget is raised. Allocations are always checked.
+// Latency depends on the machine, so it is only logged unless
+// SENTINEL_PERF_LATENCY=1 asks for it to be enforced, as make bench-api
+// does; it is never measured under the race detector, which slows every
+// handler several times over.
+var hotPathBudgets = []struct {
+	name   string
+	p99    time.Duration
+	allocs float64
+}{
+	{name: "submit", p99: time.Millisecond, allocs: 55},
+	{name: "get", p99: 500 * time.Microsecond, allocs: 30},
+}
+
+// hotPathRequest returns a function serving one request of the named
+// handler through a router with fakes behind it.
+func hotPathRequest(tb testing.TB, name string) func() *httptest.ResponseRecorder {
+	router, _, _ := setupTestRouter(tb)
+	body, _ := json.Marshal(map[string]interface{}{
+		"language":    "python",
+		"source_code": "import sys\nprint(sum(map(int, sys.stdin.read().split())))",
+		"stdin":       "1 2",
+	})
+	submit := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/submissions", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	if name == "submit" {
+		return submit
+	}
+
+	var resp domain.SubmitResponse
+	if err := json.Unmarshal(submit().Body.Bytes(), &resp); err != nil {
+		tb.Fatalf("submit: %v", err)
+	}
+	path := "/api/v1/submissions/" + resp.JobID.String()
+	return func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+}
+
+func benchmarkHotPath(b *testing.B, name string, want int) {
+	serve := hotPathRequest(b, name)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if w := serve(); w.Code != want {
+			b.Fatalf("status %d: %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+func BenchmarkSubmitHandler(b *testing.B) {
+	benchmarkHotPath(b, "submit", http.StatusAccepted)
+}
+
+func BenchmarkGetByIDHandler(b *testing.B) {
+	benchmarkHotPath(b, "get", http.StatusOK)
+}
+
+func TestHotPathBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("perf budget skipped in short mode")
+	}
+	for _, budget := range hotPathBudgets {
+		t.Run(budget.name, func(t *testing.T) {
+			serve := hotPathRequest(t, budget.name)
+
+			allocs := testing.AllocsPerRun(200, func() { serve() })
+			t.Logf("%.0f allocs per request (budget %.0f)", allocs, budget.allocs)
+			if allocs > budget.allocs {
+				t.Errorf("%.0f allocations per request exceed the budget of %.0f", allocs, budget.allocs)
+			}
+
+			if raceEnabled {
+				return
+			}
+			const samples = 2000
+			latencies := make([]time.Duration, samples)
+			for i := range latencies {
+				start := time.Now()
+				serve()
+				latencies[i] = time.Since(start)
+			}
+			sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+			p99 := latencies[samples*99/100]
+			t.Logf("p99 %v (budget %v)", p99, budget.p99)
+			if p99 > budget.p99 && os.Getenv("SENTINEL_PERF_LATENCY") == "1" {
+				t.Errorf("p99 latency %v exceeds the budget of %v", p99, budget.p99)
+			}
+		})
+	}
+}
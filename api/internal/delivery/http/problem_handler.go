@@ -0,0 +1,92 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// ProblemHandler handles HTTP requests for problems and their test data.
+type ProblemHandler struct {
+	problemUC *usecase.ProblemUsecase
+	logger    *zap.Logger
+}
+
+// NewProblemHandler creates a new ProblemHandler.
+func NewProblemHandler(problemUC *usecase.ProblemUsecase, logger *zap.Logger) *ProblemHandler {
+	return &ProblemHandler{
+		problemUC: problemUC,
+		logger:    logger,
+	}
+}
+
+// Create handles POST /api/v1/problems
+func (h *ProblemHandler) Create(c *gin.Context) {
+	var req domain.CreateProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	problem, err := h.problemUC.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.writeError(c, "Create problem failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, problem)
+}
+
+// GetByID handles GET /api/v1/problems/:id
+func (h *ProblemHandler) GetByID(c *gin.Context) {
+	problem, err := h.problemUC.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, "Get problem failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, problem)
+}
+
+// UpdateTestData handles PUT /api/v1/problems/:id/testdata
+func (h *ProblemHandler) UpdateTestData(c *gin.Context) {
+	var req domain.UpdateTestDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	problem, err := h.problemUC.UpdateTestData(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.writeError(c, "Update test data failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, problem)
+}
+
+// Rejudge handles POST /api/v1/problems/:id/rejudge
+func (h *ProblemHandler) Rejudge(c *gin.Context) {
+	resp, err := h.problemUC.Rejudge(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.writeError(c, "Rejudge failed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+func (h *ProblemHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProblemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrProblemExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidTestData):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
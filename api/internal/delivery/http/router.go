@@ -1,13 +1,21 @@
 package http
 
 import (
+	"encoding/json"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/Harsh-BH/Sentinel/api/internal/apiversion"
 	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+	"github.com/Harsh-BH/Sentinel/api/internal/ui"
 	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
 )
 
@@ -15,11 +23,390 @@ import (
 type RouterDeps struct {
 	SubmitUC        *usecase.SubmitJobUsecase
 	GetJobUC        *usecase.GetJobUsecase
+	CancelUC        *usecase.CancelJobUsecase
+	ProblemUC       *usecase.ProblemUsecase
+	AppealUC        *usecase.AppealUsecase
+	RuntimeUC       *usecase.RuntimeUsecase
+	WebhookUC       *usecase.WebhookUsecase
+	ScheduleUC      *usecase.ScheduleUsecase
+	GitHubUC        *usecase.GitHubUsecase
+	LTIUC           *usecase.LTIUsecase
+	RepairUC        *usecase.RepairUsecase
+	PurgeUC         *usecase.PurgeJobUsecase
+	Languages       *language.Registry
 	Logger          *zap.Logger
 	RateLimitPerMin int
 	DBPool          *pgxpool.Pool
-	AmqpURI         string
+	AmqpURIs        []string
 	Redis           *redis.Client
+	// RateLimitAlgorithm picks the limiter, middleware.SlidingWindow unless
+	// it is middleware.TokenBucket, whose buckets hold RateLimitBurst.
+	RateLimitAlgorithm string
+	RateLimitBurst     int
+	// RateLimitFailMode says what the limiter does while Redis cannot be
+	// reached: middleware.FailOpen unless it is middleware.FailClosed or
+	// middleware.FailLocal.
+	RateLimitFailMode string
+	// StreamShutdown, if set, closes WebSocket streams on server shutdown.
+	StreamShutdown *StreamShutdown
+	// InteractiveUC, if set, relays input and output of interactive jobs
+	// over their streams.
+	InteractiveUC *usecase.InteractiveUsecase
+	// Deprecations, if set, schedules the deprecation and sunset of API
+	// versions and endpoints.
+	Deprecations *apiversion.Policy
+	// AdminToken authorizes the /admin endpoints and purging submissions,
+	// as do API keys with the admin scope.
+	AdminToken string
+	// APIKeyUC, if set, authenticates requests by their API key and lets
+	// the admin token manage keys. APIKeysRequired rejects requests to
+	// authenticated routes that send no key.
+	APIKeyUC        *usecase.APIKeyUsecase
+	APIKeysRequired bool
+	// QueuePauseUC, with the admin token, lets operators pause languages.
+	QueuePauseUC *usecase.QueuePauseUsecase
+	// HardeningUC, with the admin token, lets operators check which
+	// protections the workers' sandbox applies.
+	HardeningUC *usecase.HardeningUsecase
+	// SecurityEventUC, with the admin token, exports the security events
+	// workers record.
+	SecurityEventUC *usecase.SecurityEventUsecase
+	// UserUC, if set, registers users, logs them in, and authenticates
+	// requests by the tokens it issues.
+	UserUC *usecase.UserUsecase
+	// OIDCUC, if set, authenticates requests by the tokens an external
+	// OIDC provider issues, alongside or instead of UserUC's.
+	OIDCUC *usecase.OIDCUsecase
+	// UI serves the embedded web UI at /ui.
+	UI bool
+	// ResultWebhooks mounts the result webhook and delivery endpoints.
+	ResultWebhooks bool
+}
+
+// apiVersions are mounted under /api/<version>, all backed by the same
+// usecases. v1 is frozen: its routes and response shapes no longer change.
+// A breaking change is added as a v2-only route next to the v1 route it
+// replaces, which is then limited to v1.
+var apiVersions = []string{"v1", "v2"}
+
+// route is one entry of the route table.
+type route struct {
+	method  string
+	path    string // relative to /api/<version>
+	handler gin.HandlerFunc
+	// limited puts the route behind the per-IP rate limiter.
+	limited bool
+	// authenticated puts the route behind API-key and user-token
+	// authentication, so it acts as the key's or user's tenant.
+	authenticated bool
+	// scope, if set, rejects authenticated requests whose key or user lacks
+	// it. owned answers 404 for a job, named by :id, that the request may
+	// only read if it submitted it, and did not.
+	scope string
+	owned bool
+	// admin puts the route behind the admin token, for which an API key
+	// with the admin scope may stand in. An authenticated admin route is
+	// also open to tenants; operators skip the tenant's checks.
+	admin bool
+	// versions limits the route to some API versions; empty means all.
+	versions []string
+
+	// body and response are the route's JSON request and response DTOs, if
+	// any, for the OpenAPI document. Bodies are validated against body's
+	// schema before the handler runs.
+	body     any
+	response any
+	// status is the route's success status; 200 OK when zero.
+	status int
+	// query names the route's query parameters.
+	query []string
+	// form, if set, also accepts the body as multipart/form-data with the
+	// parts of formBody, converting it to the JSON body ahead of
+	// validation.
+	form     gin.HandlerFunc
+	formBody any
+}
+
+func (r route) servedBy(version string) bool {
+	if len(r.versions) == 0 {
+		return true
+	}
+	for _, v := range r.versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// apiRoutes is the route table shared by every API version.
+func apiRoutes(deps *RouterDeps) []route {
+	healthHandler := NewHealthHandler(deps.Logger, deps.DBPool, deps.AmqpURIs, deps.Redis)
+	langHandler := NewLanguageHandler(deps.Languages)
+	subHandler := NewSubmissionHandler(deps.SubmitUC, deps.GetJobUC, deps.Logger)
+
+	routes := []route{
+		// Health check and languages (no rate limiting)
+		{method: "GET", path: "/health", handler: healthHandler.Health},
+		{method: "GET", path: "/languages", handler: langHandler.List},
+
+		// Submissions
+		{method: "POST", path: "/submissions", handler: subHandler.Submit, limited: true, authenticated: true, scope: domain.ScopeSubmit,
+			body: domain.SubmitRequest{}, response: domain.SubmitResponse{}, status: http.StatusAccepted,
+			form: submissionUpload(deps.Languages), formBody: SubmissionUpload{}, query: []string{"base64_encoded"}},
+		{method: "GET", path: "/submissions", handler: subHandler.List, limited: true, authenticated: true, versions: []string{"v2"},
+			response: domain.JobPage{}, query: []string{"status", "cursor", "limit"}},
+		{method: "GET", path: "/submissions/:id", handler: subHandler.GetByID, limited: true, authenticated: true, owned: true,
+			response: domain.Job{}, query: []string{"fields", "base64_encoded"}},
+		{method: "GET", path: "/submissions/by-external-id/:id", handler: subHandler.GetByExternalID, limited: true, authenticated: true, versions: []string{"v2"},
+			response: domain.Job{}, query: []string{"base64_encoded"}},
+		{method: "GET", path: "/submissions/:id/stdout", handler: subHandler.Stdout, limited: true, authenticated: true, owned: true, versions: []string{"v2"}},
+		{method: "GET", path: "/submissions/:id/stderr", handler: subHandler.Stderr, limited: true, authenticated: true, owned: true, versions: []string{"v2"}},
+		{method: "GET", path: "/submissions/:id/artifacts/:name", handler: subHandler.Artifact, limited: true, authenticated: true, owned: true, versions: []string{"v2"}},
+		{method: "POST", path: "/submissions/:id/rerun", handler: subHandler.Rerun, limited: true, authenticated: true, scope: domain.ScopeSubmit, owned: true, versions: []string{"v2"},
+			response: domain.SubmitResponse{}, status: http.StatusAccepted},
+		{method: "GET", path: "/submissions/:id/lineage", handler: subHandler.Lineage, limited: true, authenticated: true, owned: true, versions: []string{"v2"},
+			response: domain.Lineage{}},
+	}
+	if deps.CancelUC != nil {
+		subHandler.SetCancel(deps.CancelUC)
+		routes = append(routes,
+			route{method: "POST", path: "/submissions/:id/cancel", handler: subHandler.Cancel, limited: true, authenticated: true, owned: true, versions: []string{"v2"},
+				response: domain.CancelResponse{}},
+		)
+	}
+	if deps.PurgeUC != nil {
+		// Owners purge their own submissions; operators purge any.
+		subHandler.SetPurge(deps.PurgeUC)
+		routes = append(routes,
+			route{method: "DELETE", path: "/submissions/:id", handler: subHandler.Purge, limited: true, authenticated: true, scope: domain.ScopeSubmit, owned: true, admin: true, versions: []string{"v2"},
+				status: http.StatusNoContent, query: []string{"purge"}},
+		)
+	}
+
+	// Problems and their versioned test data
+	if deps.ProblemUC != nil {
+		problemHandler := NewProblemHandler(deps.ProblemUC, deps.Logger)
+		routes = append(routes,
+			route{method: "POST", path: "/problems", handler: problemHandler.Create, limited: true, authenticated: true,
+				body: domain.CreateProblemRequest{}, response: domain.Problem{}, status: http.StatusCreated},
+			route{method: "GET", path: "/problems/:id", handler: problemHandler.GetByID, limited: true, authenticated: true,
+				response: domain.Problem{}},
+			route{method: "PUT", path: "/problems/:id/testdata", handler: problemHandler.UpdateTestData, limited: true, admin: true,
+				body: domain.UpdateTestDataRequest{}, response: domain.Problem{}},
+			route{method: "POST", path: "/problems/:id/rejudge", handler: problemHandler.Rejudge, limited: true, admin: true,
+				response: domain.RejudgeResponse{}, status: http.StatusAccepted},
+		)
+	}
+
+	// Appeals on judged submissions
+	if deps.AppealUC != nil {
+		appealHandler := NewAppealHandler(deps.AppealUC, deps.Logger)
+		routes = append(routes,
+			route{method: "POST", path: "/submissions/:id/appeals", handler: appealHandler.Open, limited: true, authenticated: true, owned: true,
+				body: domain.OpenAppealRequest{}, response: domain.Appeal{}, status: http.StatusCreated},
+			route{method: "GET", path: "/appeals", handler: appealHandler.List, limited: true, authenticated: true, admin: true, query: []string{"status"}},
+			route{method: "GET", path: "/appeals/:id", handler: appealHandler.GetByID, limited: true, authenticated: true, admin: true,
+				response: domain.Appeal{}},
+			route{method: "POST", path: "/appeals/:id/rerun", handler: appealHandler.Rerun, limited: true, admin: true,
+				response: domain.RerunResponse{}, status: http.StatusAccepted},
+			route{method: "POST", path: "/appeals/:id/override", handler: appealHandler.Override, limited: true, admin: true,
+				body: domain.OverrideVerdictRequest{}, response: domain.Appeal{}},
+			route{method: "POST", path: "/appeals/:id/close", handler: appealHandler.Close, limited: true, admin: true,
+				body: domain.CloseAppealRequest{}, response: domain.Appeal{}},
+		)
+	}
+
+	if deps.RuntimeUC != nil {
+		runtimeHandler := NewRuntimeHandler(deps.RuntimeUC, deps.Logger)
+		routes = append(routes,
+			route{method: "POST", path: "/runtimes", handler: runtimeHandler.Create, limited: true, admin: true,
+				body: domain.CreateRuntimeRequest{}, response: domain.Runtime{}, status: http.StatusCreated},
+			route{method: "GET", path: "/runtimes", handler: runtimeHandler.List, limited: true, authenticated: true},
+			route{method: "GET", path: "/runtimes/:name", handler: runtimeHandler.GetByName, limited: true, authenticated: true,
+				response: domain.Runtime{}},
+			route{method: "PUT", path: "/runtimes/:name", handler: runtimeHandler.Update, limited: true, admin: true,
+				body: domain.RuntimeSpec{}, response: domain.Runtime{}},
+			route{method: "DELETE", path: "/runtimes/:name", handler: runtimeHandler.Delete, limited: true, admin: true,
+				status: http.StatusNoContent},
+		)
+	}
+
+	// User accounts. Registering acts as the request's tenant; logging in
+	// needs only the user's credentials.
+	if deps.UserUC != nil {
+		userHandler := NewUserHandler(deps.UserUC, deps.Logger)
+		v2 := []string{"v2"}
+		routes = append(routes,
+			route{method: "POST", path: "/users", handler: userHandler.Register, limited: true, authenticated: true, versions: v2,
+				body: domain.RegisterUserRequest{}, response: domain.User{}, status: http.StatusCreated},
+			route{method: "POST", path: "/users/login", handler: userHandler.Login, limited: true, versions: v2,
+				body: domain.LoginRequest{}, response: domain.UserToken{}},
+		)
+	}
+
+	// The calling tenant's dead-letter and result webhooks
+	if deps.WebhookUC != nil {
+		webhookHandler := NewWebhookHandler(deps.WebhookUC, deps.Logger)
+		v2 := []string{"v2"}
+		routes = append(routes,
+			route{method: "PUT", path: "/webhooks/dlq", handler: webhookHandler.SetDLQ, limited: true, authenticated: true, versions: v2,
+				body: domain.SetDLQWebhookRequest{}, response: domain.DLQWebhook{}},
+			route{method: "GET", path: "/webhooks/dlq", handler: webhookHandler.GetDLQ, limited: true, authenticated: true, versions: v2,
+				response: domain.DLQWebhook{}},
+			route{method: "DELETE", path: "/webhooks/dlq", handler: webhookHandler.DeleteDLQ, limited: true, authenticated: true, versions: v2,
+				status: http.StatusNoContent},
+		)
+		if deps.ResultWebhooks {
+			routes = append(routes,
+				route{method: "PUT", path: "/webhooks/results", handler: webhookHandler.SetResults, limited: true, authenticated: true, versions: v2,
+					body: domain.SetResultWebhookRequest{}, response: domain.ResultWebhook{}},
+				route{method: "GET", path: "/webhooks/results", handler: webhookHandler.GetResults, limited: true, authenticated: true, versions: v2,
+					response: domain.ResultWebhook{}},
+				route{method: "DELETE", path: "/webhooks/results", handler: webhookHandler.DeleteResults, limited: true, authenticated: true, versions: v2,
+					status: http.StatusNoContent},
+				route{method: "GET", path: "/webhooks/results/deliveries", handler: webhookHandler.ListDeliveries, limited: true, authenticated: true, versions: v2,
+					response: domain.DeliveryPage{}, query: []string{"status", "before", "limit"}},
+				route{method: "GET", path: "/webhooks/results/deliveries/:id", handler: webhookHandler.GetDelivery, limited: true, authenticated: true, versions: v2,
+					response: domain.WebhookDelivery{}},
+				route{method: "POST", path: "/webhooks/results/deliveries/:id/redrive", handler: webhookHandler.RedriveDelivery, limited: true, authenticated: true, versions: v2,
+					response: domain.WebhookDelivery{}, status: http.StatusAccepted},
+			)
+		}
+	}
+
+	// The calling tenant's recurring schedules
+	if deps.ScheduleUC != nil {
+		scheduleHandler := NewScheduleHandler(deps.ScheduleUC, deps.Logger)
+		v2 := []string{"v2"}
+		routes = append(routes,
+			route{method: "POST", path: "/schedules", handler: scheduleHandler.Create, limited: true, authenticated: true, scope: domain.ScopeSubmit, versions: v2,
+				body: domain.CreateScheduleRequest{}, response: domain.Schedule{}, status: http.StatusCreated},
+			route{method: "GET", path: "/schedules", handler: scheduleHandler.List, limited: true, authenticated: true, versions: v2},
+			route{method: "GET", path: "/schedules/:id", handler: scheduleHandler.GetByID, limited: true, authenticated: true, versions: v2,
+				response: domain.Schedule{}},
+			route{method: "DELETE", path: "/schedules/:id", handler: scheduleHandler.Delete, limited: true, authenticated: true, versions: v2,
+				status: http.StatusNoContent},
+		)
+	}
+
+	// GitHub submission intake. The webhook is not rate limited: deliveries
+	// come from GitHub's few addresses and are authenticated by signature.
+	if deps.GitHubUC != nil {
+		githubHandler := NewGitHubHandler(deps.GitHubUC, deps.Logger)
+		v2 := []string{"v2"}
+		routes = append(routes,
+			route{method: "PUT", path: "/integrations/github/repos/:owner/:name", handler: githubHandler.SetRepo, limited: true, authenticated: true, versions: v2,
+				body: domain.SetGitHubRepoRequest{}, response: domain.GitHubRepo{}},
+			route{method: "GET", path: "/integrations/github/repos/:owner/:name", handler: githubHandler.GetRepo, limited: true, authenticated: true, versions: v2,
+				response: domain.GitHubRepo{}},
+			route{method: "DELETE", path: "/integrations/github/repos/:owner/:name", handler: githubHandler.DeleteRepo, limited: true, authenticated: true, versions: v2,
+				status: http.StatusNoContent},
+			// GitHub's payload is checked against its signature, not a schema.
+			route{method: "POST", path: "/integrations/github/webhook", handler: githubHandler.Webhook, versions: v2,
+				response: domain.GitHubDelivery{}},
+		)
+	}
+
+	// LTI 1.3 tool. The login and launch endpoints are not rate limited:
+	// browsers reach them through the platform and launches are
+	// authenticated by the platform's signature.
+	if deps.LTIUC != nil {
+		ltiHandler := NewLTIHandler(deps.LTIUC, deps.Logger)
+		v2 := []string{"v2"}
+		routes = append(routes,
+			route{method: "POST", path: "/integrations/lti/platforms", handler: ltiHandler.RegisterPlatform, limited: true, authenticated: true, versions: v2,
+				body: domain.RegisterLTIPlatformRequest{}, response: domain.LTIPlatform{}, status: http.StatusCreated},
+			route{method: "GET", path: "/integrations/lti/platforms/:id", handler: ltiHandler.GetPlatform, limited: true, authenticated: true, versions: v2,
+				response: domain.LTIPlatform{}},
+			route{method: "DELETE", path: "/integrations/lti/platforms/:id", handler: ltiHandler.DeletePlatform, limited: true, authenticated: true, versions: v2,
+				status: http.StatusNoContent},
+			route{method: "GET", path: "/integrations/lti/platforms/:id/users", handler: ltiHandler.ListUsers, limited: true, authenticated: true, versions: v2},
+			route{method: "GET", path: "/integrations/lti/jwks", handler: ltiHandler.JWKS, versions: v2,
+				response: lti.JWKS{}},
+			route{method: "GET", path: "/integrations/lti/login", handler: ltiHandler.Login, versions: v2,
+				status: http.StatusFound},
+			route{method: "POST", path: "/integrations/lti/login", handler: ltiHandler.Login, versions: v2,
+				status: http.StatusFound},
+			route{method: "POST", path: "/integrations/lti/launch", handler: ltiHandler.Launch, versions: v2,
+				response: domain.LTILaunch{}, status: http.StatusCreated},
+			route{method: "GET", path: "/integrations/lti/launches/:id", handler: ltiHandler.GetLaunch, limited: true, versions: v2,
+				response: domain.LTILaunch{}},
+			route{method: "POST", path: "/integrations/lti/launches/:id/submissions", handler: ltiHandler.Submit, limited: true, versions: v2,
+				body: domain.LTISubmitRequest{}, response: domain.SubmitResponse{}, status: http.StatusAccepted},
+		)
+	}
+
+	// Operator recovery routines
+	if deps.RepairUC != nil {
+		adminHandler := NewAdminHandler(deps.RepairUC, deps.AdminToken, deps.Logger)
+		routes = append(routes,
+			route{method: "POST", path: "/admin/repair", handler: adminHandler.Repair, limited: true, admin: true, versions: []string{"v2"},
+				body: domain.RepairRequest{}, response: domain.RepairReport{}},
+		)
+		adminHandler.SetJobs(deps.GetJobUC)
+		routes = append(routes,
+			route{method: "GET", path: "/admin/jobs", handler: adminHandler.Jobs, limited: true, admin: true, versions: []string{"v2"},
+				response: domain.JobPage{}, query: []string{"status", "cursor", "limit"}},
+		)
+		if deps.APIKeyUC != nil {
+			adminHandler.SetAPIKeys(deps.APIKeyUC)
+			routes = append(routes,
+				route{method: "POST", path: "/admin/api-keys", handler: adminHandler.IssueKey, limited: true, admin: true, versions: []string{"v2"},
+					body: domain.IssueAPIKeyRequest{}, response: domain.IssuedAPIKey{}, status: http.StatusCreated},
+				route{method: "GET", path: "/admin/api-keys", handler: adminHandler.ListKeys, limited: true, admin: true, versions: []string{"v2"},
+					query: []string{"tenant_id"}},
+				route{method: "POST", path: "/admin/api-keys/:id/rotate", handler: adminHandler.RotateKey, limited: true, admin: true, versions: []string{"v2"},
+					response: domain.IssuedAPIKey{}},
+				route{method: "DELETE", path: "/admin/api-keys/:id", handler: adminHandler.RevokeKey, limited: true, admin: true, versions: []string{"v2"},
+					status: http.StatusNoContent},
+			)
+		}
+		if deps.QueuePauseUC != nil {
+			adminHandler.SetQueuePauses(deps.QueuePauseUC)
+			routes = append(routes,
+				route{method: "GET", path: "/admin/queue/pauses", handler: adminHandler.ListPauses, limited: true, admin: true, versions: []string{"v2"}},
+				route{method: "PUT", path: "/admin/queue/pauses/:language", handler: adminHandler.Pause, limited: true, admin: true, versions: []string{"v2"},
+					response: domain.QueuePause{}},
+				route{method: "DELETE", path: "/admin/queue/pauses/:language", handler: adminHandler.Resume, limited: true, admin: true, versions: []string{"v2"},
+					response: domain.QueueResume{}},
+			)
+		}
+		if deps.CancelUC != nil {
+			adminHandler.SetCancels(deps.CancelUC)
+			routes = append(routes,
+				route{method: "POST", path: "/admin/cancel", handler: adminHandler.Cancel, limited: true, admin: true, versions: []string{"v2"},
+					body: domain.BulkCancelRequest{}, response: domain.BulkCancelReport{}},
+			)
+		}
+		if deps.HardeningUC != nil {
+			adminHandler.SetHardening(deps.HardeningUC)
+			routes = append(routes,
+				route{method: "POST", path: "/admin/sandbox/hardening", handler: adminHandler.Hardening, limited: true, admin: true, versions: []string{"v2"},
+					body: domain.HardeningRequest{}, response: domain.HardeningReport{}},
+			)
+		}
+		if deps.SecurityEventUC != nil {
+			adminHandler.SetSecurityEvents(deps.SecurityEventUC)
+			routes = append(routes,
+				route{method: "GET", path: "/admin/security-events", handler: adminHandler.SecurityEvents, limited: true, admin: true, versions: []string{"v2"},
+					response: domain.SecurityEventPage{}, query: []string{"format", "kind", "tenant_id", "cursor", "limit"}},
+			)
+		}
+	}
+
+	// WebSocket for real-time updates (no rate limiting — streams are capped per client)
+	wsHandler := NewWebSocketHandler(deps.GetJobUC, deps.Logger)
+	if deps.StreamShutdown != nil {
+		wsHandler.SetShutdown(deps.StreamShutdown)
+	}
+	if deps.InteractiveUC != nil {
+		wsHandler.SetInteractive(deps.InteractiveUC)
+	}
+	routes = append(routes, route{method: "GET", path: "/submissions/:id/stream", handler: wsHandler.Stream, authenticated: true, owned: true,
+		status: http.StatusSwitchingProtocols, query: []string{resumeTokenParam}})
+
+	return routes
 }
 
 // NewRouter creates and configures the Gin router with all routes and middleware.
@@ -31,35 +418,103 @@ func NewRouter(deps *RouterDeps) *gin.Engine {
 	router.Use(middleware.RequestID())
 	router.Use(middleware.CORS())
 	router.Use(middleware.Logger(deps.Logger))
+	router.Use(middleware.Compress(1 << 10))      // gzip responses from 1 KB
 	router.Use(middleware.BodySizeLimit(1 << 20)) // 1 MB max request body
 
 	// Metrics endpoint (no rate limiting)
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	// API v1 group
-	v1 := router.Group("/api/v1")
-	{
-		// Health check (no rate limiting)
-		healthHandler := NewHealthHandler(deps.Logger, deps.DBPool, deps.AmqpURI, deps.Redis)
-		v1.GET("/health", healthHandler.Health)
-
-		// Languages
-		langHandler := NewLanguageHandler()
-		v1.GET("/languages", langHandler.List)
-
-		// Apply rate limiter to submission endpoints
-		rateLimited := v1.Group("")
-		rateLimited.Use(middleware.RateLimiter(deps.Redis, deps.RateLimitPerMin))
-		{
-			// Submissions
-			subHandler := NewSubmissionHandler(deps.SubmitUC, deps.GetJobUC, deps.Logger)
-			rateLimited.POST("/submissions", subHandler.Submit)
-			rateLimited.GET("/submissions/:id", subHandler.GetByID)
+	// Static UI (no rate limiting). It only calls the v2 API.
+	if deps.UI {
+		router.GET("/ui/*filepath", gin.WrapH(ui.Handler("/ui/")))
+	}
+
+	// One limiter for all versions, so a client's budget is shared.
+	rateLimiter := middleware.RateLimiter(middleware.NewRateLimit(deps.Redis, deps.RateLimitAlgorithm,
+		deps.RateLimitFailMode, deps.RateLimitPerMin, deps.RateLimitBurst))
+	var apiKeys gin.HandlerFunc
+	var keyAuth middleware.APIKeyAuthenticator
+	if deps.APIKeyUC != nil {
+		apiKeys = middleware.APIKey(deps.APIKeyUC, deps.APIKeysRequired, deps.Logger)
+		keyAuth = deps.APIKeyUC
+	}
+	var users gin.HandlerFunc
+	jobAccess := middleware.JobAccess(deps.GetJobUC, deps.Logger)
+	var userAuth middleware.UserAuthenticators
+	if deps.UserUC != nil {
+		userAuth = append(userAuth, deps.UserUC)
+	}
+	if deps.OIDCUC != nil {
+		userAuth = append(userAuth, deps.OIDCUC)
+	}
+	if len(userAuth) > 0 {
+		users = middleware.UserToken(userAuth, deps.Logger)
+	}
+	routes := apiRoutes(deps)
+	served := make(map[string]bool)
+	for _, version := range apiVersions {
+		group := router.Group("/api/" + version)
+		served[version] = true
+		// Each version has its own schemas, so its document only lists the
+		// types its routes use.
+		schemas := newSchemaGenerator()
+		for _, r := range routes {
+			if !r.servedBy(version) {
+				continue
+			}
+			fullPath := group.BasePath() + r.path
+			served[r.method+" "+fullPath] = true
+
+			var handlers []gin.HandlerFunc
+			if notice, ok := deps.Deprecations.Lookup(version, r.method, fullPath); ok {
+				handlers = append(handlers, middleware.Deprecation(notice))
+			}
+			if r.limited {
+				handlers = append(handlers, rateLimiter)
+			}
+			if r.admin {
+				handlers = append(handlers, middleware.Admin(deps.AdminToken, keyAuth, !r.authenticated, deps.Logger))
+			}
+			// Operators skip the tenant's checks of routes open to both.
+			tenant := func(h gin.HandlerFunc) gin.HandlerFunc {
+				if r.admin {
+					return middleware.UnlessAdmin(h)
+				}
+				return h
+			}
+			if r.authenticated && users != nil {
+				handlers = append(handlers, tenant(users))
+			}
+			if r.authenticated && apiKeys != nil {
+				handlers = append(handlers, tenant(apiKeys))
+			}
+			if r.scope != "" {
+				handlers = append(handlers, tenant(middleware.RequireScope(r.scope)))
+			}
+			if r.owned && (users != nil || apiKeys != nil) {
+				handlers = append(handlers, tenant(jobAccess))
+			}
+			if r.form != nil {
+				handlers = append(handlers, r.form)
+			}
+			if r.body != nil {
+				handlers = append(handlers, middleware.ValidateBody(schemas, schemas.SchemaOf(r.body)))
+			}
+			group.Handle(r.method, r.path, append(handlers, r.handler)...)
 		}
 
-		// WebSocket for real-time updates (no rate limiting — one connection per job)
-		wsHandler := NewWebSocketHandler(deps.GetJobUC, deps.Logger)
-		v1.GET("/submissions/:id/stream", wsHandler.Stream)
+		// The version's OpenAPI document (no rate limiting)
+		spec, err := json.Marshal(openAPIDocument(schemas, version, routes, deps))
+		if err != nil {
+			panic(err) // the document holds only strings, maps and slices
+		}
+		group.GET("/openapi.json", func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+		})
+		served["GET "+group.BasePath()+"/openapi.json"] = true
+	}
+	for _, unknown := range deps.Deprecations.Unknown(served) {
+		deps.Logger.Warn("Deprecation policy names a version or route that is not served", zap.String("entry", unknown))
 	}
 
 	return router
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// RuntimeHandler handles HTTP requests for runtimes registered through the API.
+type RuntimeHandler struct {
+	runtimeUC *usecase.RuntimeUsecase
+	logger    *zap.Logger
+}
+
+// NewRuntimeHandler creates a new RuntimeHandler.
+func NewRuntimeHandler(runtimeUC *usecase.RuntimeUsecase, logger *zap.Logger) *RuntimeHandler {
+	return &RuntimeHandler{
+		runtimeUC: runtimeUC,
+		logger:    logger,
+	}
+}
+
+// Create handles POST /api/v1/runtimes
+func (h *RuntimeHandler) Create(c *gin.Context) {
+	var req domain.CreateRuntimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rt, err := h.runtimeUC.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.writeError(c, "Create runtime failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, rt)
+}
+
+// List handles GET /api/v1/runtimes
+func (h *RuntimeHandler) List(c *gin.Context) {
+	runtimes, err := h.runtimeUC.List(c.Request.Context())
+	if err != nil {
+		h.writeError(c, "List runtimes failed", err)
+		return
+	}
+	if runtimes == nil {
+		runtimes = []*domain.Runtime{}
+	}
+	c.JSON(http.StatusOK, gin.H{"runtimes": runtimes})
+}
+
+// GetByName handles GET /api/v1/runtimes/:name
+func (h *RuntimeHandler) GetByName(c *gin.Context) {
+	rt, err := h.runtimeUC.Get(c.Request.Context(), domain.Language(c.Param("name")))
+	if err != nil {
+		h.writeError(c, "Get runtime failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, rt)
+}
+
+// Update handles PUT /api/v1/runtimes/:name
+func (h *RuntimeHandler) Update(c *gin.Context) {
+	var spec domain.RuntimeSpec
+	if err := c.ShouldBindJSON(&spec); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	rt, err := h.runtimeUC.Update(c.Request.Context(), domain.Language(c.Param("name")), &spec)
+	if err != nil {
+		h.writeError(c, "Update runtime failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, rt)
+}
+
+// Delete handles DELETE /api/v1/runtimes/:name
+func (h *RuntimeHandler) Delete(c *gin.Context) {
+	if err := h.runtimeUC.Delete(c.Request.Context(), domain.Language(c.Param("name"))); err != nil {
+		h.writeError(c, "Delete runtime failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *RuntimeHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrRuntimeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRuntimeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidRuntime):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
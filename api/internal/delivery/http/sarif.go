@@ -0,0 +1,102 @@
+package http
+
+import (
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// sarifVersion and sarifSchema identify the SARIF format security events
+// are exported in, which SIEM pipelines and code scanning tools ingest.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifRules describes each kind of security event as a SARIF rule, with
+// the level its results are reported at.
+var sarifRules = map[domain.SecurityEventKind]struct{ description, level string }{
+	domain.SecuritySeccompViolation: {"The program made a syscall its language's seccomp policy forbids and was killed.", "error"},
+	domain.SecurityNetworkBlocked:   {"The run sent packets to destinations outside the network allowlist, which were dropped.", "warning"},
+	domain.SecurityAbuseFlag:        {"The output matched one of the problem's kill patterns and the run was stopped.", "warning"},
+	domain.SecurityQuarantine:       {"The job's message kept being redelivered and was quarantined without running.", "warning"},
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool          `json:"tool"`
+	Results    []sarifResult      `json:"results"`
+	Properties sarifRunProperties `json:"properties"`
+}
+
+// sarifRunProperties carry the page's cursor, so consumers page through
+// SARIF exports as through JSON ones.
+type sarifRunProperties struct {
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                `json:"ruleId"`
+	Level      string                `json:"level,omitempty"`
+	Message    sarifMessage          `json:"message"`
+	Properties *domain.SecurityEvent `json:"properties"`
+}
+
+// securityEventsSARIF renders a page of security events as a SARIF log with
+// one run, one rule per kind and one result per event. Each result's
+// properties are the event as the JSON export lists it.
+func securityEventsSARIF(page *domain.SecurityEventPage) *sarifLog {
+	run := sarifRun{
+		Tool:       sarifTool{Driver: sarifDriver{Name: "Sentinel"}},
+		Results:    []sarifResult{},
+		Properties: sarifRunProperties{NextCursor: page.NextCursor, HasMore: page.HasMore},
+	}
+	for _, kind := range domain.SecurityEventKinds {
+		rule := sarifRules[kind]
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   string(kind),
+			ShortDescription:     sarifMessage{Text: rule.description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.level},
+		})
+	}
+	for _, e := range page.Events {
+		text := sarifRules[e.Kind].description
+		if e.Detail != "" {
+			text = e.Detail
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:     string(e.Kind),
+			Level:      sarifRules[e.Kind].level,
+			Message:    sarifMessage{Text: text},
+			Properties: e,
+		})
+	}
+	return &sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// ScheduleHandler handles HTTP requests for the calling tenant's recurring
+// schedules. The tenant comes from the X-Tenant-ID header, as for
+// submissions.
+type ScheduleHandler struct {
+	scheduleUC *usecase.ScheduleUsecase
+	logger     *zap.Logger
+}
+
+// NewScheduleHandler creates a new ScheduleHandler.
+func NewScheduleHandler(scheduleUC *usecase.ScheduleUsecase, logger *zap.Logger) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleUC: scheduleUC,
+		logger:     logger,
+	}
+}
+
+// Create handles POST /api/v2/schedules
+func (h *ScheduleHandler) Create(c *gin.Context) {
+	var req domain.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	s, err := h.scheduleUC.Create(c.Request.Context(), c.GetHeader(tenantIDHeader), &req)
+	if err != nil {
+		h.writeError(c, "Create schedule failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, s)
+}
+
+// List handles GET /api/v2/schedules
+func (h *ScheduleHandler) List(c *gin.Context) {
+	schedules, err := h.scheduleUC.List(c.Request.Context(), c.GetHeader(tenantIDHeader))
+	if err != nil {
+		h.writeError(c, "List schedules failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// GetByID handles GET /api/v2/schedules/:id
+func (h *ScheduleHandler) GetByID(c *gin.Context) {
+	id, ok := idParam(c, "Invalid schedule ID format")
+	if !ok {
+		return
+	}
+	s, err := h.scheduleUC.Get(c.Request.Context(), c.GetHeader(tenantIDHeader), id)
+	if err != nil {
+		h.writeError(c, "Get schedule failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// Delete handles DELETE /api/v2/schedules/:id
+func (h *ScheduleHandler) Delete(c *gin.Context) {
+	id, ok := idParam(c, "Invalid schedule ID format")
+	if !ok {
+		return
+	}
+	if err := h.scheduleUC.Delete(c.Request.Context(), c.GetHeader(tenantIDHeader), id); err != nil {
+		h.writeError(c, "Delete schedule failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ScheduleHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPayloadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -1,22 +1,38 @@
 package http
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
 	"github.com/Harsh-BH/Sentinel/api/internal/domain"
 	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
 )
 
+// tenantIDHeader identifies the tenant a submission is billed against.
+const tenantIDHeader = "X-Tenant-ID"
+
 // SubmissionHandler handles HTTP requests for code submissions.
 type SubmissionHandler struct {
 	submitUC *usecase.SubmitJobUsecase
 	getJobUC *usecase.GetJobUsecase
 	logger   *zap.Logger
+
+	// cancelUC is optional; Cancel is only routed when it is set.
+	cancelUC *usecase.CancelJobUsecase
+	// purgeUC is optional; Purge is only routed when it is set.
+	purgeUC *usecase.PurgeJobUsecase
 }
 
 // NewSubmissionHandler creates a new SubmissionHandler.
@@ -28,8 +44,24 @@ func NewSubmissionHandler(submitUC *usecase.SubmitJobUsecase, getJobUC *usecase.
 	}
 }
 
-// Submit handles POST /api/v1/submissions
+// SetCancel enables cancelling submissions.
+func (h *SubmissionHandler) SetCancel(cancelUC *usecase.CancelJobUsecase) {
+	h.cancelUC = cancelUC
+}
+
+// SetPurge enables permanently deleting submissions.
+func (h *SubmissionHandler) SetPurge(purgeUC *usecase.PurgeJobUsecase) {
+	h.purgeUC = purgeUC
+}
+
+// Submit handles POST /api/v1/submissions. Multipart uploads reach it as
+// JSON bodies; see submissionUpload. With base64_encoded=true the source,
+// stdin and expected output are sent base64-encoded.
 func (h *SubmissionHandler) Submit(c *gin.Context) {
+	encoded, ok := base64Encoded(c)
+	if !ok {
+		return
+	}
 	var req domain.SubmitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -37,29 +69,168 @@ func (h *SubmissionHandler) Submit(c *gin.Context) {
 		})
 		return
 	}
+	if encoded {
+		if err := req.DecodeBase64(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	req.TenantID = c.GetHeader(tenantIDHeader)
+	req.APIKeyID = apiKeyID(c)
+	req.UserID = userID(c)
 
 	resp, err := h.submitUC.Execute(c.Request.Context(), &req)
 	if err != nil {
-		switch {
-		case errors.Is(err, domain.ErrInvalidLanguage):
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case errors.Is(err, domain.ErrEmptySourceCode):
+		h.writeSubmitError(c, "Submit job failed", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// base64Encoded parses the base64_encoded query parameter, false when
+// absent. It answers 400 Bad Request and returns !ok when it is not a
+// boolean.
+func base64Encoded(c *gin.Context) (encoded, ok bool) {
+	s := c.Query("base64_encoded")
+	if s == "" {
+		return false, true
+	}
+	encoded, err := strconv.ParseBool(s)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid base64_encoded"})
+		return false, false
+	}
+	return encoded, true
+}
+
+// apiKeyID returns the ID of the API key the request was authenticated
+// with, or nil.
+func apiKeyID(c *gin.Context) *uuid.UUID {
+	v, ok := c.Get(middleware.APIKeyContextKey)
+	if !ok {
+		return nil
+	}
+	id := v.(*domain.APIKey).KeyID
+	return &id
+}
+
+// List handles GET /api/v2/submissions. A signed-in user sees only the
+// submissions they made, and an API key without the read:any scope only
+// those made with it; other requests see their tenant's.
+func (h *SubmissionHandler) List(c *gin.Context) {
+	var limit int
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	ownKey, ownUser := middleware.JobOwner(c)
+	page, err := h.getJobUC.ListSubmissions(c.Request.Context(), c.GetHeader(tenantIDHeader), ownKey, ownUser,
+		domain.ExecutionStatus(c.Query("status")), c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidJobFilter) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case errors.Is(err, domain.ErrPayloadTooLarge):
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
-		case errors.Is(err, domain.ErrPublishFailed):
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service temporarily unavailable"})
-		default:
-			h.logger.Error("Submit job failed", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
 		}
+		h.logger.Error("List submissions failed", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// Rerun handles POST /api/v2/submissions/:id/rerun
+func (h *SubmissionHandler) Rerun(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	resp, err := h.submitUC.Rerun(c.Request.Context(), id)
+	if err != nil {
+		h.writeSubmitError(c, "Rerun job failed", err)
 		return
 	}
 
 	c.JSON(http.StatusAccepted, resp)
 }
 
-// GetByID handles GET /api/v1/submissions/:id
+// writeSubmitError maps errors of creating a job to responses.
+func (h *SubmissionHandler) writeSubmitError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+	case errors.Is(err, domain.ErrInvalidLanguage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrEmptySourceCode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidSource):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidCompilerFlags):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidArgs):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidEnv):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidSandboxTier):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidNetworkPolicy):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidOutputFiles):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidInteractive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidExpectedOutput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidPriority):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidRunAt):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidExternalID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrExternalIDExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPayloadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrProblemNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQuotaExceeded):
+		writeQuotaExceeded(c, h.submitUC.QuotaResetAt(time.Now()), err)
+	case errors.Is(err, domain.ErrPublishFailed):
+		writeQueueUnavailable(c)
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// writeQuotaExceeded answers a submission over its tenant's quota, with a
+// hint to retry once the quota resets at resetAt, unless that is zero.
+func writeQuotaExceeded(c *gin.Context, resetAt time.Time, err error) {
+	if resetAt.IsZero() {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "reason": middleware.RetryQuotaExceeded})
+		return
+	}
+	c.Header("X-Quota-Reset", resetAt.Format(time.RFC3339))
+	middleware.RetryLater(c, http.StatusTooManyRequests, middleware.RetryQuotaExceeded, time.Until(resetAt), err.Error())
+}
+
+// writeQueueUnavailable answers a request whose job could not be queued.
+func writeQueueUnavailable(c *gin.Context) {
+	middleware.RetryLater(c, http.StatusServiceUnavailable, middleware.RetryQueueUnavailable,
+		middleware.QueueRetryAfter, "Service temporarily unavailable")
+}
+
+// GetByID handles GET /api/v1/submissions/:id. With base64_encoded=true
+// the job's text fields are answered base64-encoded; see Job.Base64Encoded.
 func (h *SubmissionHandler) GetByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -67,8 +238,23 @@ func (h *SubmissionHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
 		return
 	}
+	encoded, ok := base64Encoded(c)
+	if !ok {
+		return
+	}
+
+	fields, err := domain.ParseJobFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	job, err := h.getJobUC.Execute(c.Request.Context(), id)
+	var job *domain.Job
+	if fields == nil {
+		job, err = h.getJobUC.Execute(c.Request.Context(), id)
+	} else {
+		job, err = h.getJobUC.ExecuteFields(c.Request.Context(), id, fields)
+	}
 	if err != nil {
 		if errors.Is(err, domain.ErrJobNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
@@ -78,6 +264,219 @@ func (h *SubmissionHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
+	if encoded {
+		job = job.Base64Encoded()
+	}
 
+	if fields == nil {
+		c.JSON(http.StatusOK, job)
+		return
+	}
+	projected, err := domain.ProjectJob(job, fields)
+	if err != nil {
+		h.logger.Error("Project job failed", zap.Error(err), zap.String("job_id", idStr))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, projected)
+}
+
+// GetByExternalID handles GET /api/v2/submissions/by-external-id/:id,
+// looking the job up among the tenant's by the ID it was submitted with.
+// It takes base64_encoded like GetByID.
+func (h *SubmissionHandler) GetByExternalID(c *gin.Context) {
+	externalID := c.Param("id")
+	encoded, ok := base64Encoded(c)
+	if !ok {
+		return
+	}
+	job, err := h.getJobUC.ByExternalID(c.Request.Context(), c.GetHeader(tenantIDHeader), externalID)
+	if err == nil && !middleware.CanReadJob(c, job) {
+		err = domain.ErrJobNotFound
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		h.logger.Error("Get job by external ID failed", zap.Error(err), zap.String("external_id", externalID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if encoded {
+		job = job.Base64Encoded()
+	}
 	c.JSON(http.StatusOK, job)
 }
+
+// Cancel handles POST /api/v2/submissions/:id/cancel
+func (h *SubmissionHandler) Cancel(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	resp, err := h.cancelUC.Execute(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, domain.ErrJobFinished):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("Cancel job failed", zap.Error(err), zap.String("job_id", idStr))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	// A running job is cancelled once its worker stops it.
+	if resp.Status != domain.StatusCancelled {
+		c.JSON(http.StatusAccepted, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Purge handles DELETE /api/v2/submissions/:id?purge=true
+func (h *SubmissionHandler) Purge(c *gin.Context) {
+	// The owned route lets anonymous requests read any job, but only the
+	// job's owner or an operator may delete it.
+	if middleware.Actor(c) == "" {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+	// Deletion is irreversible, so the caller must ask for it explicitly.
+	if c.Query("purge") != "true" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Deleting a submission requires purge=true"})
+		return
+	}
+
+	if err := h.purgeUC.Execute(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, domain.ErrJobActive):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("Purge job failed", zap.Error(err), zap.String("job_id", idStr))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Stdout handles GET /api/v2/submissions/:id/stdout
+func (h *SubmissionHandler) Stdout(c *gin.Context) {
+	h.serveOutput(c, "stdout")
+}
+
+// Stderr handles GET /api/v2/submissions/:id/stderr
+func (h *SubmissionHandler) Stderr(c *gin.Context) {
+	h.serveOutput(c, "stderr")
+}
+
+// serveOutput sends one stored output stream as plain text, reading only
+// that column. http.ServeContent answers Range, If-Range and HEAD requests,
+// so clients can page through a large output or resume a download.
+func (h *SubmissionHandler) serveOutput(c *gin.Context, field string) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	job, err := h.getJobUC.ExecuteFields(c.Request.Context(), id, []string{"job_id", field, "updated_at"})
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		h.logger.Error("Get job output failed", zap.Error(err), zap.String("job_id", idStr), zap.String("stream", field))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	output := job.Stdout
+	if field == "stderr" {
+		output = job.Stderr
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s.txt"`, id, field))
+	http.ServeContent(c.Writer, c.Request, "", job.UpdatedAt, strings.NewReader(output))
+}
+
+// Artifact handles GET /api/v2/submissions/:id/artifacts/:name
+//
+// Artifacts are sent as attachments, typed by their extension, so a browser
+// never renders a program's output as part of the API's origin. A truncated
+// artifact is marked with X-Artifact-Truncated and its original size.
+func (h *SubmissionHandler) Artifact(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	name := c.Param("name")
+	artifact, err := h.getJobUC.Artifact(c.Request.Context(), id, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, domain.ErrArtifactNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Artifact not found"})
+		default:
+			h.logger.Error("Get artifact failed", zap.Error(err), zap.String("job_id", idStr), zap.String("artifact", name))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(artifact.Name))
+	if contentType == "" {
+		contentType = http.DetectContentType(artifact.Content)
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": artifact.Name}))
+	if artifact.Truncated {
+		c.Header("X-Artifact-Truncated", "true")
+		c.Header("X-Artifact-Size", strconv.FormatInt(artifact.SizeBytes, 10))
+	}
+	http.ServeContent(c.Writer, c.Request, "", artifact.CreatedAt, bytes.NewReader(artifact.Content))
+}
+
+// Lineage handles GET /api/v2/submissions/:id/lineage
+func (h *SubmissionHandler) Lineage(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID format"})
+		return
+	}
+
+	lineage, err := h.getJobUC.Lineage(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		h.logger.Error("Get job lineage failed", zap.Error(err), zap.String("job_id", idStr))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	c.JSON(http.StatusOK, lineage)
+}
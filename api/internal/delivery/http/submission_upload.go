@@ -0,0 +1,131 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+)
+
+// uploadMemory is how much of a multipart submission is kept in memory;
+// the body size limit keeps whole submissions below it.
+const uploadMemory = 1 << 20
+
+// SubmissionUpload is a submission sent as multipart/form-data: the source
+// and stdin as files, and the other fields of a JSON submission as a JSON
+// object in the request part.
+type SubmissionUpload struct {
+	Source  *multipart.FileHeader `json:"source" binding:"required"`
+	Stdin   *multipart.FileHeader `json:"stdin,omitempty"`
+	Request string                `json:"request,omitempty"`
+}
+
+// submissionUpload turns a multipart/form-data submission into the JSON
+// body Submit binds, so it is validated and handled like one. The language
+// is detected from the source's file name unless the request part names
+// it. Files are read as they are: with base64_encoded=true only the
+// request part's fields are encoded, and the files are encoded here for
+// Submit to decode. Other requests pass through untouched.
+func submissionUpload(langs *language.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.ContentType() != "multipart/form-data" {
+			c.Next()
+			return
+		}
+		body, err := uploadBody(c.Request, langs)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid upload: " + err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Next()
+	}
+}
+
+func uploadBody(r *http.Request, langs *language.Registry) ([]byte, error) {
+	if err := r.ParseMultipartForm(uploadMemory); err != nil {
+		return nil, err
+	}
+	defer r.MultipartForm.RemoveAll()
+	form := r.MultipartForm
+	// An invalid value is rejected by Submit.
+	encoded, _ := strconv.ParseBool(r.URL.Query().Get("base64_encoded"))
+	encode := func(s string) string {
+		if encoded {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		}
+		return s
+	}
+
+	fields := make(map[string]any)
+	if values := form.Value["request"]; len(values) > 0 {
+		dec := json.NewDecoder(bytes.NewReader([]byte(values[0])))
+		dec.UseNumber()
+		if err := dec.Decode(&fields); err != nil || fields == nil {
+			return nil, fmt.Errorf("request must be a JSON object")
+		}
+	}
+	if _, ok := fields["source_code"]; ok {
+		return nil, fmt.Errorf("source_code is read from the source file, not the request part")
+	}
+
+	sources := form.File["source"]
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("exactly one source file is required")
+	}
+	source, err := readUpload(sources[0])
+	if err != nil {
+		return nil, err
+	}
+	fields["source_code"] = encode(source)
+
+	if stdins := form.File["stdin"]; len(stdins) > 0 {
+		if _, ok := fields["stdin"]; ok || len(stdins) > 1 {
+			return nil, fmt.Errorf("stdin is set more than once")
+		}
+		stdin, err := readUpload(stdins[0])
+		if err != nil {
+			return nil, err
+		}
+		fields["stdin"] = encode(stdin)
+	}
+
+	if lang, ok := fields["language"]; !ok || lang == "" {
+		detected, ok := langs.Detect(sources[0].Filename)
+		if !ok {
+			return nil, fmt.Errorf("cannot tell the language of %q; name it in the request part", sources[0].Filename)
+		}
+		fields["language"] = detected
+	}
+	return json.Marshal(fields)
+}
+
+// readUpload reads an uploaded file, which must be UTF-8 text like the
+// JSON fields it stands in for.
+func readUpload(fh *multipart.FileHeader) (string, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", fh.Filename, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", fh.Filename, err)
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("%s is not UTF-8 text", fh.Filename)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// UserHandler handles HTTP requests to register users and log them in.
+// Users register in the tenant the request acts as, as for submissions.
+type UserHandler struct {
+	userUC *usecase.UserUsecase
+	logger *zap.Logger
+}
+
+// NewUserHandler creates a new UserHandler.
+func NewUserHandler(userUC *usecase.UserUsecase, logger *zap.Logger) *UserHandler {
+	return &UserHandler{
+		userUC: userUC,
+		logger: logger,
+	}
+}
+
+// Register handles POST /api/v2/users
+func (h *UserHandler) Register(c *gin.Context) {
+	var req domain.RegisterUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	user, err := h.userUC.Register(c.Request.Context(), c.GetHeader(tenantIDHeader), &req)
+	if err != nil {
+		h.writeError(c, "Register user failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, user)
+}
+
+// Login handles POST /api/v2/users/login
+func (h *UserHandler) Login(c *gin.Context) {
+	var req domain.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	token, err := h.userUC.Login(c.Request.Context(), &req)
+	if err != nil {
+		h.writeError(c, "Login failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+func (h *UserHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidUserRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// userID returns the ID of the user the request was authenticated as, or
+// nil.
+func userID(c *gin.Context) *uuid.UUID {
+	v, ok := c.Get(middleware.UserContextKey)
+	if !ok {
+		return nil
+	}
+	id := v.(*domain.User).UserID
+	return &id
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
+)
+
+// WebhookHandler handles HTTP requests for the calling tenant's webhooks.
+// The tenant comes from the X-Tenant-ID header, as for submissions.
+type WebhookHandler struct {
+	webhookUC *usecase.WebhookUsecase
+	logger    *zap.Logger
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(webhookUC *usecase.WebhookUsecase, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUC: webhookUC,
+		logger:    logger,
+	}
+}
+
+// SetDLQ handles PUT /api/v2/webhooks/dlq
+func (h *WebhookHandler) SetDLQ(c *gin.Context) {
+	var req domain.SetDLQWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	hook, err := h.webhookUC.SetDLQWebhook(c.Request.Context(), c.GetHeader(tenantIDHeader), &req)
+	if err != nil {
+		h.writeError(c, "Set DLQ webhook failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// GetDLQ handles GET /api/v2/webhooks/dlq
+func (h *WebhookHandler) GetDLQ(c *gin.Context) {
+	hook, err := h.webhookUC.GetDLQWebhook(c.Request.Context(), c.GetHeader(tenantIDHeader))
+	if err != nil {
+		h.writeError(c, "Get DLQ webhook failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteDLQ handles DELETE /api/v2/webhooks/dlq
+func (h *WebhookHandler) DeleteDLQ(c *gin.Context) {
+	if err := h.webhookUC.DeleteDLQWebhook(c.Request.Context(), c.GetHeader(tenantIDHeader)); err != nil {
+		h.writeError(c, "Delete DLQ webhook failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetResults handles PUT /api/v2/webhooks/results
+func (h *WebhookHandler) SetResults(c *gin.Context) {
+	var req domain.SetResultWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	hook, err := h.webhookUC.SetResultWebhook(c.Request.Context(), c.GetHeader(tenantIDHeader), &req)
+	if err != nil {
+		h.writeError(c, "Set result webhook failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// GetResults handles GET /api/v2/webhooks/results
+func (h *WebhookHandler) GetResults(c *gin.Context) {
+	hook, err := h.webhookUC.GetResultWebhook(c.Request.Context(), c.GetHeader(tenantIDHeader))
+	if err != nil {
+		h.writeError(c, "Get result webhook failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteResults handles DELETE /api/v2/webhooks/results
+func (h *WebhookHandler) DeleteResults(c *gin.Context) {
+	if err := h.webhookUC.DeleteResultWebhook(c.Request.Context(), c.GetHeader(tenantIDHeader)); err != nil {
+		h.writeError(c, "Delete result webhook failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/v2/webhooks/results/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var limit int
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+	var before int64
+	if s := c.Query("before"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before"})
+			return
+		}
+		before = n
+	}
+
+	page, err := h.webhookUC.ListDeliveries(c.Request.Context(), c.GetHeader(tenantIDHeader),
+		domain.DeliveryStatus(c.Query("status")), before, limit)
+	if err != nil {
+		h.writeError(c, "List deliveries failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetDelivery handles GET /api/v2/webhooks/results/deliveries/:id
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id, ok := deliveryID(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.webhookUC.GetDelivery(c.Request.Context(), c.GetHeader(tenantIDHeader), id)
+	if err != nil {
+		h.writeError(c, "Get delivery failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RedriveDelivery handles POST /api/v2/webhooks/results/deliveries/:id/redrive
+func (h *WebhookHandler) RedriveDelivery(c *gin.Context) {
+	id, ok := deliveryID(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.webhookUC.RedriveDelivery(c.Request.Context(), c.GetHeader(tenantIDHeader), id)
+	if err != nil {
+		h.writeError(c, "Redrive delivery failed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// deliveryID parses the :id parameter, answering 400 if it is malformed.
+func deliveryID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *WebhookHandler) writeError(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound), errors.Is(err, domain.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidWebhook), errors.Is(err, domain.ErrInvalidDeliveryFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrDeliveryPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
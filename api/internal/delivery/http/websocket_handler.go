@@ -1,7 +1,10 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -9,23 +12,44 @@ import (
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
 	"github.com/Harsh-BH/Sentinel/api/internal/domain"
 	"github.com/Harsh-BH/Sentinel/api/internal/usecase"
 )
 
 const (
-	// Maximum duration a WebSocket connection can remain open.
-	wsMaxDuration = 5 * time.Minute
+	// How long a stream may go without an update before it is closed with
+	// wsCloseIdleTimeout.
+	wsIdleTimeout = 5 * time.Minute
 
-	// How often we poll the database for job updates.
+	// Streams one client (by IP) may have open at once; more are closed
+	// with wsCloseRateLimited, asking the client to wait
+	// wsRateLimitedRetryAfter.
+	wsMaxStreamsPerClient   = 10
+	wsRateLimitedRetryAfter = 5 * time.Second
+
+	// How long a stream closed for shutdown or for being too slow asks the
+	// client to wait before reconnecting.
+	wsReconnectAfter = time.Second
+
+	// How often we poll the database for job updates when no status feed
+	// pushes them, and how often a stream checks for a slow client.
 	wsPollInterval = 500 * time.Millisecond
 
+	// How often interactive streams poll, for both job updates and output,
+	// so a REPL answers without a noticeable lag.
+	wsInteractivePollInterval = 100 * time.Millisecond
+
 	// Ping/pong keepalive intervals.
 	wsPingInterval = 30 * time.Second
 	wsPongTimeout  = 10 * time.Second
 
 	// Max message size the server will read from the client.
 	wsMaxMessageSize = 512
+
+	// Max message size on interactive streams, whose messages carry input
+	// of up to 4 KB, JSON-escaped.
+	wsMaxStdinMessageSize = 32 << 10
 )
 
 var upgrader = websocket.Upgrader{
@@ -38,15 +62,55 @@ var upgrader = websocket.Upgrader{
 
 // WebSocketHandler handles WebSocket connections for real-time job status updates.
 type WebSocketHandler struct {
-	getJobUC *usecase.GetJobUsecase
-	logger   *zap.Logger
+	getJobUC    *usecase.GetJobUsecase
+	interactive *usecase.InteractiveUsecase
+	streams     *StreamShutdown
+	logger      *zap.Logger
+
+	mu        sync.Mutex
+	perClient map[string]int
 }
 
 // NewWebSocketHandler creates a new WebSocketHandler.
 func NewWebSocketHandler(getJobUC *usecase.GetJobUsecase, logger *zap.Logger) *WebSocketHandler {
 	return &WebSocketHandler{
-		getJobUC: getJobUC,
-		logger:   logger,
+		getJobUC:  getJobUC,
+		streams:   NewStreamShutdown(),
+		logger:    logger,
+		perClient: make(map[string]int),
+	}
+}
+
+// SetShutdown closes open streams with wsCloseServerShutdown when s is
+// closed, instead of leaving them to drop with the process.
+func (h *WebSocketHandler) SetShutdown(s *StreamShutdown) {
+	h.streams = s
+}
+
+// SetInteractive relays input and output of interactive jobs over their
+// streams. Without it interactive jobs stream like any other and never get
+// input beyond their submitted stdin.
+func (h *WebSocketHandler) SetInteractive(uc *usecase.InteractiveUsecase) {
+	h.interactive = uc
+}
+
+// acquire counts a stream against its client, reporting false if the
+// client already has wsMaxStreamsPerClient open.
+func (h *WebSocketHandler) acquire(client string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.perClient[client] >= wsMaxStreamsPerClient {
+		return false
+	}
+	h.perClient[client]++
+	return true
+}
+
+func (h *WebSocketHandler) release(client string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.perClient[client]--; h.perClient[client] <= 0 {
+		delete(h.perClient, client)
 	}
 }
 
@@ -59,8 +123,17 @@ func (h *WebSocketHandler) Stream(c *gin.Context) {
 		return
 	}
 
+	// A reconnecting client resumes after the last event it saw.
+	resume := wsResumeState{jobID: id}
+	if token := c.Query(resumeTokenParam); token != "" {
+		if resume, err = parseResumeToken(token, id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resume token"})
+			return
+		}
+	}
+
 	// Verify the job exists before upgrading
-	_, err = h.getJobUC.Execute(c.Request.Context(), id)
+	job, err := h.getJobUC.Execute(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
@@ -75,36 +148,150 @@ func (h *WebSocketHandler) Stream(c *gin.Context) {
 
 	h.logger.Debug("WebSocket connection opened", zap.String("job_id", idStr))
 
+	interactive := h.interactive != nil && job.Interactive
+
 	// Configure connection
 	conn.SetReadLimit(wsMaxMessageSize)
+	if interactive {
+		conn.SetReadLimit(wsMaxStdinMessageSize)
+	}
 	conn.SetPongHandler(func(string) error {
 		conn.SetReadDeadline(time.Now().Add(wsPongTimeout + wsPingInterval))
 		return nil
 	})
 
-	// Read pump: consume messages from client (just to detect disconnection)
+	// Writes go through the outbox so a slow client can't stall this loop.
+	out := newWSOutbox(conn)
+	defer out.close()
+
+	// Refusals happen after the upgrade: browsers only expose close codes,
+	// not the status of a failed handshake.
+	if !h.streams.enter() {
+		out.sendClose(wsCloseServerShutdown, middleware.RetryCloseReason(middleware.RetryServerShutdown, wsReconnectAfter))
+		out.wait(wsSlowClientTimeout)
+		return
+	}
+	defer h.streams.leave()
+	client := c.ClientIP()
+	if !h.acquire(client) {
+		h.logger.Info("Too many WebSocket streams for client", zap.String("client", client))
+		out.sendClose(wsCloseRateLimited, middleware.RetryCloseReason(middleware.RetryRateLimited, wsRateLimitedRetryAfter))
+		out.wait(wsSlowClientTimeout)
+		return
+	}
+	defer h.release(client)
+
+	// Read pump: consume messages from client, to detect disconnection and
+	// take input for interactive jobs.
 	clientDone := make(chan struct{})
 	go func() {
 		defer close(clientDone)
 		for {
-			_, _, err := conn.ReadMessage()
+			_, data, err := conn.ReadMessage()
 			if err != nil {
 				return
 			}
+			if interactive {
+				h.handleInput(c, job, data, out)
+			}
 		}
 	}()
 
+	// Non-interactive streams are woken by status changes rather than
+	// polling. Interactive ones poll anyway, for output. Watching starts
+	// before the first read, so no change slips in between.
+	var updates <-chan struct{}
+	if !interactive {
+		var stopWatch func()
+		updates, stopWatch = h.getJobUC.Watch(id)
+		defer stopWatch()
+	}
+
 	// Timers
-	pollTicker := time.NewTicker(wsPollInterval)
+	pollInterval := wsPollInterval
+	if interactive {
+		pollInterval = wsInteractivePollInterval
+	}
+	pollTicker := time.NewTicker(pollInterval)
 	defer pollTicker.Stop()
 
 	pingTicker := time.NewTicker(wsPingInterval)
 	defer pingTicker.Stop()
 
-	maxTimer := time.NewTimer(wsMaxDuration)
-	defer maxTimer.Stop()
+	idleTimer := time.NewTimer(wsIdleTimeout)
+	defer idleTimer.Stop()
+
+	seq, lastStatus := resume.seq, resume.status
+	var outputCursor string
+
+	// poll sends the job if its status changed and reports whether the
+	// stream should go on.
+	poll := func() bool {
+		job, err := h.getJobUC.Execute(c.Request.Context(), id)
+		if err != nil {
+			out.sendJSON(gin.H{"error": "Job not found"})
+			out.sendClose(websocket.CloseInternalServerErr, "job lookup failed")
+			out.wait(wsSlowClientTimeout)
+			return false
+		}
 
-	var lastStatus domain.ExecutionStatus
+		// Output is read after the job, so a finished job's output is
+		// complete and goes out before its final event.
+		if interactive {
+			var output string
+			output, outputCursor, err = h.interactive.Output(c.Request.Context(), id, outputCursor)
+			if err != nil {
+				h.logger.Warn("Failed to read interactive output", zap.String("job_id", idStr), zap.Error(err))
+			}
+			if output != "" {
+				out.sendJSON(wsOutput{Output: output})
+				idleTimer.Reset(wsIdleTimeout)
+			}
+		}
+
+		// Only send updates when status changes (avoid flooding). A
+		// resumed stream skips the status its client already saw.
+		if job.Status != lastStatus {
+			ev := wsEvent{Job: job}
+			// A new stream starts with the job's history, so late clients
+			// can show the whole lifecycle.
+			if seq == 0 {
+				if ev.Timeline, err = h.getJobUC.Timeline(c.Request.Context(), id); err != nil {
+					h.logger.Warn("Failed to load job timeline", zap.String("job_id", idStr), zap.Error(err))
+				}
+			}
+			seq++
+			lastStatus = job.Status
+			ev.Seq = seq
+			ev.ResumeToken = wsResumeState{jobID: id, seq: seq, status: job.Status}.token()
+			// The timeline goes out once; later updates supersede each
+			// other.
+			if ev.Timeline != nil {
+				out.sendJSON(ev)
+			} else {
+				out.sendStatus(ev)
+			}
+			idleTimer.Reset(wsIdleTimeout)
+		}
+
+		// Stop streaming once the job reaches a terminal state; its
+		// final state was the last event.
+		if job.Status.IsTerminal() {
+			out.sendClose(wsCloseJobCompleted, "job-completed")
+			out.wait(wsSlowClientTimeout)
+			h.logger.Debug("Job reached terminal state, closing WebSocket",
+				zap.String("job_id", idStr),
+				zap.String("status", string(job.Status)),
+			)
+			return false
+		}
+		return true
+	}
+
+	// The first update goes out right away rather than after a poll interval.
+	if !poll() {
+		return
+	}
 
 	for {
 		select {
@@ -112,48 +299,67 @@ func (h *WebSocketHandler) Stream(c *gin.Context) {
 			h.logger.Debug("WebSocket client disconnected", zap.String("job_id", idStr))
 			return
 
-		case <-maxTimer.C:
-			h.logger.Debug("WebSocket max duration exceeded, closing", zap.String("job_id", idStr))
-			conn.WriteMessage(websocket.CloseMessage,
-				websocket.FormatCloseMessage(websocket.CloseNormalClosure, "max connection duration exceeded"))
+		case <-out.done:
+			h.logger.Debug("WebSocket write failed", zap.String("job_id", idStr))
+			return
+
+		case <-h.streams.ch:
+			out.sendClose(wsCloseServerShutdown, middleware.RetryCloseReason(middleware.RetryServerShutdown, wsReconnectAfter))
+			out.wait(wsSlowClientTimeout)
+			return
+
+		case <-idleTimer.C:
+			h.logger.Debug("WebSocket idle, closing", zap.String("job_id", idStr))
+			out.sendClose(wsCloseIdleTimeout, "idle-timeout")
+			out.wait(wsSlowClientTimeout)
 			return
 
 		case <-pingTicker.C:
-			conn.SetWriteDeadline(time.Now().Add(wsPongTimeout))
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			// Control frames may be written concurrently with the outbox writer.
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsPongTimeout)); err != nil {
 				h.logger.Debug("WebSocket ping failed", zap.Error(err))
 				return
 			}
 
 		case <-pollTicker.C:
-			job, err := h.getJobUC.Execute(c.Request.Context(), id)
-			if err != nil {
-				conn.WriteJSON(gin.H{"error": "Job not found"})
+			if lag := out.lag(time.Now()); lag > wsSlowClientTimeout {
+				h.logger.Info("Closing WebSocket for slow client",
+					zap.String("job_id", idStr),
+					zap.Duration("lag", lag),
+				)
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater,
+						middleware.RetryCloseReason(middleware.RetrySlowClient, wsReconnectAfter)),
+					time.Now().Add(time.Second))
 				return
 			}
-
-			// Only send updates when status changes (avoid flooding)
-			if job.Status != lastStatus {
-				conn.SetWriteDeadline(time.Now().Add(wsPongTimeout))
-				if err := conn.WriteJSON(job); err != nil {
-					h.logger.Debug("WebSocket write failed", zap.Error(err))
-					return
-				}
-				lastStatus = job.Status
+			if updates == nil && !poll() {
+				return
 			}
 
-			// Stop streaming once the job reaches a terminal state
-			if job.Status.IsTerminal() {
-				// Send final state and close gracefully
-				conn.WriteJSON(job)
-				conn.WriteMessage(websocket.CloseMessage,
-					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "job completed"))
-				h.logger.Debug("Job reached terminal state, closing WebSocket",
-					zap.String("job_id", idStr),
-					zap.String("status", string(job.Status)),
-				)
+		case <-updates:
+			if !poll() {
 				return
 			}
 		}
 	}
 }
+
+// handleInput passes one client message on to an interactive job's stdin,
+// answering with an error message when it cannot.
+func (h *WebSocketHandler) handleInput(c *gin.Context, job *domain.Job, data []byte, out *wsOutbox) {
+	var msg wsClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		out.sendJSON(gin.H{"error": "Invalid message: " + err.Error()})
+		return
+	}
+	err := h.interactive.SendStdin(c.Request.Context(), job, msg.Stdin, msg.StdinEOF)
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrNotInteractive), errors.Is(err, domain.ErrInvalidStdin):
+		out.sendJSON(gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Send stdin failed", zap.Error(err), zap.String("job_id", job.JobID.String()))
+		out.sendJSON(gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/delivery/http/middleware"
+)
+
+const (
+	// wsOutboxSize is how many messages may wait for a slow client. When
+	// the queue is full, a status update that a newer one supersedes makes
+	// room; if there is none, the client is closed as too slow, since
+	// output, the timeline and close frames cannot be dropped.
+	wsOutboxSize = 16
+
+	// wsSlowClientTimeout is how long a queued message may wait before the
+	// client is considered unable to keep up and is disconnected.
+	wsSlowClientTimeout = 10 * time.Second
+)
+
+type wsMessage struct {
+	kind int // websocket.TextMessage or websocket.CloseMessage
+	data []byte
+	// status marks a job update that the next one supersedes.
+	status   bool
+	queuedAt time.Time
+}
+
+// wsOutbox is the outbound queue of one WebSocket connection. The handler
+// enqueues without blocking and a single writer goroutine drains the queue,
+// so a stalled client never holds up the goroutine producing its updates.
+type wsOutbox struct {
+	conn *websocket.Conn
+
+	mu      sync.Mutex
+	queue   []wsMessage
+	closing bool
+
+	wake chan struct{}
+	// done is closed when the writer exits: after sending the close message,
+	// on a write error, or when stop is called.
+	done chan struct{}
+	stop chan struct{}
+	once sync.Once
+}
+
+func newWSOutbox(conn *websocket.Conn) *wsOutbox {
+	o := &wsOutbox{
+		conn: conn,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+		stop: make(chan struct{}),
+	}
+	go o.run()
+	return o
+}
+
+// sendJSON queues v as a text message. It reports false once the outbox is
+// closing, and if the queue is full of messages that cannot be dropped, in
+// which case the client is closed as too slow after what is queued.
+func (o *wsOutbox) sendJSON(v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	return o.push(wsMessage{kind: websocket.TextMessage, data: data})
+}
+
+// sendStatus queues v, a job update, as sendJSON does, but as one that a
+// later update supersedes and may be dropped for.
+func (o *wsOutbox) sendStatus(v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	return o.push(wsMessage{kind: websocket.TextMessage, data: data, status: true})
+}
+
+// sendClose queues a close frame after everything already queued. Nothing
+// can be queued after it.
+func (o *wsOutbox) sendClose(code int, reason string) {
+	o.push(wsMessage{kind: websocket.CloseMessage, data: websocket.FormatCloseMessage(code, reason)})
+}
+
+func (o *wsOutbox) push(m wsMessage) bool {
+	o.mu.Lock()
+	if o.closing {
+		o.mu.Unlock()
+		return false
+	}
+	m.queuedAt = time.Now()
+	queued := true
+	if len(o.queue) >= wsOutboxSize && m.kind != websocket.CloseMessage && !o.collapse(m) {
+		// The close frame goes after the messages the client has yet to
+		// read, so it learns its stream was cut short.
+		queued = false
+		m = wsMessage{
+			kind: websocket.CloseMessage,
+			data: websocket.FormatCloseMessage(websocket.CloseTryAgainLater,
+				middleware.RetryCloseReason(middleware.RetrySlowClient, wsReconnectAfter)),
+			queuedAt: m.queuedAt,
+		}
+	}
+	o.queue = append(o.queue, m)
+	o.closing = m.kind == websocket.CloseMessage
+	o.mu.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return queued
+}
+
+// collapse makes room for m by dropping the oldest queued status update
+// that a newer one, queued or m itself, supersedes. It reports false if
+// there is none. The caller holds o.mu.
+func (o *wsOutbox) collapse(m wsMessage) bool {
+	newest := -1
+	if m.status {
+		newest = len(o.queue)
+	} else {
+		for i := len(o.queue) - 1; i >= 0; i-- {
+			if o.queue[i].status {
+				newest = i
+				break
+			}
+		}
+	}
+	for i := 0; i < newest; i++ {
+		if o.queue[i].status {
+			o.queue = append(o.queue[:i], o.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// lag is how long the oldest queued message has been waiting.
+func (o *wsOutbox) lag(now time.Time) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.queue) == 0 {
+		return 0
+	}
+	return now.Sub(o.queue[0].queuedAt)
+}
+
+// wait blocks until the writer exits or timeout passes.
+func (o *wsOutbox) wait(timeout time.Duration) {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-o.done:
+	case <-t.C:
+	}
+}
+
+// close stops the writer without sending anything further.
+func (o *wsOutbox) close() {
+	o.once.Do(func() { close(o.stop) })
+}
+
+func (o *wsOutbox) pop() (wsMessage, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.queue) == 0 {
+		return wsMessage{}, false
+	}
+	m := o.queue[0]
+	o.queue = o.queue[1:]
+	return m, true
+}
+
+func (o *wsOutbox) run() {
+	defer close(o.done)
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-o.wake:
+		}
+		for {
+			m, ok := o.pop()
+			if !ok {
+				break
+			}
+			o.conn.SetWriteDeadline(time.Now().Add(wsSlowClientTimeout))
+			if err := o.conn.WriteMessage(m.kind, m.data); err != nil || m.kind == websocket.CloseMessage {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// Application close codes sent on submission streams. The reason text is the
+// code's name, so clients that only log the reason still see what happened.
+// Closes that ask the client to come back add a retry hint to the name, as
+// middleware.RetryCloseReason writes it.
+const (
+	// wsCloseJobCompleted: the job reached a terminal state and its final
+	// state was the last message. Do not reconnect.
+	wsCloseJobCompleted = 4000
+	// wsCloseServerShutdown: this API instance is going away. Reconnect
+	// after a short delay with the last resume_token.
+	wsCloseServerShutdown = 4001
+	// wsCloseIdleTimeout: the job's status did not change for a while.
+	// Reconnect with the last resume_token if still interested.
+	wsCloseIdleTimeout = 4002
+	// wsCloseRateLimited: the client has too many streams open. Close some
+	// or back off before reconnecting.
+	wsCloseRateLimited = 4003
+)
+
+// resumeTokenParam carries a resume token on reconnect.
+const resumeTokenParam = "resume_token"
+
+var errInvalidResumeToken = errors.New("invalid resume token")
+
+// wsEvent is one message on a submission stream: the full job plus where it
+// sits in the stream. seq keeps counting across resumed connections. The
+// first event of a new stream also carries the job's status timeline.
+type wsEvent struct {
+	Seq         int               `json:"seq"`
+	ResumeToken string            `json:"resume_token"`
+	Timeline    []domain.JobEvent `json:"timeline,omitempty"`
+	*domain.Job
+}
+
+// wsOutput is stdout an interactive job wrote since the previous one. It is
+// live output only: it has no seq and is not replayed on resume. It is never
+// dropped; a client too slow to take it is closed instead. The job's stdout
+// field holds the whole output once it finishes.
+type wsOutput struct {
+	Output string `json:"output"`
+}
+
+// wsClientMessage is input a client sends on an interactive job's stream.
+// StdinEOF closes the program's stdin after Stdin.
+type wsClientMessage struct {
+	Stdin    string `json:"stdin"`
+	StdinEOF bool   `json:"stdin_eof"`
+}
+
+// wsResumeState is the last event a client saw, as encoded in its resume
+// token. Tokens are not secret: they only let a client skip updates for a
+// job it could stream anyway.
+type wsResumeState struct {
+	jobID  uuid.UUID
+	seq    int
+	status domain.ExecutionStatus
+}
+
+func (s wsResumeState) token() string {
+	return base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf("%s.%d.%s", s.jobID, s.seq, s.status)))
+}
+
+// parseResumeToken decodes a token issued for jobID.
+func parseResumeToken(token string, jobID uuid.UUID) (wsResumeState, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return wsResumeState{}, errInvalidResumeToken
+	}
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 3 {
+		return wsResumeState{}, errInvalidResumeToken
+	}
+	id, err := uuid.Parse(parts[0])
+	if err != nil || id != jobID {
+		return wsResumeState{}, errInvalidResumeToken
+	}
+	seq, err := strconv.Atoi(parts[1])
+	if err != nil || seq < 0 {
+		return wsResumeState{}, errInvalidResumeToken
+	}
+	return wsResumeState{jobID: id, seq: seq, status: domain.ExecutionStatus(parts[2])}, nil
+}
+
+// StreamShutdown tells open submission streams that the server is going
+// away and waits for them to send their close frames. http.Server.Shutdown
+// does not track hijacked connections, so it cannot do this itself.
+type StreamShutdown struct {
+	mu      sync.Mutex
+	closing bool
+	active  int
+
+	ch   chan struct{} // closed by Close
+	idle chan struct{} // closed once closing and no stream is left
+}
+
+// NewStreamShutdown creates a shutdown signal for submission streams.
+func NewStreamShutdown() *StreamShutdown {
+	return &StreamShutdown{ch: make(chan struct{}), idle: make(chan struct{})}
+}
+
+// enter registers an open stream. It reports false once shutting down.
+func (s *StreamShutdown) enter() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return false
+	}
+	s.active++
+	return true
+}
+
+func (s *StreamShutdown) leave() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	if s.closing && s.active == 0 {
+		close(s.idle)
+	}
+}
+
+// Close signals every open stream to close with wsCloseServerShutdown and
+// waits until they have, or until ctx is done.
+func (s *StreamShutdown) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closing {
+		s.closing = true
+		close(s.ch)
+		if s.active == 0 {
+			close(s.idle)
+		}
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes limit what a key may do. ScopeSubmit runs code and reads the jobs
+// submitted with the key; ScopeReadAny reads every job of the key's
+// tenant; ScopeAdmin calls the admin endpoints as the admin token does, and
+// implies the other scopes.
+const (
+	ScopeSubmit  = "submit"
+	ScopeReadAny = "read:any"
+	ScopeAdmin   = "admin"
+)
+
+// Scopes are the scopes a key can be issued with.
+var Scopes = []string{ScopeSubmit, ScopeReadAny, ScopeAdmin}
+
+// DefaultAPIKeyScopes are the scopes of a key issued without any.
+var DefaultAPIKeyScopes = []string{ScopeSubmit}
+
+// UserScopes are the scopes of signed-in users, who read only the jobs
+// they submitted.
+var UserScopes = []string{ScopeSubmit}
+
+// HasScope reports whether scopes grant scope.
+func HasScope(scopes []string, scope string) bool {
+	return slices.Contains(scopes, scope) || slices.Contains(scopes, ScopeAdmin)
+}
+
+// APIKey authenticates requests as its tenant. The key itself is shown
+// only when it is issued or rotated; Prefix, its first characters, tells
+// keys apart afterwards.
+type APIKey struct {
+	KeyID     uuid.UUID  `json:"key_id"`
+	TenantID  string     `json:"tenant_id"`
+	Name      string     `json:"name,omitempty"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssueAPIKeyRequest issues a key for a tenant, with DefaultAPIKeyScopes
+// unless Scopes names some.
+type IssueAPIKeyRequest struct {
+	TenantID string   `json:"tenant_id" binding:"required"`
+	Name     string   `json:"name"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+// IssuedAPIKey is a key as issued or rotated, with the key itself.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
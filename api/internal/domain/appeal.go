@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AppealStatus is the state of an appeal against a judged submission.
+type AppealStatus string
+
+const (
+	AppealOpen   AppealStatus = "open"
+	AppealClosed AppealStatus = "closed"
+)
+
+// IsValid reports whether s is a known appeal status.
+func (s AppealStatus) IsValid() bool {
+	return s == AppealOpen || s == AppealClosed
+}
+
+// Appeal is a user's request to review the verdict of a judged submission.
+// A submission has at most one open appeal at a time.
+type Appeal struct {
+	AppealID uuid.UUID    `json:"appeal_id"`
+	JobID    uuid.UUID    `json:"job_id"`
+	TenantID string       `json:"tenant_id,omitempty"`
+	Status   AppealStatus `json:"status"`
+	Note     string       `json:"note"`
+
+	// Response is the admin's answer, set when the appeal is closed.
+	Response string     `json:"response,omitempty"`
+	ClosedBy string     `json:"closed_by,omitempty"`
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+
+	// Overrides is the audit trail of verdict changes made under this appeal,
+	// oldest first.
+	Overrides []VerdictOverride `json:"overrides,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VerdictOverride records an admin replacing a submission's verdict.
+type VerdictOverride struct {
+	OverrideID     uuid.UUID       `json:"override_id"`
+	AppealID       uuid.UUID       `json:"appeal_id"`
+	JobID          uuid.UUID       `json:"job_id"`
+	PreviousStatus ExecutionStatus `json:"previous_status"`
+	Status         ExecutionStatus `json:"status"`
+	PreviousScore  *float64        `json:"previous_score,omitempty"`
+	Score          *float64        `json:"score,omitempty"`
+	Reason         string          `json:"reason"`
+	Actor          string          `json:"actor"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// OpenAppealRequest opens an appeal on a judged submission.
+type OpenAppealRequest struct {
+	Note string `json:"note" binding:"required"`
+}
+
+// OverrideVerdictRequest replaces a submission's verdict. Score is only
+// meaningful for scored problems; nil keeps the current score.
+type OverrideVerdictRequest struct {
+	Status ExecutionStatus `json:"status" binding:"required"`
+	Score  *float64        `json:"score,omitempty"`
+	Reason string          `json:"reason" binding:"required"`
+}
+
+// CloseAppealRequest closes an appeal with a response shown to the user.
+type CloseAppealRequest struct {
+	Response string `json:"response" binding:"required"`
+}
+
+// RerunResponse reports a submission requeued for an appeal.
+type RerunResponse struct {
+	AppealID      uuid.UUID `json:"appeal_id"`
+	JobID         uuid.UUID `json:"job_id"`
+	JudgeRevision int       `json:"judge_revision"`
+}
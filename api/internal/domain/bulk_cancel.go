@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BulkCancelRequest selects the jobs POST /admin/cancel cancels, for
+// cleaning up after an integration that flooded the queue. Filters combine;
+// at least one of TenantID, ProblemID and CreatedBefore is required, so a
+// request cannot cancel the whole queue by accident.
+type BulkCancelRequest struct {
+	TenantID  string `json:"tenant_id,omitempty"`
+	ProblemID string `json:"problem_id,omitempty"`
+	// Status is QUEUED, the default, or SCHEDULED. Running jobs are
+	// cancelled one by one.
+	Status        ExecutionStatus `json:"status,omitempty"`
+	CreatedBefore *time.Time      `json:"created_before,omitempty"`
+	// Limit caps the jobs cancelled, oldest first. Zero selects the default.
+	Limit int `json:"limit,omitempty"`
+	// DryRun reports the jobs that would be cancelled without changing
+	// anything.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// BulkCancelFilter is a validated BulkCancelRequest's selection.
+type BulkCancelFilter struct {
+	TenantID      string
+	ProblemID     string
+	Status        ExecutionStatus
+	CreatedBefore *time.Time
+}
+
+// BulkCancelReport is the response of POST /admin/cancel. Cancelled counts
+// the jobs cancelled, always zero in a dry run; JobIDs are the jobs matched.
+// More holds when the limit was reached and another request may cancel
+// more.
+type BulkCancelReport struct {
+	DryRun    bool        `json:"dry_run"`
+	Matched   int         `json:"matched"`
+	Cancelled int         `json:"cancelled"`
+	JobIDs    []uuid.UUID `json:"job_ids"`
+	More      bool        `json:"more"`
+	// Tombstoned counts the broker messages marked so workers drop them
+	// without reading the job.
+	Tombstoned int `json:"tombstoned"`
+}
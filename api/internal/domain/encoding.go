@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeBase64 replaces the source, stdin and expected output of a
+// submission sent with base64_encoded=true by their decoded values. Jobs
+// are stored and run as text, so each must decode to UTF-8 without NUL
+// bytes; an error wrapping ErrInvalidEncoding names the first that does not.
+func (r *SubmitRequest) DecodeBase64() error {
+	var err error
+	if r.SourceCode, err = decodeField("source_code", r.SourceCode); err != nil {
+		return err
+	}
+	if r.Stdin, err = decodeField("stdin", r.Stdin); err != nil {
+		return err
+	}
+	if r.ExpectedOutput != nil {
+		expected, err := decodeField("expected_output", *r.ExpectedOutput)
+		if err != nil {
+			return err
+		}
+		r.ExpectedOutput = &expected
+	}
+	return nil
+}
+
+func decodeField(name, s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s is not base64", ErrInvalidEncoding, name)
+	}
+	if !utf8.Valid(data) || strings.IndexByte(string(data), 0) >= 0 {
+		return "", fmt.Errorf("%w: %s does not decode to UTF-8 text without NUL bytes", ErrInvalidEncoding, name)
+	}
+	return string(data), nil
+}
+
+// Base64Encoded returns a copy of j, as answered to a request with
+// base64_encoded=true, whose program text and output are base64-encoded:
+// source, stdin, stdout, stderr, expected output, the submitted solution
+// and the output kept for test cases.
+func (j *Job) Base64Encoded() *Job {
+	enc := base64.StdEncoding.EncodeToString
+	c := *j
+	j = &c
+	j.SourceCode = enc([]byte(j.SourceCode))
+	j.Stdin = enc([]byte(j.Stdin))
+	j.Stdout = enc([]byte(j.Stdout))
+	j.Stderr = enc([]byte(j.Stderr))
+	j.SolutionCode = enc([]byte(j.SolutionCode))
+	if j.ExpectedOutput != nil {
+		expected := enc([]byte(*j.ExpectedOutput))
+		j.ExpectedOutput = &expected
+	}
+	if j.TestResults != nil {
+		results := make([]TestCaseResult, len(j.TestResults))
+		for i, r := range j.TestResults {
+			r.Output = enc([]byte(r.Output))
+			results[i] = r
+		}
+		j.TestResults = results
+	}
+	return j
+}
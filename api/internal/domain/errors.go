@@ -6,6 +6,13 @@ var (
 	// ErrJobNotFound is returned when a job cannot be found by ID.
 	ErrJobNotFound = errors.New("job not found")
 
+	// ErrJobFinished is returned when cancelling a job that already has a result.
+	ErrJobFinished = errors.New("job already finished")
+
+	// ErrJobActive is returned when purging a job that is still queued or
+	// running.
+	ErrJobActive = errors.New("job is still queued or running; cancel it first")
+
 	// ErrInvalidLanguage is returned when an unsupported language is submitted.
 	ErrInvalidLanguage = errors.New("invalid or unsupported language")
 
@@ -15,12 +22,186 @@ var (
 	// ErrEmptySourceCode is returned when source code is empty.
 	ErrEmptySourceCode = errors.New("source code cannot be empty")
 
+	// ErrInvalidSource is returned when source code contains NUL bytes or too many or too long lines.
+	ErrInvalidSource = errors.New("invalid source code")
+
+	// ErrInvalidCompilerFlags is returned when compiler flags are malformed or too many.
+	ErrInvalidCompilerFlags = errors.New("invalid compiler flags")
+
+	// ErrInvalidArgs is returned when program arguments are malformed or too many.
+	ErrInvalidArgs = errors.New("invalid program arguments")
+
+	// ErrInvalidEnv is returned when environment variables are not allowed or too large.
+	ErrInvalidEnv = errors.New("invalid environment variables")
+
+	// ErrInvalidSandboxTier is returned when a submission asks for a sandbox tier the deployment does not offer.
+	ErrInvalidSandboxTier = errors.New("unsupported sandbox tier")
+
+	// ErrInvalidNetworkPolicy is returned when a submission asks for a network policy the deployment does not offer.
+	ErrInvalidNetworkPolicy = errors.New("unsupported network policy")
+
+	// ErrInvalidOutputFiles is returned when declared output files are not plain file names or too many.
+	ErrInvalidOutputFiles = errors.New("invalid output files")
+
+	// ErrInvalidInteractive is returned when a submission asks for interactive execution the deployment does not offer.
+	ErrInvalidInteractive = errors.New("interactive execution not available")
+
+	// ErrInvalidExpectedOutput is returned when an expected output is too large, judged, or has an unknown compare mode.
+	ErrInvalidExpectedOutput = errors.New("invalid expected output")
+
+	// ErrInvalidPriority is returned when a submission's priority is outside 0-MaxPriority.
+	ErrInvalidPriority = errors.New("invalid priority")
+
+	// ErrInvalidRunAt is returned when a submission's run_at is too far ahead or scheduling is not available.
+	ErrInvalidRunAt = errors.New("invalid run_at")
+
+	// ErrInvalidExternalID is returned when a submission's external_id is too long or not printable.
+	ErrInvalidExternalID = errors.New("invalid external_id")
+
+	// ErrExternalIDExists is returned when a tenant already has a job with the submission's external_id.
+	ErrExternalIDExists = errors.New("external_id already used by another job")
+
+	// ErrNotInteractive is returned when sending input to a job that is not interactive or has finished.
+	ErrNotInteractive = errors.New("job does not accept input")
+
+	// ErrInvalidEncoding is returned when a base64-encoded submission field is not valid base64 of UTF-8 text without NUL bytes.
+	ErrInvalidEncoding = errors.New("invalid base64 encoding")
+
+	// ErrInvalidStdin is returned when input sent to an interactive job is too large.
+	ErrInvalidStdin = errors.New("invalid stdin message")
+
+	// ErrArtifactNotFound is returned when a job has no artifact by the requested name.
+	ErrArtifactNotFound = errors.New("artifact not found")
+
+	// ErrInvalidFields is returned when a sparse fieldset names an unknown field.
+	ErrInvalidFields = errors.New("invalid fields")
+
+	// ErrInvalidJobFilter is returned when listing jobs by an unknown status or an out-of-range limit.
+	ErrInvalidJobFilter = errors.New("invalid job filter")
+
+	// ErrInvalidSecurityEventFilter is returned when listing security events by an unknown kind, cursor or format, or an out-of-range limit.
+	ErrInvalidSecurityEventFilter = errors.New("invalid security event filter")
+
 	// ErrRateLimitExceeded is returned when API rate limit is hit.
 	ErrRateLimitExceeded = errors.New("rate limit exceeded, try again later")
 
 	// ErrPublishFailed is returned when the message broker publish fails.
 	ErrPublishFailed = errors.New("failed to publish job to message queue")
 
+	// ErrQuotaExceeded is returned when a tenant has used up its daily execution budget.
+	ErrQuotaExceeded = errors.New("daily execution quota exhausted for tenant")
+
+	// ErrProblemNotFound is returned when a problem cannot be found by ID.
+	ErrProblemNotFound = errors.New("problem not found")
+
+	// ErrProblemExists is returned when creating a problem whose ID is taken.
+	ErrProblemExists = errors.New("problem already exists")
+
+	// ErrInvalidTestData is returned when a problem's test cases are missing or too large.
+	ErrInvalidTestData = errors.New("invalid test data")
+
+	// ErrAppealNotFound is returned when an appeal cannot be found by ID.
+	ErrAppealNotFound = errors.New("appeal not found")
+
+	// ErrAppealExists is returned when a submission already has an open appeal.
+	ErrAppealExists = errors.New("submission already has an open appeal")
+
+	// ErrAppealClosed is returned when acting on an appeal that is already closed.
+	ErrAppealClosed = errors.New("appeal is closed")
+
+	// ErrNotAppealable is returned when a submission is not judged or still running.
+	ErrNotAppealable = errors.New("only finished judged submissions can be appealed")
+
+	// ErrInvalidAppeal is returned when an appeal, override, or response is malformed.
+	ErrInvalidAppeal = errors.New("invalid appeal")
+
+	// ErrRuntimeNotFound is returned when a runtime cannot be found by name.
+	ErrRuntimeNotFound = errors.New("runtime not found")
+
+	// ErrRuntimeExists is returned when registering a runtime whose name is taken.
+	ErrRuntimeExists = errors.New("runtime already exists")
+
+	// ErrInvalidRuntime is returned when a runtime definition is malformed.
+	ErrInvalidRuntime = errors.New("invalid runtime")
+
+	// ErrWebhookNotFound is returned when a tenant has no webhook configured.
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrInvalidWebhook is returned when a webhook URL or secret is malformed.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+
+	// ErrDeliveryNotFound is returned when a webhook delivery cannot be found by ID.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrDeliveryPending is returned when redriving a delivery that is still being retried.
+	ErrDeliveryPending = errors.New("webhook delivery is still pending")
+
+	// ErrInvalidDeliveryFilter is returned when listing deliveries by an unknown status or an out-of-range limit.
+	ErrInvalidDeliveryFilter = errors.New("invalid delivery filter")
+
+	// ErrScheduleNotFound is returned when a tenant has no schedule by the requested ID.
+	ErrScheduleNotFound = errors.New("schedule not found")
+
+	// ErrInvalidSchedule is returned when a schedule's cron expression or job template is invalid.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+
+	// ErrGitHubRepoNotFound is returned when a repository has no mapping.
+	ErrGitHubRepoNotFound = errors.New("github repository not mapped")
+
+	// ErrGitHubRepoTaken is returned when another tenant already maps a repository.
+	ErrGitHubRepoTaken = errors.New("github repository mapped by another tenant")
+
+	// ErrInvalidGitHubRepo is returned when a repository mapping is malformed.
+	ErrInvalidGitHubRepo = errors.New("invalid github repository mapping")
+
+	// ErrInvalidSignature is returned when a webhook delivery's signature does not match.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+
+	// ErrLTIPlatformNotFound is returned when an LTI platform is not registered.
+	ErrLTIPlatformNotFound = errors.New("lti platform not found")
+
+	// ErrLTIPlatformTaken is returned when a platform's issuer and client ID are already registered.
+	ErrLTIPlatformTaken = errors.New("lti platform already registered")
+
+	// ErrInvalidLTIPlatform is returned when a platform registration is malformed.
+	ErrInvalidLTIPlatform = errors.New("invalid lti platform")
+
+	// ErrInvalidLTILaunch is returned when a login or launch cannot be verified.
+	ErrInvalidLTILaunch = errors.New("invalid lti launch")
+
+	// ErrLTILaunchNotFound is returned when a launch does not exist or has expired.
+	ErrLTILaunchNotFound = errors.New("lti launch not found")
+
+	// ErrAPIKeyNotFound is returned when an API key cannot be found by ID.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	// ErrInvalidAPIKey is returned when a request's API key is unknown or revoked.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrInvalidAPIKeyRequest is returned when issuing a key with a malformed tenant or name.
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+
+	// ErrUserNotFound is returned when no user has an email.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUserExists is returned when registering an email that already has a user.
+	ErrUserExists = errors.New("user already exists")
+
+	// ErrInvalidUserRequest is returned when registering with a malformed email or password.
+	ErrInvalidUserRequest = errors.New("invalid user request")
+
+	// ErrInvalidCredentials is returned when logging in with an unknown email or a wrong password.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrInvalidUserToken is returned when a request's user token is malformed, forged or expired.
+	ErrInvalidUserToken = errors.New("invalid user token")
+
+	// ErrInvalidRepair is returned when a repair names unknown actions or out-of-range limits.
+	ErrInvalidRepair = errors.New("invalid repair request")
+
+	// ErrInvalidBulkCancel is returned when a bulk cancel has no filter, an unsupported status or an out-of-range limit.
+	ErrInvalidBulkCancel = errors.New("invalid bulk cancel request")
+
 	// ErrDatabaseUnavailable is returned when the database is unreachable.
 	ErrDatabaseUnavailable = errors.New("database is currently unavailable")
 )
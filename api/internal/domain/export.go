@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobFact is one verdict as exported to the data warehouse: a job reaching
+// a terminal status, with its timings and an anonymized hash of its source.
+// A rejudged job yields a fact per verdict; EventID orders them and makes
+// each unique.
+type JobFact struct {
+	EventID       int64           `json:"event_id"`
+	JobID         uuid.UUID       `json:"job_id"`
+	TenantID      string          `json:"tenant_id"`
+	ProblemID     string          `json:"problem_id,omitempty"`
+	JudgeRevision int             `json:"judge_revision"`
+	Language      Language        `json:"language"`
+	Status        ExecutionStatus `json:"status"`
+	// SourceHash is the hex HMAC-SHA256 of the source under the export
+	// salt; equal sources hash equally without revealing the source.
+	SourceHash  string `json:"source_hash"`
+	SourceBytes int    `json:"source_bytes"`
+
+	Score         *float64 `json:"score,omitempty"`
+	MaxScore      *float64 `json:"max_score,omitempty"`
+	TimeUsedMs    *int     `json:"time_used_ms,omitempty"`
+	CPUTimeUsedMs *int     `json:"cpu_time_used_ms,omitempty"`
+	MemoryUsedKB  *int     `json:"memory_used_kb,omitempty"`
+
+	// QueuedAt is when the run producing the verdict was queued, StartedAt
+	// when a worker picked it up (unset if none did) and FinishedAt when
+	// the verdict was recorded.
+	QueuedAt   time.Time  `json:"queued_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time  `json:"finished_at"`
+}
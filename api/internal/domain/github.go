@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GitHubRepo maps an assignment repository to how its commits are judged.
+// Pushes and pull requests delivered by the repository's webhook submit the
+// source at Path, as Language, against ProblemID if set, and the verdict is
+// posted back as a commit status.
+type GitHubRepo struct {
+	// Repo is the repository's full name, "owner/name".
+	Repo      string   `json:"repo"`
+	TenantID  string   `json:"tenant_id"`
+	Language  Language `json:"language"`
+	ProblemID string   `json:"problem_id,omitempty"`
+	// Path is the source file, or a directory holding exactly one file.
+	Path string `json:"path"`
+
+	// Secret signs webhook deliveries; it is returned only by the request
+	// that sets the mapping. Token reads contents and writes commit
+	// statuses, and is never returned.
+	Secret string `json:"secret,omitempty"`
+	Token  string `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetGitHubRepoRequest maps a repository. Without a Secret one is generated.
+type SetGitHubRepoRequest struct {
+	Language  Language `json:"language" binding:"required"`
+	ProblemID string   `json:"problem_id,omitempty"`
+	Path      string   `json:"path" binding:"required"`
+	Secret    string   `json:"secret,omitempty"`
+	Token     string   `json:"token" binding:"required"`
+}
+
+// GitHubCheck is a job submitted from a commit, whose verdict is still to
+// be posted as the commit's status.
+type GitHubCheck struct {
+	JobID uuid.UUID
+	Repo  string
+	SHA   string
+
+	// Status is the job's current status, read with the check.
+	Status ExecutionStatus
+	Score  *float64
+}
+
+// GitHubDelivery is the outcome of one webhook delivery.
+type GitHubDelivery struct {
+	// JobID is the submitted job, unset when the delivery was ignored.
+	JobID *uuid.UUID `json:"job_id,omitempty"`
+	SHA   string     `json:"sha,omitempty"`
+	// Ignored explains why a delivery submitted nothing.
+	Ignored string `json:"ignored,omitempty"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HardeningRequest selects where POST /admin/sandbox/hardening runs its
+// probes. Empty fields submit them like any other job of the default
+// tenant, so they land on the executors that tenant's jobs use.
+type HardeningRequest struct {
+	TenantID    string `json:"tenant_id,omitempty"`
+	SandboxTier string `json:"sandbox_tier,omitempty"`
+}
+
+// HardeningResult is what one probe found out about its protection.
+type HardeningResult string
+
+const (
+	// HardeningActive: the sandbox stopped the probe.
+	HardeningActive HardeningResult = "active"
+	// HardeningMissing: the probe did what the protection should prevent.
+	HardeningMissing HardeningResult = "missing"
+	// HardeningInconclusive: the probe did not get as far as trying, or did
+	// not finish in time.
+	HardeningInconclusive HardeningResult = "inconclusive"
+)
+
+// HardeningCheck is the outcome of one probe, with the job that ran it.
+type HardeningCheck struct {
+	Name       string          `json:"name"`
+	Protection string          `json:"protection"`
+	Result     HardeningResult `json:"result"`
+	Detail     string          `json:"detail,omitempty"`
+	JobID      uuid.UUID       `json:"job_id"`
+	Status     ExecutionStatus `json:"status"`
+}
+
+// HardeningReport is the response of POST /admin/sandbox/hardening.
+// Hardened is true only when every protection was found active.
+type HardeningReport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	TenantID    string           `json:"tenant_id"`
+	SandboxTier string           `json:"sandbox_tier,omitempty"`
+	Language    Language         `json:"language"`
+	Hardened    bool             `json:"hardened"`
+	Checks      []HardeningCheck `json:"checks"`
+}
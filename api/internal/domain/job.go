@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"encoding/base64"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +12,7 @@ import (
 type ExecutionStatus string
 
 const (
+	StatusScheduled           ExecutionStatus = "SCHEDULED"
 	StatusQueued              ExecutionStatus = "QUEUED"
 	StatusCompiling           ExecutionStatus = "COMPILING"
 	StatusRunning             ExecutionStatus = "RUNNING"
@@ -19,47 +22,241 @@ const (
 	StatusTimeout             ExecutionStatus = "TIMEOUT"
 	StatusMemoryLimitExceeded ExecutionStatus = "MEMORY_LIMIT_EXCEEDED"
 	StatusInternalError       ExecutionStatus = "INTERNAL_ERROR"
+
+	// Judging verdicts for jobs submitted against a problem's test data,
+	// or with an expected output.
+	StatusAccepted    ExecutionStatus = "ACCEPTED"
+	StatusWrongAnswer ExecutionStatus = "WRONG_ANSWER"
+
+	// StatusCancelled marks a job cancelled before it finished, through
+	// POST /submissions/{id}/cancel.
+	StatusCancelled ExecutionStatus = "CANCELLED"
+
+	// StatusRuleViolation marks a judged submission rejected before it ran
+	// because its source matched one of the problem's forbidden rules.
+	StatusRuleViolation ExecutionStatus = "RULE_VIOLATION"
+
+	// StatusOutputViolation marks a judged submission stopped while it ran
+	// because its output matched one of the problem's kill patterns.
+	StatusOutputViolation ExecutionStatus = "OUTPUT_VIOLATION"
+
+	// StatusSkipped marks a test case that was not run because the
+	// problem's termination strategy ended judging early. It never applies
+	// to a job.
+	StatusSkipped ExecutionStatus = "SKIPPED"
 )
 
 // IsTerminal returns true if the status represents a final state.
 func (s ExecutionStatus) IsTerminal() bool {
 	switch s {
 	case StatusSuccess, StatusCompilationError, StatusRuntimeError,
-		StatusTimeout, StatusMemoryLimitExceeded, StatusInternalError:
+		StatusTimeout, StatusMemoryLimitExceeded, StatusInternalError,
+		StatusAccepted, StatusWrongAnswer, StatusCancelled, StatusRuleViolation,
+		StatusOutputViolation:
 		return true
 	}
 	return false
 }
 
-// Language represents a supported programming language.
+// SubmittedSource returns the source as the submitter sent it, before any
+// problem harness wrapped it.
+func (j *Job) SubmittedSource() string {
+	if j.SolutionCode != "" {
+		return j.SolutionCode
+	}
+	return j.SourceCode
+}
+
+// Language identifies a programming language. The supported set comes from
+// the language registry (sandbox/languages.yaml); the constants below name the
+// languages shipped by default.
 type Language string
 
 const (
 	LangPython Language = "python"
 	LangCpp    Language = "cpp"
+	LangGo     Language = "go"
+	LangJS     Language = "javascript"
+	LangRust   Language = "rust"
 )
 
-// IsValid checks if the language is supported.
-func (l Language) IsValid() bool {
-	return l == LangPython || l == LangCpp
-}
-
 // Job represents a code execution job throughout its lifecycle.
 type Job struct {
+	JobID         uuid.UUID         `json:"job_id"`
+	TenantID      string            `json:"tenant_id,omitempty"`
+	Language      Language          `json:"language"`
+	SourceCode    string            `json:"source_code"`
+	Stdin         string            `json:"stdin"`
+	Stdout        string            `json:"stdout,omitempty"`
+	Stderr        string            `json:"stderr,omitempty"`
+	Status        ExecutionStatus   `json:"status"`
+	ExitCode      *int              `json:"exit_code,omitempty"`
+	TimeUsedMs    *int              `json:"time_used_ms,omitempty"`
+	MemoryUsedKB  *int              `json:"memory_used_kb,omitempty"`
+	TimeLimitMs   int               `json:"time_limit_ms"`
+	MemoryLimitKB int               `json:"memory_limit_kb"`
+	CompilerFlags []string          `json:"compiler_flags,omitempty"`
+	Args          []string          `json:"args,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+	SandboxTier   string            `json:"sandbox_tier,omitempty"`
+
+	// TimeLimitMs is the wall-clock limit, repeated as WallTimeLimitMs.
+	// CPUTimeLimitMs is set only when the submission asked for a separate
+	// CPU-time limit; otherwise the wall-clock limit caps CPU time too.
+	// TimeUsedMs is wall-clock time; CPUTimeUsedMs is set by executors that
+	// can measure it.
+	WallTimeLimitMs int  `json:"wall_time_limit_ms"`
+	CPUTimeLimitMs  int  `json:"cpu_time_limit_ms,omitempty"`
+	CPUTimeUsedMs   *int `json:"cpu_time_used_ms,omitempty"`
+
+	// PidsLimit caps the processes and threads the program may have at
+	// once. Zero leaves the language's sandbox profile default in force.
+	PidsLimit int `json:"pids_limit,omitempty"`
+
+	// NetworkPolicy is NetworkAllowlist for runs allowed to reach the
+	// workers' network allowlist; empty runs without network access.
+	NetworkPolicy string `json:"network_policy,omitempty"`
+
+	// OutputFiles are the files the worker collects from the sandbox work
+	// directory after the run, served as the job's artifacts.
+	OutputFiles []string `json:"output_files,omitempty"`
+
+	// Interactive jobs also read stdin sent over their submission stream
+	// while they run, after Stdin.
+	Interactive bool `json:"interactive,omitempty"`
+
+	// ExpectedOutput, when set, is compared with stdout by CompareMode
+	// after a clean exit to give an ACCEPTED or WRONG_ANSWER verdict.
+	ExpectedOutput *string     `json:"expected_output,omitempty"`
+	CompareMode    CompareMode `json:"compare_mode,omitempty"`
+
+	// Priority orders the job in the execution queue, higher first, when
+	// the queue is declared with priorities; see DefaultPriority.
+	Priority int `json:"priority"`
+
+	// RunAt is when a SCHEDULED job is queued; nil for jobs queued on
+	// submission.
+	RunAt *time.Time `json:"run_at,omitempty"`
+
+	// DiskUsedKB is what the sandbox work directory held when the run
+	// ended, capped by the worker's work dir quota.
+	DiskUsedKB *int `json:"disk_used_kb,omitempty"`
+
+	// CompileTimeMs is how long compiling took, once per job however many
+	// test cases ran; nil for interpreted languages and cached binaries.
+	CompileTimeMs *int `json:"compile_time_ms,omitempty"`
+
+	// Timings breaks down where the job's time went; nil until the job
+	// finishes, and for jobs finished before it was recorded.
+	Timings *Timings `json:"timings,omitempty"`
+
+	// Judging fields, set only for submissions against a problem.
+	ProblemID           string              `json:"problem_id,omitempty"`
+	TestDataVersion     *int                `json:"test_data_version,omitempty"`
+	JudgeRevision       int                 `json:"judge_revision,omitempty"`
+	TestResults         []TestCaseResult    `json:"test_results,omitempty"`
+	TerminationStrategy TerminationStrategy `json:"termination_strategy,omitempty"`
+	Score               *float64            `json:"score,omitempty"`
+	MaxScore            *float64            `json:"max_score,omitempty"`
+	SubtaskResults      []SubtaskResult     `json:"subtask_results,omitempty"`
+
+	// SolutionCode is the source as submitted when the problem's harness
+	// wrapped it; SourceCode then holds the assembled program.
+	SolutionCode string `json:"solution_code,omitempty"`
+
+	// Origin says why the job was created, and ParentJobID is the job it
+	// was resubmitted from. Rejudges run a job again in place instead.
+	Origin      JobOrigin  `json:"origin"`
+	ParentJobID *uuid.UUID `json:"parent_job_id,omitempty"`
+
+	// ExternalID is the client's own ID for the job, unique per tenant.
+	ExternalID string `json:"external_id,omitempty"`
+
+	// APIKeyID is the key the job was submitted with, if any.
+	APIKeyID *uuid.UUID `json:"api_key_id,omitempty"`
+
+	// UserID is the signed-in user who submitted the job, if any.
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+
+	// Debug asks the worker to keep every test case's output, whatever its
+	// retention policy. Set only on appeal reruns and never stored.
+	Debug bool `json:"debug,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Timings is where a job's time went, phase by phase, in milliseconds of
+// wall-clock time, as measured by the worker that ran it.
+type Timings struct {
+	// QueueWaitMs is from publishing the job until a worker took it up.
+	QueueWaitMs int `json:"queue_wait_ms"`
+	// WorkdirSetupMs is preparing the sandbox's work directory.
+	WorkdirSetupMs int `json:"workdir_setup_ms"`
+	// CompileMs is zero for interpreted languages.
+	CompileMs int `json:"compile_ms"`
+	RunMs     int `json:"run_ms"`
+	// ResultStoreMs is writing the result to the database.
+	ResultStoreMs int `json:"result_store_ms"`
+}
+
+// JobOrigin records why a job was created.
+type JobOrigin string
+
+const (
+	// OriginUser jobs were submitted, directly or through an integration.
+	OriginUser JobOrigin = "user"
+	// OriginResubmit jobs are copies of their parent made by a rerun.
+	OriginResubmit JobOrigin = "resubmit"
+	// OriginSchedule jobs were created by a recurring schedule.
+	OriginSchedule JobOrigin = "schedule"
+)
+
+// LineageEntry is one job of a lineage.
+type LineageEntry struct {
 	JobID         uuid.UUID       `json:"job_id"`
-	Language      Language        `json:"language"`
-	SourceCode    string          `json:"source_code"`
-	Stdin         string          `json:"stdin"`
-	Stdout        string          `json:"stdout,omitempty"`
-	Stderr        string          `json:"stderr,omitempty"`
+	ParentJobID   *uuid.UUID      `json:"parent_job_id,omitempty"`
+	Origin        JobOrigin       `json:"origin"`
 	Status        ExecutionStatus `json:"status"`
-	ExitCode      *int            `json:"exit_code,omitempty"`
-	TimeUsedMs    *int            `json:"time_used_ms,omitempty"`
-	MemoryUsedKB  *int            `json:"memory_used_kb,omitempty"`
-	TimeLimitMs   int             `json:"time_limit_ms"`
-	MemoryLimitKB int             `json:"memory_limit_kb"`
-	CreatedAt     time.Time       `json:"created_at"`
-	UpdatedAt     time.Time       `json:"updated_at"`
+	JudgeRevision int             `json:"judge_revision"`
+	// Runs counts how often the job was queued: once when submitted, and
+	// again for each rejudge, appeal rerun or requeue after a failure.
+	Runs      int       `json:"runs"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Lineage relates a job to the jobs it was resubmitted from and to.
+type Lineage struct {
+	JobID uuid.UUID `json:"job_id"`
+	// Chain runs from the original submission to the job itself.
+	Chain []*LineageEntry `json:"chain"`
+	// Descendants are the jobs resubmitted from the job, directly or from
+	// one of its descendants, oldest first.
+	Descendants []*LineageEntry `json:"descendants"`
+	// TotalRuns sums Runs over Chain and Descendants.
+	TotalRuns int `json:"total_runs"`
+}
+
+// LockID returns the key of the worker's processing lock for the job's
+// current judge revision. It must match the worker's Job.LockID.
+func (j *Job) LockID() uuid.UUID {
+	if j.JudgeRevision == 0 {
+		return j.JobID
+	}
+	return uuid.NewSHA1(j.JobID, []byte(fmt.Sprintf("judge-revision:%d", j.JudgeRevision)))
+}
+
+// JobEvent is one entry of a job's status timeline: a status the job entered
+// and when. Rejudges and appeal reruns start over from QUEUED in the same
+// timeline.
+type JobEvent struct {
+	Status    ExecutionStatus `json:"status"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	// Reason says why the status changed where the status does not, as
+	// "preempted" for a job a worker put back to QUEUED to run
+	// higher-priority work.
+	Reason string `json:"reason,omitempty"`
 }
 
 // SubmitRequest represents an incoming code submission from the API.
@@ -69,6 +266,153 @@ type SubmitRequest struct {
 	Stdin         string   `json:"stdin"`
 	TimeLimitMs   *int     `json:"time_limit_ms,omitempty"`
 	MemoryLimitKB *int     `json:"memory_limit_kb,omitempty"`
+
+	// WallTimeLimitMs takes precedence over TimeLimitMs, its older name.
+	// CPUTimeLimitMs limits CPU time separately from wall-clock time.
+	WallTimeLimitMs *int `json:"wall_time_limit_ms,omitempty"`
+	CPUTimeLimitMs  *int `json:"cpu_time_limit_ms,omitempty"`
+
+	// PidsLimit raises (or lowers) the run phase's process limit for
+	// fork-heavy programs such as test runners; compiling keeps the
+	// language's limit.
+	PidsLimit *int `json:"pids_limit,omitempty"`
+
+	// CompilerFlags are extra compiler arguments (e.g. "-O0", "-g"). The
+	// worker accepts only flags on the language's allowlist.
+	CompilerFlags []string `json:"compiler_flags,omitempty"`
+
+	// Args are passed to the program on its command line, after the
+	// language's own run arguments.
+	Args []string `json:"args,omitempty"`
+
+	// Env sets environment variables for the program. Only variables on the
+	// language's allowlist are accepted.
+	Env map[string]string `json:"env,omitempty"`
+
+	// ProblemID judges the submission against the problem's test data; stdin is ignored.
+	ProblemID string `json:"problem_id,omitempty"`
+
+	// SandboxTier asks for a class of isolation, such as a microVM, from
+	// the tiers the deployment offers. Workers map tiers to executors.
+	SandboxTier string `json:"sandbox_tier,omitempty"`
+
+	// NetworkPolicy is "none" (the default) or "allowlist", which lets the
+	// program reach the hosts on the workers' allowlist when the deployment
+	// enables it.
+	NetworkPolicy string `json:"network_policy,omitempty"`
+
+	// OutputFiles names files the program writes to its working directory,
+	// such as "output.txt" or "plot.png", to be kept after the run and
+	// downloaded from /submissions/{id}/artifacts/{name}. Not collected for
+	// judged submissions.
+	OutputFiles []string `json:"output_files,omitempty"`
+
+	// Interactive keeps the program's stdin open for input sent over the
+	// submission's WebSocket stream, which also carries its stdout as it is
+	// written, for REPL-style use. Stdin, if any, is read first. Not
+	// available for judged submissions.
+	Interactive bool `json:"interactive,omitempty"`
+
+	// ExpectedOutput turns a clean exit into ACCEPTED when stdout matches
+	// it and WRONG_ANSWER when it does not; other statuses are unchanged.
+	// CompareMode, tokens by default, says how they are matched. Judged
+	// submissions use their problem's test data instead.
+	ExpectedOutput *string     `json:"expected_output,omitempty"`
+	CompareMode    CompareMode `json:"compare_mode,omitempty"`
+
+	// Priority, 0 to MaxPriority, lets interactive traffic run ahead of
+	// bulk work queued at the same time. DefaultPriority when nil.
+	Priority *int `json:"priority,omitempty"`
+
+	// RunAt stores the job as SCHEDULED and queues it at that time, such
+	// as the start of a contest. A time not in the future queues it at once.
+	RunAt *time.Time `json:"run_at,omitempty"`
+
+	// ExternalID tags the job with an ID from the client's own system, so
+	// it can be fetched by that ID instead of the job ID. A tenant's jobs
+	// have distinct external IDs; reruns do not copy it.
+	ExternalID string `json:"external_id,omitempty"`
+
+	// TenantID is resolved from the X-Tenant-ID header by the handler, never from the body.
+	TenantID string `json:"-"`
+
+	// Origin and ParentJobID are set by reruns and schedules, never from
+	// the body. Origin is OriginUser when empty.
+	Origin      JobOrigin  `json:"-"`
+	ParentJobID *uuid.UUID `json:"-"`
+
+	// APIKeyID is the key that authenticated the submission, set by the
+	// handler, never from the body. Reruns keep their original's key.
+	APIKeyID *uuid.UUID `json:"-"`
+
+	// UserID is the signed-in user making the submission, set by the
+	// handler, never from the body. Reruns keep their original's user.
+	UserID *uuid.UUID `json:"-"`
+}
+
+// Queue priorities of a job. The execution queue only orders by priority
+// when the deployment declares it with RABBITMQ_MAX_PRIORITY.
+const (
+	// PriorityBulk is the priority rejudges are queued at, behind every
+	// submission that did not ask for it.
+	PriorityBulk = 0
+	// DefaultPriority is the priority of a submission that names none.
+	DefaultPriority = 5
+	// MaxPriority is the highest priority a submission may ask for.
+	MaxPriority = 9
+)
+
+// Network policies of a submission.
+const (
+	NetworkNone      = "none"
+	NetworkAllowlist = "allowlist"
+)
+
+// CompareMode is how a submission's stdout is matched with its expected
+// output. It must match the worker's judge.CompareMode.
+type CompareMode string
+
+const (
+	// CompareExact requires byte-for-byte equal output.
+	CompareExact CompareMode = "exact"
+	// CompareTrailingWhitespace ignores whitespace at the end of each line
+	// and blank lines at the end of the output.
+	CompareTrailingWhitespace CompareMode = "trailing_whitespace"
+	// CompareTokens compares whitespace-separated tokens, numbers within
+	// the float tolerance, as problems are judged.
+	CompareTokens CompareMode = "tokens"
+)
+
+// IsValid reports whether m is a known compare mode.
+func (m CompareMode) IsValid() bool {
+	switch m {
+	case CompareExact, CompareTrailingWhitespace, CompareTokens:
+		return true
+	}
+	return false
+}
+
+// Artifact is an output file a job's program produced, as collected by the
+// worker.
+type Artifact struct {
+	Name    string
+	Content []byte
+	// SizeBytes is the file's size in the sandbox; Content holds only its
+	// first bytes when Truncated is set.
+	SizeBytes int64
+	Truncated bool
+	CreatedAt time.Time
+}
+
+// DefaultTenantID is used for submissions that do not identify a tenant.
+const DefaultTenantID = "default"
+
+// CancelResponse is returned by a cancellation. Status is CANCELLED for a
+// job cancelled while queued, or the status of a running job the worker has
+// been told to stop.
+type CancelResponse struct {
+	JobID  uuid.UUID       `json:"job_id"`
+	Status ExecutionStatus `json:"status"`
 }
 
 // SubmitResponse is returned after a successful submission.
@@ -77,9 +421,75 @@ type SubmitResponse struct {
 	Status string    `json:"status"`
 }
 
-// LanguageInfo describes a supported language.
+// JobPage is one page of GET /admin/jobs or GET /submissions. NextCursor,
+// if set, fetches the next page.
+type JobPage struct {
+	Jobs       []*JobSummary `json:"jobs"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// JobFilter selects the jobs a listing returns; zero fields select any.
+type JobFilter struct {
+	Status   ExecutionStatus
+	TenantID string
+	APIKeyID *uuid.UUID
+	UserID   *uuid.UUID
+}
+
+// EncodeJobCursor returns the cursor of a job listing that continues after
+// the job id. Job IDs are UUIDv7, so listings ordered by ID are ordered by
+// creation time.
+func EncodeJobCursor(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// ParseJobCursor decodes a cursor made by EncodeJobCursor.
+func ParseJobCursor(cursor string) (uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: malformed cursor", ErrInvalidJobFilter)
+	}
+	id, err := uuid.FromBytes(raw)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("%w: malformed cursor", ErrInvalidJobFilter)
+	}
+	return id, nil
+}
+
+// JobSummary is a job as listed by GET /admin/jobs, without its source,
+// input or output.
+type JobSummary struct {
+	JobID        uuid.UUID       `json:"job_id"`
+	TenantID     string          `json:"tenant_id"`
+	Language     Language        `json:"language"`
+	Status       ExecutionStatus `json:"status"`
+	ProblemID    string          `json:"problem_id,omitempty"`
+	Score        *float64        `json:"score,omitempty"`
+	MaxScore     *float64        `json:"max_score,omitempty"`
+	TimeUsedMs   *int            `json:"time_used_ms,omitempty"`
+	MemoryUsedKB *int            `json:"memory_used_kb,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// LanguageInfo describes a supported language and what submissions in it
+// may ask for, so clients can offer only the options that apply.
 type LanguageInfo struct {
 	Name     Language `json:"name"`
 	Version  string   `json:"version"`
 	Compiler string   `json:"compiler,omitempty"`
+
+	// Versions lists the versions submissions can run on. Each language
+	// has one toolchain, so it holds Version alone.
+	Versions []string `json:"versions"`
+	// SupportsCompileArgs reports whether compiler_flags are accepted,
+	// from the language's allowlist of flags.
+	SupportsCompileArgs bool `json:"supports_compile_args"`
+	// SupportsMultiFile reports whether a submission may span several
+	// source files. Submissions are a single file in every language.
+	SupportsMultiFile bool `json:"supports_multi_file"`
+	// MaxTimeMs and MaxMemoryKB are the largest time and memory limits a
+	// submission may ask for.
+	MaxTimeMs   int `json:"max_time_ms"`
+	MaxMemoryKB int `json:"max_memory_kb"`
 }
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JobFields lists the job fields a sparse fieldset may select, by JSON name.
+// Debug is never stored and cannot be selected.
+var JobFields = []string{
+	"job_id", "tenant_id", "language", "source_code", "stdin", "stdout", "stderr",
+	"status", "exit_code", "time_used_ms", "memory_used_kb", "time_limit_ms",
+	"memory_limit_kb", "wall_time_limit_ms", "cpu_time_limit_ms", "cpu_time_used_ms",
+	"pids_limit", "disk_used_kb", "compile_time_ms", "timings", "compiler_flags", "args", "env", "sandbox_tier",
+	"network_policy", "output_files", "interactive", "expected_output", "compare_mode",
+	"problem_id", "test_data_version", "judge_revision", "test_results",
+	"termination_strategy", "score", "max_score", "subtask_results",
+	"solution_code", "priority", "run_at", "origin", "parent_job_id", "external_id", "api_key_id", "user_id", "created_at", "updated_at",
+}
+
+// ParseJobFields parses a comma-separated sparse fieldset such as
+// "status,stdout,time_used_ms". job_id is always included. An empty string
+// selects nothing and returns nil, meaning the full job.
+func ParseJobFields(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	known := make(map[string]bool, len(JobFields))
+	for _, f := range JobFields {
+		known[f] = true
+	}
+	fields := []string{"job_id"}
+	seen := map[string]bool{"job_id": true}
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if !known[f] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidFields, f)
+		}
+		if !seen[f] {
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// ProjectJob returns the JSON object of job restricted to fields. Fields the
+// full response would omit, such as an empty stdout, are omitted here too.
+func ProjectJob(job *Job, fields []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			projected[f] = v
+		}
+	}
+	return projected, nil
+}
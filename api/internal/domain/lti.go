@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LTITenantMapping decides which tenant a launch's submissions count
+// against.
+type LTITenantMapping string
+
+const (
+	// LTITenantPlatform puts every user of the platform in its tenant.
+	LTITenantPlatform LTITenantMapping = "platform"
+	// LTITenantContext gives each course (LTI context) a tenant of its own,
+	// "<platform tenant>:<context id>".
+	LTITenantContext LTITenantMapping = "context"
+	// LTITenantUser gives each user a tenant of their own,
+	// "<platform tenant>:<user id>".
+	LTITenantUser LTITenantMapping = "user"
+)
+
+// IsValid reports whether m is a known tenant mapping.
+func (m LTITenantMapping) IsValid() bool {
+	switch m {
+	case LTITenantPlatform, LTITenantContext, LTITenantUser:
+		return true
+	}
+	return false
+}
+
+// LTIPlatform is a learning platform (Moodle, Canvas, ...) registered to
+// launch Sentinel as an LTI 1.3 tool. Issuer and ClientID identify it in
+// launches; the URLs are the platform's OIDC login, OAuth2 token and key
+// set endpoints.
+type LTIPlatform struct {
+	ID       uuid.UUID `json:"platform_id"`
+	TenantID string    `json:"tenant_id"`
+	Issuer   string    `json:"issuer"`
+	ClientID string    `json:"client_id"`
+	// DeploymentID, if set, is the only deployment launches are accepted from.
+	DeploymentID  string           `json:"deployment_id,omitempty"`
+	AuthLoginURL  string           `json:"auth_login_url"`
+	TokenURL      string           `json:"token_url"`
+	JWKSURL       string           `json:"jwks_url"`
+	TenantMapping LTITenantMapping `json:"tenant_mapping"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// RegisterLTIPlatformRequest registers a platform. TenantMapping defaults to
+// LTITenantPlatform.
+type RegisterLTIPlatformRequest struct {
+	Issuer        string           `json:"issuer" binding:"required"`
+	ClientID      string           `json:"client_id" binding:"required"`
+	DeploymentID  string           `json:"deployment_id,omitempty"`
+	AuthLoginURL  string           `json:"auth_login_url" binding:"required"`
+	TokenURL      string           `json:"token_url" binding:"required"`
+	JWKSURL       string           `json:"jwks_url" binding:"required"`
+	TenantMapping LTITenantMapping `json:"tenant_mapping,omitempty"`
+}
+
+// LTIUser is a platform user who has launched Sentinel, with the tenant
+// their submissions count against.
+type LTIUser struct {
+	PlatformID    uuid.UUID `json:"platform_id"`
+	Subject       string    `json:"subject"`
+	TenantID      string    `json:"tenant_id"`
+	Name          string    `json:"name,omitempty"`
+	Email         string    `json:"email,omitempty"`
+	FirstLaunchAt time.Time `json:"first_launch_at"`
+	LastLaunchAt  time.Time `json:"last_launch_at"`
+}
+
+// LTILogin is the state of an OIDC login awaiting its launch.
+type LTILogin struct {
+	PlatformID uuid.UUID `json:"platform_id"`
+	Nonce      string    `json:"nonce"`
+}
+
+// LTILaunch is a verified resource link launch: one user opening one
+// assignment, which they submit to until the launch expires.
+type LTILaunch struct {
+	ID         uuid.UUID `json:"launch_id"`
+	PlatformID uuid.UUID `json:"platform_id"`
+	Subject    string    `json:"subject"`
+	TenantID   string    `json:"tenant_id"`
+	ProblemID  string    `json:"problem_id"`
+	// LineItem is the gradebook column scores are posted to, unset when the
+	// platform grants none.
+	LineItem  string    `json:"-"`
+	Graded    bool      `json:"graded"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LTISubmitRequest submits a solution to a launch's problem.
+type LTISubmitRequest struct {
+	Language   Language `json:"language" binding:"required"`
+	SourceCode string   `json:"source_code" binding:"required"`
+}
+
+// LTIScore is a job submitted from a graded launch, whose score is still to
+// be posted to the platform.
+type LTIScore struct {
+	JobID      uuid.UUID
+	PlatformID uuid.UUID
+	Subject    string
+	LineItem   string
+
+	// Status, Score and MaxScore are the job's, read with the score.
+	Status   ExecutionStatus
+	Score    *float64
+	MaxScore *float64
+}
@@ -0,0 +1,244 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Problem is a judged task with a versioned set of test cases. Replacing the
+// test data bumps TestDataVersion; submissions record the version they were
+// judged against so outdated verdicts can be rejudged.
+type Problem struct {
+	ProblemID           string              `json:"problem_id"`
+	Title               string              `json:"title"`
+	TestDataVersion     int                 `json:"test_data_version"`
+	TerminationStrategy TerminationStrategy `json:"termination_strategy"`
+	TestCases           []TestCase          `json:"test_cases,omitempty"`
+	Subtasks            []Subtask           `json:"subtasks,omitempty"`
+	Generator           *Generator          `json:"generator,omitempty"`
+	Comparator          *Comparator         `json:"comparator,omitempty"`
+	Interactor          *Interactor         `json:"interactor,omitempty"`
+	Harnesses           []Harness           `json:"harnesses,omitempty"`
+	Rules               []SourceRule        `json:"rules,omitempty"`
+	KillPatterns        []KillPattern       `json:"kill_patterns,omitempty"`
+	CreatedAt           time.Time           `json:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at"`
+}
+
+// TerminationStrategy decides which test cases still run after one fails.
+type TerminationStrategy string
+
+const (
+	// TerminateRunAll runs every case regardless of failures.
+	TerminateRunAll TerminationStrategy = "run_all"
+	// TerminateFirstFailure skips every case after the first failure.
+	TerminateFirstFailure TerminationStrategy = "first_failure"
+	// TerminatePerSubtask skips the rest of a subtask once one of its cases fails.
+	TerminatePerSubtask TerminationStrategy = "per_subtask"
+)
+
+// IsValid reports whether s is a known strategy.
+func (s TerminationStrategy) IsValid() bool {
+	switch s {
+	case TerminateRunAll, TerminateFirstFailure, TerminatePerSubtask:
+		return true
+	}
+	return false
+}
+
+// TestCase is a single input/expected-output pair of a problem.
+type TestCase struct {
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expected_output"`
+
+	// Subtask is the 1-based position in the problem's subtask list, or 0
+	// for an ungraded case such as a sample.
+	Subtask int `json:"subtask,omitempty"`
+
+	// TimeLimitMs and MemoryLimitKB override the submission's limits for
+	// this case; nil inherits them.
+	TimeLimitMs   *int `json:"time_limit_ms,omitempty"`
+	MemoryLimitKB *int `json:"memory_limit_kb,omitempty"`
+
+	// GeneratorSeed makes the worker produce the input by running the
+	// problem's generator with this seed; Input must then be empty.
+	GeneratorSeed *int64 `json:"generator_seed,omitempty"`
+}
+
+// Generator is a program that prints a test input for the seed given as its
+// only command-line argument, so large inputs never need to be uploaded.
+type Generator struct {
+	Language   Language `json:"language"`
+	SourceCode string   `json:"source_code"`
+
+	// Hash identifies the language and source; workers key their cache of
+	// generated inputs on it. Computed by the server.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Comparator is a WebAssembly module that judges each case's output in
+// place of the token comparison. See docs/api.md for the exports it needs.
+type Comparator struct {
+	// Wasm is the module, base64-encoded in JSON. It is not returned.
+	Wasm []byte `json:"wasm,omitempty"`
+
+	// Hash identifies the module; workers key their compiled modules on it.
+	// Computed by the server.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Subtask groups test cases into a unit worth Points, awarded as Scoring
+// says.
+type Subtask struct {
+	Points  float64        `json:"points"`
+	Scoring SubtaskScoring `json:"scoring,omitempty"`
+}
+
+// SubtaskScoring decides how much of a subtask's points a submission earns.
+type SubtaskScoring string
+
+const (
+	// ScoreAllOrNothing awards the points only when every case is accepted.
+	ScoreAllOrNothing SubtaskScoring = "all_or_nothing"
+	// ScorePerTest awards the points in proportion to the accepted cases.
+	ScorePerTest SubtaskScoring = "per_test"
+)
+
+// IsValid reports whether s is a known scoring mode.
+func (s SubtaskScoring) IsValid() bool {
+	switch s {
+	case ScoreAllOrNothing, ScorePerTest:
+		return true
+	}
+	return false
+}
+
+// TestCaseResult is the per-case outcome recorded by the worker.
+type TestCaseResult struct {
+	Ordinal      int             `json:"ordinal"`
+	Status       ExecutionStatus `json:"status"`
+	TimeUsedMs   int             `json:"time_used_ms"`
+	MemoryUsedKB int             `json:"memory_used_kb"`
+	Message      string          `json:"message,omitempty"`
+	Subtask      int             `json:"subtask,omitempty"`
+
+	// TimeLimitMs and MemoryLimitKB are the limits the case ran under.
+	TimeLimitMs   int `json:"time_limit_ms"`
+	MemoryLimitKB int `json:"memory_limit_kb"`
+
+	// OutputHash is the SHA-256 of the case's stdout. Output holds the
+	// stdout itself when the worker's retention policy kept it.
+	OutputHash string `json:"output_hash,omitempty"`
+	Output     string `json:"output,omitempty"`
+}
+
+// SubtaskResult is the score awarded for one subtask of a judged job.
+type SubtaskResult struct {
+	Ordinal int             `json:"ordinal"`
+	Status  ExecutionStatus `json:"status"`
+	Points  float64         `json:"points"`
+	Score   float64         `json:"score"`
+}
+
+// Interactor is a program that converses with the submission over its stdin
+// and stdout and decides each case's verdict by its exit code, for
+// interactive problems. See docs/api.md for the protocol.
+type Interactor struct {
+	Language   Language `json:"language"`
+	SourceCode string   `json:"source_code"`
+}
+
+// HarnessPlaceholder marks where a harness template takes the submitted
+// source.
+const HarnessPlaceholder = "{{solution}}"
+
+// Harness is the driver a problem wraps around submissions in Language, so
+// a submission holds only the function under test. Template is a complete
+// program containing HarnessPlaceholder once; the server replaces it with
+// the submitted source before the job runs.
+type Harness struct {
+	Language Language `json:"language"`
+	Template string   `json:"template"`
+}
+
+// Wrap returns the program that runs source under the harness.
+func (h Harness) Wrap(source string) string {
+	return strings.Replace(h.Template, HarnessPlaceholder, source, 1)
+}
+
+// HarnessFor returns the problem's harness for lang. ok is false when the
+// problem has none for lang.
+func (p *Problem) HarnessFor(lang Language) (h Harness, ok bool) {
+	for _, h := range p.Harnesses {
+		if h.Language == lang {
+			return h, true
+		}
+	}
+	return Harness{}, false
+}
+
+// SourceRule forbids submissions in Language whose source matches Pattern,
+// an RE2 regular expression in multi-line mode such as
+// `^\s*(import|from)\s+(os|subprocess)\b`. A matching submission is given
+// the RULE_VIOLATION verdict without running; Message, when set, explains
+// the rule to the submitter.
+type SourceRule struct {
+	Language Language `json:"language"`
+	Pattern  string   `json:"pattern"`
+	Message  string   `json:"message,omitempty"`
+}
+
+// KillPattern ends a judged submission's run as soon as Repeats identical
+// lines in a row of its stdout or stderr match Pattern, an RE2 regular
+// expression such as `(?i)ignore (all )?previous instructions`, and gives
+// the test case the OUTPUT_VIOLATION verdict. Repeats of 0 or 1 ends it at
+// the first matching line; `.` with Repeats 10000 stops a program printing
+// the same line over and over. Message, when set, explains the verdict.
+type KillPattern struct {
+	Pattern string `json:"pattern"`
+	Repeats int    `json:"repeats,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// CreateProblemRequest creates a problem with its first test-data version.
+type CreateProblemRequest struct {
+	ProblemID  string       `json:"problem_id" binding:"required"`
+	Title      string       `json:"title"`
+	TestCases  []TestCase   `json:"test_cases" binding:"required"`
+	Subtasks   []Subtask    `json:"subtasks,omitempty"`
+	Generator  *Generator   `json:"generator,omitempty"`
+	Comparator *Comparator  `json:"comparator,omitempty"`
+	Interactor *Interactor  `json:"interactor,omitempty"`
+	Harnesses  []Harness    `json:"harnesses,omitempty"`
+	Rules      []SourceRule `json:"rules,omitempty"`
+
+	KillPatterns []KillPattern `json:"kill_patterns,omitempty"`
+
+	// TerminationStrategy defaults to run_all when empty.
+	TerminationStrategy TerminationStrategy `json:"termination_strategy,omitempty"`
+}
+
+// UpdateTestDataRequest replaces a problem's test cases with a new version.
+type UpdateTestDataRequest struct {
+	TestCases  []TestCase   `json:"test_cases" binding:"required"`
+	Subtasks   []Subtask    `json:"subtasks,omitempty"`
+	Generator  *Generator   `json:"generator,omitempty"`
+	Comparator *Comparator  `json:"comparator,omitempty"`
+	Interactor *Interactor  `json:"interactor,omitempty"`
+	Harnesses  []Harness    `json:"harnesses,omitempty"`
+	Rules      []SourceRule `json:"rules,omitempty"`
+
+	KillPatterns []KillPattern `json:"kill_patterns,omitempty"`
+}
+
+// RejudgeResponse summarises a rejudge of outdated submissions.
+type RejudgeResponse struct {
+	ProblemID       string `json:"problem_id"`
+	TestDataVersion int    `json:"test_data_version"`
+	Requeued        int    `json:"requeued"`
+	Failed          int    `json:"failed"`
+
+	// RuleViolations counts submissions that now break one of the
+	// problem's rules; they get that verdict instead of being requeued.
+	RuleViolations int `json:"rule_violations"`
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// QueuePause is a language whose jobs workers hold back in the broker
+// instead of running them, such as while its toolchain is upgraded.
+type QueuePause struct {
+	Language Language  `json:"language"`
+	PausedAt time.Time `json:"paused_at"`
+	// Held counts the jobs waiting in the language's holding queue. Jobs
+	// still in the execution queue join it as workers receive them.
+	Held int `json:"held"`
+}
+
+// QueueResume reports a resumed language and how many held jobs went back
+// to the execution queue.
+type QueueResume struct {
+	Language Language `json:"language"`
+	Released int      `json:"released"`
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// RepairAction names one recovery routine of POST /admin/repair.
+type RepairAction string
+
+const (
+	// RepairPurgeLocks deletes worker processing locks of unfinished jobs
+	// that were taken longer ago than the cutoff. Such a lock belongs to a
+	// worker that died mid-job and makes every redelivery look like a
+	// duplicate.
+	RepairPurgeLocks RepairAction = "purge_locks"
+
+	// RepairReconcileRunning resets COMPILING and RUNNING jobs untouched
+	// since the cutoff whose processing lock is gone to QUEUED and publishes
+	// them again. No worker is running them and the broker will not deliver
+	// them again.
+	RepairReconcileRunning RepairAction = "reconcile_running"
+
+	// RepairRequeueStuck publishes QUEUED jobs untouched since the cutoff
+	// again, for messages the broker lost or dead-lettered.
+	RepairRequeueStuck RepairAction = "requeue_stuck"
+)
+
+// RepairActions lists every action in the order a repair runs them: stale
+// locks go first so the other actions can pick up the jobs they held.
+var RepairActions = []RepairAction{RepairPurgeLocks, RepairReconcileRunning, RepairRequeueStuck}
+
+// RepairRequest selects the actions of one repair run.
+type RepairRequest struct {
+	Actions []RepairAction `json:"actions" binding:"required"`
+	// OlderThanSeconds is the cutoff: only jobs (and locks) untouched for
+	// longer are repaired. Zero selects the default.
+	OlderThanSeconds int `json:"older_than_seconds,omitempty"`
+	// Limit caps the jobs each action looks at. Zero selects the default.
+	Limit int `json:"limit,omitempty"`
+	// DryRun reports what would be done without changing anything.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// RepairOutcome is what an action did, or in a dry run would do, to a job.
+type RepairOutcome string
+
+const (
+	RepairOutcomePurged        RepairOutcome = "purged"
+	RepairOutcomeRequeued      RepairOutcome = "requeued"
+	RepairOutcomeWouldPurge    RepairOutcome = "would_purge"
+	RepairOutcomeWouldRequeue  RepairOutcome = "would_requeue"
+	RepairOutcomeLocked        RepairOutcome = "skipped_locked"
+	RepairOutcomeChanged       RepairOutcome = "skipped_changed"
+	RepairOutcomePublishFailed RepairOutcome = "publish_failed"
+)
+
+// RepairedJob is one job an action matched.
+type RepairedJob struct {
+	JobID   uuid.UUID       `json:"job_id"`
+	Status  ExecutionStatus `json:"status"`
+	Outcome RepairOutcome   `json:"outcome"`
+}
+
+// RepairActionReport is the outcome of one action.
+type RepairActionReport struct {
+	Action RepairAction `json:"action"`
+	// Matched counts the jobs the action found; Changed the ones it
+	// repaired, always zero in a dry run.
+	Matched int           `json:"matched"`
+	Changed int           `json:"changed"`
+	Jobs    []RepairedJob `json:"jobs"`
+}
+
+// RepairReport is the response of POST /admin/repair.
+type RepairReport struct {
+	DryRun           bool                 `json:"dry_run"`
+	OlderThanSeconds int                  `json:"older_than_seconds"`
+	Actions          []RepairActionReport `json:"actions"`
+}
+
+// ParseRepairActions checks that actions are known and distinct and returns
+// them in the order a repair runs them.
+func ParseRepairActions(actions []RepairAction) ([]RepairAction, error) {
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("%w: at least one action is required", ErrInvalidRepair)
+	}
+	requested := make(map[RepairAction]bool, len(actions))
+	for _, a := range actions {
+		known := false
+		for _, k := range RepairActions {
+			known = known || a == k
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidRepair, a)
+		}
+		if requested[a] {
+			return nil, fmt.Errorf("%w: action %q given twice", ErrInvalidRepair, a)
+		}
+		requested[a] = true
+	}
+	ordered := make([]RepairAction, 0, len(actions))
+	for _, a := range RepairActions {
+		if requested[a] {
+			ordered = append(ordered, a)
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Mount is a host path bind-mounted read-only into a runtime's sandbox.
+type Mount struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// RuntimeSpec describes how the worker compiles and runs a runtime's
+// programs. Commands use the same {source}, {binary} and {workdir}
+// placeholders as the language registry, and NsjailConfig names a profile in
+// the worker's sandbox config dir.
+type RuntimeSpec struct {
+	Version      string            `json:"version"`
+	SourceFile   string            `json:"source_file" binding:"required"`
+	NsjailConfig string            `json:"nsjail_config" binding:"required"`
+	Compile      []string          `json:"compile,omitempty"`
+	Run          []string          `json:"run" binding:"required"`
+	Mounts       []Mount           `json:"mounts,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+}
+
+// Runtime is an execution preset registered through the API. Submissions
+// name it as their language, so a niche interpreter can be offered by
+// mounting it into the sandbox instead of shipping a new language registry.
+type Runtime struct {
+	Name Language `json:"name"`
+	RuntimeSpec
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateRuntimeRequest registers a new runtime.
+type CreateRuntimeRequest struct {
+	Name Language `json:"name" binding:"required"`
+	RuntimeSpec
+}
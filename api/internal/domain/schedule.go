@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schedule creates a submission from its job template each time its cron
+// expression fires, as the tenant that created it. The expression is
+// evaluated in UTC.
+type Schedule struct {
+	ScheduleID uuid.UUID     `json:"schedule_id"`
+	TenantID   string        `json:"tenant_id"`
+	Name       string        `json:"name,omitempty"`
+	Cron       string        `json:"cron"`
+	Job        SubmitRequest `json:"job"`
+
+	// NextRunAt is when the schedule fires next.
+	NextRunAt time.Time `json:"next_run_at"`
+
+	// LastRunAt is when the schedule last fired. LastJobID is the job it
+	// created then, and LastStatus that job's current status; LastError
+	// says why no job was created, for example because the tenant's quota
+	// was exhausted.
+	LastRunAt  *time.Time      `json:"last_run_at,omitempty"`
+	LastJobID  *uuid.UUID      `json:"last_job_id,omitempty"`
+	LastStatus ExecutionStatus `json:"last_status,omitempty"`
+	LastError  string          `json:"last_error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateScheduleRequest creates a schedule. Job is validated as a
+// submission when the schedule is created, and again each time it runs.
+type CreateScheduleRequest struct {
+	Name string        `json:"name"`
+	Cron string        `json:"cron" binding:"required"`
+	Job  SubmitRequest `json:"job"`
+}
+
+// ScheduleRun is the outcome of one firing of a schedule: the job it
+// created, or the error that kept it from creating one.
+type ScheduleRun struct {
+	At    time.Time
+	JobID *uuid.UUID
+	Error string
+}
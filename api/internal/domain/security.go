@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecurityEventKind says what a run did that the sandbox or its policies
+// stopped. Workers record the events with the job's result.
+type SecurityEventKind string
+
+const (
+	// SecuritySeccompViolation: the program made a syscall its language's
+	// seccomp policy forbids and was killed with SIGSYS.
+	SecuritySeccompViolation SecurityEventKind = "seccomp_violation"
+	// SecurityNetworkBlocked: a run under the allowlist network policy sent
+	// packets to destinations outside the allowlist.
+	SecurityNetworkBlocked SecurityEventKind = "network_blocked"
+	// SecurityAbuseFlag: the output matched one of the problem's kill
+	// patterns.
+	SecurityAbuseFlag SecurityEventKind = "abuse_flag"
+	// SecurityQuarantine: the job's message kept being redelivered and was
+	// quarantined unrun.
+	SecurityQuarantine SecurityEventKind = "quarantine"
+)
+
+// SecurityEventKinds lists every kind, in the order exports describe them.
+var SecurityEventKinds = []SecurityEventKind{
+	SecuritySeccompViolation, SecurityNetworkBlocked, SecurityAbuseFlag, SecurityQuarantine,
+}
+
+// SecurityEvent is one security-relevant thing that happened to a job, as
+// listed by GET /admin/security-events. EventID orders events and makes
+// each unique.
+type SecurityEvent struct {
+	EventID   int64             `json:"event_id"`
+	JobID     uuid.UUID         `json:"job_id"`
+	TenantID  string            `json:"tenant_id"`
+	Kind      SecurityEventKind `json:"kind"`
+	Detail    string            `json:"detail,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// SecurityEventFilter selects the events a listing returns; zero fields
+// select any.
+type SecurityEventFilter struct {
+	Kind     SecurityEventKind
+	TenantID string
+}
+
+// SecurityEventPage is one page of GET /admin/security-events, oldest
+// event first. NextCursor continues after the page's last event, or after
+// the request's cursor if the page is empty, so a consumer polling for new
+// events always has one to resume from; HasMore says whether events past
+// it are already available.
+type SecurityEventPage struct {
+	Events     []*SecurityEvent `json:"events"`
+	NextCursor string           `json:"next_cursor"`
+	HasMore    bool             `json:"has_more"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User is an account that signs in with an email and password, or through
+// an OIDC provider, and submits as its tenant. Password users' emails are
+// unique across tenants, so logging in needs no tenant; a provider's users
+// have whatever email it vouches for, if any.
+type User struct {
+	UserID    uuid.UUID `json:"user_id"`
+	TenantID  string    `json:"tenant_id"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RegisterUserRequest creates a user in the tenant the request acts as.
+type RegisterUserRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginRequest exchanges a user's credentials for a token.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UserToken is a signed token that authenticates requests as its user
+// until it expires. Clients send it as "Authorization: Bearer <token>".
+type UserToken struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      User      `json:"user"`
+}
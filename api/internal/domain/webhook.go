@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DLQWebhook is a tenant's endpoint for dead-letter notifications. Workers
+// POST to URL whenever one of the tenant's jobs is dead-lettered or
+// quarantined, signing the body with Secret.
+type DLQWebhook struct {
+	TenantID string `json:"tenant_id"`
+	URL      string `json:"url"`
+	// Secret is returned only by the request that sets the webhook.
+	Secret string `json:"secret,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetDLQWebhookRequest sets a tenant's dead-letter webhook. Without a
+// Secret one is generated.
+type SetDLQWebhookRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// ResultWebhook is a tenant's endpoint for execution results. The API POSTs
+// a ResultEvent to URL each time one of the tenant's jobs finishes, signed
+// like dead-letter notifications, and retries until the endpoint accepts it.
+type ResultWebhook struct {
+	TenantID string `json:"tenant_id"`
+	URL      string `json:"url"`
+	// Secret is returned only by the request that sets the webhook.
+	Secret string `json:"secret,omitempty"`
+
+	// ConsecutiveFailures counts failed attempts since the endpoint last
+	// accepted a delivery.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// CircuitOpenUntil, while in the future, holds every delivery back:
+	// the endpoint failed too often in a row.
+	CircuitOpenUntil *time.Time `json:"circuit_open_until,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetResultWebhookRequest sets a tenant's result webhook. Without a Secret
+// one is generated.
+type SetResultWebhookRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// DeliveryStatus is where a result webhook delivery stands.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are sent, or retried, once due.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered deliveries were accepted with a 2xx response.
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed deliveries ran out of attempts or were refused; only a
+	// redrive sends them again.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one job result owed to a tenant's result webhook. A
+// delivery is recorded each time a job reaches a terminal status, so a
+// rejudged job is delivered again.
+type WebhookDelivery struct {
+	ID       int64     `json:"delivery_id"`
+	TenantID string    `json:"tenant_id"`
+	JobID    uuid.UUID `json:"job_id"`
+	// JobStatus is the status the job finished with.
+	JobStatus ExecutionStatus `json:"job_status"`
+
+	Status DeliveryStatus `json:"status"`
+	// Attempts counts the attempts since the delivery was recorded or last
+	// redriven; History lists all of them.
+	Attempts int `json:"attempts"`
+	Redrives int `json:"redrives"`
+	// NextAttemptAt is when a pending delivery is next tried.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// History is set only when fetching a single delivery.
+	History []*WebhookAttempt `json:"history,omitempty"`
+}
+
+// WebhookAttempt is one POST of a delivery to the tenant's endpoint.
+type WebhookAttempt struct {
+	URL string `json:"url"`
+	// StatusCode is the endpoint's response, or zero if none arrived.
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// ResultEventType is the event of every result webhook delivery.
+const ResultEventType = "job.finished"
+
+// ResultEvent is the body POSTed to a result webhook. Job is the job as
+// stored when the attempt is made.
+type ResultEvent struct {
+	Event      string `json:"event"`
+	DeliveryID int64  `json:"delivery_id"`
+	Job        *Job   `json:"job"`
+}
+
+// DeliveryPage is one page of a tenant's deliveries, newest first.
+// NextBefore, if set, fetches the next page.
+type DeliveryPage struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	NextBefore int64              `json:"next_before,omitempty"`
+}
@@ -0,0 +1,178 @@
+// Package github is a minimal client for the parts of the GitHub REST API
+// the submission intake uses: reading a file at a commit and setting commit
+// statuses.
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the public GitHub API.
+const DefaultBaseURL = "https://api.github.com"
+
+const (
+	// requestTimeout bounds each call to the API.
+	requestTimeout = 10 * time.Second
+
+	// maxResponseSize caps a response body; the contents API inlines files
+	// of up to 1 MB, base64-encoded.
+	maxResponseSize = 2 << 20
+)
+
+// ErrNotFound is returned when the repository, ref or path does not exist
+// or the token cannot see it.
+var ErrNotFound = errors.New("github: not found")
+
+// Commit status states.
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// Status is a commit status.
+type Status struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// Client is the part of the GitHub API the submission intake uses.
+type Client interface {
+	// Contents returns the file at path in repo ("owner/name") at ref. A
+	// path naming a directory that holds exactly one file returns that file.
+	Contents(ctx context.Context, token, repo, path, ref string) (string, error)
+
+	// SetStatus sets a status on the commit sha.
+	SetStatus(ctx context.Context, token, repo, sha string, status Status) error
+}
+
+type restClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the API at baseURL.
+func NewClient(baseURL string) Client {
+	return &restClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// contentEntry is one item of the contents API: a file with its content, or
+// an entry of a directory listing without it.
+type contentEntry struct {
+	Type     string `json:"type"`
+	Path     string `json:"path"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+func (c *restClient) Contents(ctx context.Context, token, repo, path, ref string) (string, error) {
+	body, err := c.getContents(ctx, token, repo, path, ref)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		var listing []contentEntry
+		if err := json.Unmarshal(body, &listing); err != nil {
+			return "", fmt.Errorf("github: decode directory %s: %w", path, err)
+		}
+		var files []string
+		for _, e := range listing {
+			if e.Type == "file" {
+				files = append(files, e.Path)
+			}
+		}
+		if len(files) != 1 {
+			return "", fmt.Errorf("github: directory %s holds %d files, want exactly one", path, len(files))
+		}
+		if body, err = c.getContents(ctx, token, repo, files[0], ref); err != nil {
+			return "", err
+		}
+	}
+
+	var file contentEntry
+	if err := json.Unmarshal(body, &file); err != nil {
+		return "", fmt.Errorf("github: decode file %s: %w", path, err)
+	}
+	if file.Type != "file" {
+		return "", fmt.Errorf("github: %s is a %s, not a file", path, file.Type)
+	}
+	if file.Encoding != "base64" {
+		// The API leaves content out of files over 1 MB.
+		return "", fmt.Errorf("github: %s is too large to submit", path)
+	}
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return "", fmt.Errorf("github: decode %s: %w", path, err)
+	}
+	return string(content), nil
+}
+
+func (c *restClient) getContents(ctx context.Context, token, repo, path, ref string) ([]byte, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", c.baseURL, repo, strings.Join(segments, "/"), url.QueryEscape(ref))
+	return c.do(ctx, http.MethodGet, endpoint, token, nil)
+}
+
+func (c *restClient) SetStatus(ctx context.Context, token, repo, sha string, status Status) error {
+	payload, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("github: encode status: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", c.baseURL, repo, url.PathEscape(sha))
+	_, err = c.do(ctx, http.MethodPost, endpoint, token, payload)
+	return err
+}
+
+func (c *restClient) do(ctx context.Context, method, endpoint, token string, payload []byte) ([]byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = strings.NewReader(string(payload))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("github: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: %s %s: %w", method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("github: read response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("github: %s %s: %s", method, req.URL.Path, resp.Status)
+	}
+	return body, nil
+}
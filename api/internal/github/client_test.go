@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ContentsAndStatus(t *testing.T) {
+	var status Status
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.URL.Query().Get("ref"); r.Method == http.MethodGet && got != "abc" {
+			t.Errorf("ref = %q, want abc", got)
+		}
+		switch r.URL.Path {
+		case "/repos/octo/hello/contents/src":
+			json.NewEncoder(w).Encode([]contentEntry{
+				{Type: "file", Path: "src/main.py"},
+				{Type: "dir", Path: "src/lib"},
+			})
+		case "/repos/octo/hello/contents/src/main.py":
+			json.NewEncoder(w).Encode(contentEntry{
+				Type: "file", Path: "src/main.py", Encoding: "base64",
+				Content: base64.StdEncoding.EncodeToString([]byte("print(42)")),
+			})
+		case "/repos/octo/hello/statuses/abc":
+			json.NewDecoder(r.Body).Decode(&status)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL + "/")
+	ctx := context.Background()
+	for _, path := range []string{"src", "src/main.py"} {
+		got, err := c.Contents(ctx, "tok", "octo/hello", path, "abc")
+		if err != nil || got != "print(42)" {
+			t.Errorf("Contents(%q) = %q, %v; want the file", path, got, err)
+		}
+	}
+	if _, err := c.Contents(ctx, "tok", "octo/hello", "missing.py", "abc"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing file: got %v, want ErrNotFound", err)
+	}
+
+	if err := c.SetStatus(ctx, "tok", "octo/hello", "abc", Status{State: StateSuccess, Context: "sentinel"}); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+	if status.State != StateSuccess || status.Context != "sentinel" {
+		t.Errorf("posted status = %+v", status)
+	}
+}
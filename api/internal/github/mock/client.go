@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/github"
+)
+
+// Ensure MockClient implements github.Client.
+var _ github.Client = (*MockClient)(nil)
+
+// PostedStatus is a status recorded by MockClient.SetStatus.
+type PostedStatus struct {
+	Repo   string
+	SHA    string
+	Status github.Status
+}
+
+// MockClient is a mock GitHub client for testing. Files maps "path@ref" to
+// content; Contents returns github.ErrNotFound for anything else.
+type MockClient struct {
+	mu       sync.Mutex
+	Files    map[string]string
+	Statuses []PostedStatus
+
+	ContentsFn  func(ctx context.Context, token, repo, path, ref string) (string, error)
+	SetStatusFn func(ctx context.Context, token, repo, sha string, status github.Status) error
+}
+
+// NewMockClient creates a new mock client.
+func NewMockClient() *MockClient {
+	return &MockClient{Files: make(map[string]string)}
+}
+
+func (m *MockClient) Contents(ctx context.Context, token, repo, path, ref string) (string, error) {
+	if m.ContentsFn != nil {
+		return m.ContentsFn(ctx, token, repo, path, ref)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content, ok := m.Files[path+"@"+ref]
+	if !ok {
+		return "", github.ErrNotFound
+	}
+	return content, nil
+}
+
+func (m *MockClient) SetStatus(ctx context.Context, token, repo, sha string, status github.Status) error {
+	if m.SetStatusFn != nil {
+		return m.SetStatusFn(ctx, token, repo, sha, status)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Statuses = append(m.Statuses, PostedStatus{Repo: repo, SHA: sha, Status: status})
+	return nil
+}
+
+// Posted returns a copy of the statuses set so far.
+func (m *MockClient) Posted() []PostedStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]PostedStatus(nil), m.Statuses...)
+}
@@ -0,0 +1,160 @@
+package language
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// MaxTimeLimitMs and MaxMemoryLimitKB cap the limits a submission may ask
+// for. A language's max_time_limit_ms and max_memory_limit_kb can lower
+// them.
+const (
+	MaxTimeLimitMs   = 30000
+	MaxMemoryLimitKB = 524288 // 512 MB
+)
+
+// entry is the subset of a language registry entry the API cares about; the
+// worker-only fields (commands, nsjail profile, compile limits) are ignored.
+type entry struct {
+	Name             string   `mapstructure:"name"`
+	Version          string   `mapstructure:"version"`
+	Compiler         string   `mapstructure:"compiler"`
+	AllowedFlags     []string `mapstructure:"allowed_flags"`
+	AllowedEnv       []string `mapstructure:"allowed_env"`
+	SourceFile       string   `mapstructure:"source_file"`
+	MaxTimeLimitMs   int      `mapstructure:"max_time_limit_ms"`
+	MaxMemoryLimitKB int      `mapstructure:"max_memory_limit_kb"`
+}
+
+// Registry is the set of languages accepted for submission, shared with the
+// worker through sandbox/languages.yaml.
+type Registry struct {
+	languages []domain.LanguageInfo
+	supported map[domain.Language]bool
+	env       map[domain.Language][]string
+	// extensions maps source file extensions to their language, or to ""
+	// when several languages share one.
+	extensions map[string]domain.Language
+}
+
+// NewRegistry builds a registry from language descriptions, in order.
+// Languages without versions run their Version, and zero limits are the
+// API's own.
+func NewRegistry(languages []domain.LanguageInfo) (*Registry, error) {
+	if len(languages) == 0 {
+		return nil, fmt.Errorf("language registry is empty")
+	}
+	reg := &Registry{supported: make(map[domain.Language]bool, len(languages))}
+	for _, l := range languages {
+		if l.Name == "" {
+			return nil, fmt.Errorf("language registry: entry without a name")
+		}
+		if reg.supported[l.Name] {
+			return nil, fmt.Errorf("language %q defined twice", l.Name)
+		}
+		if l.MaxTimeMs < 0 || l.MaxTimeMs > MaxTimeLimitMs {
+			return nil, fmt.Errorf("language %q: max_time_limit_ms must be at most %d", l.Name, MaxTimeLimitMs)
+		}
+		if l.MaxMemoryKB < 0 || l.MaxMemoryKB > MaxMemoryLimitKB {
+			return nil, fmt.Errorf("language %q: max_memory_limit_kb must be at most %d", l.Name, MaxMemoryLimitKB)
+		}
+		if l.MaxTimeMs == 0 {
+			l.MaxTimeMs = MaxTimeLimitMs
+		}
+		if l.MaxMemoryKB == 0 {
+			l.MaxMemoryKB = MaxMemoryLimitKB
+		}
+		if len(l.Versions) == 0 && l.Version != "" {
+			l.Versions = []string{l.Version}
+		}
+		reg.supported[l.Name] = true
+		reg.languages = append(reg.languages, l)
+	}
+	return reg, nil
+}
+
+// Load reads the registry from a YAML file.
+func Load(path string) (*Registry, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read language registry: %w", err)
+	}
+	var entries []entry
+	if err := v.UnmarshalKey("languages", &entries); err != nil {
+		return nil, fmt.Errorf("decode language registry: %w", err)
+	}
+
+	languages := make([]domain.LanguageInfo, len(entries))
+	env := make(map[domain.Language][]string)
+	extensions := make(map[string]domain.Language)
+	for i, e := range entries {
+		languages[i] = domain.LanguageInfo{
+			Name:                domain.Language(e.Name),
+			Version:             e.Version,
+			Compiler:            e.Compiler,
+			SupportsCompileArgs: len(e.AllowedFlags) > 0,
+			MaxTimeMs:           e.MaxTimeLimitMs,
+			MaxMemoryKB:         e.MaxMemoryLimitKB,
+		}
+		if len(e.AllowedEnv) > 0 {
+			env[languages[i].Name] = e.AllowedEnv
+		}
+		if ext := strings.ToLower(filepath.Ext(e.SourceFile)); ext != "" {
+			if _, shared := extensions[ext]; shared {
+				extensions[ext] = ""
+			} else {
+				extensions[ext] = languages[i].Name
+			}
+		}
+	}
+	reg, err := NewRegistry(languages)
+	if err != nil {
+		return nil, err
+	}
+	reg.env = env
+	reg.extensions = extensions
+	return reg, nil
+}
+
+// IsSupported reports whether lang can be submitted.
+func (r *Registry) IsSupported(lang domain.Language) bool {
+	return r.supported[lang]
+}
+
+// EnvAllowed reports whether submissions in lang may set the environment
+// variable name.
+func (r *Registry) EnvAllowed(lang domain.Language, name string) bool {
+	return slices.Contains(r.env[lang], name)
+}
+
+// Detect returns the language whose source file has the extension of
+// filename, such as python for "solution.py". It reports false when no
+// language uses the extension, or more than one does.
+func (r *Registry) Detect(filename string) (domain.Language, bool) {
+	lang := r.extensions[strings.ToLower(filepath.Ext(filename))]
+	return lang, lang != ""
+}
+
+// Limits returns the largest time and memory limits a submission in lang
+// may ask for. Languages outside the registry, such as registered
+// runtimes, have the API's own.
+func (r *Registry) Limits(lang domain.Language) (timeMs, memoryKB int) {
+	for _, l := range r.languages {
+		if l.Name == lang {
+			return l.MaxTimeMs, l.MaxMemoryKB
+		}
+	}
+	return MaxTimeLimitMs, MaxMemoryLimitKB
+}
+
+// List returns every registered language in file order.
+func (r *Registry) List() []domain.LanguageInfo {
+	return r.languages
+}
@@ -0,0 +1,128 @@
+package lti
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ScopeScore is the AGS scope for posting scores to a line item.
+const ScopeScore = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+
+// clockSkew is the leeway allowed on a launch token's expiry.
+const clockSkew = time.Minute
+
+// ErrInvalidLaunch is returned for a launch token whose claims are not an
+// LTI 1.3 resource link launch meant for this tool.
+var ErrInvalidLaunch = errors.New("lti: invalid launch")
+
+// audience is the "aud" claim, which may be a string or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// LaunchClaims are the claims of a resource link launch's id_token.
+type LaunchClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	Nonce           string   `json:"nonce"`
+	Name            string   `json:"name"`
+	Email           string   `json:"email"`
+
+	MessageType  string `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version      string `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID string `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	ResourceLink struct {
+		ID string `json:"id"`
+	} `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link"`
+	Context struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"https://purl.imsglobal.org/spec/lti/claim/context"`
+	Custom   map[string]any `json:"https://purl.imsglobal.org/spec/lti/claim/custom"`
+	Endpoint struct {
+		Scope     []string `json:"scope"`
+		LineItems string   `json:"lineitems"`
+		LineItem  string   `json:"lineitem"`
+	} `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"`
+}
+
+// ParseLaunch decodes the payload of a verified launch token.
+func ParseLaunch(payload []byte) (*LaunchClaims, error) {
+	var c LaunchClaims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLaunch, err)
+	}
+	return &c, nil
+}
+
+// Validate checks the claims against the platform registration (issuer,
+// client ID and, when set, deployment ID) and the nonce sent with the login.
+func (c *LaunchClaims) Validate(issuer, clientID, deploymentID, nonce string, now time.Time) error {
+	switch {
+	case c.Issuer != issuer:
+		return fmt.Errorf("%w: issuer %q", ErrInvalidLaunch, c.Issuer)
+	case !c.Audience.contains(clientID):
+		return fmt.Errorf("%w: token is not for client %q", ErrInvalidLaunch, clientID)
+	case len(c.Audience) > 1 && c.AuthorizedParty != clientID:
+		return fmt.Errorf("%w: authorized party %q", ErrInvalidLaunch, c.AuthorizedParty)
+	case now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)):
+		return fmt.Errorf("%w: token expired", ErrInvalidLaunch)
+	case nonce == "" || c.Nonce != nonce:
+		return fmt.Errorf("%w: nonce mismatch", ErrInvalidLaunch)
+	case deploymentID != "" && c.DeploymentID != deploymentID:
+		return fmt.Errorf("%w: deployment %q", ErrInvalidLaunch, c.DeploymentID)
+	case c.MessageType != "LtiResourceLinkRequest":
+		return fmt.Errorf("%w: message type %q", ErrInvalidLaunch, c.MessageType)
+	case c.Version != "1.3.0":
+		return fmt.Errorf("%w: version %q", ErrInvalidLaunch, c.Version)
+	case c.Subject == "":
+		return fmt.Errorf("%w: anonymous launch", ErrInvalidLaunch)
+	}
+	return nil
+}
+
+// CustomString returns the custom parameter key, if it is a string.
+func (c *LaunchClaims) CustomString(key string) string {
+	s, _ := c.Custom[key].(string)
+	return s
+}
+
+// CanPostScores reports whether the launch carries a line item the tool may
+// post scores to.
+func (c *LaunchClaims) CanPostScores() bool {
+	if c.Endpoint.LineItem == "" {
+		return false
+	}
+	for _, s := range c.Endpoint.Scope {
+		if s == ScopeScore {
+			return true
+		}
+	}
+	return false
+}
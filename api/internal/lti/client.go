@@ -0,0 +1,233 @@
+// Package lti implements the tool side of LTI 1.3: verifying launch tokens
+// signed by a learning platform and posting scores back through the
+// Assignment and Grade Services (AGS).
+package lti
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// requestTimeout bounds each call to a platform.
+	requestTimeout = 10 * time.Second
+
+	// maxResponseSize caps a response body.
+	maxResponseSize = 1 << 20
+
+	// keyCacheTTL is how long a platform's key set is reused before it is
+	// fetched again. An unknown key ID refetches immediately, so a platform
+	// rotating keys is picked up on its first launch with the new key.
+	keyCacheTTL = 15 * time.Minute
+
+	// assertionLifetime is the validity of the client assertion sent to a
+	// platform's token endpoint.
+	assertionLifetime = 5 * time.Minute
+
+	// scoreMediaType is the AGS score content type.
+	scoreMediaType = "application/vnd.ims.lis.v1.score+json"
+)
+
+// Score is an AGS score for one user on a line item.
+type Score struct {
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	Comment          string  `json:"comment,omitempty"`
+	Timestamp        string  `json:"timestamp"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+}
+
+// Client is the part of a platform's API the tool uses.
+type Client interface {
+	// Keys returns the platform's signing keys from jwksURL. When refresh is
+	// set, a cached set is bypassed.
+	Keys(ctx context.Context, jwksURL string, refresh bool) (KeySet, error)
+
+	// PostScore posts score to lineItem, authenticating at tokenURL as the
+	// tool registered under clientID.
+	PostScore(ctx context.Context, tokenURL, clientID, lineItem string, score Score) error
+}
+
+type cachedKeys struct {
+	keys    KeySet
+	fetched time.Time
+}
+
+type cachedToken struct {
+	token   string
+	expires time.Time
+}
+
+type httpClient struct {
+	key  *rsa.PrivateKey
+	http *http.Client
+
+	mu     sync.Mutex
+	keys   map[string]cachedKeys
+	tokens map[string]cachedToken
+}
+
+// NewClient creates a client that signs its token requests with key, the
+// tool's private key.
+func NewClient(key *rsa.PrivateKey) Client {
+	return &httpClient{
+		key:    key,
+		http:   &http.Client{Timeout: requestTimeout},
+		keys:   make(map[string]cachedKeys),
+		tokens: make(map[string]cachedToken),
+	}
+}
+
+func (c *httpClient) Keys(ctx context.Context, jwksURL string, refresh bool) (KeySet, error) {
+	c.mu.Lock()
+	cached, ok := c.keys[jwksURL]
+	c.mu.Unlock()
+	if ok && !refresh && time.Since(cached.fetched) < keyCacheTTL {
+		return cached.keys, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("lti: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	var set JWKS
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("lti: decode key set: %w", err)
+	}
+	keys := set.KeySet()
+
+	c.mu.Lock()
+	c.keys[jwksURL] = cachedKeys{keys: keys, fetched: time.Now()}
+	c.mu.Unlock()
+	return keys, nil
+}
+
+func (c *httpClient) PostScore(ctx context.Context, tokenURL, clientID, lineItem string, score Score) error {
+	token, err := c.token(ctx, tokenURL, clientID)
+	if err != nil {
+		return err
+	}
+	endpoint, err := scoresURL(lineItem)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(score)
+	if err != nil {
+		return fmt.Errorf("lti: encode score: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(string(payload)))
+	if err != nil {
+		return fmt.Errorf("lti: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", scoreMediaType)
+	_, err = c.do(req)
+	return err
+}
+
+// token returns an AGS access token for clientID, reusing one that has not
+// expired.
+func (c *httpClient) token(ctx context.Context, tokenURL, clientID string) (string, error) {
+	cacheKey := tokenURL + " " + clientID
+	c.mu.Lock()
+	cached, ok := c.tokens[cacheKey]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", fmt.Errorf("lti: generate assertion id: %w", err)
+	}
+	now := time.Now()
+	assertion, err := Sign(map[string]any{
+		"iss": clientID,
+		"sub": clientID,
+		"aud": tokenURL,
+		"iat": now.Unix(),
+		"exp": now.Add(assertionLifetime).Unix(),
+		"jti": hex.EncodeToString(jti),
+	}, c.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+		"scope":                 {ScopeScore},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("lti: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	body, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.AccessToken == "" {
+		return "", fmt.Errorf("lti: token endpoint returned no access token")
+	}
+
+	// Renew a minute early so a token never expires in flight.
+	if lifetime := time.Duration(resp.ExpiresIn)*time.Second - time.Minute; lifetime > 0 {
+		c.mu.Lock()
+		c.tokens[cacheKey] = cachedToken{token: resp.AccessToken, expires: now.Add(lifetime)}
+		c.mu.Unlock()
+	}
+	return resp.AccessToken, nil
+}
+
+// scoresURL returns the scores endpoint of a line item: its path with
+// "/scores" appended, keeping any query.
+func scoresURL(lineItem string) (string, error) {
+	u, err := url.Parse(lineItem)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("lti: invalid line item %q", lineItem)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/scores"
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+func (c *httpClient) do(req *http.Request) ([]byte, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("lti: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("lti: read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("lti: %s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return body, nil
+}
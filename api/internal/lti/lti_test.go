@@ -0,0 +1,180 @@
+package lti
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+func TestSignVerify(t *testing.T) {
+	key, other := newKey(t), newKey(t)
+	token, err := Sign(map[string]any{"sub": "alice"}, key)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// The key set round-trips through its JWKS form.
+	set := JWKS{Keys: []JWK{PublicJWK(&key.PublicKey), PublicJWK(&other.PublicKey)}}
+	raw, _ := json.Marshal(set)
+	var decoded JWKS
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("decode JWKS: %v", err)
+	}
+	payload, err := Verify(token, decoded.KeySet())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !strings.Contains(string(payload), `"alice"`) {
+		t.Errorf("payload = %s", payload)
+	}
+
+	parts := strings.Split(token, ".")
+	forged, _ := Sign(map[string]any{"sub": "mallory"}, key)
+	tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+	for name, tc := range map[string]struct {
+		token string
+		keys  KeySet
+	}{
+		"tampered payload": {tampered, decoded.KeySet()},
+		"unknown key":      {token, KeySet{KeyID(&other.PublicKey): &other.PublicKey}},
+		"wrong key":        {token, KeySet{KeyID(&key.PublicKey): &other.PublicKey}},
+		"not a JWT":        {"abc.def", decoded.KeySet()},
+	} {
+		if _, err := Verify(tc.token, tc.keys); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: Verify err = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestLaunchClaims_Validate(t *testing.T) {
+	now := time.Now()
+	valid := func() map[string]any {
+		return map[string]any{
+			"iss":   "https://lms.example.edu",
+			"sub":   "u-1",
+			"aud":   []string{"tool-1", "other"},
+			"azp":   "tool-1",
+			"exp":   now.Add(time.Minute).Unix(),
+			"nonce": "n-1",
+			"https://purl.imsglobal.org/spec/lti/claim/message_type":  "LtiResourceLinkRequest",
+			"https://purl.imsglobal.org/spec/lti/claim/version":       "1.3.0",
+			"https://purl.imsglobal.org/spec/lti/claim/deployment_id": "d-1",
+			"https://purl.imsglobal.org/spec/lti/claim/custom":        map[string]any{"problem_id": "two-sum", "weight": 2},
+			"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint": map[string]any{
+				"scope":    []string{ScopeScore},
+				"lineitem": "https://lms.example.edu/api/lti/courses/1/line_items/7",
+			},
+		}
+	}
+	parse := func(claims map[string]any) *LaunchClaims {
+		raw, _ := json.Marshal(claims)
+		c, err := ParseLaunch(raw)
+		if err != nil {
+			t.Fatalf("ParseLaunch: %v", err)
+		}
+		return c
+	}
+
+	c := parse(valid())
+	if err := c.Validate("https://lms.example.edu", "tool-1", "d-1", "n-1", now); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if c.CustomString("problem_id") != "two-sum" || c.CustomString("weight") != "" {
+		t.Errorf("custom = %v", c.Custom)
+	}
+	if !c.CanPostScores() {
+		t.Error("CanPostScores = false with a line item and the score scope")
+	}
+
+	for name, mutate := range map[string]func(map[string]any){
+		"issuer":     func(m map[string]any) { m["iss"] = "https://evil.example" },
+		"audience":   func(m map[string]any) { m["aud"] = "tool-2" },
+		"azp":        func(m map[string]any) { m["azp"] = "other" },
+		"expired":    func(m map[string]any) { m["exp"] = now.Add(-time.Hour).Unix() },
+		"nonce":      func(m map[string]any) { m["nonce"] = "n-2" },
+		"deployment": func(m map[string]any) { m["https://purl.imsglobal.org/spec/lti/claim/deployment_id"] = "d-2" },
+		"message type": func(m map[string]any) {
+			m["https://purl.imsglobal.org/spec/lti/claim/message_type"] = "LtiDeepLinkingRequest"
+		},
+		"anonymous": func(m map[string]any) { delete(m, "sub") },
+	} {
+		claims := valid()
+		mutate(claims)
+		if err := parse(claims).Validate("https://lms.example.edu", "tool-1", "d-1", "n-1", now); !errors.Is(err, ErrInvalidLaunch) {
+			t.Errorf("%s: Validate err = %v, want ErrInvalidLaunch", name, err)
+		}
+	}
+}
+
+func TestClient_PostScore(t *testing.T) {
+	key := newKey(t)
+	var tokenRequests int
+	var posted Score
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			r.ParseForm()
+			if r.Form.Get("scope") != ScopeScore {
+				t.Errorf("scope = %q", r.Form.Get("scope"))
+			}
+			payload, err := Verify(r.Form.Get("client_assertion"), KeySet{KeyID(&key.PublicKey): &key.PublicKey})
+			if err != nil {
+				t.Errorf("client assertion: %v", err)
+			}
+			var claims map[string]any
+			json.Unmarshal(payload, &claims)
+			if claims["iss"] != "tool-1" || claims["aud"] != "http://"+r.Host+"/token" {
+				t.Errorf("assertion claims = %v", claims)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "at-1", "expires_in": 3600})
+		case "/line_items/7/scores":
+			if got := r.Header.Get("Authorization"); got != "Bearer at-1" {
+				t.Errorf("Authorization = %q", got)
+			}
+			if got := r.Header.Get("Content-Type"); got != scoreMediaType {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := r.URL.Query().Get("type_id"); got != "3" {
+				t.Errorf("line item query lost: %q", r.URL.RawQuery)
+			}
+			json.NewDecoder(r.Body).Decode(&posted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(key)
+	score := Score{UserID: "u-1", ScoreGiven: 3, ScoreMaximum: 4, ActivityProgress: "Completed", GradingProgress: "FullyGraded"}
+	for i := 0; i < 2; i++ {
+		if err := c.PostScore(context.Background(), srv.URL+"/token", "tool-1", srv.URL+"/line_items/7?type_id=3", score); err != nil {
+			t.Fatalf("PostScore: %v", err)
+		}
+	}
+	if posted.UserID != "u-1" || posted.ScoreGiven != 3 {
+		t.Errorf("posted = %+v", posted)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token requests = %d, want the token reused", tokenRequests)
+	}
+	if err := c.PostScore(context.Background(), srv.URL+"/token", "tool-1", srv.URL+"/line_items/8", score); err == nil {
+		t.Error("PostScore to a missing line item succeeded")
+	}
+}
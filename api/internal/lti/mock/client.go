@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+)
+
+// Ensure MockClient implements lti.Client.
+var _ lti.Client = (*MockClient)(nil)
+
+// PostedScore is a score recorded by MockClient.PostScore.
+type PostedScore struct {
+	TokenURL string
+	ClientID string
+	LineItem string
+	Score    lti.Score
+}
+
+// MockClient is a mock LTI platform client for testing. KeySets maps a JWKS
+// URL to the keys it serves; Keys returns an empty set for anything else.
+type MockClient struct {
+	mu      sync.Mutex
+	KeySets map[string]lti.KeySet
+	Scores  []PostedScore
+
+	KeysFn      func(ctx context.Context, jwksURL string, refresh bool) (lti.KeySet, error)
+	PostScoreFn func(ctx context.Context, tokenURL, clientID, lineItem string, score lti.Score) error
+}
+
+// NewMockClient creates a new mock client.
+func NewMockClient() *MockClient {
+	return &MockClient{KeySets: make(map[string]lti.KeySet)}
+}
+
+func (m *MockClient) Keys(ctx context.Context, jwksURL string, refresh bool) (lti.KeySet, error) {
+	if m.KeysFn != nil {
+		return m.KeysFn(ctx, jwksURL, refresh)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if keys, ok := m.KeySets[jwksURL]; ok {
+		return keys, nil
+	}
+	return lti.KeySet{}, nil
+}
+
+func (m *MockClient) PostScore(ctx context.Context, tokenURL, clientID, lineItem string, score lti.Score) error {
+	if m.PostScoreFn != nil {
+		return m.PostScoreFn(ctx, tokenURL, clientID, lineItem, score)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Scores = append(m.Scores, PostedScore{TokenURL: tokenURL, ClientID: clientID, LineItem: lineItem, Score: score})
+	return nil
+}
+
+// Posted returns a copy of the scores posted so far.
+func (m *MockClient) Posted() []PostedScore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]PostedScore(nil), m.Scores...)
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ConsistencyChecks counts Postgres/broker consistency checks by outcome.
+	ConsistencyChecks = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_consistency_checks_total",
+			Help: "Total number of consistency checks between Postgres and the broker",
+		},
+		[]string{"outcome"},
+	)
+
+	// LostJobs is how many QUEUED jobs the last conclusive check found with
+	// no broker message.
+	LostJobs = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sentinel_consistency_lost_jobs",
+			Help: "QUEUED jobs without a broker message found by the last consistency check",
+		},
+	)
+
+	// RepublishedJobs counts lost jobs the consistency checker published again.
+	RepublishedJobs = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sentinel_consistency_republished_jobs_total",
+			Help: "Total number of lost jobs republished by the consistency checker",
+		},
+	)
+
+	// ExportedFacts counts job facts written to a warehouse sink.
+	ExportedFacts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_export_facts_total",
+			Help: "Total number of job facts exported to the data warehouse",
+		},
+		[]string{"sink"},
+	)
+
+	// ExportFailures counts export batches a warehouse sink failed to write.
+	ExportFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_export_failures_total",
+			Help: "Total number of export batches the data warehouse failed to take",
+		},
+		[]string{"sink"},
+	)
+
+	// ResultWebhookAttempts counts result webhook delivery attempts by
+	// outcome: delivered, retry (failed, tried again later) or failed
+	// (given up until redriven).
+	ResultWebhookAttempts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_result_webhook_attempts_total",
+			Help: "Total number of result webhook delivery attempts",
+		},
+		[]string{"outcome"},
+	)
+
+	// ResultWebhookCircuitOpens counts result webhook circuits opened after
+	// too many consecutive failures.
+	ResultWebhookCircuitOpens = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sentinel_result_webhook_circuit_opens_total",
+			Help: "Total number of times a result webhook's circuit was opened",
+		},
+	)
+
+	// UnconfirmedOnClose counts publishes still awaiting a broker
+	// confirmation when the publisher gave up draining on Close.
+	UnconfirmedOnClose = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sentinel_publisher_unconfirmed_on_close_total",
+			Help: "Total number of publishes left unconfirmed when the publisher closed",
+		},
+	)
+
+	// RateLimitFallbacks counts requests the rate limiter decided without
+	// Redis, by the API_RATE_LIMIT_FAIL_MODE in force.
+	RateLimitFallbacks = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_rate_limit_fallbacks_total",
+			Help: "Total number of requests rate limited without Redis, which could not be reached",
+		},
+		[]string{"mode"},
+	)
+
+	// BrokerEndpointUp reports whether each configured RabbitMQ endpoint,
+	// by host, was reachable when last tried.
+	BrokerEndpointUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sentinel_rabbitmq_endpoint_up",
+			Help: "Whether a RabbitMQ endpoint was reachable when last dialed or in use (1) or not (0)",
+		},
+		[]string{"endpoint"},
+	)
+)
+
+// queueScrapeTimeout bounds the broker round trip of one queue depth scrape.
+const queueScrapeTimeout = 2 * time.Second
+
+// RegisterQueueDepth exports the execution queue's ready messages, as
+// counted by readyMessages on each scrape. A failed count reads as NaN.
+func RegisterQueueDepth(readyMessages func(ctx context.Context) (int, error)) {
+	promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "sentinel_queue_ready_messages",
+			Help: "Number of messages waiting in the execution queue, not counting those delivered to workers",
+		},
+		func() float64 {
+			ctx, cancel := context.WithTimeout(context.Background(), queueScrapeTimeout)
+			defer cancel()
+			n, err := readyMessages(ctx)
+			if err != nil {
+				return math.NaN()
+			}
+			return float64(n)
+		},
+	)
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// Model is the dashboard's state. Rates are taken over the samples of the
+// last window, so one slow refresh does not make them jump.
+type Model struct {
+	window  time.Duration
+	samples []*Sample
+	latest  *Snapshot
+}
+
+// NewModel creates a model whose rates span window.
+func NewModel(window time.Duration) *Model {
+	return &Model{window: window}
+}
+
+// Update records a snapshot.
+func (m *Model) Update(snap *Snapshot) {
+	m.latest = snap
+	m.samples = append(m.samples, snap.Sample)
+	// Keep the newest sample at least window old as the base of the rates.
+	cutoff := snap.Sample.At.Add(-m.window)
+	drop := 0
+	for drop+1 < len(m.samples) && !m.samples[drop+1].At.After(cutoff) {
+		drop++
+	}
+	m.samples = m.samples[drop:]
+}
+
+// LanguageRate is one language's throughput, per minute.
+type LanguageRate struct {
+	Language string
+	Jobs     float64
+	Failures float64
+	Total    float64
+}
+
+// Rates returns each language's throughput over the window, busiest first,
+// and the span it was measured over. The span is zero until there are two
+// samples.
+func (m *Model) Rates() ([]LanguageRate, time.Duration) {
+	if len(m.samples) == 0 {
+		return nil, 0
+	}
+	first, last := m.samples[0], m.samples[len(m.samples)-1]
+	span := last.At.Sub(first.At)
+	var rates []LanguageRate
+	for lang, total := range last.Executions {
+		rate := LanguageRate{Language: lang, Total: total}
+		if span > 0 {
+			perMinute := time.Minute.Seconds() / span.Seconds()
+			rate.Jobs = increase(first.Executions[lang], total) * perMinute
+			rate.Failures = increase(first.Failures[lang], last.Failures[lang]) * perMinute
+		}
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Jobs != rates[j].Jobs {
+			return rates[i].Jobs > rates[j].Jobs
+		}
+		return rates[i].Language < rates[j].Language
+	})
+	return rates, span
+}
+
+// increase is how much a counter grew, treating a drop as a restart.
+func increase(from, to float64) float64 {
+	if to < from {
+		return to
+	}
+	return to - from
+}
+
+// View renders the dashboard.
+func (m *Model) View() string {
+	var b strings.Builder
+	if m.latest == nil {
+		b.WriteString("Sentinel: waiting for the first sample...\n")
+		return b.String()
+	}
+	last := m.latest.Sample
+	rates, span := m.Rates()
+
+	fmt.Fprintf(&b, "Sentinel  %s", last.At.Format("2006-01-02 15:04:05"))
+	if span > 0 {
+		fmt.Fprintf(&b, "  (rates over %s)", span.Round(time.Second))
+	}
+	b.WriteString("\n\n")
+
+	queue := "unknown"
+	if !math.IsNaN(last.QueueReady) {
+		queue = fmt.Sprintf("%.0f ready", last.QueueReady)
+	}
+	fmt.Fprintf(&b, "Queue          %s\n", queue)
+	fmt.Fprintf(&b, "Workers        %.0f busy on %d worker(s)\n", last.WorkersActive, last.Workers)
+	deadLettered := fmt.Sprintf("%.0f total", last.DeadLettered)
+	if span > 0 {
+		first := m.samples[0]
+		deadLettered = fmt.Sprintf("%.1f/min, %s", increase(first.DeadLettered, last.DeadLettered)*time.Minute.Seconds()/span.Seconds(), deadLettered)
+	}
+	fmt.Fprintf(&b, "Dead-lettered  %s\n\n", deadLettered)
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "LANGUAGE\tJOBS/MIN\tFAILED/MIN\tTOTAL\t")
+	for _, r := range rates {
+		fmt.Fprintf(tw, "%s\t%.1f\t%.1f\t%.0f\t\n", r.Language, r.Jobs, r.Failures, r.Total)
+	}
+	if len(rates) == 0 {
+		fmt.Fprintln(tw, "no executions yet\t\t\t\t")
+	}
+	tw.Flush()
+
+	b.WriteString("\nRECENT FAILURES\n")
+	if m.latest.Failures == nil {
+		b.WriteString("(not available; needs the admin token)\n")
+	} else {
+		writeFailures(&b, m.latest.Failures)
+	}
+
+	for _, err := range m.latest.Errors {
+		fmt.Fprintf(&b, "\n! %s", err)
+	}
+	if len(m.latest.Errors) > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func writeFailures(b *strings.Builder, jobs []*domain.JobSummary) {
+	if len(jobs) == 0 {
+		b.WriteString("none\n")
+		return
+	}
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AT\tJOB\tTENANT\tLANGUAGE\tPROBLEM")
+	for _, job := range jobs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			job.UpdatedAt.Local().Format("15:04:05"), job.JobID, job.TenantID, job.Language, job.ProblemID)
+	}
+	tw.Flush()
+}
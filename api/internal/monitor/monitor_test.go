@@ -0,0 +1,125 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+func workerMetrics(active int, python, pythonErrors, cpp, deadLettered float64) string {
+	return fmt.Sprintf(`# TYPE sentinel_workers_active gauge
+sentinel_workers_active %d
+# TYPE sentinel_executions_total counter
+sentinel_executions_total{language="python",status="SUCCESS"} %g
+sentinel_executions_total{language="python",status="error"} %g
+sentinel_executions_total{language="cpp",status="WRONG_ANSWER"} %g
+# TYPE sentinel_dead_lettered_jobs_total counter
+sentinel_dead_lettered_jobs_total{failure_class="sandbox_failure"} %g
+`, active, python, pythonErrors, cpp, deadLettered)
+}
+
+func TestModel_RatesOverWindow(t *testing.T) {
+	m := NewModel(time.Minute)
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	sample := func(at time.Duration, python, pythonErrors, cpp float64) *Snapshot {
+		s := NewSample(start.Add(at))
+		if err := s.AddWorker(strings.NewReader(workerMetrics(2, python, pythonErrors, cpp, 0))); err != nil {
+			t.Fatalf("add worker: %v", err)
+		}
+		return &Snapshot{Sample: s}
+	}
+
+	m.Update(sample(0, 100, 0, 50))
+	if rates, span := m.Rates(); span != 0 || len(rates) != 2 || rates[0].Jobs != 0 {
+		t.Errorf("one sample: got %+v over %s, want totals without rates", rates, span)
+	}
+
+	m.Update(sample(30*time.Second, 130, 5, 50))
+	m.Update(sample(60*time.Second, 160, 10, 60))
+	rates, span := m.Rates()
+	if span != time.Minute {
+		t.Fatalf("span = %s, want 1m", span)
+	}
+	// Python ran 60 (plus 10 failed) in a minute, cpp 10.
+	if rates[0].Language != "python" || rates[0].Jobs != 70 || rates[0].Failures != 10 || rates[0].Total != 170 {
+		t.Errorf("python = %+v", rates[0])
+	}
+	if rates[1].Language != "cpp" || rates[1].Jobs != 10 {
+		t.Errorf("cpp = %+v", rates[1])
+	}
+
+	// Older samples leave the window; a worker restart is not a negative rate.
+	m.Update(sample(90*time.Second, 10, 0, 70))
+	rates, span = m.Rates()
+	if span != time.Minute {
+		t.Errorf("span = %s, want the window", span)
+	}
+	for _, r := range rates {
+		if r.Jobs < 0 {
+			t.Errorf("negative rate after restart: %+v", r)
+		}
+	}
+}
+
+func TestSource_FetchAndView(t *testing.T) {
+	failed := &domain.JobSummary{JobID: uuid.New(), TenantID: "acme", Language: domain.LangPython, Status: domain.StatusInternalError}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics":
+			fmt.Fprint(w, "# TYPE sentinel_queue_ready_messages gauge\nsentinel_queue_ready_messages 7\n")
+		case "/api/v2/admin/jobs":
+			if r.Header.Get("Authorization") != "Bearer s3cret" || r.URL.Query().Get("status") != "INTERNAL_ERROR" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(domain.JobPage{Jobs: []*domain.JobSummary{failed}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, workerMetrics(3, 10, 1, 4, 2))
+	}))
+	defer worker.Close()
+
+	source := NewSource(api.URL, []string{worker.URL, worker.URL, api.URL + "/down"}, "s3cret")
+	snap := source.Fetch(context.Background())
+	s := snap.Sample
+	if s.QueueReady != 7 || s.Workers != 2 || s.WorkersActive != 6 || s.Executions["python"] != 22 || s.DeadLettered != 4 {
+		t.Errorf("sample = %+v", s)
+	}
+	if len(snap.Failures) != 1 || snap.Failures[0].JobID != failed.JobID {
+		t.Errorf("failures = %+v", snap.Failures)
+	}
+	if len(snap.Errors) != 1 || !strings.Contains(snap.Errors[0], "/down") {
+		t.Errorf("errors = %v, want only the unreachable worker", snap.Errors)
+	}
+
+	m := NewModel(time.Minute)
+	m.Update(snap)
+	view := m.View()
+	for _, want := range []string{"7 ready", "6 busy on 2 worker(s)", "python", failed.JobID.String(), "! worker"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	// Without a token failures are not fetched, and without the queue gauge
+	// the depth is unknown.
+	snap = NewSource(worker.URL, nil, "").Fetch(context.Background())
+	if snap.Failures != nil || !math.IsNaN(snap.Sample.QueueReady) {
+		t.Errorf("tokenless snapshot = %+v", snap)
+	}
+}
@@ -0,0 +1,112 @@
+// Package monitor backs sentinelctl top: it samples the metrics of the API
+// and the workers and the admin API, and renders what changed between
+// samples as an operator dashboard.
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// Metrics read from the API and the workers.
+const (
+	metricQueueReady    = "sentinel_queue_ready_messages"
+	metricWorkersActive = "sentinel_workers_active"
+	metricExecutions    = "sentinel_executions_total"
+	metricDeadLettered  = "sentinel_dead_lettered_jobs_total"
+)
+
+// Sample is the state of the deployment at one moment, summed over every
+// metrics endpoint that answered.
+type Sample struct {
+	At time.Time
+
+	// QueueReady is the execution queue's depth, or NaN if the API did not
+	// report it.
+	QueueReady float64
+	// Workers counts the worker metrics endpoints that answered, and
+	// WorkersActive their busy worker goroutines.
+	Workers       int
+	WorkersActive float64
+	// Executions and Failures count finished executions by language since
+	// each worker started; Failures only those that ended in an internal
+	// error rather than a verdict.
+	Executions   map[string]float64
+	Failures     map[string]float64
+	DeadLettered float64
+}
+
+// NewSample returns an empty sample taken at.
+func NewSample(at time.Time) *Sample {
+	return &Sample{
+		At:         at,
+		QueueReady: math.NaN(),
+		Executions: make(map[string]float64),
+		Failures:   make(map[string]float64),
+	}
+}
+
+// AddAPI adds the API's metrics, in the Prometheus text format, to s.
+func (s *Sample) AddAPI(r io.Reader) error {
+	families, err := parseMetrics(r)
+	if err != nil {
+		return err
+	}
+	if mf, ok := families[metricQueueReady]; ok && len(mf.GetMetric()) > 0 {
+		s.QueueReady = mf.GetMetric()[0].GetGauge().GetValue()
+	}
+	return nil
+}
+
+// AddWorker adds one worker's metrics, in the Prometheus text format, to s.
+func (s *Sample) AddWorker(r io.Reader) error {
+	families, err := parseMetrics(r)
+	if err != nil {
+		return err
+	}
+	s.Workers++
+	if mf, ok := families[metricWorkersActive]; ok {
+		for _, m := range mf.GetMetric() {
+			s.WorkersActive += m.GetGauge().GetValue()
+		}
+	}
+	if mf, ok := families[metricExecutions]; ok {
+		for _, m := range mf.GetMetric() {
+			lang, status := label(m, "language"), label(m, "status")
+			s.Executions[lang] += m.GetCounter().GetValue()
+			if status == "error" || status == "INTERNAL_ERROR" {
+				s.Failures[lang] += m.GetCounter().GetValue()
+			}
+		}
+	}
+	if mf, ok := families[metricDeadLettered]; ok {
+		for _, m := range mf.GetMetric() {
+			s.DeadLettered += m.GetCounter().GetValue()
+		}
+	}
+	return nil
+}
+
+func parseMetrics(r io.Reader) (map[string]*dto.MetricFamily, error) {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse metrics: %w", err)
+	}
+	return families, nil
+}
+
+func label(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+const (
+	// requestTimeout bounds each request of a refresh.
+	requestTimeout = 5 * time.Second
+
+	// recentFailures is how many failed jobs a snapshot lists.
+	recentFailures = 10
+)
+
+// Snapshot is what one refresh saw. Endpoints that failed are listed in
+// Errors; the rest of the snapshot is still usable.
+type Snapshot struct {
+	Sample   *Sample
+	Failures []*domain.JobSummary
+	Errors   []string
+}
+
+// Source reads the API's metrics and admin API and the workers' metrics.
+type Source struct {
+	client     *http.Client
+	apiURL     string
+	workerURLs []string
+	token      string
+}
+
+// NewSource creates a source for the API at apiURL and the worker metrics
+// endpoints workerURLs. Without an admin token, failures are not listed.
+func NewSource(apiURL string, workerURLs []string, token string) *Source {
+	return &Source{
+		client:     &http.Client{Timeout: requestTimeout},
+		apiURL:     strings.TrimRight(apiURL, "/"),
+		workerURLs: workerURLs,
+		token:      token,
+	}
+}
+
+// Fetch takes a snapshot.
+func (s *Source) Fetch(ctx context.Context) *Snapshot {
+	snap := &Snapshot{Sample: NewSample(time.Now())}
+	if err := s.get(ctx, s.apiURL+"/metrics", snap.Sample.AddAPI); err != nil {
+		snap.Errors = append(snap.Errors, fmt.Sprintf("api: %v", err))
+	}
+	for _, url := range s.workerURLs {
+		if err := s.get(ctx, url, snap.Sample.AddWorker); err != nil {
+			snap.Errors = append(snap.Errors, fmt.Sprintf("worker %s: %v", url, err))
+		}
+	}
+	if s.token != "" {
+		url := fmt.Sprintf("%s/api/v2/admin/jobs?status=%s&limit=%d", s.apiURL, domain.StatusInternalError, recentFailures)
+		err := s.get(ctx, url, func(r io.Reader) error {
+			var page domain.JobPage
+			if err := json.NewDecoder(r).Decode(&page); err != nil {
+				return fmt.Errorf("decode jobs: %w", err)
+			}
+			snap.Failures = page.Jobs
+			return nil
+		})
+		if err != nil {
+			snap.Errors = append(snap.Errors, fmt.Sprintf("admin jobs: %v", err))
+		}
+	}
+	return snap
+}
+
+// get fetches url and hands a successful response's body to read.
+func (s *Source) get(ctx context.Context, url string, read func(io.Reader) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if s.token != "" && strings.HasPrefix(url, s.apiURL+"/api/") {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return read(resp.Body)
+}
@@ -0,0 +1,118 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+)
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+func TestProvider_Verify(t *testing.T) {
+	key, rotated := newKey(t), newKey(t)
+	var current atomic.Pointer[rsa.PrivateKey]
+	current.Store(key)
+	var jwksFetches atomic.Int32
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": srv.URL, "jwks_uri": srv.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		jwksFetches.Add(1)
+		json.NewEncoder(w).Encode(lti.JWKS{Keys: []lti.JWK{lti.PublicJWK(&current.Load().PublicKey)}})
+	})
+
+	p, err := NewProvider(srv.URL, "sentinel")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	now := time.Now()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss": srv.URL, "sub": "u-1", "aud": []string{"other", "sentinel"},
+			"exp": now.Add(time.Hour).Unix(), "email": "ada@example.com", "org": "acme",
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+	sign := func(c map[string]any, k *rsa.PrivateKey) string {
+		token, err := lti.Sign(c, k)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		return token
+	}
+
+	got, err := p.Verify(context.Background(), sign(claims(nil), key), now)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got.Subject != "u-1" || got.Email != "ada@example.com" || got.String("org") != "acme" || got.String("exp") != "" {
+		t.Errorf("claims = %+v", got)
+	}
+
+	for name, token := range map[string]string{
+		"wrong audience": sign(claims(map[string]any{"aud": "other"}), key),
+		"wrong issuer":   sign(claims(map[string]any{"iss": "https://evil.example"}), key),
+		"expired":        sign(claims(map[string]any{"exp": now.Add(-time.Hour).Unix()}), key),
+		"not yet valid":  sign(claims(map[string]any{"nbf": now.Add(time.Hour).Unix()}), key),
+		"no subject":     sign(claims(map[string]any{"sub": ""}), key),
+		"unknown key":    sign(claims(nil), rotated),
+		"not a JWT":      "abc.def",
+	} {
+		if _, err := p.Verify(context.Background(), token, now); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidToken", name, err)
+		}
+	}
+
+	// The unknown key refetched the set once; later unknown keys wait.
+	fetches := jwksFetches.Load()
+	if fetches != 2 {
+		t.Errorf("key set fetched %d times, want 2", fetches)
+	}
+
+	// After the provider rotates keys, tokens with the new key verify
+	// once the refresh interval has passed.
+	current.Store(rotated)
+	later := now.Add(minRefreshInterval)
+	if _, err := p.Verify(context.Background(), sign(claims(nil), rotated), later); err != nil {
+		t.Errorf("Verify after rotation: %v", err)
+	}
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": "https://other.example", "jwks_uri": "https://other.example/keys"})
+	}))
+	defer srv.Close()
+
+	p, err := NewProvider(srv.URL, "sentinel")
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	_, err = p.Verify(context.Background(), "a.b.c", time.Now())
+	if err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want a discovery error", err)
+	}
+}
@@ -0,0 +1,253 @@
+// Package oidc verifies the tokens an external OpenID Connect provider
+// issues, so an organisation's single sign-on can stand in for Sentinel's
+// own user accounts. The provider's keys are found through OIDC discovery.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+)
+
+const (
+	// requestTimeout bounds each call to the provider.
+	requestTimeout = 10 * time.Second
+
+	// maxResponseSize caps a response body.
+	maxResponseSize = 1 << 20
+
+	// keyCacheTTL is how long the provider's key set is reused before it
+	// is fetched again.
+	keyCacheTTL = 15 * time.Minute
+
+	// minRefreshInterval spaces out the refetches a token with an unknown
+	// key ID triggers, so forged tokens cannot flood the provider.
+	minRefreshInterval = time.Minute
+
+	// clockSkew is the leeway allowed on a token's expiry and not-before
+	// times.
+	clockSkew = time.Minute
+)
+
+// ErrInvalidToken is returned for a token that is not a JWT signed by the
+// provider for the audience, or that has expired.
+var ErrInvalidToken = errors.New("oidc: invalid token")
+
+// audience is the "aud" claim, which may be a string or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims are the claims of a verified token that Sentinel reads. Times are
+// Unix seconds.
+type Claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	Email     string   `json:"email"`
+
+	raw map[string]any
+}
+
+// String returns the claim name, if it is a string.
+func (c *Claims) String(name string) string {
+	s, _ := c.raw[name].(string)
+	return s
+}
+
+// discovery is the part of the provider's discovery document Sentinel uses.
+type discovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// Provider verifies tokens issued by one OIDC provider for one audience.
+// It discovers the provider's keys when it first needs them, and fetches
+// them again after keyCacheTTL or when a token names a key it does not
+// know, as after the provider rotates keys. A Provider is safe for
+// concurrent use.
+type Provider struct {
+	issuer   string
+	audience string
+	http     *http.Client
+
+	mu        sync.Mutex
+	jwksURL   string
+	keys      lti.KeySet
+	fetched   time.Time
+	refreshed time.Time
+}
+
+// NewProvider returns a Provider for the issuer, whose discovery document
+// is served under issuer + "/.well-known/openid-configuration", accepting
+// tokens whose audience includes audience.
+func NewProvider(issuer, audience string) (*Provider, error) {
+	if !strings.HasPrefix(issuer, "https://") && !strings.HasPrefix(issuer, "http://") {
+		return nil, fmt.Errorf("oidc: issuer %q is not an http(s) URL", issuer)
+	}
+	if audience == "" {
+		return nil, fmt.Errorf("oidc: audience is required")
+	}
+	return &Provider{
+		issuer:   issuer,
+		audience: audience,
+		http:     &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Issuer returns the issuer tokens must name.
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// DiscoveryURL returns the URL of the provider's discovery document.
+func (p *Provider) DiscoveryURL() string {
+	return strings.TrimSuffix(p.issuer, "/") + "/.well-known/openid-configuration"
+}
+
+// Verify checks that token is an RS256 JWT signed with one of the
+// provider's keys, issued by it for the audience and valid at now, and
+// returns its claims. Failing to reach the provider is an error other than
+// ErrInvalidToken.
+func (p *Provider) Verify(ctx context.Context, token string, now time.Time) (*Claims, error) {
+	keys, err := p.keySet(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := lti.Verify(token, keys)
+	if errors.Is(err, lti.ErrInvalidToken) && p.mayRefresh(now) {
+		if keys, err = p.keySet(ctx, true); err != nil {
+			return nil, err
+		}
+		payload, err = lti.Verify(token, keys)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := json.Unmarshal(payload, &c.raw); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	switch {
+	case c.Issuer != p.issuer:
+		return nil, fmt.Errorf("%w: issuer %q", ErrInvalidToken, c.Issuer)
+	case !c.Audience.contains(p.audience):
+		return nil, fmt.Errorf("%w: token is not for audience %q", ErrInvalidToken, p.audience)
+	case c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(clockSkew)):
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	case c.NotBefore != 0 && now.Add(clockSkew).Before(time.Unix(c.NotBefore, 0)):
+		return nil, fmt.Errorf("%w: token not yet valid", ErrInvalidToken)
+	case c.Subject == "":
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidToken)
+	}
+	return &c, nil
+}
+
+// mayRefresh reports whether a token that did not verify may refetch the
+// keys, and if so, counts the refetch.
+func (p *Provider) mayRefresh(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now.Sub(p.refreshed) < minRefreshInterval {
+		return false
+	}
+	p.refreshed = now
+	return true
+}
+
+// keySet returns the provider's keys, discovering where they are served
+// first if need be. When refresh is set, the cached set is bypassed.
+func (p *Provider) keySet(ctx context.Context, refresh bool) (lti.KeySet, error) {
+	p.mu.Lock()
+	jwksURL, keys, fetched := p.jwksURL, p.keys, p.fetched
+	p.mu.Unlock()
+	if keys != nil && !refresh && time.Since(fetched) < keyCacheTTL {
+		return keys, nil
+	}
+
+	if jwksURL == "" {
+		var doc discovery
+		if err := p.get(ctx, p.DiscoveryURL(), &doc); err != nil {
+			return nil, err
+		}
+		// OIDC Discovery requires the document to name the issuer it was
+		// fetched for, so tokens cannot be passed off as another's.
+		if doc.Issuer != p.issuer {
+			return nil, fmt.Errorf("oidc: discovery document names issuer %q, want %q", doc.Issuer, p.issuer)
+		}
+		if doc.JWKSURI == "" {
+			return nil, fmt.Errorf("oidc: discovery document has no jwks_uri")
+		}
+		jwksURL = doc.JWKSURI
+	}
+	var set lti.JWKS
+	if err := p.get(ctx, jwksURL, &set); err != nil {
+		return nil, err
+	}
+	keys = set.KeySet()
+
+	p.mu.Lock()
+	p.jwksURL, p.keys, p.fetched = jwksURL, keys, time.Now()
+	p.mu.Unlock()
+	return keys, nil
+}
+
+func (p *Provider) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("oidc: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: GET %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("oidc: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: GET %s: %s", url, resp.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("oidc: decode %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type color string
+
+type inner struct {
+	Note string `json:"note"`
+}
+
+type Base struct {
+	ID uuid.UUID `json:"id"`
+}
+
+type request struct {
+	Base
+	Name    string            `json:"name" binding:"required"`
+	Color   color             `json:"color,omitempty"`
+	Count   *int              `json:"count,omitempty"`
+	Ratio   float64           `json:"ratio"`
+	When    *time.Time        `json:"when,omitempty"`
+	Tags    []string          `json:"tags" binding:"required"`
+	Env     map[string]string `json:"env,omitempty"`
+	Items   []inner           `json:"items,omitempty"`
+	Secret  string            `json:"-"`
+	private int
+}
+
+func newTestGenerator() *Generator {
+	g := NewGenerator()
+	Enum(g, color("red"), color("blue"))
+	return g
+}
+
+func TestSchemaOf(t *testing.T) {
+	g := newTestGenerator()
+	ref := g.SchemaOf(request{})
+	if ref.Ref != "#/components/schemas/request" {
+		t.Fatalf("ref = %q", ref.Ref)
+	}
+	s := g.Components().Schemas["request"]
+
+	var props []string
+	for name := range s.Properties {
+		props = append(props, name)
+	}
+	want := []string{"color", "count", "env", "id", "items", "name", "ratio", "tags", "when"}
+	if !equalSets(props, want) {
+		t.Errorf("properties = %v, want %v", props, want)
+	}
+	if !reflect.DeepEqual(s.Required, []string{"name", "tags"}) {
+		t.Errorf("required = %v", s.Required)
+	}
+	if p := s.Properties["name"]; p.Type != "string" || p.MinLength != 1 {
+		t.Errorf("name = %+v, want a non-empty string", p)
+	}
+	if p := s.Properties["color"]; !reflect.DeepEqual(p.Enum, []string{"red", "blue"}) {
+		t.Errorf("color = %+v, want the enum", p)
+	}
+	if p := s.Properties["count"]; p.Type != "integer" || !p.Nullable {
+		t.Errorf("count = %+v, want a nullable integer", p)
+	}
+	if p := s.Properties["when"]; p.Format != "date-time" {
+		t.Errorf("when = %+v", p)
+	}
+	if p := s.Properties["id"]; p.Format != "uuid" {
+		t.Errorf("embedded id = %+v", p)
+	}
+	if p := s.Properties["items"]; p.Items == nil || p.Items.Ref != "#/components/schemas/inner" {
+		t.Errorf("items = %+v, want a reference to inner", p)
+	}
+	if p := s.Properties["env"]; p.AdditionalProperties == nil || p.AdditionalProperties.Type != "string" {
+		t.Errorf("env = %+v", p)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	g := newTestGenerator()
+	schema := g.SchemaOf(request{})
+
+	tests := []struct {
+		name string
+		body string
+		want []string // "field message" of each error
+	}{
+		{"valid", `{"name": "x", "tags": [], "count": 3, "when": "2026-01-02T03:04:05Z", "env": {"A": "b"}}`, nil},
+		{"nulls and empty enum", `{"name": "x", "tags": ["a"], "count": null, "color": "", "items": null}`, nil},
+		{"case-insensitive names", `{"Name": "x", "TAGS": []}`, nil},
+		{"unknown fields", `{"name": "x", "tags": [], "extra": 1}`, nil},
+		{"missing required", `{"tags": null}`, []string{"name is required", "tags is required"}},
+		{"empty required string", `{"name": "", "tags": []}`, []string{"name must not be empty"}},
+		{"wrong types", `{"name": 1, "tags": "a", "count": 1.5, "ratio": "x"}`, []string{
+			"count must be an integer", "name must be a string", "ratio must be a number", "tags must be an array",
+		}},
+		{"enum", `{"name": "x", "tags": [], "color": "green"}`, []string{"color must be one of red, blue"}},
+		{"formats", `{"name": "x", "tags": [], "id": "nope", "when": "yesterday"}`, []string{
+			"id must be a UUID", "when must be an RFC 3339 date-time",
+		}},
+		{"nested", `{"name": "x", "tags": [1, "b"], "items": [{"note": "ok"}, {"note": 2}], "env": {"A": 1}}`, []string{
+			"env.A must be a string", "items[1].note must be a string", "tags[0] must be a string",
+		}},
+		{"not an object", `[1]`, []string{"body must be an object"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := g.Validate(schema, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Error())
+			}
+			if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+				t.Errorf("errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := g.Validate(schema, []byte(`{"name": `)); err == nil {
+		t.Error("malformed JSON: want an error")
+	}
+}
+
+func equalSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]bool, len(a))
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			return false
+		}
+	}
+	return true
+}
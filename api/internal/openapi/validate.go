@@ -0,0 +1,202 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldError is a field of a request body that does not match its schema.
+type FieldError struct {
+	// Field is the field's path, such as "test_cases[2].input", or empty
+	// for the body itself.
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return "body " + e.Message
+	}
+	return e.Field + " " + e.Message
+}
+
+// Validate decodes a JSON body and checks it against schema. It returns an
+// error if the body is not JSON, and otherwise the fields that do not
+// match, by path.
+//
+// Like encoding/json, it matches field names case-insensitively when no
+// name matches exactly and ignores fields the schema does not know. A null
+// or, for enums, an empty string is accepted in place of an optional
+// field, as the handlers read both as leaving the field out.
+func (g *Generator) Validate(schema *Schema, body []byte) ([]FieldError, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	var errs []FieldError
+	g.validate(schema, value, "", &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs, nil
+}
+
+func (g *Generator) validate(s *Schema, value any, path string, errs *[]FieldError) {
+	if s.Ref != "" {
+		s = g.components[strings.TrimPrefix(s.Ref, refPrefix)]
+	}
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			*errs = append(*errs, FieldError{path, "must be " + article(s.Type)})
+		}
+		return
+	}
+
+	fail := func(msg string) { *errs = append(*errs, FieldError{path, msg}) }
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		g.validateObject(s, obj, path, errs)
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		for i, item := range items {
+			g.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if msg := checkString(s, str); msg != "" {
+			fail(msg)
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if _, err := strconv.ParseInt(string(n), 10, 64); !ok || err != nil {
+			fail("must be an integer")
+		}
+	case "number":
+		n, ok := value.(json.Number)
+		if _, err := n.Float64(); !ok || err != nil {
+			fail("must be a number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be true or false")
+		}
+	}
+}
+
+func (g *Generator) validateObject(s *Schema, obj map[string]any, path string, errs *[]FieldError) {
+	// Match the body's fields to properties, exact names first.
+	fields := make(map[string]any, len(obj))
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := s.Properties[key]; ok {
+			fields[key] = obj[key]
+		}
+	}
+	for _, key := range keys {
+		if _, ok := s.Properties[key]; ok {
+			continue
+		}
+		for name := range s.Properties {
+			if _, set := fields[name]; !set && strings.EqualFold(name, key) {
+				fields[name] = obj[key]
+				break
+			}
+		}
+	}
+
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+		if fields[name] == nil {
+			*errs = append(*errs, FieldError{join(path, name), "is required"})
+		}
+	}
+	for name, value := range fields {
+		prop := s.Properties[name]
+		if value == nil || (!required[name] && prop.Enum != nil && value == "") {
+			continue
+		}
+		g.validate(prop, value, join(path, name), errs)
+	}
+
+	if s.AdditionalProperties != nil {
+		for _, key := range keys {
+			g.validate(s.AdditionalProperties, obj[key], join(path, key), errs)
+		}
+	}
+}
+
+func checkString(s *Schema, str string) string {
+	if len(str) < s.MinLength {
+		return "must not be empty"
+	}
+	if s.Enum != nil {
+		for _, v := range s.Enum {
+			if str == v {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(s.Enum, ", ")
+	}
+	var err error
+	switch s.Format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, str)
+	case "uuid":
+		_, err = uuid.Parse(str)
+	case "byte":
+		_, err = base64.StdEncoding.DecodeString(str)
+	}
+	if err != nil {
+		return "must be " + article(s.Format)
+	}
+	return ""
+}
+
+func article(kind string) string {
+	switch kind {
+	case "object", "array", "integer":
+		return "an " + kind
+	case "boolean":
+		return "true or false"
+	case "date-time":
+		return "an RFC 3339 date-time"
+	case "uuid":
+		return "a UUID"
+	case "byte":
+		return "base64"
+	}
+	return "a " + kind
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -7,13 +7,25 @@ import (
 	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
 )
 
-// Ensure MockPublisher implements publisher.Publisher.
-var _ publisher.Publisher = (*MockPublisher)(nil)
+// Ensure MockPublisher implements publisher.Publisher, publisher.QueueInspector
+// and publisher.LanguageHolder.
+var (
+	_ publisher.Publisher      = (*MockPublisher)(nil)
+	_ publisher.QueueInspector = (*MockPublisher)(nil)
+	_ publisher.LanguageHolder = (*MockPublisher)(nil)
+)
 
 // MockPublisher is a mock message publisher for testing.
 type MockPublisher struct {
 	Published []*domain.Job
 	PublishFn func(ctx context.Context, job *domain.Job) error
+
+	// Ready is what ReadyMessages reports unless ReadyMessagesFn is set.
+	Ready           int
+	ReadyMessagesFn func(ctx context.Context) (int, error)
+
+	// Held counts each language's held jobs; ReleaseHeld empties it.
+	Held map[domain.Language]int
 }
 
 // NewMockPublisher creates a new mock publisher.
@@ -29,6 +41,23 @@ func (m *MockPublisher) Publish(ctx context.Context, job *domain.Job) error {
 	return nil
 }
 
+func (m *MockPublisher) ReadyMessages(ctx context.Context) (int, error) {
+	if m.ReadyMessagesFn != nil {
+		return m.ReadyMessagesFn(ctx)
+	}
+	return m.Ready, nil
+}
+
+func (m *MockPublisher) HeldMessages(ctx context.Context, lang domain.Language) (int, error) {
+	return m.Held[lang], nil
+}
+
+func (m *MockPublisher) ReleaseHeld(ctx context.Context, lang domain.Language) (int, error) {
+	n := m.Held[lang]
+	delete(m.Held, lang)
+	return n, nil
+}
+
 func (m *MockPublisher) Close() error {
 	return nil
 }
@@ -3,14 +3,17 @@ package publisher
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/metrics"
 )
 
 const (
@@ -18,12 +21,26 @@ const (
 	exchangeType = "direct"
 	routingKey   = "execute"
 
+	// executionQueue is the queue workers consume jobs from.
+	executionQueue = "execution_tasks"
+	// holdingQueuePrefix starts the name of the queue each paused
+	// language's jobs are held in; it must match the worker's.
+	holdingQueuePrefix = executionQueue + ".paused."
+
 	// Reconnection settings
 	reconnectDelay    = 2 * time.Second
 	maxReconnectDelay = 30 * time.Second
 
 	// Publish timeout
 	publishTimeout = 5 * time.Second
+
+	// drainTimeout bounds how long Close waits for outstanding confirms.
+	drainTimeout = 5 * time.Second
+)
+
+var (
+	errPublisherClosed = errors.New("rabbitmq: publisher closed")
+	errReconnecting    = errors.New("rabbitmq: channel not available (reconnecting)")
 )
 
 // Publisher defines the interface for publishing jobs to the message broker.
@@ -32,156 +49,248 @@ type Publisher interface {
 	Close() error
 }
 
+// QueueInspector reports the state of the execution queue.
+type QueueInspector interface {
+	// ReadyMessages returns how many messages wait in the execution queue,
+	// not counting those delivered to workers and not yet acknowledged.
+	ReadyMessages(ctx context.Context) (int, error)
+}
+
+// LanguageHolder manages the queues workers hold paused languages' jobs in.
+type LanguageHolder interface {
+	// HeldMessages returns how many of a language's jobs are held.
+	HeldMessages(ctx context.Context, lang domain.Language) (int, error)
+	// ReleaseHeld moves a language's held jobs back to the execution queue
+	// and returns how many it moved, including on error.
+	ReleaseHeld(ctx context.Context, lang domain.Language) (int, error)
+}
+
+var (
+	_ QueueInspector = (*rabbitPublisher)(nil)
+	_ LanguageHolder = (*rabbitPublisher)(nil)
+)
+
+// publisherState is where the publisher is in its lifecycle. It only moves
+// connecting <-> ready until Close moves it to closed for good.
+type publisherState int
+
+const (
+	stateConnecting publisherState = iota
+	stateReady
+	stateClosed
+)
+
+// generation is one session and the calls using it. A generation is only
+// handed out while it is current, so once retired its count of active
+// calls can only fall, and its session is closed once that reaches zero.
+type generation struct {
+	id   uint64
+	sess session
+	// endpoint is the index in the publisher's endpoints sess is
+	// connected to.
+	endpoint int
+	active   sync.WaitGroup
+	// inFlight mirrors active, which cannot be read, for reporting.
+	inFlight atomic.Int64
+}
+
+// release ends one call acquired on g.
+func (g *generation) release() {
+	g.inFlight.Add(-1)
+	g.active.Done()
+}
+
 type rabbitPublisher struct {
-	url     string
-	conn    *amqp.Connection
-	channel *amqp.Channel
-	logger  *zap.Logger
-	mu      sync.RWMutex
-	closed  bool
+	endpoints *endpoints
+	dial      func(url string) (session, error)
+	logger    *zap.Logger
+
+	reconnectDelay    time.Duration
+	maxReconnectDelay time.Duration
+	drainTimeout      time.Duration
+
+	mu          sync.Mutex
+	state       publisherState
+	current     *generation // nil unless state is stateReady
+	generations uint64
+
+	// done is closed by Close to stop the reconnect loop.
+	done chan struct{}
 }
 
-// NewRabbitMQPublisher creates a new RabbitMQ publisher with exchange and queue setup.
-func NewRabbitMQPublisher(url string, logger *zap.Logger) (Publisher, error) {
-	p := &rabbitPublisher{
-		url:    url,
-		logger: logger,
+// NewRabbitMQPublisher creates a new RabbitMQ publisher with exchange and queue
+// setup. maxPriority above zero declares the execution queue with job
+// priorities; zero keeps it a quorum queue that ignores them. urls are the
+// nodes of one cluster: the publisher connects to one at a time and
+// rotates to the next when it cannot reach it or loses its connection.
+func NewRabbitMQPublisher(urls []string, maxPriority int, logger *zap.Logger) (Publisher, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("rabbitmq: no broker URL")
 	}
-
-	if err := p.connect(); err != nil {
+	dial := func(url string) (session, error) {
+		return dialAMQP(url, maxPriority)
+	}
+	p := newPublisher(urls, dial, logger)
+	if err := p.start(); err != nil {
 		return nil, err
 	}
 
-	// Watch for connection closures and reconnect
-	go p.watchConnection()
-
+	p.logger.Info("RabbitMQ publisher initialized",
+		zap.String("exchange", exchangeName),
+		zap.String("queue", executionQueue),
+		zap.Int("max_priority", maxPriority),
+		zap.Int("endpoints", len(urls)),
+	)
 	return p, nil
 }
 
-func (p *rabbitPublisher) connect() error {
-	conn, err := amqp.Dial(p.url)
-	if err != nil {
-		return fmt.Errorf("rabbitmq: dial: %w", err)
+func newPublisher(urls []string, dial func(string) (session, error), logger *zap.Logger) *rabbitPublisher {
+	return &rabbitPublisher{
+		endpoints:         newEndpoints(urls),
+		dial:              dial,
+		logger:            logger,
+		reconnectDelay:    reconnectDelay,
+		maxReconnectDelay: maxReconnectDelay,
+		drainTimeout:      drainTimeout,
+		done:              make(chan struct{}),
 	}
+}
 
-	ch, err := conn.Channel()
-	if err != nil {
-		conn.Close()
-		return fmt.Errorf("rabbitmq: channel: %w", err)
+// start dials the first session, trying each endpoint once, and watches it
+// for the publisher's lifetime.
+func (p *rabbitPublisher) start() error {
+	var err error
+	for range p.endpoints.urls {
+		var sess session
+		var endpoint int
+		if sess, endpoint, err = p.dialNext(); err == nil {
+			p.install(sess, endpoint)
+			go p.watch()
+			return nil
+		}
 	}
+	return err
+}
 
-	// Enable publisher confirms
-	if err := ch.Confirm(false); err != nil {
-		ch.Close()
-		conn.Close()
-		return fmt.Errorf("rabbitmq: enable confirms: %w", err)
+// dialNext dials the endpoint after the last one tried, recording whether
+// it was reached.
+func (p *rabbitPublisher) dialNext() (session, int, error) {
+	i := p.endpoints.pick(time.Now())
+	sess, err := p.dial(p.endpoints.url(i))
+	if err != nil {
+		p.endpoints.failed(i, time.Now())
+		return nil, i, fmt.Errorf("%w (endpoint=%s)", err, p.endpoints.label(i))
 	}
+	p.endpoints.succeeded(i)
+	return sess, i, nil
+}
 
-	// Declare the direct exchange
-	if err := ch.ExchangeDeclare(exchangeName, exchangeType, true, false, false, false, nil); err != nil {
-		ch.Close()
-		conn.Close()
-		return fmt.Errorf("rabbitmq: declare exchange: %w", err)
-	}
+// install makes sess, connected to endpoint, the current generation. It
+// reports false, leaving the caller to close sess, if the publisher was
+// closed in the meantime.
+func (p *rabbitPublisher) install(sess session, endpoint int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	// Declare dead letter exchange
-	if err := ch.ExchangeDeclare("sentinel.dlx", "direct", true, false, false, false, nil); err != nil {
-		ch.Close()
-		conn.Close()
-		return fmt.Errorf("rabbitmq: declare DLX: %w", err)
+	if p.state == stateClosed {
+		return false
 	}
+	p.generations++
+	p.current = &generation{id: p.generations, sess: sess, endpoint: endpoint}
+	p.state = stateReady
+	return true
+}
 
-	// Declare dead letter queue
-	if _, err := ch.QueueDeclare("dead_letter_queue", true, false, false, false, nil); err != nil {
-		ch.Close()
-		conn.Close()
-		return fmt.Errorf("rabbitmq: declare DLQ: %w", err)
-	}
-	if err := ch.QueueBind("dead_letter_queue", "", "sentinel.dlx", false, nil); err != nil {
-		ch.Close()
-		conn.Close()
-		return fmt.Errorf("rabbitmq: bind DLQ: %w", err)
-	}
+// acquire hands out the current generation for one call, which must call
+// release on it when finished.
+func (p *rabbitPublisher) acquire() (*generation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	// Declare main execution queue with DLX
-	args := amqp.Table{
-		"x-dead-letter-exchange": "sentinel.dlx",
-		"x-queue-type":           "quorum",
-	}
-	if _, err := ch.QueueDeclare("execution_tasks", true, false, false, false, args); err != nil {
-		ch.Close()
-		conn.Close()
-		return fmt.Errorf("rabbitmq: declare queue: %w", err)
-	}
-	if err := ch.QueueBind("execution_tasks", routingKey, exchangeName, false, nil); err != nil {
-		ch.Close()
-		conn.Close()
-		return fmt.Errorf("rabbitmq: bind queue: %w", err)
+	switch p.state {
+	case stateClosed:
+		return nil, errPublisherClosed
+	case stateConnecting:
+		return nil, errReconnecting
 	}
+	p.current.active.Add(1)
+	p.current.inFlight.Add(1)
+	return p.current, nil
+}
 
+// retire stops handing out g and closes its session once its calls finish.
+// Calls still using g fail on their own once its session is gone, so the
+// reconnect does not wait for them.
+func (p *rabbitPublisher) retire(g *generation) {
 	p.mu.Lock()
-	p.conn = conn
-	p.channel = ch
+	if p.current != g {
+		p.mu.Unlock()
+		return
+	}
+	p.current = nil
+	p.state = stateConnecting
 	p.mu.Unlock()
 
-	p.logger.Info("RabbitMQ publisher initialized",
-		zap.String("exchange", exchangeName),
-		zap.String("queue", "execution_tasks"),
-	)
-
-	return nil
+	go func() {
+		g.active.Wait()
+		g.sess.close()
+	}()
 }
 
-// watchConnection monitors the connection and reconnects on failure.
-func (p *rabbitPublisher) watchConnection() {
+// watch replaces the current session whenever it closes.
+func (p *rabbitPublisher) watch() {
 	for {
-		p.mu.RLock()
-		if p.closed {
-			p.mu.RUnlock()
-			return
+		p.mu.Lock()
+		g := p.current
+		p.mu.Unlock()
+		if g == nil {
+			return // closed
 		}
-		conn := p.conn
-		p.mu.RUnlock()
 
-		if conn == nil {
-			time.Sleep(reconnectDelay)
-			continue
-		}
-
-		// Block until the connection closes
-		reason, ok := <-conn.NotifyClose(make(chan *amqp.Error))
-		if !ok {
-			// Channel closed normally
+		select {
+		case <-p.done:
 			return
+		case <-g.sess.done():
 		}
 
 		p.logger.Warn("RabbitMQ connection lost, reconnecting...",
-			zap.String("reason", reason.Error()),
+			zap.Uint64("generation", g.id),
+			zap.String("endpoint", p.endpoints.label(g.endpoint)),
 		)
+		// The next dial goes to another node, if there is one.
+		p.endpoints.failed(g.endpoint, time.Now())
+		p.retire(g)
+		if !p.reconnect() {
+			return
+		}
+	}
+}
 
-		delay := reconnectDelay
-		for {
-			p.mu.RLock()
-			if p.closed {
-				p.mu.RUnlock()
-				return
-			}
-			p.mu.RUnlock()
-
-			time.Sleep(delay)
-
-			if err := p.connect(); err != nil {
-				p.logger.Warn("RabbitMQ reconnect failed", zap.Error(err), zap.Duration("retry_in", delay))
-				delay = delay * 2
-				if delay > maxReconnectDelay {
-					delay = maxReconnectDelay
-				}
-				continue
-			}
-
-			p.logger.Info("RabbitMQ reconnected successfully")
-			break
+// reconnect dials with exponential backoff, rotating among the endpoints,
+// until it installs a new session. It reports false if the publisher was
+// closed first.
+func (p *rabbitPublisher) reconnect() bool {
+	delay := p.reconnectDelay
+	for {
+		select {
+		case <-p.done:
+			return false
+		case <-time.After(delay):
 		}
+
+		sess, endpoint, err := p.dialNext()
+		if err != nil {
+			delay = min(delay*2, p.maxReconnectDelay)
+			p.logger.Warn("RabbitMQ reconnect failed", zap.Error(err), zap.Duration("retry_in", delay))
+			continue
+		}
+		if !p.install(sess, endpoint) {
+			sess.close()
+			return false
+		}
+
+		p.logger.Info("RabbitMQ reconnected successfully", zap.String("endpoint", p.endpoints.label(endpoint)))
+		return true
 	}
 }
 
@@ -191,65 +300,111 @@ func (p *rabbitPublisher) Publish(ctx context.Context, job *domain.Job) error {
 		return fmt.Errorf("rabbitmq: marshal job: %w", err)
 	}
 
-	p.mu.RLock()
-	ch := p.channel
-	p.mu.RUnlock()
-
-	if ch == nil {
-		return fmt.Errorf("rabbitmq: channel not available (reconnecting)")
+	g, err := p.acquire()
+	if err != nil {
+		return err
 	}
-
-	// Get confirmation channel
-	confirm := ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	defer g.release()
 
 	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
 	defer cancel()
 
-	err = ch.PublishWithContext(publishCtx,
-		exchangeName,
-		routingKey,
-		false, // mandatory
-		false, // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent,
-			MessageId:    job.JobID.String(),
-			Timestamp:    time.Now(),
-			Body:         body,
-		},
-	)
+	err = g.sess.publish(publishCtx, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    job.JobID.String(),
+		Priority:     uint8(job.Priority),
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
 	if err != nil {
-		return fmt.Errorf("rabbitmq: publish: %w", err)
-	}
-
-	// Wait for broker confirmation
-	select {
-	case ack := <-confirm:
-		if !ack.Ack {
-			return fmt.Errorf("rabbitmq: broker nacked message (job_id=%s)", job.JobID)
-		}
-	case <-publishCtx.Done():
-		return fmt.Errorf("rabbitmq: publish confirmation timeout (job_id=%s)", job.JobID)
+		return fmt.Errorf("%w (job_id=%s)", err, job.JobID)
 	}
 
 	p.logger.Debug("Published job to RabbitMQ",
 		zap.String("job_id", job.JobID.String()),
+		zap.Uint64("generation", g.id),
 		zap.Int("body_size", len(body)),
 	)
 	return nil
 }
 
+func (p *rabbitPublisher) ReadyMessages(ctx context.Context) (int, error) {
+	g, err := p.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer g.release()
+
+	return g.sess.readyMessages(ctx)
+}
+
+func (p *rabbitPublisher) HeldMessages(ctx context.Context, lang domain.Language) (int, error) {
+	g, err := p.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer g.release()
+
+	return g.sess.heldMessages(ctx, holdingQueuePrefix+string(lang))
+}
+
+func (p *rabbitPublisher) ReleaseHeld(ctx context.Context, lang domain.Language) (int, error) {
+	g, err := p.acquire()
+	if err != nil {
+		return 0, err
+	}
+	defer g.release()
+
+	return g.sess.releaseHeld(ctx, holdingQueuePrefix+string(lang))
+}
+
+// Close stops reconnecting, waits up to the drain timeout for calls in
+// flight to get their confirmations, and closes the current session. Calls
+// still waiting then fail, and Close reports how many there were so a
+// rollout restart does not lose just-accepted submissions silently. Calls
+// made after Close fail.
 func (p *rabbitPublisher) Close() error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	if p.state == stateClosed {
+		p.mu.Unlock()
+		return nil
+	}
+	g := p.current
+	p.current = nil
+	p.state = stateClosed
+	close(p.done)
+	p.mu.Unlock()
+
+	if g == nil {
+		return nil
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		g.active.Wait()
+		close(drained)
+	}()
+	timer := time.NewTimer(p.drainTimeout)
+	defer timer.Stop()
 
-	p.closed = true
+	select {
+	case <-drained:
+		return g.sess.close()
+	case <-timer.C:
+	}
 
-	if p.channel != nil {
-		p.channel.Close()
+	unconfirmed := g.inFlight.Load()
+	if unconfirmed <= 0 {
+		return g.sess.close() // drained just as the timer fired
 	}
-	if p.conn != nil {
-		return p.conn.Close()
+	metrics.UnconfirmedOnClose.Add(float64(unconfirmed))
+	p.logger.Warn("Closing RabbitMQ publisher with unconfirmed publishes",
+		zap.Int64("unconfirmed", unconfirmed),
+		zap.Duration("drain_timeout", p.drainTimeout),
+	)
+	if err := g.sess.close(); err != nil {
+		return err
 	}
-	return nil
+	return fmt.Errorf("rabbitmq: closed with %d unconfirmed publishes", unconfirmed)
 }
@@ -0,0 +1,488 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// fakeSession stands in for a broker connection. drop simulates the broker
+// going away; close is the publisher closing it. A call running on, or
+// starting on, a session the publisher has closed is a violation.
+type fakeSession struct {
+	mu         sync.Mutex
+	closed     bool
+	inFlight   int
+	violations int
+	publishes  int
+	last       amqp.Publishing
+
+	lost     chan struct{}
+	lostOnce sync.Once
+
+	// confirm, when set, holds each publish's confirmation until closed.
+	confirm chan struct{}
+}
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{lost: make(chan struct{})}
+}
+
+func (s *fakeSession) publish(ctx context.Context, msg amqp.Publishing) error {
+	s.mu.Lock()
+	if s.closed {
+		s.violations++
+	}
+	s.inFlight++
+	confirm := s.confirm
+	s.mu.Unlock()
+
+	if confirm != nil {
+		select {
+		case <-confirm:
+		case <-s.lost:
+		case <-ctx.Done():
+		}
+	} else {
+		time.Sleep(10 * time.Microsecond)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	select {
+	case <-s.lost:
+		return errors.New("connection lost")
+	default:
+	}
+	s.publishes++
+	s.last = msg
+	return nil
+}
+
+func (s *fakeSession) readyMessages(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeSession) heldMessages(ctx context.Context, queue string) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeSession) releaseHeld(ctx context.Context, queue string) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeSession) done() <-chan struct{} {
+	return s.lost
+}
+
+func (s *fakeSession) drop() {
+	s.lostOnce.Do(func() { close(s.lost) })
+}
+
+func (s *fakeSession) close() error {
+	s.mu.Lock()
+	if s.closed || s.inFlight > 0 {
+		s.violations++
+	}
+	s.closed = true
+	s.mu.Unlock()
+	s.drop()
+	return nil
+}
+
+func (s *fakeSession) state() (closed bool, violations, publishes int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed, s.violations, s.publishes
+}
+
+// fakeDialer hands out fake sessions, failing while failures remain.
+type fakeDialer struct {
+	mu       sync.Mutex
+	sessions []*fakeSession
+	failures int
+	// down refuses dials to the URLs it holds.
+	down map[string]bool
+	// dialed lists the URLs dialed, in order.
+	dialed []string
+	// gate, when set, holds each dial until it is closed.
+	gate chan struct{}
+}
+
+func (d *fakeDialer) dial(url string) (session, error) {
+	d.mu.Lock()
+	gate := d.gate
+	d.mu.Unlock()
+	if gate != nil {
+		<-gate
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dialed = append(d.dialed, url)
+	if d.down[url] {
+		return nil, errors.New("node down")
+	}
+	if d.failures > 0 {
+		d.failures--
+		return nil, errors.New("dial refused")
+	}
+	s := newFakeSession()
+	d.sessions = append(d.sessions, s)
+	return s, nil
+}
+
+func (d *fakeDialer) all() []*fakeSession {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*fakeSession(nil), d.sessions...)
+}
+
+func (d *fakeDialer) last() *fakeSession {
+	all := d.all()
+	return all[len(all)-1]
+}
+
+func startFakePublisher(t *testing.T, d *fakeDialer) *rabbitPublisher {
+	t.Helper()
+	p := newPublisher([]string{"amqp://fake"}, d.dial, zap.NewNop())
+	p.reconnectDelay = time.Millisecond
+	p.maxReconnectDelay = 4 * time.Millisecond
+	if err := p.start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	return p
+}
+
+func testJob() *domain.Job {
+	return &domain.Job{JobID: uuid.New(), Language: "python3", SourceCode: "print(1)"}
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPublisher_StressReconnectAndClose(t *testing.T) {
+	d := &fakeDialer{}
+	p := startFakePublisher(t, d)
+
+	stop := make(chan struct{})
+	var published atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := p.Publish(context.Background(), testJob()); err == nil {
+					published.Add(1)
+				}
+				p.ReadyMessages(context.Background())
+			}
+		}()
+	}
+
+	// Drop the broker connection repeatedly while publishes run.
+	for i := 0; i < 50; i++ {
+		d.last().drop()
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// Close races the publishers still running.
+	var closers sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		closers.Add(1)
+		go func() {
+			defer closers.Done()
+			if err := p.Close(); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+		}()
+	}
+	closers.Wait()
+	close(stop)
+	wg.Wait()
+
+	if err := p.Publish(context.Background(), testJob()); !errors.Is(err, errPublisherClosed) {
+		t.Fatalf("Publish after Close: got %v, want %v", err, errPublisherClosed)
+	}
+
+	sessions := d.all()
+	if len(sessions) < 2 {
+		t.Fatalf("expected reconnects, dialed %d sessions", len(sessions))
+	}
+	waitUntil(t, "every session to be closed", func() bool {
+		for _, s := range d.all() {
+			if closed, _, _ := s.state(); !closed {
+				return false
+			}
+		}
+		return true
+	})
+
+	var confirmed int
+	for i, s := range d.all() {
+		_, violations, publishes := s.state()
+		if violations > 0 {
+			t.Errorf("session %d: %d calls ran on it after it was closed", i+1, violations)
+		}
+		confirmed += publishes
+	}
+	if confirmed == 0 || int64(confirmed) != published.Load() {
+		t.Errorf("sessions confirmed %d publishes, callers saw %d succeed", confirmed, published.Load())
+	}
+}
+
+// publishHeld starts n publishes on p whose confirmations wait on the
+// current session's confirm channel, and returns their results.
+func publishHeld(t *testing.T, p *rabbitPublisher, sess *fakeSession, n int) <-chan error {
+	t.Helper()
+	sess.mu.Lock()
+	sess.confirm = make(chan struct{})
+	sess.mu.Unlock()
+
+	results := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() { results <- p.Publish(context.Background(), testJob()) }()
+	}
+	waitUntil(t, "the publishes to await confirmation", func() bool {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		return sess.inFlight == n
+	})
+	return results
+}
+
+func TestPublisher_CloseDrainsConfirms(t *testing.T) {
+	d := &fakeDialer{}
+	p := startFakePublisher(t, d)
+	sess := d.last()
+	results := publishHeld(t, p, sess, 3)
+
+	time.AfterFunc(10*time.Millisecond, func() { close(sess.confirm) })
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("publish confirmed during drain failed: %v", err)
+		}
+	}
+	if _, violations, publishes := sess.state(); violations > 0 || publishes != 3 {
+		t.Errorf("expected 3 confirmed publishes before close, got %d (violations %d)", publishes, violations)
+	}
+}
+
+func TestPublisher_CloseReportsUnconfirmed(t *testing.T) {
+	d := &fakeDialer{}
+	p := startFakePublisher(t, d)
+	p.drainTimeout = 20 * time.Millisecond
+	sess := d.last()
+	results := publishHeld(t, p, sess, 3)
+
+	err := p.Close()
+	if err == nil || !strings.Contains(err.Error(), "3 unconfirmed") {
+		t.Fatalf("Close: got %v, want it to report 3 unconfirmed publishes", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-results; err == nil {
+			t.Error("expected the unconfirmed publish to fail")
+		}
+	}
+	// The drain timeout is the one case where the publisher closes a
+	// session under running calls, so violations are not checked here.
+	if closed, _, _ := sess.state(); !closed {
+		t.Error("expected the session to be closed after the drain timeout")
+	}
+}
+
+func TestPublisher_ReconnectsAfterFailedDials(t *testing.T) {
+	d := &fakeDialer{}
+	p := startFakePublisher(t, d)
+	defer p.Close()
+
+	d.mu.Lock()
+	d.failures = 3
+	d.mu.Unlock()
+	first := d.last()
+	first.drop()
+
+	waitUntil(t, "the publisher to reconnect", func() bool {
+		return p.Publish(context.Background(), testJob()) == nil
+	})
+
+	if got := len(d.all()); got != 2 {
+		t.Fatalf("dialed %d sessions, want 2", got)
+	}
+	waitUntil(t, "the lost session to be closed", func() bool {
+		closed, _, _ := first.state()
+		return closed
+	})
+	p.mu.Lock()
+	id := p.current.id
+	p.mu.Unlock()
+	if id != 2 {
+		t.Errorf("generation = %d, want 2", id)
+	}
+}
+
+func TestPublisher_RotatesEndpoints(t *testing.T) {
+	d := &fakeDialer{down: map[string]bool{"amqp://a": true}}
+	p := newPublisher([]string{"amqp://a", "amqp://b", "amqp://c"}, d.dial, zap.NewNop())
+	p.reconnectDelay = time.Millisecond
+	if err := p.start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer p.Close()
+
+	// Losing b moves the publisher on to c; a is still cooling down.
+	d.last().drop()
+	waitUntil(t, "reconnect", func() bool { return len(d.all()) == 2 })
+	d.mu.Lock()
+	dialed := strings.Join(d.dialed, " ")
+	d.mu.Unlock()
+	if dialed != "amqp://a amqp://b amqp://c" {
+		t.Errorf("dialed %s, want a, b, then c", dialed)
+	}
+	if err := p.Publish(context.Background(), testJob()); err != nil {
+		t.Fatalf("publish after failover: %v", err)
+	}
+}
+
+func TestEndpoints_Pick(t *testing.T) {
+	now := time.Now()
+	e := newEndpoints([]string{"amqp://u:secret@a:5672/", "amqp://b:5672/"})
+	if e.label(0) != "a:5672" {
+		t.Errorf("label = %q, want the host alone", e.label(0))
+	}
+	if got := []int{e.pick(now), e.pick(now), e.pick(now)}; got[0] != 0 || got[1] != 1 || got[2] != 0 {
+		t.Errorf("picks = %v, want round robin", got)
+	}
+
+	e.failed(1, now)
+	if i := e.pick(now); i != 0 {
+		t.Errorf("picked %d, want the healthy endpoint 0", i)
+	}
+	if i := e.pick(now); i != 0 {
+		t.Errorf("picked %d, want 0 again while 1 cools down", i)
+	}
+
+	// With every endpoint down, the one back soonest is tried.
+	e.failed(1, now)
+	e.failed(0, now)
+	if i := e.pick(now); i != 0 {
+		t.Errorf("picked %d, want 0, whose cooldown is shorter", i)
+	}
+	if i := e.pick(now.Add(maxEndpointCooldown)); i != 1 {
+		t.Errorf("picked %d, want 1 once both cooldowns ended", i)
+	}
+}
+
+func TestPublisher_PublishWhileReconnecting(t *testing.T) {
+	d := &fakeDialer{}
+	p := startFakePublisher(t, d)
+	defer p.Close()
+
+	d.mu.Lock()
+	d.gate = make(chan struct{})
+	d.mu.Unlock()
+	d.last().drop()
+
+	waitUntil(t, "the publisher to start reconnecting", func() bool {
+		return errors.Is(p.Publish(context.Background(), testJob()), errReconnecting)
+	})
+	if _, err := p.ReadyMessages(context.Background()); !errors.Is(err, errReconnecting) {
+		t.Errorf("ReadyMessages while reconnecting: got %v, want %v", err, errReconnecting)
+	}
+	close(d.gate)
+}
+
+func TestPublisher_CloseDuringReconnectClosesNewSession(t *testing.T) {
+	d := &fakeDialer{}
+	p := startFakePublisher(t, d)
+
+	gate := make(chan struct{})
+	d.mu.Lock()
+	d.gate = gate
+	d.mu.Unlock()
+	d.last().drop()
+
+	waitUntil(t, "the publisher to start reconnecting", func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.state == stateConnecting
+	})
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	close(gate)
+
+	// The dial may finish after Close; its session must not outlive it.
+	time.Sleep(10 * time.Millisecond)
+	for i, s := range d.all() {
+		waitUntil(t, "the session to be closed", func() bool {
+			closed, _, _ := s.state()
+			return closed
+		})
+		if _, violations, _ := s.state(); violations > 0 {
+			t.Errorf("session %d: %d violations", i+1, violations)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestPublisher_PublishCarriesPriority(t *testing.T) {
+	d := &fakeDialer{}
+	p := startFakePublisher(t, d)
+	defer p.Close()
+
+	job := testJob()
+	job.Priority = domain.MaxPriority
+	if err := p.Publish(context.Background(), job); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	s := d.last()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last.Priority != domain.MaxPriority || s.last.MessageId != job.JobID.String() {
+		t.Errorf("published priority %d for %s, want %d", s.last.Priority, s.last.MessageId, domain.MaxPriority)
+	}
+}
+
+func TestExecutionQueueArgs(t *testing.T) {
+	if args := executionQueueArgs(0); args["x-queue-type"] != "quorum" || args["x-max-priority"] != nil {
+		t.Errorf("without priorities: got %v, want a quorum queue", args)
+	}
+	args := executionQueueArgs(domain.MaxPriority)
+	if args["x-queue-type"] != "classic" || args["x-max-priority"] != int32(domain.MaxPriority) {
+		t.Errorf("with priorities: got %v, want a classic queue with x-max-priority", args)
+	}
+	if args["x-dead-letter-exchange"] != "sentinel.dlx" {
+		t.Errorf("dead-letter exchange = %v", args["x-dead-letter-exchange"])
+	}
+}
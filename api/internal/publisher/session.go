@@ -0,0 +1,242 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// session is one broker connection and the channel publishes go through.
+type session interface {
+	// publish sends msg and waits for the broker to confirm it.
+	publish(ctx context.Context, msg amqp.Publishing) error
+	// readyMessages reports the execution queue's ready messages.
+	readyMessages(ctx context.Context) (int, error)
+	// heldMessages reports the messages in a holding queue.
+	heldMessages(ctx context.Context, queue string) (int, error)
+	// releaseHeld moves the messages in a holding queue back to the
+	// execution queue and reports how many it moved.
+	releaseHeld(ctx context.Context, queue string) (int, error)
+	// done is closed once the connection or the channel has closed.
+	done() <-chan struct{}
+	// close closes the connection.
+	close() error
+}
+
+var _ session = (*amqpSession)(nil)
+
+type amqpSession struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	closed  chan struct{}
+}
+
+// dialAMQP connects to url and declares the exchanges and queues jobs are
+// published to. A maxPriority above zero declares the execution queue with
+// that many priorities; see executionQueueArgs.
+func dialAMQP(url string, maxPriority int) (session, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("rabbitmq: dial: %w", err)
+	}
+	// Registered before anything can close them, so no close is missed.
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("rabbitmq: channel: %w", err)
+	}
+	chClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	if err := declareTopology(ch, maxPriority); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	s := &amqpSession{conn: conn, channel: ch, closed: make(chan struct{})}
+	go func() {
+		select {
+		case <-connClosed:
+		case <-chClosed:
+		}
+		close(s.closed)
+	}()
+	return s, nil
+}
+
+func declareTopology(ch *amqp.Channel, maxPriority int) error {
+	// Enable publisher confirms
+	if err := ch.Confirm(false); err != nil {
+		return fmt.Errorf("rabbitmq: enable confirms: %w", err)
+	}
+
+	// Declare the direct exchange
+	if err := ch.ExchangeDeclare(exchangeName, exchangeType, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: declare exchange: %w", err)
+	}
+
+	// Declare dead letter exchange
+	if err := ch.ExchangeDeclare("sentinel.dlx", "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: declare DLX: %w", err)
+	}
+
+	// Declare dead letter queue
+	if _, err := ch.QueueDeclare("dead_letter_queue", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: declare DLQ: %w", err)
+	}
+	if err := ch.QueueBind("dead_letter_queue", "", "sentinel.dlx", false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: bind DLQ: %w", err)
+	}
+
+	// Declare main execution queue with DLX
+	if _, err := ch.QueueDeclare(executionQueue, true, false, false, false, executionQueueArgs(maxPriority)); err != nil {
+		return fmt.Errorf("rabbitmq: declare queue: %w", err)
+	}
+	if err := ch.QueueBind(executionQueue, routingKey, exchangeName, false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: bind queue: %w", err)
+	}
+	return nil
+}
+
+// executionQueueArgs are the execution queue's arguments. Quorum queues do
+// not support x-max-priority, so a queue with priorities is a classic queue.
+// A queue's type and arguments cannot change once declared: switching
+// between the two means draining and deleting the queue first.
+func executionQueueArgs(maxPriority int) amqp.Table {
+	args := amqp.Table{"x-dead-letter-exchange": "sentinel.dlx"}
+	if maxPriority > 0 {
+		args["x-queue-type"] = "classic"
+		args["x-max-priority"] = int32(maxPriority)
+	} else {
+		args["x-queue-type"] = "quorum"
+	}
+	return args
+}
+
+// publish uses a deferred confirmation, which belongs to this message
+// alone, so concurrent publishes never see each other's acks.
+func (s *amqpSession) publish(ctx context.Context, msg amqp.Publishing) error {
+	confirm, err := s.channel.PublishWithDeferredConfirmWithContext(ctx, exchangeName, routingKey, false, false, msg)
+	if err != nil {
+		return fmt.Errorf("rabbitmq: publish: %w", err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("rabbitmq: publish confirmation: %w", err)
+	}
+	if !acked {
+		return errors.New("rabbitmq: broker nacked message")
+	}
+	return nil
+}
+
+// readyMessages declares the queue passively on a channel of its own: a
+// failed passive declare closes its channel, which must not be the one
+// publishes go through.
+func (s *amqpSession) readyMessages(ctx context.Context) (int, error) {
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("rabbitmq: channel: %w", err)
+	}
+	defer ch.Close()
+
+	q, err := ch.QueueDeclarePassive(executionQueue, true, false, false, false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("rabbitmq: inspect queue: %w", err)
+	}
+	return q.Messages, nil
+}
+
+// heldMessages declares the holding queue, which workers may not have
+// created yet, on a channel of its own, like readyMessages.
+func (s *amqpSession) heldMessages(ctx context.Context, queue string) (int, error) {
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("rabbitmq: channel: %w", err)
+	}
+	defer ch.Close()
+
+	q, err := ch.QueueDeclare(queue, true, false, false, false, holdingQueueArgs())
+	if err != nil {
+		return 0, fmt.Errorf("rabbitmq: inspect %s: %w", queue, err)
+	}
+	return q.Messages, nil
+}
+
+// releaseHeld takes each message off the holding queue and publishes it to
+// the execution queue, acknowledging it only once the broker confirmed the
+// copy. A release cut short leaves the rest held; a message copied but not
+// yet acknowledged is delivered twice, which workers' processing locks
+// absorb.
+func (s *amqpSession) releaseHeld(ctx context.Context, queue string) (int, error) {
+	ch, err := s.conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("rabbitmq: channel: %w", err)
+	}
+	defer ch.Close()
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, holdingQueueArgs()); err != nil {
+		return 0, fmt.Errorf("rabbitmq: declare %s: %w", queue, err)
+	}
+
+	released := 0
+	for ctx.Err() == nil {
+		msg, ok, err := ch.Get(queue, false)
+		if err != nil {
+			return released, fmt.Errorf("rabbitmq: get from %s: %w", queue, err)
+		}
+		if !ok {
+			return released, nil
+		}
+		publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err = s.publish(publishCtx, amqp.Publishing{
+			Headers:      withoutDeliveryCount(msg.Headers),
+			ContentType:  msg.ContentType,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    msg.MessageId,
+			Priority:     msg.Priority,
+			Timestamp:    msg.Timestamp,
+			Body:         msg.Body,
+		})
+		cancel()
+		if err != nil {
+			msg.Nack(false, true)
+			return released, err
+		}
+		if err := msg.Ack(false); err != nil {
+			return released, fmt.Errorf("rabbitmq: ack held message: %w", err)
+		}
+		released++
+	}
+	return released, ctx.Err()
+}
+
+// holdingQueueArgs are the arguments of every holding queue, which must
+// match the worker's.
+func holdingQueueArgs() amqp.Table {
+	return amqp.Table{"x-queue-type": "quorum"}
+}
+
+// withoutDeliveryCount copies headers without the count of deliveries a
+// quorum queue added, so a released job starts counting afresh.
+func withoutDeliveryCount(headers amqp.Table) amqp.Table {
+	copied := amqp.Table{}
+	for k, v := range headers {
+		if k != "x-delivery-count" {
+			copied[k] = v
+		}
+	}
+	return copied
+}
+
+func (s *amqpSession) done() <-chan struct{} {
+	return s.closed
+}
+
+func (s *amqpSession) close() error {
+	if s.conn.IsClosed() {
+		return nil
+	}
+	return s.conn.Close()
+}
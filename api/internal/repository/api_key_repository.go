@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// APIKeyRepository defines persistence for API keys, which are stored by
+// the SHA-256 hash of the key. Implementations must be safe for concurrent
+// use.
+type APIKeyRepository interface {
+	// Create inserts a new key with the hash of its key.
+	Create(ctx context.Context, key *domain.APIKey, hash []byte) error
+
+	// Get retrieves a key, returning domain.ErrAPIKeyNotFound if there is
+	// none.
+	Get(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+
+	// GetByHash retrieves the key whose key hashes to hash, revoked or not,
+	// returning domain.ErrAPIKeyNotFound if there is none.
+	GetByHash(ctx context.Context, hash []byte) (*domain.APIKey, error)
+
+	// List returns a tenant's keys, or every tenant's when tenantID is
+	// empty, oldest first.
+	List(ctx context.Context, tenantID string) ([]*domain.APIKey, error)
+
+	// Rotate replaces the hash and prefix of a key that is not revoked and
+	// returns the updated key. It returns domain.ErrAPIKeyNotFound if there
+	// is no such key.
+	Rotate(ctx context.Context, id uuid.UUID, prefix string, hash []byte) (*domain.APIKey, error)
+
+	// Revoke marks a key revoked, returning domain.ErrAPIKeyNotFound if
+	// there is none. Revoking a revoked key changes nothing.
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// AppealRepository defines persistence for appeals and their verdict overrides.
+// Implementations must be safe for concurrent use.
+type AppealRepository interface {
+	// Create inserts a new open appeal, returning domain.ErrAppealExists if
+	// the submission already has one.
+	Create(ctx context.Context, appeal *domain.Appeal) error
+
+	// GetByID retrieves an appeal together with its overrides.
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Appeal, error)
+
+	// List returns the tenant's appeals with the given status, oldest first,
+	// without their overrides. An empty tenantID lists every tenant's.
+	List(ctx context.Context, tenantID string, status domain.AppealStatus) ([]*domain.Appeal, error)
+
+	// OverrideVerdict records o and applies its status and score to the
+	// submission in one step.
+	OverrideVerdict(ctx context.Context, o *domain.VerdictOverride) error
+
+	// Close marks an open appeal closed with the admin's response, returning
+	// domain.ErrAppealClosed if it was already closed.
+	Close(ctx context.Context, id uuid.UUID, actor, response string) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CancelSignal tells workers to stop running jobs.
+type CancelSignal interface {
+	// RequestCancel asks the worker running the job to kill it and record
+	// it as cancelled. The request also reaches a worker that starts
+	// watching the job shortly after it is made.
+	RequestCancel(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// ExportRepository defines persistence for the warehouse export: the facts
+// to export and how far each sink has got. Implementations must be safe for
+// concurrent use.
+type ExportRepository interface {
+	// ListFacts returns up to limit verdicts recorded after the event
+	// afterEventID and before settledBefore, in event order, with sources
+	// hashed under salt.
+	ListFacts(ctx context.Context, afterEventID int64, settledBefore time.Time, salt string, limit int) ([]*domain.JobFact, error)
+
+	// GetCursor returns the last event exported to sink, 0 if none was.
+	GetCursor(ctx context.Context, sink string) (int64, error)
+
+	// SetCursor records eventID as the last event exported to sink.
+	SetCursor(ctx context.Context, sink string, eventID int64) error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// GitHubRepository defines persistence for the GitHub submission intake:
+// repository mappings and the commits awaiting a status. Implementations
+// must be safe for concurrent use.
+type GitHubRepository interface {
+	// PutRepo creates or replaces a repository mapping. It returns
+	// domain.ErrGitHubRepoTaken if another tenant has mapped the repository.
+	PutRepo(ctx context.Context, repo *domain.GitHubRepo) error
+
+	// GetRepo retrieves a repository mapping by full name, returning
+	// domain.ErrGitHubRepoNotFound if there is none.
+	GetRepo(ctx context.Context, repo string) (*domain.GitHubRepo, error)
+
+	// DeleteRepo removes a tenant's repository mapping, returning
+	// domain.ErrGitHubRepoNotFound if the tenant has none for repo.
+	DeleteRepo(ctx context.Context, tenantID, repo string) error
+
+	// AddCheck records a job submitted from a commit.
+	AddCheck(ctx context.Context, check *domain.GitHubCheck) error
+
+	// ListFinishedChecks returns up to limit checks whose job has reached a
+	// terminal status, oldest first, with the job's status and score.
+	ListFinishedChecks(ctx context.Context, limit int) ([]*domain.GitHubCheck, error)
+
+	// RemoveCheck deletes a check once its status has been posted.
+	RemoveCheck(ctx context.Context, jobID uuid.UUID) error
+}
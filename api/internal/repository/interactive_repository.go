@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// InteractiveStore carries the input and live output of interactive jobs
+// between the API's submission streams and the worker running the job.
+type InteractiveStore interface {
+	// SendStdin queues data for the job's stdin.
+	SendStdin(ctx context.Context, id uuid.UUID, data string) error
+
+	// CloseStdin ends the job's stdin after what was already sent.
+	CloseStdin(ctx context.Context, id uuid.UUID) error
+
+	// ReadOutput returns the stdout written since cursor, empty for the
+	// start, and the cursor to read on from.
+	ReadOutput(ctx context.Context, id uuid.UUID, cursor string) (string, string, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -11,15 +12,81 @@ import (
 // JobRepository defines the interface for job persistence operations.
 // Implementations must be safe for concurrent use.
 type JobRepository interface {
-	// Create inserts a new job into the data store.
+	// Create inserts a new job into the data store. It returns
+	// domain.ErrExternalIDExists if the job's tenant already has a job with
+	// its external ID.
 	Create(ctx context.Context, job *domain.Job) error
 
 	// GetByID retrieves a job by its UUID.
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, error)
 
+	// GetByExternalID retrieves the tenant's job with the client-supplied
+	// external ID, or returns domain.ErrJobNotFound.
+	GetByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Job, error)
+
+	// GetFields retrieves a job reading only the given domain.JobFields;
+	// the rest of the returned job is left zero.
+	GetFields(ctx context.Context, id uuid.UUID, fields []string) (*domain.Job, error)
+
 	// UpdateStatus atomically updates the status of a job.
 	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error
 
 	// SetResult stores the execution result for a completed job.
 	SetResult(ctx context.Context, id uuid.UUID, result *domain.Job) error
+
+	// ListOutdatedByProblem returns finished submissions of a problem that were
+	// judged against a test-data version older than version.
+	ListOutdatedByProblem(ctx context.Context, problemID string, version int) ([]*domain.Job, error)
+
+	// ListEvents returns the job's status timeline, oldest first.
+	ListEvents(ctx context.Context, id uuid.UUID) ([]domain.JobEvent, error)
+
+	// GetLineage returns the jobs from the original submission down to the
+	// job, and up to limit jobs resubmitted from it or its descendants,
+	// oldest first. It returns domain.ErrJobNotFound if the job does not
+	// exist.
+	GetLineage(ctx context.Context, id uuid.UUID, limit int) (chain, descendants []*domain.LineageEntry, err error)
+
+	// GetArtifact returns the output file name collected from the job's
+	// run, or domain.ErrArtifactNotFound.
+	GetArtifact(ctx context.Context, id uuid.UUID, name string) (*domain.Artifact, error)
+
+	// PrepareRejudge resets a job to QUEUED and bumps its judge revision,
+	// returning the updated job ready to be re-published.
+	PrepareRejudge(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+
+	// CancelQueued moves a job that is still QUEUED or SCHEDULED to
+	// CANCELLED. It reports false, changing nothing, when the job has left
+	// the queue.
+	CancelQueued(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// CancelMatching moves up to limit jobs matching filter to CANCELLED,
+	// oldest first, and returns their IDs and judge revisions. With dryRun
+	// it only lists them. Jobs a worker takes up meanwhile are left alone.
+	CancelMatching(ctx context.Context, filter domain.BulkCancelFilter, limit int, dryRun bool) ([]*domain.Job, error)
+
+	// ClaimDue moves up to limit SCHEDULED jobs whose run_at is not after
+	// now to QUEUED, earliest first, and returns them ready to be
+	// published. Concurrent callers never claim the same job.
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]*domain.Job, error)
+
+	// DeleteFinished permanently deletes a job that has a result, along
+	// with its events, artifacts and appeals, and its source unless
+	// another job shares it. It reports false, deleting nothing, when the
+	// job is still queued or running.
+	DeleteFinished(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// ListRecent returns up to limit jobs matching filter in descending job
+	// ID order, so newest first. Unless after is uuid.Nil, only jobs with an
+	// ID below it are listed.
+	ListRecent(ctx context.Context, filter domain.JobFilter, after uuid.UUID, limit int) ([]*domain.JobSummary, error)
+
+	// ListStale returns up to limit jobs in one of statuses that were last
+	// updated before the cutoff, oldest first.
+	ListStale(ctx context.Context, statuses []domain.ExecutionStatus, before time.Time, limit int) ([]*domain.Job, error)
+
+	// ResetStale moves a job that is still in status and was last updated
+	// before the cutoff to QUEUED, touching updated_at. It reports false,
+	// changing nothing, when the job has moved on since it was listed.
+	ResetStale(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus, before time.Time) (*domain.Job, bool, error)
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LockStore reads and clears the processing locks workers take before
+// running a job (see domain.Job.LockID). Workers only expire them, so a
+// lock outlives a worker that died mid-job.
+type LockStore interface {
+	// AcquiredAt reports when the lock was taken, or false if none is held.
+	AcquiredAt(ctx context.Context, lockID uuid.UUID) (time.Time, bool, error)
+
+	// DeleteIfAcquiredAt deletes the lock if it is still the one taken at
+	// acquiredAt, reporting whether it did.
+	DeleteIfAcquiredAt(ctx context.Context, lockID uuid.UUID, acquiredAt time.Time) (bool, error)
+
+	// Tombstone takes the lock for ttl unless it is held, reporting whether
+	// it did, so workers take deliveries of the job for duplicates and drop
+	// them without reading the job.
+	Tombstone(ctx context.Context, lockID uuid.UUID, ttl time.Duration) (bool, error)
+}
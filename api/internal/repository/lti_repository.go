@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// LTIRepository defines persistence for the LTI tool: registered platforms,
+// the users they launch, launches and the scores awaiting passback.
+// Implementations must be safe for concurrent use.
+type LTIRepository interface {
+	// CreatePlatform registers a platform. It returns
+	// domain.ErrLTIPlatformTaken if its issuer and client ID are registered.
+	CreatePlatform(ctx context.Context, platform *domain.LTIPlatform) error
+
+	// GetPlatform retrieves a platform by ID, returning
+	// domain.ErrLTIPlatformNotFound if there is none.
+	GetPlatform(ctx context.Context, id uuid.UUID) (*domain.LTIPlatform, error)
+
+	// FindPlatform retrieves a platform by issuer and client ID, returning
+	// domain.ErrLTIPlatformNotFound if there is none.
+	FindPlatform(ctx context.Context, issuer, clientID string) (*domain.LTIPlatform, error)
+
+	// DeletePlatform removes a tenant's platform with its users and
+	// launches, returning domain.ErrLTIPlatformNotFound if the tenant has
+	// no such platform.
+	DeletePlatform(ctx context.Context, tenantID string, id uuid.UUID) error
+
+	// UpsertUser records a launch by a user. A user seen before keeps the
+	// tenant first recorded; the stored user is written back to user.
+	UpsertUser(ctx context.Context, user *domain.LTIUser) error
+
+	// ListUsers returns a platform's users, most recently launched first.
+	ListUsers(ctx context.Context, platformID uuid.UUID) ([]*domain.LTIUser, error)
+
+	// CreateLaunch stores a verified launch.
+	CreateLaunch(ctx context.Context, launch *domain.LTILaunch) error
+
+	// GetLaunch retrieves a launch by ID, returning
+	// domain.ErrLTILaunchNotFound if there is none.
+	GetLaunch(ctx context.Context, id uuid.UUID) (*domain.LTILaunch, error)
+
+	// AddScore records a job submitted from a graded launch.
+	AddScore(ctx context.Context, jobID, launchID uuid.UUID) error
+
+	// ListFinishedScores returns up to limit scores whose job has reached a
+	// terminal status, oldest first, with the job's status and score.
+	ListFinishedScores(ctx context.Context, limit int) ([]*domain.LTIScore, error)
+
+	// RemoveScore deletes a score once it has been posted.
+	RemoveScore(ctx context.Context, jobID uuid.UUID) error
+}
+
+// LTILoginStore holds OIDC logins between the redirect to the platform and
+// the launch it posts back.
+type LTILoginStore interface {
+	// PutLogin stores a login under its state for ttl.
+	PutLogin(ctx context.Context, state string, login *domain.LTILogin, ttl time.Duration) error
+
+	// TakeLogin removes and returns the login stored under state, returning
+	// domain.ErrInvalidLTILaunch if there is none, so a launch cannot be
+	// replayed.
+	TakeLogin(ctx context.Context, state string) (*domain.LTILogin, error)
+}
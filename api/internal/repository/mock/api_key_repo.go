@@ -0,0 +1,109 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockAPIKeyRepository implements repository.APIKeyRepository.
+var _ repository.APIKeyRepository = (*MockAPIKeyRepository)(nil)
+
+type storedAPIKey struct {
+	key  domain.APIKey
+	hash []byte
+}
+
+// MockAPIKeyRepository is an in-memory mock of the API key repository for testing.
+type MockAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*storedAPIKey
+}
+
+// NewMockAPIKeyRepository creates a new mock API key repository.
+func NewMockAPIKeyRepository() *MockAPIKeyRepository {
+	return &MockAPIKeyRepository{keys: make(map[uuid.UUID]*storedAPIKey)}
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey, hash []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key.CreatedAt = time.Now().UTC()
+	m.keys[key.KeyID] = &storedAPIKey{key: *key, hash: hash}
+	return nil
+}
+
+func (m *MockAPIKeyRepository) Get(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.keys[id]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	key := s.key
+	return &key, nil
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, hash []byte) (*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.keys {
+		if bytes.Equal(s.hash, hash) {
+			key := s.key
+			return &key, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *MockAPIKeyRepository) List(ctx context.Context, tenantID string) ([]*domain.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := []*domain.APIKey{}
+	for _, s := range m.keys {
+		if tenantID == "" || s.key.TenantID == tenantID {
+			key := s.key
+			keys = append(keys, &key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i].KeyID[:], keys[j].KeyID[:]) < 0
+	})
+	return keys, nil
+}
+
+func (m *MockAPIKeyRepository) Rotate(ctx context.Context, id uuid.UUID, prefix string, hash []byte) (*domain.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.keys[id]
+	if !ok || s.key.RevokedAt != nil {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	now := time.Now().UTC()
+	s.key.Prefix = prefix
+	s.key.RotatedAt = &now
+	s.hash = hash
+	key := s.key
+	return &key, nil
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.keys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotFound
+	}
+	if s.key.RevokedAt == nil {
+		now := time.Now().UTC()
+		s.key.RevokedAt = &now
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockAppealRepository implements repository.AppealRepository.
+var _ repository.AppealRepository = (*MockAppealRepository)(nil)
+
+// MockAppealRepository is an in-memory mock of the appeal repository for
+// testing. Overrides are applied to the jobs of the given job repository.
+type MockAppealRepository struct {
+	mu      sync.RWMutex
+	appeals map[uuid.UUID]*domain.Appeal
+	jobs    *MockJobRepository
+
+	// Hook functions for injecting errors
+	CreateFunc          func(ctx context.Context, appeal *domain.Appeal) error
+	OverrideVerdictFunc func(ctx context.Context, o *domain.VerdictOverride) error
+}
+
+// NewMockAppealRepository creates a new mock appeal repository backed by jobs.
+func NewMockAppealRepository(jobs *MockJobRepository) *MockAppealRepository {
+	return &MockAppealRepository{
+		appeals: make(map[uuid.UUID]*domain.Appeal),
+		jobs:    jobs,
+	}
+}
+
+func (m *MockAppealRepository) Create(ctx context.Context, appeal *domain.Appeal) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, appeal)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range m.appeals {
+		if a.JobID == appeal.JobID && a.Status == domain.AppealOpen {
+			return domain.ErrAppealExists
+		}
+	}
+	now := time.Now().UTC()
+	appeal.CreatedAt = now
+	appeal.UpdatedAt = now
+	m.appeals[appeal.AppealID] = appeal
+	return nil
+}
+
+func (m *MockAppealRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Appeal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	appeal, ok := m.appeals[id]
+	if !ok {
+		return nil, domain.ErrAppealNotFound
+	}
+	copied := *appeal
+	return &copied, nil
+}
+
+func (m *MockAppealRepository) List(ctx context.Context, tenantID string, status domain.AppealStatus) ([]*domain.Appeal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Appeal
+	for _, a := range m.appeals {
+		if a.Status == status && (tenantID == "" || a.TenantID == tenantID) {
+			copied := *a
+			copied.Overrides = nil
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (m *MockAppealRepository) OverrideVerdict(ctx context.Context, o *domain.VerdictOverride) error {
+	if m.OverrideVerdictFunc != nil {
+		return m.OverrideVerdictFunc(ctx, o)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	appeal, ok := m.appeals[o.AppealID]
+	if !ok {
+		return domain.ErrAppealNotFound
+	}
+
+	m.jobs.mu.Lock()
+	defer m.jobs.mu.Unlock()
+	job, ok := m.jobs.jobs[o.JobID]
+	if !ok {
+		return domain.ErrJobNotFound
+	}
+	job.Status = o.Status
+	job.Score = o.Score
+
+	o.CreatedAt = time.Now().UTC()
+	appeal.Overrides = append(appeal.Overrides, *o)
+	appeal.UpdatedAt = o.CreatedAt
+	return nil
+}
+
+func (m *MockAppealRepository) Close(ctx context.Context, id uuid.UUID, actor, response string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	appeal, ok := m.appeals[id]
+	if !ok {
+		return domain.ErrAppealNotFound
+	}
+	if appeal.Status != domain.AppealOpen {
+		return domain.ErrAppealClosed
+	}
+	now := time.Now().UTC()
+	appeal.Status = domain.AppealClosed
+	appeal.Response = response
+	appeal.ClosedBy = actor
+	appeal.ClosedAt = &now
+	appeal.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockCancelSignal implements repository.CancelSignal.
+var _ repository.CancelSignal = (*MockCancelSignal)(nil)
+
+// MockCancelSignal records cancellation requests for testing.
+type MockCancelSignal struct {
+	mu        sync.Mutex
+	requested []uuid.UUID
+
+	RequestCancelFn func(ctx context.Context, id uuid.UUID) error
+}
+
+// NewMockCancelSignal creates a new mock cancel signal.
+func NewMockCancelSignal() *MockCancelSignal {
+	return &MockCancelSignal{}
+}
+
+func (m *MockCancelSignal) RequestCancel(ctx context.Context, id uuid.UUID) error {
+	if m.RequestCancelFn != nil {
+		return m.RequestCancelFn(ctx, id)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requested = append(m.requested, id)
+	return nil
+}
+
+// Requested returns the jobs whose cancellation was requested.
+func (m *MockCancelSignal) Requested() []uuid.UUID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]uuid.UUID(nil), m.requested...)
+}
@@ -0,0 +1,68 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockExportRepository implements repository.ExportRepository.
+var _ repository.ExportRepository = (*MockExportRepository)(nil)
+
+// MockExportRepository is an in-memory mock of the export repository for
+// testing. Facts are added as they would be recorded, already hashed; the
+// salt passed to ListFacts is ignored.
+type MockExportRepository struct {
+	mu      sync.RWMutex
+	facts   []*domain.JobFact
+	cursors map[string]int64
+
+	ListFactsFn func(ctx context.Context, afterEventID int64, settledBefore time.Time, salt string, limit int) ([]*domain.JobFact, error)
+}
+
+// NewMockExportRepository creates a new mock export repository.
+func NewMockExportRepository() *MockExportRepository {
+	return &MockExportRepository{cursors: make(map[string]int64)}
+}
+
+// AddFact records a verdict. Facts must be added in event order.
+func (m *MockExportRepository) AddFact(fact *domain.JobFact) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.facts = append(m.facts, fact)
+}
+
+func (m *MockExportRepository) ListFacts(ctx context.Context, afterEventID int64, settledBefore time.Time, salt string, limit int) ([]*domain.JobFact, error) {
+	if m.ListFactsFn != nil {
+		return m.ListFactsFn(ctx, afterEventID, settledBefore, salt, limit)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var facts []*domain.JobFact
+	for _, f := range m.facts {
+		if len(facts) == limit {
+			break
+		}
+		if f.EventID > afterEventID && f.FinishedAt.Before(settledBefore) {
+			fact := *f
+			facts = append(facts, &fact)
+		}
+	}
+	return facts, nil
+}
+
+func (m *MockExportRepository) GetCursor(ctx context.Context, sink string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cursors[sink], nil
+}
+
+func (m *MockExportRepository) SetCursor(ctx context.Context, sink string, eventID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursors[sink] = eventID
+	return nil
+}
@@ -0,0 +1,120 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockGitHubRepository implements repository.GitHubRepository.
+var _ repository.GitHubRepository = (*MockGitHubRepository)(nil)
+
+// MockGitHubRepository is an in-memory mock of the GitHub repository for
+// testing. Checks read their job's status from jobs, standing in for the
+// join the PostgreSQL implementation does.
+type MockGitHubRepository struct {
+	mu     sync.RWMutex
+	jobs   repository.JobRepository
+	repos  map[string]*domain.GitHubRepo
+	checks []*domain.GitHubCheck
+}
+
+// NewMockGitHubRepository creates a new mock GitHub repository reading job
+// statuses from jobs.
+func NewMockGitHubRepository(jobs repository.JobRepository) *MockGitHubRepository {
+	return &MockGitHubRepository{
+		jobs:  jobs,
+		repos: make(map[string]*domain.GitHubRepo),
+	}
+}
+
+func (m *MockGitHubRepository) PutRepo(ctx context.Context, repo *domain.GitHubRepo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	repo.CreatedAt = now
+	if existing, ok := m.repos[repo.Repo]; ok {
+		if existing.TenantID != repo.TenantID {
+			return domain.ErrGitHubRepoTaken
+		}
+		repo.CreatedAt = existing.CreatedAt
+	}
+	repo.UpdatedAt = now
+	stored := *repo
+	m.repos[repo.Repo] = &stored
+	return nil
+}
+
+func (m *MockGitHubRepository) GetRepo(ctx context.Context, repo string) (*domain.GitHubRepo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.repos[repo]
+	if !ok {
+		return nil, domain.ErrGitHubRepoNotFound
+	}
+	found := *stored
+	return &found, nil
+}
+
+func (m *MockGitHubRepository) DeleteRepo(ctx context.Context, tenantID, repo string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.repos[repo]
+	if !ok || stored.TenantID != tenantID {
+		return domain.ErrGitHubRepoNotFound
+	}
+	delete(m.repos, repo)
+	return nil
+}
+
+func (m *MockGitHubRepository) AddCheck(ctx context.Context, check *domain.GitHubCheck) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *check
+	m.checks = append(m.checks, &stored)
+	return nil
+}
+
+func (m *MockGitHubRepository) ListFinishedChecks(ctx context.Context, limit int) ([]*domain.GitHubCheck, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var finished []*domain.GitHubCheck
+	for _, c := range m.checks {
+		if len(finished) == limit {
+			break
+		}
+		job, err := m.jobs.GetByID(ctx, c.JobID)
+		if err != nil || !job.Status.IsTerminal() {
+			continue
+		}
+		check := *c
+		check.Status = job.Status
+		check.Score = job.Score
+		finished = append(finished, &check)
+	}
+	return finished, nil
+}
+
+func (m *MockGitHubRepository) RemoveCheck(ctx context.Context, jobID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, c := range m.checks {
+		if c.JobID == jobID {
+			m.checks = append(m.checks[:i], m.checks[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Checks returns a copy of the pending checks.
+func (m *MockGitHubRepository) Checks() []*domain.GitHubCheck {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*domain.GitHubCheck(nil), m.checks...)
+}
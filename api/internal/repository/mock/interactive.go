@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockInteractiveStore implements repository.InteractiveStore.
+var _ repository.InteractiveStore = (*MockInteractiveStore)(nil)
+
+// MockInteractiveStore is an in-memory interactive store for testing.
+type MockInteractiveStore struct {
+	mu     sync.Mutex
+	stdin  map[uuid.UUID][]string
+	closed map[uuid.UUID]bool
+	output map[uuid.UUID][]string
+}
+
+// NewMockInteractiveStore creates a new mock interactive store.
+func NewMockInteractiveStore() *MockInteractiveStore {
+	return &MockInteractiveStore{
+		stdin:  make(map[uuid.UUID][]string),
+		closed: make(map[uuid.UUID]bool),
+		output: make(map[uuid.UUID][]string),
+	}
+}
+
+func (m *MockInteractiveStore) SendStdin(ctx context.Context, id uuid.UUID, data string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stdin[id] = append(m.stdin[id], data)
+	return nil
+}
+
+func (m *MockInteractiveStore) CloseStdin(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed[id] = true
+	return nil
+}
+
+// ReadOutput uses the number of chunks read so far as its cursor.
+func (m *MockInteractiveStore) ReadOutput(ctx context.Context, id uuid.UUID, cursor string) (string, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, _ := strconv.Atoi(cursor)
+	var out string
+	for _, chunk := range m.output[id][n:] {
+		out += chunk
+	}
+	return out, strconv.Itoa(len(m.output[id])), nil
+}
+
+// WriteOutput appends stdout as the worker would (for test setup).
+func (m *MockInteractiveStore) WriteOutput(id uuid.UUID, data string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.output[id] = append(m.output[id], data)
+}
+
+// Stdin returns the input sent to a job and whether it was closed (for test assertions).
+func (m *MockInteractiveStore) Stdin(id uuid.UUID) ([]string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.stdin[id]...), m.closed[id]
+}
@@ -1,8 +1,11 @@
 package mock
 
 import (
+	"bytes"
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -15,30 +18,65 @@ var _ repository.JobRepository = (*MockJobRepository)(nil)
 
 // MockJobRepository is an in-memory mock of the job repository for testing.
 type MockJobRepository struct {
-	mu   sync.RWMutex
-	jobs map[uuid.UUID]*domain.Job
+	mu     sync.RWMutex
+	jobs   map[uuid.UUID]*domain.Job
+	events map[uuid.UUID][]domain.JobEvent
+
+	// Artifacts holds each job's output files by name, as the worker stores them.
+	Artifacts map[uuid.UUID]map[string]*domain.Artifact
 
 	// Hook functions for injecting errors
 	CreateFunc       func(ctx context.Context, job *domain.Job) error
 	GetByIDFunc      func(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	GetFieldsFunc    func(ctx context.Context, id uuid.UUID, fields []string) (*domain.Job, error)
 	UpdateStatusFunc func(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error
 	SetResultFunc    func(ctx context.Context, id uuid.UUID, result *domain.Job) error
+
+	ListOutdatedByProblemFunc func(ctx context.Context, problemID string, version int) ([]*domain.Job, error)
+	ListEventsFunc            func(ctx context.Context, id uuid.UUID) ([]domain.JobEvent, error)
+	GetArtifactFunc           func(ctx context.Context, id uuid.UUID, name string) (*domain.Artifact, error)
+	PrepareRejudgeFunc        func(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+	ListStaleFunc             func(ctx context.Context, statuses []domain.ExecutionStatus, before time.Time, limit int) ([]*domain.Job, error)
+	ResetStaleFunc            func(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus, before time.Time) (*domain.Job, bool, error)
 }
 
 // NewMockJobRepository creates a new mock repository.
 func NewMockJobRepository() *MockJobRepository {
 	return &MockJobRepository{
-		jobs: make(map[uuid.UUID]*domain.Job),
+		jobs:      make(map[uuid.UUID]*domain.Job),
+		events:    make(map[uuid.UUID][]domain.JobEvent),
+		Artifacts: make(map[uuid.UUID]map[string]*domain.Artifact),
 	}
 }
 
+// setStatus changes a job's status and records the change in its timeline,
+// as the database triggers do. The caller holds m.mu.
+func (m *MockJobRepository) setStatus(job *domain.Job, status domain.ExecutionStatus) {
+	if job.Status == status {
+		return
+	}
+	job.Status = status
+	m.events[job.JobID] = append(m.events[job.JobID], domain.JobEvent{Status: status, CreatedAt: time.Now()})
+}
+
 func (m *MockJobRepository) Create(ctx context.Context, job *domain.Job) error {
 	if m.CreateFunc != nil {
 		return m.CreateFunc(ctx, job)
 	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if job.ExternalID != "" {
+		for _, j := range m.jobs {
+			if j.TenantID == job.TenantID && j.ExternalID == job.ExternalID {
+				return domain.ErrExternalIDExists
+			}
+		}
+	}
+	if job.Origin == "" {
+		job.Origin = domain.OriginUser
+	}
 	m.jobs[job.JobID] = job
+	m.events[job.JobID] = append(m.events[job.JobID], domain.JobEvent{Status: job.Status, CreatedAt: time.Now()})
 	return nil
 }
 
@@ -52,7 +90,34 @@ func (m *MockJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 	if !ok {
 		return nil, domain.ErrJobNotFound
 	}
-	return job, nil
+	return copyJob(job), nil
+}
+
+// copyJob returns a copy of a stored job, so callers reading it never share
+// it with writers, as with rows read from the database. The caller holds
+// m.mu.
+func copyJob(job *domain.Job) *domain.Job {
+	c := *job
+	return &c
+}
+
+func (m *MockJobRepository) GetByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, job := range m.jobs {
+		if job.TenantID == tenantID && job.ExternalID == externalID {
+			return copyJob(job), nil
+		}
+	}
+	return nil, domain.ErrJobNotFound
+}
+
+// GetFields returns the whole job; callers project it anyway.
+func (m *MockJobRepository) GetFields(ctx context.Context, id uuid.UUID, fields []string) (*domain.Job, error) {
+	if m.GetFieldsFunc != nil {
+		return m.GetFieldsFunc(ctx, id, fields)
+	}
+	return m.GetByID(ctx, id)
 }
 
 func (m *MockJobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error {
@@ -65,7 +130,7 @@ func (m *MockJobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 	if !ok {
 		return domain.ErrJobNotFound
 	}
-	job.Status = status
+	m.setStatus(job, status)
 	return nil
 }
 
@@ -81,20 +146,290 @@ func (m *MockJobRepository) SetResult(ctx context.Context, id uuid.UUID, result
 	}
 	job.Stdout = result.Stdout
 	job.Stderr = result.Stderr
-	job.Status = result.Status
+	m.setStatus(job, result.Status)
 	job.ExitCode = result.ExitCode
 	job.TimeUsedMs = result.TimeUsedMs
 	job.MemoryUsedKB = result.MemoryUsedKB
+	job.CPUTimeUsedMs = result.CPUTimeUsedMs
+	job.DiskUsedKB = result.DiskUsedKB
+	job.Score = result.Score
+	job.MaxScore = result.MaxScore
 	return nil
 }
 
+func (m *MockJobRepository) ListOutdatedByProblem(ctx context.Context, problemID string, version int) ([]*domain.Job, error) {
+	if m.ListOutdatedByProblemFunc != nil {
+		return m.ListOutdatedByProblemFunc(ctx, problemID, version)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Job
+	for _, j := range m.jobs {
+		if j.ProblemID != problemID || !j.Status.IsTerminal() || j.Status == domain.StatusCancelled {
+			continue
+		}
+		if j.TestDataVersion == nil || *j.TestDataVersion < version {
+			result = append(result, j)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockJobRepository) ListEvents(ctx context.Context, id uuid.UUID) ([]domain.JobEvent, error) {
+	if m.ListEventsFunc != nil {
+		return m.ListEventsFunc(ctx, id)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]domain.JobEvent(nil), m.events[id]...), nil
+}
+
+func (m *MockJobRepository) GetLineage(ctx context.Context, id uuid.UUID, limit int) ([]*domain.LineageEntry, []*domain.LineageEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry := func(job *domain.Job) *domain.LineageEntry {
+		e := &domain.LineageEntry{
+			JobID:         job.JobID,
+			ParentJobID:   job.ParentJobID,
+			Origin:        job.Origin,
+			Status:        job.Status,
+			JudgeRevision: job.JudgeRevision,
+			CreatedAt:     job.CreatedAt,
+		}
+		for _, ev := range m.events[job.JobID] {
+			if ev.Status == domain.StatusQueued {
+				e.Runs++
+			}
+		}
+		return e
+	}
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, nil, domain.ErrJobNotFound
+	}
+	var chain []*domain.LineageEntry
+	for job != nil {
+		chain = append([]*domain.LineageEntry{entry(job)}, chain...)
+		if job.ParentJobID == nil {
+			break
+		}
+		job = m.jobs[*job.ParentJobID]
+	}
+
+	var descendants []*domain.LineageEntry
+	in := map[uuid.UUID]bool{id: true}
+	for grew := true; grew; {
+		grew = false
+		for _, j := range m.jobs {
+			if j.ParentJobID != nil && in[*j.ParentJobID] && !in[j.JobID] {
+				in[j.JobID] = true
+				descendants = append(descendants, entry(j))
+				grew = true
+			}
+		}
+	}
+	sort.Slice(descendants, func(i, k int) bool {
+		if !descendants[i].CreatedAt.Equal(descendants[k].CreatedAt) {
+			return descendants[i].CreatedAt.Before(descendants[k].CreatedAt)
+		}
+		return bytes.Compare(descendants[i].JobID[:], descendants[k].JobID[:]) < 0
+	})
+	if len(descendants) > limit {
+		descendants = descendants[:limit]
+	}
+	return chain, descendants, nil
+}
+
+func (m *MockJobRepository) GetArtifact(ctx context.Context, id uuid.UUID, name string) (*domain.Artifact, error) {
+	if m.GetArtifactFunc != nil {
+		return m.GetArtifactFunc(ctx, id, name)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.Artifacts[id][name]
+	if !ok {
+		return nil, domain.ErrArtifactNotFound
+	}
+	return a, nil
+}
+
+func (m *MockJobRepository) PrepareRejudge(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	if m.PrepareRejudgeFunc != nil {
+		return m.PrepareRejudgeFunc(ctx, id)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, domain.ErrJobNotFound
+	}
+	m.setStatus(job, domain.StatusQueued)
+	job.JudgeRevision++
+	return job, nil
+}
+
+func (m *MockJobRepository) ListStale(ctx context.Context, statuses []domain.ExecutionStatus, before time.Time, limit int) ([]*domain.Job, error) {
+	if m.ListStaleFunc != nil {
+		return m.ListStaleFunc(ctx, statuses, before, limit)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []*domain.Job
+	for _, j := range m.jobs {
+		if !j.UpdatedAt.Before(before) {
+			continue
+		}
+		for _, s := range statuses {
+			if j.Status == s {
+				result = append(result, j)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].UpdatedAt.Before(result[b].UpdatedAt) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockJobRepository) ResetStale(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus, before time.Time) (*domain.Job, bool, error) {
+	if m.ResetStaleFunc != nil {
+		return m.ResetStaleFunc(ctx, id, status, before)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok || job.Status != status || !job.UpdatedAt.Before(before) {
+		return nil, false, nil
+	}
+	m.setStatus(job, domain.StatusQueued)
+	job.UpdatedAt = time.Now()
+	return job, true, nil
+}
+
+func (m *MockJobRepository) CancelQueued(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok || (job.Status != domain.StatusQueued && job.Status != domain.StatusScheduled) {
+		return false, nil
+	}
+	m.setStatus(job, domain.StatusCancelled)
+	job.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (m *MockJobRepository) CancelMatching(ctx context.Context, filter domain.BulkCancelFilter, limit int, dryRun bool) ([]*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var matched []*domain.Job
+	for _, j := range m.jobs {
+		if j.Status != filter.Status ||
+			filter.TenantID != "" && j.TenantID != filter.TenantID ||
+			filter.ProblemID != "" && j.ProblemID != filter.ProblemID ||
+			filter.CreatedBefore != nil && !j.CreatedAt.Before(*filter.CreatedBefore) {
+			continue
+		}
+		matched = append(matched, j)
+	}
+	sort.Slice(matched, func(a, b int) bool { return matched[a].CreatedAt.Before(matched[b].CreatedAt) })
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	result := make([]*domain.Job, len(matched))
+	for i, j := range matched {
+		if !dryRun {
+			m.setStatus(j, domain.StatusCancelled)
+			j.UpdatedAt = time.Now()
+		}
+		result[i] = &domain.Job{JobID: j.JobID, JudgeRevision: j.JudgeRevision}
+	}
+	return result, nil
+}
+
+func (m *MockJobRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*domain.Job
+	for _, j := range m.jobs {
+		if j.Status == domain.StatusScheduled && j.RunAt != nil && !j.RunAt.After(now) {
+			due = append(due, j)
+		}
+	}
+	sort.Slice(due, func(a, b int) bool { return due[a].RunAt.Before(*due[b].RunAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, j := range due {
+		m.setStatus(j, domain.StatusQueued)
+		j.UpdatedAt = now
+	}
+	return due, nil
+}
+
+func (m *MockJobRepository) DeleteFinished(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok || !job.Status.IsTerminal() {
+		return false, nil
+	}
+	delete(m.jobs, id)
+	delete(m.events, id)
+	delete(m.Artifacts, id)
+	return true, nil
+}
+
+func (m *MockJobRepository) ListRecent(ctx context.Context, filter domain.JobFilter, after uuid.UUID, limit int) ([]*domain.JobSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var jobs []*domain.JobSummary
+	for _, j := range m.jobs {
+		if filter.Status != "" && j.Status != filter.Status {
+			continue
+		}
+		if filter.TenantID != "" && j.TenantID != filter.TenantID {
+			continue
+		}
+		if filter.APIKeyID != nil && (j.APIKeyID == nil || *j.APIKeyID != *filter.APIKeyID) {
+			continue
+		}
+		if filter.UserID != nil && (j.UserID == nil || *j.UserID != *filter.UserID) {
+			continue
+		}
+		if after != uuid.Nil && bytes.Compare(j.JobID[:], after[:]) >= 0 {
+			continue
+		}
+		jobs = append(jobs, &domain.JobSummary{
+			JobID:        j.JobID,
+			TenantID:     j.TenantID,
+			Language:     j.Language,
+			Status:       j.Status,
+			ProblemID:    j.ProblemID,
+			Score:        j.Score,
+			MaxScore:     j.MaxScore,
+			TimeUsedMs:   j.TimeUsedMs,
+			MemoryUsedKB: j.MemoryUsedKB,
+			CreatedAt:    j.CreatedAt,
+			UpdatedAt:    j.UpdatedAt,
+		})
+	}
+	sort.Slice(jobs, func(a, b int) bool { return bytes.Compare(jobs[a].JobID[:], jobs[b].JobID[:]) > 0 })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
 // GetAll returns all stored jobs (for test assertions).
 func (m *MockJobRepository) GetAll() []*domain.Job {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	result := make([]*domain.Job, 0, len(m.jobs))
 	for _, j := range m.jobs {
-		result = append(result, j)
+		result = append(result, copyJob(j))
 	}
 	return result
 }
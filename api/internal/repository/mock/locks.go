@@ -0,0 +1,76 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockLockStore implements repository.LockStore.
+var _ repository.LockStore = (*MockLockStore)(nil)
+
+// MockLockStore is an in-memory store of worker processing locks for testing.
+type MockLockStore struct {
+	mu    sync.RWMutex
+	locks map[uuid.UUID]time.Time
+
+	AcquiredAtFunc func(ctx context.Context, lockID uuid.UUID) (time.Time, bool, error)
+	TombstoneFunc  func(ctx context.Context, lockID uuid.UUID, ttl time.Duration) (bool, error)
+}
+
+// NewMockLockStore creates a new mock lock store.
+func NewMockLockStore() *MockLockStore {
+	return &MockLockStore{locks: make(map[uuid.UUID]time.Time)}
+}
+
+// SetLock records a lock taken at acquiredAt (for test setup).
+func (m *MockLockStore) SetLock(lockID uuid.UUID, acquiredAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locks[lockID] = acquiredAt.Truncate(time.Second)
+}
+
+// Held reports whether a lock exists (for test assertions).
+func (m *MockLockStore) Held(lockID uuid.UUID) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.locks[lockID]
+	return ok
+}
+
+func (m *MockLockStore) AcquiredAt(ctx context.Context, lockID uuid.UUID) (time.Time, bool, error) {
+	if m.AcquiredAtFunc != nil {
+		return m.AcquiredAtFunc(ctx, lockID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	at, ok := m.locks[lockID]
+	return at, ok, nil
+}
+
+func (m *MockLockStore) DeleteIfAcquiredAt(ctx context.Context, lockID uuid.UUID, acquiredAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if at, ok := m.locks[lockID]; !ok || !at.Equal(acquiredAt) {
+		return false, nil
+	}
+	delete(m.locks, lockID)
+	return true, nil
+}
+
+func (m *MockLockStore) Tombstone(ctx context.Context, lockID uuid.UUID, ttl time.Duration) (bool, error) {
+	if m.TombstoneFunc != nil {
+		return m.TombstoneFunc(ctx, lockID, ttl)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.locks[lockID]; ok {
+		return false, nil
+	}
+	m.locks[lockID] = time.Now().Truncate(time.Second)
+	return true, nil
+}
@@ -0,0 +1,244 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockLTIRepository implements repository.LTIRepository.
+var _ repository.LTIRepository = (*MockLTIRepository)(nil)
+
+type mockLTIScore struct {
+	jobID    uuid.UUID
+	launchID uuid.UUID
+}
+
+// MockLTIRepository is an in-memory mock of the LTI repository for testing.
+// Scores read their job's status from jobs, standing in for the join the
+// PostgreSQL implementation does.
+type MockLTIRepository struct {
+	mu        sync.RWMutex
+	jobs      repository.JobRepository
+	platforms map[uuid.UUID]*domain.LTIPlatform
+	users     map[uuid.UUID]map[string]*domain.LTIUser
+	launches  map[uuid.UUID]*domain.LTILaunch
+	scores    []mockLTIScore
+}
+
+// NewMockLTIRepository creates a new mock LTI repository reading job
+// statuses from jobs.
+func NewMockLTIRepository(jobs repository.JobRepository) *MockLTIRepository {
+	return &MockLTIRepository{
+		jobs:      jobs,
+		platforms: make(map[uuid.UUID]*domain.LTIPlatform),
+		users:     make(map[uuid.UUID]map[string]*domain.LTIUser),
+		launches:  make(map[uuid.UUID]*domain.LTILaunch),
+	}
+}
+
+func (m *MockLTIRepository) CreatePlatform(ctx context.Context, p *domain.LTIPlatform) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.platforms {
+		if existing.Issuer == p.Issuer && existing.ClientID == p.ClientID {
+			return domain.ErrLTIPlatformTaken
+		}
+	}
+	p.CreatedAt = time.Now().UTC()
+	stored := *p
+	m.platforms[p.ID] = &stored
+	return nil
+}
+
+func (m *MockLTIRepository) GetPlatform(ctx context.Context, id uuid.UUID) (*domain.LTIPlatform, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.platforms[id]
+	if !ok {
+		return nil, domain.ErrLTIPlatformNotFound
+	}
+	found := *stored
+	return &found, nil
+}
+
+func (m *MockLTIRepository) FindPlatform(ctx context.Context, issuer, clientID string) (*domain.LTIPlatform, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, stored := range m.platforms {
+		if stored.Issuer == issuer && stored.ClientID == clientID {
+			found := *stored
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrLTIPlatformNotFound
+}
+
+func (m *MockLTIRepository) DeletePlatform(ctx context.Context, tenantID string, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.platforms[id]
+	if !ok || stored.TenantID != tenantID {
+		return domain.ErrLTIPlatformNotFound
+	}
+	delete(m.platforms, id)
+	delete(m.users, id)
+	for launchID, launch := range m.launches {
+		if launch.PlatformID == id {
+			delete(m.launches, launchID)
+		}
+	}
+	return nil
+}
+
+func (m *MockLTIRepository) UpsertUser(ctx context.Context, user *domain.LTIUser) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	users, ok := m.users[user.PlatformID]
+	if !ok {
+		users = make(map[string]*domain.LTIUser)
+		m.users[user.PlatformID] = users
+	}
+	if existing, ok := users[user.Subject]; ok {
+		user.TenantID = existing.TenantID
+		user.FirstLaunchAt = existing.FirstLaunchAt
+	} else {
+		user.FirstLaunchAt = now
+	}
+	user.LastLaunchAt = now
+	stored := *user
+	users[user.Subject] = &stored
+	return nil
+}
+
+func (m *MockLTIRepository) ListUsers(ctx context.Context, platformID uuid.UUID) ([]*domain.LTIUser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var users []*domain.LTIUser
+	for _, u := range m.users[platformID] {
+		found := *u
+		users = append(users, &found)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].LastLaunchAt.After(users[j].LastLaunchAt) })
+	return users, nil
+}
+
+func (m *MockLTIRepository) CreateLaunch(ctx context.Context, launch *domain.LTILaunch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	launch.CreatedAt = time.Now().UTC()
+	stored := *launch
+	m.launches[launch.ID] = &stored
+	return nil
+}
+
+func (m *MockLTIRepository) GetLaunch(ctx context.Context, id uuid.UUID) (*domain.LTILaunch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stored, ok := m.launches[id]
+	if !ok {
+		return nil, domain.ErrLTILaunchNotFound
+	}
+	found := *stored
+	found.Graded = found.LineItem != ""
+	return &found, nil
+}
+
+func (m *MockLTIRepository) AddScore(ctx context.Context, jobID, launchID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scores = append(m.scores, mockLTIScore{jobID: jobID, launchID: launchID})
+	return nil
+}
+
+func (m *MockLTIRepository) ListFinishedScores(ctx context.Context, limit int) ([]*domain.LTIScore, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var finished []*domain.LTIScore
+	for _, s := range m.scores {
+		if len(finished) == limit {
+			break
+		}
+		launch, ok := m.launches[s.launchID]
+		if !ok {
+			continue
+		}
+		job, err := m.jobs.GetByID(ctx, s.jobID)
+		if err != nil || !job.Status.IsTerminal() {
+			continue
+		}
+		finished = append(finished, &domain.LTIScore{
+			JobID:      s.jobID,
+			PlatformID: launch.PlatformID,
+			Subject:    launch.Subject,
+			LineItem:   launch.LineItem,
+			Status:     job.Status,
+			Score:      job.Score,
+			MaxScore:   job.MaxScore,
+		})
+	}
+	return finished, nil
+}
+
+func (m *MockLTIRepository) RemoveScore(ctx context.Context, jobID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, s := range m.scores {
+		if s.jobID == jobID {
+			m.scores = append(m.scores[:i], m.scores[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// PendingScores returns how many scores await posting.
+func (m *MockLTIRepository) PendingScores() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.scores)
+}
+
+// Ensure MockLTILoginStore implements repository.LTILoginStore.
+var _ repository.LTILoginStore = (*MockLTILoginStore)(nil)
+
+type mockLTILogin struct {
+	login   domain.LTILogin
+	expires time.Time
+}
+
+// MockLTILoginStore is an in-memory mock of the LTI login store for testing.
+type MockLTILoginStore struct {
+	mu     sync.Mutex
+	logins map[string]mockLTILogin
+}
+
+// NewMockLTILoginStore creates a new mock LTI login store.
+func NewMockLTILoginStore() *MockLTILoginStore {
+	return &MockLTILoginStore{logins: make(map[string]mockLTILogin)}
+}
+
+func (m *MockLTILoginStore) PutLogin(ctx context.Context, state string, login *domain.LTILogin, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logins[state] = mockLTILogin{login: *login, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *MockLTILoginStore) TakeLogin(ctx context.Context, state string) (*domain.LTILogin, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.logins[state]
+	delete(m.logins, state)
+	if !ok || time.Now().After(stored.expires) {
+		return nil, domain.ErrInvalidLTILaunch
+	}
+	return &stored.login, nil
+}
@@ -0,0 +1,84 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockProblemRepository implements repository.ProblemRepository.
+var _ repository.ProblemRepository = (*MockProblemRepository)(nil)
+
+// MockProblemRepository is an in-memory mock of the problem repository for testing.
+type MockProblemRepository struct {
+	mu       sync.RWMutex
+	problems map[string]*domain.Problem
+
+	// Hook functions for injecting errors
+	CreateFunc          func(ctx context.Context, problem *domain.Problem) error
+	GetByIDFunc         func(ctx context.Context, id string) (*domain.Problem, error)
+	ReplaceTestDataFunc func(ctx context.Context, id string, generator *domain.Generator, comparator *domain.Comparator, interactor *domain.Interactor, harnesses []domain.Harness, rules []domain.SourceRule, killPatterns []domain.KillPattern, subtasks []domain.Subtask, cases []domain.TestCase) (int, error)
+}
+
+// NewMockProblemRepository creates a new mock problem repository.
+func NewMockProblemRepository() *MockProblemRepository {
+	return &MockProblemRepository{
+		problems: make(map[string]*domain.Problem),
+	}
+}
+
+func (m *MockProblemRepository) Create(ctx context.Context, problem *domain.Problem) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, problem)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.problems[problem.ProblemID]; ok {
+		return domain.ErrProblemExists
+	}
+	now := time.Now().UTC()
+	problem.TestDataVersion = 1
+	problem.CreatedAt = now
+	problem.UpdatedAt = now
+	m.problems[problem.ProblemID] = problem
+	return nil
+}
+
+func (m *MockProblemRepository) GetByID(ctx context.Context, id string) (*domain.Problem, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, id)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	problem, ok := m.problems[id]
+	if !ok {
+		return nil, domain.ErrProblemNotFound
+	}
+	return problem, nil
+}
+
+func (m *MockProblemRepository) ReplaceTestData(ctx context.Context, id string, generator *domain.Generator, comparator *domain.Comparator, interactor *domain.Interactor, harnesses []domain.Harness, rules []domain.SourceRule, killPatterns []domain.KillPattern, subtasks []domain.Subtask, cases []domain.TestCase) (int, error) {
+	if m.ReplaceTestDataFunc != nil {
+		return m.ReplaceTestDataFunc(ctx, id, generator, comparator, interactor, harnesses, rules, killPatterns, subtasks, cases)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	problem, ok := m.problems[id]
+	if !ok {
+		return 0, domain.ErrProblemNotFound
+	}
+	problem.TestDataVersion++
+	problem.TestCases = cases
+	problem.Subtasks = subtasks
+	problem.Generator = generator
+	problem.Comparator = comparator
+	problem.Interactor = interactor
+	problem.Harnesses = harnesses
+	problem.Rules = rules
+	problem.KillPatterns = killPatterns
+	problem.UpdatedAt = time.Now().UTC()
+	return problem.TestDataVersion, nil
+}
@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockQueuePauseStore implements repository.QueuePauseStore.
+var _ repository.QueuePauseStore = (*MockQueuePauseStore)(nil)
+
+// MockQueuePauseStore is an in-memory store of paused languages for testing.
+type MockQueuePauseStore struct {
+	mu     sync.Mutex
+	paused map[domain.Language]time.Time
+}
+
+// NewMockQueuePauseStore creates a new mock queue pause store.
+func NewMockQueuePauseStore() *MockQueuePauseStore {
+	return &MockQueuePauseStore{paused: make(map[domain.Language]time.Time)}
+}
+
+func (m *MockQueuePauseStore) Pause(ctx context.Context, lang domain.Language) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.paused[lang]; !ok {
+		m.paused[lang] = time.Now().UTC()
+	}
+	return m.paused[lang], nil
+}
+
+func (m *MockQueuePauseStore) Resume(ctx context.Context, lang domain.Language) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.paused[lang]
+	delete(m.paused, lang)
+	return ok, nil
+}
+
+func (m *MockQueuePauseStore) List(ctx context.Context) (map[domain.Language]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	paused := make(map[domain.Language]time.Time, len(m.paused))
+	for lang, at := range m.paused {
+		paused[lang] = at
+	}
+	return paused, nil
+}
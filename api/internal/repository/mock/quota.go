@@ -0,0 +1,45 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockQuotaStore implements repository.QuotaStore.
+var _ repository.QuotaStore = (*MockQuotaStore)(nil)
+
+// MockQuotaStore is an in-memory quota store for testing.
+type MockQuotaStore struct {
+	mu    sync.RWMutex
+	usage map[string]time.Duration
+
+	UsageFunc func(ctx context.Context, tenantID string) (time.Duration, error)
+}
+
+// NewMockQuotaStore creates a new mock quota store.
+func NewMockQuotaStore() *MockQuotaStore {
+	return &MockQuotaStore{usage: make(map[string]time.Duration)}
+}
+
+// SetUsage records usage for a tenant (for test setup).
+func (m *MockQuotaStore) SetUsage(tenantID string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage[tenantID] = d
+}
+
+func (m *MockQuotaStore) Usage(ctx context.Context, tenantID string) (time.Duration, error) {
+	if m.UsageFunc != nil {
+		return m.UsageFunc(ctx, tenantID)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.usage[tenantID], nil
+}
+
+func (m *MockQuotaStore) ResetAt(now time.Time) time.Time {
+	return now.Add(time.Hour)
+}
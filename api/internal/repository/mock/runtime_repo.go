@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockRuntimeRepository implements repository.RuntimeRepository.
+var _ repository.RuntimeRepository = (*MockRuntimeRepository)(nil)
+
+// MockRuntimeRepository is an in-memory mock of the runtime repository for testing.
+type MockRuntimeRepository struct {
+	mu       sync.RWMutex
+	runtimes map[domain.Language]*domain.Runtime
+
+	// Hook functions for injecting errors
+	GetByNameFunc func(ctx context.Context, name domain.Language) (*domain.Runtime, error)
+}
+
+// NewMockRuntimeRepository creates a new mock runtime repository.
+func NewMockRuntimeRepository() *MockRuntimeRepository {
+	return &MockRuntimeRepository{
+		runtimes: make(map[domain.Language]*domain.Runtime),
+	}
+}
+
+func (m *MockRuntimeRepository) Create(ctx context.Context, rt *domain.Runtime) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.runtimes[rt.Name]; ok {
+		return domain.ErrRuntimeExists
+	}
+	now := time.Now().UTC()
+	rt.CreatedAt = now
+	rt.UpdatedAt = now
+	m.runtimes[rt.Name] = rt
+	return nil
+}
+
+func (m *MockRuntimeRepository) GetByName(ctx context.Context, name domain.Language) (*domain.Runtime, error) {
+	if m.GetByNameFunc != nil {
+		return m.GetByNameFunc(ctx, name)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rt, ok := m.runtimes[name]
+	if !ok {
+		return nil, domain.ErrRuntimeNotFound
+	}
+	return rt, nil
+}
+
+func (m *MockRuntimeRepository) List(ctx context.Context) ([]*domain.Runtime, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	runtimes := make([]*domain.Runtime, 0, len(m.runtimes))
+	for _, rt := range m.runtimes {
+		runtimes = append(runtimes, rt)
+	}
+	sort.Slice(runtimes, func(i, j int) bool { return runtimes[i].Name < runtimes[j].Name })
+	return runtimes, nil
+}
+
+func (m *MockRuntimeRepository) Update(ctx context.Context, rt *domain.Runtime) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.runtimes[rt.Name]
+	if !ok {
+		return domain.ErrRuntimeNotFound
+	}
+	rt.CreatedAt = existing.CreatedAt
+	rt.UpdatedAt = time.Now().UTC()
+	m.runtimes[rt.Name] = rt
+	return nil
+}
+
+func (m *MockRuntimeRepository) Delete(ctx context.Context, name domain.Language) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.runtimes[name]; !ok {
+		return domain.ErrRuntimeNotFound
+	}
+	delete(m.runtimes, name)
+	return nil
+}
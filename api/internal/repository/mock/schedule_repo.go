@@ -0,0 +1,134 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockScheduleRepository implements repository.ScheduleRepository.
+var _ repository.ScheduleRepository = (*MockScheduleRepository)(nil)
+
+// MockScheduleRepository is an in-memory mock of the schedule repository for testing.
+type MockScheduleRepository struct {
+	mu        sync.RWMutex
+	schedules map[uuid.UUID]*domain.Schedule
+	jobs      repository.JobRepository
+}
+
+// NewMockScheduleRepository creates a new mock schedule repository. The
+// last run's status is read from jobs, as the database joins it.
+func NewMockScheduleRepository(jobs repository.JobRepository) *MockScheduleRepository {
+	return &MockScheduleRepository{
+		schedules: make(map[uuid.UUID]*domain.Schedule),
+		jobs:      jobs,
+	}
+}
+
+// view returns a copy of s with its last run's status. The caller holds m.mu.
+func (m *MockScheduleRepository) view(ctx context.Context, s *domain.Schedule) *domain.Schedule {
+	out := *s
+	out.LastStatus = ""
+	if s.LastJobID != nil {
+		if job, err := m.jobs.GetByID(ctx, *s.LastJobID); err == nil {
+			out.LastStatus = job.Status
+		}
+	}
+	return &out
+}
+
+func (m *MockScheduleRepository) Create(ctx context.Context, s *domain.Schedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	stored := *s
+	m.schedules[s.ScheduleID] = &stored
+	return nil
+}
+
+func (m *MockScheduleRepository) Get(ctx context.Context, tenantID string, id uuid.UUID) (*domain.Schedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.schedules[id]
+	if !ok || s.TenantID != tenantID {
+		return nil, domain.ErrScheduleNotFound
+	}
+	return m.view(ctx, s), nil
+}
+
+func (m *MockScheduleRepository) List(ctx context.Context, tenantID string) ([]*domain.Schedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	schedules := []*domain.Schedule{}
+	for _, s := range m.schedules {
+		if s.TenantID == tenantID {
+			schedules = append(schedules, m.view(ctx, s))
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules, nil
+}
+
+func (m *MockScheduleRepository) Delete(ctx context.Context, tenantID string, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schedules[id]
+	if !ok || s.TenantID != tenantID {
+		return domain.ErrScheduleNotFound
+	}
+	delete(m.schedules, id)
+	return nil
+}
+
+func (m *MockScheduleRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.Schedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var due []*domain.Schedule
+	for _, s := range m.schedules {
+		if !s.NextRunAt.After(now) {
+			due = append(due, m.view(ctx, s))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextRunAt.Before(due[j].NextRunAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (m *MockScheduleRepository) Advance(ctx context.Context, id uuid.UUID, due, next time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schedules[id]
+	if !ok || !s.NextRunAt.Equal(due) {
+		return false, nil
+	}
+	s.NextRunAt = next
+	s.UpdatedAt = time.Now().UTC()
+	return true, nil
+}
+
+func (m *MockScheduleRepository) RecordRun(ctx context.Context, id uuid.UUID, run *domain.ScheduleRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schedules[id]
+	if !ok {
+		return nil
+	}
+	at := run.At
+	s.LastRunAt = &at
+	s.LastJobID = run.JobID
+	s.LastError = run.Error
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
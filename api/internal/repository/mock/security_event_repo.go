@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockSecurityEventRepository implements repository.SecurityEventRepository.
+var _ repository.SecurityEventRepository = (*MockSecurityEventRepository)(nil)
+
+// MockSecurityEventRepository is an in-memory mock of the security event
+// repository for testing.
+type MockSecurityEventRepository struct {
+	mu     sync.RWMutex
+	events []*domain.SecurityEvent
+}
+
+// NewMockSecurityEventRepository creates a new mock security event repository.
+func NewMockSecurityEventRepository() *MockSecurityEventRepository {
+	return &MockSecurityEventRepository{}
+}
+
+// AddEvent records an event as a worker would, numbering it.
+func (m *MockSecurityEventRepository) AddEvent(event *domain.SecurityEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.EventID = int64(len(m.events) + 1)
+	m.events = append(m.events, event)
+}
+
+func (m *MockSecurityEventRepository) List(ctx context.Context, filter domain.SecurityEventFilter, afterEventID int64, settledBefore time.Time, limit int) ([]*domain.SecurityEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var events []*domain.SecurityEvent
+	for _, e := range m.events {
+		if len(events) == limit {
+			break
+		}
+		if e.EventID <= afterEventID || !e.CreatedAt.Before(settledBefore) ||
+			filter.Kind != "" && e.Kind != filter.Kind ||
+			filter.TenantID != "" && e.TenantID != filter.TenantID {
+			continue
+		}
+		event := *e
+		events = append(events, &event)
+	}
+	return events, nil
+}
@@ -0,0 +1,60 @@
+package mock
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockStatusFeed implements repository.StatusFeed.
+var _ repository.StatusFeed = (*MockStatusFeed)(nil)
+
+// MockStatusFeed is a status feed for testing whose changes are announced
+// by calling Notify.
+type MockStatusFeed struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID][]chan struct{}
+}
+
+// NewMockStatusFeed creates a new mock status feed.
+func NewMockStatusFeed() *MockStatusFeed {
+	return &MockStatusFeed{subs: make(map[uuid.UUID][]chan struct{})}
+}
+
+func (m *MockStatusFeed) Subscribe(id uuid.UUID) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	m.mu.Lock()
+	m.subs[id] = append(m.subs[id], ch)
+	m.mu.Unlock()
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, sub := range m.subs[id] {
+			if sub == ch {
+				m.subs[id] = append(m.subs[id][:i], m.subs[id][i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Notify announces a status change of the job to its subscribers.
+func (m *MockStatusFeed) Notify(id uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ch := range m.subs[id] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribers returns how many subscriptions to the job are open.
+func (m *MockStatusFeed) Subscribers(id uuid.UUID) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.subs[id])
+}
@@ -0,0 +1,66 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockUserRepository implements repository.UserRepository.
+var _ repository.UserRepository = (*MockUserRepository)(nil)
+
+type storedUser struct {
+	user domain.User
+	hash []byte
+}
+
+// MockUserRepository is an in-memory mock of the user repository for testing.
+type MockUserRepository struct {
+	mu       sync.RWMutex
+	users    map[string]*storedUser  // by email
+	external map[string]*domain.User // by issuer and subject
+}
+
+// NewMockUserRepository creates a new mock user repository.
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{users: make(map[string]*storedUser), external: make(map[string]*domain.User)}
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *domain.User, passwordHash []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[user.Email]; ok {
+		return domain.ErrUserExists
+	}
+	user.CreatedAt = time.Now().UTC()
+	m.users[user.Email] = &storedUser{user: *user, hash: passwordHash}
+	return nil
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, []byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.users[email]
+	if !ok {
+		return nil, nil, domain.ErrUserNotFound
+	}
+	user := s.user
+	return &user, s.hash, nil
+}
+
+func (m *MockUserRepository) UpsertExternal(ctx context.Context, user *domain.User, issuer, subject string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := issuer + " " + subject
+	if s, ok := m.external[key]; ok {
+		user.UserID, user.CreatedAt = s.UserID, s.CreatedAt
+	} else {
+		user.CreatedAt = time.Now().UTC()
+	}
+	stored := *user
+	m.external[key] = &stored
+	return nil
+}
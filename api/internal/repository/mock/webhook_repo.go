@@ -0,0 +1,240 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure MockWebhookRepository implements repository.WebhookRepository.
+var _ repository.WebhookRepository = (*MockWebhookRepository)(nil)
+
+// MockWebhookRepository is an in-memory mock of the webhook repository for testing.
+type MockWebhookRepository struct {
+	mu         sync.RWMutex
+	hooks      map[string]*domain.DLQWebhook
+	results    map[string]*domain.ResultWebhook
+	deliveries map[int64]*domain.WebhookDelivery
+	lastID     int64
+}
+
+// NewMockWebhookRepository creates a new mock webhook repository.
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		hooks:      make(map[string]*domain.DLQWebhook),
+		results:    make(map[string]*domain.ResultWebhook),
+		deliveries: make(map[int64]*domain.WebhookDelivery),
+	}
+}
+
+func (m *MockWebhookRepository) PutDLQWebhook(ctx context.Context, hook *domain.DLQWebhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	hook.CreatedAt = now
+	if existing, ok := m.hooks[hook.TenantID]; ok {
+		hook.CreatedAt = existing.CreatedAt
+	}
+	hook.UpdatedAt = now
+	stored := *hook
+	m.hooks[hook.TenantID] = &stored
+	return nil
+}
+
+func (m *MockWebhookRepository) GetDLQWebhook(ctx context.Context, tenantID string) (*domain.DLQWebhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hook, ok := m.hooks[tenantID]
+	if !ok {
+		return nil, domain.ErrWebhookNotFound
+	}
+	found := *hook
+	return &found, nil
+}
+
+func (m *MockWebhookRepository) DeleteDLQWebhook(ctx context.Context, tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.hooks[tenantID]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	delete(m.hooks, tenantID)
+	return nil
+}
+
+func (m *MockWebhookRepository) PutResultWebhook(ctx context.Context, hook *domain.ResultWebhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	hook.CreatedAt = now
+	if existing, ok := m.results[hook.TenantID]; ok {
+		hook.CreatedAt = existing.CreatedAt
+	}
+	hook.UpdatedAt = now
+	hook.ConsecutiveFailures = 0
+	hook.CircuitOpenUntil = nil
+	stored := *hook
+	m.results[hook.TenantID] = &stored
+	return nil
+}
+
+func (m *MockWebhookRepository) GetResultWebhook(ctx context.Context, tenantID string) (*domain.ResultWebhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hook, ok := m.results[tenantID]
+	if !ok {
+		return nil, domain.ErrWebhookNotFound
+	}
+	found := *hook
+	return &found, nil
+}
+
+func (m *MockWebhookRepository) DeleteResultWebhook(ctx context.Context, tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.results[tenantID]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	delete(m.results, tenantID)
+	for _, d := range m.deliveries {
+		if d.TenantID == tenantID && d.Status == domain.DeliveryPending {
+			d.Status = domain.DeliveryFailed
+			d.NextAttemptAt = nil
+			d.LastError = "webhook removed"
+		}
+	}
+	return nil
+}
+
+// RecordDelivery stands in for the database trigger: it records a pending
+// delivery of a finished job if its tenant has a result webhook.
+func (m *MockWebhookRepository) RecordDelivery(job *domain.Job) *domain.WebhookDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.results[job.TenantID]; !ok {
+		return nil
+	}
+	m.lastID++
+	now := time.Now().UTC()
+	d := &domain.WebhookDelivery{
+		ID:            m.lastID,
+		TenantID:      job.TenantID,
+		JobID:         job.JobID,
+		JobStatus:     job.Status,
+		Status:        domain.DeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	m.deliveries[d.ID] = d
+	found := *d
+	return &found
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []*domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		hook, ok := m.results[d.TenantID]
+		if !ok || d.Status != domain.DeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		if hook.CircuitOpenUntil != nil && hook.CircuitOpenUntil.After(now) {
+			continue
+		}
+		due = append(due, d)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*domain.WebhookDelivery, len(due))
+	for i, d := range due {
+		next := now.Add(lease)
+		d.NextAttemptAt = &next
+		found := *d
+		claimed[i] = &found
+	}
+	return claimed, nil
+}
+
+func (m *MockWebhookRepository) RecordDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt, hook *domain.ResultWebhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.deliveries[delivery.ID]
+	if !ok {
+		return domain.ErrDeliveryNotFound
+	}
+	history := append(d.History, attempt)
+	*d = *delivery
+	d.History = history
+	d.UpdatedAt = time.Now().UTC()
+	if stored, ok := m.results[hook.TenantID]; ok {
+		stored.ConsecutiveFailures = hook.ConsecutiveFailures
+		stored.CircuitOpenUntil = hook.CircuitOpenUntil
+	}
+	return nil
+}
+
+func (m *MockWebhookRepository) DeferDelivery(ctx context.Context, id int64, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d, ok := m.deliveries[id]; ok && d.Status == domain.DeliveryPending {
+		d.NextAttemptAt = &until
+	}
+	return nil
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, tenantID string, status domain.DeliveryStatus, before int64, limit int) ([]*domain.WebhookDelivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var list []*domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.TenantID != tenantID || (status != "" && d.Status != status) || (before != 0 && d.ID >= before) {
+			continue
+		}
+		found := *d
+		found.History = nil
+		list = append(list, &found)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (m *MockWebhookRepository) GetDelivery(ctx context.Context, tenantID string, id int64) (*domain.WebhookDelivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	d, ok := m.deliveries[id]
+	if !ok || d.TenantID != tenantID {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	found := *d
+	found.History = append([]*domain.WebhookAttempt(nil), d.History...)
+	return &found, nil
+}
+
+func (m *MockWebhookRepository) RedriveDelivery(ctx context.Context, tenantID string, id int64, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d, ok := m.deliveries[id]
+	if !ok || d.TenantID != tenantID {
+		return domain.ErrDeliveryNotFound
+	}
+	if d.Status == domain.DeliveryPending {
+		return domain.ErrDeliveryPending
+	}
+	d.Status = domain.DeliveryPending
+	d.Attempts = 0
+	d.Redrives++
+	d.NextAttemptAt = &now
+	d.DeliveredAt = nil
+	return nil
+}
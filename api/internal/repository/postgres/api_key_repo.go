@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgAPIKeyRepo implements repository.APIKeyRepository.
+var _ repository.APIKeyRepository = (*pgAPIKeyRepo)(nil)
+
+type pgAPIKeyRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAPIKeyRepository creates a new PostgreSQL-backed API key repository.
+func NewPostgresAPIKeyRepository(pool *pgxpool.Pool) repository.APIKeyRepository {
+	return &pgAPIKeyRepo{pool: pool}
+}
+
+// apiKeyColumns are the columns scanAPIKey reads.
+const apiKeyColumns = `key_id, tenant_id, name, prefix, scopes, created_at, rotated_at, revoked_at`
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	k := &domain.APIKey{}
+	if err := row.Scan(&k.KeyID, &k.TenantID, &k.Name, &k.Prefix, &k.Scopes, &k.CreatedAt, &k.RotatedAt, &k.RevokedAt); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (r *pgAPIKeyRepo) Create(ctx context.Context, key *domain.APIKey, hash []byte) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO api_keys (key_id, tenant_id, name, prefix, scopes, key_hash)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at`,
+		key.KeyID, key.TenantID, key.Name, key.Prefix, key.Scopes, hash,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("postgres: create api key: %w", err)
+	}
+	return nil
+}
+
+func (r *pgAPIKeyRepo) Get(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	return r.get(ctx, "get api key", `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_id = $1`, id)
+}
+
+func (r *pgAPIKeyRepo) GetByHash(ctx context.Context, hash []byte) (*domain.APIKey, error) {
+	return r.get(ctx, "get api key by hash", `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash)
+}
+
+func (r *pgAPIKeyRepo) get(ctx context.Context, op, query string, arg any) (*domain.APIKey, error) {
+	k, err := scanAPIKey(r.pool.QueryRow(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("postgres: %s: %w", op, err)
+	}
+	return k, nil
+}
+
+func (r *pgAPIKeyRepo) List(ctx context.Context, tenantID string) ([]*domain.APIKey, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE $1 = '' OR tenant_id = $1
+		ORDER BY created_at, key_id`, tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *pgAPIKeyRepo) Rotate(ctx context.Context, id uuid.UUID, prefix string, hash []byte) (*domain.APIKey, error) {
+	k, err := scanAPIKey(r.pool.QueryRow(ctx, `
+		UPDATE api_keys SET prefix = $2, key_hash = $3, rotated_at = NOW()
+		WHERE key_id = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns, id, prefix, hash,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("postgres: rotate api key: %w", err)
+	}
+	return k, nil
+}
+
+func (r *pgAPIKeyRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE key_id = $1`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgAppealRepo implements repository.AppealRepository.
+var _ repository.AppealRepository = (*pgAppealRepo)(nil)
+
+type pgAppealRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAppealRepository creates a new PostgreSQL-backed appeal repository.
+func NewPostgresAppealRepository(pool *pgxpool.Pool) repository.AppealRepository {
+	return &pgAppealRepo{pool: pool}
+}
+
+// appealColumns is the column list shared by every query that scans an appeal.
+const appealColumns = `appeal_id, job_id, tenant_id, status, note, COALESCE(response, ''),
+		       COALESCE(closed_by, ''), closed_at, created_at, updated_at`
+
+func scanAppeal(row pgx.Row) (*domain.Appeal, error) {
+	a := &domain.Appeal{}
+	err := row.Scan(
+		&a.AppealID, &a.JobID, &a.TenantID, &a.Status, &a.Note, &a.Response,
+		&a.ClosedBy, &a.ClosedAt, &a.CreatedAt, &a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (r *pgAppealRepo) Create(ctx context.Context, appeal *domain.Appeal) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO submission_appeals (appeal_id, job_id, tenant_id, status, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at`,
+		appeal.AppealID, appeal.JobID, appeal.TenantID, appeal.Status, appeal.Note,
+	).Scan(&appeal.CreatedAt, &appeal.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.ErrAppealExists
+		}
+		return fmt.Errorf("postgres: create appeal: %w", err)
+	}
+	return nil
+}
+
+func (r *pgAppealRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Appeal, error) {
+	appeal, err := scanAppeal(r.pool.QueryRow(ctx, `SELECT `+appealColumns+` FROM submission_appeals WHERE appeal_id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAppealNotFound
+		}
+		return nil, fmt.Errorf("postgres: get appeal: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT override_id, appeal_id, job_id, previous_status, status,
+		       previous_score, score, reason, actor, created_at
+		FROM verdict_overrides
+		WHERE appeal_id = $1
+		ORDER BY created_at`, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get overrides: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o domain.VerdictOverride
+		if err := rows.Scan(&o.OverrideID, &o.AppealID, &o.JobID, &o.PreviousStatus, &o.Status,
+			&o.PreviousScore, &o.Score, &o.Reason, &o.Actor, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("postgres: scan override: %w", err)
+		}
+		appeal.Overrides = append(appeal.Overrides, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: get overrides: %w", err)
+	}
+	return appeal, nil
+}
+
+func (r *pgAppealRepo) List(ctx context.Context, tenantID string, status domain.AppealStatus) ([]*domain.Appeal, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+appealColumns+`
+		FROM submission_appeals
+		WHERE status = $1 AND ($2 = '' OR tenant_id = $2)
+		ORDER BY created_at`, status, tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list appeals: %w", err)
+	}
+	defer rows.Close()
+
+	var appeals []*domain.Appeal
+	for rows.Next() {
+		appeal, err := scanAppeal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan appeal: %w", err)
+		}
+		appeals = append(appeals, appeal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list appeals: %w", err)
+	}
+	return appeals, nil
+}
+
+func (r *pgAppealRepo) OverrideVerdict(ctx context.Context, o *domain.VerdictOverride) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres: begin override: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now().UTC()
+	tag, err := tx.Exec(ctx, `
+		UPDATE execution_jobs SET status = $1, score = $2, updated_at = $3
+		WHERE job_id = $4`,
+		o.Status, o.Score, now, o.JobID,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: apply override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO verdict_overrides (override_id, appeal_id, job_id, previous_status, status,
+		                               previous_score, score, reason, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at`,
+		o.OverrideID, o.AppealID, o.JobID, o.PreviousStatus, o.Status,
+		o.PreviousScore, o.Score, o.Reason, o.Actor, now,
+	).Scan(&o.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("postgres: record override: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE submission_appeals SET updated_at = $1 WHERE appeal_id = $2`, now, o.AppealID); err != nil {
+		return fmt.Errorf("postgres: touch appeal: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit override: %w", err)
+	}
+	return nil
+}
+
+func (r *pgAppealRepo) Close(ctx context.Context, id uuid.UUID, actor, response string) error {
+	now := time.Now().UTC()
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE submission_appeals
+		SET status = 'closed', response = $1, closed_by = $2, closed_at = $3, updated_at = $3
+		WHERE appeal_id = $4 AND status = 'open'`,
+		response, actor, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: close appeal: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		// Distinguish a missing appeal from one that was already closed.
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrAppealClosed
+	}
+	return nil
+}
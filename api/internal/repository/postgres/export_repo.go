@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgExportRepo implements repository.ExportRepository.
+var _ repository.ExportRepository = (*pgExportRepo)(nil)
+
+type pgExportRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresExportRepository creates a new PostgreSQL-backed export repository.
+func NewPostgresExportRepository(pool *pgxpool.Pool) repository.ExportRepository {
+	return &pgExportRepo{pool: pool}
+}
+
+func (r *pgExportRepo) ListFacts(ctx context.Context, afterEventID int64, settledBefore time.Time, salt string, limit int) ([]*domain.JobFact, error) {
+	// A verdict's run started at the latest QUEUED event before it; the
+	// worker picked it up at the first event after that. The job's columns
+	// hold its latest verdict, so a verdict since superseded by a rejudge
+	// is exported with the newer result.
+	rows, err := r.pool.Query(ctx, `
+		SELECT e.event_id, j.job_id, j.tenant_id, COALESCE(j.problem_id, ''), j.judge_revision,
+			j.language, e.status,
+			encode(hmac(COALESCE(j.source_code, b.content), $3, 'sha256'), 'hex'), octet_length(COALESCE(j.source_code, b.content)),
+			j.score, j.max_score, j.time_used_ms, j.cpu_time_used_ms, j.memory_used_kb,
+			COALESCE(q.created_at, j.created_at), s.created_at, e.created_at
+		FROM job_events e
+		JOIN execution_jobs j ON j.job_id = e.job_id
+		LEFT JOIN source_blobs b ON b.hash = j.source_hash
+		LEFT JOIN LATERAL (
+			SELECT event_id, created_at FROM job_events
+			WHERE job_id = e.job_id AND event_id < e.event_id AND status = 'QUEUED'
+			ORDER BY event_id DESC LIMIT 1
+		) q ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT created_at FROM job_events
+			WHERE job_id = e.job_id AND event_id > COALESCE(q.event_id, 0) AND event_id < e.event_id
+			ORDER BY event_id LIMIT 1
+		) s ON TRUE
+		WHERE e.event_id > $1
+		  AND e.created_at < $2
+		  AND e.status NOT IN ('SCHEDULED', 'QUEUED', 'COMPILING', 'RUNNING')
+		ORDER BY e.event_id
+		LIMIT $4`,
+		afterEventID, settledBefore, salt, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list job facts: %w", err)
+	}
+	defer rows.Close()
+
+	var facts []*domain.JobFact
+	for rows.Next() {
+		f := &domain.JobFact{}
+		if err := rows.Scan(&f.EventID, &f.JobID, &f.TenantID, &f.ProblemID, &f.JudgeRevision,
+			&f.Language, &f.Status, &f.SourceHash, &f.SourceBytes,
+			&f.Score, &f.MaxScore, &f.TimeUsedMs, &f.CPUTimeUsedMs, &f.MemoryUsedKB,
+			&f.QueuedAt, &f.StartedAt, &f.FinishedAt); err != nil {
+			return nil, fmt.Errorf("postgres: scan job fact: %w", err)
+		}
+		facts = append(facts, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list job facts: %w", err)
+	}
+	return facts, nil
+}
+
+func (r *pgExportRepo) GetCursor(ctx context.Context, sink string) (int64, error) {
+	var eventID int64
+	err := r.pool.QueryRow(ctx, `SELECT event_id FROM export_cursors WHERE sink = $1`, sink).Scan(&eventID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("postgres: get export cursor: %w", err)
+	}
+	return eventID, nil
+}
+
+func (r *pgExportRepo) SetCursor(ctx context.Context, sink string, eventID int64) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO export_cursors (sink, event_id) VALUES ($1, $2)
+		ON CONFLICT (sink) DO UPDATE SET event_id = EXCLUDED.event_id, updated_at = NOW()`,
+		sink, eventID,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: set export cursor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgGitHubRepo implements repository.GitHubRepository.
+var _ repository.GitHubRepository = (*pgGitHubRepo)(nil)
+
+type pgGitHubRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresGitHubRepository creates a new PostgreSQL-backed GitHub repository.
+func NewPostgresGitHubRepository(pool *pgxpool.Pool) repository.GitHubRepository {
+	return &pgGitHubRepo{pool: pool}
+}
+
+func (r *pgGitHubRepo) PutRepo(ctx context.Context, repo *domain.GitHubRepo) error {
+	// The conditional update leaves another tenant's row alone, in which
+	// case nothing is returned.
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO github_repos (repo, tenant_id, language, problem_id, path, secret, token)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+		ON CONFLICT (repo) DO UPDATE SET
+			language = EXCLUDED.language, problem_id = EXCLUDED.problem_id,
+			path = EXCLUDED.path, secret = EXCLUDED.secret, token = EXCLUDED.token
+		WHERE github_repos.tenant_id = EXCLUDED.tenant_id
+		RETURNING created_at, updated_at`,
+		repo.Repo, repo.TenantID, repo.Language, repo.ProblemID, repo.Path, repo.Secret, repo.Token,
+	).Scan(&repo.CreatedAt, &repo.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrGitHubRepoTaken
+		}
+		return fmt.Errorf("postgres: put github repo: %w", err)
+	}
+	return nil
+}
+
+func (r *pgGitHubRepo) GetRepo(ctx context.Context, name string) (*domain.GitHubRepo, error) {
+	repo := &domain.GitHubRepo{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT repo, tenant_id, language, COALESCE(problem_id, ''), path, secret, token, created_at, updated_at
+		FROM github_repos
+		WHERE repo = $1`, name,
+	).Scan(&repo.Repo, &repo.TenantID, &repo.Language, &repo.ProblemID, &repo.Path,
+		&repo.Secret, &repo.Token, &repo.CreatedAt, &repo.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrGitHubRepoNotFound
+		}
+		return nil, fmt.Errorf("postgres: get github repo: %w", err)
+	}
+	return repo, nil
+}
+
+func (r *pgGitHubRepo) DeleteRepo(ctx context.Context, tenantID, repo string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM github_repos WHERE repo = $1 AND tenant_id = $2`, repo, tenantID)
+	if err != nil {
+		return fmt.Errorf("postgres: delete github repo: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrGitHubRepoNotFound
+	}
+	return nil
+}
+
+func (r *pgGitHubRepo) AddCheck(ctx context.Context, check *domain.GitHubCheck) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO github_checks (job_id, repo, sha) VALUES ($1, $2, $3)`,
+		check.JobID, check.Repo, check.SHA,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: add github check: %w", err)
+	}
+	return nil
+}
+
+func (r *pgGitHubRepo) ListFinishedChecks(ctx context.Context, limit int) ([]*domain.GitHubCheck, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.job_id, c.repo, c.sha, j.status, j.score
+		FROM github_checks c
+		JOIN execution_jobs j ON j.job_id = c.job_id
+		WHERE j.status NOT IN ($1, $2, $3, $4)
+		ORDER BY c.created_at
+		LIMIT $5`,
+		domain.StatusScheduled, domain.StatusQueued, domain.StatusCompiling, domain.StatusRunning, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list github checks: %w", err)
+	}
+	defer rows.Close()
+
+	var checks []*domain.GitHubCheck
+	for rows.Next() {
+		check := &domain.GitHubCheck{}
+		if err := rows.Scan(&check.JobID, &check.Repo, &check.SHA, &check.Status, &check.Score); err != nil {
+			return nil, fmt.Errorf("postgres: scan github check: %w", err)
+		}
+		checks = append(checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list github checks: %w", err)
+	}
+	return checks, nil
+}
+
+func (r *pgGitHubRepo) RemoveCheck(ctx context.Context, jobID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM github_checks WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("postgres: remove github check: %w", err)
+	}
+	return nil
+}
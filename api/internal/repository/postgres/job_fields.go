@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// jobFieldColumn maps a domain.JobFields entry to its column and scan
+// target. JSON columns are scanned as bytes and decoded into the target.
+type jobFieldColumn struct {
+	expr string
+	dest func(job *domain.Job) any
+	json bool
+}
+
+var jobFieldColumns = map[string]jobFieldColumn{
+	"job_id":               {expr: "job_id", dest: func(j *domain.Job) any { return &j.JobID }},
+	"tenant_id":            {expr: "tenant_id", dest: func(j *domain.Job) any { return &j.TenantID }},
+	"language":             {expr: "language", dest: func(j *domain.Job) any { return &j.Language }},
+	"source_code":          {expr: jobSource, dest: func(j *domain.Job) any { return &j.SourceCode }},
+	"stdin":                {expr: "stdin", dest: func(j *domain.Job) any { return &j.Stdin }},
+	"stdout":               {expr: "stdout", dest: func(j *domain.Job) any { return &j.Stdout }},
+	"stderr":               {expr: "stderr", dest: func(j *domain.Job) any { return &j.Stderr }},
+	"status":               {expr: "status", dest: func(j *domain.Job) any { return &j.Status }},
+	"exit_code":            {expr: "exit_code", dest: func(j *domain.Job) any { return &j.ExitCode }},
+	"time_used_ms":         {expr: "time_used_ms", dest: func(j *domain.Job) any { return &j.TimeUsedMs }},
+	"memory_used_kb":       {expr: "memory_used_kb", dest: func(j *domain.Job) any { return &j.MemoryUsedKB }},
+	"time_limit_ms":        {expr: "time_limit_ms", dest: func(j *domain.Job) any { return &j.TimeLimitMs }},
+	"memory_limit_kb":      {expr: "memory_limit_kb", dest: func(j *domain.Job) any { return &j.MemoryLimitKB }},
+	"wall_time_limit_ms":   {expr: "time_limit_ms", dest: func(j *domain.Job) any { return &j.WallTimeLimitMs }},
+	"cpu_time_limit_ms":    {expr: "COALESCE(cpu_time_limit_ms, 0)", dest: func(j *domain.Job) any { return &j.CPUTimeLimitMs }},
+	"pids_limit":           {expr: "COALESCE(pids_limit, 0)", dest: func(j *domain.Job) any { return &j.PidsLimit }},
+	"cpu_time_used_ms":     {expr: "cpu_time_used_ms", dest: func(j *domain.Job) any { return &j.CPUTimeUsedMs }},
+	"disk_used_kb":         {expr: "disk_used_kb", dest: func(j *domain.Job) any { return &j.DiskUsedKB }},
+	"compile_time_ms":      {expr: "compile_time_ms", dest: func(j *domain.Job) any { return &j.CompileTimeMs }},
+	"timings":              {expr: "timings", dest: func(j *domain.Job) any { return &j.Timings }, json: true},
+	"compiler_flags":       {expr: "compiler_flags", dest: func(j *domain.Job) any { return &j.CompilerFlags }},
+	"args":                 {expr: "args", dest: func(j *domain.Job) any { return &j.Args }},
+	"env":                  {expr: "env", dest: func(j *domain.Job) any { return &j.Env }, json: true},
+	"sandbox_tier":         {expr: "COALESCE(sandbox_tier, '')", dest: func(j *domain.Job) any { return &j.SandboxTier }},
+	"network_policy":       {expr: "COALESCE(network_policy, '')", dest: func(j *domain.Job) any { return &j.NetworkPolicy }},
+	"output_files":         {expr: "output_files", dest: func(j *domain.Job) any { return &j.OutputFiles }},
+	"interactive":          {expr: "interactive", dest: func(j *domain.Job) any { return &j.Interactive }},
+	"expected_output":      {expr: "expected_output", dest: func(j *domain.Job) any { return &j.ExpectedOutput }},
+	"compare_mode":         {expr: "COALESCE(compare_mode, '')", dest: func(j *domain.Job) any { return &j.CompareMode }},
+	"problem_id":           {expr: "COALESCE(problem_id, '')", dest: func(j *domain.Job) any { return &j.ProblemID }},
+	"test_data_version":    {expr: "test_data_version", dest: func(j *domain.Job) any { return &j.TestDataVersion }},
+	"judge_revision":       {expr: "judge_revision", dest: func(j *domain.Job) any { return &j.JudgeRevision }},
+	"test_results":         {expr: "test_results", dest: func(j *domain.Job) any { return &j.TestResults }, json: true},
+	"termination_strategy": {expr: "COALESCE(termination_strategy, '')", dest: func(j *domain.Job) any { return &j.TerminationStrategy }},
+	"score":                {expr: "score", dest: func(j *domain.Job) any { return &j.Score }},
+	"max_score":            {expr: "max_score", dest: func(j *domain.Job) any { return &j.MaxScore }},
+	"subtask_results":      {expr: "subtask_results", dest: func(j *domain.Job) any { return &j.SubtaskResults }, json: true},
+	"solution_code":        {expr: "COALESCE(solution_code, '')", dest: func(j *domain.Job) any { return &j.SolutionCode }},
+	"priority":             {expr: "priority", dest: func(j *domain.Job) any { return &j.Priority }},
+	"run_at":               {expr: "run_at", dest: func(j *domain.Job) any { return &j.RunAt }},
+	"origin":               {expr: "origin", dest: func(j *domain.Job) any { return &j.Origin }},
+	"parent_job_id":        {expr: "parent_job_id", dest: func(j *domain.Job) any { return &j.ParentJobID }},
+	"external_id":          {expr: "COALESCE(external_id, '')", dest: func(j *domain.Job) any { return &j.ExternalID }},
+	"api_key_id":           {expr: "api_key_id", dest: func(j *domain.Job) any { return &j.APIKeyID }},
+	"user_id":              {expr: "user_id", dest: func(j *domain.Job) any { return &j.UserID }},
+	"created_at":           {expr: "created_at", dest: func(j *domain.Job) any { return &j.CreatedAt }},
+	"updated_at":           {expr: "updated_at", dest: func(j *domain.Job) any { return &j.UpdatedAt }},
+}
+
+// GetFields selects only the columns behind fields, so pollers asking for
+// the status skip reading source code and outputs.
+func (r *pgJobRepo) GetFields(ctx context.Context, id uuid.UUID, fields []string) (*domain.Job, error) {
+	job := &domain.Job{}
+	exprs := make([]string, 0, len(fields))
+	dests := make([]any, 0, len(fields))
+	var decode []func() error
+	for _, f := range fields {
+		col, ok := jobFieldColumns[f]
+		if !ok {
+			return nil, fmt.Errorf("postgres: unknown job field %q", f)
+		}
+		exprs = append(exprs, col.expr)
+		target := col.dest(job)
+		if !col.json {
+			dests = append(dests, target)
+			continue
+		}
+		raw := new([]byte)
+		dests = append(dests, raw)
+		decode = append(decode, func() error {
+			if len(*raw) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(*raw, target); err != nil {
+				return fmt.Errorf("decode %s: %w", f, err)
+			}
+			return nil
+		})
+	}
+
+	query := `SELECT ` + strings.Join(exprs, ", ") + ` FROM execution_jobs WHERE job_id = $1`
+	if err := r.pool.QueryRow(ctx, query, id).Scan(dests...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("postgres: get job fields: %w", err)
+	}
+	for _, d := range decode {
+		if err := d(); err != nil {
+			return nil, fmt.Errorf("postgres: %w", err)
+		}
+	}
+	return job, nil
+}
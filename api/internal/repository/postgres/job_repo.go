@@ -2,12 +2,16 @@ package postgres
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/Harsh-BH/Sentinel/api/internal/domain"
@@ -18,48 +22,123 @@ import (
 var _ repository.JobRepository = (*pgJobRepo)(nil)
 
 type pgJobRepo struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	retry RetryPolicy
 }
 
 // NewPostgresJobRepository creates a new PostgreSQL-backed job repository.
-func NewPostgresJobRepository(pool *pgxpool.Pool) repository.JobRepository {
-	return &pgJobRepo{pool: pool}
+// Status and result writes, which are safe to repeat, are retried under
+// retry when the database goes away, as it does during a failover.
+func NewPostgresJobRepository(pool *pgxpool.Pool, retry RetryPolicy) repository.JobRepository {
+	return &pgJobRepo{pool: pool, retry: retry}
 }
 
 func (r *pgJobRepo) Create(ctx context.Context, job *domain.Job) error {
+	// The source is stored once per content; a concurrent purge of the
+	// last job sharing it can still delete the blob first, failing this
+	// insert's foreign key check.
 	query := `
-		INSERT INTO execution_jobs (job_id, language, source_code, stdin, status, time_limit_ms, memory_limit_kb, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		WITH blob AS (
+			INSERT INTO source_blobs (hash, content, size_bytes) VALUES ($4, $31, octet_length($31))
+			ON CONFLICT (hash) DO NOTHING
+		)
+		INSERT INTO execution_jobs (job_id, tenant_id, language, source_hash, stdin, status, time_limit_ms, memory_limit_kb, compiler_flags, args, env, problem_id, sandbox_tier, cpu_time_limit_ms, pids_limit, network_policy, output_files, interactive, expected_output, compare_mode, solution_code, priority, run_at, origin, parent_job_id, external_id, api_key_id, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30)`
 
+	var env []byte
+	if len(job.Env) > 0 {
+		var err error
+		if env, err = json.Marshal(job.Env); err != nil {
+			return fmt.Errorf("postgres: encode env: %w", err)
+		}
+	}
+
+	origin := job.Origin
+	if origin == "" {
+		origin = domain.OriginUser
+	}
+
+	hash := sha256.Sum256([]byte(job.SourceCode))
 	now := time.Now().UTC()
 	_, err := r.pool.Exec(ctx, query,
-		job.JobID, job.Language, job.SourceCode, job.Stdin,
-		job.Status, job.TimeLimitMs, job.MemoryLimitKB, now, now,
+		job.JobID, job.TenantID, job.Language, hash[:], job.Stdin,
+		job.Status, job.TimeLimitMs, job.MemoryLimitKB, job.CompilerFlags, job.Args, env, nullableText(job.ProblemID), nullableText(job.SandboxTier), nullableInt(job.CPUTimeLimitMs), nullableInt(job.PidsLimit), nullableText(job.NetworkPolicy), job.OutputFiles, job.Interactive, job.ExpectedOutput, nullableText(string(job.CompareMode)), nullableText(job.SolutionCode), job.Priority, job.RunAt, origin, job.ParentJobID, nullableText(job.ExternalID), job.APIKeyID, job.UserID, now, now,
+		job.SourceCode,
 	)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == "idx_jobs_tenant_external_id" {
+			return domain.ErrExternalIDExists
+		}
 		return fmt.Errorf("postgres: create job: %w", err)
 	}
+	job.Origin = origin
 	job.CreatedAt = now
 	job.UpdatedAt = now
 	return nil
 }
 
-func (r *pgJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
-	query := `
-		SELECT job_id, language, source_code, stdin, stdout, stderr, status,
-		       exit_code, time_used_ms, memory_used_kb, time_limit_ms, memory_limit_kb,
-		       created_at, updated_at
-		FROM execution_jobs
-		WHERE job_id = $1`
+// jobSource reads a job's source from its blob, or from source_code for a
+// job created before sources were content-addressed and not yet moved.
+const jobSource = `COALESCE(execution_jobs.source_code,
+		       (SELECT content FROM source_blobs WHERE hash = execution_jobs.source_hash))`
 
+// jobColumns is the column list shared by every query that scans a full job.
+const jobColumns = `job_id, tenant_id, language, ` + jobSource + `, stdin, stdout, stderr, status,
+		       exit_code, time_used_ms, memory_used_kb, time_limit_ms, memory_limit_kb, compiler_flags, args, env,
+		       COALESCE(sandbox_tier, ''), COALESCE(problem_id, ''), test_data_version, judge_revision, test_results,
+		       score, max_score, subtask_results, COALESCE(termination_strategy, ''),
+		       COALESCE(cpu_time_limit_ms, 0), cpu_time_used_ms, COALESCE(pids_limit, 0), disk_used_kb, compile_time_ms, timings,
+		       COALESCE(network_policy, ''), output_files, interactive, expected_output,
+		       COALESCE(compare_mode, ''), COALESCE(solution_code, ''), priority, run_at, origin, parent_job_id,
+		       COALESCE(external_id, ''), api_key_id, user_id, created_at, updated_at`
+
+func scanJob(row pgx.Row) (*domain.Job, error) {
 	job := &domain.Job{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&job.JobID, &job.Language, &job.SourceCode, &job.Stdin,
+	var env, testResults, subtaskResults, timings []byte
+	err := row.Scan(
+		&job.JobID, &job.TenantID, &job.Language, &job.SourceCode, &job.Stdin,
 		&job.Stdout, &job.Stderr, &job.Status,
 		&job.ExitCode, &job.TimeUsedMs, &job.MemoryUsedKB,
-		&job.TimeLimitMs, &job.MemoryLimitKB,
-		&job.CreatedAt, &job.UpdatedAt,
+		&job.TimeLimitMs, &job.MemoryLimitKB, &job.CompilerFlags, &job.Args, &env,
+		&job.SandboxTier, &job.ProblemID, &job.TestDataVersion, &job.JudgeRevision, &testResults,
+		&job.Score, &job.MaxScore, &subtaskResults, &job.TerminationStrategy,
+		&job.CPUTimeLimitMs, &job.CPUTimeUsedMs, &job.PidsLimit, &job.DiskUsedKB, &job.CompileTimeMs, &timings,
+		&job.NetworkPolicy, &job.OutputFiles, &job.Interactive, &job.ExpectedOutput,
+		&job.CompareMode, &job.SolutionCode, &job.Priority, &job.RunAt, &job.Origin, &job.ParentJobID,
+		&job.ExternalID, &job.APIKeyID, &job.UserID, &job.CreatedAt, &job.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	job.WallTimeLimitMs = job.TimeLimitMs
+	if len(env) > 0 {
+		if err := json.Unmarshal(env, &job.Env); err != nil {
+			return nil, fmt.Errorf("decode env: %w", err)
+		}
+	}
+	if len(testResults) > 0 {
+		if err := json.Unmarshal(testResults, &job.TestResults); err != nil {
+			return nil, fmt.Errorf("decode test results: %w", err)
+		}
+	}
+	if len(subtaskResults) > 0 {
+		if err := json.Unmarshal(subtaskResults, &job.SubtaskResults); err != nil {
+			return nil, fmt.Errorf("decode subtask results: %w", err)
+		}
+	}
+	if len(timings) > 0 {
+		if err := json.Unmarshal(timings, &job.Timings); err != nil {
+			return nil, fmt.Errorf("decode timings: %w", err)
+		}
+	}
+	return job, nil
+}
+
+func (r *pgJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM execution_jobs WHERE job_id = $1`
+
+	job, err := scanJob(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrJobNotFound
@@ -69,9 +148,26 @@ func (r *pgJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Job, err
 	return job, nil
 }
 
+func (r *pgJobRepo) GetByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM execution_jobs WHERE tenant_id = $1 AND external_id = $2`
+
+	job, err := scanJob(r.pool.QueryRow(ctx, query, tenantID, externalID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("postgres: get job by external id: %w", err)
+	}
+	return job, nil
+}
+
 func (r *pgJobRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error {
 	query := `UPDATE execution_jobs SET status = $1, updated_at = $2 WHERE job_id = $3`
-	tag, err := r.pool.Exec(ctx, query, status, time.Now().UTC(), id)
+	var tag pgconn.CommandTag
+	err := r.retry.do(ctx, r.pool, func() (err error) {
+		tag, err = r.pool.Exec(ctx, query, status, time.Now().UTC(), id)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("postgres: update status: %w", err)
 	}
@@ -85,13 +181,17 @@ func (r *pgJobRepo) SetResult(ctx context.Context, id uuid.UUID, result *domain.
 	query := `
 		UPDATE execution_jobs
 		SET stdout = $1, stderr = $2, status = $3, exit_code = $4,
-		    time_used_ms = $5, memory_used_kb = $6, updated_at = $7
-		WHERE job_id = $8`
+		    time_used_ms = $5, memory_used_kb = $6, cpu_time_used_ms = $7, disk_used_kb = $8, updated_at = $9
+		WHERE job_id = $10`
 
-	tag, err := r.pool.Exec(ctx, query,
-		result.Stdout, result.Stderr, result.Status, result.ExitCode,
-		result.TimeUsedMs, result.MemoryUsedKB, time.Now().UTC(), id,
-	)
+	var tag pgconn.CommandTag
+	err := r.retry.do(ctx, r.pool, func() (err error) {
+		tag, err = r.pool.Exec(ctx, query,
+			result.Stdout, result.Stderr, result.Status, result.ExitCode,
+			result.TimeUsedMs, result.MemoryUsedKB, result.CPUTimeUsedMs, result.DiskUsedKB, time.Now().UTC(), id,
+		)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("postgres: set result: %w", err)
 	}
@@ -100,3 +200,409 @@ func (r *pgJobRepo) SetResult(ctx context.Context, id uuid.UUID, result *domain.
 	}
 	return nil
 }
+
+func (r *pgJobRepo) ListOutdatedByProblem(ctx context.Context, problemID string, version int) ([]*domain.Job, error) {
+	query := `SELECT ` + jobColumns + `
+		FROM execution_jobs
+		WHERE problem_id = $1
+		  AND status NOT IN ('SCHEDULED', 'QUEUED', 'COMPILING', 'RUNNING', 'CANCELLED')
+		  AND (test_data_version IS NULL OR test_data_version < $2)
+		ORDER BY created_at`
+
+	rows, err := r.pool.Query(ctx, query, problemID, version)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list outdated jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan outdated job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list outdated jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *pgJobRepo) ListEvents(ctx context.Context, id uuid.UUID) ([]domain.JobEvent, error) {
+	query := `SELECT status, created_at, COALESCE(reason, '') FROM job_events WHERE job_id = $1 ORDER BY event_id`
+
+	rows, err := r.pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list job events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.JobEvent
+	for rows.Next() {
+		var ev domain.JobEvent
+		if err := rows.Scan(&ev.Status, &ev.CreatedAt, &ev.Reason); err != nil {
+			return nil, fmt.Errorf("postgres: scan job event: %w", err)
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list job events: %w", err)
+	}
+	return events, nil
+}
+
+// lineageColumns are the columns scanLineage reads from a job aliased j. A
+// job runs each time it is queued, which its timeline records.
+const lineageColumns = `j.job_id, j.parent_job_id, j.origin, j.status, j.judge_revision,
+		       (SELECT COUNT(*) FROM job_events e WHERE e.job_id = j.job_id AND e.status = 'QUEUED'),
+		       j.created_at`
+
+func scanLineage(rows pgx.Rows) ([]*domain.LineageEntry, error) {
+	defer rows.Close()
+	var entries []*domain.LineageEntry
+	for rows.Next() {
+		e := &domain.LineageEntry{}
+		if err := rows.Scan(&e.JobID, &e.ParentJobID, &e.Origin, &e.Status, &e.JudgeRevision, &e.Runs, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *pgJobRepo) GetLineage(ctx context.Context, id uuid.UUID, limit int) ([]*domain.LineageEntry, []*domain.LineageEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT job_id, parent_job_id, 0 AS depth FROM execution_jobs WHERE job_id = $1
+			UNION ALL
+			SELECT p.job_id, p.parent_job_id, c.depth + 1
+			FROM execution_jobs p JOIN chain c ON p.job_id = c.parent_job_id
+		)
+		SELECT `+lineageColumns+`
+		FROM chain c JOIN execution_jobs j ON j.job_id = c.job_id
+		ORDER BY c.depth DESC`, id,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgres: get job chain: %w", err)
+	}
+	chain, err := scanLineage(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgres: get job chain: %w", err)
+	}
+	if len(chain) == 0 {
+		return nil, nil, domain.ErrJobNotFound
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		WITH RECURSIVE tree AS (
+			SELECT job_id FROM execution_jobs WHERE parent_job_id = $1
+			UNION ALL
+			SELECT d.job_id FROM execution_jobs d JOIN tree t ON d.parent_job_id = t.job_id
+		)
+		SELECT `+lineageColumns+`
+		FROM tree t JOIN execution_jobs j ON j.job_id = t.job_id
+		ORDER BY j.created_at, j.job_id
+		LIMIT $2`, id, limit,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgres: get job descendants: %w", err)
+	}
+	descendants, err := scanLineage(rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgres: get job descendants: %w", err)
+	}
+	return chain, descendants, nil
+}
+
+func (r *pgJobRepo) GetArtifact(ctx context.Context, id uuid.UUID, name string) (*domain.Artifact, error) {
+	query := `SELECT name, content, size_bytes, truncated, created_at FROM job_artifacts WHERE job_id = $1 AND name = $2`
+
+	a := &domain.Artifact{}
+	err := r.pool.QueryRow(ctx, query, id, name).Scan(&a.Name, &a.Content, &a.SizeBytes, &a.Truncated, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrArtifactNotFound
+		}
+		return nil, fmt.Errorf("postgres: get artifact: %w", err)
+	}
+	return a, nil
+}
+
+func (r *pgJobRepo) PrepareRejudge(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	query := `
+		UPDATE execution_jobs
+		SET status = 'QUEUED', judge_revision = judge_revision + 1, updated_at = $1
+		WHERE job_id = $2
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.pool.QueryRow(ctx, query, time.Now().UTC(), id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("postgres: prepare rejudge: %w", err)
+	}
+	return job, nil
+}
+
+func (r *pgJobRepo) ListStale(ctx context.Context, statuses []domain.ExecutionStatus, before time.Time, limit int) ([]*domain.Job, error) {
+	query := `SELECT ` + jobColumns + `
+		FROM execution_jobs
+		WHERE status = ANY($1) AND updated_at < $2
+		ORDER BY updated_at
+		LIMIT $3`
+
+	names := make([]string, len(statuses))
+	for i, s := range statuses {
+		names[i] = string(s)
+	}
+	rows, err := r.pool.Query(ctx, query, names, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list stale jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan stale job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list stale jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *pgJobRepo) ResetStale(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus, before time.Time) (*domain.Job, bool, error) {
+	query := `
+		UPDATE execution_jobs
+		SET status = 'QUEUED', updated_at = $1
+		WHERE job_id = $2 AND status = $3 AND updated_at < $4
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.pool.QueryRow(ctx, query, time.Now().UTC(), id, status, before))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("postgres: reset stale job: %w", err)
+	}
+	return job, true, nil
+}
+
+func (r *pgJobRepo) CancelQueued(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE execution_jobs
+		SET status = 'CANCELLED', updated_at = $1
+		WHERE job_id = $2 AND status IN ('QUEUED', 'SCHEDULED')`, time.Now().UTC(), id)
+	if err != nil {
+		return false, fmt.Errorf("postgres: cancel queued job: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgJobRepo) CancelMatching(ctx context.Context, filter domain.BulkCancelFilter, limit int, dryRun bool) ([]*domain.Job, error) {
+	args := []any{string(filter.Status)}
+	conds := []string{"status = $1"}
+	if filter.TenantID != "" {
+		args = append(args, filter.TenantID)
+		conds = append(conds, fmt.Sprintf("tenant_id = $%d", len(args)))
+	}
+	if filter.ProblemID != "" {
+		args = append(args, filter.ProblemID)
+		conds = append(conds, fmt.Sprintf("problem_id = $%d", len(args)))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		conds = append(conds, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	args = append(args, limit)
+	matching := `SELECT job_id, judge_revision FROM execution_jobs
+		WHERE ` + strings.Join(conds, " AND ") + fmt.Sprintf(`
+		ORDER BY created_at
+		LIMIT $%d`, len(args))
+
+	// Locking the rows keeps workers from starting them until the update
+	// commits; SKIP LOCKED passes over the ones a worker is starting, and
+	// the status check drops any it started before they were locked.
+	query := matching
+	if !dryRun {
+		args = append(args, time.Now().UTC())
+		query = fmt.Sprintf(`
+		UPDATE execution_jobs
+		SET status = 'CANCELLED', updated_at = $%d
+		WHERE job_id IN (%s FOR UPDATE SKIP LOCKED) AND status = $1
+		RETURNING job_id, judge_revision`, len(args), matching)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: cancel matching jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job := &domain.Job{}
+		if err := rows.Scan(&job.JobID, &job.JudgeRevision); err != nil {
+			return nil, fmt.Errorf("postgres: scan cancelled job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: cancel matching jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *pgJobRepo) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*domain.Job, error) {
+	// SKIP LOCKED lets every API replica poll without claiming a job twice.
+	query := `
+		UPDATE execution_jobs
+		SET status = 'QUEUED', updated_at = $1
+		WHERE job_id IN (
+			SELECT job_id FROM execution_jobs
+			WHERE status = 'SCHEDULED' AND run_at <= $1
+			ORDER BY run_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: claim due jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan due job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: claim due jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *pgJobRepo) DeleteFinished(ctx context.Context, id uuid.UUID) (bool, error) {
+	// Rows referencing the job are removed by ON DELETE CASCADE, and its
+	// source with it unless another job shares it.
+	deleted := false
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var hash []byte
+		err := tx.QueryRow(ctx, `
+			DELETE FROM execution_jobs
+			WHERE job_id = $1 AND status NOT IN ('SCHEDULED', 'QUEUED', 'COMPILING', 'RUNNING')
+			RETURNING source_hash`, id).Scan(&hash)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("postgres: delete finished job: %w", err)
+		}
+		deleted = true
+		if hash == nil {
+			return nil
+		}
+		// A job created with the same source meanwhile keeps it: the
+		// foreign key refuses the delete, undoing only the savepoint.
+		err = pgx.BeginFunc(ctx, tx, func(sp pgx.Tx) error {
+			_, err := sp.Exec(ctx, `
+				DELETE FROM source_blobs b
+				WHERE hash = $1 AND NOT EXISTS (SELECT 1 FROM execution_jobs WHERE source_hash = b.hash)`, hash)
+			return err
+		})
+		var pgErr *pgconn.PgError
+		if err != nil && !(errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation) {
+			return fmt.Errorf("postgres: delete source blob: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+func (r *pgJobRepo) ListRecent(ctx context.Context, filter domain.JobFilter, after uuid.UUID, limit int) ([]*domain.JobSummary, error) {
+	// Conditions are added only when set, so every page is a range scan of
+	// the primary key or of idx_jobs_status_job_id, idx_jobs_tenant_job_id,
+	// idx_jobs_api_key_id or idx_jobs_user_id.
+	var conds []string
+	var args []any
+	if filter.Status != "" {
+		args = append(args, string(filter.Status))
+		conds = append(conds, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.TenantID != "" {
+		args = append(args, filter.TenantID)
+		conds = append(conds, fmt.Sprintf("tenant_id = $%d", len(args)))
+	}
+	if filter.APIKeyID != nil {
+		args = append(args, *filter.APIKeyID)
+		conds = append(conds, fmt.Sprintf("api_key_id = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conds = append(conds, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if after != uuid.Nil {
+		args = append(args, after)
+		conds = append(conds, fmt.Sprintf("job_id < $%d", len(args)))
+	}
+	query := `SELECT job_id, tenant_id, language, status, COALESCE(problem_id, ''),
+		       score, max_score, time_used_ms, memory_used_kb, created_at, updated_at
+		FROM execution_jobs`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY job_id DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list recent jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*domain.JobSummary
+	for rows.Next() {
+		job := &domain.JobSummary{}
+		if err := rows.Scan(
+			&job.JobID, &job.TenantID, &job.Language, &job.Status, &job.ProblemID,
+			&job.Score, &job.MaxScore, &job.TimeUsedMs, &job.MemoryUsedKB, &job.CreatedAt, &job.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("postgres: scan recent job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list recent jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// nullableText maps an empty string to SQL NULL.
+func nullableText(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// nullableInt maps zero to SQL NULL.
+func nullableInt(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgLTIRepo implements repository.LTIRepository.
+var _ repository.LTIRepository = (*pgLTIRepo)(nil)
+
+type pgLTIRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresLTIRepository creates a new PostgreSQL-backed LTI repository.
+func NewPostgresLTIRepository(pool *pgxpool.Pool) repository.LTIRepository {
+	return &pgLTIRepo{pool: pool}
+}
+
+const ltiPlatformColumns = `platform_id, tenant_id, issuer, client_id, deployment_id,
+	auth_login_url, token_url, jwks_url, tenant_mapping, created_at`
+
+func scanLTIPlatform(row pgx.Row) (*domain.LTIPlatform, error) {
+	p := &domain.LTIPlatform{}
+	err := row.Scan(&p.ID, &p.TenantID, &p.Issuer, &p.ClientID, &p.DeploymentID,
+		&p.AuthLoginURL, &p.TokenURL, &p.JWKSURL, &p.TenantMapping, &p.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLTIPlatformNotFound
+		}
+		return nil, fmt.Errorf("postgres: get lti platform: %w", err)
+	}
+	return p, nil
+}
+
+func (r *pgLTIRepo) CreatePlatform(ctx context.Context, p *domain.LTIPlatform) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO lti_platforms (platform_id, tenant_id, issuer, client_id, deployment_id,
+			auth_login_url, token_url, jwks_url, tenant_mapping)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at`,
+		p.ID, p.TenantID, p.Issuer, p.ClientID, p.DeploymentID,
+		p.AuthLoginURL, p.TokenURL, p.JWKSURL, p.TenantMapping,
+	).Scan(&p.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.ErrLTIPlatformTaken
+		}
+		return fmt.Errorf("postgres: create lti platform: %w", err)
+	}
+	return nil
+}
+
+func (r *pgLTIRepo) GetPlatform(ctx context.Context, id uuid.UUID) (*domain.LTIPlatform, error) {
+	return scanLTIPlatform(r.pool.QueryRow(ctx, `
+		SELECT `+ltiPlatformColumns+` FROM lti_platforms WHERE platform_id = $1`, id))
+}
+
+func (r *pgLTIRepo) FindPlatform(ctx context.Context, issuer, clientID string) (*domain.LTIPlatform, error) {
+	return scanLTIPlatform(r.pool.QueryRow(ctx, `
+		SELECT `+ltiPlatformColumns+` FROM lti_platforms WHERE issuer = $1 AND client_id = $2`,
+		issuer, clientID))
+}
+
+func (r *pgLTIRepo) DeletePlatform(ctx context.Context, tenantID string, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM lti_platforms WHERE platform_id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("postgres: delete lti platform: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLTIPlatformNotFound
+	}
+	return nil
+}
+
+func (r *pgLTIRepo) UpsertUser(ctx context.Context, user *domain.LTIUser) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO lti_users (platform_id, subject, tenant_id, name, email)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (platform_id, subject) DO UPDATE SET
+			name = EXCLUDED.name, email = EXCLUDED.email, last_launch_at = NOW()
+		RETURNING tenant_id, first_launch_at, last_launch_at`,
+		user.PlatformID, user.Subject, user.TenantID, user.Name, user.Email,
+	).Scan(&user.TenantID, &user.FirstLaunchAt, &user.LastLaunchAt)
+	if err != nil {
+		return fmt.Errorf("postgres: upsert lti user: %w", err)
+	}
+	return nil
+}
+
+func (r *pgLTIRepo) ListUsers(ctx context.Context, platformID uuid.UUID) ([]*domain.LTIUser, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT platform_id, subject, tenant_id, name, email, first_launch_at, last_launch_at
+		FROM lti_users
+		WHERE platform_id = $1
+		ORDER BY last_launch_at DESC`, platformID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list lti users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.LTIUser
+	for rows.Next() {
+		u := &domain.LTIUser{}
+		if err := rows.Scan(&u.PlatformID, &u.Subject, &u.TenantID, &u.Name, &u.Email, &u.FirstLaunchAt, &u.LastLaunchAt); err != nil {
+			return nil, fmt.Errorf("postgres: scan lti user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list lti users: %w", err)
+	}
+	return users, nil
+}
+
+func (r *pgLTIRepo) CreateLaunch(ctx context.Context, launch *domain.LTILaunch) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO lti_launches (launch_id, platform_id, subject, tenant_id, problem_id, line_item, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+		RETURNING created_at`,
+		launch.ID, launch.PlatformID, launch.Subject, launch.TenantID, launch.ProblemID, launch.LineItem, launch.ExpiresAt,
+	).Scan(&launch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("postgres: create lti launch: %w", err)
+	}
+	return nil
+}
+
+func (r *pgLTIRepo) GetLaunch(ctx context.Context, id uuid.UUID) (*domain.LTILaunch, error) {
+	launch := &domain.LTILaunch{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT launch_id, platform_id, subject, tenant_id, problem_id, COALESCE(line_item, ''), created_at, expires_at
+		FROM lti_launches
+		WHERE launch_id = $1`, id,
+	).Scan(&launch.ID, &launch.PlatformID, &launch.Subject, &launch.TenantID, &launch.ProblemID,
+		&launch.LineItem, &launch.CreatedAt, &launch.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLTILaunchNotFound
+		}
+		return nil, fmt.Errorf("postgres: get lti launch: %w", err)
+	}
+	launch.Graded = launch.LineItem != ""
+	return launch, nil
+}
+
+func (r *pgLTIRepo) AddScore(ctx context.Context, jobID, launchID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `INSERT INTO lti_scores (job_id, launch_id) VALUES ($1, $2)`, jobID, launchID); err != nil {
+		return fmt.Errorf("postgres: add lti score: %w", err)
+	}
+	return nil
+}
+
+func (r *pgLTIRepo) ListFinishedScores(ctx context.Context, limit int) ([]*domain.LTIScore, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.job_id, l.platform_id, l.subject, l.line_item, j.status, j.score, j.max_score
+		FROM lti_scores s
+		JOIN lti_launches l ON l.launch_id = s.launch_id
+		JOIN execution_jobs j ON j.job_id = s.job_id
+		WHERE j.status NOT IN ($1, $2, $3, $4)
+		ORDER BY s.created_at
+		LIMIT $5`,
+		domain.StatusScheduled, domain.StatusQueued, domain.StatusCompiling, domain.StatusRunning, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list lti scores: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []*domain.LTIScore
+	for rows.Next() {
+		s := &domain.LTIScore{}
+		if err := rows.Scan(&s.JobID, &s.PlatformID, &s.Subject, &s.LineItem, &s.Status, &s.Score, &s.MaxScore); err != nil {
+			return nil, fmt.Errorf("postgres: scan lti score: %w", err)
+		}
+		scores = append(scores, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list lti scores: %w", err)
+	}
+	return scores, nil
+}
+
+func (r *pgLTIRepo) RemoveScore(ctx context.Context, jobID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM lti_scores WHERE job_id = $1`, jobID); err != nil {
+		return fmt.Errorf("postgres: remove lti score: %w", err)
+	}
+	return nil
+}
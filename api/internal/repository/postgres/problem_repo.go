@@ -0,0 +1,327 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgProblemRepo implements repository.ProblemRepository.
+var _ repository.ProblemRepository = (*pgProblemRepo)(nil)
+
+// pgUniqueViolation is the SQLSTATE for a unique constraint violation.
+const pgUniqueViolation = "23505"
+
+// pgForeignKeyViolation is the SQLSTATE for a foreign key violation.
+const pgForeignKeyViolation = "23503"
+
+type pgProblemRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresProblemRepository creates a new PostgreSQL-backed problem repository.
+func NewPostgresProblemRepository(pool *pgxpool.Pool) repository.ProblemRepository {
+	return &pgProblemRepo{pool: pool}
+}
+
+func (r *pgProblemRepo) Create(ctx context.Context, problem *domain.Problem) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres: begin create problem: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO problems (problem_id, title, test_data_version, termination_strategy)
+		VALUES ($1, $2, 1, $3)
+		RETURNING test_data_version, created_at, updated_at`,
+		problem.ProblemID, problem.Title, problem.TerminationStrategy,
+	).Scan(&problem.TestDataVersion, &problem.CreatedAt, &problem.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.ErrProblemExists
+		}
+		return fmt.Errorf("postgres: create problem: %w", err)
+	}
+
+	if err := insertTestData(ctx, tx, problem.ProblemID, problem.TestDataVersion, problem.Generator, problem.Comparator, problem.Interactor, problem.Harnesses, problem.Rules, problem.KillPatterns, problem.Subtasks, problem.TestCases); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit create problem: %w", err)
+	}
+	return nil
+}
+
+func (r *pgProblemRepo) GetByID(ctx context.Context, id string) (*domain.Problem, error) {
+	problem := &domain.Problem{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT problem_id, title, test_data_version, termination_strategy, created_at, updated_at
+		FROM problems
+		WHERE problem_id = $1`, id,
+	).Scan(&problem.ProblemID, &problem.Title, &problem.TestDataVersion, &problem.TerminationStrategy, &problem.CreatedAt, &problem.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProblemNotFound
+		}
+		return nil, fmt.Errorf("postgres: get problem: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT input, expected_output, COALESCE(subtask, 0), time_limit_ms, memory_limit_kb, generator_seed
+		FROM problem_test_cases
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY ordinal`, id, problem.TestDataVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get test cases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tc domain.TestCase
+		if err := rows.Scan(&tc.Input, &tc.ExpectedOutput, &tc.Subtask, &tc.TimeLimitMs, &tc.MemoryLimitKB, &tc.GeneratorSeed); err != nil {
+			return nil, fmt.Errorf("postgres: scan test case: %w", err)
+		}
+		problem.TestCases = append(problem.TestCases, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: get test cases: %w", err)
+	}
+
+	subtasks, err := r.pool.Query(ctx, `
+		SELECT points, scoring
+		FROM problem_subtasks
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY ordinal`, id, problem.TestDataVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get subtasks: %w", err)
+	}
+	defer subtasks.Close()
+
+	for subtasks.Next() {
+		var st domain.Subtask
+		if err := subtasks.Scan(&st.Points, &st.Scoring); err != nil {
+			return nil, fmt.Errorf("postgres: scan subtask: %w", err)
+		}
+		problem.Subtasks = append(problem.Subtasks, st)
+	}
+	if err := subtasks.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: get subtasks: %w", err)
+	}
+
+	gen := &domain.Generator{}
+	err = r.pool.QueryRow(ctx, `
+		SELECT language, source_code, source_hash
+		FROM problem_generators
+		WHERE problem_id = $1 AND version = $2`, id, problem.TestDataVersion,
+	).Scan(&gen.Language, &gen.SourceCode, &gen.Hash)
+	switch {
+	case err == nil:
+		problem.Generator = gen
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("postgres: get generator: %w", err)
+	}
+
+	// The module itself is only read by workers.
+	var hash string
+	err = r.pool.QueryRow(ctx, `
+		SELECT wasm_hash
+		FROM problem_comparators
+		WHERE problem_id = $1 AND version = $2`, id, problem.TestDataVersion,
+	).Scan(&hash)
+	switch {
+	case err == nil:
+		problem.Comparator = &domain.Comparator{Hash: hash}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("postgres: get comparator: %w", err)
+	}
+
+	inter := &domain.Interactor{}
+	err = r.pool.QueryRow(ctx, `
+		SELECT language, source_code
+		FROM problem_interactors
+		WHERE problem_id = $1 AND version = $2`, id, problem.TestDataVersion,
+	).Scan(&inter.Language, &inter.SourceCode)
+	switch {
+	case err == nil:
+		problem.Interactor = inter
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("postgres: get interactor: %w", err)
+	}
+
+	harnesses, err := r.pool.Query(ctx, `
+		SELECT language, template
+		FROM problem_harnesses
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY language`, id, problem.TestDataVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get harnesses: %w", err)
+	}
+	defer harnesses.Close()
+
+	for harnesses.Next() {
+		var h domain.Harness
+		if err := harnesses.Scan(&h.Language, &h.Template); err != nil {
+			return nil, fmt.Errorf("postgres: scan harness: %w", err)
+		}
+		problem.Harnesses = append(problem.Harnesses, h)
+	}
+	if err := harnesses.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: get harnesses: %w", err)
+	}
+
+	rules, err := r.pool.Query(ctx, `
+		SELECT language, pattern, message
+		FROM problem_rules
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY ordinal`, id, problem.TestDataVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get rules: %w", err)
+	}
+	defer rules.Close()
+
+	for rules.Next() {
+		var rule domain.SourceRule
+		if err := rules.Scan(&rule.Language, &rule.Pattern, &rule.Message); err != nil {
+			return nil, fmt.Errorf("postgres: scan rule: %w", err)
+		}
+		problem.Rules = append(problem.Rules, rule)
+	}
+	if err := rules.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: get rules: %w", err)
+	}
+
+	patterns, err := r.pool.Query(ctx, `
+		SELECT pattern, repeats, message
+		FROM problem_kill_patterns
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY ordinal`, id, problem.TestDataVersion,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get kill patterns: %w", err)
+	}
+	defer patterns.Close()
+
+	for patterns.Next() {
+		var p domain.KillPattern
+		if err := patterns.Scan(&p.Pattern, &p.Repeats, &p.Message); err != nil {
+			return nil, fmt.Errorf("postgres: scan kill pattern: %w", err)
+		}
+		problem.KillPatterns = append(problem.KillPatterns, p)
+	}
+	if err := patterns.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: get kill patterns: %w", err)
+	}
+	return problem, nil
+}
+
+func (r *pgProblemRepo) ReplaceTestData(ctx context.Context, id string, generator *domain.Generator, comparator *domain.Comparator, interactor *domain.Interactor, harnesses []domain.Harness, rules []domain.SourceRule, killPatterns []domain.KillPattern, subtasks []domain.Subtask, cases []domain.TestCase) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: begin replace test data: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The row lock serialises concurrent uploads so each gets its own version.
+	var version int
+	err = tx.QueryRow(ctx, `
+		UPDATE problems
+		SET test_data_version = test_data_version + 1
+		WHERE problem_id = $1
+		RETURNING test_data_version`, id,
+	).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrProblemNotFound
+		}
+		return 0, fmt.Errorf("postgres: bump test data version: %w", err)
+	}
+
+	if err := insertTestData(ctx, tx, id, version, generator, comparator, interactor, harnesses, rules, killPatterns, subtasks, cases); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("postgres: commit replace test data: %w", err)
+	}
+	return version, nil
+}
+
+func insertTestData(ctx context.Context, tx pgx.Tx, problemID string, version int, generator *domain.Generator, comparator *domain.Comparator, interactor *domain.Interactor, harnesses []domain.Harness, rules []domain.SourceRule, killPatterns []domain.KillPattern, subtasks []domain.Subtask, cases []domain.TestCase) error {
+	batch := &pgx.Batch{}
+	if generator != nil {
+		batch.Queue(`
+			INSERT INTO problem_generators (problem_id, version, language, source_code, source_hash)
+			VALUES ($1, $2, $3, $4, $5)`,
+			problemID, version, generator.Language, generator.SourceCode, generator.Hash,
+		)
+	}
+	if comparator != nil {
+		batch.Queue(`
+			INSERT INTO problem_comparators (problem_id, version, wasm, wasm_hash)
+			VALUES ($1, $2, $3, $4)`,
+			problemID, version, comparator.Wasm, comparator.Hash,
+		)
+	}
+	if interactor != nil {
+		batch.Queue(`
+			INSERT INTO problem_interactors (problem_id, version, language, source_code)
+			VALUES ($1, $2, $3, $4)`,
+			problemID, version, interactor.Language, interactor.SourceCode,
+		)
+	}
+	for _, h := range harnesses {
+		batch.Queue(`
+			INSERT INTO problem_harnesses (problem_id, version, language, template)
+			VALUES ($1, $2, $3, $4)`,
+			problemID, version, h.Language, h.Template,
+		)
+	}
+	for i, rule := range rules {
+		batch.Queue(`
+			INSERT INTO problem_rules (problem_id, version, ordinal, language, pattern, message)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			problemID, version, i+1, rule.Language, rule.Pattern, rule.Message,
+		)
+	}
+	for i, p := range killPatterns {
+		batch.Queue(`
+			INSERT INTO problem_kill_patterns (problem_id, version, ordinal, pattern, repeats, message)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			problemID, version, i+1, p.Pattern, max(p.Repeats, 1), p.Message,
+		)
+	}
+	for i, st := range subtasks {
+		batch.Queue(`
+			INSERT INTO problem_subtasks (problem_id, version, ordinal, points, scoring)
+			VALUES ($1, $2, $3, $4, $5)`,
+			problemID, version, i+1, st.Points, st.Scoring,
+		)
+	}
+	for i, tc := range cases {
+		var subtask *int
+		if tc.Subtask > 0 {
+			subtask = &tc.Subtask
+		}
+		batch.Queue(`
+			INSERT INTO problem_test_cases (problem_id, version, ordinal, input, expected_output, subtask, time_limit_ms, memory_limit_kb, generator_seed)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			problemID, version, i+1, tc.Input, tc.ExpectedOutput, subtask, tc.TimeLimitMs, tc.MemoryLimitKB, tc.GeneratorSeed,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("postgres: insert test data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgRuntimeRepo implements repository.RuntimeRepository.
+var _ repository.RuntimeRepository = (*pgRuntimeRepo)(nil)
+
+type pgRuntimeRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRuntimeRepository creates a new PostgreSQL-backed runtime repository.
+func NewPostgresRuntimeRepository(pool *pgxpool.Pool) repository.RuntimeRepository {
+	return &pgRuntimeRepo{pool: pool}
+}
+
+// runtimeColumns is the column list shared by every query that scans a runtime.
+const runtimeColumns = `name, version, source_file, nsjail_config, compile_cmd, run_cmd,
+		       mounts, env, created_at, updated_at`
+
+func scanRuntime(row pgx.Row) (*domain.Runtime, error) {
+	rt := &domain.Runtime{}
+	var mounts, env []byte
+	err := row.Scan(
+		&rt.Name, &rt.Version, &rt.SourceFile, &rt.NsjailConfig, &rt.Compile, &rt.Run,
+		&mounts, &env, &rt.CreatedAt, &rt.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mounts, &rt.Mounts); err != nil {
+		return nil, fmt.Errorf("decode mounts: %w", err)
+	}
+	if err := json.Unmarshal(env, &rt.Env); err != nil {
+		return nil, fmt.Errorf("decode env: %w", err)
+	}
+	return rt, nil
+}
+
+// encodeRuntime marshals the JSONB columns, storing empty values rather than
+// NULL so the worker never has to tell them apart.
+func encodeRuntime(rt *domain.Runtime) (mounts, env []byte, err error) {
+	m := rt.Mounts
+	if m == nil {
+		m = []domain.Mount{}
+	}
+	if mounts, err = json.Marshal(m); err != nil {
+		return nil, nil, fmt.Errorf("postgres: encode mounts: %w", err)
+	}
+	e := rt.Env
+	if e == nil {
+		e = map[string]string{}
+	}
+	if env, err = json.Marshal(e); err != nil {
+		return nil, nil, fmt.Errorf("postgres: encode env: %w", err)
+	}
+	return mounts, env, nil
+}
+
+func (r *pgRuntimeRepo) Create(ctx context.Context, rt *domain.Runtime) error {
+	mounts, env, err := encodeRuntime(rt)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO runtimes (name, version, source_file, nsjail_config, compile_cmd, run_cmd, mounts, env)
+		VALUES ($1, $2, $3, $4, COALESCE($5::TEXT[], '{}'), $6, $7, $8)
+		RETURNING created_at, updated_at`,
+		rt.Name, rt.Version, rt.SourceFile, rt.NsjailConfig, rt.Compile, rt.Run, mounts, env,
+	).Scan(&rt.CreatedAt, &rt.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.ErrRuntimeExists
+		}
+		return fmt.Errorf("postgres: create runtime: %w", err)
+	}
+	return nil
+}
+
+func (r *pgRuntimeRepo) GetByName(ctx context.Context, name domain.Language) (*domain.Runtime, error) {
+	rt, err := scanRuntime(r.pool.QueryRow(ctx, `SELECT `+runtimeColumns+` FROM runtimes WHERE name = $1`, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRuntimeNotFound
+		}
+		return nil, fmt.Errorf("postgres: get runtime: %w", err)
+	}
+	return rt, nil
+}
+
+func (r *pgRuntimeRepo) List(ctx context.Context) ([]*domain.Runtime, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+runtimeColumns+` FROM runtimes ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list runtimes: %w", err)
+	}
+	defer rows.Close()
+
+	var runtimes []*domain.Runtime
+	for rows.Next() {
+		rt, err := scanRuntime(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan runtime: %w", err)
+		}
+		runtimes = append(runtimes, rt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list runtimes: %w", err)
+	}
+	return runtimes, nil
+}
+
+func (r *pgRuntimeRepo) Update(ctx context.Context, rt *domain.Runtime) error {
+	mounts, env, err := encodeRuntime(rt)
+	if err != nil {
+		return err
+	}
+	err = r.pool.QueryRow(ctx, `
+		UPDATE runtimes
+		SET version = $2, source_file = $3, nsjail_config = $4, compile_cmd = COALESCE($5::TEXT[], '{}'),
+		    run_cmd = $6, mounts = $7, env = $8
+		WHERE name = $1
+		RETURNING created_at, updated_at`,
+		rt.Name, rt.Version, rt.SourceFile, rt.NsjailConfig, rt.Compile, rt.Run, mounts, env,
+	).Scan(&rt.CreatedAt, &rt.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrRuntimeNotFound
+		}
+		return fmt.Errorf("postgres: update runtime: %w", err)
+	}
+	return nil
+}
+
+func (r *pgRuntimeRepo) Delete(ctx context.Context, name domain.Language) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM runtimes WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("postgres: delete runtime: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRuntimeNotFound
+	}
+	return nil
+}
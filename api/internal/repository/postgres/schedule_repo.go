@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgScheduleRepo implements repository.ScheduleRepository.
+var _ repository.ScheduleRepository = (*pgScheduleRepo)(nil)
+
+type pgScheduleRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresScheduleRepository creates a new PostgreSQL-backed schedule repository.
+func NewPostgresScheduleRepository(pool *pgxpool.Pool) repository.ScheduleRepository {
+	return &pgScheduleRepo{pool: pool}
+}
+
+// scheduleColumns are the columns scanSchedule reads from a schedule aliased
+// s. The last run's status is read from its job, so it stays current.
+const scheduleColumns = `s.schedule_id, s.tenant_id, s.name, s.cron, s.template, s.next_run_at,
+		       s.last_run_at, s.last_job_id, COALESCE(j.status::TEXT, ''), COALESCE(s.last_error, ''),
+		       s.created_at, s.updated_at`
+
+// scheduleFrom joins each schedule to its last job for scheduleColumns.
+const scheduleFrom = `job_schedules s LEFT JOIN execution_jobs j ON j.job_id = s.last_job_id`
+
+func scanSchedule(row pgx.Row) (*domain.Schedule, error) {
+	s := &domain.Schedule{}
+	var template []byte
+	err := row.Scan(&s.ScheduleID, &s.TenantID, &s.Name, &s.Cron, &template, &s.NextRunAt,
+		&s.LastRunAt, &s.LastJobID, &s.LastStatus, &s.LastError, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(template, &s.Job); err != nil {
+		return nil, fmt.Errorf("decode job template: %w", err)
+	}
+	return s, nil
+}
+
+func (r *pgScheduleRepo) Create(ctx context.Context, s *domain.Schedule) error {
+	template, err := json.Marshal(&s.Job)
+	if err != nil {
+		return fmt.Errorf("postgres: encode job template: %w", err)
+	}
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO job_schedules (schedule_id, tenant_id, name, cron, template, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at`,
+		s.ScheduleID, s.TenantID, s.Name, s.Cron, template, s.NextRunAt,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("postgres: create schedule: %w", err)
+	}
+	return nil
+}
+
+func (r *pgScheduleRepo) Get(ctx context.Context, tenantID string, id uuid.UUID) (*domain.Schedule, error) {
+	s, err := scanSchedule(r.pool.QueryRow(ctx, `
+		SELECT `+scheduleColumns+`
+		FROM `+scheduleFrom+`
+		WHERE s.tenant_id = $1 AND s.schedule_id = $2`, tenantID, id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("postgres: get schedule: %w", err)
+	}
+	return s, nil
+}
+
+func (r *pgScheduleRepo) List(ctx context.Context, tenantID string) ([]*domain.Schedule, error) {
+	return r.list(ctx, "list schedules", `
+		SELECT `+scheduleColumns+`
+		FROM `+scheduleFrom+`
+		WHERE s.tenant_id = $1
+		ORDER BY s.created_at, s.schedule_id`, tenantID,
+	)
+}
+
+func (r *pgScheduleRepo) Delete(ctx context.Context, tenantID string, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM job_schedules WHERE tenant_id = $1 AND schedule_id = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("postgres: delete schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrScheduleNotFound
+	}
+	return nil
+}
+
+func (r *pgScheduleRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.Schedule, error) {
+	return r.list(ctx, "list due schedules", `
+		SELECT `+scheduleColumns+`
+		FROM `+scheduleFrom+`
+		WHERE s.next_run_at <= $1
+		ORDER BY s.next_run_at, s.schedule_id
+		LIMIT $2`, now, limit,
+	)
+}
+
+func (r *pgScheduleRepo) list(ctx context.Context, op, query string, args ...any) ([]*domain.Schedule, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %s: %w", op, err)
+	}
+	defer rows.Close()
+
+	schedules := []*domain.Schedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: %s: %w", op, err)
+	}
+	return schedules, nil
+}
+
+func (r *pgScheduleRepo) Advance(ctx context.Context, id uuid.UUID, due, next time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE job_schedules SET next_run_at = $3
+		WHERE schedule_id = $1 AND next_run_at = $2`,
+		id, due, next,
+	)
+	if err != nil {
+		return false, fmt.Errorf("postgres: advance schedule: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgScheduleRepo) RecordRun(ctx context.Context, id uuid.UUID, run *domain.ScheduleRun) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE job_schedules
+		SET last_run_at = $2, last_job_id = $3, last_error = NULLIF($4, '')
+		WHERE schedule_id = $1`,
+		id, run.At, run.JobID, run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: record schedule run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgSecurityEventRepo implements repository.SecurityEventRepository.
+var _ repository.SecurityEventRepository = (*pgSecurityEventRepo)(nil)
+
+type pgSecurityEventRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSecurityEventRepository creates a new PostgreSQL-backed security event repository.
+func NewPostgresSecurityEventRepository(pool *pgxpool.Pool) repository.SecurityEventRepository {
+	return &pgSecurityEventRepo{pool: pool}
+}
+
+func (r *pgSecurityEventRepo) List(ctx context.Context, filter domain.SecurityEventFilter, afterEventID int64, settledBefore time.Time, limit int) ([]*domain.SecurityEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT event_id, job_id, tenant_id, kind, detail, created_at
+		FROM security_events
+		WHERE event_id > $1
+		  AND created_at < $2
+		  AND ($3 = '' OR kind = $3)
+		  AND ($4 = '' OR tenant_id = $4)
+		ORDER BY event_id
+		LIMIT $5`,
+		afterEventID, settledBefore, string(filter.Kind), filter.TenantID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list security events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.SecurityEvent
+	for rows.Next() {
+		e := &domain.SecurityEvent{}
+		if err := rows.Scan(&e.EventID, &e.JobID, &e.TenantID, &e.Kind, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("postgres: scan security event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list security events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+var _ repository.StatusFeed = (*pgStatusFeed)(nil)
+
+const (
+	// jobStatusChannel must match the channel migration 042's trigger
+	// notifies.
+	jobStatusChannel = "sentinel_job_status"
+
+	// The listener reconnects after statusFeedMinBackoff, doubling the
+	// wait on each failure up to statusFeedMaxBackoff.
+	statusFeedMinBackoff = 100 * time.Millisecond
+	statusFeedMaxBackoff = 5 * time.Second
+)
+
+type pgStatusFeed struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan struct{}]struct{}
+}
+
+// NewPostgresStatusFeed creates a feed listening for job status changes
+// until ctx is done. The listener holds one connection of its own, taken
+// from pool; when it is lost, the listener reconnects with backoff and
+// wakes every subscriber, since changes made meanwhile were not heard.
+func NewPostgresStatusFeed(ctx context.Context, pool *pgxpool.Pool) repository.StatusFeed {
+	f := &pgStatusFeed{subs: make(map[uuid.UUID]map[chan struct{}]struct{})}
+	go f.listen(ctx, pool)
+	return f
+}
+
+func (f *pgStatusFeed) listen(ctx context.Context, pool *pgxpool.Pool) {
+	backoff := statusFeedMinBackoff
+	for ctx.Err() == nil {
+		if f.session(ctx, pool) {
+			backoff = statusFeedMinBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, statusFeedMaxBackoff)
+	}
+}
+
+// session listens on one connection until it fails, reporting whether it
+// got as far as listening.
+func (f *pgStatusFeed) session(ctx context.Context, pool *pgxpool.Pool) bool {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return false
+	}
+	// A listening connection is not returned to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+jobStatusChannel); err != nil {
+		return false
+	}
+	f.wakeAll()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true
+		}
+		if id, err := uuid.Parse(n.Payload); err == nil {
+			f.wake(id)
+		}
+	}
+}
+
+func (f *pgStatusFeed) Subscribe(id uuid.UUID) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	f.mu.Lock()
+	if f.subs[id] == nil {
+		f.subs[id] = make(map[chan struct{}]struct{})
+	}
+	f.subs[id][ch] = struct{}{}
+	f.mu.Unlock()
+	return ch, func() {
+		f.mu.Lock()
+		delete(f.subs[id], ch)
+		if len(f.subs[id]) == 0 {
+			delete(f.subs, id)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *pgStatusFeed) wake(id uuid.UUID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subs[id] {
+		notify(ch)
+	}
+}
+
+func (f *pgStatusFeed) wakeAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, subs := range f.subs {
+		for ch := range subs {
+			notify(ch)
+		}
+	}
+}
+
+// notify sends on ch unless a value is already waiting there.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgUserRepo implements repository.UserRepository.
+var _ repository.UserRepository = (*pgUserRepo)(nil)
+
+type pgUserRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresUserRepository creates a new PostgreSQL-backed user repository.
+func NewPostgresUserRepository(pool *pgxpool.Pool) repository.UserRepository {
+	return &pgUserRepo{pool: pool}
+}
+
+func (r *pgUserRepo) Create(ctx context.Context, user *domain.User, passwordHash []byte) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO users (user_id, tenant_id, email, password_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at`,
+		user.UserID, user.TenantID, user.Email, passwordHash,
+	).Scan(&user.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return domain.ErrUserExists
+		}
+		return fmt.Errorf("postgres: create user: %w", err)
+	}
+	return nil
+}
+
+func (r *pgUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, []byte, error) {
+	u := &domain.User{}
+	var hash []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id, tenant_id, email, created_at, password_hash
+		FROM users WHERE email = $1 AND issuer IS NULL`, email,
+	).Scan(&u.UserID, &u.TenantID, &u.Email, &u.CreatedAt, &hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, domain.ErrUserNotFound
+		}
+		return nil, nil, fmt.Errorf("postgres: get user by email: %w", err)
+	}
+	return u, hash, nil
+}
+
+func (r *pgUserRepo) UpsertExternal(ctx context.Context, user *domain.User, issuer, subject string) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO users (user_id, tenant_id, email, issuer, subject)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (issuer, subject) WHERE issuer IS NOT NULL DO UPDATE
+		SET tenant_id = EXCLUDED.tenant_id, email = EXCLUDED.email
+		RETURNING user_id, created_at`,
+		user.UserID, user.TenantID, user.Email, issuer, subject,
+	).Scan(&user.UserID, &user.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("postgres: upsert external user: %w", err)
+	}
+	return nil
+}
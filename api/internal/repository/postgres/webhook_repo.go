@@ -0,0 +1,338 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// Ensure pgWebhookRepo implements repository.WebhookRepository.
+var _ repository.WebhookRepository = (*pgWebhookRepo)(nil)
+
+type pgWebhookRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresWebhookRepository creates a new PostgreSQL-backed webhook repository.
+func NewPostgresWebhookRepository(pool *pgxpool.Pool) repository.WebhookRepository {
+	return &pgWebhookRepo{pool: pool}
+}
+
+func (r *pgWebhookRepo) PutDLQWebhook(ctx context.Context, hook *domain.DLQWebhook) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO tenant_dlq_webhooks (tenant_id, url, secret)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE SET url = EXCLUDED.url, secret = EXCLUDED.secret
+		RETURNING created_at, updated_at`,
+		hook.TenantID, hook.URL, hook.Secret,
+	).Scan(&hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("postgres: put dlq webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *pgWebhookRepo) GetDLQWebhook(ctx context.Context, tenantID string) (*domain.DLQWebhook, error) {
+	hook := &domain.DLQWebhook{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT tenant_id, url, secret, created_at, updated_at
+		FROM tenant_dlq_webhooks
+		WHERE tenant_id = $1`, tenantID,
+	).Scan(&hook.TenantID, &hook.URL, &hook.Secret, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("postgres: get dlq webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (r *pgWebhookRepo) DeleteDLQWebhook(ctx context.Context, tenantID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM tenant_dlq_webhooks WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("postgres: delete dlq webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *pgWebhookRepo) PutResultWebhook(ctx context.Context, hook *domain.ResultWebhook) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO tenant_result_webhooks (tenant_id, url, secret)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET url = EXCLUDED.url, secret = EXCLUDED.secret,
+		    consecutive_failures = 0, circuit_open_until = NULL
+		RETURNING created_at, updated_at`,
+		hook.TenantID, hook.URL, hook.Secret,
+	).Scan(&hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("postgres: put result webhook: %w", err)
+	}
+	hook.ConsecutiveFailures = 0
+	hook.CircuitOpenUntil = nil
+	return nil
+}
+
+func (r *pgWebhookRepo) GetResultWebhook(ctx context.Context, tenantID string) (*domain.ResultWebhook, error) {
+	hook := &domain.ResultWebhook{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT tenant_id, url, secret, consecutive_failures, circuit_open_until, created_at, updated_at
+		FROM tenant_result_webhooks
+		WHERE tenant_id = $1`, tenantID,
+	).Scan(&hook.TenantID, &hook.URL, &hook.Secret, &hook.ConsecutiveFailures, &hook.CircuitOpenUntil,
+		&hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("postgres: get result webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (r *pgWebhookRepo) DeleteResultWebhook(ctx context.Context, tenantID string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres: begin delete result webhook: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM tenant_result_webhooks WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("postgres: delete result webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, last_error = 'webhook removed'
+		WHERE tenant_id = $1 AND status = $3`,
+		tenantID, domain.DeliveryFailed, domain.DeliveryPending,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: fail pending deliveries: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit delete result webhook: %w", err)
+	}
+	return nil
+}
+
+// deliveryColumns are the webhook_deliveries columns scanDelivery reads.
+const deliveryColumns = `delivery_id, tenant_id, job_id, job_status, status, attempts, redrives,
+	next_attempt_at, COALESCE(last_error, ''), delivered_at, created_at, updated_at`
+
+func scanDelivery(row pgx.Row) (*domain.WebhookDelivery, error) {
+	d := &domain.WebhookDelivery{}
+	var next time.Time
+	err := row.Scan(&d.ID, &d.TenantID, &d.JobID, &d.JobStatus, &d.Status, &d.Attempts, &d.Redrives,
+		&next, &d.LastError, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == domain.DeliveryPending {
+		d.NextAttemptAt = &next
+	}
+	return d, nil
+}
+
+func (r *pgWebhookRepo) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = $2
+		WHERE delivery_id IN (
+			SELECT d.delivery_id
+			FROM webhook_deliveries d
+			JOIN tenant_result_webhooks w ON w.tenant_id = d.tenant_id
+			WHERE d.status = $3 AND d.next_attempt_at <= $1
+			  AND (w.circuit_open_until IS NULL OR w.circuit_open_until <= $1)
+			ORDER BY d.delivery_id
+			LIMIT $4
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING `+deliveryColumns,
+		now, now.Add(lease), domain.DeliveryPending, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: claim due deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: claim due deliveries: %w", err)
+	}
+	// UPDATE ... RETURNING does not keep the subselect's order.
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *pgWebhookRepo) RecordDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt, hook *domain.ResultWebhook) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres: begin record delivery attempt: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, url, status_code, error, duration_ms, attempted_at)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, ''), $5, $6)`,
+		delivery.ID, attempt.URL, attempt.StatusCode, attempt.Error, attempt.DurationMs, attempt.AttemptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: insert delivery attempt: %w", err)
+	}
+	next := attempt.AttemptedAt
+	if delivery.NextAttemptAt != nil {
+		next = *delivery.NextAttemptAt
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = NULLIF($5, ''), delivered_at = $6
+		WHERE delivery_id = $1`,
+		delivery.ID, delivery.Status, delivery.Attempts, next, delivery.LastError, delivery.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: update delivery: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE tenant_result_webhooks
+		SET consecutive_failures = $2, circuit_open_until = $3
+		WHERE tenant_id = $1`,
+		hook.TenantID, hook.ConsecutiveFailures, hook.CircuitOpenUntil,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: update result webhook circuit: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("postgres: commit delivery attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *pgWebhookRepo) DeferDelivery(ctx context.Context, id int64, until time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE delivery_id = $1 AND status = $3`,
+		id, until, domain.DeliveryPending,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: defer delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *pgWebhookRepo) ListDeliveries(ctx context.Context, tenantID string, status domain.DeliveryStatus, before int64, limit int) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE tenant_id = $1
+		  AND ($2 = '' OR status = $2)
+		  AND ($3 = 0 OR delivery_id < $3)
+		ORDER BY delivery_id DESC
+		LIMIT $4`,
+		tenantID, string(status), before, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *pgWebhookRepo) GetDelivery(ctx context.Context, tenantID string, id int64) (*domain.WebhookDelivery, error) {
+	d, err := scanDelivery(r.pool.QueryRow(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE delivery_id = $1 AND tenant_id = $2`, id, tenantID,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("postgres: get delivery: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT url, COALESCE(status_code, 0), COALESCE(error, ''), duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt_id`, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: list delivery attempts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := &domain.WebhookAttempt{}
+		if err := rows.Scan(&a.URL, &a.StatusCode, &a.Error, &a.DurationMs, &a.AttemptedAt); err != nil {
+			return nil, fmt.Errorf("postgres: scan delivery attempt: %w", err)
+		}
+		d.History = append(d.History, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: list delivery attempts: %w", err)
+	}
+	return d, nil
+}
+
+func (r *pgWebhookRepo) RedriveDelivery(ctx context.Context, tenantID string, id int64, now time.Time) error {
+	var status domain.DeliveryStatus
+	err := r.pool.QueryRow(ctx, `
+		WITH target AS (
+			SELECT delivery_id, status FROM webhook_deliveries
+			WHERE delivery_id = $1 AND tenant_id = $2
+			FOR UPDATE
+		), redriven AS (
+			UPDATE webhook_deliveries d
+			SET status = $4, attempts = 0, redrives = d.redrives + 1,
+			    next_attempt_at = $3, delivered_at = NULL
+			FROM target t
+			WHERE d.delivery_id = t.delivery_id AND t.status <> $4
+		)
+		SELECT status FROM target`,
+		id, tenantID, now, domain.DeliveryPending,
+	).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrDeliveryNotFound
+		}
+		return fmt.Errorf("postgres: redrive delivery: %w", err)
+	}
+	if status == domain.DeliveryPending {
+		return domain.ErrDeliveryPending
+	}
+	return nil
+}
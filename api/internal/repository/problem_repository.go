@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// ProblemRepository defines persistence for problems and their versioned test data.
+// Implementations must be safe for concurrent use.
+type ProblemRepository interface {
+	// Create inserts a problem together with version 1 of its test cases,
+	// subtasks and generator.
+	Create(ctx context.Context, problem *domain.Problem) error
+
+	// GetByID retrieves a problem and the test data of its current version.
+	GetByID(ctx context.Context, id string) (*domain.Problem, error)
+
+	// ReplaceTestData stores the generator, comparator and interactor (each
+	// nil for none), harnesses, rules, kill patterns, subtasks and cases as a
+	// new test-data version and returns it.
+	ReplaceTestData(ctx context.Context, id string, generator *domain.Generator, comparator *domain.Comparator, interactor *domain.Interactor, harnesses []domain.Harness, rules []domain.SourceRule, killPatterns []domain.KillPattern, subtasks []domain.Subtask, cases []domain.TestCase) (int, error)
+}
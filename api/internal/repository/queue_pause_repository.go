@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// QueuePauseStore records the languages whose jobs workers hold back.
+// Workers check it for every job they receive.
+type QueuePauseStore interface {
+	// Pause pauses a language and returns when it was paused. Pausing a
+	// paused language keeps its time.
+	Pause(ctx context.Context, lang domain.Language) (time.Time, error)
+
+	// Resume resumes a language, reporting whether it was paused.
+	Resume(ctx context.Context, lang domain.Language) (bool, error)
+
+	// List returns when each paused language was paused.
+	List(ctx context.Context) (map[domain.Language]time.Time, error)
+}
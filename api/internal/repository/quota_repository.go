@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// QuotaStore exposes per-tenant execution usage for the current quota window.
+// Usage is written by the worker after each execution; the API only reads it.
+type QuotaStore interface {
+	// Usage returns the cumulative execution time consumed by the tenant in the
+	// current window.
+	Usage(ctx context.Context, tenantID string) (time.Duration, error)
+
+	// ResetAt returns when the current quota window ends.
+	ResetAt(now time.Time) time.Time
+}
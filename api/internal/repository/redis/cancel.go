@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+var _ repository.CancelSignal = (*redisCancelSignal)(nil)
+
+const (
+	// cancelChannel and cancelKeyPrefix must match what the worker
+	// subscribes to and checks as it starts a job.
+	cancelChannel   = "sentinel:cancel"
+	cancelKeyPrefix = "sentinel:cancel:"
+
+	// cancelKeyTTL outlives any job's time limit.
+	cancelKeyTTL = time.Hour
+)
+
+type redisCancelSignal struct {
+	client *goredis.Client
+}
+
+// NewRedisCancelSignal creates a signal publishing cancellation requests on
+// a Redis channel every worker subscribes to. A key marks the job as well,
+// for a worker that has not yet registered it when the message goes out.
+func NewRedisCancelSignal(client *goredis.Client) repository.CancelSignal {
+	return &redisCancelSignal{client: client}
+}
+
+func (r *redisCancelSignal) RequestCancel(ctx context.Context, id uuid.UUID) error {
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, cancelKeyPrefix+id.String(), 1, cancelKeyTTL)
+	pipe.Publish(ctx, cancelChannel, id.String())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis: request cancel: %w", err)
+	}
+	return nil
+}
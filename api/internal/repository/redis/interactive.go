@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+var _ repository.InteractiveStore = (*redisInteractiveStore)(nil)
+
+const (
+	// interactiveKeyPrefix must match the prefix the worker reads stdin from
+	// and writes output to.
+	interactiveKeyPrefix = "sentinel:interactive:"
+
+	// interactiveKeyTTL outlives any job's time limit plus its queueing.
+	interactiveKeyTTL = time.Hour
+
+	// interactiveMaxEntries caps each stream; stdin past it is dropped
+	// before the worker reads it.
+	interactiveMaxEntries = 1000
+)
+
+type redisInteractiveStore struct {
+	client *goredis.Client
+}
+
+// NewRedisInteractiveStore creates a store keeping each interactive job's
+// stdin and stdout in a Redis stream of its own.
+func NewRedisInteractiveStore(client *goredis.Client) repository.InteractiveStore {
+	return &redisInteractiveStore{client: client}
+}
+
+func interactiveKey(id uuid.UUID, stream string) string {
+	return interactiveKeyPrefix + id.String() + ":" + stream
+}
+
+func (r *redisInteractiveStore) SendStdin(ctx context.Context, id uuid.UUID, data string) error {
+	return r.addStdin(ctx, id, map[string]interface{}{"data": data})
+}
+
+func (r *redisInteractiveStore) CloseStdin(ctx context.Context, id uuid.UUID) error {
+	return r.addStdin(ctx, id, map[string]interface{}{"eof": "1"})
+}
+
+func (r *redisInteractiveStore) addStdin(ctx context.Context, id uuid.UUID, values map[string]interface{}) error {
+	key := interactiveKey(id, "stdin")
+	pipe := r.client.TxPipeline()
+	pipe.XAdd(ctx, &goredis.XAddArgs{Stream: key, MaxLen: interactiveMaxEntries, Values: values})
+	pipe.Expire(ctx, key, interactiveKeyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis: send stdin: %w", err)
+	}
+	return nil
+}
+
+func (r *redisInteractiveStore) ReadOutput(ctx context.Context, id uuid.UUID, cursor string) (string, string, error) {
+	if cursor == "" {
+		cursor = "0"
+	}
+	// A negative Block reads without blocking.
+	streams, err := r.client.XRead(ctx, &goredis.XReadArgs{
+		Streams: []string{interactiveKey(id, "stdout"), cursor},
+		Count:   100,
+		Block:   -1,
+	}).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", cursor, nil
+	}
+	if err != nil {
+		return "", cursor, fmt.Errorf("redis: read output: %w", err)
+	}
+	var out strings.Builder
+	for _, s := range streams {
+		for _, msg := range s.Messages {
+			cursor = msg.ID
+			if data, ok := msg.Values["data"].(string); ok {
+				out.WriteString(data)
+			}
+		}
+	}
+	return out.String(), cursor, nil
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+var _ repository.LockStore = (*redisLockStore)(nil)
+
+// lockKeyPrefix must match the prefix used by the worker's idempotency store,
+// which keeps the Unix second the lock was taken as its value.
+const lockKeyPrefix = "sentinel:lock:"
+
+// deleteIfEqual deletes KEYS[1] only while it still holds ARGV[1], so a lock
+// retaken between reading and deleting it survives.
+var deleteIfEqual = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+type redisLockStore struct {
+	client *goredis.Client
+}
+
+// NewRedisLockStore creates a store over the workers' processing locks.
+func NewRedisLockStore(client *goredis.Client) repository.LockStore {
+	return &redisLockStore{client: client}
+}
+
+// AcquiredAt reads the time a lock was taken.
+func (r *redisLockStore) AcquiredAt(ctx context.Context, lockID uuid.UUID) (time.Time, bool, error) {
+	value, err := r.client.Get(ctx, lockKeyPrefix+lockID.String()).Result()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("redis: get lock: %w", err)
+	}
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("redis: lock %s holds %q, not a timestamp", lockID, value)
+	}
+	return time.Unix(unix, 0), true, nil
+}
+
+// DeleteIfAcquiredAt deletes a lock unless it was retaken meanwhile.
+func (r *redisLockStore) DeleteIfAcquiredAt(ctx context.Context, lockID uuid.UUID, acquiredAt time.Time) (bool, error) {
+	n, err := deleteIfEqual.Run(ctx, r.client, []string{lockKeyPrefix + lockID.String()}, acquiredAt.Unix()).Int()
+	if err != nil {
+		return false, fmt.Errorf("redis: delete lock: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Tombstone takes a lock as a worker would, stamped with the current time.
+func (r *redisLockStore) Tombstone(ctx context.Context, lockID uuid.UUID, ttl time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, lockKeyPrefix+lockID.String(), time.Now().Unix(), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis: tombstone lock: %w", err)
+	}
+	return ok, nil
+}
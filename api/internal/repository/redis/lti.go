@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+var _ repository.LTILoginStore = (*redisLTILoginStore)(nil)
+
+const ltiLoginKeyPrefix = "sentinel:lti:login:"
+
+type redisLTILoginStore struct {
+	client *goredis.Client
+}
+
+// NewRedisLTILoginStore creates a store keeping each pending OIDC login in
+// an expiring Redis key, deleted as the launch reads it.
+func NewRedisLTILoginStore(client *goredis.Client) repository.LTILoginStore {
+	return &redisLTILoginStore{client: client}
+}
+
+func (r *redisLTILoginStore) PutLogin(ctx context.Context, state string, login *domain.LTILogin, ttl time.Duration) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return fmt.Errorf("redis: encode lti login: %w", err)
+	}
+	if err := r.client.Set(ctx, ltiLoginKeyPrefix+state, data, ttl).Err(); err != nil {
+		return fmt.Errorf("redis: put lti login: %w", err)
+	}
+	return nil
+}
+
+func (r *redisLTILoginStore) TakeLogin(ctx context.Context, state string) (*domain.LTILogin, error) {
+	data, err := r.client.GetDel(ctx, ltiLoginKeyPrefix+state).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, fmt.Errorf("%w: unknown or expired state", domain.ErrInvalidLTILaunch)
+		}
+		return nil, fmt.Errorf("redis: take lti login: %w", err)
+	}
+	var login domain.LTILogin
+	if err := json.Unmarshal(data, &login); err != nil {
+		return nil, fmt.Errorf("redis: decode lti login: %w", err)
+	}
+	return &login, nil
+}
@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+var _ repository.QueuePauseStore = (*redisQueuePauseStore)(nil)
+
+// queuePausesKey must match the hash the worker checks for each job's
+// language. It maps paused languages to the Unix millisecond they were
+// paused at.
+const queuePausesKey = "sentinel:queue:paused"
+
+type redisQueuePauseStore struct {
+	client *goredis.Client
+}
+
+// NewRedisQueuePauseStore creates a store of paused languages in a Redis
+// hash.
+func NewRedisQueuePauseStore(client *goredis.Client) repository.QueuePauseStore {
+	return &redisQueuePauseStore{client: client}
+}
+
+func (r *redisQueuePauseStore) Pause(ctx context.Context, lang domain.Language) (time.Time, error) {
+	pipe := r.client.TxPipeline()
+	pipe.HSetNX(ctx, queuePausesKey, string(lang), time.Now().UnixMilli())
+	get := pipe.HGet(ctx, queuePausesKey, string(lang))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("redis: pause %s: %w", lang, err)
+	}
+	return parsePausedAt(lang, get.Val())
+}
+
+func (r *redisQueuePauseStore) Resume(ctx context.Context, lang domain.Language) (bool, error) {
+	n, err := r.client.HDel(ctx, queuePausesKey, string(lang)).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis: resume %s: %w", lang, err)
+	}
+	return n > 0, nil
+}
+
+func (r *redisQueuePauseStore) List(ctx context.Context) (map[domain.Language]time.Time, error) {
+	values, err := r.client.HGetAll(ctx, queuePausesKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis: list paused languages: %w", err)
+	}
+	paused := make(map[domain.Language]time.Time, len(values))
+	for lang, value := range values {
+		at, err := parsePausedAt(domain.Language(lang), value)
+		if err != nil {
+			return nil, err
+		}
+		paused[domain.Language(lang)] = at
+	}
+	return paused, nil
+}
+
+func parsePausedAt(lang domain.Language, value string) (time.Time, error) {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("redis: pause of %s holds %q, not a timestamp", lang, value)
+	}
+	return time.UnixMilli(ms).UTC(), nil
+}
@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+var _ repository.QuotaStore = (*redisQuotaStore)(nil)
+
+// quotaKeyPrefix must match the prefix used by the worker when recording usage.
+const quotaKeyPrefix = "sentinel:quota:"
+
+type redisQuotaStore struct {
+	client *goredis.Client
+}
+
+// NewRedisQuotaStore creates a Redis-backed quota store. Usage is kept in one
+// counter per tenant per UTC day (milliseconds), so windows reset at midnight UTC.
+func NewRedisQuotaStore(client *goredis.Client) repository.QuotaStore {
+	return &redisQuotaStore{client: client}
+}
+
+// Usage reads the tenant's millisecond counter for the current UTC day.
+func (r *redisQuotaStore) Usage(ctx context.Context, tenantID string) (time.Duration, error) {
+	ms, err := r.client.Get(ctx, QuotaKey(tenantID, time.Now())).Int64()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("redis: get quota usage: %w", err)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// ResetAt returns the next midnight UTC after now.
+func (r *redisQuotaStore) ResetAt(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// QuotaKey returns the Redis key holding a tenant's usage for the UTC day containing t.
+func QuotaKey(tenantID string, t time.Time) string {
+	return quotaKeyPrefix + tenantID + ":" + t.UTC().Format("20060102")
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// RuntimeRepository defines persistence for runtimes registered through the
+// API. Implementations must be safe for concurrent use.
+type RuntimeRepository interface {
+	// Create inserts a new runtime, returning domain.ErrRuntimeExists if the
+	// name is taken.
+	Create(ctx context.Context, rt *domain.Runtime) error
+
+	// GetByName retrieves a runtime, returning domain.ErrRuntimeNotFound if
+	// there is none.
+	GetByName(ctx context.Context, name domain.Language) (*domain.Runtime, error)
+
+	// List returns every runtime ordered by name.
+	List(ctx context.Context) ([]*domain.Runtime, error)
+
+	// Update replaces a runtime's definition.
+	Update(ctx context.Context, rt *domain.Runtime) error
+
+	// Delete removes a runtime.
+	Delete(ctx context.Context, name domain.Language) error
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// ScheduleRepository defines persistence for tenants' recurring schedules.
+// Implementations must be safe for concurrent use.
+type ScheduleRepository interface {
+	// Create inserts a new schedule.
+	Create(ctx context.Context, s *domain.Schedule) error
+
+	// Get retrieves a tenant's schedule, returning domain.ErrScheduleNotFound
+	// if there is none.
+	Get(ctx context.Context, tenantID string, id uuid.UUID) (*domain.Schedule, error)
+
+	// List returns a tenant's schedules, oldest first.
+	List(ctx context.Context, tenantID string) ([]*domain.Schedule, error)
+
+	// Delete removes a tenant's schedule, returning
+	// domain.ErrScheduleNotFound if there is none. Jobs it created are kept.
+	Delete(ctx context.Context, tenantID string, id uuid.UUID) error
+
+	// ListDue returns up to limit schedules due by now, most overdue first.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.Schedule, error)
+
+	// Advance moves a schedule's next run from due to next, reporting
+	// false if it was no longer due at due: another caller advanced it
+	// first, or it was deleted. Only the caller that advanced a run
+	// performs it.
+	Advance(ctx context.Context, id uuid.UUID, due, next time.Time) (bool, error)
+
+	// RecordRun stores the outcome of a schedule's latest run.
+	RecordRun(ctx context.Context, id uuid.UUID, run *domain.ScheduleRun) error
+}
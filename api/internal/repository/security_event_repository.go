@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// SecurityEventRepository defines read access to the security events
+// workers record. Implementations must be safe for concurrent use.
+type SecurityEventRepository interface {
+	// List returns up to limit events matching filter recorded after the
+	// event afterEventID and before settledBefore, in event order.
+	List(ctx context.Context, filter domain.SecurityEventFilter, afterEventID int64, settledBefore time.Time, limit int) ([]*domain.SecurityEvent, error)
+}
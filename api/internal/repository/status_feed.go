@@ -0,0 +1,14 @@
+package repository
+
+import "github.com/google/uuid"
+
+// StatusFeed tells the API when a job's status changes, whichever service
+// changed it, so that streams need not poll for it.
+type StatusFeed interface {
+	// Subscribe returns a channel that receives a value after the job's
+	// status changes, and a function that ends the subscription. Changes
+	// in quick succession may arrive as one value. A change the feed may
+	// have missed, such as one made while it was reconnecting, is sent
+	// to every subscriber, so each rechecks its job rather than waits.
+	Subscribe(id uuid.UUID) (<-chan struct{}, func())
+}
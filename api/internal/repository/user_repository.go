@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// UserRepository defines persistence for users, which are stored with the
+// bcrypt hash of their password or, for users of an OIDC provider, its
+// issuer and their subject. Implementations must be safe for
+// concurrent use.
+type UserRepository interface {
+	// Create inserts a new user with the hash of its password. It returns
+	// domain.ErrUserExists if the email already has a user.
+	Create(ctx context.Context, user *domain.User, passwordHash []byte) error
+
+	// GetByEmail retrieves a password user and its password hash,
+	// returning domain.ErrUserNotFound if there is none.
+	GetByEmail(ctx context.Context, email string) (*domain.User, []byte, error)
+
+	// UpsertExternal records the user the OIDC provider issuer knows as
+	// subject, updating its tenant and email if it exists. user's ID is
+	// used only for a new user; the stored ID and creation time are set
+	// on user.
+	UpsertExternal(ctx context.Context, user *domain.User, issuer, subject string) error
+}
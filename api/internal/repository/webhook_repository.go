@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// WebhookRepository defines persistence for tenants' webhook endpoints and
+// the deliveries owed to their result webhooks. Deliveries are recorded by
+// the database when a job finishes. Implementations must be safe for
+// concurrent use.
+type WebhookRepository interface {
+	// PutDLQWebhook creates or replaces a tenant's dead-letter webhook.
+	PutDLQWebhook(ctx context.Context, hook *domain.DLQWebhook) error
+
+	// GetDLQWebhook retrieves a tenant's dead-letter webhook, returning
+	// domain.ErrWebhookNotFound if there is none.
+	GetDLQWebhook(ctx context.Context, tenantID string) (*domain.DLQWebhook, error)
+
+	// DeleteDLQWebhook removes a tenant's dead-letter webhook, returning
+	// domain.ErrWebhookNotFound if there is none.
+	DeleteDLQWebhook(ctx context.Context, tenantID string) error
+
+	// PutResultWebhook creates or replaces a tenant's result webhook,
+	// closing its circuit.
+	PutResultWebhook(ctx context.Context, hook *domain.ResultWebhook) error
+
+	// GetResultWebhook retrieves a tenant's result webhook, returning
+	// domain.ErrWebhookNotFound if there is none.
+	GetResultWebhook(ctx context.Context, tenantID string) (*domain.ResultWebhook, error)
+
+	// DeleteResultWebhook removes a tenant's result webhook and fails its
+	// pending deliveries, returning domain.ErrWebhookNotFound if there is
+	// none.
+	DeleteResultWebhook(ctx context.Context, tenantID string) error
+
+	// ClaimDueDeliveries returns up to limit pending deliveries due by now
+	// whose webhook's circuit is closed, oldest first, and pushes their
+	// next attempt to now+lease so that no other caller claims them while
+	// they are sent.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.WebhookDelivery, error)
+
+	// RecordDeliveryAttempt appends attempt to a delivery's history and
+	// stores the delivery's and its webhook's new state in one transaction.
+	RecordDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery, attempt *domain.WebhookAttempt, hook *domain.ResultWebhook) error
+
+	// DeferDelivery moves a pending delivery's next attempt to until
+	// without counting an attempt.
+	DeferDelivery(ctx context.Context, id int64, until time.Time) error
+
+	// ListDeliveries returns up to limit of a tenant's deliveries with an ID
+	// below before, newest first, only those in status unless it is empty.
+	// A zero before starts at the newest.
+	ListDeliveries(ctx context.Context, tenantID string, status domain.DeliveryStatus, before int64, limit int) ([]*domain.WebhookDelivery, error)
+
+	// GetDelivery retrieves a tenant's delivery with its history, returning
+	// domain.ErrDeliveryNotFound if there is none.
+	GetDelivery(ctx context.Context, tenantID string, id int64) (*domain.WebhookDelivery, error)
+
+	// RedriveDelivery makes a tenant's delivered or failed delivery pending
+	// again, due at now with a fresh set of attempts. It returns
+	// domain.ErrDeliveryNotFound if there is none and
+	// domain.ErrDeliveryPending if it is still pending.
+	RedriveDelivery(ctx context.Context, tenantID string, id int64, now time.Time) error
+}
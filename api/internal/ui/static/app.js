@@ -0,0 +1,246 @@
+// Sentinel's embedded UI. It talks to the v2 API of the server it is
+// served from; nothing is stored beyond the admin token, kept for the
+// browser session.
+"use strict";
+
+const API = "/api/v2";
+const PENDING = ["QUEUED", "COMPILING", "RUNNING"];
+const OK = ["SUCCESS", "ACCEPTED"];
+
+const $ = (id) => document.getElementById(id);
+
+let stream = null;
+let currentJob = null;
+let nextCursor = "";
+
+// api calls the API and returns the decoded body, throwing the API's error
+// message on failure.
+async function api(method, path, { body, headers = {} } = {}) {
+  const init = { method, headers: { ...headers } };
+  if (body !== undefined) {
+    init.headers["Content-Type"] = "application/json";
+    init.body = JSON.stringify(body);
+  }
+  const resp = await fetch(API + path, init);
+  const data = resp.status === 204 ? null : await resp.json().catch(() => null);
+  if (!resp.ok) {
+    throw new Error((data && data.error) || `${resp.status} ${resp.statusText}`);
+  }
+  return data;
+}
+
+function statusClass(status) {
+  if (PENDING.includes(status)) return "pending";
+  return OK.includes(status) ? "ok" : "bad";
+}
+
+// --- Views ---
+
+function showView(name) {
+  for (const tab of document.querySelectorAll(".tab")) {
+    tab.classList.toggle("active", tab.dataset.view === name);
+  }
+  for (const view of document.querySelectorAll(".view")) {
+    view.hidden = view.id !== `view-${name}`;
+  }
+  if (name === "jobs" && $("admin-token").value) {
+    loadJobs();
+  }
+}
+
+// --- Submitting and following a job ---
+
+async function loadLanguages() {
+  const select = $("language");
+  try {
+    const { languages } = await api("GET", "/languages");
+    for (const lang of languages) {
+      const option = document.createElement("option");
+      option.value = lang.name;
+      option.textContent = lang.version ? `${lang.name} (${lang.version})` : lang.name;
+      select.append(option);
+    }
+  } catch (err) {
+    $("submit-error").textContent = `Could not load languages: ${err.message}`;
+  }
+}
+
+async function submit(event) {
+  event.preventDefault();
+  $("submit-error").textContent = "";
+  $("submit-button").disabled = true;
+  const headers = {};
+  const tenant = $("tenant").value.trim();
+  if (tenant) headers["X-Tenant-ID"] = tenant;
+  try {
+    const resp = await api("POST", "/submissions", {
+      headers,
+      body: {
+        language: $("language").value,
+        source_code: $("source").value,
+        stdin: $("stdin").value,
+      },
+    });
+    follow(resp.job_id);
+  } catch (err) {
+    $("submit-error").textContent = err.message;
+  } finally {
+    $("submit-button").disabled = false;
+  }
+}
+
+function renderJob(job) {
+  currentJob = job;
+  $("job").hidden = false;
+  $("job-id").textContent = job.job_id;
+  const status = $("job-status");
+  status.textContent = job.status;
+  status.className = `status ${statusClass(job.status)}`;
+  $("cancel-button").hidden = !PENDING.includes(job.status);
+  $("rerun-button").hidden = PENDING.includes(job.status);
+
+  const usage = [];
+  if (job.time_used_ms != null) usage.push(`${job.time_used_ms} ms`);
+  if (job.memory_used_kb != null) usage.push(`${job.memory_used_kb} KB`);
+  if (job.exit_code != null) usage.push(`exit code ${job.exit_code}`);
+  if (job.score != null) usage.push(`score ${job.score}/${job.max_score}`);
+  $("job-usage").textContent = usage.join(" · ");
+
+  $("job-stdout").textContent = job.stdout || "";
+  $("job-stderr").textContent = job.stderr || "";
+}
+
+function renderTimeline(events) {
+  const list = $("job-timeline");
+  list.replaceChildren();
+  for (const ev of events) {
+    const item = document.createElement("li");
+    item.textContent = `${ev.status} ${new Date(ev.created_at).toLocaleTimeString()}`;
+    list.append(item);
+  }
+}
+
+// follow shows a job and streams its updates until it finishes.
+function follow(jobID) {
+  if (stream) stream.close();
+  showView("submit");
+  $("submit-error").textContent = "";
+  $("job-timeline").replaceChildren();
+  $("job-stdout").textContent = "";
+  $("job-stderr").textContent = "";
+
+  const scheme = location.protocol === "https:" ? "wss" : "ws";
+  const ws = new WebSocket(`${scheme}://${location.host}${API}/submissions/${jobID}/stream`);
+  stream = ws;
+  const timeline = [];
+  ws.onmessage = (msg) => {
+    const data = JSON.parse(msg.data);
+    if (data.output !== undefined) {
+      $("job-stdout").textContent += data.output;
+      return;
+    }
+    if (data.timeline) timeline.push(...data.timeline);
+    else if (timeline.length === 0 || timeline[timeline.length - 1].status !== data.status) {
+      timeline.push({ status: data.status, created_at: data.updated_at });
+    }
+    renderTimeline(timeline);
+    renderJob(data);
+  };
+  ws.onclose = (ev) => {
+    if (stream !== ws) return;
+    stream = null;
+    // 4000 means the job finished. Otherwise the last state stays shown.
+    if (ev.code !== 4000 && ev.code !== 1000) {
+      $("submit-error").textContent = `Stream closed (${ev.code})${ev.reason ? ": " + ev.reason : ""}`;
+    }
+  };
+}
+
+async function cancel() {
+  if (!currentJob) return;
+  try {
+    await api("POST", `/submissions/${currentJob.job_id}/cancel`);
+  } catch (err) {
+    $("submit-error").textContent = err.message;
+  }
+}
+
+async function rerun() {
+  if (!currentJob) return;
+  try {
+    const resp = await api("POST", `/submissions/${currentJob.job_id}/rerun`);
+    follow(resp.job_id);
+  } catch (err) {
+    $("submit-error").textContent = err.message;
+  }
+}
+
+// --- Admin: recent jobs ---
+
+// loadJobs lists the newest jobs, or with more the page after those shown.
+async function loadJobs(event, more = false) {
+  if (event) event.preventDefault();
+  $("jobs-error").textContent = "";
+  const token = $("admin-token").value;
+  sessionStorage.setItem("sentinel-admin-token", token);
+  const params = new URLSearchParams({ limit: "100" });
+  const status = $("jobs-status").value;
+  if (status) params.set("status", status);
+  if (more) params.set("cursor", nextCursor);
+
+  const body = $("jobs-body");
+  try {
+    const page = await api("GET", `/admin/jobs?${params}`, {
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    const jobs = page.jobs;
+    if (!more) body.replaceChildren();
+    nextCursor = page.next_cursor || "";
+    $("jobs-more").hidden = !nextCursor;
+    for (const job of jobs) {
+      const row = document.createElement("tr");
+      const cells = [
+        new Date(job.created_at).toLocaleString(),
+        job.job_id,
+        job.tenant_id,
+        job.language,
+        job.problem_id || "",
+        job.status,
+      ];
+      for (const text of cells) {
+        const cell = document.createElement("td");
+        cell.textContent = text;
+        row.append(cell);
+      }
+      row.lastChild.className = `status ${statusClass(job.status)}`;
+      row.addEventListener("click", () => follow(job.job_id));
+      body.append(row);
+    }
+    if (jobs.length === 0 && !more) {
+      const row = document.createElement("tr");
+      const cell = document.createElement("td");
+      cell.colSpan = 6;
+      cell.className = "muted";
+      cell.textContent = "No jobs";
+      row.append(cell);
+      body.append(row);
+    }
+  } catch (err) {
+    if (!more) body.replaceChildren();
+    $("jobs-error").textContent = err.message;
+  }
+}
+
+// --- Wiring ---
+
+for (const tab of document.querySelectorAll(".tab")) {
+  tab.addEventListener("click", () => showView(tab.dataset.view));
+}
+$("submit-form").addEventListener("submit", submit);
+$("cancel-button").addEventListener("click", cancel);
+$("rerun-button").addEventListener("click", rerun);
+$("jobs-form").addEventListener("submit", loadJobs);
+$("jobs-status").addEventListener("change", () => loadJobs());
+$("jobs-more").addEventListener("click", () => loadJobs(null, true));
+$("admin-token").value = sessionStorage.getItem("sentinel-admin-token") || "";
+loadLanguages();
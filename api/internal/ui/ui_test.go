@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_ServesEmbeddedFiles(t *testing.T) {
+	h := Handler("/ui/")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/ui/")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<script src="app.js">`) {
+		t.Fatalf("index: got %d: %.200s", w.Code, w.Body.String())
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'self'") {
+		t.Errorf("Content-Security-Policy = %q", csp)
+	}
+	for _, path := range []string{"/ui/app.js", "/ui/style.css"} {
+		if w := get(path); w.Code != http.StatusOK {
+			t.Errorf("%s: got %d", path, w.Code)
+		}
+	}
+	if w := get("/ui/missing.js"); w.Code != http.StatusNotFound {
+		t.Errorf("missing file: got %d", w.Code)
+	}
+}
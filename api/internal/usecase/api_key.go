@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	// apiKeyScheme starts every API key, so a leaked one is easy to
+	// recognize.
+	apiKeyScheme = "snt_"
+	// apiKeyPrefixLen is how much of a key is kept to tell keys apart.
+	apiKeyPrefixLen = len(apiKeyScheme) + 8
+
+	maxAPIKeyTenantLen = 200
+	maxAPIKeyNameLen   = 200
+)
+
+// APIKeyUsecase issues, rotates and revokes API keys and authenticates
+// requests by them. Keys are stored only as their SHA-256 hash; they are
+// random enough that a slow hash would add nothing.
+type APIKeyUsecase struct {
+	keys   repository.APIKeyRepository
+	logger *zap.Logger
+}
+
+// NewAPIKeyUsecase creates a new APIKeyUsecase.
+func NewAPIKeyUsecase(keys repository.APIKeyRepository, logger *zap.Logger) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// Issue creates a key for a tenant. The returned key carries the key
+// itself, which is not stored and cannot be shown again.
+func (uc *APIKeyUsecase) Issue(ctx context.Context, req *domain.IssueAPIKeyRequest) (*domain.IssuedAPIKey, error) {
+	if req.TenantID == "" || len(req.TenantID) > maxAPIKeyTenantLen || strings.TrimSpace(req.TenantID) != req.TenantID {
+		return nil, fmt.Errorf("%w: tenant_id must be 1-%d bytes without surrounding spaces", domain.ErrInvalidAPIKeyRequest, maxAPIKeyTenantLen)
+	}
+	if len(req.Name) > maxAPIKeyNameLen {
+		return nil, fmt.Errorf("%w: name longer than %d bytes", domain.ErrInvalidAPIKeyRequest, maxAPIKeyNameLen)
+	}
+	scopes := slices.Clone(domain.DefaultAPIKeyScopes)
+	if len(req.Scopes) > 0 {
+		scopes = nil
+		for _, s := range req.Scopes {
+			if !slices.Contains(domain.Scopes, s) {
+				return nil, fmt.Errorf("%w: unknown scope %q", domain.ErrInvalidAPIKeyRequest, s)
+			}
+			if !slices.Contains(scopes, s) {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate UUIDv7: %w", err)
+	}
+	secret, hash, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &domain.APIKey{KeyID: id, TenantID: req.TenantID, Name: req.Name, Prefix: secret[:apiKeyPrefixLen], Scopes: scopes}
+	if err := uc.keys.Create(ctx, key, hash); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("API key issued",
+		zap.String("key_id", id.String()),
+		zap.String("tenant_id", key.TenantID),
+		zap.Strings("scopes", key.Scopes),
+	)
+	return &domain.IssuedAPIKey{APIKey: *key, Key: secret}, nil
+}
+
+// List returns a tenant's keys, or every key when tenantID is empty,
+// oldest first.
+func (uc *APIKeyUsecase) List(ctx context.Context, tenantID string) ([]*domain.APIKey, error) {
+	return uc.keys.List(ctx, tenantID)
+}
+
+// Rotate gives a key a new key, which is returned, and stops the old one
+// from authenticating. Jobs stay associated with the key.
+func (uc *APIKeyUsecase) Rotate(ctx context.Context, id uuid.UUID) (*domain.IssuedAPIKey, error) {
+	secret, hash, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key, err := uc.keys.Rotate(ctx, id, secret[:apiKeyPrefixLen], hash)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Info("API key rotated", zap.String("key_id", id.String()), zap.String("tenant_id", key.TenantID))
+	return &domain.IssuedAPIKey{APIKey: *key, Key: secret}, nil
+}
+
+// Revoke stops a key from authenticating. It is kept, revoked, for the
+// jobs submitted with it.
+func (uc *APIKeyUsecase) Revoke(ctx context.Context, id uuid.UUID) error {
+	if err := uc.keys.Revoke(ctx, id); err != nil {
+		return err
+	}
+	uc.logger.Info("API key revoked", zap.String("key_id", id.String()))
+	return nil
+}
+
+// Authenticate returns the key a request presented, or
+// domain.ErrInvalidAPIKey if it is unknown or revoked.
+func (uc *APIKeyUsecase) Authenticate(ctx context.Context, secret string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyScheme) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	sum := sha256.Sum256([]byte(secret))
+	key, err := uc.keys.GetByHash(ctx, sum[:])
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("authenticate api key: %w", err)
+	}
+	if key.RevokedAt != nil {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	return key, nil
+}
+
+// newAPIKey generates a key and its hash.
+func newAPIKey() (string, []byte, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("generate api key: %w", err)
+	}
+	secret := apiKeyScheme + hex.EncodeToString(buf)
+	sum := sha256.Sum256([]byte(secret))
+	return secret, sum[:], nil
+}
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	maxAppealTextLen = 4000
+	maxActorLen      = 128
+)
+
+// AppealUsecase lets users appeal judged submissions and admins review them:
+// rerun with every case output kept, override the verdict, and respond.
+type AppealUsecase struct {
+	appeals   repository.AppealRepository
+	jobs      repository.JobRepository
+	publisher publisher.Publisher
+	logger    *zap.Logger
+}
+
+// NewAppealUsecase creates a new AppealUsecase.
+func NewAppealUsecase(appeals repository.AppealRepository, jobs repository.JobRepository, pub publisher.Publisher, logger *zap.Logger) *AppealUsecase {
+	return &AppealUsecase{
+		appeals:   appeals,
+		jobs:      jobs,
+		publisher: pub,
+		logger:    logger,
+	}
+}
+
+// Open files an appeal against a finished judged submission.
+func (uc *AppealUsecase) Open(ctx context.Context, jobID uuid.UUID, tenantID string, req *domain.OpenAppealRequest) (*domain.Appeal, error) {
+	if err := validateAppealText("note", req.Note); err != nil {
+		return nil, err
+	}
+	job, err := uc.appealableJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	appealID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate UUIDv7: %w", err)
+	}
+	appeal := &domain.Appeal{
+		AppealID: appealID,
+		JobID:    job.JobID,
+		TenantID: tenantID,
+		Status:   domain.AppealOpen,
+		Note:     req.Note,
+	}
+	if err := uc.appeals.Create(ctx, appeal); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Appeal opened", zap.String("appeal_id", appealID.String()), zap.String("job_id", jobID.String()))
+	return appeal, nil
+}
+
+// Get returns an appeal with its override history. Another tenant's appeal
+// is reported as not found unless tenantID is empty, as for admins.
+func (uc *AppealUsecase) Get(ctx context.Context, id uuid.UUID, tenantID string) (*domain.Appeal, error) {
+	appeal, err := uc.appeals.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenantID != "" && appeal.TenantID != tenantID {
+		return nil, domain.ErrAppealNotFound
+	}
+	return appeal, nil
+}
+
+// List returns the tenant's appeals in the given state, oldest first, or
+// every tenant's if tenantID is empty, as for admins.
+func (uc *AppealUsecase) List(ctx context.Context, tenantID string, status domain.AppealStatus) ([]*domain.Appeal, error) {
+	if !status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidAppeal, status)
+	}
+	return uc.appeals.List(ctx, tenantID, status)
+}
+
+// Rerun requeues the appealed submission in debug mode, so the worker keeps
+// every test case's output for review. The new verdict replaces the old one
+// like any rejudge.
+func (uc *AppealUsecase) Rerun(ctx context.Context, id uuid.UUID, actor string) (*domain.RerunResponse, error) {
+	appeal, err := uc.openAppeal(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.appealableJob(ctx, appeal.JobID); err != nil {
+		return nil, err
+	}
+
+	job, err := uc.jobs.PrepareRejudge(ctx, appeal.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("prepare rerun: %w", err)
+	}
+	job.Debug = true
+	if err := uc.publisher.Publish(ctx, job); err != nil {
+		uc.logger.Error("Failed to publish appeal rerun", zap.Error(err), zap.String("job_id", job.JobID.String()))
+		_ = uc.jobs.UpdateStatus(ctx, job.JobID, domain.StatusInternalError)
+		return nil, domain.ErrPublishFailed
+	}
+
+	uc.logger.Info("Appeal rerun queued",
+		zap.String("appeal_id", id.String()),
+		zap.String("job_id", job.JobID.String()),
+		zap.Int("judge_revision", job.JudgeRevision),
+		zap.String("actor", actor),
+	)
+	return &domain.RerunResponse{AppealID: id, JobID: job.JobID, JudgeRevision: job.JudgeRevision}, nil
+}
+
+// Override replaces the submission's verdict and records who did it and why.
+func (uc *AppealUsecase) Override(ctx context.Context, id uuid.UUID, actor string, req *domain.OverrideVerdictRequest) (*domain.Appeal, error) {
+	appeal, err := uc.openAppeal(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAppealText("reason", req.Reason); err != nil {
+		return nil, err
+	}
+	if !req.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: %q is not a final verdict", domain.ErrInvalidAppeal, req.Status)
+	}
+	job, err := uc.appealableJob(ctx, appeal.JobID)
+	if err != nil {
+		return nil, err
+	}
+
+	score := job.Score
+	if req.Score != nil {
+		if job.MaxScore == nil {
+			return nil, fmt.Errorf("%w: the submission is not scored", domain.ErrInvalidAppeal)
+		}
+		if s := *req.Score; s < 0 || s > *job.MaxScore || math.IsNaN(s) {
+			return nil, fmt.Errorf("%w: score must be 0-%g", domain.ErrInvalidAppeal, *job.MaxScore)
+		}
+		score = req.Score
+	}
+
+	overrideID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate UUIDv7: %w", err)
+	}
+	override := &domain.VerdictOverride{
+		OverrideID:     overrideID,
+		AppealID:       id,
+		JobID:          job.JobID,
+		PreviousStatus: job.Status,
+		Status:         req.Status,
+		PreviousScore:  job.Score,
+		Score:          score,
+		Reason:         req.Reason,
+		Actor:          actor,
+	}
+	if err := uc.appeals.OverrideVerdict(ctx, override); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Verdict overridden",
+		zap.String("appeal_id", id.String()),
+		zap.String("job_id", job.JobID.String()),
+		zap.String("previous_status", string(override.PreviousStatus)),
+		zap.String("status", string(override.Status)),
+		zap.String("actor", actor),
+	)
+	return uc.appeals.GetByID(ctx, id)
+}
+
+// Close ends the appeal with a response visible to the user.
+func (uc *AppealUsecase) Close(ctx context.Context, id uuid.UUID, actor string, req *domain.CloseAppealRequest) (*domain.Appeal, error) {
+	if _, err := uc.openAppeal(ctx, id, actor); err != nil {
+		return nil, err
+	}
+	if err := validateAppealText("response", req.Response); err != nil {
+		return nil, err
+	}
+	if err := uc.appeals.Close(ctx, id, actor, req.Response); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Appeal closed", zap.String("appeal_id", id.String()), zap.String("actor", actor))
+	return uc.appeals.GetByID(ctx, id)
+}
+
+// openAppeal loads an appeal an admin is about to act on.
+func (uc *AppealUsecase) openAppeal(ctx context.Context, id uuid.UUID, actor string) (*domain.Appeal, error) {
+	if actor == "" || len(actor) > maxActorLen {
+		return nil, fmt.Errorf("%w: actor must be 1-%d characters", domain.ErrInvalidAppeal, maxActorLen)
+	}
+	appeal, err := uc.appeals.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if appeal.Status != domain.AppealOpen {
+		return nil, domain.ErrAppealClosed
+	}
+	return appeal, nil
+}
+
+// appealableJob returns the submission if it was judged against a problem
+// and is not currently queued or running.
+func (uc *AppealUsecase) appealableJob(ctx context.Context, jobID uuid.UUID) (*domain.Job, error) {
+	job, err := uc.jobs.GetByID(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+	if job.ProblemID == "" || !job.Status.IsTerminal() {
+		return nil, domain.ErrNotAppealable
+	}
+	return job, nil
+}
+
+func validateAppealText(field, s string) error {
+	if strings.TrimSpace(s) == "" || len(s) > maxAppealTextLen {
+		return fmt.Errorf("%w: %s must be 1-%d characters", domain.ErrInvalidAppeal, field, maxAppealTextLen)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	defaultBulkCancelLimit = 1000
+	maxBulkCancelLimit     = 10000
+
+	// tombstoneTTL is how long a bulk-cancelled job's tombstone outlives
+	// it, long enough for its message to leave a backed-up or paused queue.
+	tombstoneTTL = 24 * time.Hour
+)
+
+// CancelJobUsecase cancels submissions. A queued job is cancelled in the
+// database, and the worker that later picks it up skips it; a running job
+// is killed by its worker, which records it as cancelled.
+type CancelJobUsecase struct {
+	jobs    repository.JobRepository
+	signals repository.CancelSignal
+	logger  *zap.Logger
+
+	locks repository.LockStore
+}
+
+// NewCancelJobUsecase creates a new CancelJobUsecase.
+func NewCancelJobUsecase(jobs repository.JobRepository, signals repository.CancelSignal, logger *zap.Logger) *CancelJobUsecase {
+	return &CancelJobUsecase{
+		jobs:    jobs,
+		signals: signals,
+		logger:  logger,
+	}
+}
+
+// SetTombstones lets bulk cancels mark the broker messages of the jobs
+// they cancel in the workers' lock store, so workers drop them unread.
+func (uc *CancelJobUsecase) SetTombstones(locks repository.LockStore) {
+	uc.locks = locks
+}
+
+// Execute cancels the job. It returns domain.ErrJobFinished if the job
+// already has a result.
+func (uc *CancelJobUsecase) Execute(ctx context.Context, id uuid.UUID) (*domain.CancelResponse, error) {
+	cancelled, err := uc.jobs.CancelQueued(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cancelled {
+		uc.logger.Info("Queued job cancelled", zap.String("job_id", id.String()))
+		return &domain.CancelResponse{JobID: id, Status: domain.StatusCancelled}, nil
+	}
+
+	// The job has left the queue, or never was in it.
+	job, err := uc.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status.IsTerminal() {
+		return nil, domain.ErrJobFinished
+	}
+	if err := uc.signals.RequestCancel(ctx, id); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Cancellation of running job requested",
+		zap.String("job_id", id.String()),
+		zap.String("status", string(job.Status)),
+	)
+	return &domain.CancelResponse{JobID: id, Status: job.Status}, nil
+}
+
+// CancelMatching cancels the queued jobs req selects, oldest first, up to
+// its limit. Workers would skip them anyway on finding them CANCELLED; the
+// tombstones spare them the database round trip for each message of a
+// flood. A tombstone that cannot be written is logged and the job left to
+// that check.
+func (uc *CancelJobUsecase) CancelMatching(ctx context.Context, req *domain.BulkCancelRequest) (*domain.BulkCancelReport, error) {
+	if req.TenantID == "" && req.ProblemID == "" && req.CreatedBefore == nil {
+		return nil, fmt.Errorf("%w: one of tenant_id, problem_id and created_before is required", domain.ErrInvalidBulkCancel)
+	}
+	filter := domain.BulkCancelFilter{
+		TenantID:      req.TenantID,
+		ProblemID:     req.ProblemID,
+		Status:        req.Status,
+		CreatedBefore: req.CreatedBefore,
+	}
+	if filter.Status == "" {
+		filter.Status = domain.StatusQueued
+	}
+	if filter.Status != domain.StatusQueued && filter.Status != domain.StatusScheduled {
+		return nil, fmt.Errorf("%w: status must be QUEUED or SCHEDULED", domain.ErrInvalidBulkCancel)
+	}
+	limit := defaultBulkCancelLimit
+	if req.Limit != 0 {
+		limit = req.Limit
+	}
+	if limit < 1 || limit > maxBulkCancelLimit {
+		return nil, fmt.Errorf("%w: limit must be 1-%d", domain.ErrInvalidBulkCancel, maxBulkCancelLimit)
+	}
+
+	jobs, err := uc.jobs.CancelMatching(ctx, filter, limit, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.BulkCancelReport{
+		DryRun:  req.DryRun,
+		Matched: len(jobs),
+		JobIDs:  make([]uuid.UUID, len(jobs)),
+		More:    len(jobs) == limit,
+	}
+	for i, job := range jobs {
+		report.JobIDs[i] = job.JobID
+	}
+	if req.DryRun {
+		return report, nil
+	}
+	report.Cancelled = len(jobs)
+
+	// Scheduled jobs have no message yet.
+	if uc.locks != nil && filter.Status == domain.StatusQueued {
+		for _, job := range jobs {
+			ok, err := uc.locks.Tombstone(ctx, job.LockID(), tombstoneTTL)
+			if err != nil {
+				uc.logger.Warn("Failed to tombstone cancelled job", zap.Error(err), zap.String("job_id", job.JobID.String()))
+				continue
+			}
+			if ok {
+				report.Tombstoned++
+			}
+		}
+	}
+	uc.logger.Info("Jobs cancelled in bulk",
+		zap.String("tenant_id", filter.TenantID),
+		zap.String("problem_id", filter.ProblemID),
+		zap.String("status", string(filter.Status)),
+		zap.Int("cancelled", report.Cancelled),
+		zap.Int("tombstoned", report.Tombstoned),
+	)
+	return report, nil
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// consistencyLimit caps the lost jobs one check reports and repairs.
+const consistencyLimit = 100
+
+// ConsistencyReport is the outcome of one consistency check.
+type ConsistencyReport struct {
+	// Backlogged is set when messages were waiting in the queue, which makes
+	// lost jobs indistinguishable from queued ones; nothing else was checked.
+	Backlogged bool
+	// Lost lists the QUEUED jobs found without a broker message.
+	Lost []uuid.UUID
+	// Republished counts the lost jobs published again.
+	Republished int
+}
+
+// ConsistencyChecker periodically compares QUEUED jobs in Postgres with the
+// execution queue. Quorum queues cannot be browsed without redelivering, so
+// it only judges when the queue holds no ready message: a QUEUED job older
+// than the grace period whose processing lock is not held then has no
+// message, because the publish was lost or the message was dead-lettered.
+// Messages referencing missing jobs are caught by the worker on delivery.
+type ConsistencyChecker struct {
+	repair     *RepairUsecase
+	queue      publisher.QueueInspector
+	grace      time.Duration
+	autoRepair bool
+	logger     *zap.Logger
+}
+
+// NewConsistencyChecker creates a checker that reports QUEUED jobs untouched
+// for longer than grace.
+func NewConsistencyChecker(
+	jobs repository.JobRepository,
+	locks repository.LockStore,
+	pub publisher.Publisher,
+	queue publisher.QueueInspector,
+	grace time.Duration,
+	logger *zap.Logger,
+) *ConsistencyChecker {
+	return &ConsistencyChecker{
+		repair: NewRepairUsecase(jobs, locks, pub, logger),
+		queue:  queue,
+		grace:  grace,
+		logger: logger,
+	}
+}
+
+// SetAutoRepair makes the checker publish lost jobs again instead of only
+// reporting them.
+func (c *ConsistencyChecker) SetAutoRepair(enabled bool) {
+	c.autoRepair = enabled
+}
+
+// Run checks every interval until ctx is done.
+func (c *ConsistencyChecker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Check(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Warn("Consistency check failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Check runs one comparison and, with auto-repair, publishes lost jobs again
+// the way POST /admin/repair's requeue_stuck does.
+func (c *ConsistencyChecker) Check(ctx context.Context) (*ConsistencyReport, error) {
+	ready, err := c.queue.ReadyMessages(ctx)
+	if err != nil {
+		metrics.ConsistencyChecks.WithLabelValues("failed").Inc()
+		return nil, fmt.Errorf("inspect queue: %w", err)
+	}
+	if ready > 0 {
+		metrics.ConsistencyChecks.WithLabelValues("backlogged").Inc()
+		return &ConsistencyReport{Backlogged: true}, nil
+	}
+
+	cutoff := time.Now().Add(-c.grace)
+	jobs, err := c.repair.jobs.ListStale(ctx, []domain.ExecutionStatus{domain.StatusQueued}, cutoff, consistencyLimit)
+	if err != nil {
+		metrics.ConsistencyChecks.WithLabelValues("failed").Inc()
+		return nil, fmt.Errorf("list queued jobs: %w", err)
+	}
+	report := &ConsistencyReport{}
+	for _, job := range jobs {
+		// A held lock means a worker took the message and has not yet
+		// updated the status, or died doing so; purge_locks handles the latter.
+		_, held, err := c.repair.locks.AcquiredAt(ctx, job.LockID())
+		if err != nil {
+			metrics.ConsistencyChecks.WithLabelValues("failed").Inc()
+			return nil, fmt.Errorf("look up lock: %w", err)
+		}
+		if held {
+			continue
+		}
+		report.Lost = append(report.Lost, job.JobID)
+		if !c.autoRepair {
+			continue
+		}
+		outcome, err := c.repair.requeueJob(ctx, job, cutoff)
+		if err != nil {
+			metrics.ConsistencyChecks.WithLabelValues("failed").Inc()
+			return nil, fmt.Errorf("republish job %s: %w", job.JobID, err)
+		}
+		if outcome == domain.RepairOutcomeRequeued {
+			report.Republished++
+			metrics.RepublishedJobs.Inc()
+		}
+	}
+
+	metrics.LostJobs.Set(float64(len(report.Lost)))
+	if len(report.Lost) == 0 {
+		metrics.ConsistencyChecks.WithLabelValues("consistent").Inc()
+		return report, nil
+	}
+	metrics.ConsistencyChecks.WithLabelValues("inconsistent").Inc()
+	c.logger.Warn("QUEUED jobs without a broker message",
+		zap.Int("lost", len(report.Lost)),
+		zap.Int("republished", report.Republished),
+		zap.Bool("auto_repair", c.autoRepair),
+	)
+	return report, nil
+}
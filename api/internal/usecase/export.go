@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+	"github.com/Harsh-BH/Sentinel/api/internal/warehouse"
+)
+
+// exportSettleDelay holds back verdicts this recent. Event IDs are assigned
+// before their transaction commits, so a verdict may become visible after
+// one with a higher ID; waiting lets such stragglers land before the cursor
+// passes them.
+const exportSettleDelay = 30 * time.Second
+
+// ExportUsecase copies verdicts to a data warehouse in batches, resuming
+// from a cursor kept per sink. Delivery is at least once: a batch whose
+// write fails is written again on the next pass.
+type ExportUsecase struct {
+	repo      repository.ExportRepository
+	sink      warehouse.Sink
+	salt      string
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewExportUsecase creates a new ExportUsecase writing batches of up to
+// batchSize facts to sink. salt keys the source hashes, so that they cannot
+// be matched against hashes of guessed sources.
+func NewExportUsecase(repo repository.ExportRepository, sink warehouse.Sink, salt string, batchSize int, logger *zap.Logger) *ExportUsecase {
+	return &ExportUsecase{
+		repo:      repo,
+		sink:      sink,
+		salt:      salt,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Run exports every interval until ctx is done.
+func (uc *ExportUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := uc.Export(ctx); err != nil && ctx.Err() == nil {
+				uc.logger.Warn("Warehouse export failed", zap.String("sink", uc.sink.Name()), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Export writes every settled verdict not yet exported and returns how many
+// facts were written.
+func (uc *ExportUsecase) Export(ctx context.Context) (int, error) {
+	name := uc.sink.Name()
+	cursor, err := uc.repo.GetCursor(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	settledBefore := time.Now().Add(-exportSettleDelay)
+	exported := 0
+	for {
+		facts, err := uc.repo.ListFacts(ctx, cursor, settledBefore, uc.salt, uc.batchSize)
+		if err != nil {
+			return exported, err
+		}
+		if len(facts) == 0 {
+			return exported, nil
+		}
+		if err := uc.sink.Write(ctx, facts); err != nil {
+			metrics.ExportFailures.WithLabelValues(name).Inc()
+			return exported, err
+		}
+		cursor = facts[len(facts)-1].EventID
+		if err := uc.repo.SetCursor(ctx, name, cursor); err != nil {
+			return exported, err
+		}
+		exported += len(facts)
+		metrics.ExportedFacts.WithLabelValues(name).Add(float64(len(facts)))
+		if len(facts) < uc.batchSize {
+			return exported, nil
+		}
+	}
+}
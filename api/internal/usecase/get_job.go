@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -10,9 +12,18 @@ import (
 	"github.com/Harsh-BH/Sentinel/api/internal/repository"
 )
 
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+
+	// maxLineageDescendants bounds the descendants a lineage lists.
+	maxLineageDescendants = 1000
+)
+
 // GetJobUsecase handles fetching job status and results.
 type GetJobUsecase struct {
 	repo   repository.JobRepository
+	feed   repository.StatusFeed
 	logger *zap.Logger
 }
 
@@ -24,6 +35,21 @@ func NewGetJobUsecase(repo repository.JobRepository, logger *zap.Logger) *GetJob
 	}
 }
 
+// SetStatusFeed lets Watch report status changes as they happen.
+func (uc *GetJobUsecase) SetStatusFeed(feed repository.StatusFeed) {
+	uc.feed = feed
+}
+
+// Watch returns a channel that receives a value after the job's status
+// changes, and a function that stops watching. Without a status feed the
+// channel is nil, and callers have to poll.
+func (uc *GetJobUsecase) Watch(id uuid.UUID) (<-chan struct{}, func()) {
+	if uc.feed == nil {
+		return nil, func() {}
+	}
+	return uc.feed.Subscribe(id)
+}
+
 // Execute retrieves a job by its ID.
 func (uc *GetJobUsecase) Execute(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
 	job, err := uc.repo.GetByID(ctx, id)
@@ -33,3 +59,127 @@ func (uc *GetJobUsecase) Execute(ctx context.Context, id uuid.UUID) (*domain.Job
 	}
 	return job, nil
 }
+
+// ByExternalID retrieves the tenant's job with the external ID the client
+// submitted it with.
+func (uc *GetJobUsecase) ByExternalID(ctx context.Context, tenantID, externalID string) (*domain.Job, error) {
+	job, err := uc.repo.GetByExternalID(ctx, tenantOrDefault(tenantID), externalID)
+	if errors.Is(err, domain.ErrJobNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job by external id: %w", err)
+	}
+	return job, nil
+}
+
+// ExecuteFields retrieves a job reading only fields, as parsed by
+// domain.ParseJobFields.
+func (uc *GetJobUsecase) ExecuteFields(ctx context.Context, id uuid.UUID, fields []string) (*domain.Job, error) {
+	job, err := uc.repo.GetFields(ctx, id, fields)
+	if err != nil {
+		uc.logger.Debug("Job not found", zap.String("job_id", id.String()), zap.Error(err))
+		return nil, domain.ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Artifact returns the output file name collected from the job's run.
+func (uc *GetJobUsecase) Artifact(ctx context.Context, id uuid.UUID, name string) (*domain.Artifact, error) {
+	artifact, err := uc.repo.GetArtifact(ctx, id, name)
+	if errors.Is(err, domain.ErrArtifactNotFound) {
+		// Tell a missing job from a job without the artifact.
+		if _, err := uc.repo.GetFields(ctx, id, []string{"job_id"}); err != nil {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get artifact: %w", err)
+	}
+	return artifact, nil
+}
+
+// ListRecent returns a page of every tenant's jobs, newest first, only
+// those in status unless it is empty. An empty cursor starts at the newest
+// job, and a zero limit selects the default.
+func (uc *GetJobUsecase) ListRecent(ctx context.Context, status domain.ExecutionStatus, cursor string, limit int) (*domain.JobPage, error) {
+	return uc.list(ctx, domain.JobFilter{Status: status}, cursor, limit)
+}
+
+// ListSubmissions pages through the tenant's jobs like ListRecent, only
+// those submitted with the API key unless apiKeyID is nil, and only those
+// the user submitted unless userID is nil.
+func (uc *GetJobUsecase) ListSubmissions(ctx context.Context, tenantID string, apiKeyID, userID *uuid.UUID, status domain.ExecutionStatus, cursor string, limit int) (*domain.JobPage, error) {
+	filter := domain.JobFilter{Status: status, TenantID: tenantOrDefault(tenantID), APIKeyID: apiKeyID, UserID: userID}
+	return uc.list(ctx, filter, cursor, limit)
+}
+
+func (uc *GetJobUsecase) list(ctx context.Context, filter domain.JobFilter, cursor string, limit int) (*domain.JobPage, error) {
+	switch status := filter.Status; {
+	case status == "", status.IsTerminal():
+	case status == domain.StatusScheduled, status == domain.StatusQueued, status == domain.StatusCompiling, status == domain.StatusRunning:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidJobFilter, status)
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 1 || limit > maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be 1-%d", domain.ErrInvalidJobFilter, maxListLimit)
+	}
+	var after uuid.UUID
+	if cursor != "" {
+		var err error
+		if after, err = domain.ParseJobCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// One job more than the page tells whether there is a next page.
+	jobs, err := uc.repo.ListRecent(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list recent jobs: %w", err)
+	}
+	page := &domain.JobPage{Jobs: jobs}
+	if len(jobs) > limit {
+		page.Jobs = jobs[:limit]
+		page.NextCursor = domain.EncodeJobCursor(page.Jobs[limit-1].JobID)
+	}
+	if page.Jobs == nil {
+		page.Jobs = []*domain.JobSummary{}
+	}
+	return page, nil
+}
+
+// Timeline returns the statuses a job has been through, oldest first.
+func (uc *GetJobUsecase) Timeline(ctx context.Context, id uuid.UUID) ([]domain.JobEvent, error) {
+	events, err := uc.repo.ListEvents(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("list job events: %w", err)
+	}
+	return events, nil
+}
+
+// Lineage returns the jobs a job was resubmitted from and the jobs
+// resubmitted from it.
+func (uc *GetJobUsecase) Lineage(ctx context.Context, id uuid.UUID) (*domain.Lineage, error) {
+	chain, descendants, err := uc.repo.GetLineage(ctx, id, maxLineageDescendants)
+	if errors.Is(err, domain.ErrJobNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get job lineage: %w", err)
+	}
+	if descendants == nil {
+		descendants = []*domain.LineageEntry{}
+	}
+	lineage := &domain.Lineage{JobID: id, Chain: chain, Descendants: descendants}
+	for _, entry := range chain {
+		lineage.TotalRuns += entry.Runs
+	}
+	for _, entry := range descendants {
+		lineage.TotalRuns += entry.Runs
+	}
+	return lineage, nil
+}
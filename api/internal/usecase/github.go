@@ -0,0 +1,375 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/github"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	// gitHubStatusContext labels Sentinel's commit statuses.
+	gitHubStatusContext = "sentinel"
+
+	// gitHubReportLimit caps the statuses one report pass posts.
+	gitHubReportLimit = 100
+
+	// maxGitHubPathLen and maxGitHubTokenLen bound a mapping's fields.
+	maxGitHubPathLen  = 1024
+	maxGitHubTokenLen = 1024
+
+	// maxGitHubDescriptionLen is GitHub's limit on a status description.
+	maxGitHubDescriptionLen = 140
+)
+
+// gitHubRepoName matches a repository's full name, "owner/name".
+var gitHubRepoName = regexp.MustCompile(`^[A-Za-z0-9-]{1,39}/[A-Za-z0-9._-]{1,100}$`)
+
+// GitHubUsecase submits the source committed to mapped repositories and
+// reports each verdict back as a commit status. Deliveries arrive on the
+// repository's webhook; verdicts are posted by Run once the job finishes.
+type GitHubUsecase struct {
+	repos     repository.GitHubRepository
+	submit    *SubmitJobUsecase
+	client    github.Client
+	publicURL string
+	logger    *zap.Logger
+}
+
+// NewGitHubUsecase creates a new GitHubUsecase. publicURL, if set, is the
+// API's external base URL, which commit statuses link to.
+func NewGitHubUsecase(repos repository.GitHubRepository, submit *SubmitJobUsecase, client github.Client, publicURL string, logger *zap.Logger) *GitHubUsecase {
+	return &GitHubUsecase{
+		repos:     repos,
+		submit:    submit,
+		client:    client,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		logger:    logger,
+	}
+}
+
+// SetRepo validates and stores a tenant's mapping of repo, replacing any
+// previous one. The returned mapping carries the webhook secret, which is
+// generated when the request has none.
+func (uc *GitHubUsecase) SetRepo(ctx context.Context, tenantID, repo string, req *domain.SetGitHubRepoRequest) (*domain.GitHubRepo, error) {
+	if !gitHubRepoName.MatchString(repo) {
+		return nil, fmt.Errorf("%w: repository must be owner/name", domain.ErrInvalidGitHubRepo)
+	}
+	if err := uc.submit.checkLanguage(ctx, req.Language); err != nil {
+		return nil, err
+	}
+	if req.ProblemID != "" {
+		if _, err := uc.submit.checkProblem(ctx, req.ProblemID); err != nil {
+			return nil, err
+		}
+	}
+	cleaned := path.Clean(req.Path)
+	if len(req.Path) > maxGitHubPathLen || path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return nil, fmt.Errorf("%w: path must be relative to the repository root and at most %d bytes", domain.ErrInvalidGitHubRepo, maxGitHubPathLen)
+	}
+	if len(req.Token) > maxGitHubTokenLen {
+		return nil, fmt.Errorf("%w: token longer than %d bytes", domain.ErrInvalidGitHubRepo, maxGitHubTokenLen)
+	}
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(buf)
+	} else if len(secret) < minWebhookSecretLen || len(secret) > maxWebhookSecretLen {
+		return nil, fmt.Errorf("%w: secret must be %d-%d bytes", domain.ErrInvalidGitHubRepo, minWebhookSecretLen, maxWebhookSecretLen)
+	}
+
+	mapping := &domain.GitHubRepo{
+		Repo:      repo,
+		TenantID:  tenantOrDefault(tenantID),
+		Language:  req.Language,
+		ProblemID: req.ProblemID,
+		Path:      cleaned,
+		Secret:    secret,
+		Token:     req.Token,
+	}
+	if err := uc.repos.PutRepo(ctx, mapping); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("GitHub repository mapped",
+		zap.String("tenant_id", mapping.TenantID),
+		zap.String("repo", repo),
+	)
+	return mapping, nil
+}
+
+// GetRepo returns a tenant's mapping of repo without its secret.
+func (uc *GitHubUsecase) GetRepo(ctx context.Context, tenantID, repo string) (*domain.GitHubRepo, error) {
+	mapping, err := uc.repos.GetRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.TenantID != tenantOrDefault(tenantID) {
+		return nil, domain.ErrGitHubRepoNotFound
+	}
+	mapping.Secret = ""
+	return mapping, nil
+}
+
+// DeleteRepo stops submissions from repo. Commits already submitted still
+// get their status.
+func (uc *GitHubUsecase) DeleteRepo(ctx context.Context, tenantID, repo string) error {
+	return uc.repos.DeleteRepo(ctx, tenantOrDefault(tenantID), repo)
+}
+
+// gitHubEvent holds the fields of push, pull_request and ping payloads the
+// intake reads.
+type gitHubEvent struct {
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+
+	// push
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+
+	// pull_request
+	Action      string `json:"action"`
+	PullRequest struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+}
+
+// HandleEvent handles one webhook delivery: event is the X-GitHub-Event
+// header, signature the X-Hub-Signature-256 header and body the raw payload
+// it signs. Pushes and opened or updated pull requests submit the mapped
+// path at the commit they point to; anything else is acknowledged and
+// ignored.
+func (uc *GitHubUsecase) HandleEvent(ctx context.Context, event, signature string, body []byte) (*domain.GitHubDelivery, error) {
+	var payload gitHubEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: payload is not valid JSON", domain.ErrInvalidGitHubRepo)
+	}
+	mapping, err := uc.repos.GetRepo(ctx, payload.Repository.FullName)
+	if err != nil {
+		return nil, err
+	}
+	if !validGitHubSignature(mapping.Secret, signature, body) {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	var sha string
+	switch event {
+	case "ping":
+		return &domain.GitHubDelivery{Ignored: "ping"}, nil
+	case "push":
+		if payload.Deleted || strings.Trim(payload.After, "0") == "" {
+			return &domain.GitHubDelivery{Ignored: "branch deleted"}, nil
+		}
+		sha = payload.After
+	case "pull_request":
+		switch payload.Action {
+		case "opened", "synchronize", "reopened":
+		default:
+			return &domain.GitHubDelivery{Ignored: "pull request " + payload.Action}, nil
+		}
+		sha = payload.PullRequest.Head.SHA
+	default:
+		return &domain.GitHubDelivery{Ignored: "event " + event + " not handled"}, nil
+	}
+
+	source, err := uc.client.Contents(ctx, mapping.Token, mapping.Repo, mapping.Path, sha)
+	if err != nil {
+		if !errors.Is(err, github.ErrNotFound) {
+			return nil, fmt.Errorf("fetch source: %w", err)
+		}
+		uc.postStatus(ctx, mapping, sha, github.Status{
+			State:       github.StateError,
+			Description: mapping.Path + " not found",
+		})
+		return &domain.GitHubDelivery{SHA: sha, Ignored: mapping.Path + " not found"}, nil
+	}
+
+	resp, err := uc.submit.Execute(ctx, &domain.SubmitRequest{
+		Language:   mapping.Language,
+		SourceCode: source,
+		ProblemID:  mapping.ProblemID,
+		TenantID:   mapping.TenantID,
+	})
+	if err != nil {
+		if !submissionRejected(err) {
+			return nil, fmt.Errorf("submit: %w", err)
+		}
+		uc.postStatus(ctx, mapping, sha, github.Status{
+			State:       github.StateError,
+			Description: "Submission rejected: " + err.Error(),
+		})
+		return &domain.GitHubDelivery{SHA: sha, Ignored: "submission rejected: " + err.Error()}, nil
+	}
+
+	// The pending status goes out before the check is recorded so that it
+	// cannot overwrite the verdict.
+	uc.postStatus(ctx, mapping, sha, github.Status{
+		State:       github.StatePending,
+		TargetURL:   uc.targetURL(resp.JobID.String()),
+		Description: "Judging",
+	})
+	check := &domain.GitHubCheck{JobID: resp.JobID, Repo: mapping.Repo, SHA: sha}
+	if err := uc.repos.AddCheck(ctx, check); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("GitHub commit submitted",
+		zap.String("repo", mapping.Repo),
+		zap.String("sha", sha),
+		zap.String("job_id", resp.JobID.String()),
+	)
+	return &domain.GitHubDelivery{JobID: &resp.JobID, SHA: sha}, nil
+}
+
+// Run posts verdicts every interval until ctx is done.
+func (uc *GitHubUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := uc.Report(ctx); err != nil && ctx.Err() == nil {
+				uc.logger.Warn("GitHub status report failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Report posts the status of every finished job submitted from a commit and
+// returns how many were posted. A status GitHub refuses is retried on the
+// next pass, unless the repository or its mapping is gone.
+func (uc *GitHubUsecase) Report(ctx context.Context) (int, error) {
+	checks, err := uc.repos.ListFinishedChecks(ctx, gitHubReportLimit)
+	if err != nil {
+		return 0, err
+	}
+	posted := 0
+	for _, check := range checks {
+		mapping, err := uc.repos.GetRepo(ctx, check.Repo)
+		switch {
+		case errors.Is(err, domain.ErrGitHubRepoNotFound):
+			// Unmapped since submitting; there is no token to post with.
+		case err != nil:
+			return posted, err
+		default:
+			err = uc.client.SetStatus(ctx, mapping.Token, mapping.Repo, check.SHA, verdictStatus(check, uc.targetURL(check.JobID.String())))
+			if err != nil && !errors.Is(err, github.ErrNotFound) {
+				uc.logger.Warn("Posting commit status failed",
+					zap.String("repo", check.Repo),
+					zap.String("sha", check.SHA),
+					zap.Error(err),
+				)
+				continue
+			}
+			if err == nil {
+				posted++
+			}
+		}
+		if err := uc.repos.RemoveCheck(ctx, check.JobID); err != nil {
+			return posted, err
+		}
+	}
+	return posted, nil
+}
+
+// postStatus sets a status on a commit, logging failures: a missing status
+// does not fail the delivery.
+func (uc *GitHubUsecase) postStatus(ctx context.Context, mapping *domain.GitHubRepo, sha string, status github.Status) {
+	status.Context = gitHubStatusContext
+	status.Description = truncateDescription(status.Description)
+	if err := uc.client.SetStatus(ctx, mapping.Token, mapping.Repo, sha, status); err != nil {
+		uc.logger.Warn("Posting commit status failed",
+			zap.String("repo", mapping.Repo),
+			zap.String("sha", sha),
+			zap.Error(err),
+		)
+	}
+}
+
+func (uc *GitHubUsecase) targetURL(jobID string) string {
+	if uc.publicURL == "" {
+		return ""
+	}
+	return uc.publicURL + "/api/v2/submissions/" + jobID
+}
+
+// verdictStatus maps a finished job to its commit status: accepted or
+// cleanly run jobs succeed, internal errors and cancelled jobs are errors
+// and every other verdict fails.
+func verdictStatus(check *domain.GitHubCheck, targetURL string) github.Status {
+	state := github.StateFailure
+	switch check.Status {
+	case domain.StatusAccepted, domain.StatusSuccess:
+		state = github.StateSuccess
+	case domain.StatusInternalError, domain.StatusCancelled:
+		state = github.StateError
+	}
+	description := string(check.Status)
+	if check.Score != nil {
+		description += fmt.Sprintf(", score %g", *check.Score)
+	}
+	return github.Status{
+		State:       state,
+		TargetURL:   targetURL,
+		Description: truncateDescription(description),
+		Context:     gitHubStatusContext,
+	}
+}
+
+// submissionRejected reports whether err is the submission being refused,
+// rather than failing, for reasons a commit can run into.
+func submissionRejected(err error) bool {
+	for _, target := range []error{
+		domain.ErrInvalidLanguage, domain.ErrEmptySourceCode, domain.ErrInvalidSource,
+		domain.ErrPayloadTooLarge, domain.ErrProblemNotFound, domain.ErrQuotaExceeded,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// validGitHubSignature checks an X-Hub-Signature-256 header, "sha256="
+// followed by the hex HMAC-SHA256 of the body.
+func validGitHubSignature(secret, signature string, body []byte) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func truncateDescription(s string) string {
+	if len(s) <= maxGitHubDescriptionLen {
+		return s
+	}
+	return s[:maxGitHubDescriptionLen-3] + "..."
+}
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+const (
+	// hardeningTimeout bounds how long a hardening run waits for its probes,
+	// under the API's default write timeout of 30s.
+	hardeningTimeout = 20 * time.Second
+
+	// hardeningPollInterval is how often a run checks on its probes.
+	hardeningPollInterval = 250 * time.Millisecond
+
+	// Probes print these markers: probeAttempt right before trying what the
+	// protection should stop, then probeExposed or probeProtected.
+	probeAttempt   = "ATTEMPT"
+	probeExposed   = "EXPOSED"
+	probeProtected = "PROTECTED"
+)
+
+// hardeningProbe is a program that tries to get past one protection of the
+// sandbox. The sandbox killing it after its attempt with one of killedBy
+// also counts as the protection holding.
+type hardeningProbe struct {
+	name       string
+	protection string
+	source     string
+	memoryKB   int
+	killedBy   []domain.ExecutionStatus
+}
+
+// hardeningProbes are written in Python, which every deployment runs.
+var hardeningProbes = []hardeningProbe{
+	{
+		name:       "seccomp",
+		protection: "seccomp-bpf syscall filter blocks ptrace",
+		source: `import ctypes
+libc = ctypes.CDLL(None, use_errno=True)
+print("ATTEMPT", flush=True)
+if libc.ptrace(0, 0, None, None) == 0:
+    print("EXPOSED ptrace(PTRACE_TRACEME) succeeded")
+else:
+    print("PROTECTED ptrace failed with errno %d" % ctypes.get_errno())
+`,
+		killedBy: []domain.ExecutionStatus{domain.StatusRuntimeError},
+	},
+	{
+		name:       "network",
+		protection: "network namespace without outside connectivity",
+		source: `import socket
+print("ATTEMPT", flush=True)
+try:
+    s = socket.socket(socket.AF_INET, socket.SOCK_STREAM)
+    s.settimeout(3)
+    s.connect(("1.1.1.1", 53))
+    print("EXPOSED connected to 1.1.1.1:53")
+except socket.timeout:
+    print("connection to 1.1.1.1:53 timed out")
+except OSError as e:
+    print("PROTECTED %s" % e)
+`,
+		killedBy: []domain.ExecutionStatus{domain.StatusRuntimeError},
+	},
+	{
+		name:       "memory",
+		protection: "cgroup memory limit",
+		source: `print("ATTEMPT", flush=True)
+b = bytearray(256 << 20)
+for i in range(0, len(b), 4096):
+    b[i] = 1
+print("EXPOSED allocated 256 MB under a 64 MB limit")
+`,
+		memoryKB: 65536,
+		killedBy: []domain.ExecutionStatus{domain.StatusMemoryLimitExceeded, domain.StatusRuntimeError},
+	},
+	{
+		name:       "pids",
+		protection: "process limit",
+		source: `import os, time
+print("ATTEMPT", flush=True)
+n = 0
+try:
+    for _ in range(512):
+        if os.fork() == 0:
+            time.sleep(2)
+            os._exit(0)
+        n += 1
+except OSError as e:
+    print("PROTECTED fork failed after %d processes: %s" % (n, e))
+else:
+    print("EXPOSED started %d processes" % n)
+`,
+		killedBy: []domain.ExecutionStatus{domain.StatusRuntimeError},
+	},
+	{
+		name:       "filesystem",
+		protection: "read-only system directories",
+		source: `print("ATTEMPT", flush=True)
+try:
+    open("/usr/sentinel-probe", "w").close()
+    print("EXPOSED wrote /usr/sentinel-probe")
+except OSError as e:
+    print("PROTECTED %s" % e)
+`,
+	},
+	{
+		name:       "user",
+		protection: "unprivileged user",
+		source: `import os
+print("ATTEMPT", flush=True)
+if os.geteuid() == 0:
+    print("EXPOSED running as root")
+else:
+    print("PROTECTED running as uid %d" % os.geteuid())
+`,
+	},
+	{
+		name:       "processes",
+		protection: "PID namespace hides the host's processes",
+		source: `import os
+print("ATTEMPT", flush=True)
+n = sum(1 for p in os.listdir("/proc") if p.isdigit())
+if n > 8:
+    print("EXPOSED sees %d processes" % n)
+else:
+    print("PROTECTED sees %d processes" % n)
+`,
+	},
+}
+
+// HardeningUsecase checks which protections the sandbox actually applies
+// by running probes through the live workers as ordinary jobs.
+type HardeningUsecase struct {
+	submit *SubmitJobUsecase
+	jobs   *GetJobUsecase
+	logger *zap.Logger
+
+	timeout time.Duration
+}
+
+// NewHardeningUsecase creates a new HardeningUsecase.
+func NewHardeningUsecase(submit *SubmitJobUsecase, jobs *GetJobUsecase, logger *zap.Logger) *HardeningUsecase {
+	return &HardeningUsecase{
+		submit:  submit,
+		jobs:    jobs,
+		logger:  logger,
+		timeout: hardeningTimeout,
+	}
+}
+
+// Run submits every probe and waits for them, up to hardeningTimeout.
+// Probes still unfinished then are reported inconclusive. Errors of
+// submitting a probe, such as an unknown sandbox tier, are returned as is.
+func (uc *HardeningUsecase) Run(ctx context.Context, req *domain.HardeningRequest) (*domain.HardeningReport, error) {
+	tenantID := tenantOrDefault(req.TenantID)
+	priority := domain.MaxPriority
+	ids := make([]uuid.UUID, len(hardeningProbes))
+	for i, p := range hardeningProbes {
+		sub := &domain.SubmitRequest{
+			Language:    domain.LangPython,
+			SourceCode:  p.source,
+			SandboxTier: req.SandboxTier,
+			Priority:    &priority,
+			TenantID:    tenantID,
+		}
+		if p.memoryKB > 0 {
+			sub.MemoryLimitKB = &p.memoryKB
+		}
+		resp, err := uc.submit.Execute(ctx, sub)
+		if err != nil {
+			return nil, fmt.Errorf("submit %s probe: %w", p.name, err)
+		}
+		ids[i] = resp.JobID
+	}
+
+	jobs, err := uc.wait(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.HardeningReport{
+		GeneratedAt: time.Now().UTC(),
+		TenantID:    tenantID,
+		SandboxTier: req.SandboxTier,
+		Language:    domain.LangPython,
+		Hardened:    true,
+	}
+	for i, p := range hardeningProbes {
+		check := p.check(jobs[i])
+		check.JobID = ids[i]
+		report.Hardened = report.Hardened && check.Result == domain.HardeningActive
+		report.Checks = append(report.Checks, check)
+	}
+	uc.logger.Info("Sandbox hardening checked",
+		zap.String("tenant_id", tenantID),
+		zap.String("sandbox_tier", req.SandboxTier),
+		zap.Bool("hardened", report.Hardened),
+	)
+	return report, nil
+}
+
+// QuotaResetAt returns when the current quota window of the probes' tenant
+// ends, or the zero time if quotas are disabled.
+func (uc *HardeningUsecase) QuotaResetAt(now time.Time) time.Time {
+	return uc.submit.QuotaResetAt(now)
+}
+
+// wait polls the probes' jobs until all have finished or the run's time is
+// up, and returns their last known state.
+func (uc *HardeningUsecase) wait(ctx context.Context, ids []uuid.UUID) ([]*domain.Job, error) {
+	deadline := time.NewTimer(uc.timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(hardeningPollInterval)
+	defer ticker.Stop()
+
+	jobs := make([]*domain.Job, len(ids))
+	for {
+		done := true
+		for i, id := range ids {
+			if jobs[i] != nil && jobs[i].Status.IsTerminal() {
+				continue
+			}
+			job, err := uc.jobs.Execute(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("get probe job %s: %w", id, err)
+			}
+			jobs[i] = job
+			done = done && job.Status.IsTerminal()
+		}
+		if done {
+			return jobs, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return jobs, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check judges the protection by the probe's job.
+func (p hardeningProbe) check(job *domain.Job) domain.HardeningCheck {
+	check := domain.HardeningCheck{
+		Name:       p.name,
+		Protection: p.protection,
+		Result:     domain.HardeningInconclusive,
+		Status:     job.Status,
+	}
+	if !job.Status.IsTerminal() {
+		check.Detail = "probe did not finish in time"
+		return check
+	}
+	for _, line := range strings.Split(job.Stdout, "\n") {
+		verdict, detail, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch verdict {
+		case probeExposed:
+			check.Result, check.Detail = domain.HardeningMissing, detail
+			return check
+		case probeProtected:
+			check.Result, check.Detail = domain.HardeningActive, detail
+			return check
+		}
+	}
+	attempted := strings.Contains(job.Stdout, probeAttempt)
+	switch {
+	case attempted && slices.Contains(p.killedBy, job.Status):
+		check.Result = domain.HardeningActive
+		check.Detail = "the sandbox stopped the probe"
+	case attempted:
+		check.Detail = "probe gave no verdict"
+		if _, rest, _ := strings.Cut(job.Stdout, probeAttempt); strings.TrimSpace(rest) != "" {
+			check.Detail += ": " + strings.TrimSpace(rest)
+		}
+	default:
+		check.Detail = "probe ended before its attempt"
+	}
+	return check
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// maxStdinMessage caps one piece of input sent to an interactive job.
+const maxStdinMessage = 4096
+
+// InteractiveUsecase relays input to interactive jobs and their output back
+// while they run.
+type InteractiveUsecase struct {
+	store  repository.InteractiveStore
+	logger *zap.Logger
+}
+
+// NewInteractiveUsecase creates a new InteractiveUsecase.
+func NewInteractiveUsecase(store repository.InteractiveStore, logger *zap.Logger) *InteractiveUsecase {
+	return &InteractiveUsecase{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// SendStdin queues data for job's stdin, or ends its stdin when eof is set.
+// Input sent before the job starts is read once it does.
+func (uc *InteractiveUsecase) SendStdin(ctx context.Context, job *domain.Job, data string, eof bool) error {
+	if !job.Interactive || job.Status.IsTerminal() {
+		return domain.ErrNotInteractive
+	}
+	if len(data) > maxStdinMessage {
+		return fmt.Errorf("%w: at most %d bytes per message", domain.ErrInvalidStdin, maxStdinMessage)
+	}
+	if data != "" {
+		if err := uc.store.SendStdin(ctx, job.JobID, data); err != nil {
+			return fmt.Errorf("send stdin: %w", err)
+		}
+	}
+	if eof {
+		if err := uc.store.CloseStdin(ctx, job.JobID); err != nil {
+			return fmt.Errorf("close stdin: %w", err)
+		}
+	}
+	return nil
+}
+
+// Output returns the stdout an interactive job wrote since cursor, empty
+// for the start, and the cursor to read on from.
+func (uc *InteractiveUsecase) Output(ctx context.Context, id uuid.UUID, cursor string) (string, string, error) {
+	out, next, err := uc.store.ReadOutput(ctx, id, cursor)
+	if err != nil {
+		return "", cursor, fmt.Errorf("read output: %w", err)
+	}
+	return out, next, nil
+}
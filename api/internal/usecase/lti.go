@@ -0,0 +1,446 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	// ltiLoginTTL is how long a platform has to post the launch after the
+	// OIDC login redirect.
+	ltiLoginTTL = 10 * time.Minute
+
+	// ltiReportLimit caps the scores one report pass posts.
+	ltiReportLimit = 100
+
+	// maxLTIFieldLen bounds each field of a platform registration.
+	maxLTIFieldLen = 1024
+
+	// ltiLaunchPath is the launch endpoint, registered with platforms as the
+	// tool's redirect URI.
+	ltiLaunchPath = "/api/v2/integrations/lti/launch"
+)
+
+// LTILoginRequest is a platform's OIDC third-party login initiation.
+type LTILoginRequest struct {
+	Issuer         string
+	ClientID       string
+	LoginHint      string
+	LTIMessageHint string
+}
+
+// LTIUsecase is the LTI 1.3 tool: platforms launch it from an assignment,
+// the launched user submits against the assignment's problem, and each
+// verdict is posted to the platform's gradebook through the Assignment and
+// Grade Services once the job finishes.
+type LTIUsecase struct {
+	repo      repository.LTIRepository
+	logins    repository.LTILoginStore
+	submit    *SubmitJobUsecase
+	client    lti.Client
+	key       *rsa.PrivateKey
+	publicURL string
+	appURL    string
+	launchTTL time.Duration
+	logger    *zap.Logger
+}
+
+// NewLTIUsecase creates a new LTIUsecase signing with key, the tool's
+// private key. publicURL is the API's external base URL, from which the
+// launch URL platforms redirect to is built. appURL, if set, is the page
+// launches are redirected to, with the launch ID in its "launch" query
+// parameter. A launch accepts submissions for launchTTL.
+func NewLTIUsecase(repo repository.LTIRepository, logins repository.LTILoginStore, submit *SubmitJobUsecase, client lti.Client, key *rsa.PrivateKey, publicURL, appURL string, launchTTL time.Duration, logger *zap.Logger) *LTIUsecase {
+	return &LTIUsecase{
+		repo:      repo,
+		logins:    logins,
+		submit:    submit,
+		client:    client,
+		key:       key,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		appURL:    appURL,
+		launchTTL: launchTTL,
+		logger:    logger,
+	}
+}
+
+// RegisterPlatform validates and registers a platform for a tenant, whose
+// quota its launches count against unless the tenant mapping gives them
+// tenants of their own.
+func (uc *LTIUsecase) RegisterPlatform(ctx context.Context, tenantID string, req *domain.RegisterLTIPlatformRequest) (*domain.LTIPlatform, error) {
+	for _, field := range []string{req.Issuer, req.ClientID, req.DeploymentID} {
+		if len(field) > maxLTIFieldLen {
+			return nil, fmt.Errorf("%w: fields must be at most %d bytes", domain.ErrInvalidLTIPlatform, maxLTIFieldLen)
+		}
+	}
+	for name, endpoint := range map[string]string{
+		"issuer": req.Issuer, "auth_login_url": req.AuthLoginURL, "token_url": req.TokenURL, "jwks_url": req.JWKSURL,
+	} {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || len(endpoint) > maxLTIFieldLen {
+			return nil, fmt.Errorf("%w: %s must be an absolute http(s) URL", domain.ErrInvalidLTIPlatform, name)
+		}
+	}
+	mapping := req.TenantMapping
+	if mapping == "" {
+		mapping = domain.LTITenantPlatform
+	}
+	if !mapping.IsValid() {
+		return nil, fmt.Errorf("%w: tenant_mapping must be platform, context or user", domain.ErrInvalidLTIPlatform)
+	}
+
+	platform := &domain.LTIPlatform{
+		ID:            uuid.New(),
+		TenantID:      tenantOrDefault(tenantID),
+		Issuer:        req.Issuer,
+		ClientID:      req.ClientID,
+		DeploymentID:  req.DeploymentID,
+		AuthLoginURL:  req.AuthLoginURL,
+		TokenURL:      req.TokenURL,
+		JWKSURL:       req.JWKSURL,
+		TenantMapping: mapping,
+	}
+	if err := uc.repo.CreatePlatform(ctx, platform); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("LTI platform registered",
+		zap.String("tenant_id", platform.TenantID),
+		zap.String("platform_id", platform.ID.String()),
+		zap.String("issuer", platform.Issuer),
+	)
+	return platform, nil
+}
+
+// GetPlatform returns a tenant's platform.
+func (uc *LTIUsecase) GetPlatform(ctx context.Context, tenantID string, id uuid.UUID) (*domain.LTIPlatform, error) {
+	platform, err := uc.repo.GetPlatform(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if platform.TenantID != tenantOrDefault(tenantID) {
+		return nil, domain.ErrLTIPlatformNotFound
+	}
+	return platform, nil
+}
+
+// DeletePlatform unregisters a tenant's platform. Its launches end, and
+// scores not yet posted are dropped.
+func (uc *LTIUsecase) DeletePlatform(ctx context.Context, tenantID string, id uuid.UUID) error {
+	return uc.repo.DeletePlatform(ctx, tenantOrDefault(tenantID), id)
+}
+
+// ListUsers returns the users who have launched from a tenant's platform,
+// with the tenant each is mapped to.
+func (uc *LTIUsecase) ListUsers(ctx context.Context, tenantID string, id uuid.UUID) ([]*domain.LTIUser, error) {
+	if _, err := uc.GetPlatform(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+	return uc.repo.ListUsers(ctx, id)
+}
+
+// JWKS returns the tool's public key set, which platforms verify its
+// token requests against.
+func (uc *LTIUsecase) JWKS() lti.JWKS {
+	return lti.JWKS{Keys: []lti.JWK{lti.PublicJWK(&uc.key.PublicKey)}}
+}
+
+// Login starts a launch: it records a state and nonce for the platform and
+// returns the URL of the platform's authentication request, which posts
+// the launch's id_token back to the launch URL.
+func (uc *LTIUsecase) Login(ctx context.Context, req *LTILoginRequest) (string, error) {
+	if req.Issuer == "" || req.ClientID == "" || req.LoginHint == "" {
+		return "", fmt.Errorf("%w: iss, client_id and login_hint are required", domain.ErrInvalidLTILaunch)
+	}
+	platform, err := uc.repo.FindPlatform(ctx, req.Issuer, req.ClientID)
+	if err != nil {
+		return "", err
+	}
+	state, err := randomHex()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomHex()
+	if err != nil {
+		return "", err
+	}
+	if err := uc.logins.PutLogin(ctx, state, &domain.LTILogin{PlatformID: platform.ID, Nonce: nonce}, ltiLoginTTL); err != nil {
+		return "", err
+	}
+
+	auth, err := url.Parse(platform.AuthLoginURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: auth_login_url", domain.ErrInvalidLTIPlatform)
+	}
+	query := auth.Query()
+	query.Set("scope", "openid")
+	query.Set("response_type", "id_token")
+	query.Set("response_mode", "form_post")
+	query.Set("prompt", "none")
+	query.Set("client_id", platform.ClientID)
+	query.Set("redirect_uri", uc.publicURL+ltiLaunchPath)
+	query.Set("login_hint", req.LoginHint)
+	if req.LTIMessageHint != "" {
+		query.Set("lti_message_hint", req.LTIMessageHint)
+	}
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	auth.RawQuery = query.Encode()
+	return auth.String(), nil
+}
+
+// Launch verifies the id_token a platform posts back for the login holding
+// state, and records the launch. The token must be a resource link launch
+// carrying the problem in the custom parameter problem_id; if it grants
+// the AGS score scope on a line item, submissions from the launch are
+// graded.
+func (uc *LTIUsecase) Launch(ctx context.Context, idToken, state string) (*domain.LTILaunch, error) {
+	login, err := uc.logins.TakeLogin(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	platform, err := uc.repo.GetPlatform(ctx, login.PlatformID)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := uc.verify(ctx, platform, idToken)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := lti.ParseLaunch(payload)
+	if err == nil {
+		err = claims.Validate(platform.Issuer, platform.ClientID, platform.DeploymentID, login.Nonce, time.Now())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidLTILaunch, err)
+	}
+	problemID := claims.CustomString("problem_id")
+	if problemID == "" {
+		return nil, fmt.Errorf("%w: custom parameter problem_id is missing", domain.ErrInvalidLTILaunch)
+	}
+	if _, err := uc.submit.checkProblem(ctx, problemID); err != nil {
+		return nil, err
+	}
+
+	user := &domain.LTIUser{
+		PlatformID: platform.ID,
+		Subject:    claims.Subject,
+		TenantID:   ltiTenant(platform, claims),
+		Name:       claims.Name,
+		Email:      claims.Email,
+	}
+	if err := uc.repo.UpsertUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	launch := &domain.LTILaunch{
+		ID:         uuid.New(),
+		PlatformID: platform.ID,
+		Subject:    claims.Subject,
+		TenantID:   user.TenantID,
+		ProblemID:  problemID,
+		ExpiresAt:  time.Now().Add(uc.launchTTL).UTC(),
+	}
+	if claims.CanPostScores() {
+		launch.LineItem = claims.Endpoint.LineItem
+		launch.Graded = true
+	}
+	if err := uc.repo.CreateLaunch(ctx, launch); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("LTI launch",
+		zap.String("platform_id", platform.ID.String()),
+		zap.String("launch_id", launch.ID.String()),
+		zap.String("tenant_id", launch.TenantID),
+		zap.String("problem_id", problemID),
+	)
+	return launch, nil
+}
+
+// verify checks a launch token against the platform's keys, fetching them
+// again once if the token does not verify against the cached set, as when
+// the platform has rotated keys.
+func (uc *LTIUsecase) verify(ctx context.Context, platform *domain.LTIPlatform, idToken string) ([]byte, error) {
+	keys, err := uc.client.Keys(ctx, platform.JWKSURL, false)
+	if err != nil {
+		return nil, fmt.Errorf("fetch platform keys: %w", err)
+	}
+	payload, err := lti.Verify(idToken, keys)
+	if errors.Is(err, lti.ErrInvalidToken) {
+		if keys, err = uc.client.Keys(ctx, platform.JWKSURL, true); err != nil {
+			return nil, fmt.Errorf("fetch platform keys: %w", err)
+		}
+		payload, err = lti.Verify(idToken, keys)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidLTILaunch, err)
+	}
+	return payload, nil
+}
+
+// AppURL returns where a browser completing launch is sent, or "" if no
+// app URL is configured.
+func (uc *LTIUsecase) AppURL(launch *domain.LTILaunch) string {
+	if uc.appURL == "" {
+		return ""
+	}
+	u, err := url.Parse(uc.appURL)
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	query.Set("launch", launch.ID.String())
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// GetLaunch returns a launch that has not expired.
+func (uc *LTIUsecase) GetLaunch(ctx context.Context, id uuid.UUID) (*domain.LTILaunch, error) {
+	launch, err := uc.repo.GetLaunch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(launch.ExpiresAt) {
+		return nil, domain.ErrLTILaunchNotFound
+	}
+	return launch, nil
+}
+
+// QuotaResetAt returns when the current quota window of submissions ends,
+// or the zero time if quotas are disabled.
+func (uc *LTIUsecase) QuotaResetAt(now time.Time) time.Time {
+	return uc.submit.QuotaResetAt(now)
+}
+
+// Submit submits a solution to a launch's problem on behalf of its user,
+// counting against the user's tenant. Submissions from a graded launch
+// have their score posted to the platform once judged.
+func (uc *LTIUsecase) Submit(ctx context.Context, launchID uuid.UUID, req *domain.LTISubmitRequest) (*domain.SubmitResponse, error) {
+	launch, err := uc.GetLaunch(ctx, launchID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := uc.submit.Execute(ctx, &domain.SubmitRequest{
+		Language:   req.Language,
+		SourceCode: req.SourceCode,
+		ProblemID:  launch.ProblemID,
+		TenantID:   launch.TenantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if launch.Graded {
+		if err := uc.repo.AddScore(ctx, resp.JobID, launch.ID); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// Run posts scores every interval until ctx is done.
+func (uc *LTIUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := uc.Report(ctx); err != nil && ctx.Err() == nil {
+				uc.logger.Warn("LTI score report failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Report posts the score of every finished job submitted from a graded
+// launch and returns how many were posted. A score the platform refuses is
+// retried on the next pass. Internal errors and cancelled jobs are not
+// graded.
+func (uc *LTIUsecase) Report(ctx context.Context) (int, error) {
+	scores, err := uc.repo.ListFinishedScores(ctx, ltiReportLimit)
+	if err != nil {
+		return 0, err
+	}
+	posted := 0
+	for _, s := range scores {
+		platform, err := uc.repo.GetPlatform(ctx, s.PlatformID)
+		switch {
+		case errors.Is(err, domain.ErrLTIPlatformNotFound):
+			// Unregistered since submitting; there is no one to post to.
+		case err != nil:
+			return posted, err
+		case s.Status == domain.StatusInternalError || s.Status == domain.StatusCancelled:
+		default:
+			if err := uc.client.PostScore(ctx, platform.TokenURL, platform.ClientID, s.LineItem, ltiScore(s, time.Now())); err != nil {
+				uc.logger.Warn("Posting LTI score failed",
+					zap.String("platform_id", platform.ID.String()),
+					zap.String("job_id", s.JobID.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			posted++
+		}
+		if err := uc.repo.RemoveScore(ctx, s.JobID); err != nil {
+			return posted, err
+		}
+	}
+	return posted, nil
+}
+
+// ltiTenant maps a launching user to a tenant under the platform's
+// mapping. A context mapping without a context falls back to the
+// platform's tenant.
+func ltiTenant(platform *domain.LTIPlatform, claims *lti.LaunchClaims) string {
+	switch {
+	case platform.TenantMapping == domain.LTITenantUser:
+		return platform.TenantID + ":" + claims.Subject
+	case platform.TenantMapping == domain.LTITenantContext && claims.Context.ID != "":
+		return platform.TenantID + ":" + claims.Context.ID
+	}
+	return platform.TenantID
+}
+
+// ltiScore maps a finished job to its AGS score: a scored problem's points
+// out of its maximum, otherwise full marks for an accepted or cleanly run
+// job and none for any other verdict.
+func ltiScore(s *domain.LTIScore, now time.Time) lti.Score {
+	given, maximum := 0.0, 1.0
+	switch {
+	case s.Score != nil && s.MaxScore != nil && *s.MaxScore > 0:
+		given, maximum = *s.Score, *s.MaxScore
+	case s.Status == domain.StatusAccepted || s.Status == domain.StatusSuccess:
+		given = 1
+	}
+	return lti.Score{
+		UserID:           s.Subject,
+		ScoreGiven:       given,
+		ScoreMaximum:     maximum,
+		Comment:          string(s.Status),
+		Timestamp:        now.UTC().Format(time.RFC3339Nano),
+		ActivityProgress: "Completed",
+		GradingProgress:  "FullyGraded",
+	}
+}
+
+func randomHex() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate lti state: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
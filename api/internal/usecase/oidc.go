@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/oidc"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	// maxOIDCTenantLen bounds a tenant taken from a token's claim, as for
+	// the tenant of an API key.
+	maxOIDCTenantLen = 200
+
+	// maxKnownOIDCUsers bounds the users OIDCUsecase remembers; past it,
+	// it starts over.
+	maxKnownOIDCUsers = 10000
+)
+
+// OIDCUsecase authenticates requests by the tokens an external OpenID
+// Connect provider issues. A user of the provider becomes a Sentinel user
+// the first time one of its tokens is seen, in the tenant its tenant claim
+// names or else the configured tenant.
+type OIDCUsecase struct {
+	provider    *oidc.Provider
+	users       repository.UserRepository
+	tenantID    string
+	tenantClaim string
+	logger      *zap.Logger
+
+	// known holds the users seen recently by subject, so a request whose
+	// token agrees with the stored tenant and email writes nothing.
+	mu    sync.Mutex
+	known map[string]*domain.User
+}
+
+// NewOIDCUsecase creates an OIDCUsecase for provider's users. tenantClaim,
+// if set, names the string claim holding a user's tenant, and a token
+// without it is rejected; otherwise users act as tenantID, the default
+// tenant if empty.
+func NewOIDCUsecase(provider *oidc.Provider, users repository.UserRepository, tenantID, tenantClaim string, logger *zap.Logger) *OIDCUsecase {
+	return &OIDCUsecase{
+		provider:    provider,
+		users:       users,
+		tenantID:    tenantOrDefault(tenantID),
+		tenantClaim: tenantClaim,
+		logger:      logger,
+		known:       make(map[string]*domain.User),
+	}
+}
+
+// Authenticate returns the user a provider's token was issued to, creating
+// it on its first request, or domain.ErrInvalidUserToken if the token does
+// not verify or names no usable tenant.
+func (uc *OIDCUsecase) Authenticate(ctx context.Context, token string) (*domain.User, error) {
+	claims, err := uc.provider.Verify(ctx, token, time.Now())
+	if errors.Is(err, oidc.ErrInvalidToken) {
+		return nil, domain.ErrInvalidUserToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("verify oidc token: %w", err)
+	}
+
+	tenantID := uc.tenantID
+	if uc.tenantClaim != "" {
+		tenantID = claims.String(uc.tenantClaim)
+		if tenantID == "" || len(tenantID) > maxOIDCTenantLen || strings.TrimSpace(tenantID) != tenantID {
+			return nil, domain.ErrInvalidUserToken
+		}
+	}
+
+	uc.mu.Lock()
+	known, ok := uc.known[claims.Subject]
+	uc.mu.Unlock()
+	if ok && known.TenantID == tenantID && known.Email == claims.Email {
+		user := *known
+		return &user, nil
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate UUIDv7: %w", err)
+	}
+	user := &domain.User{UserID: id, TenantID: tenantID, Email: claims.Email}
+	if err := uc.users.UpsertExternal(ctx, user, uc.provider.Issuer(), claims.Subject); err != nil {
+		return nil, err
+	}
+	if !ok {
+		uc.logger.Info("OIDC user signed in",
+			zap.String("user_id", user.UserID.String()),
+			zap.String("tenant_id", user.TenantID),
+		)
+	}
+
+	stored := *user
+	uc.mu.Lock()
+	if len(uc.known) >= maxKnownOIDCUsers {
+		clear(uc.known)
+	}
+	uc.known[claims.Subject] = &stored
+	uc.mu.Unlock()
+	return user, nil
+}
+
+// DiscoveryURL returns where the provider's discovery document is served.
+func (uc *OIDCUsecase) DiscoveryURL() string {
+	return uc.provider.DiscoveryURL()
+}
@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	maxProblemIDLength = 64
+	maxTestCases       = 100
+	maxSubtasks        = 20
+
+	// maxComparatorSize caps an uploaded comparator module.
+	maxComparatorSize = 1 << 20 // 1 MB
+
+	maxRules             = 32
+	maxRulePatternLength = 1024
+	maxRuleMessageLength = 256
+
+	maxKillPatterns       = 16
+	maxKillPatternRepeats = 1000000
+)
+
+// wasmMagic starts every WebAssembly binary module.
+var wasmMagic = []byte("\x00asm")
+
+// ProblemUsecase manages problems, their versioned test data, and rejudging.
+type ProblemUsecase struct {
+	problems  repository.ProblemRepository
+	jobs      repository.JobRepository
+	publisher publisher.Publisher
+	languages *language.Registry
+	logger    *zap.Logger
+}
+
+// NewProblemUsecase creates a new ProblemUsecase. languages decides which
+// generator languages are accepted.
+func NewProblemUsecase(problems repository.ProblemRepository, jobs repository.JobRepository, pub publisher.Publisher, languages *language.Registry, logger *zap.Logger) *ProblemUsecase {
+	return &ProblemUsecase{
+		problems:  problems,
+		jobs:      jobs,
+		publisher: pub,
+		languages: languages,
+		logger:    logger,
+	}
+}
+
+// Create registers a new problem with version 1 of its test data.
+func (uc *ProblemUsecase) Create(ctx context.Context, req *domain.CreateProblemRequest) (*domain.Problem, error) {
+	id := strings.TrimSpace(req.ProblemID)
+	if id == "" || len(id) > maxProblemIDLength {
+		return nil, fmt.Errorf("%w: problem_id must be 1-%d characters", domain.ErrInvalidTestData, maxProblemIDLength)
+	}
+	if err := uc.validateGenerator(req.Generator); err != nil {
+		return nil, err
+	}
+	if err := validateComparator(req.Comparator); err != nil {
+		return nil, err
+	}
+	if err := uc.validateInteractor(req.Interactor, req.Comparator); err != nil {
+		return nil, err
+	}
+	if err := uc.validateHarnesses(req.Harnesses); err != nil {
+		return nil, err
+	}
+	if err := uc.validateRules(req.Rules); err != nil {
+		return nil, err
+	}
+	if err := validateKillPatterns(req.KillPatterns); err != nil {
+		return nil, err
+	}
+	if err := validateTestData(req.Generator, req.Subtasks, req.TestCases); err != nil {
+		return nil, err
+	}
+	strategy := req.TerminationStrategy
+	if strategy == "" {
+		strategy = domain.TerminateRunAll
+	}
+	if !strategy.IsValid() {
+		return nil, fmt.Errorf("%w: unknown termination_strategy %q", domain.ErrInvalidTestData, strategy)
+	}
+
+	problem := &domain.Problem{
+		ProblemID:           id,
+		Title:               req.Title,
+		TerminationStrategy: strategy,
+		TestCases:           req.TestCases,
+		Subtasks:            req.Subtasks,
+		Generator:           req.Generator,
+		Comparator:          req.Comparator,
+		Interactor:          req.Interactor,
+		Harnesses:           req.Harnesses,
+		Rules:               req.Rules,
+		KillPatterns:        req.KillPatterns,
+	}
+	if err := uc.problems.Create(ctx, problem); err != nil {
+		return nil, err
+	}
+	if problem.Comparator != nil {
+		// Like Get, report the module by its hash only.
+		problem.Comparator = &domain.Comparator{Hash: problem.Comparator.Hash}
+	}
+
+	uc.logger.Info("Problem created", zap.String("problem_id", id), zap.Int("test_cases", len(req.TestCases)))
+	return problem, nil
+}
+
+// Get returns a problem with the test cases of its current version.
+func (uc *ProblemUsecase) Get(ctx context.Context, id string) (*domain.Problem, error) {
+	return uc.problems.GetByID(ctx, id)
+}
+
+// UpdateTestData stores a new version of the problem's test cases. Existing
+// verdicts keep the version they were judged on until rejudged.
+func (uc *ProblemUsecase) UpdateTestData(ctx context.Context, id string, req *domain.UpdateTestDataRequest) (*domain.Problem, error) {
+	if err := uc.validateGenerator(req.Generator); err != nil {
+		return nil, err
+	}
+	if err := validateComparator(req.Comparator); err != nil {
+		return nil, err
+	}
+	if err := uc.validateInteractor(req.Interactor, req.Comparator); err != nil {
+		return nil, err
+	}
+	if err := uc.validateHarnesses(req.Harnesses); err != nil {
+		return nil, err
+	}
+	if err := uc.validateRules(req.Rules); err != nil {
+		return nil, err
+	}
+	if err := validateKillPatterns(req.KillPatterns); err != nil {
+		return nil, err
+	}
+	if err := validateTestData(req.Generator, req.Subtasks, req.TestCases); err != nil {
+		return nil, err
+	}
+	version, err := uc.problems.ReplaceTestData(ctx, id, req.Generator, req.Comparator, req.Interactor, req.Harnesses, req.Rules, req.KillPatterns, req.Subtasks, req.TestCases)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Problem test data updated",
+		zap.String("problem_id", id),
+		zap.Int("test_data_version", version),
+		zap.Int("test_cases", len(req.TestCases)),
+	)
+	return uc.problems.GetByID(ctx, id)
+}
+
+// Rejudge re-queues every finished submission of the problem that was judged
+// against an older test-data version, at PriorityBulk so that new submissions
+// run first. Submissions that break one of the
+// problem's current rules get that verdict instead of being requeued. A
+// failure to requeue one submission is counted and logged; the rest are still
+// processed.
+func (uc *ProblemUsecase) Rejudge(ctx context.Context, id string) (*domain.RejudgeResponse, error) {
+	problem, err := uc.problems.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	outdated, err := uc.jobs.ListOutdatedByProblem(ctx, id, problem.TestDataVersion)
+	if err != nil {
+		return nil, fmt.Errorf("list outdated submissions: %w", err)
+	}
+
+	resp := &domain.RejudgeResponse{
+		ProblemID:       id,
+		TestDataVersion: problem.TestDataVersion,
+	}
+	for _, old := range outdated {
+		job, err := uc.jobs.PrepareRejudge(ctx, old.JobID)
+		if err != nil {
+			uc.logger.Error("Failed to reset job for rejudge", zap.Error(err), zap.String("job_id", old.JobID.String()))
+			resp.Failed++
+			continue
+		}
+		if violation := ruleViolation(problem.Rules, job.Language, job.SubmittedSource()); violation != "" {
+			if err := uc.jobs.SetResult(ctx, job.JobID, &domain.Job{Status: domain.StatusRuleViolation, Stderr: violation}); err != nil {
+				uc.logger.Error("Failed to record rule violation", zap.Error(err), zap.String("job_id", job.JobID.String()))
+				resp.Failed++
+				continue
+			}
+			resp.RuleViolations++
+			continue
+		}
+		job.Priority = domain.PriorityBulk
+		if err := uc.publisher.Publish(ctx, job); err != nil {
+			uc.logger.Error("Failed to publish rejudge", zap.Error(err), zap.String("job_id", job.JobID.String()))
+			_ = uc.jobs.UpdateStatus(ctx, job.JobID, domain.StatusInternalError)
+			resp.Failed++
+			continue
+		}
+		resp.Requeued++
+	}
+
+	uc.logger.Info("Rejudge queued",
+		zap.String("problem_id", id),
+		zap.Int("test_data_version", problem.TestDataVersion),
+		zap.Int("requeued", resp.Requeued),
+		zap.Int("failed", resp.Failed),
+		zap.Int("rule_violations", resp.RuleViolations),
+	)
+	return resp, nil
+}
+
+// validateGenerator checks an optional generator and fills in its Hash.
+func (uc *ProblemUsecase) validateGenerator(gen *domain.Generator) error {
+	if gen == nil {
+		return nil
+	}
+	if !uc.languages.IsSupported(gen.Language) {
+		return fmt.Errorf("%w: unsupported generator language %q", domain.ErrInvalidTestData, gen.Language)
+	}
+	if strings.TrimSpace(gen.SourceCode) == "" || len(gen.SourceCode) > maxSourceCodeSize {
+		return fmt.Errorf("%w: generator source_code must be 1-%d bytes", domain.ErrInvalidTestData, maxSourceCodeSize)
+	}
+	sum := sha256.Sum256([]byte(string(gen.Language) + "\x00" + gen.SourceCode))
+	gen.Hash = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// validateComparator checks that an optional comparator is a WebAssembly
+// module of acceptable size and fills in its Hash. Whether it has the exports
+// a comparator needs is only known once a worker instantiates it.
+func validateComparator(cmp *domain.Comparator) error {
+	if cmp == nil {
+		return nil
+	}
+	if len(cmp.Wasm) > maxComparatorSize || !bytes.HasPrefix(cmp.Wasm, wasmMagic) {
+		return fmt.Errorf("%w: comparator wasm must be a WebAssembly module of at most %d bytes", domain.ErrInvalidTestData, maxComparatorSize)
+	}
+	sum := sha256.Sum256(cmp.Wasm)
+	cmp.Hash = hex.EncodeToString(sum[:])
+	return nil
+}
+
+// validateInteractor checks an optional interactor. An interactor decides
+// verdicts itself, so it cannot be combined with a comparator.
+func (uc *ProblemUsecase) validateInteractor(inter *domain.Interactor, cmp *domain.Comparator) error {
+	if inter == nil {
+		return nil
+	}
+	if cmp != nil {
+		return fmt.Errorf("%w: a problem cannot have both an interactor and a comparator", domain.ErrInvalidTestData)
+	}
+	if !uc.languages.IsSupported(inter.Language) {
+		return fmt.Errorf("%w: unsupported interactor language %q", domain.ErrInvalidTestData, inter.Language)
+	}
+	if strings.TrimSpace(inter.SourceCode) == "" || len(inter.SourceCode) > maxSourceCodeSize {
+		return fmt.Errorf("%w: interactor source_code must be 1-%d bytes", domain.ErrInvalidTestData, maxSourceCodeSize)
+	}
+	return nil
+}
+
+// validateHarnesses checks a problem's harnesses: at most one per supported
+// language, each a template holding the placeholder exactly once.
+func (uc *ProblemUsecase) validateHarnesses(harnesses []domain.Harness) error {
+	seen := make(map[domain.Language]bool, len(harnesses))
+	for i, h := range harnesses {
+		if !uc.languages.IsSupported(h.Language) {
+			return fmt.Errorf("%w: harness %d has unsupported language %q", domain.ErrInvalidTestData, i+1, h.Language)
+		}
+		if seen[h.Language] {
+			return fmt.Errorf("%w: more than one harness for %q", domain.ErrInvalidTestData, h.Language)
+		}
+		seen[h.Language] = true
+		if len(h.Template) > maxSourceCodeSize {
+			return fmt.Errorf("%w: harness %d template exceeds %d bytes", domain.ErrInvalidTestData, i+1, maxSourceCodeSize)
+		}
+		if strings.Count(h.Template, domain.HarnessPlaceholder) != 1 {
+			return fmt.Errorf("%w: harness %d template must contain %s exactly once", domain.ErrInvalidTestData, i+1, domain.HarnessPlaceholder)
+		}
+	}
+	return nil
+}
+
+// validateRules checks a problem's forbidden source rules: a supported
+// language and a pattern that compiles.
+func (uc *ProblemUsecase) validateRules(rules []domain.SourceRule) error {
+	if len(rules) > maxRules {
+		return fmt.Errorf("%w: at most %d rules", domain.ErrInvalidTestData, maxRules)
+	}
+	for i, rule := range rules {
+		if !uc.languages.IsSupported(rule.Language) {
+			return fmt.Errorf("%w: rule %d has unsupported language %q", domain.ErrInvalidTestData, i+1, rule.Language)
+		}
+		if rule.Pattern == "" || len(rule.Pattern) > maxRulePatternLength {
+			return fmt.Errorf("%w: rule %d pattern must be 1-%d bytes", domain.ErrInvalidTestData, i+1, maxRulePatternLength)
+		}
+		if _, err := compileRule(rule); err != nil {
+			return fmt.Errorf("%w: rule %d pattern: %v", domain.ErrInvalidTestData, i+1, err)
+		}
+		if len(rule.Message) > maxRuleMessageLength {
+			return fmt.Errorf("%w: rule %d message exceeds %d bytes", domain.ErrInvalidTestData, i+1, maxRuleMessageLength)
+		}
+	}
+	return nil
+}
+
+// compileRule compiles a rule's pattern, in which ^ and $ match at line
+// boundaries.
+func compileRule(rule domain.SourceRule) (*regexp.Regexp, error) {
+	return regexp.Compile("(?m)" + rule.Pattern)
+}
+
+// ruleViolation returns the verdict message for the first of rules that
+// source in lang breaks, naming the rule and the line it matched, or "" if
+// it breaks none.
+func ruleViolation(rules []domain.SourceRule, lang domain.Language, source string) string {
+	for i, rule := range rules {
+		if rule.Language != lang {
+			continue
+		}
+		re, err := compileRule(rule)
+		if err != nil {
+			// Validated on upload.
+			continue
+		}
+		loc := re.FindStringIndex(source)
+		if loc == nil {
+			continue
+		}
+		msg := rule.Message
+		if msg == "" {
+			msg = fmt.Sprintf("source matches forbidden pattern %q", rule.Pattern)
+		}
+		line := strings.Count(source[:loc[0]], "\n") + 1
+		return fmt.Sprintf("rule %d, line %d: %s", i+1, line, msg)
+	}
+	return ""
+}
+
+// validateKillPatterns checks a problem's kill patterns: a pattern that
+// compiles and a repeat count in range. Workers match them line by line.
+func validateKillPatterns(patterns []domain.KillPattern) error {
+	if len(patterns) > maxKillPatterns {
+		return fmt.Errorf("%w: at most %d kill patterns", domain.ErrInvalidTestData, maxKillPatterns)
+	}
+	for i, p := range patterns {
+		if p.Pattern == "" || len(p.Pattern) > maxRulePatternLength {
+			return fmt.Errorf("%w: kill pattern %d must be 1-%d bytes", domain.ErrInvalidTestData, i+1, maxRulePatternLength)
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("%w: kill pattern %d: %v", domain.ErrInvalidTestData, i+1, err)
+		}
+		if p.Repeats < 0 || p.Repeats > maxKillPatternRepeats {
+			return fmt.Errorf("%w: kill pattern %d repeats must be 0-%d", domain.ErrInvalidTestData, i+1, maxKillPatternRepeats)
+		}
+		if len(p.Message) > maxRuleMessageLength {
+			return fmt.Errorf("%w: kill pattern %d message exceeds %d bytes", domain.ErrInvalidTestData, i+1, maxRuleMessageLength)
+		}
+	}
+	return nil
+}
+
+func validateTestData(gen *domain.Generator, subtasks []domain.Subtask, cases []domain.TestCase) error {
+	if len(cases) == 0 || len(cases) > maxTestCases {
+		return fmt.Errorf("%w: expected 1-%d test cases", domain.ErrInvalidTestData, maxTestCases)
+	}
+	if len(subtasks) > maxSubtasks {
+		return fmt.Errorf("%w: at most %d subtasks", domain.ErrInvalidTestData, maxSubtasks)
+	}
+	for i, st := range subtasks {
+		if st.Points < 0 || math.IsNaN(st.Points) || math.IsInf(st.Points, 0) {
+			return fmt.Errorf("%w: subtask %d has invalid points", domain.ErrInvalidTestData, i+1)
+		}
+		if st.Scoring == "" {
+			subtasks[i].Scoring = domain.ScoreAllOrNothing
+		} else if !st.Scoring.IsValid() {
+			return fmt.Errorf("%w: subtask %d has unknown scoring %q", domain.ErrInvalidTestData, i+1, st.Scoring)
+		}
+	}
+
+	// Every case must name a defined subtask (or none), and every subtask
+	// needs at least one case so its points can't be awarded for free.
+	used := make([]bool, len(subtasks))
+	for i, tc := range cases {
+		if tc.Subtask < 0 || tc.Subtask > len(subtasks) {
+			return fmt.Errorf("%w: test case %d references unknown subtask %d", domain.ErrInvalidTestData, i+1, tc.Subtask)
+		}
+		if tc.Subtask > 0 {
+			used[tc.Subtask-1] = true
+		}
+		if tc.TimeLimitMs != nil && (*tc.TimeLimitMs <= 0 || *tc.TimeLimitMs > maxTimeLimitMs) {
+			return fmt.Errorf("%w: test case %d time_limit_ms must be 1-%d", domain.ErrInvalidTestData, i+1, maxTimeLimitMs)
+		}
+		if tc.MemoryLimitKB != nil && (*tc.MemoryLimitKB <= 0 || *tc.MemoryLimitKB > maxMemoryLimitKB) {
+			return fmt.Errorf("%w: test case %d memory_limit_kb must be 1-%d", domain.ErrInvalidTestData, i+1, maxMemoryLimitKB)
+		}
+		if tc.GeneratorSeed != nil {
+			if gen == nil {
+				return fmt.Errorf("%w: test case %d has a generator_seed but the problem has no generator", domain.ErrInvalidTestData, i+1)
+			}
+			if tc.Input != "" {
+				return fmt.Errorf("%w: test case %d sets both input and generator_seed", domain.ErrInvalidTestData, i+1)
+			}
+		}
+	}
+	for i, ok := range used {
+		if !ok {
+			return fmt.Errorf("%w: subtask %d has no test cases", domain.ErrInvalidTestData, i+1)
+		}
+	}
+	return nil
+}
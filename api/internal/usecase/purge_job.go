@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// PurgeJobUsecase permanently deletes submissions, for sources that were
+// submitted by mistake and must not be retained. Only finished jobs are
+// purged, so that no worker writes a result for a deleted row.
+type PurgeJobUsecase struct {
+	jobs   repository.JobRepository
+	logger *zap.Logger
+}
+
+// NewPurgeJobUsecase creates a new PurgeJobUsecase.
+func NewPurgeJobUsecase(jobs repository.JobRepository, logger *zap.Logger) *PurgeJobUsecase {
+	return &PurgeJobUsecase{
+		jobs:   jobs,
+		logger: logger,
+	}
+}
+
+// Execute deletes the job with its events, artifacts and appeals. It
+// returns domain.ErrJobActive if the job has no result yet.
+func (uc *PurgeJobUsecase) Execute(ctx context.Context, id uuid.UUID) error {
+	deleted, err := uc.jobs.DeleteFinished(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		// Either the job does not exist or it has not finished.
+		if _, err := uc.jobs.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrJobActive
+	}
+	uc.logger.Warn("Job purged", zap.String("job_id", id.String()))
+	return nil
+}
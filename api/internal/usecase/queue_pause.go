@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// QueuePauseUsecase pauses and resumes languages. Workers move the jobs of
+// a paused language they receive to its holding queue in the broker, where
+// they wait until the language is resumed, while other languages keep
+// running.
+type QueuePauseUsecase struct {
+	pauses repository.QueuePauseStore
+	queue  publisher.LanguageHolder
+	logger *zap.Logger
+}
+
+// NewQueuePauseUsecase creates a new QueuePauseUsecase.
+func NewQueuePauseUsecase(pauses repository.QueuePauseStore, queue publisher.LanguageHolder, logger *zap.Logger) *QueuePauseUsecase {
+	return &QueuePauseUsecase{
+		pauses: pauses,
+		queue:  queue,
+		logger: logger,
+	}
+}
+
+// Pause pauses a language, built-in or registered as a runtime. Pausing a
+// paused language changes nothing.
+func (uc *QueuePauseUsecase) Pause(ctx context.Context, lang domain.Language) (*domain.QueuePause, error) {
+	if err := validatePauseLanguage(lang); err != nil {
+		return nil, err
+	}
+	pausedAt, err := uc.pauses.Pause(ctx, lang)
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Language paused", zap.String("language", string(lang)))
+
+	held, err := uc.queue.HeldMessages(ctx, lang)
+	if err != nil {
+		return nil, fmt.Errorf("count held jobs: %w", err)
+	}
+	return &domain.QueuePause{Language: lang, PausedAt: pausedAt, Held: held}, nil
+}
+
+// Resume resumes a language and moves its held jobs back to the execution
+// queue. Resuming again moves any a release cut short, or a worker held as
+// the language was resumed.
+func (uc *QueuePauseUsecase) Resume(ctx context.Context, lang domain.Language) (*domain.QueueResume, error) {
+	if err := validatePauseLanguage(lang); err != nil {
+		return nil, err
+	}
+	wasPaused, err := uc.pauses.Resume(ctx, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	released, err := uc.queue.ReleaseHeld(ctx, lang)
+	if err != nil {
+		uc.logger.Error("Releasing held jobs failed",
+			zap.String("language", string(lang)),
+			zap.Int("released", released),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("release held jobs: %w", err)
+	}
+	if wasPaused || released > 0 {
+		uc.logger.Info("Language resumed", zap.String("language", string(lang)), zap.Int("released", released))
+	}
+	return &domain.QueueResume{Language: lang, Released: released}, nil
+}
+
+// List returns the paused languages by name, with their held jobs.
+func (uc *QueuePauseUsecase) List(ctx context.Context) ([]*domain.QueuePause, error) {
+	paused, err := uc.pauses.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pauses := make([]*domain.QueuePause, 0, len(paused))
+	for lang, pausedAt := range paused {
+		held, err := uc.queue.HeldMessages(ctx, lang)
+		if err != nil {
+			return nil, fmt.Errorf("count held jobs: %w", err)
+		}
+		pauses = append(pauses, &domain.QueuePause{Language: lang, PausedAt: pausedAt, Held: held})
+	}
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i].Language < pauses[j].Language })
+	return pauses, nil
+}
+
+// validatePauseLanguage accepts any name a language or runtime can have,
+// so a runtime can be paused before it is registered.
+func validatePauseLanguage(lang domain.Language) error {
+	if !runtimeNamePattern.MatchString(string(lang)) {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidLanguage, lang)
+	}
+	return nil
+}
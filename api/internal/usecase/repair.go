@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	defaultRepairOlderThan = 15 * time.Minute
+	// minRepairOlderThan keeps a repair away from jobs that are merely slow.
+	minRepairOlderThan = time.Minute
+	defaultRepairLimit = 100
+	maxRepairLimit     = 1000
+)
+
+// RepairUsecase runs the recovery routines of POST /admin/repair. Each
+// action only touches jobs untouched since a cutoff and leaves them touched,
+// so running a repair twice does nothing the second time.
+type RepairUsecase struct {
+	jobs      repository.JobRepository
+	locks     repository.LockStore
+	publisher publisher.Publisher
+	logger    *zap.Logger
+}
+
+// NewRepairUsecase creates a new RepairUsecase.
+func NewRepairUsecase(jobs repository.JobRepository, locks repository.LockStore, pub publisher.Publisher, logger *zap.Logger) *RepairUsecase {
+	return &RepairUsecase{
+		jobs:      jobs,
+		locks:     locks,
+		publisher: pub,
+		logger:    logger,
+	}
+}
+
+// Repair runs the requested actions in domain.RepairActions order. A dry
+// run reports each action against the current state, so jobs a real run
+// would first free with purge_locks still show as skipped_locked.
+func (uc *RepairUsecase) Repair(ctx context.Context, req *domain.RepairRequest) (*domain.RepairReport, error) {
+	actions, err := domain.ParseRepairActions(req.Actions)
+	if err != nil {
+		return nil, err
+	}
+	olderThan := defaultRepairOlderThan
+	if req.OlderThanSeconds != 0 {
+		olderThan = time.Duration(req.OlderThanSeconds) * time.Second
+	}
+	if olderThan < minRepairOlderThan {
+		return nil, fmt.Errorf("%w: older_than_seconds must be at least %d", domain.ErrInvalidRepair, int(minRepairOlderThan.Seconds()))
+	}
+	limit := defaultRepairLimit
+	if req.Limit != 0 {
+		limit = req.Limit
+	}
+	if limit < 1 || limit > maxRepairLimit {
+		return nil, fmt.Errorf("%w: limit must be 1-%d", domain.ErrInvalidRepair, maxRepairLimit)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	report := &domain.RepairReport{
+		DryRun:           req.DryRun,
+		OlderThanSeconds: int(olderThan.Seconds()),
+	}
+	for _, action := range actions {
+		var ar *domain.RepairActionReport
+		switch action {
+		case domain.RepairPurgeLocks:
+			ar, err = uc.purgeLocks(ctx, cutoff, limit, req.DryRun)
+		case domain.RepairReconcileRunning:
+			ar, err = uc.requeue(ctx, action, []domain.ExecutionStatus{domain.StatusCompiling, domain.StatusRunning}, cutoff, limit, req.DryRun)
+		case domain.RepairRequeueStuck:
+			ar, err = uc.requeue(ctx, action, []domain.ExecutionStatus{domain.StatusQueued}, cutoff, limit, req.DryRun)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", action, err)
+		}
+		uc.logger.Info("Repair action finished",
+			zap.String("action", string(action)),
+			zap.Bool("dry_run", req.DryRun),
+			zap.Int("matched", ar.Matched),
+			zap.Int("changed", ar.Changed),
+		)
+		report.Actions = append(report.Actions, *ar)
+	}
+	return report, nil
+}
+
+// purgeLocks deletes the processing locks of unfinished jobs that were
+// taken before the cutoff. Jobs without a lock are not reported.
+func (uc *RepairUsecase) purgeLocks(ctx context.Context, cutoff time.Time, limit int, dryRun bool) (*domain.RepairActionReport, error) {
+	jobs, err := uc.jobs.ListStale(ctx, []domain.ExecutionStatus{domain.StatusQueued, domain.StatusCompiling, domain.StatusRunning}, cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	ar := &domain.RepairActionReport{Action: domain.RepairPurgeLocks, Jobs: []domain.RepairedJob{}}
+	for _, job := range jobs {
+		acquiredAt, held, err := uc.locks.AcquiredAt(ctx, job.LockID())
+		if err != nil {
+			return nil, err
+		}
+		if !held || !acquiredAt.Before(cutoff) {
+			continue
+		}
+		entry := domain.RepairedJob{JobID: job.JobID, Status: job.Status, Outcome: domain.RepairOutcomeWouldPurge}
+		if !dryRun {
+			deleted, err := uc.locks.DeleteIfAcquiredAt(ctx, job.LockID(), acquiredAt)
+			if err != nil {
+				return nil, err
+			}
+			entry.Outcome = domain.RepairOutcomeChanged
+			if deleted {
+				entry.Outcome = domain.RepairOutcomePurged
+				ar.Changed++
+			}
+		}
+		ar.Jobs = append(ar.Jobs, entry)
+	}
+	ar.Matched = len(ar.Jobs)
+	return ar, nil
+}
+
+// requeue resets stale jobs in statuses to QUEUED and publishes them again.
+// Jobs whose lock is still held are skipped: a worker may be running them,
+// and the lock would make it drop the new message as a duplicate.
+func (uc *RepairUsecase) requeue(ctx context.Context, action domain.RepairAction, statuses []domain.ExecutionStatus, cutoff time.Time, limit int, dryRun bool) (*domain.RepairActionReport, error) {
+	jobs, err := uc.jobs.ListStale(ctx, statuses, cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	ar := &domain.RepairActionReport{Action: action, Matched: len(jobs), Jobs: make([]domain.RepairedJob, 0, len(jobs))}
+	for _, job := range jobs {
+		entry := domain.RepairedJob{JobID: job.JobID, Status: job.Status}
+		_, held, err := uc.locks.AcquiredAt(ctx, job.LockID())
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case held:
+			entry.Outcome = domain.RepairOutcomeLocked
+		case dryRun:
+			entry.Outcome = domain.RepairOutcomeWouldRequeue
+		default:
+			if entry.Outcome, err = uc.requeueJob(ctx, job, cutoff); err != nil {
+				return nil, err
+			}
+			if entry.Outcome == domain.RepairOutcomeRequeued {
+				ar.Changed++
+			}
+		}
+		ar.Jobs = append(ar.Jobs, entry)
+	}
+	return ar, nil
+}
+
+// requeueJob resets one job and publishes it. A job that fails to publish
+// stays QUEUED, so a later repair picks it up again.
+func (uc *RepairUsecase) requeueJob(ctx context.Context, job *domain.Job, cutoff time.Time) (domain.RepairOutcome, error) {
+	reset, ok, err := uc.jobs.ResetStale(ctx, job.JobID, job.Status, cutoff)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return domain.RepairOutcomeChanged, nil
+	}
+	if err := uc.publisher.Publish(ctx, reset); err != nil {
+		uc.logger.Error("Failed to publish repaired job", zap.Error(err), zap.String("job_id", job.JobID.String()))
+		return domain.RepairOutcomePublishFailed, nil
+	}
+	return domain.RepairOutcomeRequeued, nil
+}
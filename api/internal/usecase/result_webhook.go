@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+	"github.com/Harsh-BH/Sentinel/api/internal/webhook"
+)
+
+const (
+	// deliveryBatch caps the deliveries claimed at once.
+	deliveryBatch = 20
+
+	// maxDeliveryBackoff caps the wait between two attempts of a delivery.
+	maxDeliveryBackoff = 6 * time.Hour
+)
+
+// DeliveryPolicy governs how result webhook deliveries are retried.
+type DeliveryPolicy struct {
+	// MaxAttempts is how often a delivery is tried before it fails.
+	MaxAttempts int
+	// Backoff is the wait after a delivery's first failed attempt; it
+	// doubles after each further one, up to maxDeliveryBackoff.
+	Backoff time.Duration
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+	// BreakerThreshold consecutive failed attempts to one endpoint open its
+	// circuit for BreakerCooldown, holding back all of its deliveries. The
+	// first attempt after that is a probe: another failure opens the
+	// circuit again at once.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// backoff returns the wait after a delivery's attempts-th failed attempt.
+func (p DeliveryPolicy) backoff(attempts int) time.Duration {
+	wait := p.Backoff
+	for i := 1; i < attempts && wait < maxDeliveryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxDeliveryBackoff)
+}
+
+// ResultDispatcher sends the deliveries recorded for tenants' result
+// webhooks, recording every attempt. Every API replica may run one: each due
+// delivery is claimed by one of them at a time. A delivery may still arrive
+// more than once, if a replica stops between sending and recording it.
+type ResultDispatcher struct {
+	webhooks repository.WebhookRepository
+	jobs     repository.JobRepository
+	sender   webhook.Sender
+	policy   DeliveryPolicy
+	logger   *zap.Logger
+}
+
+// NewResultDispatcher creates a new ResultDispatcher.
+func NewResultDispatcher(webhooks repository.WebhookRepository, jobs repository.JobRepository, sender webhook.Sender, policy DeliveryPolicy, logger *zap.Logger) *ResultDispatcher {
+	return &ResultDispatcher{
+		webhooks: webhooks,
+		jobs:     jobs,
+		sender:   sender,
+		policy:   policy,
+		logger:   logger,
+	}
+}
+
+// Run sends due deliveries every interval until ctx is done.
+func (d *ResultDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Tick(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				d.logger.Warn("Sending result webhook deliveries failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tick sends every delivery due by now and returns how many were accepted.
+// Deliveries are sent one at a time, so an endpoint sees its tenant's
+// results in the order they finished.
+func (d *ResultDispatcher) Tick(ctx context.Context, now time.Time) (int, error) {
+	// A claim outlasts the slowest batch, so that another replica does not
+	// send the same deliveries meanwhile.
+	lease := deliveryBatch*d.policy.Timeout + time.Minute
+	start := time.Now()
+	delivered := 0
+	for {
+		deliveries, err := d.webhooks.ClaimDueDeliveries(ctx, now, lease, deliveryBatch)
+		if err != nil {
+			return delivered, fmt.Errorf("claim due deliveries: %w", err)
+		}
+		hooks := make(map[string]*domain.ResultWebhook)
+		for _, delivery := range deliveries {
+			hook, ok := hooks[delivery.TenantID]
+			if !ok {
+				hook, err = d.webhooks.GetResultWebhook(ctx, delivery.TenantID)
+				if errors.Is(err, domain.ErrWebhookNotFound) {
+					// Removed since the claim, which failed the delivery.
+					continue
+				}
+				if err != nil {
+					return delivered, err
+				}
+				hooks[delivery.TenantID] = hook
+			}
+			ok, err := d.send(ctx, delivery, hook, now.Add(time.Since(start)).UTC())
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				delivered++
+			}
+		}
+		if len(deliveries) < deliveryBatch {
+			break
+		}
+	}
+	return delivered, nil
+}
+
+// send makes one attempt of delivery at start, or defers it while hook's
+// circuit is open, and records the outcome on both. It reports whether the
+// endpoint accepted the delivery.
+func (d *ResultDispatcher) send(ctx context.Context, delivery *domain.WebhookDelivery, hook *domain.ResultWebhook, start time.Time) (bool, error) {
+	log := d.logger.With(zap.Int64("delivery_id", delivery.ID), zap.String("tenant_id", delivery.TenantID))
+
+	if hook.CircuitOpenUntil != nil && hook.CircuitOpenUntil.After(start) {
+		// Opened by an earlier delivery of this batch.
+		if err := d.webhooks.DeferDelivery(ctx, delivery.ID, *hook.CircuitOpenUntil); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	job, err := d.jobs.GetByID(ctx, delivery.JobID)
+	if err != nil {
+		return false, fmt.Errorf("get job %s: %w", delivery.JobID, err)
+	}
+	body, err := json.Marshal(&domain.ResultEvent{Event: domain.ResultEventType, DeliveryID: delivery.ID, Job: job})
+	if err != nil {
+		return false, fmt.Errorf("encode result event: %w", err)
+	}
+
+	sent := time.Now()
+	code, sendErr := d.sender.Send(ctx, hook.URL, hook.Secret, delivery.ID, body)
+	attempt := &domain.WebhookAttempt{
+		URL:         hook.URL,
+		StatusCode:  code,
+		DurationMs:  time.Since(sent).Milliseconds(),
+		AttemptedAt: start,
+	}
+	delivery.Attempts++
+
+	if sendErr == nil {
+		delivery.Status = domain.DeliveryDelivered
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		delivery.DeliveredAt = &start
+		hook.ConsecutiveFailures = 0
+		hook.CircuitOpenUntil = nil
+		metrics.ResultWebhookAttempts.WithLabelValues("delivered").Inc()
+	} else {
+		attempt.Error = sendErr.Error()
+		delivery.LastError = attempt.Error
+		hook.ConsecutiveFailures++
+		if hook.ConsecutiveFailures >= d.policy.BreakerThreshold {
+			until := start.Add(d.policy.BreakerCooldown)
+			hook.CircuitOpenUntil = &until
+			metrics.ResultWebhookCircuitOpens.Inc()
+			log.Warn("Result webhook circuit opened",
+				zap.Int("consecutive_failures", hook.ConsecutiveFailures),
+				zap.Time("until", until),
+			)
+		}
+		if delivery.Attempts >= d.policy.MaxAttempts || refused(code) {
+			delivery.Status = domain.DeliveryFailed
+			delivery.NextAttemptAt = nil
+			metrics.ResultWebhookAttempts.WithLabelValues("failed").Inc()
+			log.Warn("Result webhook delivery failed", zap.Error(sendErr), zap.Int("attempts", delivery.Attempts))
+		} else {
+			next := start.Add(d.policy.backoff(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+			metrics.ResultWebhookAttempts.WithLabelValues("retry").Inc()
+		}
+	}
+
+	if err := d.webhooks.RecordDeliveryAttempt(ctx, delivery, attempt, hook); err != nil {
+		return false, err
+	}
+	return sendErr == nil, nil
+}
+
+// refused reports whether a response rejects the delivery for good: a 4xx
+// other than a timeout or rate limit will not change on retry.
+func refused(code int) bool {
+	return code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
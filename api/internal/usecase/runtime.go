@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	maxRuntimeFieldLen = 128
+	maxRuntimeMounts   = 16
+
+	// sandboxWorkDir is where the worker mounts a job's files; runtime mounts
+	// must stay out of it.
+	sandboxWorkDir = "/tmp/work"
+)
+
+var (
+	runtimeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_+-]{0,31}$`)
+	envNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// RuntimeUsecase manages runtimes registered through the API, which
+// submissions can name as their language alongside the built-in registry.
+type RuntimeUsecase struct {
+	runtimes   repository.RuntimeRepository
+	languages  *language.Registry
+	mountRoots []string
+	logger     *zap.Logger
+}
+
+// NewRuntimeUsecase creates a new RuntimeUsecase. Runtimes may not reuse the
+// name of a language in the registry.
+func NewRuntimeUsecase(runtimes repository.RuntimeRepository, languages *language.Registry, logger *zap.Logger) *RuntimeUsecase {
+	return &RuntimeUsecase{
+		runtimes:  runtimes,
+		languages: languages,
+		logger:    logger,
+	}
+}
+
+// SetMountRoots sets the worker host directories runtimes may mount, at or
+// below. Without any, runtimes may mount nothing.
+func (uc *RuntimeUsecase) SetMountRoots(roots []string) {
+	uc.mountRoots = nil
+	for _, root := range roots {
+		if root != "" {
+			uc.mountRoots = append(uc.mountRoots, path.Clean(root))
+		}
+	}
+}
+
+// Create validates and registers a runtime.
+func (uc *RuntimeUsecase) Create(ctx context.Context, req *domain.CreateRuntimeRequest) (*domain.Runtime, error) {
+	if !runtimeNamePattern.MatchString(string(req.Name)) {
+		return nil, fmt.Errorf("%w: name must be 1-32 lowercase letters, digits, '_', '+' or '-', starting with a letter", domain.ErrInvalidRuntime)
+	}
+	if uc.languages.IsSupported(req.Name) {
+		return nil, fmt.Errorf("%w: %s is a built-in language", domain.ErrInvalidRuntime, req.Name)
+	}
+	if err := uc.validateSpec(&req.RuntimeSpec); err != nil {
+		return nil, err
+	}
+
+	rt := &domain.Runtime{Name: req.Name, RuntimeSpec: req.RuntimeSpec}
+	if err := uc.runtimes.Create(ctx, rt); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Runtime registered", zap.String("runtime", string(rt.Name)))
+	return rt, nil
+}
+
+// Get returns a runtime by name.
+func (uc *RuntimeUsecase) Get(ctx context.Context, name domain.Language) (*domain.Runtime, error) {
+	return uc.runtimes.GetByName(ctx, name)
+}
+
+// List returns every registered runtime.
+func (uc *RuntimeUsecase) List(ctx context.Context) ([]*domain.Runtime, error) {
+	return uc.runtimes.List(ctx)
+}
+
+// Update replaces a runtime's definition. Queued submissions pick up the new
+// definition when a worker runs them.
+func (uc *RuntimeUsecase) Update(ctx context.Context, name domain.Language, spec *domain.RuntimeSpec) (*domain.Runtime, error) {
+	if err := uc.validateSpec(spec); err != nil {
+		return nil, err
+	}
+	rt := &domain.Runtime{Name: name, RuntimeSpec: *spec}
+	if err := uc.runtimes.Update(ctx, rt); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Runtime updated", zap.String("runtime", string(name)))
+	return rt, nil
+}
+
+// Delete removes a runtime. Queued submissions that name it fail with an
+// internal error when a worker picks them up.
+func (uc *RuntimeUsecase) Delete(ctx context.Context, name domain.Language) error {
+	if err := uc.runtimes.Delete(ctx, name); err != nil {
+		return err
+	}
+	uc.logger.Info("Runtime deleted", zap.String("runtime", string(name)))
+	return nil
+}
+
+// validateRuntimeSpec applies the checks the worker's language registry
+// makes, plus size limits, so a bad definition is rejected at registration
+// rather than failing every submission that uses it.
+func validateRuntimeSpec(spec *domain.RuntimeSpec) error {
+	switch {
+	case len(spec.Version) > maxRuntimeFieldLen:
+		return fmt.Errorf("%w: version must be at most %d bytes", domain.ErrInvalidRuntime, maxRuntimeFieldLen)
+	case !isPlainFileName(spec.SourceFile) || spec.SourceFile == "stdin.txt" || spec.SourceFile == "program":
+		return fmt.Errorf("%w: source_file must be a plain file name other than stdin.txt and program", domain.ErrInvalidRuntime)
+	case !isPlainFileName(spec.NsjailConfig):
+		return fmt.Errorf("%w: nsjail_config must be a file name in the sandbox config dir", domain.ErrInvalidRuntime)
+	case len(spec.Run) == 0:
+		return fmt.Errorf("%w: run command is required", domain.ErrInvalidRuntime)
+	case len(spec.Mounts) > maxRuntimeMounts:
+		return fmt.Errorf("%w: at most %d mounts", domain.ErrInvalidRuntime, maxRuntimeMounts)
+	case len(spec.Env) > maxEnvVars:
+		return fmt.Errorf("%w: at most %d environment variables", domain.ErrInvalidRuntime, maxEnvVars)
+	}
+	for _, cmd := range [][]string{spec.Compile, spec.Run} {
+		if len(cmd) > maxArgs {
+			return fmt.Errorf("%w: commands take at most %d arguments", domain.ErrInvalidRuntime, maxArgs)
+		}
+		for _, arg := range cmd {
+			if len(arg) > maxArgLen || strings.ContainsRune(arg, 0) {
+				return fmt.Errorf("%w: command arguments must be at most %d bytes without NUL", domain.ErrInvalidRuntime, maxArgLen)
+			}
+		}
+	}
+	for _, m := range spec.Mounts {
+		if !isCleanAbsPath(m.Source) || !isCleanAbsPath(m.Target) || m.Target == "/" {
+			return fmt.Errorf("%w: mount paths must be clean absolute paths, got %q -> %q", domain.ErrInvalidRuntime, m.Source, m.Target)
+		}
+		if m.Target == sandboxWorkDir || strings.HasPrefix(m.Target, sandboxWorkDir+"/") {
+			return fmt.Errorf("%w: mounts may not target %s", domain.ErrInvalidRuntime, sandboxWorkDir)
+		}
+	}
+	for name, value := range spec.Env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: invalid environment variable name %q", domain.ErrInvalidRuntime, name)
+		}
+		if len(value) > maxEnvValueLen || strings.ContainsRune(value, 0) {
+			return fmt.Errorf("%w: %s must be at most %d bytes without NUL", domain.ErrInvalidRuntime, name, maxEnvValueLen)
+		}
+	}
+	return nil
+}
+
+// validateSpec checks spec with validateRuntimeSpec and keeps its mounts
+// under the mount roots, so a runtime cannot expose the rest of the worker
+// host to submissions.
+func (uc *RuntimeUsecase) validateSpec(spec *domain.RuntimeSpec) error {
+	if err := validateRuntimeSpec(spec); err != nil {
+		return err
+	}
+	for _, m := range spec.Mounts {
+		if !uc.mountable(m.Source) {
+			return fmt.Errorf("%w: mount source %q is outside the runtime mount roots", domain.ErrInvalidRuntime, m.Source)
+		}
+	}
+	return nil
+}
+
+func (uc *RuntimeUsecase) mountable(source string) bool {
+	for _, root := range uc.mountRoots {
+		if root == "/" || source == root || strings.HasPrefix(source, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isPlainFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && len(name) <= maxRuntimeFieldLen &&
+		!strings.ContainsAny(name, "/\\\x00")
+}
+
+func isCleanAbsPath(p string) bool {
+	return len(p) <= maxArgLen && path.IsAbs(p) && path.Clean(p) == p && !strings.ContainsAny(p, ":\x00")
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/cron"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	maxScheduleNameLen = 200
+	// scheduleBatch caps the due schedules run at once.
+	scheduleBatch = 100
+)
+
+// ScheduleUsecase manages tenants' recurring schedules and runs them: each
+// time a schedule's cron expression fires, a submission is made from its
+// job template. Every API replica may run schedules; each run is claimed
+// by exactly one of them.
+type ScheduleUsecase struct {
+	schedules repository.ScheduleRepository
+	submit    *SubmitJobUsecase
+	logger    *zap.Logger
+}
+
+// NewScheduleUsecase creates a new ScheduleUsecase.
+func NewScheduleUsecase(schedules repository.ScheduleRepository, submit *SubmitJobUsecase, logger *zap.Logger) *ScheduleUsecase {
+	return &ScheduleUsecase{
+		schedules: schedules,
+		submit:    submit,
+		logger:    logger,
+	}
+}
+
+// Create validates and stores a schedule for a tenant. The job template is
+// checked as a submission would be, without counting against the quota.
+func (uc *ScheduleUsecase) Create(ctx context.Context, tenantID string, req *domain.CreateScheduleRequest) (*domain.Schedule, error) {
+	if len(req.Name) > maxScheduleNameLen {
+		return nil, fmt.Errorf("%w: name longer than %d bytes", domain.ErrInvalidSchedule, maxScheduleNameLen)
+	}
+	spec, err := cron.Parse(req.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSchedule, err)
+	}
+	now := time.Now().UTC()
+	next := spec.Next(now)
+	if next.IsZero() {
+		return nil, fmt.Errorf("%w: cron expression %q never fires", domain.ErrInvalidSchedule, req.Cron)
+	}
+
+	// Runs are made unattended: nobody is connected to feed an
+	// interactive job, and the schedule itself says when to run.
+	if req.Job.Interactive {
+		return nil, fmt.Errorf("%w: job cannot be interactive", domain.ErrInvalidSchedule)
+	}
+	if req.Job.RunAt != nil {
+		return nil, fmt.Errorf("%w: job cannot have a run_at", domain.ErrInvalidSchedule)
+	}
+	// Every run would claim the same external ID.
+	if req.Job.ExternalID != "" {
+		return nil, fmt.Errorf("%w: job cannot have an external_id", domain.ErrInvalidSchedule)
+	}
+	tenantID = tenantOrDefault(tenantID)
+	template := req.Job
+	template.TenantID = tenantID
+	if err := uc.submit.Check(ctx, &template); err != nil {
+		return nil, fmt.Errorf("%w: job: %w", domain.ErrInvalidSchedule, err)
+	}
+	template.TenantID = ""
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate UUIDv7: %w", err)
+	}
+	s := &domain.Schedule{
+		ScheduleID: id,
+		TenantID:   tenantID,
+		Name:       req.Name,
+		Cron:       req.Cron,
+		Job:        template,
+		NextRunAt:  next,
+	}
+	if err := uc.schedules.Create(ctx, s); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Schedule created",
+		zap.String("schedule_id", id.String()),
+		zap.String("tenant_id", tenantID),
+		zap.String("cron", req.Cron),
+	)
+	return s, nil
+}
+
+// Get returns a tenant's schedule.
+func (uc *ScheduleUsecase) Get(ctx context.Context, tenantID string, id uuid.UUID) (*domain.Schedule, error) {
+	return uc.schedules.Get(ctx, tenantOrDefault(tenantID), id)
+}
+
+// List returns a tenant's schedules, oldest first.
+func (uc *ScheduleUsecase) List(ctx context.Context, tenantID string) ([]*domain.Schedule, error) {
+	return uc.schedules.List(ctx, tenantOrDefault(tenantID))
+}
+
+// Delete stops and removes a tenant's schedule. Jobs it created are kept.
+func (uc *ScheduleUsecase) Delete(ctx context.Context, tenantID string, id uuid.UUID) error {
+	tenantID = tenantOrDefault(tenantID)
+	if err := uc.schedules.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+	uc.logger.Info("Schedule deleted", zap.String("schedule_id", id.String()), zap.String("tenant_id", tenantID))
+	return nil
+}
+
+// Run runs due schedules every interval until ctx is done.
+func (uc *ScheduleUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := uc.Tick(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				uc.logger.Warn("Running schedules failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tick runs every schedule due by now and returns how many it ran. A run
+// missed while no replica was polling is made once, late, and the schedule
+// then continues from now. A run whose submission is rejected, for example
+// by the tenant's quota, records the error and is not retried.
+func (uc *ScheduleUsecase) Tick(ctx context.Context, now time.Time) (int, error) {
+	ran := 0
+	for {
+		due, err := uc.schedules.ListDue(ctx, now, scheduleBatch)
+		if err != nil {
+			return ran, fmt.Errorf("list due schedules: %w", err)
+		}
+		claimed := 0
+		for _, s := range due {
+			ok, err := uc.runOnce(ctx, s, now)
+			if err != nil {
+				return ran, err
+			}
+			if ok {
+				claimed++
+				ran++
+			}
+		}
+		// Stop once no more are due, or when other replicas claimed the
+		// whole batch and may still be running it.
+		if len(due) < scheduleBatch || claimed == 0 {
+			break
+		}
+	}
+	if ran > 0 {
+		uc.logger.Info("Schedules run", zap.Int("schedules", ran))
+	}
+	return ran, nil
+}
+
+// runOnce claims the schedule's due run and makes its submission,
+// reporting false if another caller claimed it first.
+func (uc *ScheduleUsecase) runOnce(ctx context.Context, s *domain.Schedule, now time.Time) (bool, error) {
+	log := uc.logger.With(zap.String("schedule_id", s.ScheduleID.String()), zap.String("tenant_id", s.TenantID))
+	spec, err := cron.Parse(s.Cron)
+	if err != nil {
+		log.Error("Stored schedule has an invalid cron expression", zap.Error(err))
+		return false, nil
+	}
+	next := spec.Next(now)
+	if next.IsZero() {
+		log.Error("Stored schedule never fires again", zap.String("cron", s.Cron))
+		return false, nil
+	}
+	ok, err := uc.schedules.Advance(ctx, s.ScheduleID, s.NextRunAt, next)
+	if err != nil {
+		return false, fmt.Errorf("advance schedule: %w", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	req := s.Job
+	req.TenantID = s.TenantID
+	req.Origin = domain.OriginSchedule
+	run := &domain.ScheduleRun{At: now}
+	resp, err := uc.submit.Execute(ctx, &req)
+	switch {
+	case err == nil:
+		run.JobID = &resp.JobID
+		log.Info("Schedule ran", zap.String("job_id", resp.JobID.String()), zap.Time("next_run_at", next))
+	case errors.Is(err, context.Canceled):
+		return false, err
+	default:
+		run.Error = err.Error()
+		log.Warn("Schedule run rejected", zap.Error(err), zap.Time("next_run_at", next))
+	}
+	if err := uc.schedules.RecordRun(ctx, s.ScheduleID, run); err != nil {
+		log.Error("Failed to record schedule run", zap.Error(err))
+	}
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// schedulerBatch caps the jobs claimed at once.
+const schedulerBatch = 100
+
+// Scheduler queues SCHEDULED jobs once their run_at arrives. Every API
+// replica may run one: each due job is claimed by exactly one of them.
+type Scheduler struct {
+	jobs      repository.JobRepository
+	publisher publisher.Publisher
+	logger    *zap.Logger
+}
+
+// NewScheduler creates a new Scheduler.
+func NewScheduler(jobs repository.JobRepository, pub publisher.Publisher, logger *zap.Logger) *Scheduler {
+	return &Scheduler{jobs: jobs, publisher: pub, logger: logger}
+}
+
+// Run queues due jobs every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Tick(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				s.logger.Warn("Queueing scheduled jobs failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Tick publishes every job due by now and returns how many were queued. A
+// job whose publish fails goes back to SCHEDULED and is retried on the next
+// tick; the rest of the batch is still published.
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) (int, error) {
+	queued := 0
+	for {
+		jobs, err := s.jobs.ClaimDue(ctx, now, schedulerBatch)
+		if err != nil {
+			return queued, fmt.Errorf("claim due jobs: %w", err)
+		}
+		failed := false
+		for _, job := range jobs {
+			if err := s.publisher.Publish(ctx, job); err != nil {
+				s.logger.Error("Failed to publish scheduled job", zap.Error(err), zap.String("job_id", job.JobID.String()))
+				if err := s.jobs.UpdateStatus(ctx, job.JobID, domain.StatusScheduled); err != nil {
+					s.logger.Error("Failed to reschedule job", zap.Error(err), zap.String("job_id", job.JobID.String()))
+				}
+				failed = true
+				continue
+			}
+			queued++
+		}
+		// Stop after a failure too, or the rescheduled jobs would be
+		// claimed again at once.
+		if len(jobs) < schedulerBatch || failed {
+			break
+		}
+	}
+	if queued > 0 {
+		s.logger.Info("Scheduled jobs queued", zap.Int("jobs", queued))
+	}
+	return queued, nil
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+// SecurityEventUsecase pages through the security events workers record,
+// for SIEM pipelines to collect.
+type SecurityEventUsecase struct {
+	repo   repository.SecurityEventRepository
+	logger *zap.Logger
+}
+
+// NewSecurityEventUsecase creates a new SecurityEventUsecase.
+func NewSecurityEventUsecase(repo repository.SecurityEventRepository, logger *zap.Logger) *SecurityEventUsecase {
+	return &SecurityEventUsecase{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// List returns a page of up to limit events matching filter after cursor,
+// from the first event if cursor is empty. Events as recent as
+// exportSettleDelay are held back like the warehouse export's verdicts, so
+// that a cursor never passes one that commits late.
+func (uc *SecurityEventUsecase) List(ctx context.Context, filter domain.SecurityEventFilter, cursor string, limit int) (*domain.SecurityEventPage, error) {
+	if filter.Kind != "" && !slices.Contains(domain.SecurityEventKinds, filter.Kind) {
+		return nil, fmt.Errorf("%w: unknown kind %q", domain.ErrInvalidSecurityEventFilter, filter.Kind)
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 1 || limit > maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be 1-%d", domain.ErrInvalidSecurityEventFilter, maxListLimit)
+	}
+	var after int64
+	if cursor != "" {
+		var err error
+		if after, err = strconv.ParseInt(cursor, 10, 64); err != nil || after < 0 {
+			return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidSecurityEventFilter)
+		}
+	}
+
+	// One event more than the page tells whether there is more to fetch.
+	events, err := uc.repo.List(ctx, filter, after, time.Now().Add(-exportSettleDelay), limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list security events: %w", err)
+	}
+	page := &domain.SecurityEventPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.HasMore = true
+	}
+	if page.Events == nil {
+		page.Events = []*domain.SecurityEvent{}
+	}
+	if n := len(page.Events); n > 0 {
+		after = page.Events[n-1].EventID
+	}
+	page.NextCursor = strconv.FormatInt(after, 10)
+	return page, nil
+}
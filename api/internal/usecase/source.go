@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+// utf8BOM is stripped from the start of sources, where editors on Windows
+// put it and some compilers and interpreters reject it.
+const utf8BOM = "\uFEFF"
+
+// SourcePolicy configures the optional clean-up of submitted sources before
+// they are stored: the BOM is stripped, line endings become "\n", and NUL
+// bytes and oversized sources are rejected. Zero limits are not enforced.
+type SourcePolicy struct {
+	MaxLines      int
+	MaxLineLength int
+}
+
+// SetSourcePolicy enables source normalization for submissions.
+func (uc *SubmitJobUsecase) SetSourcePolicy(policy SourcePolicy) {
+	uc.sourcePolicy = &policy
+}
+
+// Normalize returns src cleaned up, or an error wrapping
+// domain.ErrInvalidSource that names the first offending line.
+func (p *SourcePolicy) Normalize(src string) (string, error) {
+	if i := strings.IndexByte(src, 0); i >= 0 {
+		return "", fmt.Errorf("%w: NUL byte on line %d", domain.ErrInvalidSource, strings.Count(src[:i], "\n")+1)
+	}
+	src = strings.TrimPrefix(src, utf8BOM)
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	if p.MaxLines > 0 && len(lines) > p.MaxLines {
+		return "", fmt.Errorf("%w: %d lines exceeds the limit of %d", domain.ErrInvalidSource, len(lines), p.MaxLines)
+	}
+	if p.MaxLineLength > 0 {
+		for i, line := range lines {
+			if len(line) > p.MaxLineLength {
+				return "", fmt.Errorf("%w: line %d is %d bytes, the limit is %d", domain.ErrInvalidSource, i+1, len(line), p.MaxLineLength)
+			}
+		}
+	}
+	return src, nil
+}
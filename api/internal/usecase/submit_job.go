@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
 	"github.com/Harsh-BH/Sentinel/api/internal/publisher"
 	"github.com/Harsh-BH/Sentinel/api/internal/repository"
 )
@@ -18,73 +20,156 @@ const (
 	maxSourceCodeSize    = 1 << 20 // 1 MB
 	defaultTimeLimitMs   = 5000
 	defaultMemoryLimitKB = 262144 // 256 MB
+	maxTimeLimitMs       = language.MaxTimeLimitMs
+	maxMemoryLimitKB     = language.MaxMemoryLimitKB
+	maxPidsLimit         = 1024
+	maxCompilerFlags     = 8
+	maxCompilerFlagLen   = 32
+	maxArgs              = 32
+	maxArgLen            = 256
+	maxEnvVars           = 16
+	maxEnvValueLen       = 256
+	maxOutputFiles       = 8
+	maxOutputFileLen     = 128
+	maxExpectedOutput    = 1 << 20 // 1 MB
+	maxExternalIDLen     = 255
+
+	// maxScheduleAhead caps how far ahead run_at may be.
+	maxScheduleAhead = 30 * 24 * time.Hour
 )
 
 // SubmitJobUsecase handles the business logic for submitting code execution jobs.
 type SubmitJobUsecase struct {
 	repo      repository.JobRepository
 	publisher publisher.Publisher
+	languages *language.Registry
 	logger    *zap.Logger
+
+	// Optional per-tenant daily execution budget; disabled when quota is nil.
+	quota       repository.QuotaStore
+	dailyBudget time.Duration
+
+	// Optional problem lookup; submissions with a problem_id are rejected when nil.
+	problems repository.ProblemRepository
+
+	// Optional runtime lookup for languages outside the registry.
+	runtimes repository.RuntimeRepository
+
+	// Optional source clean-up; sources are stored as sent when nil.
+	sourcePolicy *SourcePolicy
+
+	// Sandbox tiers submissions may request; any tier is rejected when empty.
+	sandboxTiers map[string]bool
+
+	// Whether submissions may ask for the allowlist network policy.
+	networkAllowlist bool
+
+	// Whether submissions may ask for interactive execution.
+	interactive bool
+
+	// Whether submissions may set a run_at.
+	scheduling bool
 }
 
 // NewSubmitJobUsecase creates a new SubmitJobUsecase.
-func NewSubmitJobUsecase(repo repository.JobRepository, pub publisher.Publisher, logger *zap.Logger) *SubmitJobUsecase {
+func NewSubmitJobUsecase(repo repository.JobRepository, pub publisher.Publisher, languages *language.Registry, logger *zap.Logger) *SubmitJobUsecase {
 	return &SubmitJobUsecase{
 		repo:      repo,
 		publisher: pub,
+		languages: languages,
 		logger:    logger,
 	}
 }
 
-// Execute validates the submission, creates a job, publishes it, and returns the job ID.
-func (uc *SubmitJobUsecase) Execute(ctx context.Context, req *domain.SubmitRequest) (*domain.SubmitResponse, error) {
-	// Validate language
-	if !req.Language.IsValid() {
-		return nil, domain.ErrInvalidLanguage
-	}
+// SetQuota enables per-tenant daily execution budgets. A zero budget disables the check.
+func (uc *SubmitJobUsecase) SetQuota(quota repository.QuotaStore, dailyBudget time.Duration) {
+	uc.quota = quota
+	uc.dailyBudget = dailyBudget
+}
 
-	// Validate source code
-	if strings.TrimSpace(req.SourceCode) == "" {
-		return nil, domain.ErrEmptySourceCode
-	}
-	if len(req.SourceCode) > maxSourceCodeSize {
-		return nil, domain.ErrPayloadTooLarge
-	}
+// SetProblems enables judged submissions against a problem's test data.
+func (uc *SubmitJobUsecase) SetProblems(problems repository.ProblemRepository) {
+	uc.problems = problems
+}
 
-	// Apply defaults
-	timeLimitMs := defaultTimeLimitMs
-	if req.TimeLimitMs != nil && *req.TimeLimitMs > 0 && *req.TimeLimitMs <= 30000 {
-		timeLimitMs = *req.TimeLimitMs
-	}
-	memoryLimitKB := defaultMemoryLimitKB
-	if req.MemoryLimitKB != nil && *req.MemoryLimitKB > 0 && *req.MemoryLimitKB <= 524288 {
-		memoryLimitKB = *req.MemoryLimitKB
+// SetRuntimes lets submissions name a runtime registered through the API as
+// their language.
+func (uc *SubmitJobUsecase) SetRuntimes(runtimes repository.RuntimeRepository) {
+	uc.runtimes = runtimes
+}
+
+// SetSandboxTiers lists the sandbox tiers submissions may request.
+func (uc *SubmitJobUsecase) SetSandboxTiers(tiers []string) {
+	uc.sandboxTiers = make(map[string]bool, len(tiers))
+	for _, t := range tiers {
+		uc.sandboxTiers[t] = true
 	}
+}
 
-	// Generate UUIDv7 (time-ordered)
-	jobID, err := uuid.NewV7()
+// SetNetworkAllowlist lets submissions ask for network_policy "allowlist".
+// Workers must be configured with an allowlist to run such jobs.
+func (uc *SubmitJobUsecase) SetNetworkAllowlist(enabled bool) {
+	uc.networkAllowlist = enabled
+}
+
+// SetInteractive lets submissions ask for interactive execution. Interactive
+// jobs hold a worker until they end or hit their time limit, however long
+// the client takes to send input.
+func (uc *SubmitJobUsecase) SetInteractive(enabled bool) {
+	uc.interactive = enabled
+}
+
+// SetScheduling lets submissions set a run_at. A Scheduler must then be
+// running to queue them.
+func (uc *SubmitJobUsecase) SetScheduling(enabled bool) {
+	uc.scheduling = enabled
+}
+
+// Execute validates the submission, creates a job, publishes it, and returns the job ID.
+func (uc *SubmitJobUsecase) Execute(ctx context.Context, req *domain.SubmitRequest) (*domain.SubmitResponse, error) {
+	job, violation, err := uc.build(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("generate UUIDv7: %w", err)
+		return nil, err
 	}
-
-	job := &domain.Job{
-		JobID:         jobID,
-		Language:      req.Language,
-		SourceCode:    req.SourceCode,
-		Stdin:         req.Stdin,
-		Status:        domain.StatusQueued,
-		TimeLimitMs:   timeLimitMs,
-		MemoryLimitKB: memoryLimitKB,
-		CreatedAt:     time.Now().UTC(),
-		UpdatedAt:     time.Now().UTC(),
+	jobID := job.JobID
+	if err := uc.checkQuota(ctx, job.TenantID); err != nil {
+		return nil, err
 	}
 
 	// Persist to PostgreSQL
 	if err := uc.repo.Create(ctx, job); err != nil {
+		if errors.Is(err, domain.ErrExternalIDExists) {
+			return nil, err
+		}
 		uc.logger.Error("Failed to create job in database", zap.Error(err), zap.String("job_id", jobID.String()))
 		return nil, fmt.Errorf("create job: %w", err)
 	}
 
+	if violation != "" {
+		if err := uc.repo.SetResult(ctx, jobID, &domain.Job{Status: domain.StatusRuleViolation, Stderr: violation}); err != nil {
+			uc.logger.Error("Failed to record rule violation", zap.Error(err), zap.String("job_id", jobID.String()))
+		}
+		uc.logger.Info("Job rejected by problem rule",
+			zap.String("job_id", jobID.String()),
+			zap.String("problem_id", req.ProblemID),
+		)
+		return &domain.SubmitResponse{
+			JobID:  jobID,
+			Status: string(domain.StatusRuleViolation),
+		}, nil
+	}
+
+	if job.RunAt != nil {
+		uc.logger.Info("Job scheduled",
+			zap.String("job_id", jobID.String()),
+			zap.Time("run_at", *job.RunAt),
+		)
+		return &domain.SubmitResponse{
+			JobID:  jobID,
+			Status: string(domain.StatusScheduled),
+		}, nil
+	}
+
 	// Publish to RabbitMQ
 	if err := uc.publisher.Publish(ctx, job); err != nil {
 		uc.logger.Error("Failed to publish job to queue", zap.Error(err), zap.String("job_id", jobID.String()))
@@ -103,3 +188,452 @@ func (uc *SubmitJobUsecase) Execute(ctx context.Context, req *domain.SubmitReque
 		Status: string(domain.StatusQueued),
 	}, nil
 }
+
+// Check validates the submission as Execute does, without creating a job
+// or counting it against the tenant's quota.
+func (uc *SubmitJobUsecase) Check(ctx context.Context, req *domain.SubmitRequest) error {
+	_, _, err := uc.build(ctx, req)
+	return err
+}
+
+// build validates the submission and returns the job to create, and the
+// problem rule it breaks, if any.
+func (uc *SubmitJobUsecase) build(ctx context.Context, req *domain.SubmitRequest) (*domain.Job, string, error) {
+	// Validate language
+	if err := uc.checkLanguage(ctx, req.Language); err != nil {
+		return nil, "", err
+	}
+
+	// Validate source code
+	sourceCode := req.SourceCode
+	if uc.sourcePolicy != nil {
+		normalized, err := uc.sourcePolicy.Normalize(sourceCode)
+		if err != nil {
+			return nil, "", err
+		}
+		sourceCode = normalized
+	}
+	if strings.TrimSpace(sourceCode) == "" {
+		return nil, "", domain.ErrEmptySourceCode
+	}
+	if len(sourceCode) > maxSourceCodeSize {
+		return nil, "", domain.ErrPayloadTooLarge
+	}
+	if err := validateCompilerFlags(req.CompilerFlags); err != nil {
+		return nil, "", err
+	}
+	if err := validateArgs(req.Args); err != nil {
+		return nil, "", err
+	}
+	if err := uc.validateEnv(req.Language, req.Env); err != nil {
+		return nil, "", err
+	}
+	if req.SandboxTier != "" && !uc.sandboxTiers[req.SandboxTier] {
+		return nil, "", fmt.Errorf("%w: %q", domain.ErrInvalidSandboxTier, req.SandboxTier)
+	}
+	networkPolicy, err := uc.networkPolicy(req.NetworkPolicy)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := validateOutputFiles(req.OutputFiles, req.ProblemID != ""); err != nil {
+		return nil, "", err
+	}
+	if req.Interactive && !uc.interactive {
+		return nil, "", domain.ErrInvalidInteractive
+	}
+	if req.Interactive && req.ProblemID != "" {
+		return nil, "", fmt.Errorf("%w for judged submissions", domain.ErrInvalidInteractive)
+	}
+
+	compareMode, err := validateExpectedOutput(req)
+	if err != nil {
+		return nil, "", err
+	}
+	priority := domain.DefaultPriority
+	if req.Priority != nil {
+		if *req.Priority < 0 || *req.Priority > domain.MaxPriority {
+			return nil, "", fmt.Errorf("%w: must be 0-%d", domain.ErrInvalidPriority, domain.MaxPriority)
+		}
+		priority = *req.Priority
+	}
+	runAt, err := uc.runAt(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := validateExternalID(req.ExternalID); err != nil {
+		return nil, "", err
+	}
+
+	// A problem's rules are checked against the source as submitted. A
+	// problem with harnesses takes only the function under test and wraps
+	// it in the driver for the submission's language.
+	var solutionCode, violation string
+	if req.ProblemID != "" {
+		problem, err := uc.checkProblem(ctx, req.ProblemID)
+		if err != nil {
+			return nil, "", err
+		}
+		violation = ruleViolation(problem.Rules, req.Language, sourceCode)
+		if len(problem.Harnesses) > 0 {
+			harness, ok := problem.HarnessFor(req.Language)
+			if !ok {
+				return nil, "", fmt.Errorf("%w: problem %q has no harness for %q", domain.ErrInvalidLanguage, req.ProblemID, req.Language)
+			}
+			solutionCode = sourceCode
+			sourceCode = harness.Wrap(sourceCode)
+			if len(sourceCode) > maxSourceCodeSize {
+				return nil, "", domain.ErrPayloadTooLarge
+			}
+		}
+	}
+
+	tenantID := req.TenantID
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	origin := req.Origin
+	if origin == "" {
+		origin = domain.OriginUser
+	}
+
+	// Apply defaults, within the language's caps
+	maxTimeMs, maxMemoryKB := uc.languages.Limits(req.Language)
+	timeLimitMs := min(defaultTimeLimitMs, maxTimeMs)
+	if req.TimeLimitMs != nil && *req.TimeLimitMs > 0 && *req.TimeLimitMs <= maxTimeMs {
+		timeLimitMs = *req.TimeLimitMs
+	}
+	if req.WallTimeLimitMs != nil && *req.WallTimeLimitMs > 0 && *req.WallTimeLimitMs <= maxTimeMs {
+		timeLimitMs = *req.WallTimeLimitMs
+	}
+	// Zero leaves CPU time under the wall-clock limit.
+	cpuTimeLimitMs := 0
+	if req.CPUTimeLimitMs != nil && *req.CPUTimeLimitMs > 0 && *req.CPUTimeLimitMs <= maxTimeMs {
+		cpuTimeLimitMs = *req.CPUTimeLimitMs
+	}
+	memoryLimitKB := min(defaultMemoryLimitKB, maxMemoryKB)
+	if req.MemoryLimitKB != nil && *req.MemoryLimitKB > 0 && *req.MemoryLimitKB <= maxMemoryKB {
+		memoryLimitKB = *req.MemoryLimitKB
+	}
+	// Zero leaves the process limit to the language's sandbox profile.
+	pidsLimit := 0
+	if req.PidsLimit != nil && *req.PidsLimit > 0 && *req.PidsLimit <= maxPidsLimit {
+		pidsLimit = *req.PidsLimit
+	}
+
+	// Generate UUIDv7 (time-ordered)
+	jobID, err := uuid.NewV7()
+	if err != nil {
+		return nil, "", fmt.Errorf("generate UUIDv7: %w", err)
+	}
+
+	job := &domain.Job{
+		JobID:           jobID,
+		TenantID:        tenantID,
+		Language:        req.Language,
+		SourceCode:      sourceCode,
+		Stdin:           req.Stdin,
+		Status:          domain.StatusQueued,
+		TimeLimitMs:     timeLimitMs,
+		MemoryLimitKB:   memoryLimitKB,
+		CompilerFlags:   req.CompilerFlags,
+		WallTimeLimitMs: timeLimitMs,
+		CPUTimeLimitMs:  cpuTimeLimitMs,
+		PidsLimit:       pidsLimit,
+		NetworkPolicy:   networkPolicy,
+		OutputFiles:     req.OutputFiles,
+		Interactive:     req.Interactive,
+		ExpectedOutput:  req.ExpectedOutput,
+		CompareMode:     compareMode,
+		Args:            req.Args,
+		Env:             req.Env,
+		ProblemID:       req.ProblemID,
+		SolutionCode:    solutionCode,
+		Priority:        priority,
+		Origin:          origin,
+		ParentJobID:     req.ParentJobID,
+		ExternalID:      req.ExternalID,
+		APIKeyID:        req.APIKeyID,
+		UserID:          req.UserID,
+		SandboxTier:     req.SandboxTier,
+		CreatedAt:       time.Now().UTC(),
+		UpdatedAt:       time.Now().UTC(),
+	}
+
+	// A scheduled job is queued by the Scheduler once run_at arrives. A rule
+	// violation is the job's verdict; it is stored finished and never
+	// queued.
+	if runAt != nil {
+		job.Status = domain.StatusScheduled
+		job.RunAt = runAt
+	}
+	if violation != "" {
+		job.Status = domain.StatusRuleViolation
+	}
+
+	return job, violation, nil
+}
+
+// Rerun submits a copy of the job under a new ID: the same source, input,
+// limits and options, for the same tenant. The copy is validated and
+// counted against the tenant's quota like any submission, so a rerun fails
+// if the deployment no longer offers an option the job used.
+func (uc *SubmitJobUsecase) Rerun(ctx context.Context, id uuid.UUID) (*domain.SubmitResponse, error) {
+	job, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := uc.Execute(ctx, rerunRequest(job))
+	if err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Job rerun",
+		zap.String("job_id", resp.JobID.String()),
+		zap.String("rerun_of", id.String()),
+	)
+	return resp, nil
+}
+
+// rerunRequest is the submission that created job, as far as it was stored.
+// A harnessed job is resubmitted as the function alone and wrapped again.
+func rerunRequest(job *domain.Job) *domain.SubmitRequest {
+	// Zero limits were left to the defaults and are again.
+	optional := func(n int) *int {
+		if n == 0 {
+			return nil
+		}
+		return &n
+	}
+	return &domain.SubmitRequest{
+		Language:        job.Language,
+		SourceCode:      job.SubmittedSource(),
+		Stdin:           job.Stdin,
+		WallTimeLimitMs: optional(job.TimeLimitMs),
+		CPUTimeLimitMs:  optional(job.CPUTimeLimitMs),
+		MemoryLimitKB:   optional(job.MemoryLimitKB),
+		PidsLimit:       optional(job.PidsLimit),
+		CompilerFlags:   job.CompilerFlags,
+		Args:            job.Args,
+		Env:             job.Env,
+		ProblemID:       job.ProblemID,
+		SandboxTier:     job.SandboxTier,
+		NetworkPolicy:   job.NetworkPolicy,
+		OutputFiles:     job.OutputFiles,
+		Interactive:     job.Interactive,
+		ExpectedOutput:  job.ExpectedOutput,
+		CompareMode:     job.CompareMode,
+		Priority:        &job.Priority,
+		TenantID:        job.TenantID,
+		Origin:          domain.OriginResubmit,
+		ParentJobID:     &job.JobID,
+		APIKeyID:        job.APIKeyID,
+		UserID:          job.UserID,
+	}
+}
+
+// checkLanguage accepts languages from the registry and, when enabled,
+// registered runtimes.
+func (uc *SubmitJobUsecase) checkLanguage(ctx context.Context, lang domain.Language) error {
+	if uc.languages.IsSupported(lang) {
+		return nil
+	}
+	if uc.runtimes == nil {
+		return domain.ErrInvalidLanguage
+	}
+	if _, err := uc.runtimes.GetByName(ctx, lang); err != nil {
+		if errors.Is(err, domain.ErrRuntimeNotFound) {
+			return domain.ErrInvalidLanguage
+		}
+		return fmt.Errorf("check runtime: %w", err)
+	}
+	return nil
+}
+
+// runAt returns when a scheduled submission is to be queued, or nil to queue
+// it now.
+func (uc *SubmitJobUsecase) runAt(req *domain.SubmitRequest) (*time.Time, error) {
+	now := time.Now()
+	if req.RunAt == nil || !req.RunAt.After(now) {
+		return nil, nil
+	}
+	if !uc.scheduling {
+		return nil, fmt.Errorf("%w: scheduled execution is not enabled", domain.ErrInvalidRunAt)
+	}
+	if req.Interactive {
+		return nil, fmt.Errorf("%w for interactive submissions", domain.ErrInvalidRunAt)
+	}
+	if req.RunAt.After(now.Add(maxScheduleAhead)) {
+		return nil, fmt.Errorf("%w: at most %d days ahead", domain.ErrInvalidRunAt, int(maxScheduleAhead.Hours()/24))
+	}
+	runAt := req.RunAt.UTC()
+	return &runAt, nil
+}
+
+// networkPolicy resolves a submission's network policy to the one stored
+// with the job, empty for none.
+func (uc *SubmitJobUsecase) networkPolicy(policy string) (string, error) {
+	switch policy {
+	case "", domain.NetworkNone:
+		return "", nil
+	case domain.NetworkAllowlist:
+		if uc.networkAllowlist {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", domain.ErrInvalidNetworkPolicy, policy)
+}
+
+// checkProblem returns the problem a judged submission targets.
+func (uc *SubmitJobUsecase) checkProblem(ctx context.Context, problemID string) (*domain.Problem, error) {
+	if uc.problems == nil {
+		return nil, domain.ErrProblemNotFound
+	}
+	problem, err := uc.problems.GetByID(ctx, problemID)
+	if err != nil {
+		if errors.Is(err, domain.ErrProblemNotFound) {
+			return nil, domain.ErrProblemNotFound
+		}
+		return nil, fmt.Errorf("get problem: %w", err)
+	}
+	return problem, nil
+}
+
+// checkQuota rejects the submission if the tenant has exhausted its daily budget.
+// Quota store failures are logged and the submission is allowed (fail-open),
+// matching the rate limiter's behaviour when Redis is unavailable.
+func (uc *SubmitJobUsecase) checkQuota(ctx context.Context, tenantID string) error {
+	if uc.quota == nil || uc.dailyBudget <= 0 {
+		return nil
+	}
+	used, err := uc.quota.Usage(ctx, tenantID)
+	if err != nil {
+		uc.logger.Warn("Quota lookup failed, allowing submission", zap.Error(err), zap.String("tenant_id", tenantID))
+		return nil
+	}
+	if used >= uc.dailyBudget {
+		uc.logger.Info("Tenant quota exhausted",
+			zap.String("tenant_id", tenantID),
+			zap.Duration("used", used),
+			zap.Duration("budget", uc.dailyBudget),
+		)
+		return domain.ErrQuotaExceeded
+	}
+	return nil
+}
+
+// QuotaResetAt returns when the current quota window ends, or the zero time if quotas are disabled.
+func (uc *SubmitJobUsecase) QuotaResetAt(now time.Time) time.Time {
+	if uc.quota == nil {
+		return time.Time{}
+	}
+	return uc.quota.ResetAt(now)
+}
+
+// validateCompilerFlags bounds the flags a submission may carry. Whether a flag
+// is actually allowed for the language is decided by the worker's allowlist.
+func validateCompilerFlags(flags []string) error {
+	if len(flags) > maxCompilerFlags {
+		return fmt.Errorf("%w: at most %d flags", domain.ErrInvalidCompilerFlags, maxCompilerFlags)
+	}
+	for _, flag := range flags {
+		if !strings.HasPrefix(flag, "-") || len(flag) > maxCompilerFlagLen || strings.ContainsAny(flag, " \t\r\n") {
+			return fmt.Errorf("%w: %q must be a single option of at most %d characters", domain.ErrInvalidCompilerFlags, flag, maxCompilerFlagLen)
+		}
+	}
+	return nil
+}
+
+// validateArgs bounds the command-line arguments of a submission. NUL bytes
+// can't be passed through exec, so they are rejected up front.
+func validateArgs(args []string) error {
+	if len(args) > maxArgs {
+		return fmt.Errorf("%w: at most %d arguments", domain.ErrInvalidArgs, maxArgs)
+	}
+	for i, arg := range args {
+		if len(arg) > maxArgLen || strings.ContainsRune(arg, 0) {
+			return fmt.Errorf("%w: argument %d must be at most %d bytes without NUL", domain.ErrInvalidArgs, i+1, maxArgLen)
+		}
+	}
+	return nil
+}
+
+// validateOutputFiles checks that declared output files are distinct plain
+// names in the work directory; the worker never follows a path out of it.
+// Judged submissions run once per test case, so they collect none.
+func validateOutputFiles(names []string, judged bool) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if judged {
+		return fmt.Errorf("%w: not collected for judged submissions", domain.ErrInvalidOutputFiles)
+	}
+	if len(names) > maxOutputFiles {
+		return fmt.Errorf("%w: at most %d files", domain.ErrInvalidOutputFiles, maxOutputFiles)
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || len(name) > maxOutputFileLen || strings.ContainsAny(name, "/\\\x00") {
+			return fmt.Errorf("%w: %q must be a file name of at most %d bytes without slashes", domain.ErrInvalidOutputFiles, name, maxOutputFileLen)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: %q listed twice", domain.ErrInvalidOutputFiles, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// validateExternalID checks that an external ID, if any, fits in a URL path
+// segment: printable ASCII without slashes.
+func validateExternalID(id string) error {
+	if len(id) > maxExternalIDLen {
+		return fmt.Errorf("%w: at most %d bytes", domain.ErrInvalidExternalID, maxExternalIDLen)
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' || id[i] == '/' {
+			return fmt.Errorf("%w: only printable ASCII without spaces or slashes", domain.ErrInvalidExternalID)
+		}
+	}
+	return nil
+}
+
+// validateExpectedOutput checks a submission's expected output and returns
+// the compare mode it is matched with, empty without one.
+func validateExpectedOutput(req *domain.SubmitRequest) (domain.CompareMode, error) {
+	if req.ExpectedOutput == nil {
+		if req.CompareMode != "" {
+			return "", fmt.Errorf("%w: compare_mode needs expected_output", domain.ErrInvalidExpectedOutput)
+		}
+		return "", nil
+	}
+	if req.ProblemID != "" {
+		return "", fmt.Errorf("%w: judged submissions use their problem's test data", domain.ErrInvalidExpectedOutput)
+	}
+	if len(*req.ExpectedOutput) > maxExpectedOutput {
+		return "", fmt.Errorf("%w: at most %d bytes", domain.ErrInvalidExpectedOutput, maxExpectedOutput)
+	}
+	mode := req.CompareMode
+	if mode == "" {
+		mode = domain.CompareTokens
+	}
+	if !mode.IsValid() {
+		return "", fmt.Errorf("%w: unknown compare_mode %q", domain.ErrInvalidExpectedOutput, mode)
+	}
+	return mode, nil
+}
+
+// validateEnv checks a submission's environment against the language's
+// allowlist. NUL bytes can't be passed through exec, so they are rejected.
+func (uc *SubmitJobUsecase) validateEnv(lang domain.Language, env map[string]string) error {
+	if len(env) > maxEnvVars {
+		return fmt.Errorf("%w: at most %d variables", domain.ErrInvalidEnv, maxEnvVars)
+	}
+	for name, value := range env {
+		if !uc.languages.EnvAllowed(lang, name) {
+			return fmt.Errorf("%w: %q is not allowed for %s", domain.ErrInvalidEnv, name, lang)
+		}
+		if len(value) > maxEnvValueLen || strings.ContainsRune(value, 0) {
+			return fmt.Errorf("%w: %s must be at most %d bytes without NUL", domain.ErrInvalidEnv, name, maxEnvValueLen)
+		}
+	}
+	return nil
+}
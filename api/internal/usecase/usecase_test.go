@@ -2,22 +2,53 @@ package usecase
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/github"
+	mockgh "github.com/Harsh-BH/Sentinel/api/internal/github/mock"
+	"github.com/Harsh-BH/Sentinel/api/internal/language"
+	"github.com/Harsh-BH/Sentinel/api/internal/lti"
+	mocklti "github.com/Harsh-BH/Sentinel/api/internal/lti/mock"
 	mockpub "github.com/Harsh-BH/Sentinel/api/internal/publisher/mock"
 	mockrepo "github.com/Harsh-BH/Sentinel/api/internal/repository/mock"
+	mockwh "github.com/Harsh-BH/Sentinel/api/internal/warehouse/mock"
+	mockhook "github.com/Harsh-BH/Sentinel/api/internal/webhook/mock"
 )
 
+// registryPath is the language registry shipped with the repository.
+const registryPath = "../../../sandbox/languages.yaml"
+
+func testLanguages(t testing.TB) *language.Registry {
+	t.Helper()
+	reg, err := language.Load(registryPath)
+	if err != nil {
+		t.Fatalf("load language registry: %v", err)
+	}
+	return reg
+}
+
 func TestSubmitJob_Success(t *testing.T) {
 	repo := mockrepo.NewMockJobRepository()
 	pub := mockpub.NewMockPublisher()
 	logger := zap.NewNop()
 
-	uc := NewSubmitJobUsecase(repo, pub, logger)
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	req := &domain.SubmitRequest{
 		Language:   domain.LangPython,
@@ -65,7 +96,7 @@ func TestSubmitJob_InvalidLanguage(t *testing.T) {
 	pub := mockpub.NewMockPublisher()
 	logger := zap.NewNop()
 
-	uc := NewSubmitJobUsecase(repo, pub, logger)
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	req := &domain.SubmitRequest{
 		Language:   domain.Language("ruby"),
@@ -83,7 +114,7 @@ func TestSubmitJob_EmptySourceCode(t *testing.T) {
 	pub := mockpub.NewMockPublisher()
 	logger := zap.NewNop()
 
-	uc := NewSubmitJobUsecase(repo, pub, logger)
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	req := &domain.SubmitRequest{
 		Language:   domain.LangPython,
@@ -101,7 +132,7 @@ func TestSubmitJob_PayloadTooLarge(t *testing.T) {
 	pub := mockpub.NewMockPublisher()
 	logger := zap.NewNop()
 
-	uc := NewSubmitJobUsecase(repo, pub, logger)
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	// Create source code larger than 1MB
 	largeCode := make([]byte, maxSourceCodeSize+1)
@@ -125,7 +156,7 @@ func TestSubmitJob_CustomLimits(t *testing.T) {
 	pub := mockpub.NewMockPublisher()
 	logger := zap.NewNop()
 
-	uc := NewSubmitJobUsecase(repo, pub, logger)
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	timeLimit := 10000
 	memLimit := 131072
@@ -154,6 +185,747 @@ func TestSubmitJob_CustomLimits(t *testing.T) {
 	_ = resp
 }
 
+func TestSubmitJob_LanguageLimits(t *testing.T) {
+	reg, err := language.NewRegistry([]domain.LanguageInfo{
+		{Name: domain.LangPython, Version: "3.12", MaxTimeMs: 2000, MaxMemoryKB: 65536},
+		{Name: domain.LangCpp, Version: "17"},
+	})
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	if _, err := language.NewRegistry([]domain.LanguageInfo{{Name: domain.LangGo, MaxTimeMs: maxTimeLimitMs + 1}}); err == nil {
+		t.Error("expected a time cap above the API's to be rejected")
+	}
+
+	intPtr := func(v int) *int { return &v }
+	tests := []struct {
+		name       string
+		lang       domain.Language
+		time, mem  *int
+		wantTime   int
+		wantMemory int
+	}{
+		{name: "defaults lowered to the caps", lang: domain.LangPython, wantTime: 2000, wantMemory: 65536},
+		{name: "within the caps", lang: domain.LangPython, time: intPtr(1500), mem: intPtr(32768), wantTime: 1500, wantMemory: 32768},
+		{name: "above the caps ignored", lang: domain.LangPython, time: intPtr(3000), mem: intPtr(131072), wantTime: 2000, wantMemory: 65536},
+		{name: "uncapped language", lang: domain.LangCpp, time: intPtr(20000), wantTime: 20000, wantMemory: defaultMemoryLimitKB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mockrepo.NewMockJobRepository()
+			uc := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), reg, zap.NewNop())
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+				Language:      tt.lang,
+				SourceCode:    "x",
+				TimeLimitMs:   tt.time,
+				MemoryLimitKB: tt.mem,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			job := repo.GetAll()[0]
+			if job.TimeLimitMs != tt.wantTime || job.MemoryLimitKB != tt.wantMemory {
+				t.Errorf("limits = %d ms, %d KB, want %d ms, %d KB", job.TimeLimitMs, job.MemoryLimitKB, tt.wantTime, tt.wantMemory)
+			}
+		})
+	}
+}
+
+func TestSubmitJob_WallAndCPUTimeLimits(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	tests := []struct {
+		name     string
+		time     *int
+		wall     *int
+		cpu      *int
+		wantWall int
+		wantCPU  int
+	}{
+		{name: "defaults", wantWall: defaultTimeLimitMs, wantCPU: 0},
+		{name: "time limit is the wall limit", time: intPtr(8000), wantWall: 8000, wantCPU: 0},
+		{name: "wall limit wins", time: intPtr(8000), wall: intPtr(3000), wantWall: 3000, wantCPU: 0},
+		{name: "separate cpu limit", wall: intPtr(10000), cpu: intPtr(2000), wantWall: 10000, wantCPU: 2000},
+		{name: "out of range cpu limit ignored", cpu: intPtr(maxTimeLimitMs + 1), wantWall: defaultTimeLimitMs, wantCPU: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mockrepo.NewMockJobRepository()
+			pub := mockpub.NewMockPublisher()
+			uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+				Language:        domain.LangPython,
+				SourceCode:      "print(1)",
+				TimeLimitMs:     tt.time,
+				WallTimeLimitMs: tt.wall,
+				CPUTimeLimitMs:  tt.cpu,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			job := repo.GetAll()[0]
+			if job.TimeLimitMs != tt.wantWall || job.WallTimeLimitMs != tt.wantWall {
+				t.Errorf("time_limit_ms = %d, wall_time_limit_ms = %d, want %d", job.TimeLimitMs, job.WallTimeLimitMs, tt.wantWall)
+			}
+			if job.CPUTimeLimitMs != tt.wantCPU {
+				t.Errorf("cpu_time_limit_ms = %d, want %d", job.CPUTimeLimitMs, tt.wantCPU)
+			}
+		})
+	}
+}
+
+func TestSubmitJob_PidsLimit(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	tests := []struct {
+		name string
+		pids *int
+		want int
+	}{
+		{name: "profile default", want: 0},
+		{name: "raised", pids: intPtr(256), want: 256},
+		{name: "out of range ignored", pids: intPtr(maxPidsLimit + 1), want: 0},
+		{name: "negative ignored", pids: intPtr(-1), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mockrepo.NewMockJobRepository()
+			pub := mockpub.NewMockPublisher()
+			uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+				Language:   domain.LangPython,
+				SourceCode: "print(1)",
+				PidsLimit:  tt.pids,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := pub.Published[0].PidsLimit; got != tt.want {
+				t.Errorf("published pids_limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitJob_NetworkPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		enabled bool
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: ""},
+		{name: "none", policy: domain.NetworkNone, want: ""},
+		{name: "allowlist", policy: domain.NetworkAllowlist, enabled: true, want: domain.NetworkAllowlist},
+		{name: "allowlist disabled", policy: domain.NetworkAllowlist, wantErr: true},
+		{name: "unknown", policy: "open", enabled: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mockrepo.NewMockJobRepository()
+			pub := mockpub.NewMockPublisher()
+			uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+			uc.SetNetworkAllowlist(tt.enabled)
+
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+				Language:      domain.LangPython,
+				SourceCode:    "print(1)",
+				NetworkPolicy: tt.policy,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidNetworkPolicy) {
+					t.Fatalf("expected ErrInvalidNetworkPolicy, got %v", err)
+				}
+				if len(pub.Published) != 0 {
+					t.Error("rejected submission was published")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := pub.Published[0].NetworkPolicy; got != tt.want {
+				t.Errorf("published network_policy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitJob_OutputFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		problemID string
+		wantErr   bool
+	}{
+		{name: "none"},
+		{name: "plain names", files: []string{"output.txt", "plot.png", ".hidden"}},
+		{name: "path", files: []string{"../etc/passwd"}, wantErr: true},
+		{name: "subdirectory", files: []string{"out/result.txt"}, wantErr: true},
+		{name: "dot dot", files: []string{".."}, wantErr: true},
+		{name: "empty", files: []string{""}, wantErr: true},
+		{name: "duplicate", files: []string{"a.txt", "a.txt"}, wantErr: true},
+		{name: "too many", files: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"}, wantErr: true},
+		{name: "too long", files: []string{strings.Repeat("a", maxOutputFileLen+1)}, wantErr: true},
+		{name: "judged", files: []string{"output.txt"}, problemID: "sum", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mockrepo.NewMockJobRepository()
+			pub := mockpub.NewMockPublisher()
+			uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+				Language:    domain.LangPython,
+				SourceCode:  "print(1)",
+				OutputFiles: tt.files,
+				ProblemID:   tt.problemID,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidOutputFiles) {
+					t.Fatalf("expected ErrInvalidOutputFiles, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := pub.Published[0].OutputFiles; len(got) != len(tt.files) {
+				t.Errorf("published output_files = %q, want %q", got, tt.files)
+			}
+		})
+	}
+}
+
+func TestSubmitJob_Interactive(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		problemID string
+		wantErr   bool
+	}{
+		{name: "enabled", enabled: true},
+		{name: "disabled", wantErr: true},
+		{name: "judged", enabled: true, problemID: "sum", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := mockpub.NewMockPublisher()
+			uc := NewSubmitJobUsecase(mockrepo.NewMockJobRepository(), pub, testLanguages(t), zap.NewNop())
+			uc.SetInteractive(tt.enabled)
+
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+				Language:    domain.LangPython,
+				SourceCode:  "print(input())",
+				ProblemID:   tt.problemID,
+				Interactive: true,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInteractive) {
+					t.Fatalf("expected ErrInvalidInteractive, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !pub.Published[0].Interactive {
+				t.Error("published job is not interactive")
+			}
+		})
+	}
+}
+
+func TestSubmitJob_ExpectedOutput(t *testing.T) {
+	output := "3\n"
+	huge := strings.Repeat("x", maxExpectedOutput+1)
+	tests := []struct {
+		name      string
+		expected  *string
+		mode      domain.CompareMode
+		problemID string
+		wantMode  domain.CompareMode
+		wantErr   bool
+	}{
+		{name: "defaults to tokens", expected: &output, wantMode: domain.CompareTokens},
+		{name: "exact", expected: &output, mode: domain.CompareExact, wantMode: domain.CompareExact},
+		{name: "none"},
+		{name: "unknown mode", expected: &output, mode: "fuzzy", wantErr: true},
+		{name: "mode without output", mode: domain.CompareExact, wantErr: true},
+		{name: "too large", expected: &huge, wantErr: true},
+		{name: "judged", expected: &output, problemID: "sum", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := mockpub.NewMockPublisher()
+			uc := NewSubmitJobUsecase(mockrepo.NewMockJobRepository(), pub, testLanguages(t), zap.NewNop())
+
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+				Language:       domain.LangPython,
+				SourceCode:     "print(1 + 2)",
+				ProblemID:      tt.problemID,
+				ExpectedOutput: tt.expected,
+				CompareMode:    tt.mode,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidExpectedOutput) {
+					t.Fatalf("expected ErrInvalidExpectedOutput, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			job := pub.Published[0]
+			if job.CompareMode != tt.wantMode {
+				t.Errorf("compare mode %q, want %q", job.CompareMode, tt.wantMode)
+			}
+			if (job.ExpectedOutput == nil) != (tt.expected == nil) {
+				t.Errorf("published expected output %v, want %v", job.ExpectedOutput, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInteractive_SendStdin(t *testing.T) {
+	store := mockrepo.NewMockInteractiveStore()
+	uc := NewInteractiveUsecase(store, zap.NewNop())
+	ctx := context.Background()
+	job := &domain.Job{JobID: uuid.New(), Status: domain.StatusRunning, Interactive: true}
+
+	if err := uc.SendStdin(ctx, job, "1 2\n", false); err != nil {
+		t.Fatalf("SendStdin: %v", err)
+	}
+	if err := uc.SendStdin(ctx, job, "", true); err != nil {
+		t.Fatalf("close stdin: %v", err)
+	}
+	if sent, closed := store.Stdin(job.JobID); fmt.Sprint(sent) != "[1 2\n]" || !closed {
+		t.Errorf("stdin = %q (closed %v), want one message and closed", sent, closed)
+	}
+	if err := uc.SendStdin(ctx, job, strings.Repeat("x", maxStdinMessage+1), false); !errors.Is(err, domain.ErrInvalidStdin) {
+		t.Errorf("oversized input: expected ErrInvalidStdin, got %v", err)
+	}
+
+	plain := &domain.Job{JobID: uuid.New(), Status: domain.StatusRunning}
+	finished := &domain.Job{JobID: uuid.New(), Status: domain.StatusSuccess, Interactive: true}
+	for _, j := range []*domain.Job{plain, finished} {
+		if err := uc.SendStdin(ctx, j, "x", false); !errors.Is(err, domain.ErrNotInteractive) {
+			t.Errorf("job %+v: expected ErrNotInteractive, got %v", j, err)
+		}
+	}
+
+	store.WriteOutput(job.JobID, "3\n")
+	out, cursor, err := uc.Output(ctx, job.JobID, "")
+	if err != nil || out != "3\n" {
+		t.Fatalf("Output() = %q, %v", out, err)
+	}
+	if out, _, _ := uc.Output(ctx, job.JobID, cursor); out != "" {
+		t.Errorf("Output after cursor = %q, want nothing new", out)
+	}
+}
+
+func TestGetJob_Artifact(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	uc := NewGetJobUsecase(repo, zap.NewNop())
+	id := uuid.New()
+	repo.Create(context.Background(), &domain.Job{JobID: id, Status: domain.StatusSuccess})
+	repo.Artifacts[id] = map[string]*domain.Artifact{"out.txt": {Name: "out.txt", Content: []byte("42")}}
+
+	a, err := uc.Artifact(context.Background(), id, "out.txt")
+	if err != nil || string(a.Content) != "42" {
+		t.Fatalf("Artifact() = %v, %v", a, err)
+	}
+	if _, err := uc.Artifact(context.Background(), id, "other.txt"); !errors.Is(err, domain.ErrArtifactNotFound) {
+		t.Errorf("missing artifact: expected ErrArtifactNotFound, got %v", err)
+	}
+	if _, err := uc.Artifact(context.Background(), uuid.New(), "out.txt"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("missing job: expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestSubmitJob_CompilerFlags(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+
+	req := &domain.SubmitRequest{
+		Language:      domain.LangCpp,
+		SourceCode:    "int main() {}",
+		CompilerFlags: []string{"-O0", "-g"},
+	}
+	if _, err := uc.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jobs := repo.GetAll()
+	if len(jobs) != 1 || len(jobs[0].CompilerFlags) != 2 || jobs[0].CompilerFlags[1] != "-g" {
+		t.Fatalf("expected flags to be stored on the job, got %+v", jobs)
+	}
+
+	invalid := [][]string{
+		{"O2"},
+		{"-O0 -g"},
+		{"-std=" + strings.Repeat("x", maxCompilerFlagLen)},
+		make([]string, maxCompilerFlags+1),
+	}
+	for _, flags := range invalid {
+		req := &domain.SubmitRequest{Language: domain.LangCpp, SourceCode: "int main() {}", CompilerFlags: flags}
+		if _, err := uc.Execute(context.Background(), req); !errors.Is(err, domain.ErrInvalidCompilerFlags) {
+			t.Errorf("flags %q: expected ErrInvalidCompilerFlags, got %v", flags, err)
+		}
+	}
+}
+
+func TestSubmitJob_Args(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	uc := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+
+	req := &domain.SubmitRequest{
+		Language:   domain.LangPython,
+		SourceCode: "import sys; print(sys.argv[1:])",
+		Args:       []string{"--verbose", "input file.txt", ""},
+	}
+	if _, err := uc.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jobs := repo.GetAll(); len(jobs) != 1 || len(jobs[0].Args) != 3 || jobs[0].Args[1] != "input file.txt" {
+		t.Fatalf("expected args to be stored verbatim, got %+v", jobs)
+	}
+
+	invalid := [][]string{
+		make([]string, maxArgs+1),
+		{strings.Repeat("a", maxArgLen+1)},
+		{"nul\x00byte"},
+	}
+	for _, args := range invalid {
+		req := &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "pass", Args: args}
+		if _, err := uc.Execute(context.Background(), req); !errors.Is(err, domain.ErrInvalidArgs) {
+			t.Errorf("expected ErrInvalidArgs, got %v", err)
+		}
+	}
+}
+
+func TestSubmitJob_Env(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	uc := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+
+	req := &domain.SubmitRequest{
+		Language:   domain.LangPython,
+		SourceCode: "import os; print(os.environ['PYTHONHASHSEED'])",
+		Env:        map[string]string{"PYTHONHASHSEED": "0", "LANG": "C.UTF-8"},
+	}
+	if _, err := uc.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jobs := repo.GetAll(); len(jobs) != 1 || jobs[0].Env["PYTHONHASHSEED"] != "0" {
+		t.Fatalf("expected env to be stored, got %+v", jobs)
+	}
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= maxEnvVars; i++ {
+		tooMany[fmt.Sprintf("VAR%d", i)] = "x"
+	}
+	invalid := []struct {
+		lang domain.Language
+		env  map[string]string
+	}{
+		{domain.LangPython, map[string]string{"LD_PRELOAD": "/tmp/work/evil.so"}},
+		{domain.LangCpp, map[string]string{"PYTHONHASHSEED": "0"}},
+		{domain.LangPython, map[string]string{"LANG": strings.Repeat("a", maxEnvValueLen+1)}},
+		{domain.LangPython, map[string]string{"TZ": "UTC\x00"}},
+		{domain.LangPython, tooMany},
+	}
+	for _, tt := range invalid {
+		req := &domain.SubmitRequest{Language: tt.lang, SourceCode: "pass", Env: tt.env}
+		if _, err := uc.Execute(context.Background(), req); !errors.Is(err, domain.ErrInvalidEnv) {
+			t.Errorf("expected ErrInvalidEnv for %s %v, got %v", tt.lang, tt.env, err)
+		}
+	}
+}
+
+func TestSubmitJob_SandboxTier(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	uc := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+
+	req := &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "pass", SandboxTier: "microvm"}
+	if _, err := uc.Execute(context.Background(), req); !errors.Is(err, domain.ErrInvalidSandboxTier) {
+		t.Fatalf("expected ErrInvalidSandboxTier without configured tiers, got %v", err)
+	}
+
+	uc.SetSandboxTiers([]string{"microvm", "gvisor"})
+	if _, err := uc.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jobs := repo.GetAll(); len(jobs) != 1 || jobs[0].SandboxTier != "microvm" {
+		t.Fatalf("expected sandbox tier to be stored, got %+v", jobs)
+	}
+
+	req.SandboxTier = "bare-metal"
+	if _, err := uc.Execute(context.Background(), req); !errors.Is(err, domain.ErrInvalidSandboxTier) {
+		t.Errorf("expected ErrInvalidSandboxTier for an unlisted tier, got %v", err)
+	}
+}
+
+func TestSubmitJob_Rerun(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	uc.SetSandboxTiers([]string{"microvm"})
+	ctx := context.Background()
+
+	wall, cpu, pids := 2000, 1500, 64
+	first, err := uc.Execute(ctx, &domain.SubmitRequest{
+		Language:        domain.LangPython,
+		SourceCode:      "import sys; print(sys.argv)",
+		Stdin:           "1 2\n",
+		WallTimeLimitMs: &wall,
+		CPUTimeLimitMs:  &cpu,
+		PidsLimit:       &pids,
+		Args:            []string{"--fast"},
+		SandboxTier:     "microvm",
+		TenantID:        "acme",
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	original, _ := repo.GetByID(ctx, first.JobID)
+	_ = repo.SetResult(ctx, first.JobID, &domain.Job{Status: domain.StatusInternalError, Stderr: "sandbox failure"})
+
+	resp, err := uc.Rerun(ctx, first.JobID)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if resp.JobID == first.JobID || resp.Status != string(domain.StatusQueued) {
+		t.Fatalf("rerun response = %+v, want a new queued job", resp)
+	}
+	rerun, err := repo.GetByID(ctx, resp.JobID)
+	if err != nil {
+		t.Fatalf("rerun job not stored: %v", err)
+	}
+	if rerun.SourceCode != original.SourceCode || rerun.Stdin != original.Stdin || rerun.TenantID != "acme" ||
+		rerun.TimeLimitMs != wall || rerun.CPUTimeLimitMs != cpu || rerun.PidsLimit != pids ||
+		rerun.MemoryLimitKB != original.MemoryLimitKB || rerun.SandboxTier != "microvm" || len(rerun.Args) != 1 {
+		t.Errorf("rerun job = %+v, want a copy of %+v", rerun, original)
+	}
+	if len(pub.Published) != 2 || pub.Published[1].JobID != resp.JobID {
+		t.Errorf("expected the copy to be published, got %d messages", len(pub.Published))
+	}
+	if got, _ := repo.GetByID(ctx, first.JobID); got.Status != domain.StatusInternalError {
+		t.Errorf("original job changed to %s", got.Status)
+	}
+
+	if _, err := uc.Rerun(ctx, uuid.New()); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("rerun unknown job: got %v, want ErrJobNotFound", err)
+	}
+	// The copy is validated against what the deployment offers now.
+	uc.SetSandboxTiers(nil)
+	if _, err := uc.Rerun(ctx, first.JobID); !errors.Is(err, domain.ErrInvalidSandboxTier) {
+		t.Errorf("rerun with a withdrawn tier: got %v, want ErrInvalidSandboxTier", err)
+	}
+}
+
+func TestSubmitJob_Priority(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	ctx := context.Background()
+
+	resp, err := uc.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if job, _ := repo.GetByID(ctx, resp.JobID); job.Priority != domain.DefaultPriority {
+		t.Errorf("priority = %d, want the default %d", job.Priority, domain.DefaultPriority)
+	}
+
+	high := domain.MaxPriority
+	resp, err = uc.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", Priority: &high})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if pub.Published[1].Priority != high {
+		t.Errorf("published priority = %d, want %d", pub.Published[1].Priority, high)
+	}
+	rerun, err := uc.Rerun(ctx, resp.JobID)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if job, _ := repo.GetByID(ctx, rerun.JobID); job.Priority != high {
+		t.Errorf("rerun priority = %d, want %d", job.Priority, high)
+	}
+
+	for _, p := range []int{-1, domain.MaxPriority + 1} {
+		_, err := uc.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", Priority: &p})
+		if !errors.Is(err, domain.ErrInvalidPriority) {
+			t.Errorf("priority %d: got %v, want ErrInvalidPriority", p, err)
+		}
+	}
+}
+
+func TestSubmitJob_RunAt(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	ctx := context.Background()
+
+	later := time.Now().Add(time.Hour)
+	req := &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", RunAt: &later}
+	if _, err := uc.Execute(ctx, req); !errors.Is(err, domain.ErrInvalidRunAt) {
+		t.Fatalf("scheduling disabled: got %v, want ErrInvalidRunAt", err)
+	}
+
+	uc.SetScheduling(true)
+	resp, err := uc.Execute(ctx, req)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if resp.Status != string(domain.StatusScheduled) || len(pub.Published) != 0 {
+		t.Errorf("got %s with %d published, want SCHEDULED and nothing published", resp.Status, len(pub.Published))
+	}
+	job, _ := repo.GetByID(ctx, resp.JobID)
+	if job.Status != domain.StatusScheduled || job.RunAt == nil || !job.RunAt.Equal(later) {
+		t.Errorf("job = %s at %v, want SCHEDULED at %v", job.Status, job.RunAt, later)
+	}
+
+	// A time already passed queues the job at once.
+	past := time.Now().Add(-time.Minute)
+	resp, err = uc.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", RunAt: &past})
+	if err != nil || resp.Status != string(domain.StatusQueued) || len(pub.Published) != 1 {
+		t.Errorf("past run_at: got %+v, %v; want it queued", resp, err)
+	}
+
+	farOff := time.Now().Add(maxScheduleAhead + time.Hour)
+	if _, err := uc.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", RunAt: &farOff}); !errors.Is(err, domain.ErrInvalidRunAt) {
+		t.Errorf("run_at too far ahead: got %v, want ErrInvalidRunAt", err)
+	}
+
+	cancelled, err := NewCancelJobUsecase(repo, nil, zap.NewNop()).Execute(ctx, job.JobID)
+	if err != nil || cancelled.Status != domain.StatusCancelled {
+		t.Errorf("cancel scheduled job: got %+v, %v; want it cancelled", cancelled, err)
+	}
+}
+
+func TestScheduler_QueuesDueJobs(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	ctx := context.Background()
+	now := time.Now()
+
+	schedule := func(at time.Time) *domain.Job {
+		job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: domain.StatusScheduled, RunAt: &at}
+		_ = repo.Create(ctx, job)
+		return job
+	}
+	first := schedule(now.Add(-2 * time.Minute))
+	second := schedule(now.Add(-time.Minute))
+	future := schedule(now.Add(time.Hour))
+
+	s := NewScheduler(repo, pub, zap.NewNop())
+	queued, err := s.Tick(ctx, now)
+	if err != nil || queued != 2 {
+		t.Fatalf("tick: queued %d, %v; want 2", queued, err)
+	}
+	if len(pub.Published) != 2 || pub.Published[0].JobID != first.JobID || pub.Published[1].JobID != second.JobID {
+		t.Errorf("expected the due jobs published earliest first, got %d", len(pub.Published))
+	}
+	if first.Status != domain.StatusQueued || future.Status != domain.StatusScheduled {
+		t.Errorf("statuses = %s, %s; want QUEUED and SCHEDULED", first.Status, future.Status)
+	}
+
+	// A job whose publish fails waits for the next tick.
+	pub.PublishFn = func(ctx context.Context, job *domain.Job) error { return errors.New("broker down") }
+	queued, err = s.Tick(ctx, now.Add(2*time.Hour))
+	if err != nil || queued != 0 || future.Status != domain.StatusScheduled {
+		t.Errorf("failed publish: queued %d, %v, status %s; want the job rescheduled", queued, err, future.Status)
+	}
+	pub.PublishFn = nil
+	if queued, _ := s.Tick(ctx, now.Add(2*time.Hour)); queued != 1 || future.Status != domain.StatusQueued {
+		t.Errorf("retry: queued %d, status %s; want the job queued", queued, future.Status)
+	}
+}
+
+func TestSchedule_CreateAndRun(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	submit := NewSubmitJobUsecase(jobs, pub, testLanguages(t), zap.NewNop())
+	quota := mockrepo.NewMockQuotaStore()
+	submit.SetQuota(quota, time.Hour)
+	schedules := mockrepo.NewMockScheduleRepository(jobs)
+	uc := NewScheduleUsecase(schedules, submit, zap.NewNop())
+	ctx := context.Background()
+
+	job := domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print('nightly')"}
+	for name, req := range map[string]*domain.CreateScheduleRequest{
+		"bad cron":    {Cron: "61 * * * *", Job: job},
+		"never fires": {Cron: "0 0 30 2 *", Job: job},
+		"bad job":     {Cron: "@daily", Job: domain.SubmitRequest{Language: "cobol", SourceCode: "x"}},
+		"run_at":      {Cron: "@daily", Job: domain.SubmitRequest{Language: domain.LangPython, SourceCode: "x", RunAt: &time.Time{}}},
+	} {
+		if _, err := uc.Create(ctx, "acme", req); !errors.Is(err, domain.ErrInvalidSchedule) {
+			t.Errorf("%s: expected ErrInvalidSchedule, got %v", name, err)
+		}
+	}
+
+	s, err := uc.Create(ctx, "acme", &domain.CreateScheduleRequest{Name: "nightly", Cron: "0 3 * * *", Job: job})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if s.NextRunAt.Hour() != 3 || s.NextRunAt.Minute() != 0 || !s.NextRunAt.After(time.Now()) {
+		t.Errorf("next run = %v, want the next 03:00 UTC", s.NextRunAt)
+	}
+	if _, err := uc.Get(ctx, "other", s.ScheduleID); !errors.Is(err, domain.ErrScheduleNotFound) {
+		t.Errorf("other tenant: expected ErrScheduleNotFound, got %v", err)
+	}
+
+	// Nothing is due yet.
+	if ran, err := uc.Tick(ctx, s.NextRunAt.Add(-time.Second)); err != nil || ran != 0 {
+		t.Fatalf("early tick: ran %d, %v; want 0", ran, err)
+	}
+
+	due := s.NextRunAt
+	if ran, err := uc.Tick(ctx, due); err != nil || ran != 1 {
+		t.Fatalf("tick: ran %d, %v; want 1", ran, err)
+	}
+	if len(pub.Published) != 1 {
+		t.Fatalf("expected one job published, got %d", len(pub.Published))
+	}
+	created := pub.Published[0]
+	if created.TenantID != "acme" || created.Origin != domain.OriginSchedule || created.SourceCode != job.SourceCode {
+		t.Errorf("created job = %+v, want the template for acme with origin schedule", created)
+	}
+	got, _ := uc.Get(ctx, "acme", s.ScheduleID)
+	if got.LastJobID == nil || *got.LastJobID != created.JobID || got.LastStatus != domain.StatusQueued ||
+		got.LastRunAt == nil || !got.NextRunAt.Equal(due.Add(24*time.Hour)) {
+		t.Errorf("schedule after run = %+v, want the job recorded and the next day due", got)
+	}
+
+	// A run that was already advanced is not made twice.
+	if ok, _ := schedules.Advance(ctx, s.ScheduleID, due, due.Add(time.Hour)); ok {
+		t.Error("advanced a run that was already claimed")
+	}
+
+	// A rejected submission is recorded and the schedule moves on.
+	quota.SetUsage("acme", 2*time.Hour)
+	next := got.NextRunAt
+	if ran, err := uc.Tick(ctx, next); err != nil || ran != 1 {
+		t.Fatalf("tick over quota: ran %d, %v; want 1", ran, err)
+	}
+	got, _ = uc.Get(ctx, "acme", s.ScheduleID)
+	if got.LastJobID != nil || got.LastError == "" || !got.NextRunAt.After(next) || len(pub.Published) != 1 {
+		t.Errorf("schedule after rejected run = %+v, want the error recorded", got)
+	}
+
+	if list, _ := uc.List(ctx, "acme"); len(list) != 1 {
+		t.Errorf("list: got %d schedules, want 1", len(list))
+	}
+	if err := uc.Delete(ctx, "acme", s.ScheduleID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := uc.Delete(ctx, "acme", s.ScheduleID); !errors.Is(err, domain.ErrScheduleNotFound) {
+		t.Errorf("second delete: expected ErrScheduleNotFound, got %v", err)
+	}
+}
+
 func TestSubmitJob_PublishFailure(t *testing.T) {
 	repo := mockrepo.NewMockJobRepository()
 	pub := mockpub.NewMockPublisher()
@@ -162,7 +934,7 @@ func TestSubmitJob_PublishFailure(t *testing.T) {
 	}
 	logger := zap.NewNop()
 
-	uc := NewSubmitJobUsecase(repo, pub, logger)
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	req := &domain.SubmitRequest{
 		Language:   domain.LangPython,
@@ -192,7 +964,7 @@ func TestSubmitJob_RepoCreateFailure(t *testing.T) {
 	pub := mockpub.NewMockPublisher()
 	logger := zap.NewNop()
 
-	uc := NewSubmitJobUsecase(repo, pub, logger)
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	req := &domain.SubmitRequest{
 		Language:   domain.LangPython,
@@ -215,7 +987,7 @@ func TestGetJob_Success(t *testing.T) {
 
 	// Pre-populate a job
 	pub := mockpub.NewMockPublisher()
-	submitUC := NewSubmitJobUsecase(repo, pub, logger)
+	submitUC := NewSubmitJobUsecase(repo, pub, testLanguages(t), logger)
 
 	req := &domain.SubmitRequest{
 		Language:   domain.LangPython,
@@ -244,3 +1016,2062 @@ func TestGetJob_NotFound(t *testing.T) {
 		t.Errorf("expected ErrJobNotFound, got %v", err)
 	}
 }
+
+func TestGetJob_ByExternalID(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	submit := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	getUC := NewGetJobUsecase(repo, zap.NewNop())
+	ctx := context.Background()
+
+	resp, err := submit.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", ExternalID: "lms:attempt"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	// An unnamed tenant is the default one, on submission and lookup alike.
+	job, err := getUC.ByExternalID(ctx, domain.DefaultTenantID, "lms:attempt")
+	if err != nil {
+		t.Fatalf("by external id: %v", err)
+	}
+	if job.JobID != resp.JobID {
+		t.Errorf("job = %s, want %s", job.JobID, resp.JobID)
+	}
+
+	rerun, err := submit.Rerun(ctx, resp.JobID)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if job, _ := repo.GetByID(ctx, rerun.JobID); job.ExternalID != "" {
+		t.Errorf("rerun external_id = %q, want none", job.ExternalID)
+	}
+
+	if _, err := getUC.ByExternalID(ctx, "acme", "lms:attempt"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("other tenant: expected ErrJobNotFound, got %v", err)
+	}
+	for _, id := range []string{"a b", "a/b", "caf\u00e9", strings.Repeat("x", maxExternalIDLen+1)} {
+		_, err := submit.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", ExternalID: id})
+		if !errors.Is(err, domain.ErrInvalidExternalID) {
+			t.Errorf("external_id %q: expected ErrInvalidExternalID, got %v", id, err)
+		}
+	}
+}
+
+func TestGetJob_Lineage(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	submit := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	getUC := NewGetJobUsecase(repo, zap.NewNop())
+	ctx := context.Background()
+
+	root, err := submit.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	child, err := submit.Rerun(ctx, root.JobID)
+	if err != nil {
+		t.Fatalf("rerun root: %v", err)
+	}
+	grandchild, err := submit.Rerun(ctx, child.JobID)
+	if err != nil {
+		t.Fatalf("rerun child: %v", err)
+	}
+	// A rejudge runs the finished child again in place.
+	_ = repo.SetResult(ctx, child.JobID, &domain.Job{Status: domain.StatusSuccess})
+	if _, err := repo.PrepareRejudge(ctx, child.JobID); err != nil {
+		t.Fatalf("rejudge child: %v", err)
+	}
+
+	job, _ := repo.GetByID(ctx, child.JobID)
+	if job.Origin != domain.OriginResubmit || job.ParentJobID == nil || *job.ParentJobID != root.JobID {
+		t.Errorf("child origin = %s, parent = %v, want resubmit of %s", job.Origin, job.ParentJobID, root.JobID)
+	}
+	if job, _ := repo.GetByID(ctx, root.JobID); job.Origin != domain.OriginUser || job.ParentJobID != nil {
+		t.Errorf("root origin = %s, parent = %v, want a user submission", job.Origin, job.ParentJobID)
+	}
+
+	lineage, err := getUC.Lineage(ctx, child.JobID)
+	if err != nil {
+		t.Fatalf("lineage: %v", err)
+	}
+	if len(lineage.Chain) != 2 || lineage.Chain[0].JobID != root.JobID || lineage.Chain[1].JobID != child.JobID {
+		t.Fatalf("chain = %+v, want root then child", lineage.Chain)
+	}
+	if len(lineage.Descendants) != 1 || lineage.Descendants[0].JobID != grandchild.JobID {
+		t.Fatalf("descendants = %+v, want the grandchild", lineage.Descendants)
+	}
+	if c := lineage.Chain[1]; c.Runs != 2 || c.JudgeRevision != 1 {
+		t.Errorf("child runs = %d, revision = %d, want 2 and 1", c.Runs, c.JudgeRevision)
+	}
+	if lineage.TotalRuns != 4 {
+		t.Errorf("total runs = %d, want 4", lineage.TotalRuns)
+	}
+
+	lineage, err = getUC.Lineage(ctx, grandchild.JobID)
+	if err != nil {
+		t.Fatalf("lineage: %v", err)
+	}
+	if len(lineage.Chain) != 3 || lineage.Descendants == nil || len(lineage.Descendants) != 0 {
+		t.Errorf("grandchild lineage = %+v, want a chain of 3 and no descendants", lineage)
+	}
+
+	if _, err := getUC.Lineage(ctx, uuid.New()); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	signals := mockrepo.NewMockCancelSignal()
+	uc := NewCancelJobUsecase(jobs, signals, zap.NewNop())
+	ctx := context.Background()
+
+	seed := func(status domain.ExecutionStatus) *domain.Job {
+		job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: status}
+		if err := jobs.Create(ctx, job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		return job
+	}
+
+	queued := seed(domain.StatusQueued)
+	resp, err := uc.Execute(ctx, queued.JobID)
+	if err != nil || resp.Status != domain.StatusCancelled {
+		t.Fatalf("cancel queued job = %+v, %v; want CANCELLED", resp, err)
+	}
+	if got, _ := jobs.GetByID(ctx, queued.JobID); got.Status != domain.StatusCancelled {
+		t.Errorf("queued job stored as %s, want CANCELLED", got.Status)
+	}
+	if len(signals.Requested()) != 0 {
+		t.Error("a queued job needs no signal to workers")
+	}
+
+	running := seed(domain.StatusRunning)
+	resp, err = uc.Execute(ctx, running.JobID)
+	if err != nil || resp.Status != domain.StatusRunning {
+		t.Fatalf("cancel running job = %+v, %v; want RUNNING with a signal sent", resp, err)
+	}
+	if got := signals.Requested(); len(got) != 1 || got[0] != running.JobID {
+		t.Errorf("signalled %v, want only the running job", got)
+	}
+
+	if _, err := uc.Execute(ctx, seed(domain.StatusAccepted).JobID); !errors.Is(err, domain.ErrJobFinished) {
+		t.Errorf("cancel finished job: got %v, want ErrJobFinished", err)
+	}
+	if _, err := uc.Execute(ctx, queued.JobID); !errors.Is(err, domain.ErrJobFinished) {
+		t.Errorf("cancel twice: got %v, want ErrJobFinished", err)
+	}
+	if _, err := uc.Execute(ctx, uuid.New()); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("cancel unknown job: got %v, want ErrJobNotFound", err)
+	}
+
+	signals.RequestCancelFn = func(ctx context.Context, id uuid.UUID) error { return errors.New("redis down") }
+	if _, err := uc.Execute(ctx, seed(domain.StatusCompiling).JobID); err == nil {
+		t.Error("expected a failed signal to be returned")
+	}
+}
+
+func TestCancelJob_Matching(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	locks := mockrepo.NewMockLockStore()
+	uc := NewCancelJobUsecase(jobs, mockrepo.NewMockCancelSignal(), zap.NewNop())
+	uc.SetTombstones(locks)
+	ctx := context.Background()
+
+	now := time.Now()
+	seed := func(tenant string, status domain.ExecutionStatus, age time.Duration) *domain.Job {
+		job := &domain.Job{JobID: uuid.New(), TenantID: tenant, Language: domain.LangPython, Status: status, CreatedAt: now.Add(-age)}
+		if err := jobs.Create(ctx, job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		return job
+	}
+	oldest := seed("flood", domain.StatusQueued, 3*time.Hour)
+	older := seed("flood", domain.StatusQueued, 2*time.Hour)
+	recent := seed("flood", domain.StatusQueued, time.Minute)
+	running := seed("flood", domain.StatusRunning, 3*time.Hour)
+	other := seed("acme", domain.StatusQueued, 3*time.Hour)
+
+	for name, req := range map[string]domain.BulkCancelRequest{
+		"no filter":      {Status: domain.StatusQueued},
+		"running status": {TenantID: "flood", Status: domain.StatusRunning},
+		"limit too high": {TenantID: "flood", Limit: 10001},
+	} {
+		if _, err := uc.CancelMatching(ctx, &req); !errors.Is(err, domain.ErrInvalidBulkCancel) {
+			t.Errorf("%s: got %v, want ErrInvalidBulkCancel", name, err)
+		}
+	}
+
+	before := now.Add(-time.Hour)
+	report, err := uc.CancelMatching(ctx, &domain.BulkCancelRequest{TenantID: "flood", CreatedBefore: &before, DryRun: true})
+	if err != nil || report.Matched != 2 || report.Cancelled != 0 {
+		t.Fatalf("dry run = %+v, %v; want 2 matched and none cancelled", report, err)
+	}
+	if got, _ := jobs.GetByID(ctx, oldest.JobID); got.Status != domain.StatusQueued {
+		t.Errorf("dry run cancelled a job")
+	}
+
+	report, err = uc.CancelMatching(ctx, &domain.BulkCancelRequest{TenantID: "flood", CreatedBefore: &before, Limit: 1})
+	if err != nil || report.Cancelled != 1 || report.JobIDs[0] != oldest.JobID || !report.More || report.Tombstoned != 1 {
+		t.Fatalf("limited cancel = %+v, %v; want the oldest job cancelled and tombstoned, with more left", report, err)
+	}
+	report, err = uc.CancelMatching(ctx, &domain.BulkCancelRequest{TenantID: "flood", CreatedBefore: &before})
+	if err != nil || report.Cancelled != 1 || report.JobIDs[0] != older.JobID || report.More {
+		t.Fatalf("second cancel = %+v, %v; want the next job cancelled", report, err)
+	}
+	for _, job := range []*domain.Job{oldest, older} {
+		if got, _ := jobs.GetByID(ctx, job.JobID); got.Status != domain.StatusCancelled || !locks.Held(job.LockID()) {
+			t.Errorf("job %s: status %s, tombstone %v; want CANCELLED and tombstoned", job.JobID, got.Status, locks.Held(job.LockID()))
+		}
+	}
+	for _, job := range []*domain.Job{recent, running, other} {
+		if got, _ := jobs.GetByID(ctx, job.JobID); got.Status == domain.StatusCancelled || locks.Held(job.LockID()) {
+			t.Errorf("job %s outside the filter was cancelled", job.JobID)
+		}
+	}
+
+	// A job whose tombstone cannot be written is still cancelled.
+	locks.TombstoneFunc = func(ctx context.Context, lockID uuid.UUID, ttl time.Duration) (bool, error) {
+		return false, errors.New("redis down")
+	}
+	report, err = uc.CancelMatching(ctx, &domain.BulkCancelRequest{TenantID: "acme"})
+	if err != nil || report.Cancelled != 1 || report.Tombstoned != 0 {
+		t.Errorf("cancel without tombstones = %+v, %v; want the job cancelled", report, err)
+	}
+}
+
+func TestPurgeJob(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	uc := NewPurgeJobUsecase(jobs, zap.NewNop())
+	ctx := context.Background()
+
+	seed := func(status domain.ExecutionStatus) *domain.Job {
+		job := &domain.Job{JobID: uuid.New(), Language: domain.LangPython, Status: status}
+		if err := jobs.Create(ctx, job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+		return job
+	}
+
+	running := seed(domain.StatusRunning)
+	if err := uc.Execute(ctx, running.JobID); !errors.Is(err, domain.ErrJobActive) {
+		t.Errorf("purge running job: got %v, want ErrJobActive", err)
+	}
+	if _, err := jobs.GetByID(ctx, running.JobID); err != nil {
+		t.Errorf("running job must be kept: %v", err)
+	}
+
+	finished := seed(domain.StatusWrongAnswer)
+	if err := uc.Execute(ctx, finished.JobID); err != nil {
+		t.Fatalf("purge finished job: %v", err)
+	}
+	if _, err := jobs.GetByID(ctx, finished.JobID); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("purged job still stored: %v", err)
+	}
+	if err := uc.Execute(ctx, finished.JobID); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("purge twice: got %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestSubmitJob_QuotaExceeded(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	quota := mockrepo.NewMockQuotaStore()
+	quota.SetUsage("acme", time.Hour)
+
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	uc.SetQuota(quota, time.Hour)
+
+	req := &domain.SubmitRequest{
+		Language:   domain.LangPython,
+		SourceCode: "print('hello')",
+		TenantID:   "acme",
+	}
+
+	_, err := uc.Execute(context.Background(), req)
+	if !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if len(repo.GetAll()) != 0 || len(pub.Published) != 0 {
+		t.Error("rejected submission must not be stored or published")
+	}
+
+	// Other tenants are unaffected.
+	req.TenantID = "globex"
+	if _, err := uc.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error for tenant with remaining budget: %v", err)
+	}
+	if jobs := repo.GetAll(); len(jobs) != 1 || jobs[0].TenantID != "globex" {
+		t.Errorf("expected one job for globex, got %+v", jobs)
+	}
+}
+
+func TestSubmitJob_QuotaLookupFailureAllows(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	quota := mockrepo.NewMockQuotaStore()
+	quota.UsageFunc = func(ctx context.Context, tenantID string) (time.Duration, error) {
+		return 0, errors.New("redis down")
+	}
+
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	uc.SetQuota(quota, time.Minute)
+
+	req := &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)"}
+	if _, err := uc.Execute(context.Background(), req); err != nil {
+		t.Fatalf("expected fail-open on quota lookup error, got %v", err)
+	}
+	if jobs := repo.GetAll(); len(jobs) != 1 || jobs[0].TenantID != domain.DefaultTenantID {
+		t.Errorf("expected one job for the default tenant, got %+v", jobs)
+	}
+}
+
+func TestSubmitJob_UnknownProblem(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	uc.SetProblems(mockrepo.NewMockProblemRepository())
+
+	req := &domain.SubmitRequest{Language: domain.LangPython, SourceCode: "print(1)", ProblemID: "missing"}
+	if _, err := uc.Execute(context.Background(), req); !errors.Is(err, domain.ErrProblemNotFound) {
+		t.Fatalf("expected ErrProblemNotFound, got %v", err)
+	}
+	if len(repo.GetAll()) != 0 || len(pub.Published) != 0 {
+		t.Error("rejected submission must not be stored or published")
+	}
+}
+
+func TestSubmitJob_Harness(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	problems := mockrepo.NewMockProblemRepository()
+	_ = problems.Create(context.Background(), &domain.Problem{
+		ProblemID: "add",
+		TestCases: []domain.TestCase{{Input: "1 2\n", ExpectedOutput: "3\n"}},
+		Harnesses: []domain.Harness{{
+			Language: domain.LangPython,
+			Template: "{{solution}}\n\na, b = map(int, input().split())\nprint(add(a, b))\n",
+		}},
+	})
+
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	uc.SetProblems(problems)
+	ctx := context.Background()
+
+	fn := "def add(a, b):\n    return a + b"
+	resp, err := uc.Execute(ctx, &domain.SubmitRequest{Language: domain.LangPython, SourceCode: fn, ProblemID: "add"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	job, _ := repo.GetByID(ctx, resp.JobID)
+	want := fn + "\n\na, b = map(int, input().split())\nprint(add(a, b))\n"
+	if job.SourceCode != want || job.SolutionCode != fn {
+		t.Errorf("job source = %q, solution = %q; want the function wrapped in the harness", job.SourceCode, job.SolutionCode)
+	}
+
+	// A rerun wraps the submitted function again, not the assembled program.
+	rerun, err := uc.Rerun(ctx, resp.JobID)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if copied, _ := repo.GetByID(ctx, rerun.JobID); copied.SourceCode != want || copied.SolutionCode != fn {
+		t.Errorf("rerun source = %q, want %q", copied.SourceCode, want)
+	}
+
+	_, err = uc.Execute(ctx, &domain.SubmitRequest{Language: domain.LangGo, SourceCode: "func add(a, b int) int { return a + b }", ProblemID: "add"})
+	if !errors.Is(err, domain.ErrInvalidLanguage) {
+		t.Errorf("language without a harness: got %v, want ErrInvalidLanguage", err)
+	}
+}
+
+func TestSubmitJob_RuleViolation(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	problems := mockrepo.NewMockProblemRepository()
+	_ = problems.Create(context.Background(), &domain.Problem{
+		ProblemID: "sum",
+		TestCases: []domain.TestCase{{Input: "1 2\n", ExpectedOutput: "3\n"}},
+		Rules: []domain.SourceRule{
+			{Language: domain.LangCpp, Pattern: `#include\s*<thread>`},
+			{Language: domain.LangPython, Pattern: `^\s*(import|from)\s+(os|subprocess)\b`, Message: "os and subprocess are not allowed"},
+		},
+	})
+
+	uc := NewSubmitJobUsecase(repo, pub, testLanguages(t), zap.NewNop())
+	uc.SetProblems(problems)
+	ctx := context.Background()
+
+	resp, err := uc.Execute(ctx, &domain.SubmitRequest{
+		Language:   domain.LangPython,
+		SourceCode: "import sys\nimport os\nprint(sum(map(int, input().split())))",
+		ProblemID:  "sum",
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if resp.Status != string(domain.StatusRuleViolation) {
+		t.Errorf("status = %s, want RULE_VIOLATION", resp.Status)
+	}
+	job, _ := repo.GetByID(ctx, resp.JobID)
+	if job.Status != domain.StatusRuleViolation || job.Stderr != "rule 2, line 2: os and subprocess are not allowed" {
+		t.Errorf("job = %s %q, want the violated rule recorded", job.Status, job.Stderr)
+	}
+	if len(pub.Published) != 0 {
+		t.Error("a rule violation must not be queued")
+	}
+
+	// Rules apply to their own language only, and to whole identifiers.
+	resp, err = uc.Execute(ctx, &domain.SubmitRequest{
+		Language:   domain.LangPython,
+		SourceCode: "import osmium\nprint('#include <thread>')",
+		ProblemID:  "sum",
+	})
+	if err != nil || resp.Status != string(domain.StatusQueued) || len(pub.Published) != 1 {
+		t.Errorf("compliant submission: got %+v, %v", resp, err)
+	}
+}
+
+func TestProblem_UpdateTestDataBumpsVersion(t *testing.T) {
+	problems := mockrepo.NewMockProblemRepository()
+	uc := NewProblemUsecase(problems, mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+
+	cases := []domain.TestCase{{Input: "1 2\n", ExpectedOutput: "3\n"}}
+	p, err := uc.Create(context.Background(), &domain.CreateProblemRequest{ProblemID: "sum", TestCases: cases})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if p.TestDataVersion != 1 {
+		t.Fatalf("expected version 1, got %d", p.TestDataVersion)
+	}
+
+	cases = append(cases, domain.TestCase{Input: "-1 1\n", ExpectedOutput: "0\n"})
+	p, err = uc.UpdateTestData(context.Background(), "sum", &domain.UpdateTestDataRequest{TestCases: cases})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if p.TestDataVersion != 2 || len(p.TestCases) != 2 {
+		t.Errorf("expected version 2 with 2 cases, got version %d with %d cases", p.TestDataVersion, len(p.TestCases))
+	}
+
+	_, err = uc.UpdateTestData(context.Background(), "sum", &domain.UpdateTestDataRequest{})
+	if !errors.Is(err, domain.ErrInvalidTestData) {
+		t.Errorf("expected ErrInvalidTestData for empty cases, got %v", err)
+	}
+}
+
+func TestProblem_RejudgeRequeuesOutdatedOnly(t *testing.T) {
+	problems := mockrepo.NewMockProblemRepository()
+	jobs := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	uc := NewProblemUsecase(problems, jobs, pub, testLanguages(t), zap.NewNop())
+
+	cases := []domain.TestCase{{Input: "1\n", ExpectedOutput: "1\n"}}
+	if _, err := uc.Create(context.Background(), &domain.CreateProblemRequest{ProblemID: "echo", TestCases: cases}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := uc.UpdateTestData(context.Background(), "echo", &domain.UpdateTestDataRequest{TestCases: cases}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	v1, v2 := 1, 2
+	outdated := &domain.Job{JobID: uuid.New(), ProblemID: "echo", Status: domain.StatusWrongAnswer, TestDataVersion: &v1}
+	current := &domain.Job{JobID: uuid.New(), ProblemID: "echo", Status: domain.StatusAccepted, TestDataVersion: &v2}
+	running := &domain.Job{JobID: uuid.New(), ProblemID: "echo", Status: domain.StatusRunning}
+	for _, j := range []*domain.Job{outdated, current, running} {
+		_ = jobs.Create(context.Background(), j)
+	}
+
+	resp, err := uc.Rejudge(context.Background(), "echo")
+	if err != nil {
+		t.Fatalf("rejudge: %v", err)
+	}
+	if resp.Requeued != 1 || resp.Failed != 0 || resp.TestDataVersion != 2 {
+		t.Errorf("unexpected rejudge response: %+v", resp)
+	}
+	if len(pub.Published) != 1 || pub.Published[0].JobID != outdated.JobID {
+		t.Fatalf("expected only the outdated job to be published, got %d", len(pub.Published))
+	}
+	if pub.Published[0].Priority != domain.PriorityBulk {
+		t.Errorf("rejudge priority = %d, want %d", pub.Published[0].Priority, domain.PriorityBulk)
+	}
+	if outdated.Status != domain.StatusQueued || outdated.JudgeRevision != 1 {
+		t.Errorf("expected QUEUED with judge revision 1, got %s rev %d", outdated.Status, outdated.JudgeRevision)
+	}
+
+	if _, err := uc.Rejudge(context.Background(), "nope"); !errors.Is(err, domain.ErrProblemNotFound) {
+		t.Errorf("expected ErrProblemNotFound, got %v", err)
+	}
+}
+
+func TestProblem_RejudgeChecksRules(t *testing.T) {
+	problems := mockrepo.NewMockProblemRepository()
+	jobs := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	uc := NewProblemUsecase(problems, jobs, pub, testLanguages(t), zap.NewNop())
+
+	cases := []domain.TestCase{{Input: "1\n", ExpectedOutput: "1\n"}}
+	if _, err := uc.Create(context.Background(), &domain.CreateProblemRequest{ProblemID: "echo", TestCases: cases}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	v1 := 1
+	clean := &domain.Job{JobID: uuid.New(), ProblemID: "echo", Language: domain.LangPython, SourceCode: "print(input())", Status: domain.StatusAccepted, TestDataVersion: &v1}
+	banned := &domain.Job{JobID: uuid.New(), ProblemID: "echo", Language: domain.LangPython, SourceCode: "import os\nprint(input())", Status: domain.StatusAccepted, TestDataVersion: &v1}
+	for _, j := range []*domain.Job{clean, banned} {
+		_ = jobs.Create(context.Background(), j)
+	}
+
+	rules := []domain.SourceRule{{Language: domain.LangPython, Pattern: `^import os$`}}
+	if _, err := uc.UpdateTestData(context.Background(), "echo", &domain.UpdateTestDataRequest{TestCases: cases, Rules: rules}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	resp, err := uc.Rejudge(context.Background(), "echo")
+	if err != nil {
+		t.Fatalf("rejudge: %v", err)
+	}
+	if resp.Requeued != 1 || resp.RuleViolations != 1 || resp.Failed != 0 {
+		t.Errorf("unexpected rejudge response: %+v", resp)
+	}
+	if len(pub.Published) != 1 || pub.Published[0].JobID != clean.JobID {
+		t.Fatalf("expected only the compliant job to be published, got %d", len(pub.Published))
+	}
+	if banned.Status != domain.StatusRuleViolation || banned.Stderr == "" {
+		t.Errorf("expected RULE_VIOLATION with a message, got %s %q", banned.Status, banned.Stderr)
+	}
+}
+
+func TestProblem_SubtaskValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+
+	tests := []struct {
+		name     string
+		subtasks []domain.Subtask
+		cases    []domain.TestCase
+		wantErr  bool
+	}{
+		{
+			name:     "valid groups with ungraded sample",
+			subtasks: []domain.Subtask{{Points: 40}, {Points: 60}},
+			cases:    []domain.TestCase{{Subtask: 0}, {Subtask: 1}, {Subtask: 2}},
+		},
+		{
+			name:     "unknown subtask",
+			subtasks: []domain.Subtask{{Points: 100}},
+			cases:    []domain.TestCase{{Subtask: 2}},
+			wantErr:  true,
+		},
+		{
+			name:     "empty subtask",
+			subtasks: []domain.Subtask{{Points: 50}, {Points: 50}},
+			cases:    []domain.TestCase{{Subtask: 1}},
+			wantErr:  true,
+		},
+		{
+			name:     "negative points",
+			subtasks: []domain.Subtask{{Points: -1}},
+			cases:    []domain.TestCase{{Subtask: 1}},
+			wantErr:  true,
+		},
+		{
+			name:     "per-test scoring",
+			subtasks: []domain.Subtask{{Points: 100, Scoring: domain.ScorePerTest}},
+			cases:    []domain.TestCase{{Subtask: 1}},
+		},
+		{
+			name:     "unknown scoring",
+			subtasks: []domain.Subtask{{Points: 100, Scoring: "best_of"}},
+			cases:    []domain.TestCase{{Subtask: 1}},
+			wantErr:  true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &domain.CreateProblemRequest{
+				ProblemID: fmt.Sprintf("p%d", i),
+				TestCases: tt.cases,
+				Subtasks:  tt.subtasks,
+			}
+			p, err := uc.Create(context.Background(), req)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidTestData) {
+					t.Errorf("expected ErrInvalidTestData, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(p.Subtasks) != len(tt.subtasks) {
+				t.Errorf("expected %d subtasks stored, got %d", len(tt.subtasks), len(p.Subtasks))
+			}
+			for j, st := range p.Subtasks {
+				if !st.Scoring.IsValid() {
+					t.Errorf("subtask %d: expected a scoring mode to be stored, got %q", j+1, st.Scoring)
+				}
+			}
+		})
+	}
+}
+
+func TestProblem_CaseLimitValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+
+	intPtr := func(v int) *int { return &v }
+	tests := []struct {
+		name    string
+		tc      domain.TestCase
+		wantErr bool
+	}{
+		{name: "inherits job limits", tc: domain.TestCase{}},
+		{name: "valid overrides", tc: domain.TestCase{TimeLimitMs: intPtr(10000), MemoryLimitKB: intPtr(maxMemoryLimitKB)}},
+		{name: "zero time limit", tc: domain.TestCase{TimeLimitMs: intPtr(0)}, wantErr: true},
+		{name: "time limit above maximum", tc: domain.TestCase{TimeLimitMs: intPtr(maxTimeLimitMs + 1)}, wantErr: true},
+		{name: "negative memory limit", tc: domain.TestCase{MemoryLimitKB: intPtr(-1)}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &domain.CreateProblemRequest{
+				ProblemID: fmt.Sprintf("limits%d", i),
+				TestCases: []domain.TestCase{tt.tc},
+			}
+			_, err := uc.Create(context.Background(), req)
+			if tt.wantErr && !errors.Is(err, domain.ErrInvalidTestData) {
+				t.Errorf("expected ErrInvalidTestData, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProblem_TerminationStrategy(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	cases := []domain.TestCase{{Input: "1", ExpectedOutput: "1"}}
+
+	p, err := uc.Create(context.Background(), &domain.CreateProblemRequest{ProblemID: "default", TestCases: cases})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.TerminationStrategy != domain.TerminateRunAll {
+		t.Errorf("expected default strategy run_all, got %q", p.TerminationStrategy)
+	}
+
+	p, err = uc.Create(context.Background(), &domain.CreateProblemRequest{
+		ProblemID:           "strict",
+		TestCases:           cases,
+		TerminationStrategy: domain.TerminateFirstFailure,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.TerminationStrategy != domain.TerminateFirstFailure {
+		t.Errorf("expected first_failure, got %q", p.TerminationStrategy)
+	}
+
+	_, err = uc.Create(context.Background(), &domain.CreateProblemRequest{
+		ProblemID:           "bogus",
+		TestCases:           cases,
+		TerminationStrategy: "stop_eventually",
+	})
+	if !errors.Is(err, domain.ErrInvalidTestData) {
+		t.Errorf("expected ErrInvalidTestData for unknown strategy, got %v", err)
+	}
+}
+
+func TestProblem_GeneratorValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+
+	seed := int64(7)
+	gen := func() *domain.Generator {
+		return &domain.Generator{Language: domain.LangPython, SourceCode: "import sys\nprint(sys.argv[1])"}
+	}
+	tests := []struct {
+		name    string
+		gen     *domain.Generator
+		tc      domain.TestCase
+		wantErr bool
+	}{
+		{name: "seeded case", gen: gen(), tc: domain.TestCase{GeneratorSeed: &seed, ExpectedOutput: "7"}},
+		{name: "seed without generator", tc: domain.TestCase{GeneratorSeed: &seed}, wantErr: true},
+		{name: "seed with input", gen: gen(), tc: domain.TestCase{GeneratorSeed: &seed, Input: "7"}, wantErr: true},
+		{name: "unsupported language", gen: &domain.Generator{Language: "cobol", SourceCode: "x"}, tc: domain.TestCase{GeneratorSeed: &seed}, wantErr: true},
+		{name: "empty source", gen: &domain.Generator{Language: domain.LangPython, SourceCode: "  "}, tc: domain.TestCase{GeneratorSeed: &seed}, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := uc.Create(context.Background(), &domain.CreateProblemRequest{
+				ProblemID: fmt.Sprintf("gen%d", i),
+				TestCases: []domain.TestCase{tt.tc},
+				Generator: tt.gen,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidTestData) {
+					t.Errorf("expected ErrInvalidTestData, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(p.Generator.Hash) != 64 {
+				t.Errorf("expected a sha256 hex hash, got %q", p.Generator.Hash)
+			}
+		})
+	}
+
+	// The hash changes with the source so workers never reuse stale inputs.
+	a, b := gen(), gen()
+	b.SourceCode += "\n"
+	for _, g := range []*domain.Generator{a, b} {
+		if err := uc.validateGenerator(g); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if a.Hash == b.Hash {
+		t.Error("expected different hashes for different sources")
+	}
+}
+
+func TestProblem_ComparatorValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	cases := []domain.TestCase{{Input: "1", ExpectedOutput: "1"}}
+	module := []byte("\x00asm\x01\x00\x00\x00")
+
+	p, err := uc.Create(context.Background(), &domain.CreateProblemRequest{
+		ProblemID:  "custom",
+		TestCases:  cases,
+		Comparator: &domain.Comparator{Wasm: module},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.Comparator.Hash) != 64 {
+		t.Errorf("expected a sha256 hex hash, got %q", p.Comparator.Hash)
+	}
+	if p.Comparator.Wasm != nil {
+		t.Error("expected the module not to be echoed back")
+	}
+
+	for name, wasm := range map[string][]byte{
+		"not wasm":  []byte("#!/bin/sh\necho ok"),
+		"empty":     nil,
+		"too large": append(append([]byte{}, module...), make([]byte, maxComparatorSize)...),
+	} {
+		_, err := uc.UpdateTestData(context.Background(), "custom", &domain.UpdateTestDataRequest{
+			TestCases:  cases,
+			Comparator: &domain.Comparator{Wasm: wasm},
+		})
+		if !errors.Is(err, domain.ErrInvalidTestData) {
+			t.Errorf("%s: expected ErrInvalidTestData, got %v", name, err)
+		}
+	}
+}
+
+func TestProblem_InteractorValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	cases := []domain.TestCase{{Input: "42"}}
+	inter := &domain.Interactor{Language: domain.LangPython, SourceCode: "import sys\nprint(sys.stdin.readline())"}
+
+	p, err := uc.Create(context.Background(), &domain.CreateProblemRequest{
+		ProblemID:  "guess",
+		TestCases:  cases,
+		Interactor: inter,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Interactor == nil || p.Interactor.Language != domain.LangPython {
+		t.Errorf("expected the interactor to be stored, got %+v", p.Interactor)
+	}
+
+	for name, req := range map[string]*domain.UpdateTestDataRequest{
+		"unsupported language": {TestCases: cases, Interactor: &domain.Interactor{Language: "cobol", SourceCode: "x"}},
+		"empty source":         {TestCases: cases, Interactor: &domain.Interactor{Language: domain.LangPython, SourceCode: " "}},
+		"with comparator": {
+			TestCases:  cases,
+			Interactor: inter,
+			Comparator: &domain.Comparator{Wasm: []byte("\x00asm\x01\x00\x00\x00")},
+		},
+	} {
+		_, err := uc.UpdateTestData(context.Background(), "guess", req)
+		if !errors.Is(err, domain.ErrInvalidTestData) {
+			t.Errorf("%s: expected ErrInvalidTestData, got %v", name, err)
+		}
+	}
+}
+
+func TestProblem_HarnessValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	cases := []domain.TestCase{{Input: "1 2\n", ExpectedOutput: "3\n"}}
+	harness := domain.Harness{Language: domain.LangPython, Template: "{{solution}}\nprint(add(*map(int, input().split())))"}
+
+	p, err := uc.Create(context.Background(), &domain.CreateProblemRequest{
+		ProblemID: "add",
+		TestCases: cases,
+		Harnesses: []domain.Harness{harness},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h, ok := p.HarnessFor(domain.LangPython); !ok || h.Template != harness.Template {
+		t.Errorf("expected the harness to be stored, got %+v", p.Harnesses)
+	}
+
+	for name, harnesses := range map[string][]domain.Harness{
+		"unsupported language": {{Language: "cobol", Template: "{{solution}}"}},
+		"no placeholder":       {{Language: domain.LangPython, Template: "print(add(1, 2))"}},
+		"two placeholders":     {{Language: domain.LangPython, Template: "{{solution}}\n{{solution}}"}},
+		"duplicate language":   {harness, harness},
+	} {
+		_, err := uc.UpdateTestData(context.Background(), "add", &domain.UpdateTestDataRequest{TestCases: cases, Harnesses: harnesses})
+		if !errors.Is(err, domain.ErrInvalidTestData) {
+			t.Errorf("%s: expected ErrInvalidTestData, got %v", name, err)
+		}
+	}
+}
+
+func TestProblem_RuleValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	cases := []domain.TestCase{{Input: "1\n", ExpectedOutput: "1\n"}}
+	if _, err := uc.Create(context.Background(), &domain.CreateProblemRequest{ProblemID: "echo", TestCases: cases}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	tooMany := make([]domain.SourceRule, maxRules+1)
+	for i := range tooMany {
+		tooMany[i] = domain.SourceRule{Language: domain.LangPython, Pattern: "x"}
+	}
+	for name, rules := range map[string][]domain.SourceRule{
+		"unsupported language": {{Language: "cobol", Pattern: "x"}},
+		"empty pattern":        {{Language: domain.LangPython}},
+		"invalid pattern":      {{Language: domain.LangPython, Pattern: "(import"}},
+		"long message":         {{Language: domain.LangPython, Pattern: "x", Message: strings.Repeat("m", maxRuleMessageLength+1)}},
+		"too many":             tooMany,
+	} {
+		_, err := uc.UpdateTestData(context.Background(), "echo", &domain.UpdateTestDataRequest{TestCases: cases, Rules: rules})
+		if !errors.Is(err, domain.ErrInvalidTestData) {
+			t.Errorf("%s: expected ErrInvalidTestData, got %v", name, err)
+		}
+	}
+}
+
+func TestProblem_KillPatternValidation(t *testing.T) {
+	uc := NewProblemUsecase(mockrepo.NewMockProblemRepository(), mockrepo.NewMockJobRepository(), mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	cases := []domain.TestCase{{Input: "1\n", ExpectedOutput: "1\n"}}
+	if _, err := uc.Create(context.Background(), &domain.CreateProblemRequest{ProblemID: "echo", TestCases: cases}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	valid := []domain.KillPattern{{Pattern: `(?i)ignore previous instructions`}, {Pattern: ".", Repeats: 10000}}
+	problem, err := uc.UpdateTestData(context.Background(), "echo", &domain.UpdateTestDataRequest{TestCases: cases, KillPatterns: valid})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if len(problem.KillPatterns) != 2 {
+		t.Errorf("kill patterns = %+v, want 2", problem.KillPatterns)
+	}
+
+	for name, patterns := range map[string][]domain.KillPattern{
+		"empty pattern":    {{}},
+		"invalid pattern":  {{Pattern: "(ignore"}},
+		"negative repeats": {{Pattern: "x", Repeats: -1}},
+		"too many repeats": {{Pattern: "x", Repeats: maxKillPatternRepeats + 1}},
+		"long message":     {{Pattern: "x", Message: strings.Repeat("m", maxRuleMessageLength+1)}},
+		"too many":         make([]domain.KillPattern, maxKillPatterns+1),
+	} {
+		_, err := uc.UpdateTestData(context.Background(), "echo", &domain.UpdateTestDataRequest{TestCases: cases, KillPatterns: patterns})
+		if !errors.Is(err, domain.ErrInvalidTestData) {
+			t.Errorf("%s: expected ErrInvalidTestData, got %v", name, err)
+		}
+	}
+}
+
+// judgedJob stores a finished submission against a scored problem.
+func judgedJob(t *testing.T, jobs *mockrepo.MockJobRepository, status domain.ExecutionStatus) *domain.Job {
+	t.Helper()
+	score, max := 40.0, 100.0
+	job := &domain.Job{
+		JobID:     uuid.New(),
+		Language:  domain.LangPython,
+		Status:    status,
+		ProblemID: "sum",
+		Score:     &score,
+		MaxScore:  &max,
+	}
+	if err := jobs.Create(context.Background(), job); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	return job
+}
+
+func TestAppeal_OpenRequiresFinishedJudgedSubmission(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	uc := NewAppealUsecase(mockrepo.NewMockAppealRepository(jobs), jobs, mockpub.NewMockPublisher(), zap.NewNop())
+	ctx := context.Background()
+	note := &domain.OpenAppealRequest{Note: "case 3 accepts either order"}
+
+	running := judgedJob(t, jobs, domain.StatusRunning)
+	if _, err := uc.Open(ctx, running.JobID, "", note); !errors.Is(err, domain.ErrNotAppealable) {
+		t.Errorf("expected ErrNotAppealable for a running job, got %v", err)
+	}
+	plain := &domain.Job{JobID: uuid.New(), Status: domain.StatusSuccess}
+	_ = jobs.Create(ctx, plain)
+	if _, err := uc.Open(ctx, plain.JobID, "", note); !errors.Is(err, domain.ErrNotAppealable) {
+		t.Errorf("expected ErrNotAppealable for an unjudged job, got %v", err)
+	}
+	if _, err := uc.Open(ctx, uuid.New(), "", note); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+
+	job := judgedJob(t, jobs, domain.StatusWrongAnswer)
+	if _, err := uc.Open(ctx, job.JobID, "", &domain.OpenAppealRequest{Note: "  "}); !errors.Is(err, domain.ErrInvalidAppeal) {
+		t.Errorf("expected ErrInvalidAppeal for a blank note, got %v", err)
+	}
+	appeal, err := uc.Open(ctx, job.JobID, "acme", note)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if appeal.Status != domain.AppealOpen || appeal.TenantID != "acme" {
+		t.Errorf("unexpected appeal: %+v", appeal)
+	}
+	if _, err := uc.Open(ctx, job.JobID, "acme", note); !errors.Is(err, domain.ErrAppealExists) {
+		t.Errorf("expected ErrAppealExists for a second open appeal, got %v", err)
+	}
+
+	// Tenants only see their own appeals; admins see every tenant's.
+	if _, err := uc.Get(ctx, appeal.AppealID, "globex"); !errors.Is(err, domain.ErrAppealNotFound) {
+		t.Errorf("another tenant's get: expected ErrAppealNotFound, got %v", err)
+	}
+	for _, tenant := range []string{"acme", ""} {
+		if _, err := uc.Get(ctx, appeal.AppealID, tenant); err != nil {
+			t.Errorf("get as %q: %v", tenant, err)
+		}
+	}
+	for tenant, want := range map[string]int{"acme": 1, "globex": 0, "": 1} {
+		if open, err := uc.List(ctx, tenant, domain.AppealOpen); err != nil || len(open) != want {
+			t.Errorf("list as %q = %d appeals (%v), want %d", tenant, len(open), err, want)
+		}
+	}
+}
+
+func TestAppeal_RerunOverrideClose(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	uc := NewAppealUsecase(mockrepo.NewMockAppealRepository(jobs), jobs, pub, zap.NewNop())
+	ctx := context.Background()
+
+	job := judgedJob(t, jobs, domain.StatusWrongAnswer)
+	appeal, err := uc.Open(ctx, job.JobID, "", &domain.OpenAppealRequest{Note: "checker is too strict"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	if _, err := uc.Rerun(ctx, appeal.AppealID, ""); !errors.Is(err, domain.ErrInvalidAppeal) {
+		t.Errorf("expected ErrInvalidAppeal without an actor, got %v", err)
+	}
+	rerun, err := uc.Rerun(ctx, appeal.AppealID, "judge-1")
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if rerun.JudgeRevision != 1 || len(pub.Published) != 1 || !pub.Published[0].Debug {
+		t.Fatalf("expected one debug rerun at revision 1, got %+v and %d published", rerun, len(pub.Published))
+	}
+
+	// The rerun finished with the same verdict; the admin overrides it.
+	_ = jobs.UpdateStatus(ctx, job.JobID, domain.StatusWrongAnswer)
+	score := 100.0
+	bad := []*domain.OverrideVerdictRequest{
+		{Status: domain.StatusQueued, Reason: "x"},
+		{Status: domain.StatusAccepted, Reason: ""},
+		{Status: domain.StatusAccepted, Reason: "x", Score: func() *float64 { s := 101.0; return &s }()},
+	}
+	for _, req := range bad {
+		if _, err := uc.Override(ctx, appeal.AppealID, "judge-1", req); !errors.Is(err, domain.ErrInvalidAppeal) {
+			t.Errorf("expected ErrInvalidAppeal for %+v, got %v", req, err)
+		}
+	}
+	got, err := uc.Override(ctx, appeal.AppealID, "judge-1", &domain.OverrideVerdictRequest{
+		Status: domain.StatusAccepted, Score: &score, Reason: "output order is unspecified",
+	})
+	if err != nil {
+		t.Fatalf("override: %v", err)
+	}
+	if len(got.Overrides) != 1 {
+		t.Fatalf("expected one override in the audit trail, got %d", len(got.Overrides))
+	}
+	o := got.Overrides[0]
+	if o.PreviousStatus != domain.StatusWrongAnswer || o.Status != domain.StatusAccepted || *o.PreviousScore != 40 || *o.Score != 100 || o.Actor != "judge-1" {
+		t.Errorf("unexpected override record: %+v", o)
+	}
+	if stored, _ := jobs.GetByID(ctx, job.JobID); stored.Status != domain.StatusAccepted || *stored.Score != 100 {
+		t.Errorf("expected the override applied to the job, got %s %v", stored.Status, *stored.Score)
+	}
+
+	closed, err := uc.Close(ctx, appeal.AppealID, "judge-1", &domain.CloseAppealRequest{Response: "Verdict corrected."})
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if closed.Status != domain.AppealClosed || closed.Response != "Verdict corrected." || closed.ClosedBy != "judge-1" {
+		t.Errorf("unexpected closed appeal: %+v", closed)
+	}
+	if _, err := uc.Rerun(ctx, appeal.AppealID, "judge-1"); !errors.Is(err, domain.ErrAppealClosed) {
+		t.Errorf("expected ErrAppealClosed after closing, got %v", err)
+	}
+
+	open, err := uc.List(ctx, "", domain.AppealOpen)
+	if err != nil || len(open) != 0 {
+		t.Errorf("expected no open appeals, got %d (%v)", len(open), err)
+	}
+}
+
+func luaRuntime() *domain.CreateRuntimeRequest {
+	return &domain.CreateRuntimeRequest{
+		Name: "lua",
+		RuntimeSpec: domain.RuntimeSpec{
+			Version:      "5.4",
+			SourceFile:   "main.lua",
+			NsjailConfig: "python.cfg",
+			Run:          []string{"/opt/lua/bin/lua", "{source}"},
+			Mounts:       []domain.Mount{{Source: "/opt/runtimes/lua", Target: "/opt/lua"}},
+			Env:          map[string]string{"LUA_PATH": "/opt/lua/share/?.lua"},
+		},
+	}
+}
+
+func TestRuntime_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *domain.CreateRuntimeRequest)
+	}{
+		{"built-in name", func(req *domain.CreateRuntimeRequest) { req.Name = domain.LangPython }},
+		{"bad name", func(req *domain.CreateRuntimeRequest) { req.Name = "Lua 5" }},
+		{"source path", func(req *domain.CreateRuntimeRequest) { req.SourceFile = "../main.lua" }},
+		{"source shadows stdin", func(req *domain.CreateRuntimeRequest) { req.SourceFile = "stdin.txt" }},
+		{"profile path", func(req *domain.CreateRuntimeRequest) { req.NsjailConfig = "/etc/nsjail/python.cfg" }},
+		{"no run command", func(req *domain.CreateRuntimeRequest) { req.Run = nil }},
+		{"relative mount", func(req *domain.CreateRuntimeRequest) { req.Mounts[0].Source = "opt/lua" }},
+		{"unclean mount", func(req *domain.CreateRuntimeRequest) { req.Mounts[0].Target = "/opt/../etc" }},
+		{"mount over work dir", func(req *domain.CreateRuntimeRequest) { req.Mounts[0].Target = "/tmp/work/lib" }},
+		{"mount outside roots", func(req *domain.CreateRuntimeRequest) { req.Mounts[0].Source = "/etc" }},
+		{"mount beside root", func(req *domain.CreateRuntimeRequest) { req.Mounts[0].Source = "/opt/runtimes-lua" }},
+		{"mount above root", func(req *domain.CreateRuntimeRequest) { req.Mounts[0].Source = "/opt" }},
+		{"bad env name", func(req *domain.CreateRuntimeRequest) { req.Env = map[string]string{"A=B": "1"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewRuntimeUsecase(mockrepo.NewMockRuntimeRepository(), testLanguages(t), zap.NewNop())
+			uc.SetMountRoots([]string{"/opt/runtimes/"})
+			req := luaRuntime()
+			tt.modify(req)
+			if _, err := uc.Create(context.Background(), req); !errors.Is(err, domain.ErrInvalidRuntime) {
+				t.Errorf("expected ErrInvalidRuntime, got %v", err)
+			}
+		})
+	}
+
+	uc := NewRuntimeUsecase(mockrepo.NewMockRuntimeRepository(), testLanguages(t), zap.NewNop())
+	if _, err := uc.Create(context.Background(), luaRuntime()); !errors.Is(err, domain.ErrInvalidRuntime) {
+		t.Errorf("mount without mount roots: expected ErrInvalidRuntime, got %v", err)
+	}
+	uc.SetMountRoots([]string{"/srv/toolchains", "/opt/runtimes"})
+	if _, err := uc.Create(context.Background(), luaRuntime()); err != nil {
+		t.Fatalf("valid runtime rejected: %v", err)
+	}
+	if _, err := uc.Create(context.Background(), luaRuntime()); !errors.Is(err, domain.ErrRuntimeExists) {
+		t.Errorf("duplicate runtime: expected ErrRuntimeExists, got %v", err)
+	}
+	spec := luaRuntime().RuntimeSpec
+	spec.Mounts[0].Source = "/"
+	if _, err := uc.Update(context.Background(), "lua", &spec); !errors.Is(err, domain.ErrInvalidRuntime) {
+		t.Errorf("update mounting the host root: expected ErrInvalidRuntime, got %v", err)
+	}
+}
+
+func TestAPIKey_IssueRotateRevoke(t *testing.T) {
+	ctx := context.Background()
+	uc := NewAPIKeyUsecase(mockrepo.NewMockAPIKeyRepository(), zap.NewNop())
+
+	for _, req := range []domain.IssueAPIKeyRequest{
+		{TenantID: ""},
+		{TenantID: " acme"},
+		{TenantID: strings.Repeat("a", maxAPIKeyTenantLen+1)},
+		{TenantID: "acme", Name: strings.Repeat("n", maxAPIKeyNameLen+1)},
+		{TenantID: "acme", Scopes: []string{domain.ScopeSubmit, "write:all"}},
+	} {
+		if _, err := uc.Issue(ctx, &req); !errors.Is(err, domain.ErrInvalidAPIKeyRequest) {
+			t.Errorf("Issue(%q, %d-byte name): expected ErrInvalidAPIKeyRequest, got %v", req.TenantID, len(req.Name), err)
+		}
+	}
+
+	issued, err := uc.Issue(ctx, &domain.IssueAPIKeyRequest{TenantID: "acme", Name: "ci"})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !strings.HasPrefix(issued.Key, apiKeyScheme) || !strings.HasPrefix(issued.Key, issued.Prefix) {
+		t.Errorf("key %q does not start with %q and its prefix %q", issued.Key, apiKeyScheme, issued.Prefix)
+	}
+	key, err := uc.Authenticate(ctx, issued.Key)
+	if err != nil || key.KeyID != issued.KeyID || key.TenantID != "acme" || !slices.Equal(key.Scopes, domain.DefaultAPIKeyScopes) {
+		t.Fatalf("Authenticate = %+v, %v", key, err)
+	}
+	scoped, err := NewAPIKeyUsecase(mockrepo.NewMockAPIKeyRepository(), zap.NewNop()).Issue(ctx, &domain.IssueAPIKeyRequest{TenantID: "acme", Scopes: []string{"read:any", "submit", "read:any"}})
+	if err != nil || !slices.Equal(scoped.Scopes, []string{domain.ScopeReadAny, domain.ScopeSubmit}) {
+		t.Errorf("Issue with scopes = %+v, %v", scoped, err)
+	}
+	for _, secret := range []string{"", "acme", issued.Key + "0", apiKeyScheme + "00"} {
+		if _, err := uc.Authenticate(ctx, secret); !errors.Is(err, domain.ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q): expected ErrInvalidAPIKey, got %v", secret, err)
+		}
+	}
+
+	// Rotating replaces the key at once, under the same ID.
+	rotated, err := uc.Rotate(ctx, issued.KeyID)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if rotated.KeyID != issued.KeyID || rotated.Key == issued.Key || rotated.RotatedAt == nil {
+		t.Errorf("rotated = %+v", rotated)
+	}
+	if _, err := uc.Authenticate(ctx, issued.Key); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("old key after rotation: expected ErrInvalidAPIKey, got %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, rotated.Key); err != nil {
+		t.Errorf("new key after rotation: %v", err)
+	}
+
+	if err := uc.Revoke(ctx, issued.KeyID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := uc.Revoke(ctx, issued.KeyID); err != nil {
+		t.Errorf("second Revoke: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, rotated.Key); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("revoked key: expected ErrInvalidAPIKey, got %v", err)
+	}
+	if _, err := uc.Rotate(ctx, issued.KeyID); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("rotate revoked key: expected ErrAPIKeyNotFound, got %v", err)
+	}
+	if err := uc.Revoke(ctx, uuid.New()); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("revoke unknown key: expected ErrAPIKeyNotFound, got %v", err)
+	}
+
+	other, _ := uc.Issue(ctx, &domain.IssueAPIKeyRequest{TenantID: "globex"})
+	if keys, err := uc.List(ctx, "acme"); err != nil || len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("List(acme) = %v, %v; want the revoked key", keys, err)
+	}
+	if keys, _ := uc.List(ctx, ""); len(keys) != 2 || keys[1].KeyID != other.KeyID {
+		t.Errorf("List() = %v, want both keys, oldest first", keys)
+	}
+}
+
+func TestUser_RegisterLoginAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte(strings.Repeat("k", 32))
+	if _, err := NewUserUsecase(mockrepo.NewMockUserRepository(), secret[:16], time.Hour, zap.NewNop()); err == nil {
+		t.Error("short secret: expected an error")
+	}
+	uc, err := NewUserUsecase(mockrepo.NewMockUserRepository(), secret, time.Hour, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewUserUsecase: %v", err)
+	}
+
+	for _, req := range []domain.RegisterUserRequest{
+		{Email: "ada", Password: "correct horse"},
+		{Email: "Ada <ada@example.com>", Password: "correct horse"},
+		{Email: "ada@example.com", Password: "short"},
+		{Email: "ada@example.com", Password: strings.Repeat("p", maxPasswordLen+1)},
+	} {
+		if _, err := uc.Register(ctx, "acme", &req); !errors.Is(err, domain.ErrInvalidUserRequest) {
+			t.Errorf("Register(%q, %d-byte password): expected ErrInvalidUserRequest, got %v", req.Email, len(req.Password), err)
+		}
+	}
+
+	user, err := uc.Register(ctx, "", &domain.RegisterUserRequest{Email: "Ada@Example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if user.Email != "ada@example.com" || user.TenantID != domain.DefaultTenantID {
+		t.Errorf("user = %+v, want a lowercased email in the default tenant", user)
+	}
+	if _, err := uc.Register(ctx, "acme", &domain.RegisterUserRequest{Email: "ada@example.com", Password: "another one"}); !errors.Is(err, domain.ErrUserExists) {
+		t.Errorf("second Register: expected ErrUserExists, got %v", err)
+	}
+
+	for _, req := range []domain.LoginRequest{
+		{Email: "ada@example.com", Password: "wrong horse"},
+		{Email: "eve@example.com", Password: "correct horse"},
+		{Email: "not an email", Password: "correct horse"},
+	} {
+		if _, err := uc.Login(ctx, &req); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Errorf("Login(%q): expected ErrInvalidCredentials, got %v", req.Email, err)
+		}
+	}
+	token, err := uc.Login(ctx, &domain.LoginRequest{Email: "ADA@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if token.User.UserID != user.UserID || time.Until(token.ExpiresAt) > time.Hour {
+		t.Errorf("token = %+v", token)
+	}
+
+	got, err := uc.Authenticate(ctx, token.Token)
+	if err != nil || got.UserID != user.UserID || got.TenantID != user.TenantID {
+		t.Fatalf("Authenticate = %+v, %v", got, err)
+	}
+	other, _ := NewUserUsecase(mockrepo.NewMockUserRepository(), []byte(strings.Repeat("o", 32)), time.Hour, zap.NewNop())
+	if _, err := other.Authenticate(ctx, token.Token); !errors.Is(err, domain.ErrInvalidUserToken) {
+		t.Errorf("token from another secret: expected ErrInvalidUserToken, got %v", err)
+	}
+}
+
+func TestQueuePause_HoldsAndReleasesOneLanguage(t *testing.T) {
+	ctx := context.Background()
+	pub := mockpub.NewMockPublisher()
+	uc := NewQueuePauseUsecase(mockrepo.NewMockQueuePauseStore(), pub, zap.NewNop())
+
+	for _, lang := range []domain.Language{"", "C++", "../cpp"} {
+		if _, err := uc.Pause(ctx, lang); !errors.Is(err, domain.ErrInvalidLanguage) {
+			t.Errorf("Pause(%q): expected ErrInvalidLanguage, got %v", lang, err)
+		}
+	}
+
+	first, err := uc.Pause(ctx, domain.LangCpp)
+	if err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	pub.Held = map[domain.Language]int{domain.LangCpp: 3}
+	again, err := uc.Pause(ctx, domain.LangCpp)
+	if err != nil || !again.PausedAt.Equal(first.PausedAt) || again.Held != 3 {
+		t.Errorf("second Pause = %+v, %v; want the first pause's time and 3 held", again, err)
+	}
+	if _, err := uc.Pause(ctx, "lua"); err != nil {
+		t.Errorf("pausing a runtime: %v", err)
+	}
+	pauses, err := uc.List(ctx)
+	if err != nil || len(pauses) != 2 || pauses[0].Language != domain.LangCpp || pauses[1].Language != "lua" {
+		t.Fatalf("List = %v, %v", pauses, err)
+	}
+
+	resume, err := uc.Resume(ctx, domain.LangCpp)
+	if err != nil || resume.Released != 3 {
+		t.Fatalf("Resume = %+v, %v; want 3 released", resume, err)
+	}
+	if resume, err := uc.Resume(ctx, domain.LangCpp); err != nil || resume.Released != 0 {
+		t.Errorf("second Resume = %+v, %v", resume, err)
+	}
+	if pauses, _ := uc.List(ctx); len(pauses) != 1 || pauses[0].Language != "lua" {
+		t.Errorf("List after resume = %v, want only lua", pauses)
+	}
+}
+
+func TestSubmitJob_RegisteredRuntime(t *testing.T) {
+	ctx := context.Background()
+	runtimes := mockrepo.NewMockRuntimeRepository()
+	runtimeUC := NewRuntimeUsecase(runtimes, testLanguages(t), zap.NewNop())
+	runtimeUC.SetMountRoots([]string{"/opt/runtimes"})
+
+	repo := mockrepo.NewMockJobRepository()
+	uc := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	uc.SetRuntimes(runtimes)
+
+	req := &domain.SubmitRequest{Language: "lua", SourceCode: "print(1)"}
+	if _, err := uc.Execute(ctx, req); !errors.Is(err, domain.ErrInvalidLanguage) {
+		t.Fatalf("unregistered runtime: expected ErrInvalidLanguage, got %v", err)
+	}
+
+	if _, err := runtimeUC.Create(ctx, luaRuntime()); err != nil {
+		t.Fatalf("create runtime: %v", err)
+	}
+	if _, err := uc.Execute(ctx, req); err != nil {
+		t.Fatalf("registered runtime rejected: %v", err)
+	}
+
+	if err := runtimeUC.Delete(ctx, "lua"); err != nil {
+		t.Fatalf("delete runtime: %v", err)
+	}
+	if _, err := uc.Execute(ctx, req); !errors.Is(err, domain.ErrInvalidLanguage) {
+		t.Errorf("deleted runtime: expected ErrInvalidLanguage, got %v", err)
+	}
+}
+
+func TestSubmitJob_SourceNormalization(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	uc := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	uc.SetSourcePolicy(SourcePolicy{MaxLines: 3, MaxLineLength: 10})
+
+	resp, err := uc.Execute(context.Background(), &domain.SubmitRequest{
+		Language:   domain.LangPython,
+		SourceCode: "\uFEFFx = 1\r\nprint(x)\rpass\n",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	job, _ := repo.GetByID(context.Background(), resp.JobID)
+	if want := "x = 1\nprint(x)\npass\n"; job.SourceCode != want {
+		t.Errorf("expected normalized source %q, got %q", want, job.SourceCode)
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"nul byte", "print(1)\nx = '\x00'\n", "line 2"},
+		{"too many lines", "a\nb\nc\nd\n", "4 lines"},
+		{"long line", "print(1)\nprint(12345678)\n", "line 2 is 15 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Execute(context.Background(), &domain.SubmitRequest{Language: domain.LangPython, SourceCode: tt.source})
+			if !errors.Is(err, domain.ErrInvalidSource) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected ErrInvalidSource mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestHardening_ReportsEachProtection(t *testing.T) {
+	repo := mockrepo.NewMockJobRepository()
+	submitUC := NewSubmitJobUsecase(repo, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	uc := NewHardeningUsecase(submitUC, NewGetJobUsecase(repo, zap.NewNop()), zap.NewNop())
+	uc.timeout = 50 * time.Millisecond
+
+	// Workers run the probes: the network and user probes get past the
+	// sandbox, the memory probe is killed, the pids probe never starts and
+	// the processes probe never finishes.
+	outcomes := map[string]domain.Job{
+		"ptrace":     {Status: domain.StatusSuccess, Stdout: "ATTEMPT\nPROTECTED ptrace failed with errno 1\n"},
+		"socket":     {Status: domain.StatusSuccess, Stdout: "ATTEMPT\nEXPOSED connected to 1.1.1.1:53\n"},
+		"bytearray":  {Status: domain.StatusMemoryLimitExceeded, Stdout: "ATTEMPT\n"},
+		"os.fork":    {Status: domain.StatusRuntimeError},
+		"/usr/":      {Status: domain.StatusSuccess, Stdout: "ATTEMPT\nPROTECTED [Errno 30] Read-only file system\n"},
+		"geteuid":    {Status: domain.StatusSuccess, Stdout: "ATTEMPT\nEXPOSED running as root\n"},
+		"os.listdir": {Status: domain.StatusRunning},
+	}
+	repo.GetByIDFunc = func(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+		for _, job := range repo.GetAll() {
+			if job.JobID != id {
+				continue
+			}
+			for marker, outcome := range outcomes {
+				if strings.Contains(job.SourceCode, marker) {
+					outcome.JobID = id
+					return &outcome, nil
+				}
+			}
+			t.Fatalf("no outcome for probe %q", job.SourceCode)
+		}
+		return nil, domain.ErrJobNotFound
+	}
+
+	report, err := uc.Run(context.Background(), &domain.HardeningRequest{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if report.Hardened || report.TenantID != domain.DefaultTenantID || len(report.Checks) != len(hardeningProbes) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	want := map[string]domain.HardeningResult{
+		"seccomp":    domain.HardeningActive,
+		"network":    domain.HardeningMissing,
+		"memory":     domain.HardeningActive,
+		"pids":       domain.HardeningInconclusive,
+		"filesystem": domain.HardeningActive,
+		"user":       domain.HardeningMissing,
+		"processes":  domain.HardeningInconclusive,
+	}
+	for _, check := range report.Checks {
+		if check.Result != want[check.Name] {
+			t.Errorf("%s: result %s (%s), want %s", check.Name, check.Result, check.Detail, want[check.Name])
+		}
+	}
+	if jobs := repo.GetAll(); len(jobs) != len(hardeningProbes) || jobs[0].Priority != domain.MaxPriority {
+		t.Errorf("expected every probe queued at the top priority, got %d jobs", len(jobs))
+	}
+
+	if _, err := uc.Run(context.Background(), &domain.HardeningRequest{SandboxTier: "nope"}); !errors.Is(err, domain.ErrInvalidSandboxTier) {
+		t.Errorf("expected ErrInvalidSandboxTier, got %v", err)
+	}
+}
+
+func TestRepair_RecoversStuckJobsIdempotently(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	locks := mockrepo.NewMockLockStore()
+	pub := mockpub.NewMockPublisher()
+	uc := NewRepairUsecase(jobs, locks, pub, zap.NewNop())
+	ctx := context.Background()
+	hourAgo := time.Now().Add(-time.Hour)
+
+	seed := func(status domain.ExecutionStatus, updatedAt time.Time) *domain.Job {
+		job := &domain.Job{JobID: uuid.New(), Status: status}
+		if err := jobs.Create(ctx, job); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		job.UpdatedAt = updatedAt
+		return job
+	}
+	crashed := seed(domain.StatusRunning, hourAgo) // its worker died holding the lock
+	locks.SetLock(crashed.LockID(), hourAgo)
+	running := seed(domain.StatusRunning, hourAgo) // lock still fresh
+	locks.SetLock(running.LockID(), time.Now())
+	lost := seed(domain.StatusQueued, hourAgo) // its message never arrived
+	seed(domain.StatusQueued, time.Now())
+	seed(domain.StatusSuccess, hourAgo)
+
+	all := []domain.RepairAction{domain.RepairRequeueStuck, domain.RepairReconcileRunning, domain.RepairPurgeLocks}
+	outcomes := func(r *domain.RepairReport) map[domain.RepairAction]map[uuid.UUID]domain.RepairOutcome {
+		m := make(map[domain.RepairAction]map[uuid.UUID]domain.RepairOutcome)
+		for _, a := range r.Actions {
+			m[a.Action] = make(map[uuid.UUID]domain.RepairOutcome)
+			for _, j := range a.Jobs {
+				m[a.Action][j.JobID] = j.Outcome
+			}
+		}
+		return m
+	}
+
+	dry, err := uc.Repair(ctx, &domain.RepairRequest{Actions: all, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if got := []domain.RepairAction{dry.Actions[0].Action, dry.Actions[1].Action, dry.Actions[2].Action}; fmt.Sprint(got) != fmt.Sprint(domain.RepairActions) {
+		t.Errorf("actions ran in order %v, want %v", got, domain.RepairActions)
+	}
+	o := outcomes(dry)
+	if o[domain.RepairPurgeLocks][crashed.JobID] != domain.RepairOutcomeWouldPurge || len(o[domain.RepairPurgeLocks]) != 1 {
+		t.Errorf("dry purge_locks = %v", o[domain.RepairPurgeLocks])
+	}
+	if o[domain.RepairReconcileRunning][running.JobID] != domain.RepairOutcomeLocked {
+		t.Errorf("dry reconcile_running = %v", o[domain.RepairReconcileRunning])
+	}
+	if o[domain.RepairRequeueStuck][lost.JobID] != domain.RepairOutcomeWouldRequeue || len(o[domain.RepairRequeueStuck]) != 1 {
+		t.Errorf("dry requeue_stuck = %v", o[domain.RepairRequeueStuck])
+	}
+	if len(pub.Published) != 0 || !locks.Held(crashed.LockID()) || crashed.Status != domain.StatusRunning {
+		t.Fatal("dry run changed state")
+	}
+
+	report, err := uc.Repair(ctx, &domain.RepairRequest{Actions: all})
+	if err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	o = outcomes(report)
+	if o[domain.RepairPurgeLocks][crashed.JobID] != domain.RepairOutcomePurged {
+		t.Errorf("purge_locks = %v", o[domain.RepairPurgeLocks])
+	}
+	if o[domain.RepairReconcileRunning][crashed.JobID] != domain.RepairOutcomeRequeued ||
+		o[domain.RepairReconcileRunning][running.JobID] != domain.RepairOutcomeLocked {
+		t.Errorf("reconcile_running = %v", o[domain.RepairReconcileRunning])
+	}
+	if o[domain.RepairRequeueStuck][lost.JobID] != domain.RepairOutcomeRequeued {
+		t.Errorf("requeue_stuck = %v", o[domain.RepairRequeueStuck])
+	}
+	if crashed.Status != domain.StatusQueued || running.Status != domain.StatusRunning {
+		t.Errorf("statuses = %s, %s; want QUEUED, RUNNING", crashed.Status, running.Status)
+	}
+	if len(pub.Published) != 2 {
+		t.Errorf("published %d jobs, want 2", len(pub.Published))
+	}
+
+	again, err := uc.Repair(ctx, &domain.RepairRequest{Actions: all})
+	if err != nil {
+		t.Fatalf("second repair: %v", err)
+	}
+	for _, a := range again.Actions {
+		if a.Changed != 0 {
+			t.Errorf("second run changed %d jobs in %s", a.Changed, a.Action)
+		}
+	}
+	if len(pub.Published) != 2 {
+		t.Errorf("second run published again: %d jobs", len(pub.Published))
+	}
+
+	for _, req := range []domain.RepairRequest{
+		{},
+		{Actions: []domain.RepairAction{"vacuum"}},
+		{Actions: []domain.RepairAction{domain.RepairPurgeLocks, domain.RepairPurgeLocks}},
+		{Actions: all, OlderThanSeconds: 5},
+		{Actions: all, Limit: 5000},
+	} {
+		if _, err := uc.Repair(ctx, &req); !errors.Is(err, domain.ErrInvalidRepair) {
+			t.Errorf("Repair(%+v) = %v, want ErrInvalidRepair", req, err)
+		}
+	}
+}
+
+func TestConsistency_ReportsAndRepublishesLostJobs(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	locks := mockrepo.NewMockLockStore()
+	pub := mockpub.NewMockPublisher()
+	checker := NewConsistencyChecker(jobs, locks, pub, pub, 5*time.Minute, zap.NewNop())
+	ctx := context.Background()
+	hourAgo := time.Now().Add(-time.Hour)
+
+	seed := func(status domain.ExecutionStatus, updatedAt time.Time) *domain.Job {
+		job := &domain.Job{JobID: uuid.New(), Status: status}
+		if err := jobs.Create(ctx, job); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		job.UpdatedAt = updatedAt
+		return job
+	}
+	lost := seed(domain.StatusQueued, hourAgo)
+	taken := seed(domain.StatusQueued, hourAgo) // a worker holds it
+	locks.SetLock(taken.LockID(), time.Now())
+	seed(domain.StatusQueued, time.Now()) // within the grace period
+	seed(domain.StatusRunning, hourAgo)
+
+	pub.Ready = 3
+	report, err := checker.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if !report.Backlogged || len(report.Lost) != 0 {
+		t.Errorf("with a backlog: %+v, want only Backlogged", report)
+	}
+
+	pub.Ready = 0
+	report, err = checker.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(report.Lost) != 1 || report.Lost[0] != lost.JobID || report.Republished != 0 || len(pub.Published) != 0 {
+		t.Errorf("report-only check: %+v, published %d", report, len(pub.Published))
+	}
+
+	checker.SetAutoRepair(true)
+	report, err = checker.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if report.Republished != 1 || len(pub.Published) != 1 || pub.Published[0].JobID != lost.JobID {
+		t.Errorf("auto-repair check: %+v, published %v", report, pub.Published)
+	}
+	// The republish touched the job, so it is not lost again right away.
+	if report, _ = checker.Check(ctx); len(report.Lost) != 0 {
+		t.Errorf("second check found %v lost", report.Lost)
+	}
+
+	pub.ReadyMessagesFn = func(ctx context.Context) (int, error) { return 0, errors.New("channel closed") }
+	if _, err := checker.Check(ctx); err == nil {
+		t.Error("expected queue inspection failure to be returned")
+	}
+}
+
+// signGitHub signs a webhook payload the way GitHub does.
+func signGitHub(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGitHub_SetRepoValidation(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	submit := NewSubmitJobUsecase(jobs, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	uc := NewGitHubUsecase(mockrepo.NewMockGitHubRepository(jobs), submit, mockgh.NewMockClient(), "", zap.NewNop())
+	ctx := context.Background()
+
+	valid := func() *domain.SetGitHubRepoRequest {
+		return &domain.SetGitHubRepoRequest{Language: domain.LangPython, Path: "src/main.py", Token: "ghp_token"}
+	}
+	tests := []struct {
+		name    string
+		repo    string
+		mutate  func(*domain.SetGitHubRepoRequest)
+		wantErr error
+	}{
+		{"valid", "octo/hello", func(*domain.SetGitHubRepoRequest) {}, nil},
+		{"no owner", "hello", func(*domain.SetGitHubRepoRequest) {}, domain.ErrInvalidGitHubRepo},
+		{"unknown language", "octo/hello", func(r *domain.SetGitHubRepoRequest) { r.Language = "cobol" }, domain.ErrInvalidLanguage},
+		{"absolute path", "octo/hello", func(r *domain.SetGitHubRepoRequest) { r.Path = "/etc/passwd" }, domain.ErrInvalidGitHubRepo},
+		{"escaping path", "octo/hello", func(r *domain.SetGitHubRepoRequest) { r.Path = "src/../../x" }, domain.ErrInvalidGitHubRepo},
+		{"short secret", "octo/hello", func(r *domain.SetGitHubRepoRequest) { r.Secret = "short" }, domain.ErrInvalidGitHubRepo},
+		{"unknown problem", "octo/hello", func(r *domain.SetGitHubRepoRequest) { r.ProblemID = "missing" }, domain.ErrProblemNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.mutate(req)
+			repo, err := uc.SetRepo(ctx, "acme", tt.repo, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetRepo: got %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(repo.Secret) != 64 {
+				t.Errorf("generated secret %q, want 64 hex characters", repo.Secret)
+			}
+		})
+	}
+
+	if _, err := uc.SetRepo(ctx, "other", "octo/hello", valid()); !errors.Is(err, domain.ErrGitHubRepoTaken) {
+		t.Errorf("mapping another tenant's repository: got %v, want ErrGitHubRepoTaken", err)
+	}
+	if _, err := uc.GetRepo(ctx, "other", "octo/hello"); !errors.Is(err, domain.ErrGitHubRepoNotFound) {
+		t.Errorf("reading another tenant's mapping: got %v, want ErrGitHubRepoNotFound", err)
+	}
+	got, err := uc.GetRepo(ctx, "acme", "octo/hello")
+	if err != nil || got.Secret != "" {
+		t.Errorf("GetRepo = %+v, %v; want the mapping without its secret", got, err)
+	}
+}
+
+func TestGitHub_PushSubmitsAndReportsVerdict(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	pub := mockpub.NewMockPublisher()
+	submit := NewSubmitJobUsecase(jobs, pub, testLanguages(t), zap.NewNop())
+	repos := mockrepo.NewMockGitHubRepository(jobs)
+	client := mockgh.NewMockClient()
+	uc := NewGitHubUsecase(repos, submit, client, "https://sentinel.example/", zap.NewNop())
+	ctx := context.Background()
+
+	const secret = "0123456789abcdef0123456789abcdef"
+	if _, err := uc.SetRepo(ctx, "acme", "octo/hello", &domain.SetGitHubRepoRequest{
+		Language: domain.LangPython, Path: "main.py", Secret: secret, Token: "ghp_token",
+	}); err != nil {
+		t.Fatalf("SetRepo: %v", err)
+	}
+	const sha = "6dcb09b5b57875f334f61aebed695e2e4193db5e"
+	client.Files["main.py@"+sha] = "print(42)"
+	body := `{"repository":{"full_name":"octo/hello"},"after":"` + sha + `"}`
+
+	if _, err := uc.HandleEvent(ctx, "push", signGitHub("wrong secret, wrong secret", body), []byte(body)); !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Fatalf("badly signed delivery: got %v, want ErrInvalidSignature", err)
+	}
+	unmapped := `{"repository":{"full_name":"octo/other"},"after":"` + sha + `"}`
+	if _, err := uc.HandleEvent(ctx, "push", signGitHub(secret, unmapped), []byte(unmapped)); !errors.Is(err, domain.ErrGitHubRepoNotFound) {
+		t.Fatalf("unmapped repository: got %v, want ErrGitHubRepoNotFound", err)
+	}
+	ping := `{"repository":{"full_name":"octo/hello"},"zen":"Keep it simple."}`
+	if d, err := uc.HandleEvent(ctx, "ping", signGitHub(secret, ping), []byte(ping)); err != nil || d.JobID != nil {
+		t.Fatalf("ping: %+v, %v; want it ignored", d, err)
+	}
+
+	delivery, err := uc.HandleEvent(ctx, "push", signGitHub(secret, body), []byte(body))
+	if err != nil {
+		t.Fatalf("HandleEvent: %v", err)
+	}
+	if delivery.JobID == nil || delivery.SHA != sha {
+		t.Fatalf("delivery = %+v, want a job for %s", delivery, sha)
+	}
+	job, err := jobs.GetByID(ctx, *delivery.JobID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if job.SourceCode != "print(42)" || job.TenantID != "acme" || job.Language != domain.LangPython {
+		t.Errorf("submitted job = %+v, want the committed source for tenant acme", job)
+	}
+	posted := client.Posted()
+	if len(posted) != 1 || posted[0].Status.State != github.StatePending || posted[0].SHA != sha {
+		t.Fatalf("statuses after delivery = %+v, want one pending", posted)
+	}
+	wantURL := "https://sentinel.example/api/v2/submissions/" + delivery.JobID.String()
+	if posted[0].Status.TargetURL != wantURL {
+		t.Errorf("target URL = %q, want %q", posted[0].Status.TargetURL, wantURL)
+	}
+
+	// Nothing is reported while the job runs.
+	if n, err := uc.Report(ctx); err != nil || n != 0 {
+		t.Fatalf("Report before the verdict = %d, %v; want 0", n, err)
+	}
+	if err := jobs.SetResult(ctx, job.JobID, &domain.Job{Status: domain.StatusWrongAnswer}); err != nil {
+		t.Fatalf("SetResult: %v", err)
+	}
+	if n, err := uc.Report(ctx); err != nil || n != 1 {
+		t.Fatalf("Report = %d, %v; want 1", n, err)
+	}
+	posted = client.Posted()
+	if last := posted[len(posted)-1].Status; last.State != github.StateFailure || last.Description != "WRONG_ANSWER" {
+		t.Errorf("verdict status = %+v, want failure WRONG_ANSWER", last)
+	}
+	if len(repos.Checks()) != 0 {
+		t.Error("check kept after its status was posted")
+	}
+
+	// A commit without the mapped file gets an error status and no job.
+	const missing = "1111111111111111111111111111111111111111"
+	body = `{"repository":{"full_name":"octo/hello"},"action":"opened","pull_request":{"head":{"sha":"` + missing + `"}}}`
+	delivery, err = uc.HandleEvent(ctx, "pull_request", signGitHub(secret, body), []byte(body))
+	if err != nil || delivery.JobID != nil {
+		t.Fatalf("delivery without the file = %+v, %v; want it ignored", delivery, err)
+	}
+	posted = client.Posted()
+	if last := posted[len(posted)-1]; last.SHA != missing || last.Status.State != github.StateError {
+		t.Errorf("status for a commit without the file = %+v, want error", last)
+	}
+}
+
+func TestLTI_LaunchSubmitsAndPostsScore(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	submit := NewSubmitJobUsecase(jobs, mockpub.NewMockPublisher(), testLanguages(t), zap.NewNop())
+	problems := mockrepo.NewMockProblemRepository()
+	submit.SetProblems(problems)
+	repo := mockrepo.NewMockLTIRepository(jobs)
+	client := mocklti.NewMockClient()
+	toolKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	platformKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	uc := NewLTIUsecase(repo, mockrepo.NewMockLTILoginStore(), submit, client, toolKey,
+		"https://sentinel.example/", "https://app.sentinel.example/lti", time.Hour, zap.NewNop())
+	ctx := context.Background()
+
+	if err := problems.Create(ctx, &domain.Problem{ProblemID: "two-sum", Title: "Two Sum"}); err != nil {
+		t.Fatalf("Create problem: %v", err)
+	}
+	req := &domain.RegisterLTIPlatformRequest{
+		Issuer:        "https://lms.example.edu",
+		ClientID:      "tool-1",
+		DeploymentID:  "d-1",
+		AuthLoginURL:  "https://lms.example.edu/auth",
+		TokenURL:      "https://lms.example.edu/token",
+		JWKSURL:       "ftp://lms.example.edu/jwks",
+		TenantMapping: domain.LTITenantContext,
+	}
+	if _, err := uc.RegisterPlatform(ctx, "acme", req); !errors.Is(err, domain.ErrInvalidLTIPlatform) {
+		t.Fatalf("non-HTTP key set URL: got %v, want ErrInvalidLTIPlatform", err)
+	}
+	req.JWKSURL = "https://lms.example.edu/jwks"
+	platform, err := uc.RegisterPlatform(ctx, "acme", req)
+	if err != nil {
+		t.Fatalf("RegisterPlatform: %v", err)
+	}
+	if _, err := uc.RegisterPlatform(ctx, "other", req); !errors.Is(err, domain.ErrLTIPlatformTaken) {
+		t.Fatalf("duplicate registration: got %v, want ErrLTIPlatformTaken", err)
+	}
+	client.KeySets[req.JWKSURL] = lti.KeySet{lti.KeyID(&platformKey.PublicKey): &platformKey.PublicKey}
+
+	// login runs the OIDC login and returns the state and nonce the
+	// platform is asked to echo.
+	login := func() (string, string) {
+		t.Helper()
+		redirect, err := uc.Login(ctx, &LTILoginRequest{Issuer: req.Issuer, ClientID: req.ClientID, LoginHint: "u-1"})
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		u, err := url.Parse(redirect)
+		if err != nil {
+			t.Fatalf("parse redirect: %v", err)
+		}
+		q := u.Query()
+		if u.Host != "lms.example.edu" || q.Get("redirect_uri") != "https://sentinel.example/api/v2/integrations/lti/launch" || q.Get("login_hint") != "u-1" {
+			t.Fatalf("login redirect = %s", redirect)
+		}
+		return q.Get("state"), q.Get("nonce")
+	}
+	idToken := func(key *rsa.PrivateKey, nonce string) string {
+		t.Helper()
+		token, err := lti.Sign(map[string]any{
+			"iss":   req.Issuer,
+			"sub":   "u-1",
+			"aud":   req.ClientID,
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": nonce,
+			"name":  "Ada",
+			"https://purl.imsglobal.org/spec/lti/claim/message_type":  "LtiResourceLinkRequest",
+			"https://purl.imsglobal.org/spec/lti/claim/version":       "1.3.0",
+			"https://purl.imsglobal.org/spec/lti/claim/deployment_id": "d-1",
+			"https://purl.imsglobal.org/spec/lti/claim/context":       map[string]any{"id": "course-1"},
+			"https://purl.imsglobal.org/spec/lti/claim/custom":        map[string]any{"problem_id": "two-sum"},
+			"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint": map[string]any{
+				"scope":    []string{lti.ScopeScore},
+				"lineitem": "https://lms.example.edu/line_items/7",
+			},
+		}, key)
+		if err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		return token
+	}
+
+	if _, err := uc.Login(ctx, &LTILoginRequest{Issuer: req.Issuer, ClientID: "tool-2", LoginHint: "u-1"}); !errors.Is(err, domain.ErrLTIPlatformNotFound) {
+		t.Fatalf("login for an unregistered client: got %v, want ErrLTIPlatformNotFound", err)
+	}
+	state, nonce := login()
+	if _, err := uc.Launch(ctx, idToken(toolKey, nonce), state); !errors.Is(err, domain.ErrInvalidLTILaunch) {
+		t.Fatalf("token signed by another key: got %v, want ErrInvalidLTILaunch", err)
+	}
+	state, nonce = login()
+	token := idToken(platformKey, nonce)
+	launch, err := uc.Launch(ctx, token, state)
+	if err != nil {
+		t.Fatalf("Launch: %v", err)
+	}
+	if launch.TenantID != "acme:course-1" || launch.ProblemID != "two-sum" || !launch.Graded {
+		t.Errorf("launch = %+v, want a graded launch of two-sum in tenant acme:course-1", launch)
+	}
+	if _, err := uc.Launch(ctx, token, state); !errors.Is(err, domain.ErrInvalidLTILaunch) {
+		t.Fatalf("replayed launch: got %v, want ErrInvalidLTILaunch", err)
+	}
+	if got := uc.AppURL(launch); got != "https://app.sentinel.example/lti?launch="+launch.ID.String() {
+		t.Errorf("AppURL = %q", got)
+	}
+
+	resp, err := uc.Submit(ctx, launch.ID, &domain.LTISubmitRequest{Language: domain.LangPython, SourceCode: "print(42)"})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	job, err := jobs.GetByID(ctx, resp.JobID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if job.TenantID != "acme:course-1" || job.ProblemID != "two-sum" {
+		t.Errorf("submitted job = %+v, want two-sum for tenant acme:course-1", job)
+	}
+	if _, err := uc.Submit(ctx, uuid.New(), &domain.LTISubmitRequest{Language: domain.LangPython, SourceCode: "print(42)"}); !errors.Is(err, domain.ErrLTILaunchNotFound) {
+		t.Errorf("unknown launch: got %v, want ErrLTILaunchNotFound", err)
+	}
+
+	// Nothing is posted while the job runs.
+	if n, err := uc.Report(ctx); err != nil || n != 0 {
+		t.Fatalf("Report before the verdict = %d, %v; want 0", n, err)
+	}
+	score, maxScore := 3.0, 4.0
+	if err := jobs.SetResult(ctx, job.JobID, &domain.Job{Status: domain.StatusWrongAnswer, Score: &score, MaxScore: &maxScore}); err != nil {
+		t.Fatalf("SetResult: %v", err)
+	}
+	if n, err := uc.Report(ctx); err != nil || n != 1 {
+		t.Fatalf("Report = %d, %v; want 1", n, err)
+	}
+	posted := client.Posted()
+	if len(posted) != 1 || posted[0].LineItem != "https://lms.example.edu/line_items/7" || posted[0].TokenURL != req.TokenURL {
+		t.Fatalf("posted = %+v, want one score on the launch's line item", posted)
+	}
+	if s := posted[0].Score; s.UserID != "u-1" || s.ScoreGiven != 3 || s.ScoreMaximum != 4 || s.GradingProgress != "FullyGraded" {
+		t.Errorf("score = %+v, want 3/4 for u-1", s)
+	}
+	if repo.PendingScores() != 0 {
+		t.Error("score kept after it was posted")
+	}
+
+	users, err := uc.ListUsers(ctx, "acme", platform.ID)
+	if err != nil || len(users) != 1 || users[0].TenantID != "acme:course-1" || users[0].Name != "Ada" {
+		t.Errorf("users = %+v, %v; want Ada mapped to acme:course-1", users, err)
+	}
+	if _, err := uc.ListUsers(ctx, "other", platform.ID); !errors.Is(err, domain.ErrLTIPlatformNotFound) {
+		t.Errorf("another tenant's platform: got %v, want ErrLTIPlatformNotFound", err)
+	}
+}
+
+func TestExport_ResumesFromCursorAndRetriesFailedBatches(t *testing.T) {
+	repo := mockrepo.NewMockExportRepository()
+	sink := mockwh.NewMockSink()
+	uc := NewExportUsecase(repo, sink, "salt", 2, zap.NewNop())
+	ctx := context.Background()
+
+	settled := time.Now().Add(-time.Hour)
+	for id := int64(1); id <= 3; id++ {
+		repo.AddFact(&domain.JobFact{EventID: id, JobID: uuid.New(), Status: domain.StatusAccepted, FinishedAt: settled})
+	}
+	// A verdict this recent may still have stragglers behind it.
+	repo.AddFact(&domain.JobFact{EventID: 4, JobID: uuid.New(), Status: domain.StatusAccepted, FinishedAt: time.Now()})
+
+	if n, err := uc.Export(ctx); err != nil || n != 3 {
+		t.Fatalf("Export = %d, %v; want 3 settled facts", n, err)
+	}
+	if cursor, _ := repo.GetCursor(ctx, "mock"); cursor != 3 {
+		t.Errorf("cursor = %d, want 3", cursor)
+	}
+	if n, err := uc.Export(ctx); err != nil || n != 0 {
+		t.Fatalf("second Export = %d, %v; want nothing new", n, err)
+	}
+
+	repo.AddFact(&domain.JobFact{EventID: 5, JobID: uuid.New(), Status: domain.StatusWrongAnswer, FinishedAt: settled})
+	sink.WriteFn = func(ctx context.Context, facts []*domain.JobFact) error { return errors.New("warehouse down") }
+	if _, err := uc.Export(ctx); err == nil {
+		t.Fatal("Export with a failing sink succeeded")
+	}
+	if cursor, _ := repo.GetCursor(ctx, "mock"); cursor != 3 {
+		t.Errorf("cursor after a failed write = %d, want it unmoved", cursor)
+	}
+	sink.WriteFn = nil
+	if n, err := uc.Export(ctx); err != nil || n != 1 {
+		t.Fatalf("Export after recovery = %d, %v; want the failed fact", n, err)
+	}
+	written := sink.Written()
+	if len(written) != 4 || written[3].EventID != 5 {
+		t.Errorf("written = %d facts, last %+v", len(written), written[len(written)-1])
+	}
+}
+
+func TestResultDispatcher_RetriesAndOpensCircuit(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	hooks := mockrepo.NewMockWebhookRepository()
+	sender := mockhook.NewMockSender()
+	ctx := context.Background()
+	now := time.Now().Add(time.Second)
+
+	webhookUC := NewWebhookUsecase(hooks, zap.NewNop())
+	if _, err := webhookUC.SetResultWebhook(ctx, "acme", &domain.SetResultWebhookRequest{URL: "https://hooks.example.com/results"}); err != nil {
+		t.Fatalf("set result webhook: %v", err)
+	}
+	finish := func() *domain.WebhookDelivery {
+		job := &domain.Job{JobID: uuid.New(), TenantID: "acme", Language: domain.LangPython, Status: domain.StatusAccepted}
+		_ = jobs.Create(ctx, job)
+		return hooks.RecordDelivery(job)
+	}
+	policy := DeliveryPolicy{MaxAttempts: 2, Backoff: time.Minute, Timeout: time.Second, BreakerThreshold: 2, BreakerCooldown: time.Hour}
+	d := NewResultDispatcher(hooks, jobs, sender, policy, zap.NewNop())
+
+	first := finish()
+	if sent, err := d.Tick(ctx, now); err != nil || sent != 1 {
+		t.Fatalf("tick: sent %d, %v; want 1", sent, err)
+	}
+	got, _ := webhookUC.GetDelivery(ctx, "acme", first.ID)
+	if got.Status != domain.DeliveryDelivered || len(got.History) != 1 || got.History[0].StatusCode != 200 {
+		t.Errorf("expected one accepted attempt, got %+v", got)
+	}
+	var event domain.ResultEvent
+	if err := json.Unmarshal(sender.Events()[0].Body, &event); err != nil || event.Event != domain.ResultEventType || event.Job.JobID != first.JobID {
+		t.Errorf("unexpected event %s: %v", sender.Events()[0].Body, err)
+	}
+
+	// Two failures in a row open the circuit, holding the fourth delivery
+	// back without spending one of its attempts.
+	sender.SendFn = func(ctx context.Context, url, secret string, id int64, body []byte) (int, error) {
+		return 503, errors.New("webhook responded 503 Service Unavailable")
+	}
+	second, third, fourth := finish(), finish(), finish()
+	if sent, _ := d.Tick(ctx, now); sent != 0 {
+		t.Errorf("expected nothing accepted, got %d", sent)
+	}
+	hook, _ := webhookUC.GetResultWebhook(ctx, "acme")
+	if hook.ConsecutiveFailures != 2 || hook.CircuitOpenUntil == nil {
+		t.Fatalf("expected the circuit open, got %+v", hook)
+	}
+	got, _ = webhookUC.GetDelivery(ctx, "acme", second.ID)
+	if got.Status != domain.DeliveryPending || got.Attempts != 1 || got.LastError == "" {
+		t.Errorf("second delivery: %+v", got)
+	}
+	got, _ = webhookUC.GetDelivery(ctx, "acme", fourth.ID)
+	if got.Attempts != 0 || !got.NextAttemptAt.Equal(*hook.CircuitOpenUntil) {
+		t.Errorf("expected the fourth delivery deferred until the circuit closes, got %+v", got)
+	}
+	if _, _ = d.Tick(ctx, now.Add(30*time.Minute)); len(sender.Events()) != 3 {
+		t.Errorf("expected no attempts while the circuit is open, got %d events", len(sender.Events()))
+	}
+
+	// After the cooldown the second delivery's last attempt is the probe:
+	// it fails the delivery for good and opens the circuit again. The
+	// recovered endpoint then gets the rest, and a redrive sends the second
+	// again.
+	later := hook.CircuitOpenUntil.Add(time.Minute)
+	_, _ = d.Tick(ctx, later)
+	got, _ = webhookUC.GetDelivery(ctx, "acme", second.ID)
+	if got.Status != domain.DeliveryFailed || len(got.History) != 2 {
+		t.Errorf("expected the second delivery failed after 2 attempts, got %+v", got)
+	}
+	if got, _ := webhookUC.GetDelivery(ctx, "acme", third.ID); got.Attempts != 1 {
+		t.Errorf("expected the third delivery held back by the failed probe, got %+v", got)
+	}
+	sender.SendFn = nil
+	later = later.Add(2 * time.Hour)
+	if sent, _ := d.Tick(ctx, later); sent != 2 {
+		t.Errorf("expected the third and fourth deliveries accepted, got %d", sent)
+	}
+	if hook, _ := webhookUC.GetResultWebhook(ctx, "acme"); hook.ConsecutiveFailures != 0 || hook.CircuitOpenUntil != nil {
+		t.Errorf("expected the circuit closed, got %+v", hook)
+	}
+
+	if _, err := webhookUC.RedriveDelivery(ctx, "acme", third.ID); err != nil {
+		t.Errorf("redrive a delivered delivery: %v", err)
+	}
+	if _, err := webhookUC.RedriveDelivery(ctx, "acme", third.ID); !errors.Is(err, domain.ErrDeliveryPending) {
+		t.Errorf("redrive a pending delivery: got %v, want ErrDeliveryPending", err)
+	}
+	if _, err := webhookUC.RedriveDelivery(ctx, "other", second.ID); !errors.Is(err, domain.ErrDeliveryNotFound) {
+		t.Errorf("redrive another tenant's delivery: got %v, want ErrDeliveryNotFound", err)
+	}
+	redriven, err := webhookUC.RedriveDelivery(ctx, "acme", second.ID)
+	if err != nil || redriven.Status != domain.DeliveryPending || redriven.Attempts != 0 || redriven.Redrives != 1 {
+		t.Fatalf("redrive: %+v, %v", redriven, err)
+	}
+	if sent, _ := d.Tick(ctx, later.Add(time.Second)); sent != 2 {
+		t.Errorf("expected both redriven deliveries accepted, got %d", sent)
+	}
+
+	page, err := webhookUC.ListDeliveries(ctx, "acme", domain.DeliveryDelivered, 0, 2)
+	if err != nil || len(page.Deliveries) != 2 || page.Deliveries[0].ID != fourth.ID || page.NextBefore != third.ID {
+		t.Errorf("list: %+v, %v", page, err)
+	}
+	if _, err := webhookUC.ListDeliveries(ctx, "acme", "lost", 0, 0); !errors.Is(err, domain.ErrInvalidDeliveryFilter) {
+		t.Errorf("unknown status: got %v, want ErrInvalidDeliveryFilter", err)
+	}
+}
+
+func TestResultDispatcher_RefusedDeliveryFailsAtOnce(t *testing.T) {
+	jobs := mockrepo.NewMockJobRepository()
+	hooks := mockrepo.NewMockWebhookRepository()
+	sender := mockhook.NewMockSender()
+	sender.SendFn = func(ctx context.Context, url, secret string, id int64, body []byte) (int, error) {
+		return 410, errors.New("webhook responded 410 Gone")
+	}
+	ctx := context.Background()
+
+	_ = hooks.PutResultWebhook(ctx, &domain.ResultWebhook{TenantID: "acme", URL: "https://hooks.example.com", Secret: "0123456789abcdef"})
+	job := &domain.Job{JobID: uuid.New(), TenantID: "acme", Language: domain.LangPython, Status: domain.StatusWrongAnswer}
+	_ = jobs.Create(ctx, job)
+	delivery := hooks.RecordDelivery(job)
+
+	policy := DeliveryPolicy{MaxAttempts: 5, Backoff: time.Minute, Timeout: time.Second, BreakerThreshold: 5, BreakerCooldown: time.Hour}
+	_, _ = NewResultDispatcher(hooks, jobs, sender, policy, zap.NewNop()).Tick(ctx, time.Now())
+	got, _ := hooks.GetDelivery(ctx, "acme", delivery.ID)
+	if got.Status != domain.DeliveryFailed || got.Attempts != 1 || got.History[0].StatusCode != 410 {
+		t.Errorf("expected the delivery failed on a 410, got %+v", got)
+	}
+}
+
+func TestSecurityEvents_PagesSettledEvents(t *testing.T) {
+	repo := mockrepo.NewMockSecurityEventRepository()
+	uc := NewSecurityEventUsecase(repo, zap.NewNop())
+	ctx := context.Background()
+
+	settled := time.Now().Add(-time.Hour)
+	for _, kind := range []domain.SecurityEventKind{domain.SecuritySeccompViolation, domain.SecurityAbuseFlag, domain.SecuritySeccompViolation} {
+		repo.AddEvent(&domain.SecurityEvent{JobID: uuid.New(), TenantID: "acme", Kind: kind, CreatedAt: settled})
+	}
+	// An event this recent may still have stragglers behind it.
+	repo.AddEvent(&domain.SecurityEvent{JobID: uuid.New(), TenantID: "acme", Kind: domain.SecurityQuarantine, CreatedAt: time.Now()})
+
+	for _, tt := range []struct {
+		kind   domain.SecurityEventKind
+		cursor string
+		limit  int
+	}{{"bogus", "", 0}, {"", "x", 0}, {"", "-1", 0}, {"", "", maxListLimit + 1}} {
+		if _, err := uc.List(ctx, domain.SecurityEventFilter{Kind: tt.kind}, tt.cursor, tt.limit); !errors.Is(err, domain.ErrInvalidSecurityEventFilter) {
+			t.Errorf("List(%q, %q, %d) = %v, want ErrInvalidSecurityEventFilter", tt.kind, tt.cursor, tt.limit, err)
+		}
+	}
+
+	page, err := uc.List(ctx, domain.SecurityEventFilter{}, "", 2)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page.Events) != 2 || !page.HasMore || page.NextCursor != "2" {
+		t.Fatalf("first page = %d events, has_more %v, cursor %q; want 2, true, \"2\"", len(page.Events), page.HasMore, page.NextCursor)
+	}
+	page, err = uc.List(ctx, domain.SecurityEventFilter{}, page.NextCursor, 2)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page.Events) != 1 || page.Events[0].EventID != 3 || page.HasMore || page.NextCursor != "3" {
+		t.Fatalf("second page = %+v; want the last settled event", page)
+	}
+	// Polling past the end keeps the position.
+	page, err = uc.List(ctx, domain.SecurityEventFilter{}, page.NextCursor, 2)
+	if err != nil || len(page.Events) != 0 || page.NextCursor != "3" {
+		t.Fatalf("empty page = %+v, %v; want cursor \"3\" kept", page, err)
+	}
+
+	page, err = uc.List(ctx, domain.SecurityEventFilter{Kind: domain.SecuritySeccompViolation}, "", 0)
+	if err != nil || len(page.Events) != 2 {
+		t.Fatalf("seccomp events = %+v, %v; want 2", page, err)
+	}
+	page, err = uc.List(ctx, domain.SecurityEventFilter{TenantID: "other"}, "", 0)
+	if err != nil || len(page.Events) != 0 {
+		t.Errorf("other tenant's events = %+v, %v; want none", page, err)
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/auth"
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	maxEmailLen    = 254
+	minPasswordLen = 8
+	// maxPasswordLen is as much as bcrypt reads of a password.
+	maxPasswordLen = 72
+
+	// userTokenType is the scheme clients send user tokens with.
+	userTokenType = "Bearer"
+)
+
+// UserUsecase registers users, logs them in with their password, and
+// authenticates requests by the tokens it issues. Tokens are stateless:
+// one stays valid until it expires.
+type UserUsecase struct {
+	users    repository.UserRepository
+	secret   []byte
+	tokenTTL time.Duration
+	logger   *zap.Logger
+
+	// dummyHash is compared against on logins with an unknown email, so
+	// they take as long as logins with a wrong password.
+	dummyOnce sync.Once
+	dummyHash []byte
+}
+
+// NewUserUsecase creates a UserUsecase signing tokens with secret, at least
+// auth.MinSecretLen bytes, that are valid for tokenTTL.
+func NewUserUsecase(users repository.UserRepository, secret []byte, tokenTTL time.Duration, logger *zap.Logger) (*UserUsecase, error) {
+	if len(secret) < auth.MinSecretLen {
+		return nil, fmt.Errorf("user token secret must be at least %d bytes", auth.MinSecretLen)
+	}
+	if tokenTTL <= 0 {
+		return nil, fmt.Errorf("user token lifetime must be positive")
+	}
+	return &UserUsecase{
+		users:    users,
+		secret:   secret,
+		tokenTTL: tokenTTL,
+		logger:   logger,
+	}, nil
+}
+
+// Register creates a user in the tenant, the default tenant if empty.
+func (uc *UserUsecase) Register(ctx context.Context, tenantID string, req *domain.RegisterUserRequest) (*domain.User, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Password) < minPasswordLen || len(req.Password) > maxPasswordLen {
+		return nil, fmt.Errorf("%w: password must be %d-%d bytes", domain.ErrInvalidUserRequest, minPasswordLen, maxPasswordLen)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("generate UUIDv7: %w", err)
+	}
+
+	user := &domain.User{UserID: id, TenantID: tenantOrDefault(tenantID), Email: email}
+	if err := uc.users.Create(ctx, user, hash); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("User registered", zap.String("user_id", id.String()), zap.String("tenant_id", user.TenantID))
+	return user, nil
+}
+
+// Login returns a token for the user with the email and password, or
+// domain.ErrInvalidCredentials without telling which of them is wrong.
+func (uc *UserUsecase) Login(ctx context.Context, req *domain.LoginRequest) (*domain.UserToken, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	user, hash, err := uc.users.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword(uc.dummy(), []byte(req.Password))
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(req.Password)) != nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	expires := now.Add(uc.tokenTTL)
+	token, err := auth.Sign(auth.Claims{
+		Subject:   user.UserID.String(),
+		Tenant:    user.TenantID,
+		Email:     user.Email,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expires.Unix(),
+	}, uc.secret)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UserToken{Token: token, TokenType: userTokenType, ExpiresAt: time.Unix(expires.Unix(), 0).UTC(), User: *user}, nil
+}
+
+// Authenticate returns the user a token was issued to, or
+// domain.ErrInvalidUserToken if it is forged or expired. CreatedAt is
+// left zero; the token does not carry it.
+func (uc *UserUsecase) Authenticate(ctx context.Context, token string) (*domain.User, error) {
+	claims, err := auth.Verify(token, uc.secret, time.Now())
+	if err != nil {
+		return nil, domain.ErrInvalidUserToken
+	}
+	id, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, domain.ErrInvalidUserToken
+	}
+	return &domain.User{UserID: id, TenantID: claims.Tenant, Email: claims.Email}, nil
+}
+
+func (uc *UserUsecase) dummy() []byte {
+	uc.dummyOnce.Do(func() {
+		uc.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	})
+	return uc.dummyHash
+}
+
+// normalizeEmail lowercases a bare email address, so logins do not depend
+// on case.
+func normalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > maxEmailLen {
+		return "", fmt.Errorf("%w: email must be a bare address of at most %d bytes", domain.ErrInvalidUserRequest, maxEmailLen)
+	}
+	return strings.ToLower(email), nil
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/repository"
+)
+
+const (
+	maxWebhookURLLen    = 2048
+	minWebhookSecretLen = 16
+	maxWebhookSecretLen = 256
+)
+
+// WebhookUsecase manages the endpoints tenants are notified on and the
+// deliveries owed to their result webhooks. Workers read dead-letter webhooks
+// directly from the database; results are sent by a ResultDispatcher.
+type WebhookUsecase struct {
+	webhooks repository.WebhookRepository
+	logger   *zap.Logger
+}
+
+// NewWebhookUsecase creates a new WebhookUsecase.
+func NewWebhookUsecase(webhooks repository.WebhookRepository, logger *zap.Logger) *WebhookUsecase {
+	return &WebhookUsecase{
+		webhooks: webhooks,
+		logger:   logger,
+	}
+}
+
+// SetDLQWebhook validates and stores a tenant's dead-letter webhook,
+// replacing any previous one. The returned webhook carries the secret, which
+// is generated when the request has none.
+func (uc *WebhookUsecase) SetDLQWebhook(ctx context.Context, tenantID string, req *domain.SetDLQWebhookRequest) (*domain.DLQWebhook, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	secret, err := webhookSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &domain.DLQWebhook{TenantID: tenantOrDefault(tenantID), URL: req.URL, Secret: secret}
+	if err := uc.webhooks.PutDLQWebhook(ctx, hook); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("DLQ webhook set", zap.String("tenant_id", hook.TenantID))
+	return hook, nil
+}
+
+// GetDLQWebhook returns a tenant's dead-letter webhook without its secret.
+func (uc *WebhookUsecase) GetDLQWebhook(ctx context.Context, tenantID string) (*domain.DLQWebhook, error) {
+	hook, err := uc.webhooks.GetDLQWebhook(ctx, tenantOrDefault(tenantID))
+	if err != nil {
+		return nil, err
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+// DeleteDLQWebhook stops dead-letter notifications for a tenant.
+func (uc *WebhookUsecase) DeleteDLQWebhook(ctx context.Context, tenantID string) error {
+	return uc.webhooks.DeleteDLQWebhook(ctx, tenantOrDefault(tenantID))
+}
+
+// SetResultWebhook validates and stores a tenant's result webhook, replacing
+// any previous one and closing its circuit. The returned webhook carries the
+// secret, which is generated when the request has none.
+func (uc *WebhookUsecase) SetResultWebhook(ctx context.Context, tenantID string, req *domain.SetResultWebhookRequest) (*domain.ResultWebhook, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	secret, err := webhookSecret(req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &domain.ResultWebhook{TenantID: tenantOrDefault(tenantID), URL: req.URL, Secret: secret}
+	if err := uc.webhooks.PutResultWebhook(ctx, hook); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Result webhook set", zap.String("tenant_id", hook.TenantID))
+	return hook, nil
+}
+
+// GetResultWebhook returns a tenant's result webhook without its secret.
+func (uc *WebhookUsecase) GetResultWebhook(ctx context.Context, tenantID string) (*domain.ResultWebhook, error) {
+	hook, err := uc.webhooks.GetResultWebhook(ctx, tenantOrDefault(tenantID))
+	if err != nil {
+		return nil, err
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+// DeleteResultWebhook stops result deliveries for a tenant. Its pending
+// deliveries fail; they can be redriven once a webhook is set again.
+func (uc *WebhookUsecase) DeleteResultWebhook(ctx context.Context, tenantID string) error {
+	return uc.webhooks.DeleteResultWebhook(ctx, tenantOrDefault(tenantID))
+}
+
+// ListDeliveries returns a page of a tenant's result deliveries, newest
+// first, only those in status unless it is empty. A zero before starts at
+// the newest delivery, and a zero limit selects the default.
+func (uc *WebhookUsecase) ListDeliveries(ctx context.Context, tenantID string, status domain.DeliveryStatus, before int64, limit int) (*domain.DeliveryPage, error) {
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryFailed:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidDeliveryFilter, status)
+	}
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 1 || limit > maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be 1-%d", domain.ErrInvalidDeliveryFilter, maxListLimit)
+	}
+	if before < 0 {
+		return nil, fmt.Errorf("%w: malformed before", domain.ErrInvalidDeliveryFilter)
+	}
+
+	deliveries, err := uc.webhooks.ListDeliveries(ctx, tenantOrDefault(tenantID), status, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	page := &domain.DeliveryPage{Deliveries: deliveries}
+	if page.Deliveries == nil {
+		page.Deliveries = []*domain.WebhookDelivery{}
+	}
+	if len(deliveries) == limit {
+		page.NextBefore = deliveries[len(deliveries)-1].ID
+	}
+	return page, nil
+}
+
+// GetDelivery returns one of a tenant's result deliveries with every
+// attempt made.
+func (uc *WebhookUsecase) GetDelivery(ctx context.Context, tenantID string, id int64) (*domain.WebhookDelivery, error) {
+	return uc.webhooks.GetDelivery(ctx, tenantOrDefault(tenantID), id)
+}
+
+// RedriveDelivery sends a delivered or failed delivery again, with a fresh
+// set of attempts, and returns it. The job is sent as it is stored now.
+func (uc *WebhookUsecase) RedriveDelivery(ctx context.Context, tenantID string, id int64) (*domain.WebhookDelivery, error) {
+	tenantID = tenantOrDefault(tenantID)
+	if err := uc.webhooks.RedriveDelivery(ctx, tenantID, id, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	uc.logger.Info("Result delivery redriven", zap.String("tenant_id", tenantID), zap.Int64("delivery_id", id))
+	return uc.webhooks.GetDelivery(ctx, tenantID, id)
+}
+
+// webhookSecret returns secret if it is a valid webhook secret, or a
+// generated one if it is empty.
+func webhookSecret(secret string) (string, error) {
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("generate webhook secret: %w", err)
+		}
+		return hex.EncodeToString(buf), nil
+	}
+	if len(secret) < minWebhookSecretLen || len(secret) > maxWebhookSecretLen {
+		return "", fmt.Errorf("%w: secret must be %d-%d bytes", domain.ErrInvalidWebhook, minWebhookSecretLen, maxWebhookSecretLen)
+	}
+	return secret, nil
+}
+
+func validateWebhookURL(raw string) error {
+	if len(raw) > maxWebhookURLLen {
+		return fmt.Errorf("%w: url longer than %d bytes", domain.ErrInvalidWebhook, maxWebhookURLLen)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalidWebhook)
+	}
+	return nil
+}
+
+func tenantOrDefault(tenantID string) string {
+	if tenantID == "" {
+		return domain.DefaultTenantID
+	}
+	return tenantID
+}
@@ -0,0 +1,213 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+const (
+	// bigQueryBaseURL is the BigQuery REST API.
+	bigQueryBaseURL = "https://bigquery.googleapis.com"
+
+	// bigQueryScope is the OAuth2 scope for streaming inserts.
+	bigQueryScope = "https://www.googleapis.com/auth/bigquery.insertdata"
+)
+
+// serviceAccount holds the fields of a Google service account key file the
+// sink uses.
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+type bigQuerySink struct {
+	baseURL  string
+	project  string
+	dataset  string
+	table    string
+	email    string
+	tokenURI string
+	key      *rsa.PrivateKey
+	http     *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewBigQuerySink creates a sink streaming into table, "project.dataset.table",
+// authenticated as the service account whose key is in credentialsFile.
+// Each fact's event ID is its insert ID, which BigQuery uses to drop facts
+// written again after a failure.
+func NewBigQuerySink(table, credentialsFile string) (Sink, error) {
+	parts := strings.Split(table, ".")
+	if len(parts) != 3 || !tableName.MatchString(table) {
+		return nil, fmt.Errorf("warehouse: bigquery table must be project.dataset.table, got %q", table)
+	}
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("warehouse: read bigquery credentials: %w", err)
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil || account.ClientEmail == "" || account.TokenURI == "" {
+		return nil, fmt.Errorf("warehouse: %s is not a service account key", credentialsFile)
+	}
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("warehouse: service account key holds no PEM block")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("warehouse: parse service account key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("warehouse: service account key is not an RSA key")
+	}
+	return &bigQuerySink{
+		baseURL:  bigQueryBaseURL,
+		project:  parts[0],
+		dataset:  parts[1],
+		table:    parts[2],
+		email:    account.ClientEmail,
+		tokenURI: account.TokenURI,
+		key:      key,
+		http:     &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+func (s *bigQuerySink) Name() string { return "bigquery" }
+
+type insertRow struct {
+	InsertID string          `json:"insertId"`
+	JSON     *domain.JobFact `json:"json"`
+}
+
+func (s *bigQuerySink) Write(ctx context.Context, facts []*domain.JobFact) error {
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([]insertRow, len(facts))
+	for i, f := range facts {
+		rows[i] = insertRow{InsertID: strconv.FormatInt(f.EventID, 10), JSON: f}
+	}
+	payload, err := json.Marshal(map[string]any{"rows": rows})
+	if err != nil {
+		return fmt.Errorf("warehouse: encode rows: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/bigquery/v2/projects/%s/datasets/%s/tables/%s/insertAll",
+		s.baseURL, url.PathEscape(s.project), url.PathEscape(s.dataset), url.PathEscape(s.table))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("warehouse: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	body, err := do(s.http, req)
+	if err != nil {
+		return err
+	}
+
+	// insertAll answers 200 even when rows are refused.
+	var resp struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("warehouse: decode insertAll response: %w", err)
+	}
+	if len(resp.InsertErrors) > 0 {
+		first := resp.InsertErrors[0]
+		msg := "unknown error"
+		if len(first.Errors) > 0 {
+			msg = first.Errors[0].Message
+		}
+		return fmt.Errorf("warehouse: bigquery refused %d rows, first at %d: %s", len(resp.InsertErrors), first.Index, msg)
+	}
+	return nil
+}
+
+// accessToken returns an OAuth2 token for the service account, reusing one
+// that has not expired.
+func (s *bigQuerySink) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.expires) {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	assertion, err := signRS256(map[string]any{
+		"iss":   s.email,
+		"scope": bigQueryScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}, s.key)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("warehouse: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := do(s.http, req)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.AccessToken == "" {
+		return "", fmt.Errorf("warehouse: token endpoint returned no access token")
+	}
+	// Renew a minute early so a token never expires in flight.
+	s.token = resp.AccessToken
+	s.expires = now.Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+	return s.token, nil
+}
+
+// signRS256 encodes claims as a JWT signed with RS256.
+func signRS256(claims any, key *rsa.PrivateKey) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("warehouse: encode claims: %w", err)
+	}
+	signing := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("warehouse: sign assertion: %w", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
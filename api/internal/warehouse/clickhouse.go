@@ -0,0 +1,72 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+type clickHouseSink struct {
+	endpoint string
+	user     string
+	password string
+	table    string
+	http     *http.Client
+}
+
+// NewClickHouseSink creates a sink inserting into table through the
+// ClickHouse HTTP interface at endpoint. Credentials in the endpoint's user
+// info are sent as ClickHouse's authentication headers.
+//
+// Declare the table with a ReplacingMergeTree ordered by event_id so that
+// facts written again after a failure are merged away.
+func NewClickHouseSink(endpoint, table string) (Sink, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("warehouse: clickhouse endpoint must be an http(s) URL")
+	}
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("warehouse: invalid table %q", table)
+	}
+	s := &clickHouseSink{table: table, http: &http.Client{Timeout: requestTimeout}}
+	if u.User != nil {
+		s.user = u.User.Username()
+		s.password, _ = u.User.Password()
+		u.User = nil
+	}
+	s.endpoint = u.String()
+	return s, nil
+}
+
+func (s *clickHouseSink) Name() string { return "clickhouse" }
+
+func (s *clickHouseSink) Write(ctx context.Context, facts []*domain.JobFact) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, f := range facts {
+		if err := enc.Encode(f); err != nil {
+			return fmt.Errorf("warehouse: encode fact: %w", err)
+		}
+	}
+
+	u, _ := url.Parse(s.endpoint)
+	query := u.Query()
+	query.Set("query", "INSERT INTO "+s.table+" FORMAT JSONEachRow")
+	query.Set("date_time_input_format", "best_effort")
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), &body)
+	if err != nil {
+		return fmt.Errorf("warehouse: build request: %w", err)
+	}
+	if s.user != "" {
+		req.Header.Set("X-ClickHouse-User", s.user)
+		req.Header.Set("X-ClickHouse-Key", s.password)
+	}
+	_, err = do(s.http, req)
+	return err
+}
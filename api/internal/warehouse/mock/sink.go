@@ -0,0 +1,45 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+	"github.com/Harsh-BH/Sentinel/api/internal/warehouse"
+)
+
+// Ensure MockSink implements warehouse.Sink.
+var _ warehouse.Sink = (*MockSink)(nil)
+
+// MockSink is a mock warehouse sink for testing, recording what it is
+// written.
+type MockSink struct {
+	mu    sync.Mutex
+	facts []*domain.JobFact
+
+	WriteFn func(ctx context.Context, facts []*domain.JobFact) error
+}
+
+// NewMockSink creates a new mock sink.
+func NewMockSink() *MockSink {
+	return &MockSink{}
+}
+
+func (m *MockSink) Name() string { return "mock" }
+
+func (m *MockSink) Write(ctx context.Context, facts []*domain.JobFact) error {
+	if m.WriteFn != nil {
+		return m.WriteFn(ctx, facts)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.facts = append(m.facts, facts...)
+	return nil
+}
+
+// Written returns a copy of the facts written so far.
+func (m *MockSink) Written() []*domain.JobFact {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*domain.JobFact(nil), m.facts...)
+}
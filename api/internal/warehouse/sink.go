@@ -0,0 +1,74 @@
+// Package warehouse writes job facts to analytics warehouses, so long-term
+// analytics can run without loading the production database.
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+const (
+	// requestTimeout bounds each call to a warehouse.
+	requestTimeout = 30 * time.Second
+
+	// maxResponseSize caps a response body.
+	maxResponseSize = 1 << 20
+)
+
+// tableName matches a table, optionally qualified by its database, dataset
+// or project.
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*(\.[A-Za-z_][A-Za-z0-9_-]*){0,2}$`)
+
+// Sink is a warehouse facts are exported to.
+type Sink interface {
+	// Name identifies the sink; the export keeps its progress per name.
+	Name() string
+
+	// Write stores a batch of facts. A batch that failed, even partly, is
+	// written again, so sinks should let the warehouse drop facts it
+	// already holds, keyed by event ID.
+	Write(ctx context.Context, facts []*domain.JobFact) error
+}
+
+// New creates the sink called kind ("clickhouse" or "bigquery") writing to
+// table. endpoint is ClickHouse's HTTP interface; credentialsFile is a
+// Google service account key for BigQuery.
+func New(kind, endpoint, table, credentialsFile string) (Sink, error) {
+	switch kind {
+	case "clickhouse":
+		return NewClickHouseSink(endpoint, table)
+	case "bigquery":
+		return NewBigQuerySink(table, credentialsFile)
+	}
+	return nil, fmt.Errorf("warehouse: unknown sink %q, want clickhouse or bigquery", kind)
+}
+
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("warehouse: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("warehouse: read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("warehouse: %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, truncate(string(body), 200))
+	}
+	return body, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,147 @@
+package warehouse
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/domain"
+)
+
+func testFacts() []*domain.JobFact {
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	return []*domain.JobFact{
+		{EventID: 41, JobID: uuid.New(), TenantID: "acme", Language: domain.LangPython, Status: domain.StatusAccepted, SourceHash: "ab", QueuedAt: now, FinishedAt: now},
+		{EventID: 42, JobID: uuid.New(), TenantID: "acme", Language: domain.LangPython, Status: domain.StatusWrongAnswer, SourceHash: "cd", QueuedAt: now, FinishedAt: now},
+	}
+}
+
+func TestClickHouseSink_Write(t *testing.T) {
+	var rows []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("query"); got != "INSERT INTO analytics.job_facts FORMAT JSONEachRow" {
+			t.Errorf("query = %q", got)
+		}
+		if r.Header.Get("X-ClickHouse-User") != "exporter" || r.Header.Get("X-ClickHouse-Key") != "pw" {
+			t.Errorf("credentials = %q/%q", r.Header.Get("X-ClickHouse-User"), r.Header.Get("X-ClickHouse-Key"))
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var row map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				t.Errorf("line %q: %v", scanner.Text(), err)
+			}
+			rows = append(rows, row)
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := NewClickHouseSink(srv.URL, "job_facts; DROP TABLE x"); err == nil {
+		t.Error("NewClickHouseSink accepted an injected table name")
+	}
+	endpoint := strings.Replace(srv.URL, "http://", "http://exporter:pw@", 1)
+	sink, err := New("clickhouse", endpoint, "analytics.job_facts", "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := sink.Write(context.Background(), testFacts()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(rows) != 2 || rows[1]["event_id"] != float64(42) || rows[1]["status"] != "WRONG_ANSWER" {
+		t.Errorf("rows = %v", rows)
+	}
+}
+
+func TestBigQuerySink_Write(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	var tokenRequests int
+	var inserted struct {
+		Rows []struct {
+			InsertID string         `json:"insertId"`
+			JSON     map[string]any `json:"json"`
+		} `json:"rows"`
+	}
+	refuse := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || strings.Count(r.Form.Get("assertion"), ".") != 2 {
+				t.Errorf("token request = %v", r.Form)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "ya29", "expires_in": 3600})
+		case "/bigquery/v2/projects/proj/datasets/analytics/tables/job_facts/insertAll":
+			if r.Header.Get("Authorization") != "Bearer ya29" {
+				t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+			}
+			json.NewDecoder(r.Body).Decode(&inserted)
+			if refuse {
+				w.Write([]byte(`{"insertErrors":[{"index":1,"errors":[{"message":"no such field"}]}]}`))
+				return
+			}
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	credentials := filepath.Join(t.TempDir(), "sa.json")
+	account, _ := json.Marshal(serviceAccount{
+		ClientEmail: "exporter@proj.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    srv.URL + "/token",
+	})
+	if err := os.WriteFile(credentials, account, 0o600); err != nil {
+		t.Fatalf("write credentials: %v", err)
+	}
+
+	if _, err := NewBigQuerySink("job_facts", credentials); err == nil {
+		t.Error("NewBigQuerySink accepted an unqualified table")
+	}
+	sink, err := New("bigquery", "", "proj.analytics.job_facts", credentials)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	sink.(*bigQuerySink).baseURL = srv.URL
+
+	ctx := context.Background()
+	if err := sink.Write(ctx, testFacts()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(inserted.Rows) != 2 || inserted.Rows[0].InsertID != "41" || inserted.Rows[0].JSON["tenant_id"] != "acme" {
+		t.Errorf("inserted = %+v", inserted)
+	}
+	refuse = true
+	if err := sink.Write(ctx, testFacts()); err == nil || !strings.Contains(err.Error(), "no such field") {
+		t.Errorf("Write with refused rows: err = %v", err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token requests = %d, want the token reused", tokenRequests)
+	}
+}
+
+func TestNew_UnknownSink(t *testing.T) {
+	if _, err := New("snowflake", "", "t", ""); err == nil {
+		t.Error("New accepted an unknown sink")
+	}
+}
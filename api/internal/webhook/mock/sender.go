@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Harsh-BH/Sentinel/api/internal/webhook"
+)
+
+// Ensure MockSender implements webhook.Sender.
+var _ webhook.Sender = (*MockSender)(nil)
+
+// Sent is an event recorded by MockSender.Send.
+type Sent struct {
+	URL        string
+	DeliveryID int64
+	Body       []byte
+}
+
+// MockSender is a mock webhook sender for testing. Without SendFn every
+// event is accepted with a 200.
+type MockSender struct {
+	mu     sync.Mutex
+	events []Sent
+
+	SendFn func(ctx context.Context, url, secret string, deliveryID int64, body []byte) (int, error)
+}
+
+// NewMockSender creates a new mock sender.
+func NewMockSender() *MockSender {
+	return &MockSender{}
+}
+
+func (m *MockSender) Send(ctx context.Context, url, secret string, deliveryID int64, body []byte) (int, error) {
+	m.mu.Lock()
+	m.events = append(m.events, Sent{URL: url, DeliveryID: deliveryID, Body: body})
+	m.mu.Unlock()
+	if m.SendFn != nil {
+		return m.SendFn(ctx, url, secret, deliveryID, body)
+	}
+	return 200, nil
+}
+
+// Events returns a copy of the events sent so far, failed ones included.
+func (m *MockSender) Events() []Sent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Sent(nil), m.events...)
+}
@@ -0,0 +1,74 @@
+// Package webhook posts signed events to tenants' webhook endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// request body, keyed with the webhook's secret. Workers sign
+	// dead-letter notifications the same way.
+	SignatureHeader = "X-Sentinel-Signature"
+
+	// DeliveryHeader carries the delivery ID, which stays the same across
+	// the attempts of a delivery so that receivers can drop duplicates.
+	DeliveryHeader = "X-Sentinel-Delivery"
+
+	// maxDrainSize caps how much of a response body is read, so that the
+	// connection can be reused.
+	maxDrainSize = 64 << 10
+)
+
+// Sender posts one event to an endpoint.
+type Sender interface {
+	// Send POSTs body to url, signed with secret. It returns the response's
+	// status code, or zero with an error if no response arrived. Any
+	// response outside 2xx is also returned as an error.
+	Send(ctx context.Context, url, secret string, deliveryID int64, body []byte) (int, error)
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewSender creates a Sender whose requests time out after timeout.
+func NewSender(timeout time.Duration) Sender {
+	return &httpSender{client: &http.Client{Timeout: timeout}}
+}
+
+// Sign returns the SignatureHeader value of body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *httpSender) Send(ctx context.Context, url, secret string, deliveryID int64, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(deliveryID, 10))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrainSize)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSender_SignsAndReportsStatus(t *testing.T) {
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(SignatureHeader), Sign("s3cret", body); got != want {
+			t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+		}
+		if got := r.Header.Get(DeliveryHeader); got != "42" {
+			t.Errorf("%s = %q, want 42", DeliveryHeader, got)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	s := NewSender(time.Second)
+	code, err := s.Send(context.Background(), srv.URL, "s3cret", 42, []byte(`{"event":"job.finished"}`))
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v; want 204, nil", code, err)
+	}
+
+	status = http.StatusServiceUnavailable
+	code, err = s.Send(context.Background(), srv.URL, "s3cret", 42, []byte(`{}`))
+	if err == nil || code != http.StatusServiceUnavailable {
+		t.Fatalf("Send = %d, %v; want 503 with an error", code, err)
+	}
+}
@@ -0,0 +1,1323 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v5.29.3
+// source: sentinel/v1/sentinel.proto
+
+// Sentinel's gRPC API: submitting jobs, fetching them and streaming their
+// status changes. It is served alongside the REST API on API_GRPC_PORT and
+// shares its validation, quotas and errors; see docs/api.md.
+
+package sentinelv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// JobStatus is the lifecycle state of a job, or the verdict of one of its
+// test cases. Names match the REST API's status strings.
+type JobStatus int32
+
+const (
+	JobStatus_JOB_STATUS_UNSPECIFIED           JobStatus = 0
+	JobStatus_JOB_STATUS_SCHEDULED             JobStatus = 1
+	JobStatus_JOB_STATUS_QUEUED                JobStatus = 2
+	JobStatus_JOB_STATUS_COMPILING             JobStatus = 3
+	JobStatus_JOB_STATUS_RUNNING               JobStatus = 4
+	JobStatus_JOB_STATUS_SUCCESS               JobStatus = 5
+	JobStatus_JOB_STATUS_COMPILATION_ERROR     JobStatus = 6
+	JobStatus_JOB_STATUS_RUNTIME_ERROR         JobStatus = 7
+	JobStatus_JOB_STATUS_TIMEOUT               JobStatus = 8
+	JobStatus_JOB_STATUS_MEMORY_LIMIT_EXCEEDED JobStatus = 9
+	JobStatus_JOB_STATUS_INTERNAL_ERROR        JobStatus = 10
+	JobStatus_JOB_STATUS_ACCEPTED              JobStatus = 11
+	JobStatus_JOB_STATUS_WRONG_ANSWER          JobStatus = 12
+	JobStatus_JOB_STATUS_CANCELLED             JobStatus = 13
+	JobStatus_JOB_STATUS_RULE_VIOLATION        JobStatus = 14
+	// Test cases only: not run because judging ended early.
+	JobStatus_JOB_STATUS_SKIPPED          JobStatus = 15
+	JobStatus_JOB_STATUS_OUTPUT_VIOLATION JobStatus = 16
+)
+
+// Enum value maps for JobStatus.
+var (
+	JobStatus_name = map[int32]string{
+		0:  "JOB_STATUS_UNSPECIFIED",
+		1:  "JOB_STATUS_SCHEDULED",
+		2:  "JOB_STATUS_QUEUED",
+		3:  "JOB_STATUS_COMPILING",
+		4:  "JOB_STATUS_RUNNING",
+		5:  "JOB_STATUS_SUCCESS",
+		6:  "JOB_STATUS_COMPILATION_ERROR",
+		7:  "JOB_STATUS_RUNTIME_ERROR",
+		8:  "JOB_STATUS_TIMEOUT",
+		9:  "JOB_STATUS_MEMORY_LIMIT_EXCEEDED",
+		10: "JOB_STATUS_INTERNAL_ERROR",
+		11: "JOB_STATUS_ACCEPTED",
+		12: "JOB_STATUS_WRONG_ANSWER",
+		13: "JOB_STATUS_CANCELLED",
+		14: "JOB_STATUS_RULE_VIOLATION",
+		15: "JOB_STATUS_SKIPPED",
+		16: "JOB_STATUS_OUTPUT_VIOLATION",
+	}
+	JobStatus_value = map[string]int32{
+		"JOB_STATUS_UNSPECIFIED":           0,
+		"JOB_STATUS_SCHEDULED":             1,
+		"JOB_STATUS_QUEUED":                2,
+		"JOB_STATUS_COMPILING":             3,
+		"JOB_STATUS_RUNNING":               4,
+		"JOB_STATUS_SUCCESS":               5,
+		"JOB_STATUS_COMPILATION_ERROR":     6,
+		"JOB_STATUS_RUNTIME_ERROR":         7,
+		"JOB_STATUS_TIMEOUT":               8,
+		"JOB_STATUS_MEMORY_LIMIT_EXCEEDED": 9,
+		"JOB_STATUS_INTERNAL_ERROR":        10,
+		"JOB_STATUS_ACCEPTED":              11,
+		"JOB_STATUS_WRONG_ANSWER":          12,
+		"JOB_STATUS_CANCELLED":             13,
+		"JOB_STATUS_RULE_VIOLATION":        14,
+		"JOB_STATUS_SKIPPED":               15,
+		"JOB_STATUS_OUTPUT_VIOLATION":      16,
+	}
+)
+
+func (x JobStatus) Enum() *JobStatus {
+	p := new(JobStatus)
+	*p = x
+	return p
+}
+
+func (x JobStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_sentinel_v1_sentinel_proto_enumTypes[0].Descriptor()
+}
+
+func (JobStatus) Type() protoreflect.EnumType {
+	return &file_sentinel_v1_sentinel_proto_enumTypes[0]
+}
+
+func (x JobStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobStatus.Descriptor instead.
+func (JobStatus) EnumDescriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{0}
+}
+
+// SubmitRequest mirrors the REST submission body. Unset optional fields
+// take the same defaults. Interactive jobs are submitted over REST, since
+// their input is sent over the WebSocket stream.
+type SubmitRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Language        string                 `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	SourceCode      string                 `protobuf:"bytes,2,opt,name=source_code,json=sourceCode,proto3" json:"source_code,omitempty"`
+	Stdin           string                 `protobuf:"bytes,3,opt,name=stdin,proto3" json:"stdin,omitempty"`
+	TimeLimitMs     *int32                 `protobuf:"varint,4,opt,name=time_limit_ms,json=timeLimitMs,proto3,oneof" json:"time_limit_ms,omitempty"`
+	MemoryLimitKb   *int32                 `protobuf:"varint,5,opt,name=memory_limit_kb,json=memoryLimitKb,proto3,oneof" json:"memory_limit_kb,omitempty"`
+	WallTimeLimitMs *int32                 `protobuf:"varint,6,opt,name=wall_time_limit_ms,json=wallTimeLimitMs,proto3,oneof" json:"wall_time_limit_ms,omitempty"`
+	CpuTimeLimitMs  *int32                 `protobuf:"varint,7,opt,name=cpu_time_limit_ms,json=cpuTimeLimitMs,proto3,oneof" json:"cpu_time_limit_ms,omitempty"`
+	PidsLimit       *int32                 `protobuf:"varint,8,opt,name=pids_limit,json=pidsLimit,proto3,oneof" json:"pids_limit,omitempty"`
+	CompilerFlags   []string               `protobuf:"bytes,9,rep,name=compiler_flags,json=compilerFlags,proto3" json:"compiler_flags,omitempty"`
+	Args            []string               `protobuf:"bytes,10,rep,name=args,proto3" json:"args,omitempty"`
+	Env             map[string]string      `protobuf:"bytes,11,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ProblemId       string                 `protobuf:"bytes,12,opt,name=problem_id,json=problemId,proto3" json:"problem_id,omitempty"`
+	SandboxTier     string                 `protobuf:"bytes,13,opt,name=sandbox_tier,json=sandboxTier,proto3" json:"sandbox_tier,omitempty"`
+	NetworkPolicy   string                 `protobuf:"bytes,14,opt,name=network_policy,json=networkPolicy,proto3" json:"network_policy,omitempty"`
+	OutputFiles     []string               `protobuf:"bytes,15,rep,name=output_files,json=outputFiles,proto3" json:"output_files,omitempty"`
+	ExpectedOutput  *string                `protobuf:"bytes,16,opt,name=expected_output,json=expectedOutput,proto3,oneof" json:"expected_output,omitempty"`
+	CompareMode     string                 `protobuf:"bytes,17,opt,name=compare_mode,json=compareMode,proto3" json:"compare_mode,omitempty"`
+	Priority        *int32                 `protobuf:"varint,18,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	RunAt           *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=run_at,json=runAt,proto3" json:"run_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SubmitRequest) Reset() {
+	*x = SubmitRequest{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitRequest) ProtoMessage() {}
+
+func (x *SubmitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitRequest.ProtoReflect.Descriptor instead.
+func (*SubmitRequest) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubmitRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetSourceCode() string {
+	if x != nil {
+		return x.SourceCode
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetStdin() string {
+	if x != nil {
+		return x.Stdin
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetTimeLimitMs() int32 {
+	if x != nil && x.TimeLimitMs != nil {
+		return *x.TimeLimitMs
+	}
+	return 0
+}
+
+func (x *SubmitRequest) GetMemoryLimitKb() int32 {
+	if x != nil && x.MemoryLimitKb != nil {
+		return *x.MemoryLimitKb
+	}
+	return 0
+}
+
+func (x *SubmitRequest) GetWallTimeLimitMs() int32 {
+	if x != nil && x.WallTimeLimitMs != nil {
+		return *x.WallTimeLimitMs
+	}
+	return 0
+}
+
+func (x *SubmitRequest) GetCpuTimeLimitMs() int32 {
+	if x != nil && x.CpuTimeLimitMs != nil {
+		return *x.CpuTimeLimitMs
+	}
+	return 0
+}
+
+func (x *SubmitRequest) GetPidsLimit() int32 {
+	if x != nil && x.PidsLimit != nil {
+		return *x.PidsLimit
+	}
+	return 0
+}
+
+func (x *SubmitRequest) GetCompilerFlags() []string {
+	if x != nil {
+		return x.CompilerFlags
+	}
+	return nil
+}
+
+func (x *SubmitRequest) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *SubmitRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *SubmitRequest) GetProblemId() string {
+	if x != nil {
+		return x.ProblemId
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetSandboxTier() string {
+	if x != nil {
+		return x.SandboxTier
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetNetworkPolicy() string {
+	if x != nil {
+		return x.NetworkPolicy
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetOutputFiles() []string {
+	if x != nil {
+		return x.OutputFiles
+	}
+	return nil
+}
+
+func (x *SubmitRequest) GetExpectedOutput() string {
+	if x != nil && x.ExpectedOutput != nil {
+		return *x.ExpectedOutput
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetCompareMode() string {
+	if x != nil {
+		return x.CompareMode
+	}
+	return ""
+}
+
+func (x *SubmitRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *SubmitRequest) GetRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunAt
+	}
+	return nil
+}
+
+type SubmitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Status        JobStatus              `protobuf:"varint,2,opt,name=status,proto3,enum=sentinel.v1.JobStatus" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitResponse) Reset() {
+	*x = SubmitResponse{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitResponse) ProtoMessage() {}
+
+func (x *SubmitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitResponse.ProtoReflect.Descriptor instead.
+func (*SubmitResponse) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubmitResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *SubmitResponse) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WatchRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Seq numbers the stream's messages from 1.
+	Seq int64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Job *Job  `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
+	// Timeline is the job's status history, sent with the first message
+	// only.
+	Timeline      []*JobEvent `protobuf:"bytes,3,rep,name=timeline,proto3" json:"timeline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *WatchResponse) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetTimeline() []*JobEvent {
+	if x != nil {
+		return x.Timeline
+	}
+	return nil
+}
+
+// Job is a job as submitted, with its result once it has run.
+type Job struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	JobId    string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	TenantId string                 `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	Language string                 `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	Status   JobStatus              `protobuf:"varint,4,opt,name=status,proto3,enum=sentinel.v1.JobStatus" json:"status,omitempty"`
+	// SourceCode is the source as submitted, before any problem harness
+	// wrapped it.
+	SourceCode      string `protobuf:"bytes,5,opt,name=source_code,json=sourceCode,proto3" json:"source_code,omitempty"`
+	Stdin           string `protobuf:"bytes,6,opt,name=stdin,proto3" json:"stdin,omitempty"`
+	WallTimeLimitMs int32  `protobuf:"varint,7,opt,name=wall_time_limit_ms,json=wallTimeLimitMs,proto3" json:"wall_time_limit_ms,omitempty"`
+	CpuTimeLimitMs  int32  `protobuf:"varint,8,opt,name=cpu_time_limit_ms,json=cpuTimeLimitMs,proto3" json:"cpu_time_limit_ms,omitempty"`
+	MemoryLimitKb   int32  `protobuf:"varint,9,opt,name=memory_limit_kb,json=memoryLimitKb,proto3" json:"memory_limit_kb,omitempty"`
+	PidsLimit       int32  `protobuf:"varint,10,opt,name=pids_limit,json=pidsLimit,proto3" json:"pids_limit,omitempty"`
+	Priority        int32  `protobuf:"varint,11,opt,name=priority,proto3" json:"priority,omitempty"`
+	SandboxTier     string `protobuf:"bytes,12,opt,name=sandbox_tier,json=sandboxTier,proto3" json:"sandbox_tier,omitempty"`
+	NetworkPolicy   string `protobuf:"bytes,13,opt,name=network_policy,json=networkPolicy,proto3" json:"network_policy,omitempty"`
+	ProblemId       string `protobuf:"bytes,14,opt,name=problem_id,json=problemId,proto3" json:"problem_id,omitempty"`
+	JudgeRevision   int32  `protobuf:"varint,15,opt,name=judge_revision,json=judgeRevision,proto3" json:"judge_revision,omitempty"`
+	// Origin is "user", "resubmit" or "schedule".
+	Origin      string                 `protobuf:"bytes,16,opt,name=origin,proto3" json:"origin,omitempty"`
+	ParentJobId string                 `protobuf:"bytes,17,opt,name=parent_job_id,json=parentJobId,proto3" json:"parent_job_id,omitempty"`
+	RunAt       *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=run_at,json=runAt,proto3" json:"run_at,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,19,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Result is unset until the job has finished.
+	Result        *ExecutionResult `protobuf:"bytes,21,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Job) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *Job) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *Job) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *Job) GetSourceCode() string {
+	if x != nil {
+		return x.SourceCode
+	}
+	return ""
+}
+
+func (x *Job) GetStdin() string {
+	if x != nil {
+		return x.Stdin
+	}
+	return ""
+}
+
+func (x *Job) GetWallTimeLimitMs() int32 {
+	if x != nil {
+		return x.WallTimeLimitMs
+	}
+	return 0
+}
+
+func (x *Job) GetCpuTimeLimitMs() int32 {
+	if x != nil {
+		return x.CpuTimeLimitMs
+	}
+	return 0
+}
+
+func (x *Job) GetMemoryLimitKb() int32 {
+	if x != nil {
+		return x.MemoryLimitKb
+	}
+	return 0
+}
+
+func (x *Job) GetPidsLimit() int32 {
+	if x != nil {
+		return x.PidsLimit
+	}
+	return 0
+}
+
+func (x *Job) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Job) GetSandboxTier() string {
+	if x != nil {
+		return x.SandboxTier
+	}
+	return ""
+}
+
+func (x *Job) GetNetworkPolicy() string {
+	if x != nil {
+		return x.NetworkPolicy
+	}
+	return ""
+}
+
+func (x *Job) GetProblemId() string {
+	if x != nil {
+		return x.ProblemId
+	}
+	return ""
+}
+
+func (x *Job) GetJudgeRevision() int32 {
+	if x != nil {
+		return x.JudgeRevision
+	}
+	return 0
+}
+
+func (x *Job) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *Job) GetParentJobId() string {
+	if x != nil {
+		return x.ParentJobId
+	}
+	return ""
+}
+
+func (x *Job) GetRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RunAt
+	}
+	return nil
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetResult() *ExecutionResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// ExecutionResult is what a run of a job produced.
+type ExecutionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stdout        string                 `protobuf:"bytes,1,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr        string                 `protobuf:"bytes,2,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	ExitCode      *int32                 `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	TimeUsedMs    *int32                 `protobuf:"varint,4,opt,name=time_used_ms,json=timeUsedMs,proto3,oneof" json:"time_used_ms,omitempty"`
+	CpuTimeUsedMs *int32                 `protobuf:"varint,5,opt,name=cpu_time_used_ms,json=cpuTimeUsedMs,proto3,oneof" json:"cpu_time_used_ms,omitempty"`
+	MemoryUsedKb  *int32                 `protobuf:"varint,6,opt,name=memory_used_kb,json=memoryUsedKb,proto3,oneof" json:"memory_used_kb,omitempty"`
+	DiskUsedKb    *int32                 `protobuf:"varint,7,opt,name=disk_used_kb,json=diskUsedKb,proto3,oneof" json:"disk_used_kb,omitempty"`
+	// Judged jobs only.
+	Score          *float64          `protobuf:"fixed64,8,opt,name=score,proto3,oneof" json:"score,omitempty"`
+	MaxScore       *float64          `protobuf:"fixed64,9,opt,name=max_score,json=maxScore,proto3,oneof" json:"max_score,omitempty"`
+	TestResults    []*TestCaseResult `protobuf:"bytes,10,rep,name=test_results,json=testResults,proto3" json:"test_results,omitempty"`
+	SubtaskResults []*SubtaskResult  `protobuf:"bytes,11,rep,name=subtask_results,json=subtaskResults,proto3" json:"subtask_results,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExecutionResult) Reset() {
+	*x = ExecutionResult{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecutionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutionResult) ProtoMessage() {}
+
+func (x *ExecutionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutionResult.ProtoReflect.Descriptor instead.
+func (*ExecutionResult) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExecutionResult) GetStdout() string {
+	if x != nil {
+		return x.Stdout
+	}
+	return ""
+}
+
+func (x *ExecutionResult) GetStderr() string {
+	if x != nil {
+		return x.Stderr
+	}
+	return ""
+}
+
+func (x *ExecutionResult) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetTimeUsedMs() int32 {
+	if x != nil && x.TimeUsedMs != nil {
+		return *x.TimeUsedMs
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetCpuTimeUsedMs() int32 {
+	if x != nil && x.CpuTimeUsedMs != nil {
+		return *x.CpuTimeUsedMs
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetMemoryUsedKb() int32 {
+	if x != nil && x.MemoryUsedKb != nil {
+		return *x.MemoryUsedKb
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetDiskUsedKb() int32 {
+	if x != nil && x.DiskUsedKb != nil {
+		return *x.DiskUsedKb
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetScore() float64 {
+	if x != nil && x.Score != nil {
+		return *x.Score
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetMaxScore() float64 {
+	if x != nil && x.MaxScore != nil {
+		return *x.MaxScore
+	}
+	return 0
+}
+
+func (x *ExecutionResult) GetTestResults() []*TestCaseResult {
+	if x != nil {
+		return x.TestResults
+	}
+	return nil
+}
+
+func (x *ExecutionResult) GetSubtaskResults() []*SubtaskResult {
+	if x != nil {
+		return x.SubtaskResults
+	}
+	return nil
+}
+
+type TestCaseResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ordinal       int32                  `protobuf:"varint,1,opt,name=ordinal,proto3" json:"ordinal,omitempty"`
+	Status        JobStatus              `protobuf:"varint,2,opt,name=status,proto3,enum=sentinel.v1.JobStatus" json:"status,omitempty"`
+	TimeUsedMs    int32                  `protobuf:"varint,3,opt,name=time_used_ms,json=timeUsedMs,proto3" json:"time_used_ms,omitempty"`
+	MemoryUsedKb  int32                  `protobuf:"varint,4,opt,name=memory_used_kb,json=memoryUsedKb,proto3" json:"memory_used_kb,omitempty"`
+	Message       string                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	Subtask       int32                  `protobuf:"varint,6,opt,name=subtask,proto3" json:"subtask,omitempty"`
+	TimeLimitMs   int32                  `protobuf:"varint,7,opt,name=time_limit_ms,json=timeLimitMs,proto3" json:"time_limit_ms,omitempty"`
+	MemoryLimitKb int32                  `protobuf:"varint,8,opt,name=memory_limit_kb,json=memoryLimitKb,proto3" json:"memory_limit_kb,omitempty"`
+	OutputHash    string                 `protobuf:"bytes,9,opt,name=output_hash,json=outputHash,proto3" json:"output_hash,omitempty"`
+	Output        string                 `protobuf:"bytes,10,opt,name=output,proto3" json:"output,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TestCaseResult) Reset() {
+	*x = TestCaseResult{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TestCaseResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestCaseResult) ProtoMessage() {}
+
+func (x *TestCaseResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestCaseResult.ProtoReflect.Descriptor instead.
+func (*TestCaseResult) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TestCaseResult) GetOrdinal() int32 {
+	if x != nil {
+		return x.Ordinal
+	}
+	return 0
+}
+
+func (x *TestCaseResult) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *TestCaseResult) GetTimeUsedMs() int32 {
+	if x != nil {
+		return x.TimeUsedMs
+	}
+	return 0
+}
+
+func (x *TestCaseResult) GetMemoryUsedKb() int32 {
+	if x != nil {
+		return x.MemoryUsedKb
+	}
+	return 0
+}
+
+func (x *TestCaseResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TestCaseResult) GetSubtask() int32 {
+	if x != nil {
+		return x.Subtask
+	}
+	return 0
+}
+
+func (x *TestCaseResult) GetTimeLimitMs() int32 {
+	if x != nil {
+		return x.TimeLimitMs
+	}
+	return 0
+}
+
+func (x *TestCaseResult) GetMemoryLimitKb() int32 {
+	if x != nil {
+		return x.MemoryLimitKb
+	}
+	return 0
+}
+
+func (x *TestCaseResult) GetOutputHash() string {
+	if x != nil {
+		return x.OutputHash
+	}
+	return ""
+}
+
+func (x *TestCaseResult) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+type SubtaskResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ordinal       int32                  `protobuf:"varint,1,opt,name=ordinal,proto3" json:"ordinal,omitempty"`
+	Status        JobStatus              `protobuf:"varint,2,opt,name=status,proto3,enum=sentinel.v1.JobStatus" json:"status,omitempty"`
+	Points        float64                `protobuf:"fixed64,3,opt,name=points,proto3" json:"points,omitempty"`
+	Score         float64                `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubtaskResult) Reset() {
+	*x = SubtaskResult{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubtaskResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubtaskResult) ProtoMessage() {}
+
+func (x *SubtaskResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubtaskResult.ProtoReflect.Descriptor instead.
+func (*SubtaskResult) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SubtaskResult) GetOrdinal() int32 {
+	if x != nil {
+		return x.Ordinal
+	}
+	return 0
+}
+
+func (x *SubtaskResult) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *SubtaskResult) GetPoints() float64 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+func (x *SubtaskResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// JobEvent is one entry of a job's status timeline.
+type JobEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        JobStatus              `protobuf:"varint,1,opt,name=status,proto3,enum=sentinel.v1.JobStatus" json:"status,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobEvent) Reset() {
+	*x = JobEvent{}
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobEvent) ProtoMessage() {}
+
+func (x *JobEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_sentinel_v1_sentinel_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobEvent.ProtoReflect.Descriptor instead.
+func (*JobEvent) Descriptor() ([]byte, []int) {
+	return file_sentinel_v1_sentinel_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *JobEvent) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *JobEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+var File_sentinel_v1_sentinel_proto protoreflect.FileDescriptor
+
+const file_sentinel_v1_sentinel_proto_rawDesc = "" +
+	"\n" +
+	"\x1asentinel/v1/sentinel.proto\x12\vsentinel.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9c\a\n" +
+	"\rSubmitRequest\x12\x1a\n" +
+	"\blanguage\x18\x01 \x01(\tR\blanguage\x12\x1f\n" +
+	"\vsource_code\x18\x02 \x01(\tR\n" +
+	"sourceCode\x12\x14\n" +
+	"\x05stdin\x18\x03 \x01(\tR\x05stdin\x12'\n" +
+	"\rtime_limit_ms\x18\x04 \x01(\x05H\x00R\vtimeLimitMs\x88\x01\x01\x12+\n" +
+	"\x0fmemory_limit_kb\x18\x05 \x01(\x05H\x01R\rmemoryLimitKb\x88\x01\x01\x120\n" +
+	"\x12wall_time_limit_ms\x18\x06 \x01(\x05H\x02R\x0fwallTimeLimitMs\x88\x01\x01\x12.\n" +
+	"\x11cpu_time_limit_ms\x18\a \x01(\x05H\x03R\x0ecpuTimeLimitMs\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"pids_limit\x18\b \x01(\x05H\x04R\tpidsLimit\x88\x01\x01\x12%\n" +
+	"\x0ecompiler_flags\x18\t \x03(\tR\rcompilerFlags\x12\x12\n" +
+	"\x04args\x18\n" +
+	" \x03(\tR\x04args\x125\n" +
+	"\x03env\x18\v \x03(\v2#.sentinel.v1.SubmitRequest.EnvEntryR\x03env\x12\x1d\n" +
+	"\n" +
+	"problem_id\x18\f \x01(\tR\tproblemId\x12!\n" +
+	"\fsandbox_tier\x18\r \x01(\tR\vsandboxTier\x12%\n" +
+	"\x0enetwork_policy\x18\x0e \x01(\tR\rnetworkPolicy\x12!\n" +
+	"\foutput_files\x18\x0f \x03(\tR\voutputFiles\x12,\n" +
+	"\x0fexpected_output\x18\x10 \x01(\tH\x05R\x0eexpectedOutput\x88\x01\x01\x12!\n" +
+	"\fcompare_mode\x18\x11 \x01(\tR\vcompareMode\x12\x1f\n" +
+	"\bpriority\x18\x12 \x01(\x05H\x06R\bpriority\x88\x01\x01\x121\n" +
+	"\x06run_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\x05runAt\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x10\n" +
+	"\x0e_time_limit_msB\x12\n" +
+	"\x10_memory_limit_kbB\x15\n" +
+	"\x13_wall_time_limit_msB\x14\n" +
+	"\x12_cpu_time_limit_msB\r\n" +
+	"\v_pids_limitB\x12\n" +
+	"\x10_expected_outputB\v\n" +
+	"\t_priority\"W\n" +
+	"\x0eSubmitResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12.\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x16.sentinel.v1.JobStatusR\x06status\"#\n" +
+	"\n" +
+	"GetRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"%\n" +
+	"\fWatchRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"x\n" +
+	"\rWatchResponse\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12\"\n" +
+	"\x03job\x18\x02 \x01(\v2\x10.sentinel.v1.JobR\x03job\x121\n" +
+	"\btimeline\x18\x03 \x03(\v2\x15.sentinel.v1.JobEventR\btimeline\"\xa2\x06\n" +
+	"\x03Job\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12.\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x16.sentinel.v1.JobStatusR\x06status\x12\x1f\n" +
+	"\vsource_code\x18\x05 \x01(\tR\n" +
+	"sourceCode\x12\x14\n" +
+	"\x05stdin\x18\x06 \x01(\tR\x05stdin\x12+\n" +
+	"\x12wall_time_limit_ms\x18\a \x01(\x05R\x0fwallTimeLimitMs\x12)\n" +
+	"\x11cpu_time_limit_ms\x18\b \x01(\x05R\x0ecpuTimeLimitMs\x12&\n" +
+	"\x0fmemory_limit_kb\x18\t \x01(\x05R\rmemoryLimitKb\x12\x1d\n" +
+	"\n" +
+	"pids_limit\x18\n" +
+	" \x01(\x05R\tpidsLimit\x12\x1a\n" +
+	"\bpriority\x18\v \x01(\x05R\bpriority\x12!\n" +
+	"\fsandbox_tier\x18\f \x01(\tR\vsandboxTier\x12%\n" +
+	"\x0enetwork_policy\x18\r \x01(\tR\rnetworkPolicy\x12\x1d\n" +
+	"\n" +
+	"problem_id\x18\x0e \x01(\tR\tproblemId\x12%\n" +
+	"\x0ejudge_revision\x18\x0f \x01(\x05R\rjudgeRevision\x12\x16\n" +
+	"\x06origin\x18\x10 \x01(\tR\x06origin\x12\"\n" +
+	"\rparent_job_id\x18\x11 \x01(\tR\vparentJobId\x121\n" +
+	"\x06run_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\x05runAt\x129\n" +
+	"\n" +
+	"created_at\x18\x13 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x124\n" +
+	"\x06result\x18\x15 \x01(\v2\x1c.sentinel.v1.ExecutionResultR\x06result\"\xbc\x04\n" +
+	"\x0fExecutionResult\x12\x16\n" +
+	"\x06stdout\x18\x01 \x01(\tR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x02 \x01(\tR\x06stderr\x12 \n" +
+	"\texit_code\x18\x03 \x01(\x05H\x00R\bexitCode\x88\x01\x01\x12%\n" +
+	"\ftime_used_ms\x18\x04 \x01(\x05H\x01R\n" +
+	"timeUsedMs\x88\x01\x01\x12,\n" +
+	"\x10cpu_time_used_ms\x18\x05 \x01(\x05H\x02R\rcpuTimeUsedMs\x88\x01\x01\x12)\n" +
+	"\x0ememory_used_kb\x18\x06 \x01(\x05H\x03R\fmemoryUsedKb\x88\x01\x01\x12%\n" +
+	"\fdisk_used_kb\x18\a \x01(\x05H\x04R\n" +
+	"diskUsedKb\x88\x01\x01\x12\x19\n" +
+	"\x05score\x18\b \x01(\x01H\x05R\x05score\x88\x01\x01\x12 \n" +
+	"\tmax_score\x18\t \x01(\x01H\x06R\bmaxScore\x88\x01\x01\x12>\n" +
+	"\ftest_results\x18\n" +
+	" \x03(\v2\x1b.sentinel.v1.TestCaseResultR\vtestResults\x12C\n" +
+	"\x0fsubtask_results\x18\v \x03(\v2\x1a.sentinel.v1.SubtaskResultR\x0esubtaskResultsB\f\n" +
+	"\n" +
+	"_exit_codeB\x0f\n" +
+	"\r_time_used_msB\x13\n" +
+	"\x11_cpu_time_used_msB\x11\n" +
+	"\x0f_memory_used_kbB\x0f\n" +
+	"\r_disk_used_kbB\b\n" +
+	"\x06_scoreB\f\n" +
+	"\n" +
+	"_max_score\"\xdb\x02\n" +
+	"\x0eTestCaseResult\x12\x18\n" +
+	"\aordinal\x18\x01 \x01(\x05R\aordinal\x12.\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x16.sentinel.v1.JobStatusR\x06status\x12 \n" +
+	"\ftime_used_ms\x18\x03 \x01(\x05R\n" +
+	"timeUsedMs\x12$\n" +
+	"\x0ememory_used_kb\x18\x04 \x01(\x05R\fmemoryUsedKb\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12\x18\n" +
+	"\asubtask\x18\x06 \x01(\x05R\asubtask\x12\"\n" +
+	"\rtime_limit_ms\x18\a \x01(\x05R\vtimeLimitMs\x12&\n" +
+	"\x0fmemory_limit_kb\x18\b \x01(\x05R\rmemoryLimitKb\x12\x1f\n" +
+	"\voutput_hash\x18\t \x01(\tR\n" +
+	"outputHash\x12\x16\n" +
+	"\x06output\x18\n" +
+	" \x01(\tR\x06output\"\x87\x01\n" +
+	"\rSubtaskResult\x12\x18\n" +
+	"\aordinal\x18\x01 \x01(\x05R\aordinal\x12.\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x16.sentinel.v1.JobStatusR\x06status\x12\x16\n" +
+	"\x06points\x18\x03 \x01(\x01R\x06points\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\"u\n" +
+	"\bJobEvent\x12.\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x16.sentinel.v1.JobStatusR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt*\xe7\x03\n" +
+	"\tJobStatus\x12\x1a\n" +
+	"\x16JOB_STATUS_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14JOB_STATUS_SCHEDULED\x10\x01\x12\x15\n" +
+	"\x11JOB_STATUS_QUEUED\x10\x02\x12\x18\n" +
+	"\x14JOB_STATUS_COMPILING\x10\x03\x12\x16\n" +
+	"\x12JOB_STATUS_RUNNING\x10\x04\x12\x16\n" +
+	"\x12JOB_STATUS_SUCCESS\x10\x05\x12 \n" +
+	"\x1cJOB_STATUS_COMPILATION_ERROR\x10\x06\x12\x1c\n" +
+	"\x18JOB_STATUS_RUNTIME_ERROR\x10\a\x12\x16\n" +
+	"\x12JOB_STATUS_TIMEOUT\x10\b\x12$\n" +
+	" JOB_STATUS_MEMORY_LIMIT_EXCEEDED\x10\t\x12\x1d\n" +
+	"\x19JOB_STATUS_INTERNAL_ERROR\x10\n" +
+	"\x12\x17\n" +
+	"\x13JOB_STATUS_ACCEPTED\x10\v\x12\x1b\n" +
+	"\x17JOB_STATUS_WRONG_ANSWER\x10\f\x12\x18\n" +
+	"\x14JOB_STATUS_CANCELLED\x10\r\x12\x1d\n" +
+	"\x19JOB_STATUS_RULE_VIOLATION\x10\x0e\x12\x16\n" +
+	"\x12JOB_STATUS_SKIPPED\x10\x0f\x12\x1f\n" +
+	"\x1bJOB_STATUS_OUTPUT_VIOLATION\x10\x102\xc1\x01\n" +
+	"\bSentinel\x12A\n" +
+	"\x06Submit\x12\x1a.sentinel.v1.SubmitRequest\x1a\x1b.sentinel.v1.SubmitResponse\x120\n" +
+	"\x03Get\x12\x17.sentinel.v1.GetRequest\x1a\x10.sentinel.v1.Job\x12@\n" +
+	"\x05Watch\x12\x19.sentinel.v1.WatchRequest\x1a\x1a.sentinel.v1.WatchResponse0\x01B?Z=github.com/Harsh-BH/Sentinel/api/proto/sentinel/v1;sentinelv1b\x06proto3"
+
+var (
+	file_sentinel_v1_sentinel_proto_rawDescOnce sync.Once
+	file_sentinel_v1_sentinel_proto_rawDescData []byte
+)
+
+func file_sentinel_v1_sentinel_proto_rawDescGZIP() []byte {
+	file_sentinel_v1_sentinel_proto_rawDescOnce.Do(func() {
+		file_sentinel_v1_sentinel_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sentinel_v1_sentinel_proto_rawDesc), len(file_sentinel_v1_sentinel_proto_rawDesc)))
+	})
+	return file_sentinel_v1_sentinel_proto_rawDescData
+}
+
+var file_sentinel_v1_sentinel_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sentinel_v1_sentinel_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_sentinel_v1_sentinel_proto_goTypes = []any{
+	(JobStatus)(0),                // 0: sentinel.v1.JobStatus
+	(*SubmitRequest)(nil),         // 1: sentinel.v1.SubmitRequest
+	(*SubmitResponse)(nil),        // 2: sentinel.v1.SubmitResponse
+	(*GetRequest)(nil),            // 3: sentinel.v1.GetRequest
+	(*WatchRequest)(nil),          // 4: sentinel.v1.WatchRequest
+	(*WatchResponse)(nil),         // 5: sentinel.v1.WatchResponse
+	(*Job)(nil),                   // 6: sentinel.v1.Job
+	(*ExecutionResult)(nil),       // 7: sentinel.v1.ExecutionResult
+	(*TestCaseResult)(nil),        // 8: sentinel.v1.TestCaseResult
+	(*SubtaskResult)(nil),         // 9: sentinel.v1.SubtaskResult
+	(*JobEvent)(nil),              // 10: sentinel.v1.JobEvent
+	nil,                           // 11: sentinel.v1.SubmitRequest.EnvEntry
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_sentinel_v1_sentinel_proto_depIdxs = []int32{
+	11, // 0: sentinel.v1.SubmitRequest.env:type_name -> sentinel.v1.SubmitRequest.EnvEntry
+	12, // 1: sentinel.v1.SubmitRequest.run_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: sentinel.v1.SubmitResponse.status:type_name -> sentinel.v1.JobStatus
+	6,  // 3: sentinel.v1.WatchResponse.job:type_name -> sentinel.v1.Job
+	10, // 4: sentinel.v1.WatchResponse.timeline:type_name -> sentinel.v1.JobEvent
+	0,  // 5: sentinel.v1.Job.status:type_name -> sentinel.v1.JobStatus
+	12, // 6: sentinel.v1.Job.run_at:type_name -> google.protobuf.Timestamp
+	12, // 7: sentinel.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	12, // 8: sentinel.v1.Job.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 9: sentinel.v1.Job.result:type_name -> sentinel.v1.ExecutionResult
+	8,  // 10: sentinel.v1.ExecutionResult.test_results:type_name -> sentinel.v1.TestCaseResult
+	9,  // 11: sentinel.v1.ExecutionResult.subtask_results:type_name -> sentinel.v1.SubtaskResult
+	0,  // 12: sentinel.v1.TestCaseResult.status:type_name -> sentinel.v1.JobStatus
+	0,  // 13: sentinel.v1.SubtaskResult.status:type_name -> sentinel.v1.JobStatus
+	0,  // 14: sentinel.v1.JobEvent.status:type_name -> sentinel.v1.JobStatus
+	12, // 15: sentinel.v1.JobEvent.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 16: sentinel.v1.Sentinel.Submit:input_type -> sentinel.v1.SubmitRequest
+	3,  // 17: sentinel.v1.Sentinel.Get:input_type -> sentinel.v1.GetRequest
+	4,  // 18: sentinel.v1.Sentinel.Watch:input_type -> sentinel.v1.WatchRequest
+	2,  // 19: sentinel.v1.Sentinel.Submit:output_type -> sentinel.v1.SubmitResponse
+	6,  // 20: sentinel.v1.Sentinel.Get:output_type -> sentinel.v1.Job
+	5,  // 21: sentinel.v1.Sentinel.Watch:output_type -> sentinel.v1.WatchResponse
+	19, // [19:22] is the sub-list for method output_type
+	16, // [16:19] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_sentinel_v1_sentinel_proto_init() }
+func file_sentinel_v1_sentinel_proto_init() {
+	if File_sentinel_v1_sentinel_proto != nil {
+		return
+	}
+	file_sentinel_v1_sentinel_proto_msgTypes[0].OneofWrappers = []any{}
+	file_sentinel_v1_sentinel_proto_msgTypes[6].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sentinel_v1_sentinel_proto_rawDesc), len(file_sentinel_v1_sentinel_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sentinel_v1_sentinel_proto_goTypes,
+		DependencyIndexes: file_sentinel_v1_sentinel_proto_depIdxs,
+		EnumInfos:         file_sentinel_v1_sentinel_proto_enumTypes,
+		MessageInfos:      file_sentinel_v1_sentinel_proto_msgTypes,
+	}.Build()
+	File_sentinel_v1_sentinel_proto = out.File
+	file_sentinel_v1_sentinel_proto_goTypes = nil
+	file_sentinel_v1_sentinel_proto_depIdxs = nil
+}
@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: sentinel/v1/sentinel.proto
+
+// Sentinel's gRPC API: submitting jobs, fetching them and streaming their
+// status changes. It is served alongside the REST API on API_GRPC_PORT and
+// shares its validation, quotas and errors; see docs/api.md.
+
+package sentinelv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Sentinel_Submit_FullMethodName = "/sentinel.v1.Sentinel/Submit"
+	Sentinel_Get_FullMethodName    = "/sentinel.v1.Sentinel/Get"
+	Sentinel_Watch_FullMethodName  = "/sentinel.v1.Sentinel/Watch"
+)
+
+// SentinelClient is the client API for Sentinel service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SentinelClient interface {
+	// Submit validates and queues a job, as POST /api/v2/submissions does.
+	// The tenant is read from the x-tenant-id metadata key.
+	Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error)
+	// Get returns a job with its result so far.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error)
+	// Watch streams a job each time its status changes, starting with its
+	// current state, and ends after the job reaches a terminal status.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error)
+}
+
+type sentinelClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSentinelClient(cc grpc.ClientConnInterface) SentinelClient {
+	return &sentinelClient{cc}
+}
+
+func (c *sentinelClient) Submit(ctx context.Context, in *SubmitRequest, opts ...grpc.CallOption) (*SubmitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitResponse)
+	err := c.cc.Invoke(ctx, Sentinel_Submit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Sentinel_Get_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sentinel_ServiceDesc.Streams[0], Sentinel_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sentinel_WatchClient = grpc.ServerStreamingClient[WatchResponse]
+
+// SentinelServer is the server API for Sentinel service.
+// All implementations must embed UnimplementedSentinelServer
+// for forward compatibility.
+type SentinelServer interface {
+	// Submit validates and queues a job, as POST /api/v2/submissions does.
+	// The tenant is read from the x-tenant-id metadata key.
+	Submit(context.Context, *SubmitRequest) (*SubmitResponse, error)
+	// Get returns a job with its result so far.
+	Get(context.Context, *GetRequest) (*Job, error)
+	// Watch streams a job each time its status changes, starting with its
+	// current state, and ends after the job reaches a terminal status.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error
+	mustEmbedUnimplementedSentinelServer()
+}
+
+// UnimplementedSentinelServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSentinelServer struct{}
+
+func (UnimplementedSentinelServer) Submit(context.Context, *SubmitRequest) (*SubmitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedSentinelServer) Get(context.Context, *GetRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedSentinelServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedSentinelServer) mustEmbedUnimplementedSentinelServer() {}
+func (UnimplementedSentinelServer) testEmbeddedByValue()                  {}
+
+// UnsafeSentinelServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SentinelServer will
+// result in compilation errors.
+type UnsafeSentinelServer interface {
+	mustEmbedUnimplementedSentinelServer()
+}
+
+func RegisterSentinelServer(s grpc.ServiceRegistrar, srv SentinelServer) {
+	// If the following call pancis, it indicates UnimplementedSentinelServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Sentinel_ServiceDesc, srv)
+}
+
+func _Sentinel_Submit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServer).Submit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentinel_Submit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServer).Submit(ctx, req.(*SubmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentinel_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sentinel_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sentinel_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SentinelServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sentinel_WatchServer = grpc.ServerStreamingServer[WatchResponse]
+
+// Sentinel_ServiceDesc is the grpc.ServiceDesc for Sentinel service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sentinel_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sentinel.v1.Sentinel",
+	HandlerType: (*SentinelServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Submit",
+			Handler:    _Sentinel_Submit_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Sentinel_Get_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Sentinel_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sentinel/v1/sentinel.proto",
+}
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback tenant attribution
+-- =============================================================================
+
+DROP INDEX IF EXISTS idx_jobs_tenant;
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS tenant_id;
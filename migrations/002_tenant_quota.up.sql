@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Tenant attribution for execution quotas
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX idx_jobs_tenant ON execution_jobs(tenant_id, created_at);
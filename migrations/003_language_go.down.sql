@@ -0,0 +1,13 @@
+-- =============================================================================
+-- Project Sentinel — Rollback Go language support
+-- =============================================================================
+-- PostgreSQL cannot drop a value from an enum, so the type is rebuilt without
+-- 'go'. Any remaining Go jobs must be removed first.
+
+DELETE FROM execution_jobs WHERE language = 'go';
+
+ALTER TYPE language RENAME TO language_old;
+CREATE TYPE language AS ENUM ('python', 'cpp');
+ALTER TABLE execution_jobs
+    ALTER COLUMN language TYPE language USING language::text::language;
+DROP TYPE language_old;
@@ -0,0 +1,13 @@
+-- =============================================================================
+-- Project Sentinel — Rollback JavaScript language support
+-- =============================================================================
+-- PostgreSQL cannot drop a value from an enum, so the type is rebuilt without
+-- 'javascript'. Any remaining JavaScript jobs must be removed first.
+
+DELETE FROM execution_jobs WHERE language = 'javascript';
+
+ALTER TYPE language RENAME TO language_old;
+CREATE TYPE language AS ENUM ('python', 'cpp', 'go');
+ALTER TABLE execution_jobs
+    ALTER COLUMN language TYPE language USING language::text::language;
+DROP TYPE language_old;
@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Add JavaScript (Node.js) to the supported languages
+-- =============================================================================
+
+ALTER TYPE language ADD VALUE IF NOT EXISTS 'javascript';
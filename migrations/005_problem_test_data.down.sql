@@ -0,0 +1,16 @@
+-- =============================================================================
+-- Project Sentinel — Rollback problems and test data
+-- =============================================================================
+-- The ACCEPTED / WRONG_ANSWER enum values are left in place: PostgreSQL
+-- cannot drop enum values and they are harmless without the judging columns.
+
+DROP INDEX IF EXISTS idx_jobs_problem_version;
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS test_results,
+    DROP COLUMN IF EXISTS judge_revision,
+    DROP COLUMN IF EXISTS test_data_version,
+    DROP COLUMN IF EXISTS problem_id;
+
+DROP TABLE IF EXISTS problem_test_cases;
+DROP TRIGGER IF EXISTS trg_problems_updated_at ON problems;
+DROP TABLE IF EXISTS problems;
@@ -0,0 +1,39 @@
+-- =============================================================================
+-- Project Sentinel — Problems with versioned test data
+-- =============================================================================
+
+ALTER TYPE execution_status ADD VALUE IF NOT EXISTS 'ACCEPTED';
+ALTER TYPE execution_status ADD VALUE IF NOT EXISTS 'WRONG_ANSWER';
+
+CREATE TABLE problems (
+    problem_id        TEXT PRIMARY KEY,
+    title             TEXT NOT NULL DEFAULT '',
+    test_data_version INT NOT NULL DEFAULT 1,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trg_problems_updated_at
+    BEFORE UPDATE ON problems
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
+
+-- Every version's cases are kept so past verdicts stay explainable.
+CREATE TABLE problem_test_cases (
+    problem_id      TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version         INT NOT NULL,
+    ordinal         INT NOT NULL,
+    input           TEXT NOT NULL DEFAULT '',
+    expected_output TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (problem_id, version, ordinal)
+);
+
+ALTER TABLE execution_jobs
+    ADD COLUMN problem_id        TEXT,
+    ADD COLUMN test_data_version INT,
+    ADD COLUMN judge_revision    INT NOT NULL DEFAULT 0,
+    ADD COLUMN test_results      JSONB;
+
+-- Rejudge lookups: submissions of a problem judged on an older version.
+CREATE INDEX idx_jobs_problem_version ON execution_jobs(problem_id, test_data_version)
+    WHERE problem_id IS NOT NULL;
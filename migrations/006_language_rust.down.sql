@@ -0,0 +1,13 @@
+-- =============================================================================
+-- Project Sentinel — Rollback Rust language support
+-- =============================================================================
+-- PostgreSQL cannot drop a value from an enum, so the type is rebuilt without
+-- 'rust'. Any remaining Rust jobs must be removed first.
+
+DELETE FROM execution_jobs WHERE language = 'rust';
+
+ALTER TYPE language RENAME TO language_old;
+CREATE TYPE language AS ENUM ('python', 'cpp', 'go', 'javascript');
+ALTER TABLE execution_jobs
+    ALTER COLUMN language TYPE language USING language::text::language;
+DROP TYPE language_old;
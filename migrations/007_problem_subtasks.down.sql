@@ -0,0 +1,13 @@
+-- =============================================================================
+-- Project Sentinel — Rollback subtasks and partial scoring
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS subtask_results,
+    DROP COLUMN IF EXISTS max_score,
+    DROP COLUMN IF EXISTS score;
+
+ALTER TABLE problem_test_cases
+    DROP COLUMN IF EXISTS subtask;
+
+DROP TABLE IF EXISTS problem_subtasks;
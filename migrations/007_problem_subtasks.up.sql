@@ -0,0 +1,21 @@
+-- =============================================================================
+-- Project Sentinel — Subtasks and partial scoring
+-- =============================================================================
+
+-- Subtasks are versioned with the test data they group.
+CREATE TABLE problem_subtasks (
+    problem_id TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version    INT NOT NULL,
+    ordinal    INT NOT NULL,
+    points     DOUBLE PRECISION NOT NULL CHECK (points >= 0),
+    PRIMARY KEY (problem_id, version, ordinal)
+);
+
+-- NULL marks an ungraded case (e.g. a sample).
+ALTER TABLE problem_test_cases
+    ADD COLUMN subtask INT;
+
+ALTER TABLE execution_jobs
+    ADD COLUMN score           DOUBLE PRECISION,
+    ADD COLUMN max_score       DOUBLE PRECISION,
+    ADD COLUMN subtask_results JSONB;
@@ -0,0 +1,11 @@
+-- =============================================================================
+-- Project Sentinel — Rollback registry-driven language column
+-- =============================================================================
+-- Jobs in languages outside the built-in enum must be removed first.
+
+DELETE FROM execution_jobs
+    WHERE language NOT IN ('python', 'cpp', 'go', 'javascript', 'rust');
+
+CREATE TYPE language AS ENUM ('python', 'cpp', 'go', 'javascript', 'rust');
+ALTER TABLE execution_jobs
+    ALTER COLUMN language TYPE language USING language::language;
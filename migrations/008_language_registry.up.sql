@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Language column driven by the language registry
+-- =============================================================================
+-- Supported languages now come from sandbox/languages.yaml, so the column is
+-- plain text and adding a language no longer needs a schema change.
+
+ALTER TABLE execution_jobs
+    ALTER COLUMN language TYPE TEXT USING language::text;
+DROP TYPE language;
@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-test-case limit overrides
+-- =============================================================================
+
+ALTER TABLE problem_test_cases
+    DROP COLUMN IF EXISTS memory_limit_kb,
+    DROP COLUMN IF EXISTS time_limit_ms;
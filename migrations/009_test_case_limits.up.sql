@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Per-test-case limit overrides
+-- =============================================================================
+
+-- NULL inherits the submission's limit.
+ALTER TABLE problem_test_cases
+    ADD COLUMN time_limit_ms   INT CHECK (time_limit_ms > 0),
+    ADD COLUMN memory_limit_kb INT CHECK (memory_limit_kb > 0);
@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Rollback early-termination strategies
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS termination_strategy;
+
+ALTER TABLE problems
+    DROP COLUMN IF EXISTS termination_strategy;
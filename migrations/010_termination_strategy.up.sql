@@ -0,0 +1,11 @@
+-- =============================================================================
+-- Project Sentinel — Early-termination strategies for judging
+-- =============================================================================
+
+ALTER TABLE problems
+    ADD COLUMN termination_strategy TEXT NOT NULL DEFAULT 'run_all'
+        CHECK (termination_strategy IN ('run_all', 'first_failure', 'per_subtask'));
+
+-- The strategy a judged job actually ran with; NULL for plain executions.
+ALTER TABLE execution_jobs
+    ADD COLUMN termination_strategy TEXT;
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-submission compiler flags
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS compiler_flags;
@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Per-submission compiler flags
+-- =============================================================================
+
+-- Checked against the language's allowlist by the worker; NULL when none.
+ALTER TABLE execution_jobs
+    ADD COLUMN compiler_flags TEXT[];
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback command-line arguments
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS args;
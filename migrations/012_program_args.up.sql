@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Command-line arguments for executed programs
+-- =============================================================================
+
+-- Passed to the program after its run command; NULL when none.
+ALTER TABLE execution_jobs
+    ADD COLUMN args TEXT[];
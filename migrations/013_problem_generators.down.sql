@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Rollback generator-based test data
+-- =============================================================================
+
+ALTER TABLE problem_test_cases
+    DROP COLUMN IF EXISTS generator_seed;
+
+DROP TABLE IF EXISTS problem_generators;
@@ -0,0 +1,17 @@
+-- =============================================================================
+-- Project Sentinel — Generator-based test data
+-- =============================================================================
+
+-- A generator is versioned with the test data whose inputs it produces.
+CREATE TABLE problem_generators (
+    problem_id  TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version     INT NOT NULL,
+    language    TEXT NOT NULL,
+    source_code TEXT NOT NULL,
+    source_hash TEXT NOT NULL,
+    PRIMARY KEY (problem_id, version)
+);
+
+-- A seeded case's input is the generator's output for that seed.
+ALTER TABLE problem_test_cases
+    ADD COLUMN generator_seed BIGINT;
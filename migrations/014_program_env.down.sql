@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback program environment variables
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS env;
@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Environment variables for executed programs
+-- =============================================================================
+
+-- JSON object of variable name to value, set for the program only; NULL when none.
+ALTER TABLE execution_jobs
+    ADD COLUMN env JSONB;
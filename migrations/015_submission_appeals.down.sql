@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback appeals and verdict overrides
+-- =============================================================================
+
+DROP TABLE IF EXISTS verdict_overrides;
+DROP TABLE IF EXISTS submission_appeals;
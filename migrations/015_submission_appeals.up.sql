@@ -0,0 +1,46 @@
+-- =============================================================================
+-- Project Sentinel — Appeals on judged submissions and verdict overrides
+-- =============================================================================
+
+CREATE TABLE submission_appeals (
+    appeal_id  UUID PRIMARY KEY,
+    job_id     UUID NOT NULL REFERENCES execution_jobs(job_id) ON DELETE CASCADE,
+    tenant_id  TEXT NOT NULL,
+    status     TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    note       TEXT NOT NULL,
+    response   TEXT,
+    closed_by  TEXT,
+    closed_at  TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- At most one open appeal per submission.
+CREATE UNIQUE INDEX idx_submission_appeals_open
+    ON submission_appeals (job_id)
+    WHERE status = 'open';
+
+CREATE INDEX idx_submission_appeals_status
+    ON submission_appeals (status, created_at);
+
+CREATE TRIGGER trg_submission_appeals_updated_at
+    BEFORE UPDATE ON submission_appeals
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
+
+-- Audit trail of admin verdict changes; rows are never updated or deleted.
+CREATE TABLE verdict_overrides (
+    override_id     UUID PRIMARY KEY,
+    appeal_id       UUID NOT NULL REFERENCES submission_appeals(appeal_id) ON DELETE CASCADE,
+    job_id          UUID NOT NULL REFERENCES execution_jobs(job_id) ON DELETE CASCADE,
+    previous_status TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    previous_score  DOUBLE PRECISION,
+    score           DOUBLE PRECISION,
+    reason          TEXT NOT NULL,
+    actor           TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_verdict_overrides_appeal
+    ON verdict_overrides (appeal_id, created_at);
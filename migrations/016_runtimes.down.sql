@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback API-registered runtimes
+-- =============================================================================
+
+DROP TABLE IF EXISTS runtimes;
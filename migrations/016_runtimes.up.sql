@@ -0,0 +1,23 @@
+-- =============================================================================
+-- Project Sentinel — Runtimes registered through the API
+-- =============================================================================
+
+-- Execution presets that submissions name as their language. The worker
+-- reads them when a language is not in its built-in registry.
+CREATE TABLE runtimes (
+    name          TEXT PRIMARY KEY,
+    version       TEXT NOT NULL DEFAULT '',
+    source_file   TEXT NOT NULL,
+    nsjail_config TEXT NOT NULL,
+    compile_cmd   TEXT[] NOT NULL DEFAULT '{}',
+    run_cmd       TEXT[] NOT NULL,
+    mounts        JSONB NOT NULL DEFAULT '[]',
+    env           JSONB NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trg_runtimes_updated_at
+    BEFORE UPDATE ON runtimes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
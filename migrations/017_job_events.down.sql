@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Rollback job status timeline
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS trg_execution_jobs_status_event ON execution_jobs;
+DROP TRIGGER IF EXISTS trg_execution_jobs_created_event ON execution_jobs;
+DROP FUNCTION IF EXISTS record_job_event();
+DROP TABLE IF EXISTS job_events;
@@ -0,0 +1,33 @@
+-- =============================================================================
+-- Project Sentinel — Job status timeline
+-- =============================================================================
+
+-- One row per status a job has been in, written by triggers so every path
+-- that changes a job's status (API, worker, rejudges) is recorded.
+CREATE TABLE job_events (
+    event_id   BIGSERIAL PRIMARY KEY,
+    job_id     UUID NOT NULL REFERENCES execution_jobs(job_id) ON DELETE CASCADE,
+    status     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_events_job ON job_events (job_id, event_id);
+
+CREATE OR REPLACE FUNCTION record_job_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO job_events (job_id, status) VALUES (NEW.job_id, NEW.status::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_execution_jobs_created_event
+    AFTER INSERT ON execution_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION record_job_event();
+
+CREATE TRIGGER trg_execution_jobs_status_event
+    AFTER UPDATE OF status ON execution_jobs
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION record_job_event();
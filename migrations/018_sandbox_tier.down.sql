@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback requested sandbox tier
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS sandbox_tier;
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Requested sandbox tier per job
+-- =============================================================================
+
+-- Isolation class the submission asked for; workers map tiers to executor
+-- backends. NULL uses the worker's tenant, language and default rules.
+ALTER TABLE execution_jobs
+    ADD COLUMN sandbox_tier TEXT;
@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Rollback separate CPU-time limits
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS cpu_time_used_ms,
+    DROP COLUMN IF EXISTS cpu_time_limit_ms;
@@ -0,0 +1,10 @@
+-- =============================================================================
+-- Project Sentinel — Separate CPU-time limits
+-- =============================================================================
+
+-- time_limit_ms remains the wall-clock limit. cpu_time_limit_ms is set only
+-- when the submission asked for a separate CPU-time limit; NULL applies the
+-- wall-clock limit to CPU time as well.
+ALTER TABLE execution_jobs
+    ADD COLUMN cpu_time_limit_ms INTEGER,
+    ADD COLUMN cpu_time_used_ms  INTEGER;
@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-tenant dead-letter webhooks
+-- =============================================================================
+
+DROP TABLE IF EXISTS tenant_dlq_webhooks;
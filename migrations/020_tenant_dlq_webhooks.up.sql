@@ -0,0 +1,19 @@
+-- =============================================================================
+-- Project Sentinel — Per-tenant dead-letter webhooks
+-- =============================================================================
+
+-- One endpoint per tenant, notified by workers when one of the tenant's jobs
+-- is dead-lettered or quarantined. Bodies are signed with HMAC-SHA256 using
+-- secret.
+CREATE TABLE tenant_dlq_webhooks (
+    tenant_id  TEXT PRIMARY KEY,
+    url        TEXT NOT NULL,
+    secret     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trg_tenant_dlq_webhooks_updated_at
+    BEFORE UPDATE ON tenant_dlq_webhooks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback work directory disk usage
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS disk_used_kb;
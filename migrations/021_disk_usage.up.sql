@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Work directory disk usage
+-- =============================================================================
+
+-- disk_used_kb is what the sandbox work directory held when the run ended;
+-- NULL for jobs that ran before it was recorded.
+ALTER TABLE execution_jobs
+    ADD COLUMN disk_used_kb INTEGER;
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-job process limits
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS pids_limit;
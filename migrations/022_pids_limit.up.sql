@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Per-job process limits
+-- =============================================================================
+
+-- pids_limit overrides the process limit of the language's sandbox profile
+-- for the run phase; NULL keeps the profile's limit.
+ALTER TABLE execution_jobs
+    ADD COLUMN pids_limit INTEGER;
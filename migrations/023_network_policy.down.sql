@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-job network policy
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS network_policy;
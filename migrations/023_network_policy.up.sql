@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Per-job network policy
+-- =============================================================================
+
+-- network_policy is 'allowlist' for runs allowed to reach the workers'
+-- network allowlist; NULL runs without network access.
+ALTER TABLE execution_jobs
+    ADD COLUMN network_policy TEXT;
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Rollback output artifacts
+-- =============================================================================
+
+DROP TABLE IF EXISTS job_artifacts;
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS output_files;
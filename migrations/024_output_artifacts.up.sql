@@ -0,0 +1,21 @@
+-- =============================================================================
+-- Project Sentinel — Output artifacts
+-- =============================================================================
+
+-- output_files lists the files, by name in the sandbox work directory, a
+-- job asked to have collected after its run; NULL collects none.
+ALTER TABLE execution_jobs
+    ADD COLUMN output_files TEXT[];
+
+-- One row per declared output file the program produced. size_bytes is the
+-- file's size in the sandbox; content is cut to the worker's limit when
+-- truncated is set. Rewritten with every result, so a rejudge replaces them.
+CREATE TABLE job_artifacts (
+    job_id     UUID NOT NULL REFERENCES execution_jobs(job_id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    content    BYTEA NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    truncated  BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (job_id, name)
+);
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback interactive jobs
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS interactive;
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Interactive jobs
+-- =============================================================================
+
+-- Interactive jobs read stdin from their submission stream while they run
+-- instead of only from the stdin column; the input itself lives in Redis.
+ALTER TABLE execution_jobs
+    ADD COLUMN interactive BOOLEAN NOT NULL DEFAULT FALSE;
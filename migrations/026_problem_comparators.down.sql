@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback WebAssembly comparators
+-- =============================================================================
+
+DROP TABLE IF EXISTS problem_comparators;
@@ -0,0 +1,13 @@
+-- =============================================================================
+-- Project Sentinel — WebAssembly comparators
+-- =============================================================================
+
+-- A comparator replaces the token comparison for one version of a problem's
+-- test data. Workers cache compiled modules by wasm_hash.
+CREATE TABLE problem_comparators (
+    problem_id TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version    INT NOT NULL,
+    wasm       BYTEA NOT NULL,
+    wasm_hash  TEXT NOT NULL,
+    PRIMARY KEY (problem_id, version)
+);
@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Rollback expected output of standalone submissions
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS compare_mode,
+    DROP COLUMN IF EXISTS expected_output;
@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Expected output of standalone submissions
+-- =============================================================================
+
+-- A submission outside any problem may carry the output it should print; the
+-- worker then reports ACCEPTED or WRONG_ANSWER instead of SUCCESS.
+ALTER TABLE execution_jobs
+    ADD COLUMN expected_output TEXT,
+    ADD COLUMN compare_mode    TEXT;
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-test subtask scoring
+-- =============================================================================
+
+ALTER TABLE problem_subtasks
+    DROP COLUMN IF EXISTS scoring;
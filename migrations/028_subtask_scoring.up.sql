@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Per-test subtask scoring
+-- =============================================================================
+
+-- per_test subtasks award their points in proportion to the accepted cases;
+-- existing subtasks keep all-or-nothing scoring.
+ALTER TABLE problem_subtasks
+    ADD COLUMN scoring TEXT NOT NULL DEFAULT 'all_or_nothing'
+        CHECK (scoring IN ('all_or_nothing', 'per_test'));
@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback interactive problems
+-- =============================================================================
+
+DROP TABLE IF EXISTS problem_interactors;
@@ -0,0 +1,12 @@
+-- =============================================================================
+-- Project Sentinel — Interactive problems
+-- =============================================================================
+
+-- An interactor is versioned with the test data it converses over.
+CREATE TABLE problem_interactors (
+    problem_id  TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version     INT NOT NULL,
+    language    TEXT NOT NULL,
+    source_code TEXT NOT NULL,
+    PRIMARY KEY (problem_id, version)
+);
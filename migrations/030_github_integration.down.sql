@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback GitHub submission intake
+-- =============================================================================
+
+DROP TABLE IF EXISTS github_checks;
+DROP TABLE IF EXISTS github_repos;
@@ -0,0 +1,32 @@
+-- =============================================================================
+-- Project Sentinel — GitHub submission intake
+-- =============================================================================
+
+-- A repository's webhook deliveries submit the source at path. One tenant
+-- owns each repository; secret verifies deliveries and token reads contents
+-- and writes commit statuses.
+CREATE TABLE github_repos (
+    repo       TEXT PRIMARY KEY,
+    tenant_id  TEXT NOT NULL,
+    language   TEXT NOT NULL,
+    problem_id TEXT REFERENCES problems(problem_id) ON DELETE CASCADE,
+    path       TEXT NOT NULL,
+    secret     TEXT NOT NULL,
+    token      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trg_github_repos_updated_at
+    BEFORE UPDATE ON github_repos
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
+
+-- Jobs submitted from a commit whose verdict is not yet posted back. A row
+-- is deleted once the commit status is set.
+CREATE TABLE github_checks (
+    job_id     UUID PRIMARY KEY REFERENCES execution_jobs(job_id) ON DELETE CASCADE,
+    repo       TEXT NOT NULL,
+    sha        TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
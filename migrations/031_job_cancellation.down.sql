@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Rollback job cancellation
+-- =============================================================================
+-- The CANCELLED enum value is left in place: PostgreSQL cannot drop enum
+-- values. Cancelled jobs are marked as internal errors so that older code,
+-- which does not know the status, reads them as finished.
+
+UPDATE execution_jobs SET status = 'INTERNAL_ERROR' WHERE status = 'CANCELLED';
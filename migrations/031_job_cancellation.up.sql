@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Job cancellation
+-- =============================================================================
+
+-- A job cancelled through the API before it finished. Workers do not start
+-- a job in this status.
+ALTER TYPE execution_status ADD VALUE IF NOT EXISTS 'CANCELLED';
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Rollback LTI 1.3 tool
+-- =============================================================================
+
+DROP TABLE IF EXISTS lti_scores;
+DROP TABLE IF EXISTS lti_launches;
+DROP TABLE IF EXISTS lti_users;
+DROP TABLE IF EXISTS lti_platforms;
@@ -0,0 +1,57 @@
+-- =============================================================================
+-- Project Sentinel — LTI 1.3 tool
+-- =============================================================================
+
+-- Learning platforms registered to launch Sentinel. A platform is
+-- identified in launches by its issuer and client ID; tenant_mapping
+-- decides whether its users share tenant_id or get tenants of their own.
+CREATE TABLE lti_platforms (
+    platform_id    UUID PRIMARY KEY,
+    tenant_id      TEXT NOT NULL,
+    issuer         TEXT NOT NULL,
+    client_id      TEXT NOT NULL,
+    deployment_id  TEXT NOT NULL DEFAULT '',
+    auth_login_url TEXT NOT NULL,
+    token_url      TEXT NOT NULL,
+    jwks_url       TEXT NOT NULL,
+    tenant_mapping TEXT NOT NULL DEFAULT 'platform'
+                   CHECK (tenant_mapping IN ('platform', 'context', 'user')),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (issuer, client_id)
+);
+
+-- Users who have launched from a platform. The tenant is fixed at the
+-- first launch.
+CREATE TABLE lti_users (
+    platform_id     UUID NOT NULL REFERENCES lti_platforms(platform_id) ON DELETE CASCADE,
+    subject         TEXT NOT NULL,
+    tenant_id       TEXT NOT NULL,
+    name            TEXT NOT NULL DEFAULT '',
+    email           TEXT NOT NULL DEFAULT '',
+    first_launch_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_launch_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (platform_id, subject)
+);
+
+CREATE INDEX idx_lti_users_last_launch ON lti_users (platform_id, last_launch_at DESC);
+
+-- Verified launches. line_item is set when the platform grants score
+-- passback for the assignment.
+CREATE TABLE lti_launches (
+    launch_id   UUID PRIMARY KEY,
+    platform_id UUID NOT NULL REFERENCES lti_platforms(platform_id) ON DELETE CASCADE,
+    subject     TEXT NOT NULL,
+    tenant_id   TEXT NOT NULL,
+    problem_id  TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    line_item   TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL
+);
+
+-- Jobs submitted from a graded launch whose score is not yet posted. A row
+-- is deleted once the platform accepts the score.
+CREATE TABLE lti_scores (
+    job_id     UUID PRIMARY KEY REFERENCES execution_jobs(job_id) ON DELETE CASCADE,
+    launch_id  UUID NOT NULL REFERENCES lti_launches(launch_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback data warehouse export
+-- =============================================================================
+
+DROP TABLE IF EXISTS export_cursors;
@@ -0,0 +1,11 @@
+-- =============================================================================
+-- Project Sentinel — Data warehouse export
+-- =============================================================================
+
+-- How far each warehouse sink has been exported: the last job event whose
+-- verdict it was written.
+CREATE TABLE export_cursors (
+    sink       TEXT PRIMARY KEY,
+    event_id   BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
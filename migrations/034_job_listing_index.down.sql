@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback job listing index
+-- =============================================================================
+
+DROP INDEX IF EXISTS idx_jobs_status_job_id;
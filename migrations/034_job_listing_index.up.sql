@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Job listing index
+-- =============================================================================
+
+-- GET /admin/jobs pages through jobs by descending job_id (UUIDv7, so by
+-- creation time). Unfiltered pages scan the primary key; this index serves
+-- pages filtered by status.
+CREATE INDEX idx_jobs_status_job_id ON execution_jobs (status, job_id DESC);
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback function-only grading harnesses
+-- =============================================================================
+
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS solution_code;
+DROP TABLE IF EXISTS problem_harnesses;
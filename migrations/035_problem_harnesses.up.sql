@@ -0,0 +1,18 @@
+-- =============================================================================
+-- Project Sentinel — Function-only grading harnesses
+-- =============================================================================
+
+-- A harness is the driver program a problem wraps around submissions in one
+-- language. Like the interactor, it is versioned with the test data whose
+-- input format it reads.
+CREATE TABLE problem_harnesses (
+    problem_id TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version    INT NOT NULL,
+    language   TEXT NOT NULL,
+    template   TEXT NOT NULL,
+    PRIMARY KEY (problem_id, version, language)
+);
+
+-- The function as submitted, for jobs whose source_code is the assembled
+-- program. NULL when no harness was applied.
+ALTER TABLE execution_jobs ADD COLUMN solution_code TEXT;
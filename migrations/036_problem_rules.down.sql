@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-problem forbidden source rules
+-- =============================================================================
+
+-- The RULE_VIOLATION enum value is left in place: PostgreSQL cannot drop enum
+-- values. Jobs holding it are marked as internal errors instead.
+UPDATE execution_jobs SET status = 'INTERNAL_ERROR' WHERE status = 'RULE_VIOLATION';
+
+DROP TABLE IF EXISTS problem_rules;
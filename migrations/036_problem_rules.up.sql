@@ -0,0 +1,18 @@
+-- =============================================================================
+-- Project Sentinel — Per-problem forbidden source rules
+-- =============================================================================
+
+-- A judged submission whose source matches one of its problem's rules is
+-- given this verdict by the API and never reaches a worker.
+ALTER TYPE execution_status ADD VALUE IF NOT EXISTS 'RULE_VIOLATION';
+
+-- Rules are versioned with the test data, in the order they are checked.
+CREATE TABLE problem_rules (
+    problem_id TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version    INT NOT NULL,
+    ordinal    INT NOT NULL,
+    language   TEXT NOT NULL,
+    pattern    TEXT NOT NULL,
+    message    TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (problem_id, version, ordinal)
+);
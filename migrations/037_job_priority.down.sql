@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback job priority
+-- =============================================================================
+
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Job priority
+-- =============================================================================
+
+-- The queue priority a job was submitted with, kept so that reruns and
+-- republished stale jobs are queued at it again. Existing jobs get the
+-- default priority (domain.DefaultPriority).
+ALTER TABLE execution_jobs ADD COLUMN priority SMALLINT NOT NULL DEFAULT 5;
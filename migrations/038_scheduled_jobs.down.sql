@@ -0,0 +1,10 @@
+-- =============================================================================
+-- Project Sentinel — Rollback scheduled execution
+-- =============================================================================
+
+-- The SCHEDULED enum value is left in place: PostgreSQL cannot drop enum
+-- values. Jobs still waiting are cancelled, since nothing would queue them.
+UPDATE execution_jobs SET status = 'CANCELLED' WHERE status = 'SCHEDULED';
+
+DROP INDEX IF EXISTS idx_jobs_scheduled_run_at;
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS run_at;
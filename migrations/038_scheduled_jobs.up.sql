@@ -0,0 +1,15 @@
+-- =============================================================================
+-- Project Sentinel — Scheduled execution
+-- =============================================================================
+
+-- A job submitted with a future run_at waits as SCHEDULED until the API's
+-- scheduler moves it to QUEUED and publishes it.
+ALTER TYPE execution_status ADD VALUE IF NOT EXISTS 'SCHEDULED';
+
+ALTER TABLE execution_jobs ADD COLUMN run_at TIMESTAMPTZ;
+
+-- The scheduler polls for due jobs. Only jobs ever scheduled are indexed;
+-- the predicate avoids the new enum value, which cannot be used in the
+-- transaction that adds it.
+CREATE INDEX idx_jobs_scheduled_run_at ON execution_jobs (run_at)
+    WHERE run_at IS NOT NULL;
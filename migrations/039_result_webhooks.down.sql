@@ -0,0 +1,10 @@
+-- =============================================================================
+-- Project Sentinel — Rollback result webhooks
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS trg_execution_jobs_status_delivery ON execution_jobs;
+DROP TRIGGER IF EXISTS trg_execution_jobs_created_delivery ON execution_jobs;
+DROP FUNCTION IF EXISTS record_result_delivery();
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS tenant_result_webhooks;
@@ -0,0 +1,91 @@
+-- =============================================================================
+-- Project Sentinel — Result webhooks with tracked deliveries
+-- =============================================================================
+
+-- One endpoint per tenant, sent every job of the tenant's that finishes.
+-- Bodies are signed with HMAC-SHA256 using secret. consecutive_failures and
+-- circuit_open_until are the endpoint's circuit breaker, shared by every API
+-- replica.
+CREATE TABLE tenant_result_webhooks (
+    tenant_id            TEXT PRIMARY KEY,
+    url                  TEXT NOT NULL,
+    secret               TEXT NOT NULL,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    circuit_open_until   TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trg_tenant_result_webhooks_updated_at
+    BEFORE UPDATE ON tenant_result_webhooks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
+
+-- One row per job result owed to a tenant's result webhook. attempts counts
+-- the attempts since the delivery was recorded or last redriven.
+CREATE TABLE webhook_deliveries (
+    delivery_id     BIGSERIAL PRIMARY KEY,
+    tenant_id       TEXT NOT NULL,
+    job_id          UUID NOT NULL REFERENCES execution_jobs(job_id) ON DELETE CASCADE,
+    job_status      TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending'
+                    CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    redrives        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_tenant ON webhook_deliveries (tenant_id, delivery_id);
+CREATE INDEX idx_webhook_deliveries_job ON webhook_deliveries (job_id);
+
+CREATE TRIGGER trg_webhook_deliveries_updated_at
+    BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
+
+-- Every POST made for a delivery. status_code is NULL when no response
+-- arrived.
+CREATE TABLE webhook_delivery_attempts (
+    attempt_id   BIGSERIAL PRIMARY KEY,
+    delivery_id  BIGINT NOT NULL REFERENCES webhook_deliveries(delivery_id) ON DELETE CASCADE,
+    url          TEXT NOT NULL,
+    status_code  INTEGER,
+    error        TEXT,
+    duration_ms  BIGINT NOT NULL,
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts (delivery_id, attempt_id);
+
+-- Deliveries are recorded by triggers, in the transaction that finishes the
+-- job, so that no path that finishes a job (worker, API, rejudges) can lose
+-- one.
+CREATE OR REPLACE FUNCTION record_result_delivery()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status::TEXT NOT IN ('SCHEDULED', 'QUEUED', 'COMPILING', 'RUNNING') THEN
+        INSERT INTO webhook_deliveries (tenant_id, job_id, job_status)
+        SELECT w.tenant_id, NEW.job_id, NEW.status::TEXT
+        FROM tenant_result_webhooks w
+        WHERE w.tenant_id = NEW.tenant_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_execution_jobs_created_delivery
+    AFTER INSERT ON execution_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION record_result_delivery();
+
+CREATE TRIGGER trg_execution_jobs_status_delivery
+    AFTER UPDATE OF status ON execution_jobs
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION record_result_delivery();
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Rollback job lineage
+-- =============================================================================
+
+DROP INDEX IF EXISTS idx_jobs_parent_job_id;
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS parent_job_id,
+    DROP COLUMN IF EXISTS origin;
@@ -0,0 +1,17 @@
+-- =============================================================================
+-- Project Sentinel — Job lineage
+-- =============================================================================
+
+-- Every job records how it was created. A resubmission (POST
+-- /submissions/:id/rerun) points at the job it was created from; rejudges,
+-- appeal reruns and requeues run a job in place and are counted by its
+-- QUEUED events instead.
+ALTER TABLE execution_jobs
+    ADD COLUMN origin TEXT NOT NULL DEFAULT 'user'
+        CHECK (origin IN ('user', 'resubmit')),
+    ADD COLUMN parent_job_id UUID
+        REFERENCES execution_jobs(job_id) ON DELETE SET NULL;
+
+-- Descendants are found by walking parent_job_id downwards.
+CREATE INDEX idx_jobs_parent_job_id ON execution_jobs (parent_job_id)
+    WHERE parent_job_id IS NOT NULL;
@@ -0,0 +1,12 @@
+-- =============================================================================
+-- Project Sentinel — Rollback recurring schedules
+-- =============================================================================
+
+-- Jobs created by schedules are kept as user submissions.
+ALTER TABLE execution_jobs DROP CONSTRAINT execution_jobs_origin_check;
+UPDATE execution_jobs SET origin = 'user' WHERE origin = 'schedule';
+ALTER TABLE execution_jobs ADD CONSTRAINT execution_jobs_origin_check
+    CHECK (origin IN ('user', 'resubmit'));
+
+DROP TRIGGER IF EXISTS trg_job_schedules_updated_at ON job_schedules;
+DROP TABLE IF EXISTS job_schedules;
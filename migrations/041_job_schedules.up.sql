@@ -0,0 +1,35 @@
+-- =============================================================================
+-- Project Sentinel — Recurring schedules
+-- =============================================================================
+
+-- A schedule creates a job from its template, a submission as JSON, each
+-- time its cron expression fires. The API's schedule runner claims a run by
+-- moving next_run_at forward, so each run creates at most one job however
+-- many replicas poll. last_job_id is the job the latest run created;
+-- last_error says why it created none.
+CREATE TABLE job_schedules (
+    schedule_id UUID PRIMARY KEY,
+    tenant_id   TEXT NOT NULL,
+    name        TEXT NOT NULL DEFAULT '',
+    cron        TEXT NOT NULL,
+    template    JSONB NOT NULL,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    last_job_id UUID REFERENCES execution_jobs(job_id) ON DELETE SET NULL,
+    last_error  TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_schedules_next_run_at ON job_schedules (next_run_at);
+CREATE INDEX idx_job_schedules_tenant ON job_schedules (tenant_id, created_at);
+
+CREATE TRIGGER trg_job_schedules_updated_at
+    BEFORE UPDATE ON job_schedules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at();
+
+-- Jobs created by a schedule have their own origin.
+ALTER TABLE execution_jobs DROP CONSTRAINT execution_jobs_origin_check;
+ALTER TABLE execution_jobs ADD CONSTRAINT execution_jobs_origin_check
+    CHECK (origin IN ('user', 'resubmit', 'schedule'));
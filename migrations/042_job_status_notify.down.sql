@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback job status notifications
+-- =============================================================================
+
+DROP TRIGGER IF EXISTS trg_execution_jobs_status_notify ON execution_jobs;
+DROP FUNCTION IF EXISTS notify_job_status();
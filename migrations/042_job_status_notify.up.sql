@@ -0,0 +1,23 @@
+-- =============================================================================
+-- Project Sentinel — Job status notifications
+-- =============================================================================
+
+-- Every status change, whether the worker, a cancellation, or the API's
+-- background loops made it, is announced on the sentinel_job_status
+-- channel with the job's ID as the payload. The API listens so that its
+-- submission streams push updates instead of polling each job.
+-- Notifications go out when the transaction commits, so a listener that
+-- reads the job sees the change.
+CREATE OR REPLACE FUNCTION notify_job_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('sentinel_job_status', NEW.job_id::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_execution_jobs_status_notify
+    AFTER UPDATE OF status ON execution_jobs
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status)
+    EXECUTE FUNCTION notify_job_status();
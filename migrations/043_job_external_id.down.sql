@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback client-supplied external job IDs
+-- =============================================================================
+
+DROP INDEX IF EXISTS idx_jobs_tenant_external_id;
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,11 @@
+-- =============================================================================
+-- Project Sentinel — Client-supplied external job IDs
+-- =============================================================================
+
+-- Clients may tag a submission with their own ID and look the job up by it
+-- (GET /submissions/by-external-id/:id). An ID names at most one job per
+-- tenant; reruns do not copy it.
+ALTER TABLE execution_jobs ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX idx_jobs_tenant_external_id ON execution_jobs (tenant_id, external_id)
+    WHERE external_id IS NOT NULL;
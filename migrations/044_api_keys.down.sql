@@ -0,0 +1,7 @@
+-- =============================================================================
+-- Project Sentinel — Rollback API keys
+-- =============================================================================
+
+DROP INDEX IF EXISTS idx_jobs_api_key_id;
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS api_key_id;
+DROP TABLE IF EXISTS api_keys;
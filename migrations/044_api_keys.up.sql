@@ -0,0 +1,27 @@
+-- =============================================================================
+-- Project Sentinel — API keys
+-- =============================================================================
+
+-- An API key authenticates requests as its tenant. Only a SHA-256 hash of
+-- the key is stored; prefix is its first characters, shown to tell keys
+-- apart. Rotating a key replaces its hash, so the old key stops working
+-- while jobs stay associated with the key. Revoked keys are kept for the
+-- jobs that name them.
+CREATE TABLE api_keys (
+    key_id      UUID PRIMARY KEY,
+    tenant_id   TEXT NOT NULL,
+    name        TEXT NOT NULL DEFAULT '',
+    prefix      TEXT NOT NULL,
+    key_hash    BYTEA NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    rotated_at  TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_api_keys_tenant ON api_keys (tenant_id, created_at);
+
+-- The key a job was submitted with, if any.
+ALTER TABLE execution_jobs ADD COLUMN api_key_id UUID REFERENCES api_keys(key_id);
+
+CREATE INDEX idx_jobs_api_key_id ON execution_jobs (api_key_id)
+    WHERE api_key_id IS NOT NULL;
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback compile time
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS compile_time_ms;
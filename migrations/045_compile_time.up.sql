@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Compile time
+-- =============================================================================
+
+-- compile_time_ms is how long compiling the submission took, once per job
+-- however many test cases ran; NULL for interpreted languages, cached
+-- binaries and jobs that ran before it was recorded.
+ALTER TABLE execution_jobs
+    ADD COLUMN compile_time_ms INTEGER;
@@ -0,0 +1,8 @@
+-- =============================================================================
+-- Project Sentinel — Rollback user accounts
+-- =============================================================================
+
+DROP INDEX IF EXISTS idx_jobs_user_id;
+DROP INDEX IF EXISTS idx_jobs_tenant_job_id;
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS user_id;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,23 @@
+-- =============================================================================
+-- Project Sentinel — User accounts
+-- =============================================================================
+
+-- A user signs in with an email and password and submits as its tenant.
+-- Emails are stored lowercased and are unique across tenants, so logging
+-- in needs no tenant. Only a bcrypt hash of the password is stored.
+CREATE TABLE users (
+    user_id        UUID PRIMARY KEY,
+    tenant_id      TEXT NOT NULL,
+    email          TEXT NOT NULL UNIQUE,
+    password_hash  BYTEA NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The signed-in user who submitted a job, if any.
+ALTER TABLE execution_jobs ADD COLUMN user_id UUID REFERENCES users(user_id);
+
+-- GET /submissions pages through a tenant's jobs, or one user's, newest
+-- first.
+CREATE INDEX idx_jobs_tenant_job_id ON execution_jobs (tenant_id, job_id);
+CREATE INDEX idx_jobs_user_id ON execution_jobs (user_id, job_id)
+    WHERE user_id IS NOT NULL;
@@ -0,0 +1,16 @@
+-- =============================================================================
+-- Project Sentinel — Rollback users signed in through an OIDC provider
+-- =============================================================================
+
+UPDATE execution_jobs SET user_id = NULL
+WHERE user_id IN (SELECT user_id FROM users WHERE issuer IS NOT NULL);
+DELETE FROM users WHERE issuer IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_issuer_subject;
+DROP INDEX IF EXISTS idx_users_email;
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_one_credential,
+    DROP COLUMN IF EXISTS subject,
+    DROP COLUMN IF EXISTS issuer,
+    ALTER COLUMN password_hash SET NOT NULL,
+    ADD CONSTRAINT users_email_key UNIQUE (email);
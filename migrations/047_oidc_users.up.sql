@@ -0,0 +1,19 @@
+-- =============================================================================
+-- Project Sentinel — Users signed in through an OIDC provider
+-- =============================================================================
+
+-- A user may instead be known to an external OpenID Connect provider by
+-- its issuer and subject. Such users have no password and are created the
+-- first time they authenticate; their email is whatever the provider says
+-- and need not be unique, so only password users' emails are.
+ALTER TABLE users
+    ALTER COLUMN password_hash DROP NOT NULL,
+    ADD COLUMN issuer  TEXT,
+    ADD COLUMN subject TEXT,
+    ADD CONSTRAINT users_one_credential CHECK (
+        (issuer IS NULL) = (subject IS NULL) AND (issuer IS NULL) = (password_hash IS NOT NULL)
+    ),
+    DROP CONSTRAINT users_email_key;
+
+CREATE UNIQUE INDEX idx_users_email ON users (email) WHERE issuer IS NULL;
+CREATE UNIQUE INDEX idx_users_issuer_subject ON users (issuer, subject) WHERE issuer IS NOT NULL;
@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-problem output kill patterns
+-- =============================================================================
+
+-- The OUTPUT_VIOLATION enum value is left in place: PostgreSQL cannot drop
+-- enum values. Jobs holding it are marked as internal errors instead.
+UPDATE execution_jobs SET status = 'INTERNAL_ERROR' WHERE status = 'OUTPUT_VIOLATION';
+
+DROP TABLE IF EXISTS problem_kill_patterns;
@@ -0,0 +1,20 @@
+-- =============================================================================
+-- Project Sentinel — Per-problem output kill patterns
+-- =============================================================================
+
+-- A judged submission whose output matches one of its problem's kill
+-- patterns is stopped by the worker and given this verdict.
+ALTER TYPE execution_status ADD VALUE IF NOT EXISTS 'OUTPUT_VIOLATION';
+
+-- Kill patterns are versioned with the test data, in the order they are
+-- checked. repeats counts the identical matching lines in a row that end a
+-- run.
+CREATE TABLE problem_kill_patterns (
+    problem_id TEXT NOT NULL REFERENCES problems(problem_id) ON DELETE CASCADE,
+    version    INT NOT NULL,
+    ordinal    INT NOT NULL,
+    pattern    TEXT NOT NULL,
+    repeats    INT NOT NULL DEFAULT 1 CHECK (repeats >= 1),
+    message    TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (problem_id, version, ordinal)
+);
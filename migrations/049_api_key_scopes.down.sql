@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Rollback API key scopes
+-- =============================================================================
+
+DROP INDEX IF EXISTS idx_jobs_api_key_id;
+CREATE INDEX idx_jobs_api_key_id ON execution_jobs (api_key_id)
+    WHERE api_key_id IS NOT NULL;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
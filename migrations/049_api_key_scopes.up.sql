@@ -0,0 +1,15 @@
+-- =============================================================================
+-- Project Sentinel — API key scopes
+-- =============================================================================
+
+-- The scopes limit what a key may do: submit, read:any and admin. New keys
+-- default to submit, which reads only the jobs submitted with the key.
+-- Keys issued before scopes existed could read any job, so they keep
+-- read:any.
+ALTER TABLE api_keys ADD COLUMN scopes TEXT[] NOT NULL DEFAULT '{submit}';
+UPDATE api_keys SET scopes = '{submit,read:any}';
+
+-- GET /submissions pages through the jobs of one key, newest first.
+DROP INDEX IF EXISTS idx_jobs_api_key_id;
+CREATE INDEX idx_jobs_api_key_id ON execution_jobs (api_key_id, job_id)
+    WHERE api_key_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- =============================================================================
+-- Project Sentinel — Rollback security event export
+-- =============================================================================
+
+DROP TABLE IF EXISTS security_events;
@@ -0,0 +1,19 @@
+-- =============================================================================
+-- Project Sentinel — Security event export
+-- =============================================================================
+
+-- One row per security-relevant thing a run did: a seccomp kill, packets
+-- dropped outside the network allowlist, a kill pattern match or a
+-- quarantined message. Workers write them with the job's result; the API
+-- pages through them in event order for SIEM pipelines. Events outlive
+-- purged jobs, so they carry the tenant rather than reference the job.
+CREATE TABLE security_events (
+    event_id   BIGSERIAL PRIMARY KEY,
+    job_id     UUID NOT NULL,
+    tenant_id  TEXT NOT NULL,
+    kind       TEXT NOT NULL,
+    detail     TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_security_events_kind ON security_events (kind, event_id);
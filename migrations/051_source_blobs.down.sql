@@ -0,0 +1,16 @@
+-- =============================================================================
+-- Project Sentinel — Rollback content-addressed submission sources
+-- =============================================================================
+
+-- Sources move back into their jobs before the blobs go.
+UPDATE execution_jobs j
+SET source_code = b.content
+FROM source_blobs b
+WHERE j.source_code IS NULL AND b.hash = j.source_hash;
+
+DROP FUNCTION IF EXISTS backfill_source_blobs(INT);
+ALTER TABLE execution_jobs DROP CONSTRAINT IF EXISTS execution_jobs_source_present;
+DROP INDEX IF EXISTS idx_jobs_source_hash;
+ALTER TABLE execution_jobs DROP COLUMN IF EXISTS source_hash;
+ALTER TABLE execution_jobs ALTER COLUMN source_code SET NOT NULL;
+DROP TABLE IF EXISTS source_blobs;
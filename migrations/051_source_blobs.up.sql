@@ -0,0 +1,54 @@
+-- =============================================================================
+-- Project Sentinel — Content-addressed submission sources
+-- =============================================================================
+
+-- Sources are stored once per distinct content, keyed by the SHA-256 of
+-- their UTF-8 bytes, and jobs reference them by hash. Contests see the same
+-- source resubmitted many times; each copy now costs a 32-byte hash.
+CREATE TABLE source_blobs (
+    hash       BYTEA PRIMARY KEY CHECK (octet_length(hash) = 32),
+    content    TEXT NOT NULL,
+    size_bytes INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- New jobs set source_hash and leave source_code NULL. Jobs created before
+-- keep their source_code until backfill_source_blobs moves it.
+ALTER TABLE execution_jobs ADD COLUMN source_hash BYTEA REFERENCES source_blobs(hash);
+ALTER TABLE execution_jobs ALTER COLUMN source_code DROP NOT NULL;
+ALTER TABLE execution_jobs ADD CONSTRAINT execution_jobs_source_present
+    CHECK (source_code IS NOT NULL OR source_hash IS NOT NULL);
+
+-- Purging a job drops its blob unless another job still references it.
+CREATE INDEX idx_jobs_source_hash ON execution_jobs (source_hash)
+    WHERE source_hash IS NOT NULL;
+
+-- backfill_source_blobs moves the sources of up to batch_size older jobs
+-- into source_blobs and returns how many it moved; 0 means it is done.
+-- Each call is one short transaction, so `sentinelctl backfill-sources`
+-- runs it repeatedly alongside live traffic. Rows it moves free their
+-- space to later writes once vacuumed.
+CREATE OR REPLACE FUNCTION backfill_source_blobs(batch_size INT)
+RETURNS INT AS $$
+DECLARE
+    moved INT;
+BEGIN
+    WITH batch AS (
+        SELECT job_id, source_code, sha256(convert_to(source_code, 'UTF8')) AS hash
+        FROM execution_jobs
+        WHERE source_code IS NOT NULL
+        LIMIT batch_size
+        FOR UPDATE SKIP LOCKED
+    ), blobs AS (
+        INSERT INTO source_blobs (hash, content, size_bytes)
+        SELECT DISTINCT ON (hash) hash, source_code, octet_length(source_code) FROM batch
+        ON CONFLICT (hash) DO NOTHING
+    )
+    UPDATE execution_jobs j
+    SET source_hash = b.hash, source_code = NULL
+    FROM batch b
+    WHERE j.job_id = b.job_id;
+    GET DIAGNOSTICS moved = ROW_COUNT;
+    RETURN moved;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,13 @@
+-- =============================================================================
+-- Project Sentinel — Rollback reasons on timeline events
+-- =============================================================================
+
+CREATE OR REPLACE FUNCTION record_job_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO job_events (job_id, status) VALUES (NEW.job_id, NEW.status::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE job_events DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,18 @@
+-- =============================================================================
+-- Project Sentinel — Reasons on timeline events
+-- =============================================================================
+
+-- Why a job changed status, where the status alone does not say: a worker
+-- preempting a job for higher-priority work puts it back to QUEUED with
+-- reason 'preempted'. Writers set sentinel.event_reason for their
+-- transaction; the trigger copies it, so other writes record no reason.
+ALTER TABLE job_events ADD COLUMN reason TEXT;
+
+CREATE OR REPLACE FUNCTION record_job_event()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO job_events (job_id, status, reason)
+    VALUES (NEW.job_id, NEW.status::TEXT, NULLIF(current_setting('sentinel.event_reason', true), ''));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,6 @@
+-- =============================================================================
+-- Project Sentinel — Rollback per-phase timings
+-- =============================================================================
+
+ALTER TABLE execution_jobs
+    DROP COLUMN IF EXISTS timings;
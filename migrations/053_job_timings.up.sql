@@ -0,0 +1,9 @@
+-- =============================================================================
+-- Project Sentinel — Per-phase timings
+-- =============================================================================
+
+-- timings breaks down where a finished job's time went: queue_wait_ms,
+-- workdir_setup_ms, compile_ms, run_ms and result_store_ms, written by the
+-- worker with the result; NULL for jobs that ran before it was recorded.
+ALTER TABLE execution_jobs
+    ADD COLUMN timings JSONB;
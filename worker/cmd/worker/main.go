@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -9,7 +10,6 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	goredis "github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -18,13 +18,24 @@ import (
 	amqpdelivery "github.com/Harsh-BH/Sentinel/worker/internal/delivery/amqp"
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
 	"github.com/Harsh-BH/Sentinel/worker/internal/executor"
+	"github.com/Harsh-BH/Sentinel/worker/internal/judge"
+	"github.com/Harsh-BH/Sentinel/worker/internal/judge/wasmcheck"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
 	"github.com/Harsh-BH/Sentinel/worker/internal/pool"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository/disk"
 	"github.com/Harsh-BH/Sentinel/worker/internal/repository/postgres"
 	redisrepo "github.com/Harsh-BH/Sentinel/worker/internal/repository/redis"
 	"github.com/Harsh-BH/Sentinel/worker/internal/usecase"
+	"github.com/Harsh-BH/Sentinel/worker/internal/webhook"
 )
 
 func main() {
+	validate := flag.Bool("validate-config", false, "check the configuration and the files it names, then exit")
+	flag.Parse()
+	if *validate {
+		os.Exit(validateConfig())
+	}
+
 	// Initialize logger
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
@@ -41,14 +52,23 @@ func main() {
 	defer cancel()
 
 	// Connect to PostgreSQL
-	dbPool, err := pgxpool.New(ctx, cfg.Database.URL)
+	dbRetry := postgres.RetryPolicy{
+		Attempts:   cfg.Database.RetryAttempts,
+		Backoff:    cfg.Database.RetryBackoff,
+		MaxBackoff: cfg.Database.RetryMaxBackoff,
+	}
+	dbPool, err := postgres.NewPool(ctx, cfg.Database.URL, postgres.PoolConfig{
+		MaxConns:          int32(cfg.Database.MaxConns),
+		MinConns:          int32(cfg.Database.MinConns),
+		ConnectTimeout:    cfg.Database.ConnectTimeout,
+		HealthCheckPeriod: cfg.Database.HealthCheckPeriod,
+		MaxConnLifetime:   cfg.Database.MaxConnLifetime,
+		Retry:             dbRetry,
+	})
 	if err != nil {
 		logger.Fatal("Failed to connect to PostgreSQL", zap.Error(err))
 	}
 	defer dbPool.Close()
-	if err := dbPool.Ping(ctx); err != nil {
-		logger.Fatal("Failed to ping PostgreSQL", zap.Error(err))
-	}
 	logger.Info("Connected to PostgreSQL")
 
 	// Connect to Redis
@@ -64,27 +84,174 @@ func main() {
 	logger.Info("Connected to Redis")
 
 	// Initialize repositories
-	jobRepo := postgres.NewPostgresJobRepository(dbPool)
+	jobRepo := postgres.NewPostgresJobRepository(dbPool, dbRetry)
 	idempotencyStore := redisrepo.NewRedisIdempotencyStore(redisClient)
 
+	// Load the language registry
+	languages, err := language.Load(cfg.Sandbox.LanguagesFile)
+	if err != nil {
+		logger.Fatal("Failed to load language registry", zap.Error(err), zap.String("path", cfg.Sandbox.LanguagesFile))
+	}
+	if cfg.Sandbox.OverridesFile != "" {
+		if err := languages.LoadOverrides(cfg.Sandbox.OverridesFile); err != nil {
+			logger.Fatal("Failed to apply sandbox overrides", zap.Error(err), zap.String("path", cfg.Sandbox.OverridesFile))
+		}
+	}
+	logger.Info("Loaded language registry", zap.Int("languages", len(languages.All())))
+
 	// Initialize sandbox executor
-	sandboxExec := executor.NewSandboxExecutor(cfg.Sandbox.NsjailPath, cfg.Sandbox.ConfigDir, logger)
+	sandboxExec := executor.NewSandboxExecutor(cfg.Sandbox.NsjailPath, cfg.Sandbox.ConfigDir, languages, logger)
+	binaryCache, err := disk.NewDiskBinaryCache(cfg.Sandbox.BinaryCacheDir, int64(cfg.Sandbox.BinaryCacheMaxMB)<<20)
+	if err != nil {
+		logger.Fatal("Failed to initialize compiled binary cache", zap.Error(err))
+	}
+	sandboxExec.SetBinaryCache(binaryCache)
+	sandboxExec.SetRuntimes(postgres.NewPostgresRuntimeRepository(dbPool))
+	if cfg.Sandbox.PolicyDir != "" {
+		if err := sandboxExec.SetPolicyDir(cfg.Sandbox.PolicyDir); err != nil {
+			logger.Fatal("Missing seccomp policies", zap.Error(err), zap.String("path", cfg.Sandbox.PolicyDir))
+		}
+	}
+	if cfg.Sandbox.TemplateDir != "" {
+		if err := sandboxExec.SetConfigTemplates(cfg.Sandbox.TemplateDir); err != nil {
+			logger.Fatal("Invalid nsjail config templates", zap.Error(err), zap.String("path", cfg.Sandbox.TemplateDir))
+		}
+	}
+	sandboxExec.SetRandomIdentity(cfg.Sandbox.RandomIdentity)
+	ioLimits := executor.IOLimitConfig{
+		ReadMBps:  cfg.Sandbox.IOReadMBps,
+		WriteMBps: cfg.Sandbox.IOWriteMBps,
+		ReadIOPS:  cfg.Sandbox.IOReadIOPS,
+		WriteIOPS: cfg.Sandbox.IOWriteIOPS,
+	}
+	limitIO := ioLimits != executor.IOLimitConfig{}
+	if cfg.Sandbox.CgroupRoot != "" {
+		cgroups, err := executor.NewJobCgroups(cfg.Sandbox.CgroupRoot)
+		if err != nil {
+			// Executions still run, but report no memory usage.
+			logger.Warn("Per-job cgroups unavailable, memory usage will not be reported", zap.Error(err))
+		} else {
+			sandboxExec.SetJobCgroups(cgroups)
+			if cfg.Sandbox.CPUSet != "" {
+				setCPUPinning(sandboxExec, cgroups, cfg, logger)
+			}
+			if limitIO {
+				setIOLimits(sandboxExec, cgroups, ioLimits, logger)
+			}
+		}
+	} else {
+		if cfg.Sandbox.CPUSet != "" {
+			logger.Warn("CPU pinning needs WORKER_CGROUP_ROOT, runs are not pinned")
+		}
+		if limitIO {
+			logger.Warn("I/O limits need WORKER_CGROUP_ROOT, sandbox I/O is not limited")
+		}
+	}
+	if cfg.Sandbox.WorkDirQuotaMB > 0 {
+		quota, err := executor.NewWorkDirQuota(cfg.Sandbox.WorkDirQuotaMB)
+		if err != nil {
+			// Executions still run, but can write until the disk is full.
+			logger.Warn("Work dir quota unavailable, sandbox writes are not limited", zap.Error(err))
+		} else {
+			sandboxExec.SetWorkDirQuota(quota)
+		}
+	}
+	if cfg.Sandbox.NetworkAllowlist != "" {
+		allowlist, err := executor.ParseNetworkAllowlist(cfg.Sandbox.NetworkAllowlist)
+		if err != nil {
+			logger.Fatal("Invalid network allowlist", zap.Error(err))
+		}
+		networks, err := executor.NewJobNetworks(executor.NetworkConfig{
+			Subnet:    cfg.Sandbox.NetworkSubnet,
+			Allowlist: allowlist,
+		})
+		if err != nil {
+			// Jobs asking for the allowlist fail; the rest run as usual.
+			logger.Warn("Network allowlist unavailable, allowlist jobs will fail", zap.Error(err))
+		} else {
+			sandboxExec.SetJobNetworks(networks)
+		}
+	}
+	var workDirs *pool.WorkDirPool
+	if cfg.Worker.WorkDirPoolSize > 0 {
+		workDirs = pool.NewWorkDirPool("", cfg.Worker.WorkDirPoolSize, logger)
+		sandboxExec.SetWorkDirSource(workDirs)
+	}
+	jobExec, err := newExecutorFactory(cfg, sandboxExec, languages, logger)
+	if err != nil {
+		logger.Fatal("Invalid executor selection", zap.Error(err))
+	}
 
 	// Initialize use case
-	executeUC := usecase.NewExecuteJobUsecase(jobRepo, idempotencyStore, sandboxExec, logger)
+	executeUC := usecase.NewExecuteJobUsecase(jobRepo, idempotencyStore, jobExec, languages, logger)
+	executeUC.SetQuotaTracker(redisrepo.NewRedisQuotaTracker(redisClient))
+	executeUC.SetInteractive(redisrepo.NewRedisInteractiveIO(redisClient))
+	executeUC.SetCancelWatcher(redisrepo.NewRedisCancelWatcher(ctx, redisClient))
+	executeUC.SetMaxPids(cfg.Sandbox.MaxPids)
+	tiers, err := usecase.ParseTenantTiers(cfg.Worker.TenantTiers)
+	if err != nil {
+		logger.Fatal("Invalid WORKER_TENANT_TIERS", zap.Error(err))
+	}
+	executeUC.SetTenantTiers(tiers)
+	judgeSvc := judge.NewJudge(jobExec, judge.NewComparator(judge.DefaultTolerance), logger)
+	judgeSvc.SetConcurrency(cfg.Worker.JudgeConcurrency, languages.ConcurrencyLimits())
+	retention, err := judge.ParseOutputRetention(cfg.Worker.OutputRetention)
+	if err != nil {
+		logger.Fatal("Invalid WORKER_CASE_OUTPUT_RETENTION", zap.Error(err))
+	}
+	judgeSvc.SetOutputRetention(retention)
+	inputCache, err := disk.NewDiskInputCache(cfg.Sandbox.InputCacheDir, int64(cfg.Sandbox.InputCacheMaxMB)<<20)
+	if err != nil {
+		logger.Fatal("Failed to initialize generated input cache", zap.Error(err))
+	}
+	judgeSvc.SetInputCache(inputCache)
+	comparators := wasmcheck.New(ctx, wasmcheck.Config{
+		MemoryLimitMB: cfg.Worker.ComparatorMemoryMB,
+		Timeout:       cfg.Worker.ComparatorTimeout,
+	})
+	defer comparators.Close(context.Background())
+	judgeSvc.SetComparatorRunner(comparators)
+	executeUC.SetJudge(postgres.NewPostgresProblemRepository(dbPool), judgeSvc)
 
 	// Create buffered job channel (carries JobMessage with ACK callbacks).
 	jobsChan := make(chan *domain.JobMessage, cfg.Worker.PoolSize*2)
 
 	// Initialize AMQP consumer
-	consumer, err := amqpdelivery.NewConsumer(cfg.RabbitMQ.URL, jobsChan, logger)
+	consumer, err := amqpdelivery.NewConsumer(cfg.RabbitMQ.URLs(), cfg.RabbitMQ.MaxPriority, jobsChan, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize AMQP consumer", zap.Error(err))
 	}
+	consumer.SetPauses(redisrepo.NewRedisQueuePauses(redisClient))
+	if cfg.Worker.PreemptPriority > 0 {
+		// One message beyond the pool's, for a priority job to wait in
+		// while it preempts.
+		if err := consumer.SetPrefetch(cfg.Worker.PoolSize + 1); err != nil {
+			logger.Fatal("Failed to raise AMQP prefetch for preemption", zap.Error(err))
+		}
+	}
 	logger.Info("Connected to RabbitMQ")
 
 	// Start worker pool
 	workerPool := pool.NewWorkerPool(cfg.Worker.PoolSize, jobsChan, executeUC, logger)
+	if workDirs != nil {
+		workerPool.SetWorkDirPool(workDirs)
+	}
+	dlqNotifier := webhook.NewDLQNotifier(postgres.NewPostgresWebhookRepository(dbPool), cfg.Worker.DLQWebhookTimeout, logger)
+	workerPool.SetDeadLetterNotifier(dlqNotifier)
+	workerPool.SetMaxDeliveries(cfg.Worker.MaxDeliveries)
+	workerPool.SetPreemption(cfg.Worker.PreemptPriority)
+	pressureLimits := pool.PressureLimits{
+		MemoryPercent: cfg.Worker.PressureMemoryPercent,
+		DiskPercent:   cfg.Worker.PressureDiskPercent,
+		LoadPerCPU:    cfg.Worker.PressureLoadPerCPU,
+	}
+	if pressureLimits != (pool.PressureLimits{}) {
+		guard := pool.NewPressureGuard(pressureLimits)
+		if _, err := guard.Sample(); err != nil {
+			logger.Warn("Host pressure cannot be sampled; jobs will start regardless", zap.Error(err))
+		}
+		workerPool.SetPressureGuard(guard, cfg.Worker.PressureWait)
+	}
 	workerPool.Start(ctx)
 
 	// Start AMQP consumer in a goroutine
@@ -145,8 +312,9 @@ func main() {
 	// 3. Wait for workers to drain in-flight jobs.
 	workerPool.Stop()
 
-	// 4. Close the job channel.
+	// 4. Close the job channel and let dead-letter notifications finish.
 	close(jobsChan)
+	dlqNotifier.Wait()
 
 	// 5. Shut down the metrics server.
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -157,3 +325,124 @@ func main() {
 
 	logger.Info("Worker stopped")
 }
+
+// newExecutorFactory builds the executors the selection rules reference,
+// with sandboxExec as the nsjail backend.
+func newExecutorFactory(cfg *config.Config, sandboxExec *executor.SandboxExecutor, languages *language.Registry, logger *zap.Logger) (*executor.ExecutorFactory, error) {
+	var rules executor.SelectionRules
+	var err error
+	if rules.Default, err = executor.ParseBackend(cfg.Executor.Default); err != nil {
+		return nil, fmt.Errorf("WORKER_EXECUTOR_DEFAULT: %w", err)
+	}
+	if rules.Tiers, err = executor.ParseAssignments(cfg.Executor.Tiers); err != nil {
+		return nil, fmt.Errorf("WORKER_EXECUTOR_TIERS: %w", err)
+	}
+	if rules.Tenants, err = executor.ParseAssignments(cfg.Executor.Tenants); err != nil {
+		return nil, fmt.Errorf("WORKER_EXECUTOR_TENANTS: %w", err)
+	}
+	if rules.Languages, err = executor.ParseAssignments(cfg.Executor.Languages); err != nil {
+		return nil, fmt.Errorf("WORKER_EXECUTOR_LANGUAGES: %w", err)
+	}
+	if cfg.Executor.FirecrackerTenants != "" {
+		logger.Warn("WORKER_FIRECRACKER_TENANTS is deprecated, use WORKER_EXECUTOR_TENANTS and WORKER_EXECUTOR_DEFAULT")
+		if err := rules.AddFirecrackerTenants(cfg.Executor.FirecrackerTenants); err != nil {
+			return nil, fmt.Errorf("WORKER_FIRECRACKER_TENANTS: %w", err)
+		}
+	}
+
+	factory := executor.NewExecutorFactory(rules)
+	for _, backend := range rules.Backends() {
+		switch backend {
+		case executor.BackendNsjail:
+			factory.Register(backend, sandboxExec)
+		case executor.BackendFirecracker:
+			factory.Register(backend, executor.NewFirecrackerExecutor(executor.FirecrackerConfig{
+				FirecrackerPath: cfg.Firecracker.Path,
+				MkfsPath:        cfg.Firecracker.MkfsPath,
+				KernelPath:      cfg.Firecracker.KernelPath,
+				RootfsDir:       cfg.Firecracker.RootfsDir,
+				VCPUs:           cfg.Firecracker.VCPUs,
+				BootTimeout:     cfg.Firecracker.BootTimeout,
+			}, languages, logger))
+		case executor.BackendDocker, executor.BackendGVisor:
+			containerCfg := executor.ContainerConfig{
+				DockerPath: cfg.Executor.DockerPath,
+				Image:      cfg.Executor.DockerImage,
+			}
+			if backend == executor.BackendGVisor {
+				containerCfg.Runtime = cfg.Executor.GVisorRuntime
+			}
+			factory.Register(backend, executor.NewContainerExecutor(containerCfg, languages, logger))
+		}
+	}
+	if err := factory.Validate(); err != nil {
+		return nil, err
+	}
+	logger.Info("Executor backends enabled", zap.Any("backends", rules.Backends()))
+	return factory, nil
+}
+
+// setCPUPinning pins sandbox runs to the cores of WORKER_CPU_SET. Runs
+// stay unpinned if the host does not delegate the cpuset controller.
+func setCPUPinning(sandboxExec *executor.SandboxExecutor, cgroups *executor.JobCgroups, cfg *config.Config, logger *zap.Logger) {
+	pinning, err := executor.NewCPUPinning(cfg.Sandbox.CPUSet)
+	if err != nil {
+		logger.Fatal("Invalid CPU set", zap.Error(err), zap.String("cpus", cfg.Sandbox.CPUSet))
+	}
+	if err := cgroups.EnableCPUSet(); err != nil {
+		logger.Warn("CPU pinning unavailable, runs are not pinned", zap.Error(err))
+		return
+	}
+	if pinning.Size() < cfg.Worker.PoolSize {
+		// Runs past the number of cores wait for one to free up.
+		logger.Warn("Fewer pinned CPUs than pool workers",
+			zap.Int("cpus", pinning.Size()),
+			zap.Int("pool_size", cfg.Worker.PoolSize),
+		)
+	}
+	sandboxExec.SetCPUPinning(pinning)
+}
+
+// setIOLimits caps sandbox runs' throughput on the disk holding their work
+// dirs. Runs stay unlimited if the host does not delegate the io controller
+// or the work dirs are not on a disk.
+func setIOLimits(sandboxExec *executor.SandboxExecutor, cgroups *executor.JobCgroups, cfg executor.IOLimitConfig, logger *zap.Logger) {
+	limits, err := executor.NewIOLimits(os.TempDir(), cfg)
+	if err != nil {
+		logger.Warn("I/O limits unavailable, sandbox I/O is not limited", zap.Error(err))
+		return
+	}
+	if err := cgroups.EnableIO(); err != nil {
+		logger.Warn("I/O limits unavailable, sandbox I/O is not limited", zap.Error(err))
+		return
+	}
+	logger.Info("Limiting sandbox I/O", zap.String("device", limits.Device()))
+	sandboxExec.SetIOLimits(limits)
+}
+
+// validateConfig loads the configuration and the files it names as the
+// worker would, for --validate-config, and reports what is wrong with
+// them. It returns the exit status.
+func validateConfig() int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	languages, err := language.Load(cfg.Sandbox.LanguagesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %s: %v\n", cfg.Sandbox.LanguagesFile, err)
+		return 1
+	}
+	if cfg.Sandbox.OverridesFile != "" {
+		if err := languages.LoadOverrides(cfg.Sandbox.OverridesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "config: %s: %v\n", cfg.Sandbox.OverridesFile, err)
+			return 1
+		}
+	}
+	fmt.Println("Configuration is valid")
+	for _, path := range config.Files() {
+		fmt.Println("  read", path)
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultsCoverSettings(t *testing.T) {
+	defaults := viper.New()
+	defaults.SetConfigType("yaml")
+	if err := defaults.ReadConfig(strings.NewReader(string(defaultsYAML))); err != nil {
+		t.Fatal(err)
+	}
+	schema := settings()
+	for name := range schema {
+		if !defaults.IsSet(name) {
+			t.Errorf("%s has no default", name)
+		}
+	}
+	if errs := checkSettings("defaults.yaml", schema, defaults.AllSettings(), true); len(errs) > 0 {
+		t.Errorf("defaults do not match the settings: %v", errs)
+	}
+}
+
+func TestLoad_Layers(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "base.yaml", `
+shared:
+  DATABASE_MAX_CONNS: 10
+  RABBITMQ_MAX_PRIORITY: 9
+api:
+  API_RATE_LIMIT: 200
+worker:
+  WORKER_POOL_SIZE: 8
+  WORKER_PRESSURE_WAIT: 30s
+`)
+	writeConfig(t, dir, "production.yaml", `
+shared:
+  DATABASE_MAX_CONNS: 40
+worker:
+  WORKER_POOL_SIZE: 16
+  WORKER_PRESSURE_LOAD_PER_CPU: 1.5
+`)
+	t.Setenv(ConfigDirEnv, dir)
+	t.Setenv(EnvironmentEnv, "production")
+	t.Setenv("WORKER_MAX_PIDS", "512")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Worker.MetricsPort != 9090 {
+		t.Errorf("MetricsPort = %d, want the default 9090", cfg.Worker.MetricsPort)
+	}
+	if cfg.Worker.PressureWait != 30*time.Second || cfg.RabbitMQ.MaxPriority != 9 {
+		t.Errorf("base.yaml not applied: pressure wait %v, max priority %d", cfg.Worker.PressureWait, cfg.RabbitMQ.MaxPriority)
+	}
+	if cfg.Worker.PoolSize != 16 || cfg.Database.MaxConns != 40 || cfg.Worker.PressureLoadPerCPU != 1.5 {
+		t.Errorf("overlay not applied: pool size %d, max conns %d, load per CPU %v",
+			cfg.Worker.PoolSize, cfg.Database.MaxConns, cfg.Worker.PressureLoadPerCPU)
+	}
+	if cfg.Sandbox.MaxPids != 512 {
+		t.Errorf("MaxPids = %d, want 512 from the environment", cfg.Sandbox.MaxPids)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "base.yaml", `
+shared:
+  WORKER_POOL_SIZE: 4
+worker:
+  WORKER_POOL_SIZ: 8
+  WORKER_PRESSURE_WAIT: 10
+`)
+	t.Setenv(ConfigDirEnv, dir)
+	t.Setenv(EnvironmentEnv, "")
+	t.Setenv("WORKER_METRICS_PORT", "metrics")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load succeeded, want errors")
+	}
+	for _, want := range []string{
+		"unknown setting WORKER_POOL_SIZ",
+		"WORKER_PRESSURE_WAIT: want a duration",
+		"environment: WORKER_METRICS_PORT: want an integer",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package amqp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -12,10 +13,17 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
 )
 
 const (
 	queueName = "execution_tasks"
+	// holdingQueuePrefix starts the name of the queue each paused
+	// language's jobs are held in; it must match the API's.
+	holdingQueuePrefix = queueName + ".paused."
+	// priorDeliveriesHeader counts the deliveries a preempted job's message
+	// had before it was republished; see deliveryCount.
+	priorDeliveriesHeader = "x-sentinel-prior-deliveries"
 
 	// Reconnection parameters
 	maxReconnectDelay  = 30 * time.Second
@@ -24,11 +32,23 @@ const (
 
 // Consumer listens to RabbitMQ and dispatches JobMessage (with ACK callbacks) to a channel.
 type Consumer struct {
-	url     string
-	conn    *amqplib.Connection
-	channel *amqplib.Channel
-	logger  *zap.Logger
-	jobs    chan<- *domain.JobMessage
+	endpoints *endpoints
+	// endpoint is the index in endpoints of the node conn is connected to.
+	endpoint int
+	// maxPriority above zero declares the queue with job priorities.
+	maxPriority int
+	conn        *amqplib.Connection
+	channel     *amqplib.Channel
+	// holdChannel, in confirm mode, moves paused languages' jobs to their
+	// holding queues and preempted jobs to the back of the job queue.
+	holdChannel *amqplib.Channel
+	pauses      repository.QueuePauses
+	logger      *zap.Logger
+	jobs        chan<- *domain.JobMessage
+
+	// prefetch is how many unacknowledged messages the broker delivers;
+	// 1 unless SetPrefetch raises it.
+	prefetch int
 
 	mu      sync.Mutex
 	closed  bool
@@ -38,25 +58,70 @@ type Consumer struct {
 // NewConsumer creates a new RabbitMQ consumer.
 // Unlike Phase 0, the consumer does NOT auto-ACK after dispatch.
 // Instead, it wraps each delivery in a JobMessage with Ack/Nack callbacks
-// that the worker pool calls after execution completes.
-func NewConsumer(url string, jobs chan<- *domain.JobMessage, logger *zap.Logger) (*Consumer, error) {
+// that the worker pool calls after execution completes. maxPriority must
+// match the API's RABBITMQ_MAX_PRIORITY; with priorities the broker delivers
+// the highest-priority ready job first. urls are the nodes of one cluster:
+// the consumer connects to one at a time and rotates to the next when it
+// cannot reach it or loses its connection.
+func NewConsumer(urls []string, maxPriority int, jobs chan<- *domain.JobMessage, logger *zap.Logger) (*Consumer, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("amqp: no broker URL")
+	}
 	c := &Consumer{
-		url:     url,
-		logger:  logger,
-		jobs:    jobs,
-		closeCh: make(chan struct{}),
+		endpoints:   newEndpoints(urls),
+		maxPriority: maxPriority,
+		prefetch:    1,
+		logger:      logger,
+		jobs:        jobs,
+		closeCh:     make(chan struct{}),
+	}
+
+	// Each endpoint is tried once before giving up.
+	var err error
+	for range urls {
+		if err = c.connect(); err == nil {
+			return c, nil
+		}
 	}
+	return nil, err
+}
 
-	if err := c.connect(); err != nil {
-		return nil, err
+// SetPrefetch lets the broker deliver up to n unacknowledged messages, on
+// the current connection and on reconnects.
+func (c *Consumer) SetPrefetch(n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prefetch = n
+	if err := c.channel.Qos(n, 0, false); err != nil {
+		return fmt.Errorf("amqp qos: %w", err)
 	}
+	return nil
+}
 
-	return c, nil
+// SetPauses makes the consumer hold back the jobs of languages paused
+// through the API: they are moved to the language's holding queue, where
+// they wait for the API to release them, instead of being dispatched.
+func (c *Consumer) SetPauses(pauses repository.QueuePauses) {
+	c.pauses = pauses
 }
 
-// connect establishes the AMQP connection and channel with prefetch=1.
+// connect establishes the AMQP connection and channel with prefetch=1 on
+// the endpoint after the last one tried.
 func (c *Consumer) connect() error {
-	conn, err := amqplib.Dial(c.url)
+	i := c.endpoints.pick(time.Now())
+	if err := c.connectTo(c.endpoints.url(i)); err != nil {
+		c.endpoints.failed(i, time.Now())
+		return fmt.Errorf("%w (endpoint=%s)", err, c.endpoints.label(i))
+	}
+	c.endpoints.succeeded(i)
+	c.mu.Lock()
+	c.endpoint = i
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Consumer) connectTo(url string) error {
+	conn, err := amqplib.Dial(url)
 	if err != nil {
 		return fmt.Errorf("amqp dial: %w", err)
 	}
@@ -67,25 +132,25 @@ func (c *Consumer) connect() error {
 		return fmt.Errorf("amqp channel: %w", err)
 	}
 
-	// Set prefetch to 1: only deliver one unacknowledged message per consumer.
-	if err := ch.Qos(1, 0, false); err != nil {
+	// Prefetch 1 unless SetPrefetch raised it: only deliver one
+	// unacknowledged message per consumer.
+	c.mu.Lock()
+	prefetch := c.prefetch
+	c.mu.Unlock()
+	if err := ch.Qos(prefetch, 0, false); err != nil {
 		ch.Close()
 		conn.Close()
 		return fmt.Errorf("amqp qos: %w", err)
 	}
 
-	// Declare the queue (idempotent) — ensures it exists with quorum type.
+	// Declare the queue (idempotent) — ensures it exists with the expected type.
 	_, err = ch.QueueDeclare(
 		queueName,
 		true,  // durable
 		false, // auto-delete
 		false, // exclusive
 		false, // no-wait
-		amqplib.Table{
-			"x-queue-type":              "quorum",
-			"x-dead-letter-exchange":    "dlx.execution_tasks",
-			"x-dead-letter-routing-key": "execution_tasks.dlq",
-		},
+		queueArgs(c.maxPriority),
 	)
 	if err != nil {
 		ch.Close()
@@ -93,14 +158,41 @@ func (c *Consumer) connect() error {
 		return fmt.Errorf("amqp queue declare: %w", err)
 	}
 
+	holdCh, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("amqp hold channel: %w", err)
+	}
+	if err := holdCh.Confirm(false); err != nil {
+		conn.Close()
+		return fmt.Errorf("amqp hold channel confirms: %w", err)
+	}
+
 	c.mu.Lock()
 	c.conn = conn
 	c.channel = ch
+	c.holdChannel = holdCh
 	c.mu.Unlock()
 
 	return nil
 }
 
+// queueArgs are the execution queue's arguments. Quorum queues do not
+// support x-max-priority, so with priorities the queue is a classic queue;
+// prefetch=1 then lets the broker hand each worker the highest-priority job.
+func queueArgs(maxPriority int) amqplib.Table {
+	args := amqplib.Table{
+		"x-queue-type":              "quorum",
+		"x-dead-letter-exchange":    "dlx.execution_tasks",
+		"x-dead-letter-routing-key": "execution_tasks.dlq",
+	}
+	if maxPriority > 0 {
+		args["x-queue-type"] = "classic"
+		args["x-max-priority"] = int32(maxPriority)
+	}
+	return args
+}
+
 // Start begins consuming messages. It blocks until the context is cancelled.
 // On connection loss it automatically reconnects with exponential backoff.
 func (c *Consumer) Start(ctx context.Context) error {
@@ -120,7 +212,15 @@ func (c *Consumer) Start(ctx context.Context) error {
 		default:
 		}
 
-		c.logger.Warn("AMQP consumer lost connection, reconnecting...", zap.Error(err))
+		// The next connection goes to another node, if there is one.
+		c.mu.Lock()
+		lost := c.endpoint
+		c.mu.Unlock()
+		c.endpoints.failed(lost, time.Now())
+		c.logger.Warn("AMQP consumer lost connection, reconnecting...",
+			zap.Error(err),
+			zap.String("endpoint", c.endpoints.label(lost)),
+		)
 
 		// Exponential backoff reconnection loop.
 		for attempt := 0; ; attempt++ {
@@ -147,7 +247,10 @@ func (c *Consumer) Start(ctx context.Context) error {
 				continue
 			}
 
-			c.logger.Info("Reconnected to RabbitMQ")
+			c.mu.Lock()
+			endpoint := c.endpoints.label(c.endpoint)
+			c.mu.Unlock()
+			c.logger.Info("Reconnected to RabbitMQ", zap.String("endpoint", endpoint))
 			break
 		}
 	}
@@ -157,6 +260,7 @@ func (c *Consumer) Start(ctx context.Context) error {
 func (c *Consumer) consume(ctx context.Context) error {
 	c.mu.Lock()
 	ch := c.channel
+	holdCh := c.holdChannel
 	c.mu.Unlock()
 
 	if ch == nil {
@@ -197,12 +301,27 @@ func (c *Consumer) consume(ctx context.Context) error {
 				delivery.Nack(false, false) // reject → DLQ
 				continue
 			}
+			job.QueuedAt = delivery.Timestamp
 
 			c.logger.Debug("Received job from queue",
 				zap.String("job_id", job.JobID.String()),
 				zap.String("language", string(job.Language)),
 			)
 
+			if c.paused(ctx, job.Language) {
+				if err := hold(ctx, holdCh, delivery, job.Language); err != nil {
+					// Back to the queue; the reconnect backs off before the
+					// broker delivers it again.
+					delivery.Nack(false, true)
+					return fmt.Errorf("hold job %s: %w", job.JobID, err)
+				}
+				c.logger.Info("Held job of paused language",
+					zap.String("job_id", job.JobID.String()),
+					zap.String("language", string(job.Language)),
+				)
+				continue
+			}
+
 			// Create a local copy of the delivery tag so the closures are safe.
 			tag := delivery.DeliveryTag
 			localCh := ch
@@ -215,6 +334,10 @@ func (c *Consumer) consume(ctx context.Context) error {
 				Nack: func(requeue bool) error {
 					return localCh.Nack(tag, false, requeue)
 				},
+				Requeue: func() error {
+					return requeuePreempted(ctx, holdCh, delivery)
+				},
+				Deliveries: deliveryCount(delivery.Headers),
 			}
 
 			// Dispatch to worker pool. This blocks if the channel is full,
@@ -230,6 +353,85 @@ func (c *Consumer) consume(ctx context.Context) error {
 	}
 }
 
+// paused reports whether lang is paused. Jobs run if that cannot be told,
+// so an unreachable Redis does not stall every language.
+func (c *Consumer) paused(ctx context.Context, lang domain.Language) bool {
+	if c.pauses == nil {
+		return false
+	}
+	paused, err := c.pauses.Paused(ctx, lang)
+	if err != nil {
+		c.logger.Warn("Cannot check whether language is paused; running its job", zap.Error(err))
+		return false
+	}
+	return paused
+}
+
+// hold moves a delivery to its language's holding queue, acknowledging it
+// only once the broker confirmed the copy, so the job is in the broker
+// throughout.
+func hold(ctx context.Context, ch *amqplib.Channel, delivery amqplib.Delivery, lang domain.Language) error {
+	queue := holdingQueuePrefix + string(lang)
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, amqplib.Table{"x-queue-type": "quorum"}); err != nil {
+		return fmt.Errorf("declare %s: %w", queue, err)
+	}
+
+	// The count of deliveries restarts once the job is released.
+	if err := publishCopy(ctx, ch, queue, delivery, uncountedHeaders(delivery.Headers)); err != nil {
+		return err
+	}
+	return delivery.Ack(false)
+}
+
+// requeuePreempted moves a preempted job's delivery to the back of the job
+// queue, acknowledging it once the broker confirmed the copy. The copy
+// records the deliveries before this one, so the preempted delivery does
+// not count toward the delivery limit.
+func requeuePreempted(ctx context.Context, ch *amqplib.Channel, delivery amqplib.Delivery) error {
+	headers := uncountedHeaders(delivery.Headers)
+	headers[priorDeliveriesHeader] = int64(deliveryCount(delivery.Headers) - 1)
+	if err := publishCopy(ctx, ch, queueName, delivery, headers); err != nil {
+		return err
+	}
+	return delivery.Ack(false)
+}
+
+// uncountedHeaders copies headers without the delivery counts.
+func uncountedHeaders(headers amqplib.Table) amqplib.Table {
+	copied := amqplib.Table{}
+	for k, v := range headers {
+		if k != "x-delivery-count" && k != priorDeliveriesHeader {
+			copied[k] = v
+		}
+	}
+	return copied
+}
+
+// publishCopy publishes delivery's message to queue with headers and waits
+// for the broker to confirm it.
+func publishCopy(ctx context.Context, ch *amqplib.Channel, queue string, delivery amqplib.Delivery, headers amqplib.Table) error {
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, false, false, amqplib.Publishing{
+		Headers:      headers,
+		ContentType:  delivery.ContentType,
+		DeliveryMode: amqplib.Persistent,
+		MessageId:    delivery.MessageId,
+		Priority:     delivery.Priority,
+		Timestamp:    delivery.Timestamp,
+		Body:         delivery.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("publish to %s: %w", queue, err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("publish to %s: %w", queue, err)
+	}
+	if !acked {
+		return fmt.Errorf("broker nacked publish to %s", queue)
+	}
+	return nil
+}
+
 // Close gracefully shuts down the consumer.
 func (c *Consumer) Close() error {
 	c.mu.Lock()
@@ -254,3 +456,24 @@ func (c *Consumer) Close() error {
 	}
 	return firstErr
 }
+
+// deliveryCount reads how often a message has been delivered. Quorum queues
+// count earlier deliveries in the x-delivery-count header, which is absent on
+// the first one; a preempted job's republished message adds those of the
+// original from priorDeliveriesHeader.
+func deliveryCount(headers amqplib.Table) int {
+	return headerInt(headers, priorDeliveriesHeader) + headerInt(headers, "x-delivery-count") + 1
+}
+
+// headerInt reads an integer header, 0 if it is absent.
+func headerInt(headers amqplib.Table, name string) int {
+	switch n := headers[name].(type) {
+	case int64:
+		return int(n)
+	case int32:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package amqp
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+)
+
+const (
+	// An endpoint that fails is passed over for endpointCooldown, doubling
+	// with each failure in a row up to maxEndpointCooldown.
+	endpointCooldown    = 5 * time.Second
+	maxEndpointCooldown = time.Minute
+)
+
+// endpoints rotates among the broker URLs of a cluster. Each dial goes to
+// the endpoint after the last one tried, skipping endpoints that failed
+// recently until none is left; then the one that comes back soonest is
+// tried anyway, so a cluster that is entirely down is still retried.
+type endpoints struct {
+	mu        sync.Mutex
+	urls      []string
+	labels    []string
+	next      int
+	failures  []int
+	downUntil []time.Time
+}
+
+func newEndpoints(urls []string) *endpoints {
+	e := &endpoints{
+		urls:      urls,
+		labels:    make([]string, len(urls)),
+		failures:  make([]int, len(urls)),
+		downUntil: make([]time.Time, len(urls)),
+	}
+	for i, u := range urls {
+		e.labels[i] = endpointLabel(u)
+	}
+	return e
+}
+
+// endpointLabel names an endpoint by host, leaving out its credentials.
+func endpointLabel(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "invalid"
+	}
+	return u.Host
+}
+
+// pick returns the index of the endpoint to dial next.
+func (e *endpoints) pick(now time.Time) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	best := -1
+	for k := range e.urls {
+		i := (e.next + k) % len(e.urls)
+		if !now.Before(e.downUntil[i]) {
+			best = i
+			break
+		}
+		if best == -1 || e.downUntil[i].Before(e.downUntil[best]) {
+			best = i
+		}
+	}
+	e.next = best + 1
+	return best
+}
+
+func (e *endpoints) url(i int) string {
+	return e.urls[i]
+}
+
+func (e *endpoints) label(i int) string {
+	return e.labels[i]
+}
+
+// succeeded records that endpoint i was reached.
+func (e *endpoints) succeeded(i int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures[i] = 0
+	e.downUntil[i] = time.Time{}
+	metrics.BrokerEndpointUp.WithLabelValues(e.labels[i]).Set(1)
+}
+
+// failed records that endpoint i could not be reached or dropped its
+// connection, passing it over until its cooldown ends.
+func (e *endpoints) failed(i int, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cooldown := endpointCooldown << min(e.failures[i], 4)
+	e.failures[i]++
+	e.downUntil[i] = now.Add(min(cooldown, maxEndpointCooldown))
+	metrics.BrokerEndpointUp.WithLabelValues(e.labels[i]).Set(0)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FailureClass says why a job was dead-lettered without a result.
+type FailureClass string
+
+const (
+	// FailureLock: the idempotency store could not be reached.
+	FailureLock FailureClass = "lock_unavailable"
+	// FailureStatusUpdate: the job's status could not be written.
+	FailureStatusUpdate FailureClass = "status_update_failed"
+	// FailureSandbox: the executor failed rather than the program.
+	FailureSandbox FailureClass = "sandbox_failure"
+	// FailureResultStore: the result could not be written.
+	FailureResultStore FailureClass = "result_store_failed"
+	// FailureRedeliveryLimit: the message kept coming back, typically
+	// because processing it crashed workers, and was quarantined unrun.
+	FailureRedeliveryLimit FailureClass = "redelivery_limit"
+	// FailureJobMissing: the message references a job Postgres does not
+	// have, so there is no row to fail and no tenant to tell.
+	FailureJobMissing FailureClass = "job_missing"
+	// FailureUnknown covers errors no step classified.
+	FailureUnknown FailureClass = "unknown"
+)
+
+// ErrJobNotFound is returned by repository writes to a job that does not exist.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobCancelled is returned by status updates to a job cancelled before it
+// started, and is the cause of a running job's context once its
+// cancellation is requested.
+var ErrJobCancelled = errors.New("job cancelled")
+
+// ErrJobPreempted is the cause of a running job's context once the pool
+// stops it to make room for a higher-priority job. The job is requeued to
+// run again from the start.
+var ErrJobPreempted = errors.New("job preempted")
+
+// EventReasonPreempted is the reason recorded in a job's timeline when it
+// goes back to QUEUED because it was preempted.
+const EventReasonPreempted = "preempted"
+
+// ErrRunSuperseded is returned by writes from a run of a job that already
+// has its result, or that was rejudged since the run's message was
+// published. Such a run is a duplicate: its writes change nothing.
+var ErrRunSuperseded = errors.New("job run superseded")
+
+// JobFailure is an error that dead-letters a job, tagged with its class. It
+// reads as the error it wraps.
+type JobFailure struct {
+	Class FailureClass
+	Err   error
+}
+
+func (f *JobFailure) Error() string { return f.Err.Error() }
+
+func (f *JobFailure) Unwrap() error { return f.Err }
+
+// FailureClassOf returns the class of err, or FailureUnknown.
+func FailureClassOf(err error) FailureClass {
+	var f *JobFailure
+	if errors.As(err, &f) {
+		return f.Class
+	}
+	return FailureUnknown
+}
+
+// Dead-letter event types sent to tenants' webhooks.
+const (
+	EventJobDeadLettered = "job.dead_lettered"
+	EventJobQuarantined  = "job.quarantined"
+)
+
+// DeadLetterEvent tells a tenant that one of its jobs left the queue without
+// a result. Error details stay in the worker's logs.
+type DeadLetterEvent struct {
+	Event        string       `json:"event"`
+	JobID        uuid.UUID    `json:"job_id"`
+	TenantID     string       `json:"tenant_id"`
+	Language     Language     `json:"language"`
+	ProblemID    string       `json:"problem_id,omitempty"`
+	FailureClass FailureClass `json:"failure_class"`
+	Deliveries   int          `json:"deliveries"`
+	OccurredAt   time.Time    `json:"occurred_at"`
+}
+
+// DLQWebhook is a tenant's dead-letter endpoint, set through the API.
+type DLQWebhook struct {
+	URL    string
+	Secret string
+}
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"fmt"
+	"io"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,47 +22,194 @@ const (
 	StatusTimeout             ExecutionStatus = "TIMEOUT"
 	StatusMemoryLimitExceeded ExecutionStatus = "MEMORY_LIMIT_EXCEEDED"
 	StatusInternalError       ExecutionStatus = "INTERNAL_ERROR"
+
+	// Judging verdicts for jobs submitted against a problem's test data,
+	// or with an expected output.
+	StatusAccepted    ExecutionStatus = "ACCEPTED"
+	StatusWrongAnswer ExecutionStatus = "WRONG_ANSWER"
+
+	// StatusCancelled marks a job whose cancellation was requested through
+	// the API before it finished.
+	StatusCancelled ExecutionStatus = "CANCELLED"
+
+	// StatusOutputViolation marks a run stopped because its output matched
+	// one of the request's kill patterns.
+	StatusOutputViolation ExecutionStatus = "OUTPUT_VIOLATION"
+
+	// StatusSkipped marks a test case that was not run because the
+	// termination strategy ended judging early. It never applies to a job.
+	StatusSkipped ExecutionStatus = "SKIPPED"
 )
 
 // IsTerminal returns true if the status represents a final state.
 func (s ExecutionStatus) IsTerminal() bool {
 	switch s {
 	case StatusSuccess, StatusCompilationError, StatusRuntimeError,
-		StatusTimeout, StatusMemoryLimitExceeded, StatusInternalError:
+		StatusTimeout, StatusMemoryLimitExceeded, StatusInternalError,
+		StatusAccepted, StatusWrongAnswer, StatusCancelled, StatusOutputViolation:
 		return true
 	}
 	return false
 }
 
-// Language represents a supported programming language.
+// Language identifies a programming language. The supported set and how each
+// is compiled and run come from the language registry (sandbox/languages.yaml);
+// the constants below name the languages shipped by default.
 type Language string
 
 const (
 	LangPython Language = "python"
 	LangCpp    Language = "cpp"
+	LangGo     Language = "go"
+	LangJS     Language = "javascript"
+	LangRust   Language = "rust"
 )
 
+// DefaultTenantID is charged for jobs that carry no tenant.
+const DefaultTenantID = "default"
+
 // Job represents a code execution job (received from the queue).
 type Job struct {
-	JobID         uuid.UUID       `json:"job_id"`
-	Language      Language        `json:"language"`
-	SourceCode    string          `json:"source_code"`
-	Stdin         string          `json:"stdin"`
-	Status        ExecutionStatus `json:"status"`
-	TimeLimitMs   int             `json:"time_limit_ms"`
-	MemoryLimitKB int             `json:"memory_limit_kb"`
-	CreatedAt     time.Time       `json:"created_at"`
-	UpdatedAt     time.Time       `json:"updated_at"`
+	JobID          uuid.UUID         `json:"job_id"`
+	TenantID       string            `json:"tenant_id,omitempty"`
+	Language       Language          `json:"language"`
+	SourceCode     string            `json:"source_code"`
+	Stdin          string            `json:"stdin"`
+	Status         ExecutionStatus   `json:"status"`
+	TimeLimitMs    int               `json:"time_limit_ms"`
+	MemoryLimitKB  int               `json:"memory_limit_kb"`
+	CPUTimeLimitMs int               `json:"cpu_time_limit_ms,omitempty"`
+	PidsLimit      int               `json:"pids_limit,omitempty"`
+	ProblemID      string            `json:"problem_id,omitempty"`
+	JudgeRevision  int               `json:"judge_revision,omitempty"`
+	Debug          bool              `json:"debug,omitempty"`
+	CompilerFlags  []string          `json:"compiler_flags,omitempty"`
+	Args           []string          `json:"args,omitempty"`
+	Env            map[string]string `json:"env,omitempty"`
+	SandboxTier    string            `json:"sandbox_tier,omitempty"`
+	NetworkPolicy  string            `json:"network_policy,omitempty"`
+	OutputFiles    []string          `json:"output_files,omitempty"`
+	Interactive    bool              `json:"interactive,omitempty"`
+	ExpectedOutput *string           `json:"expected_output,omitempty"`
+	CompareMode    string            `json:"compare_mode,omitempty"`
+	Priority       int               `json:"priority"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+
+	// QueuedAt is when the message was published, from its AMQP
+	// timestamp; zero where the broker did not carry one.
+	QueuedAt time.Time `json:"-"`
+}
+
+// LockID returns the idempotency key for this delivery of the job. Rejudges
+// re-publish the same job with a higher JudgeRevision and must not be treated
+// as duplicates of the original run.
+func (j *Job) LockID() uuid.UUID {
+	if j.JudgeRevision == 0 {
+		return j.JobID
+	}
+	return uuid.NewSHA1(j.JobID, []byte(fmt.Sprintf("judge-revision:%d", j.JudgeRevision)))
 }
 
 // ExecutionRequest is passed to the sandbox executor.
 type ExecutionRequest struct {
 	JobID         uuid.UUID
+	TenantID      string
 	Language      Language
 	SourceCode    string
 	Stdin         string
 	TimeLimitMs   int
 	MemoryLimitKB int
+
+	// CPUTimeLimitMs caps the CPU time the program may use, while
+	// TimeLimitMs caps wall-clock time. Zero applies TimeLimitMs to both.
+	CPUTimeLimitMs int
+
+	// PidsLimit caps the processes and threads the program may have at
+	// once. Zero keeps the limit of the language's sandbox profile.
+	PidsLimit int
+
+	// Compile-phase limits for compiled languages. Zero selects the
+	// language's default; the runtime limits above never apply to compiling.
+	CompileTimeLimitMs   int
+	CompileMemoryLimitKB int
+
+	// CompilerFlags are extra compiler arguments requested by the
+	// submission, checked against the language's allowlist before use.
+	CompilerFlags []string
+
+	// Args are appended to the language's run command.
+	Args []string
+
+	// Env is set for the program (not the compiler), checked against the
+	// language's allowlist before use.
+	Env map[string]string
+
+	// MaxOutputBytes raises the stdout/stderr capture limit when positive.
+	MaxOutputBytes int
+
+	// Debug keeps the output of every judged test case, whatever the
+	// judge's retention policy. Set for appeal reruns.
+	Debug bool
+
+	// SandboxTier asks for a class of isolation; ExecutorFactory maps tiers
+	// to executor backends.
+	SandboxTier string
+
+	// NetworkPolicy is NetworkAllowlist to let the program reach the
+	// worker's network allowlist; empty runs it without network access.
+	NetworkPolicy string
+
+	// OutputFiles names files in the work directory to collect into the
+	// result's Artifacts after the run phase.
+	OutputFiles []string
+
+	// StdinStream, when set, is read after Stdin for the rest of the run
+	// phase's input, and LiveOutput receives its stdout as it is written.
+	// Both are for interactive jobs; executors that cannot stream reject
+	// requests with a StdinStream.
+	StdinStream io.Reader
+	LiveOutput  io.Writer
+
+	// Program, when set, is the request's source already compiled, which
+	// compiled languages run in place of compiling it again.
+	Program *CompiledProgram
+
+	// KillPatterns stop the run as soon as its output matches one of them,
+	// with StatusOutputViolation. The compile phase is not watched.
+	KillPatterns []KillPattern
+}
+
+// KillPattern matches Repeats identical lines in a row of stdout or stderr
+// that each match Pattern.
+type KillPattern struct {
+	Pattern *regexp.Regexp
+	Repeats int
+
+	// Message explains the verdict; empty names the pattern instead.
+	Message string
+}
+
+// CompiledProgram is a submission compiled once for several runs, such as
+// the test cases of a judged job.
+type CompiledProgram struct {
+	Binary []byte
+
+	// CompileTimeMs is how long compiling took, or zero when the binary
+	// came from the compile cache.
+	CompileTimeMs int
+}
+
+// NetworkAllowlist is the network policy of runs allowed to reach the
+// worker's allowlist.
+const NetworkAllowlist = "allowlist"
+
+// CPULimitMs returns the CPU-time limit in force for the request.
+func (r *ExecutionRequest) CPULimitMs() int {
+	if r.CPUTimeLimitMs > 0 {
+		return r.CPUTimeLimitMs
+	}
+	return r.TimeLimitMs
 }
 
 // ExecutionResult is returned by the sandbox executor after execution completes.
@@ -70,6 +220,224 @@ type ExecutionResult struct {
 	Status       ExecutionStatus
 	TimeUsedMs   int
 	MemoryUsedKB int
+
+	// CPUTimeUsedMs is the CPU time the program used, or zero where the
+	// backend cannot measure it.
+	CPUTimeUsedMs int
+
+	// DiskUsedKB is what the work directory held when the run ended,
+	// source and stdin included, or zero where the backend cannot see it.
+	DiskUsedKB int
+
+	// CompileTimeMs is how long the compile phase took, reported once for
+	// a judged job however many cases ran; zero when nothing was compiled.
+	CompileTimeMs int
+
+	// StdoutTruncated reports that stdout hit the capture limit.
+	StdoutTruncated bool
+
+	// Timings are the phases the executor measured; the worker adds the
+	// time the job was queued and its result took to store.
+	Timings Timings
+
+	// Violation says which kill pattern stopped the run, set with
+	// StatusOutputViolation.
+	Violation string
+
+	// SecurityEvents are what the run did that the sandbox stopped; a
+	// judged job has those of all its cases.
+	SecurityEvents []SecurityEvent
+
+	// Artifacts are the requested output files the program left in its
+	// work directory; files it did not produce are missing.
+	Artifacts []Artifact
+
+	// Set only for jobs judged against a problem's test data.
+	TestDataVersion     int
+	TestResults         []TestCaseResult
+	TerminationStrategy TerminationStrategy
+
+	// Set only when the problem's test data defines subtasks.
+	Score          *float64
+	MaxScore       *float64
+	SubtaskResults []SubtaskResult
+}
+
+// TerminationStrategy decides which test cases still run after one fails.
+type TerminationStrategy string
+
+const (
+	// TerminateRunAll runs every case regardless of failures.
+	TerminateRunAll TerminationStrategy = "run_all"
+	// TerminateFirstFailure skips every case after the first failure.
+	TerminateFirstFailure TerminationStrategy = "first_failure"
+	// TerminatePerSubtask skips the remaining cases of a subtask once one of
+	// them fails, since the subtask can no longer score. Ungraded cases and
+	// per-test subtasks always run.
+	TerminatePerSubtask TerminationStrategy = "per_subtask"
+)
+
+// TestCase is a single input/expected-output pair of a problem.
+type TestCase struct {
+	Ordinal        int
+	Input          string
+	ExpectedOutput string
+
+	// Subtask is the 1-based subtask the case belongs to; 0 means the case
+	// is ungraded (e.g. a sample) and only affects the verdict.
+	Subtask int
+
+	// TimeLimitMs and MemoryLimitKB override the job's limits for this case
+	// when positive.
+	TimeLimitMs   int
+	MemoryLimitKB int
+
+	// GeneratorSeed, when set, means Input is produced by running the
+	// problem's generator with this seed instead of being stored.
+	GeneratorSeed *int64
+}
+
+// Generator is a problem's input generator. It is run in the sandbox with a
+// test case's seed as its only argument and its stdout becomes the input.
+type Generator struct {
+	Language   Language
+	SourceCode string
+
+	// Hash identifies the language and source, keying cached inputs.
+	Hash string
+}
+
+// Comparator is a problem's own output check, a WebAssembly module run by
+// the worker for every case that exits cleanly.
+type Comparator struct {
+	Wasm []byte
+
+	// Hash identifies the module, keying compiled modules.
+	Hash string
+}
+
+// Interactor is a problem's judge program for interactive problems. It runs
+// in the sandbox alongside the submission, each reading what the other
+// writes, and decides the case's verdict by its exit code.
+type Interactor struct {
+	Language   Language
+	SourceCode string
+}
+
+// Subtask is a group of test cases worth Points, awarded as Scoring says.
+type Subtask struct {
+	Ordinal int
+	Points  float64
+	Scoring SubtaskScoring
+}
+
+// SubtaskScoring decides how much of a subtask's points a submission earns.
+type SubtaskScoring string
+
+const (
+	// ScoreAllOrNothing awards the points only when every case in the
+	// subtask is accepted.
+	ScoreAllOrNothing SubtaskScoring = "all_or_nothing"
+	// ScorePerTest awards the points in proportion to the accepted cases.
+	ScorePerTest SubtaskScoring = "per_test"
+)
+
+// TestData is the versioned set of test cases a submission is judged against.
+type TestData struct {
+	ProblemID string
+	Version   int
+	Cases     []TestCase
+	Subtasks  []Subtask
+
+	// Strategy is the problem's early-termination strategy; empty means
+	// TerminateRunAll.
+	Strategy TerminationStrategy
+
+	// Generator produces the input of cases with a GeneratorSeed; nil when
+	// every input is stored.
+	Generator *Generator
+
+	// Comparator replaces the token comparison of outputs; nil when the
+	// problem has none.
+	Comparator *Comparator
+
+	// Interactor converses with the submission and judges each case in
+	// place of comparing outputs; nil unless the problem is interactive.
+	Interactor *Interactor
+
+	// KillPatterns stop any case whose output matches one of them.
+	KillPatterns []KillPattern
+}
+
+// Timings breaks down where a job's time went, in milliseconds of wall-clock
+// time. CompileMs is zero for interpreted languages; for judged jobs the
+// work directory setup and run times add up all cases.
+type Timings struct {
+	QueueWaitMs    int `json:"queue_wait_ms"`
+	WorkdirSetupMs int `json:"workdir_setup_ms"`
+	CompileMs      int `json:"compile_ms"`
+	RunMs          int `json:"run_ms"`
+	ResultStoreMs  int `json:"result_store_ms"`
+}
+
+// Artifact is an output file collected from a run's work directory.
+type Artifact struct {
+	Name    string
+	Content []byte
+	// SizeBytes is the file's size; Content holds only its first bytes
+	// when Truncated is set.
+	SizeBytes int64
+	Truncated bool
+}
+
+// TestCaseResult is the per-case outcome stored alongside a judged job.
+type TestCaseResult struct {
+	Ordinal       int             `json:"ordinal"`
+	Status        ExecutionStatus `json:"status"`
+	TimeUsedMs    int             `json:"time_used_ms"`
+	MemoryUsedKB  int             `json:"memory_used_kb"`
+	CPUTimeUsedMs int             `json:"cpu_time_used_ms,omitempty"`
+	DiskUsedKB    int             `json:"disk_used_kb,omitempty"`
+	Message       string          `json:"message,omitempty"`
+	Subtask       int             `json:"subtask,omitempty"`
+
+	// TimeLimitMs and MemoryLimitKB are the limits the case actually ran
+	// under, after any per-case override.
+	TimeLimitMs   int `json:"time_limit_ms"`
+	MemoryLimitKB int `json:"memory_limit_kb"`
+
+	// OutputHash is the SHA-256 of the case's stdout; Output is the stdout
+	// itself, kept only when the judge's retention policy says so.
+	OutputHash string `json:"output_hash,omitempty"`
+	Output     string `json:"output,omitempty"`
+}
+
+// SubtaskResult is the score awarded for one subtask of a judged job.
+type SubtaskResult struct {
+	Ordinal int             `json:"ordinal"`
+	Status  ExecutionStatus `json:"status"`
+	Points  float64         `json:"points"`
+	Score   float64         `json:"score"`
+}
+
+// Mount is a host path bind-mounted read-only into the sandbox.
+type Mount struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Runtime is an execution preset registered through the API and stored in
+// the database. Submissions name it as their language; the worker turns it
+// into a language spec when the language is not in its registry.
+type Runtime struct {
+	Name         Language
+	Version      string
+	SourceFile   string
+	NsjailConfig string
+	Compile      []string
+	Run          []string
+	Mounts       []Mount
+	Env          map[string]string
 }
 
 // AckFunc acknowledges that a message has been successfully processed.
@@ -79,10 +447,21 @@ type AckFunc func() error
 // returned to the queue; otherwise it is routed to the dead-letter exchange.
 type NackFunc func(requeue bool) error
 
+// RequeueFunc returns a message to the queue without counting the current
+// delivery against the delivery limit.
+type RequeueFunc func() error
+
 // JobMessage wraps a Job together with its RabbitMQ acknowledgement callbacks.
 // The worker pool must call Ack after successful execution or Nack on failure.
 type JobMessage struct {
 	Job  *Job
 	Ack  AckFunc
 	Nack NackFunc
+	// Requeue, if set, returns a preempted job's message to the queue; see
+	// RequeueFunc. Without it the pool requeues with Nack.
+	Requeue RequeueFunc
+
+	// Deliveries counts how often the broker has delivered the message,
+	// this delivery included, less the deliveries that ended in preemption.
+	Deliveries int
 }
@@ -0,0 +1,32 @@
+package domain
+
+// SecurityEventKind says what a run did that the sandbox or its policies
+// stopped. Events are stored with the job's result for the API's security
+// event export.
+type SecurityEventKind string
+
+const (
+	// SecuritySeccompViolation: the program made a syscall its language's
+	// seccomp policy forbids and was killed with SIGSYS.
+	SecuritySeccompViolation SecurityEventKind = "seccomp_violation"
+	// SecurityNetworkBlocked: a run under the allowlist network policy sent
+	// packets to destinations outside the allowlist.
+	SecurityNetworkBlocked SecurityEventKind = "network_blocked"
+	// SecurityAbuseFlag: the output matched one of the problem's kill
+	// patterns.
+	SecurityAbuseFlag SecurityEventKind = "abuse_flag"
+	// SecurityQuarantine: the job's message kept being redelivered and was
+	// quarantined unrun.
+	SecurityQuarantine SecurityEventKind = "quarantine"
+)
+
+// SecurityEvent is one security-relevant thing that happened to a job.
+type SecurityEvent struct {
+	Kind   SecurityEventKind
+	Detail string
+}
+
+// Flag records a security event of the run.
+func (r *ExecutionResult) Flag(kind SecurityEventKind, detail string) {
+	r.SecurityEvents = append(r.SecurityEvents, SecurityEvent{Kind: kind, Detail: detail})
+}
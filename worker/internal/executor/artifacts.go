@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// maxArtifactBytes caps what is kept of each output file.
+const maxArtifactBytes = 1 << 20 // 1 MB
+
+// collectArtifacts reads the requested output files from workDir. The
+// program controls the directory, so anything but a regular file directly
+// in it is skipped: a symlink must not make the worker read host files, and
+// a FIFO must not block it. Files the program did not produce or made
+// unreadable are skipped too.
+func collectArtifacts(workDir string, names []string) ([]domain.Artifact, error) {
+	var artifacts []domain.Artifact
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+			continue
+		}
+		a, err := readArtifact(filepath.Join(workDir, name))
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) || errors.Is(err, errNotRegular) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("collect artifact %s: %w", name, err)
+		}
+		a.Name = name
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, nil
+}
+
+var errNotRegular = errors.New("not a regular file")
+
+func readArtifact(path string) (domain.Artifact, error) {
+	// O_NOFOLLOW fails on a symlink; O_NONBLOCK keeps opening a FIFO from
+	// waiting for a writer.
+	f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_NONBLOCK, 0)
+	if errors.Is(err, syscall.ELOOP) {
+		return domain.Artifact{}, errNotRegular
+	}
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return domain.Artifact{}, errNotRegular
+	}
+	content, err := io.ReadAll(io.LimitReader(f, maxArtifactBytes))
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	return domain.Artifact{
+		Content:   content,
+		SizeBytes: info.Size(),
+		Truncated: info.Size() > int64(len(content)),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupControllers are the controllers nsjail's limits need below a job cgroup.
+const cgroupControllers = "+memory +pids +cpu"
+
+// JobCgroups gives every sandbox run its own cgroup v2 directory under a
+// root the worker owns. nsjail creates its jail cgroup inside it and removes
+// that on exit, while the job cgroup keeps the hierarchical memory.peak and
+// memory.events of that one run, unaffected by concurrent executions.
+type JobCgroups struct {
+	root string
+}
+
+// NewJobCgroups prepares root, which must be on a cgroup v2 filesystem whose
+// parent delegates the memory, pids and cpu controllers to it.
+func NewJobCgroups(root string) (*JobCgroups, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create cgroup root: %w", err)
+	}
+	if err := enableControllers(root); err != nil {
+		return nil, err
+	}
+	return &JobCgroups{root: root}, nil
+}
+
+func enableControllers(dir string) error {
+	if err := os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte(cgroupControllers), 0o644); err != nil {
+		return fmt.Errorf("enable cgroup controllers in %s: %w", dir, err)
+	}
+	return nil
+}
+
+// jobCgroup is the cgroup of one sandbox run.
+type jobCgroup struct {
+	dir string
+}
+
+// create makes a fresh cgroup for one run of jobID. Judged jobs run several
+// cases at once, so the name is made unique.
+func (c *JobCgroups) create(jobID string) (*jobCgroup, error) {
+	dir, err := os.MkdirTemp(c.root, jobID+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("create job cgroup: %w", err)
+	}
+	if err := enableControllers(dir); err != nil {
+		os.Remove(dir)
+		return nil, err
+	}
+	return &jobCgroup{dir: dir}, nil
+}
+
+// cgroupStats is what a job cgroup recorded for its run.
+type cgroupStats struct {
+	peakKB   int
+	oomKills int
+	cpuMs    int
+}
+
+// stats reads the run's peak memory, OOM kill count and CPU time. Missing
+// files (memory.peak needs Linux 5.19) read as zero.
+func (g *jobCgroup) stats() cgroupStats {
+	var s cgroupStats
+	if data, err := os.ReadFile(filepath.Join(g.dir, "memory.peak")); err == nil {
+		if v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil {
+			s.peakKB = int(v / 1024)
+		}
+	}
+	s.oomKills = int(g.statValue("memory.events", "oom_kill"))
+	s.cpuMs = int(g.statValue("cpu.stat", "usage_usec") / 1000)
+	return s
+}
+
+// statValue reads one "key value" line of a flat-keyed cgroup file.
+func (g *jobCgroup) statValue(file, key string) int64 {
+	data, err := os.ReadFile(filepath.Join(g.dir, file))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, value, ok := strings.Cut(line, " "); ok && name == key {
+			v, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			return v
+		}
+	}
+	return 0
+}
+
+// remove deletes the job cgroup along with any jail cgroup nsjail left
+// behind after being killed.
+func (g *jobCgroup) remove() error {
+	children, _ := filepath.Glob(filepath.Join(g.dir, "NSJAIL.*"))
+	for _, child := range children {
+		os.Remove(child)
+	}
+	return os.Remove(g.dir)
+}
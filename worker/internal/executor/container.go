@@ -0,0 +1,296 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+)
+
+const (
+	// containerStartGrace is how long past the job's time limit the host
+	// waits for a container before killing it; it covers container start-up.
+	containerStartGrace = 10 * time.Second
+
+	// dockerRunFailed is the exit code of "docker run" itself failing, as
+	// opposed to the command in the container.
+	dockerRunFailed = 125
+
+	// killedExitCode is what timeout(1) and the OOM killer leave behind.
+	killedExitCode = 137
+
+	// defaultContainerPids is the process limit of requests without one.
+	defaultContainerPids = 64
+)
+
+// ContainerConfig locates Docker and the image executions run in.
+type ContainerConfig struct {
+	DockerPath string
+	// Image must have each language's toolchain at the paths in the
+	// registry, as the worker image does.
+	Image string
+	// Runtime is passed as --runtime; "runsc" runs containers under gVisor.
+	// Empty uses the daemon's default runtime.
+	Runtime string
+}
+
+// ContainerExecutor runs each phase of an execution in a throwaway Docker
+// container with no network, a read-only root filesystem and the job's
+// limits as cgroup limits. With the runsc runtime it is the gVisor backend.
+// It supports registry languages only and does not report memory usage.
+type ContainerExecutor struct {
+	cfg       ContainerConfig
+	languages *language.Registry
+	logger    *zap.Logger
+}
+
+// NewContainerExecutor creates a container executor for the languages in the registry.
+func NewContainerExecutor(cfg ContainerConfig, languages *language.Registry, logger *zap.Logger) *ContainerExecutor {
+	if cfg.DockerPath == "" {
+		cfg.DockerPath = "docker"
+	}
+	return &ContainerExecutor{
+		cfg:       cfg,
+		languages: languages,
+		logger:    logger,
+	}
+}
+
+// Execute compiles (if needed) and runs the code, one container per phase,
+// sharing the work dir between them.
+func (e *ContainerExecutor) Execute(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+	spec, ok := e.languages.Lookup(req.Language)
+	if !ok {
+		return &domain.ExecutionResult{
+			Status: domain.StatusInternalError,
+			Stderr: "unsupported language: " + string(req.Language),
+		}, nil
+	}
+	if err := spec.CheckFlags(req.CompilerFlags); err != nil {
+		return &domain.ExecutionResult{
+			Status:   domain.StatusCompilationError,
+			Stderr:   err.Error(),
+			ExitCode: 1,
+		}, nil
+	}
+	if err := spec.CheckEnv(req.Env); err != nil {
+		return &domain.ExecutionResult{
+			Status: domain.StatusInternalError,
+			Stderr: err.Error(),
+		}, nil
+	}
+	if req.NetworkPolicy != "" {
+		return networkUnsupported(req), nil
+	}
+	if req.StdinStream != nil {
+		return interactiveUnsupported(), nil
+	}
+
+	// Named without the job ID, which the program could read from its
+	// mounts.
+	setupStart := time.Now()
+	workDir, err := os.MkdirTemp("", "sentinel-ctr-*")
+	if err != nil {
+		return nil, fmt.Errorf("create work dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	// The container runs as nobody and writes the compiled binary here.
+	if err := os.Chmod(workDir, 0o777); err != nil {
+		return nil, fmt.Errorf("chmod work dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, spec.SourceFile), []byte(req.SourceCode), 0o644); err != nil {
+		return nil, fmt.Errorf("write source: %w", err)
+	}
+	timings := domain.Timings{WorkdirSetupMs: int(time.Since(setupStart).Milliseconds())}
+
+	if spec.IsCompiled() {
+		compileStart := time.Now()
+		compileResult, err := e.runContainer(ctx, compileRequest(req, spec), spec, workDir, "compile", spec.CompileArgs(req.CompilerFlags))
+		if err != nil {
+			return nil, fmt.Errorf("compile: %w", err)
+		}
+		timings.CompileMs = int(time.Since(compileStart).Milliseconds())
+		if compileResult.ExitCode != 0 {
+			compileResult.Status = domain.StatusCompilationError
+			compileResult.DiskUsedKB = diskUsageKB(workDir)
+			compileResult.Timings = timings
+			return compileResult, nil
+		}
+	}
+	runStart := time.Now()
+	result, err := e.runContainer(ctx, req, spec, workDir, "run", append(spec.RunArgs(), req.Args...))
+	if err != nil {
+		return nil, err
+	}
+	timings.RunMs = int(time.Since(runStart).Milliseconds())
+	result.Timings = timings
+	result.DiskUsedKB = diskUsageKB(workDir)
+	if result.Artifacts, err = collectArtifacts(workDir, req.OutputFiles); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// containerArgs builds the "docker run" arguments for one phase. The
+// container is not removed on exit so its OOM flag can be read afterwards.
+func (e *ContainerExecutor) containerArgs(name string, req *domain.ExecutionRequest, spec *language.Spec, workDir string, argv []string) []string {
+	memory := fmt.Sprintf("%dk", req.MemoryLimitKB)
+	pids := defaultContainerPids
+	if req.PidsLimit > 0 {
+		pids = req.PidsLimit
+	}
+	args := []string{"run", "--name", name}
+	if e.cfg.Runtime != "" {
+		args = append(args, "--runtime", e.cfg.Runtime)
+	}
+	args = append(args,
+		"--network", "none",
+		"--memory", memory,
+		"--memory-swap", memory,
+		"--pids-limit", fmt.Sprintf("%d", pids),
+		"--cpus", "1",
+		"--ulimit", fmt.Sprintf("cpu=%d", cpuLimitSeconds(req)),
+		"--read-only",
+		"--tmpfs", "/tmp",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--user", "65534:65534",
+		"-v", workDir+":/tmp/work",
+		"-w", "/tmp/work",
+	)
+	// The submission's variables come last so they win over the spec's.
+	for _, env := range []map[string]string{spec.EnvAt(language.SandboxWorkDir), req.Env} {
+		for _, name := range language.EnvNames(env) {
+			args = append(args, "-e", name+"="+env[name])
+		}
+	}
+	args = append(args,
+		"-i", "--entrypoint", "timeout", e.cfg.Image,
+		"-s", "KILL", fmt.Sprintf("%.3f", float64(req.TimeLimitMs)/1000),
+	)
+	return append(args, argv...)
+}
+
+func (e *ContainerExecutor) runContainer(
+	ctx context.Context,
+	req *domain.ExecutionRequest,
+	spec *language.Spec,
+	workDir, phase string,
+	argv []string,
+) (*domain.ExecutionResult, error) {
+	name := fmt.Sprintf("sentinel-%s-%s", req.JobID.String(), phase)
+	defer e.docker(context.Background(), "rm", "-f", name)
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(req.TimeLimitMs)*time.Millisecond+containerStartGrace)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, e.cfg.DockerPath, e.containerArgs(name, req, spec, workDir, argv)...)
+	cmd.Stdin = strings.NewReader(req.Stdin)
+	limit := maxOutputBytes
+	if req.MaxOutputBytes > 0 {
+		limit = req.MaxOutputBytes
+	}
+	var stdout, stderr limitedBuffer
+	stdout.limit = limit
+	stderr.limit = limit
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// The watch goes first: the buffers' short writes past their limit
+	// would stop MultiWriter before it.
+	watch := newOutputWatch(req.KillPatterns, cancel)
+	var watchStdout, watchStderr io.WriteCloser
+	if watch != nil {
+		watchStdout, watchStderr = watch.stream("stdout", nil), watch.stream("stderr", nil)
+		cmd.Stdout = io.MultiWriter(watchStdout, &stdout)
+		cmd.Stderr = io.MultiWriter(watchStderr, &stderr)
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+	if watch != nil {
+		watchStdout.Close()
+		watchStderr.Close()
+	}
+	e.logger.Debug("Container execution completed",
+		zap.String("job_id", req.JobID.String()),
+		zap.String("phase", phase),
+		zap.Duration("elapsed", elapsed),
+		zap.Error(err),
+	)
+
+	result := &domain.ExecutionResult{
+		Stdout:          truncateOutput(stdout.String(), stdout.truncated),
+		Stderr:          truncateOutput(stderr.String(), false),
+		TimeUsedMs:      int(elapsed.Milliseconds()),
+		StdoutTruncated: stdout.truncated,
+	}
+
+	if watch != nil && watch.Violation() != "" {
+		e.docker(context.Background(), "kill", name)
+		result.Status = domain.StatusOutputViolation
+		result.Violation = watch.Violation()
+		result.Flag(domain.SecurityAbuseFlag, result.Violation)
+		result.ExitCode = -1
+		return result, nil
+	}
+	if runCtx.Err() == context.DeadlineExceeded {
+		// Killing the client leaves the container running.
+		e.docker(context.Background(), "kill", name)
+		result.Status = domain.StatusTimeout
+		result.ExitCode = -1
+		return result, nil
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.Status = domain.StatusSuccess
+		return result, nil
+	case !errors.As(err, &exitErr):
+		return nil, fmt.Errorf("run container: %w", err)
+	case exitErr.ExitCode() == dockerRunFailed:
+		return nil, fmt.Errorf("run container: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	result.ExitCode = exitErr.ExitCode()
+	switch {
+	case e.oomKilled(name):
+		result.Status = domain.StatusMemoryLimitExceeded
+	case result.ExitCode == killedExitCode && result.TimeUsedMs >= req.TimeLimitMs:
+		result.Status = domain.StatusTimeout
+		result.ExitCode = -1
+	default:
+		result.Status = domain.StatusRuntimeError
+	}
+	return result, nil
+}
+
+// oomKilled reports whether the kernel killed the container for exceeding
+// its memory limit.
+func (e *ContainerExecutor) oomKilled(name string) bool {
+	out, err := e.docker(context.Background(), "inspect", "-f", "{{.State.OOMKilled}}", name)
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+func (e *ContainerExecutor) docker(ctx context.Context, args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.cfg.DockerPath, args...)
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		e.logger.Debug("docker command failed", zap.Strings("args", args), zap.Error(err))
+		return "", err
+	}
+	return out.String(), nil
+}
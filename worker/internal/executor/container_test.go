@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// fakeDocker writes a docker stand-in that logs each "run" command line to
+// runs.log, handles "run" with runScript and answers "inspect" with oom.
+func fakeDocker(t *testing.T, runScript, oom string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "runs.log")
+	body := `#!/bin/sh
+case "$1" in
+run) echo "$@" >> ` + log + `
+` + runScript + `
+;;
+inspect) echo ` + oom + ` ;;
+esac
+`
+	docker := filepath.Join(dir, "docker")
+	if err := os.WriteFile(docker, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return docker, log
+}
+
+func TestContainer_RunsInGVisor(t *testing.T) {
+	docker, log := fakeDocker(t, "cat; echo hello", "false")
+	exe := NewContainerExecutor(ContainerConfig{DockerPath: docker, Image: "sentinel-worker", Runtime: "runsc"}, testLanguages(t), zap.NewNop())
+
+	res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(input())",
+		Stdin:         "in\n",
+		TimeLimitMs:   1500,
+		MemoryLimitKB: 65536,
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if res.Status != domain.StatusSuccess || res.Stdout != "in\nhello\n" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := string(data)
+	for _, want := range []string{"--runtime runsc", "--network none", "--memory 65536k", "--read-only", "sentinel-worker -s KILL 1.500"} {
+		if !strings.Contains(run, want) {
+			t.Errorf("docker run %q missing %q", run, want)
+		}
+	}
+}
+
+func TestContainer_Statuses(t *testing.T) {
+	tests := []struct {
+		name      string
+		language  domain.Language
+		runScript string
+		oom       string
+		want      domain.ExecutionStatus
+	}{
+		{"oom", domain.LangPython, "exit 137", "true", domain.StatusMemoryLimitExceeded},
+		{"runtime error", domain.LangPython, "exit 3", "false", domain.StatusRuntimeError},
+		{"compile error", domain.LangCpp, "echo 'error: expected ;' >&2; exit 1", "false", domain.StatusCompilationError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docker, log := fakeDocker(t, tt.runScript, tt.oom)
+			exe := NewContainerExecutor(ContainerConfig{DockerPath: docker, Image: "img"}, testLanguages(t), zap.NewNop())
+			res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+				JobID:         uuid.New(),
+				Language:      tt.language,
+				SourceCode:    "x",
+				TimeLimitMs:   1000,
+				MemoryLimitKB: 65536,
+			})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if res.Status != tt.want {
+				t.Errorf("status = %s, want %s (%+v)", res.Status, tt.want, res)
+			}
+			if data, _ := os.ReadFile(log); strings.Count(string(data), "\n") != 1 {
+				t.Errorf("expected one container, got:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestContainer_DockerFailureIsAnError(t *testing.T) {
+	docker, _ := fakeDocker(t, "echo 'Unable to find image' >&2; exit 125", "false")
+	exe := NewContainerExecutor(ContainerConfig{DockerPath: docker, Image: "img"}, testLanguages(t), zap.NewNop())
+	_, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "x",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	})
+	if err == nil || !strings.Contains(err.Error(), "Unable to find image") {
+		t.Fatalf("expected the docker error, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ParseCPUList parses a cpuset list such as "2-5,8" into CPU numbers.
+func ParseCPUList(s string) ([]int, error) {
+	var cpus []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(lo)
+		last := first
+		if err == nil && isRange {
+			last, err = strconv.Atoi(hi)
+		}
+		if err != nil || first < 0 || last < first {
+			return nil, fmt.Errorf("invalid CPU list entry %q", part)
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			if seen[cpu] {
+				return nil, fmt.Errorf("CPU %d listed twice", cpu)
+			}
+			seen[cpu] = true
+			cpus = append(cpus, cpu)
+		}
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("CPU list is empty")
+	}
+	return cpus, nil
+}
+
+// CPUPinning hands every sandbox run a core of its own from a fixed set,
+// so concurrent runs do not take cycles from each other and time limits
+// judge each run on an otherwise idle core. A run waits for a core when all
+// are taken, so the set should have a core for every sandbox the worker
+// runs at once. Cores are applied through the run's job cgroup.
+type CPUPinning struct {
+	free chan int
+}
+
+// NewCPUPinning pins runs to the cores in cpus, a cpuset list.
+func NewCPUPinning(cpus string) (*CPUPinning, error) {
+	list, err := ParseCPUList(cpus)
+	if err != nil {
+		return nil, err
+	}
+	p := &CPUPinning{free: make(chan int, len(list))}
+	for _, cpu := range list {
+		p.free <- cpu
+	}
+	return p, nil
+}
+
+// Size is the number of cores runs are spread over.
+func (p *CPUPinning) Size() int {
+	return cap(p.free)
+}
+
+// acquire takes a free core, waiting until one is released or ctx is done.
+func (p *CPUPinning) acquire(ctx context.Context) (int, error) {
+	select {
+	case cpu := <-p.free:
+		return cpu, nil
+	case <-ctx.Done():
+		return 0, fmt.Errorf("wait for a free CPU: %w", ctx.Err())
+	}
+}
+
+func (p *CPUPinning) release(cpu int) {
+	p.free <- cpu
+}
+
+// pin restricts the run to cpu. The job cgroup's parent must have the
+// cpuset controller enabled, see JobCgroups.EnableCPUSet.
+func (g *jobCgroup) pin(cpu int) error {
+	if err := os.WriteFile(filepath.Join(g.dir, "cpuset.cpus"), []byte(strconv.Itoa(cpu)), 0o644); err != nil {
+		return fmt.Errorf("pin job cgroup to CPU %d: %w", cpu, err)
+	}
+	return nil
+}
+
+// EnableCPUSet makes job cgroups pinnable to cores. It is separate from
+// the controllers NewJobCgroups enables because not every host delegates
+// cpuset.
+func (c *JobCgroups) EnableCPUSet() error {
+	if err := os.WriteFile(filepath.Join(c.root, "cgroup.subtree_control"), []byte("+cpuset"), 0o644); err != nil {
+		return fmt.Errorf("enable cpuset controller in %s: %w", c.root, err)
+	}
+	return nil
+}
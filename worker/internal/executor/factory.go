@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+// Backend names an executor implementation.
+type Backend string
+
+const (
+	// BackendNsjail runs code in an nsjail sandbox (SandboxExecutor).
+	BackendNsjail Backend = "nsjail"
+	// BackendFirecracker runs code in a microVM (FirecrackerExecutor).
+	BackendFirecracker Backend = "firecracker"
+	// BackendDocker runs code in a Docker container (ContainerExecutor).
+	BackendDocker Backend = "docker"
+	// BackendGVisor runs code in a Docker container under gVisor's runsc.
+	BackendGVisor Backend = "gvisor"
+)
+
+// ParseBackend validates a backend name.
+func ParseBackend(s string) (Backend, error) {
+	switch b := Backend(strings.TrimSpace(s)); b {
+	case BackendNsjail, BackendFirecracker, BackendDocker, BackendGVisor:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown executor backend %q (want nsjail, firecracker, docker or gvisor)", s)
+}
+
+// ParseAssignments parses "key=backend,..." into a map, as used for the
+// tier, tenant and language selection rules.
+func ParseAssignments(s string) (map[string]Backend, error) {
+	assignments := make(map[string]Backend)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, name, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid executor assignment %q (want key=backend)", pair)
+		}
+		backend, err := ParseBackend(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := assignments[key]; dup {
+			return nil, fmt.Errorf("%q assigned an executor twice", key)
+		}
+		assignments[key] = backend
+	}
+	return assignments, nil
+}
+
+// SelectionRules choose the backend for an execution. The first rule that
+// matches wins, in field order: the job's sandbox tier, its tenant, its
+// language, and finally Default.
+type SelectionRules struct {
+	Tiers     map[string]Backend
+	Tenants   map[string]Backend
+	Languages map[string]Backend
+	Default   Backend
+}
+
+// AddFirecrackerTenants folds the deprecated WORKER_FIRECRACKER_TENANTS
+// list into r: each tenant listed gets a firecracker tenant rule, and "*"
+// makes firecracker the default. A tenant the tenant rules assign to
+// another backend, or "*" with a default other than nsjail, is an error.
+func (r *SelectionRules) AddFirecrackerTenants(list string) error {
+	for _, tenant := range strings.Split(list, ",") {
+		tenant = strings.TrimSpace(tenant)
+		switch {
+		case tenant == "":
+		case tenant == "*":
+			if r.Default != "" && r.Default != BackendNsjail && r.Default != BackendFirecracker {
+				return fmt.Errorf("\"*\" conflicts with default executor %q", r.Default)
+			}
+			r.Default = BackendFirecracker
+		default:
+			if b, ok := r.Tenants[tenant]; ok && b != BackendFirecracker {
+				return fmt.Errorf("%q assigned an executor twice", tenant)
+			}
+			if r.Tenants == nil {
+				r.Tenants = make(map[string]Backend)
+			}
+			r.Tenants[tenant] = BackendFirecracker
+		}
+	}
+	return nil
+}
+
+// Backends lists every backend the rules can select, sorted.
+func (r SelectionRules) Backends() []Backend {
+	seen := map[Backend]bool{r.Default: true}
+	for _, m := range []map[string]Backend{r.Tiers, r.Tenants, r.Languages} {
+		for _, b := range m {
+			seen[b] = true
+		}
+	}
+	backends := make([]Backend, 0, len(seen))
+	for b := range seen {
+		backends = append(backends, b)
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i] < backends[j] })
+	return backends
+}
+
+// ExecutorFactory picks an executor backend per execution. It implements
+// repository.Executor, so the execute use case and the judge use it in place
+// of a single executor.
+type ExecutorFactory struct {
+	rules    SelectionRules
+	backends map[Backend]repository.Executor
+}
+
+// NewExecutorFactory creates a factory applying rules. Every backend the
+// rules reference must be registered before use; see Validate.
+func NewExecutorFactory(rules SelectionRules) *ExecutorFactory {
+	if rules.Default == "" {
+		rules.Default = BackendNsjail
+	}
+	return &ExecutorFactory{
+		rules:    rules,
+		backends: make(map[Backend]repository.Executor),
+	}
+}
+
+// Register makes exec the executor for backend.
+func (f *ExecutorFactory) Register(backend Backend, exec repository.Executor) {
+	f.backends[backend] = exec
+}
+
+// Validate reports a backend the rules reference but nobody registered.
+func (f *ExecutorFactory) Validate() error {
+	for _, b := range f.rules.Backends() {
+		if f.backends[b] == nil {
+			return fmt.Errorf("executor backend %q is selected but not configured", b)
+		}
+	}
+	return nil
+}
+
+// Select returns the backend for req. Tiers without a rule fall through to
+// the tenant and language rules. Requests without a tenant count as
+// domain.DefaultTenantID.
+func (f *ExecutorFactory) Select(req *domain.ExecutionRequest) Backend {
+	if b, ok := f.rules.Tiers[req.SandboxTier]; ok && req.SandboxTier != "" {
+		return b
+	}
+	tenant := req.TenantID
+	if tenant == "" {
+		tenant = domain.DefaultTenantID
+	}
+	if b, ok := f.rules.Tenants[tenant]; ok {
+		return b
+	}
+	if b, ok := f.rules.Languages[string(req.Language)]; ok {
+		return b
+	}
+	return f.rules.Default
+}
+
+// Execute runs req on the executor selected for it.
+func (f *ExecutorFactory) Execute(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+	backend := f.Select(req)
+	exec := f.backends[backend]
+	if exec == nil {
+		return nil, fmt.Errorf("executor backend %q is not configured", backend)
+	}
+	metrics.ExecutorSelections.WithLabelValues(string(backend)).Inc()
+	return exec.Execute(ctx, req)
+}
+
+// Compile compiles req with the backend Execute would run it on, when that
+// backend is a repository.Compiler; otherwise it returns a nil program and
+// each run compiles for itself.
+func (f *ExecutorFactory) Compile(ctx context.Context, req *domain.ExecutionRequest) (*domain.CompiledProgram, *domain.ExecutionResult, error) {
+	compiler, ok := f.backends[f.Select(req)].(repository.Compiler)
+	if !ok {
+		return nil, nil, nil
+	}
+	return compiler.Compile(ctx, req)
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository/mock"
+)
+
+func TestExecutorFactory_Select(t *testing.T) {
+	rules := SelectionRules{
+		Tiers:     map[string]Backend{"strict": BackendFirecracker},
+		Tenants:   map[string]Backend{"acme": BackendGVisor, "default": BackendDocker},
+		Languages: map[string]Backend{"python": BackendDocker},
+		Default:   BackendNsjail,
+	}
+	tests := []struct {
+		req  domain.ExecutionRequest
+		want Backend
+	}{
+		{domain.ExecutionRequest{SandboxTier: "strict", TenantID: "acme"}, BackendFirecracker},
+		{domain.ExecutionRequest{SandboxTier: "unknown", TenantID: "acme"}, BackendGVisor},
+		{domain.ExecutionRequest{TenantID: "acme", Language: domain.LangPython}, BackendGVisor},
+		{domain.ExecutionRequest{TenantID: "", Language: domain.LangCpp}, BackendDocker},
+		{domain.ExecutionRequest{TenantID: "other", Language: domain.LangPython}, BackendDocker},
+		{domain.ExecutionRequest{TenantID: "other", Language: domain.LangCpp}, BackendNsjail},
+	}
+
+	f := NewExecutorFactory(rules)
+	execs := map[Backend]*mock.Executor{}
+	for _, b := range rules.Backends() {
+		execs[b] = &mock.Executor{}
+		f.Register(b, execs[b])
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	for _, tt := range tests {
+		if got := f.Select(&tt.req); got != tt.want {
+			t.Errorf("Select(tier %q, tenant %q, language %q) = %s, want %s",
+				tt.req.SandboxTier, tt.req.TenantID, tt.req.Language, got, tt.want)
+		}
+	}
+
+	if _, err := f.Execute(context.Background(), &domain.ExecutionRequest{SandboxTier: "strict"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(execs[BackendFirecracker].ExecuteCalls) != 1 || len(execs[BackendNsjail].ExecuteCalls) != 0 {
+		t.Error("Execute did not run on the selected backend")
+	}
+}
+
+func TestExecutorFactory_ValidateMissingBackend(t *testing.T) {
+	f := NewExecutorFactory(SelectionRules{Tenants: map[string]Backend{"acme": BackendGVisor}})
+	f.Register(BackendNsjail, &mock.Executor{})
+	if err := f.Validate(); err == nil {
+		t.Fatal("expected an error for the unregistered gvisor backend")
+	}
+}
+
+func TestParseAssignments(t *testing.T) {
+	got, err := ParseAssignments(" acme=gvisor, strict = firecracker ,")
+	if err != nil {
+		t.Fatalf("ParseAssignments: %v", err)
+	}
+	if len(got) != 2 || got["acme"] != BackendGVisor || got["strict"] != BackendFirecracker {
+		t.Errorf("ParseAssignments = %v", got)
+	}
+	for _, bad := range []string{"acme", "=docker", "acme=kvm", "acme=docker,acme=nsjail"} {
+		if _, err := ParseAssignments(bad); err == nil {
+			t.Errorf("ParseAssignments(%q): expected error", bad)
+		}
+	}
+}
+
+func TestSelectionRules_AddFirecrackerTenants(t *testing.T) {
+	rules := SelectionRules{Default: BackendNsjail, Tenants: map[string]Backend{"beta": BackendFirecracker}}
+	if err := rules.AddFirecrackerTenants("acme, beta,"); err != nil {
+		t.Fatalf("AddFirecrackerTenants: %v", err)
+	}
+	if rules.Tenants["acme"] != BackendFirecracker || rules.Tenants["beta"] != BackendFirecracker || rules.Default != BackendNsjail {
+		t.Errorf("rules = %+v, want acme and beta on firecracker", rules)
+	}
+
+	all := SelectionRules{Default: BackendNsjail}
+	if err := all.AddFirecrackerTenants("*"); err != nil || all.Default != BackendFirecracker {
+		t.Errorf("\"*\": default %q, %v; want firecracker", all.Default, err)
+	}
+
+	conflicts := []SelectionRules{
+		{Default: BackendNsjail, Tenants: map[string]Backend{"acme": BackendGVisor}},
+		{Default: BackendDocker},
+	}
+	for i, list := range []string{"acme", "*"} {
+		if err := conflicts[i].AddFirecrackerTenants(list); err == nil {
+			t.Errorf("AddFirecrackerTenants(%q) over %+v: expected error", list, conflicts[i])
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+)
+
+const (
+	// fcBootArgs boots straight into the guest agent, which reboots the VM
+	// when done; reboot=k turns that into Firecracker exiting.
+	fcBootArgs = "console=ttyS0 reboot=k panic=1 pci=off quiet init=/sbin/sentinel-init"
+
+	// fcGuestOverheadMiB is added to the job's memory limit for the guest
+	// kernel, agent and the tmpfs work dir.
+	fcGuestOverheadMiB = 96
+
+	// Console lines printed by sandbox/firecracker/sentinel-init.
+	fcReadyMarker  = "SENTINEL_READY"
+	fcResultMarker = "SENTINEL_RESULT "
+)
+
+// FirecrackerConfig locates the Firecracker binary and the guest images.
+type FirecrackerConfig struct {
+	FirecrackerPath string
+	// MkfsPath builds the per-job drive; it must support "mkfs.ext4 -d".
+	MkfsPath   string
+	KernelPath string
+	// RootfsDir holds one ext4 root filesystem per language, named
+	// <language>.ext4, with sandbox/firecracker/sentinel-init installed as
+	// /sbin/sentinel-init. They are attached read-only and shared by all VMs.
+	RootfsDir   string
+	VCPUs       int
+	BootTimeout time.Duration
+}
+
+// FirecrackerExecutor runs each execution in its own Firecracker microVM,
+// for deployments that want a VM boundary around untrusted code rather than
+// nsjail's namespaces. It supports registry languages that have a rootfs;
+// it does not use the binary cache, registered runtimes or the work dir pool.
+type FirecrackerExecutor struct {
+	cfg       FirecrackerConfig
+	languages *language.Registry
+	logger    *zap.Logger
+}
+
+// NewFirecrackerExecutor creates a microVM executor for the languages in the registry.
+func NewFirecrackerExecutor(cfg FirecrackerConfig, languages *language.Registry, logger *zap.Logger) *FirecrackerExecutor {
+	if cfg.VCPUs <= 0 {
+		cfg.VCPUs = 1
+	}
+	return &FirecrackerExecutor{
+		cfg:       cfg,
+		languages: languages,
+		logger:    logger,
+	}
+}
+
+// fcResult is the outcome line printed by the guest agent. Outputs are
+// base64 in the JSON so any bytes survive the serial console.
+type fcResult struct {
+	Phase           string `json:"phase"` // "compile" (only when compiling failed) or "run"
+	ExitCode        int    `json:"exit_code"`
+	TimedOut        bool   `json:"timed_out"`
+	OOMKilled       bool   `json:"oom_killed"`
+	TimeMs          int    `json:"time_ms"`
+	MemoryKB        int    `json:"memory_kb"`
+	CPUTimeMs       int    `json:"cpu_time_ms"`
+	DiskKB          int    `json:"disk_kb"`
+	StdoutTruncated bool   `json:"stdout_truncated"`
+	Stdout          []byte `json:"stdout"`
+	Stderr          []byte `json:"stderr"`
+
+	// CompileMs is how long a successful compile took; a failed one's
+	// time is TimeMs.
+	CompileMs int `json:"compile_ms"`
+}
+
+// Execute boots a microVM with the job attached as a second drive and
+// returns the result the guest reports.
+func (e *FirecrackerExecutor) Execute(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+	spec, ok := e.languages.Lookup(req.Language)
+	if !ok {
+		return &domain.ExecutionResult{
+			Status: domain.StatusInternalError,
+			Stderr: "unsupported language: " + string(req.Language),
+		}, nil
+	}
+	if err := spec.CheckFlags(req.CompilerFlags); err != nil {
+		return &domain.ExecutionResult{
+			Status:   domain.StatusCompilationError,
+			Stderr:   err.Error(),
+			ExitCode: 1,
+		}, nil
+	}
+	if err := spec.CheckEnv(req.Env); err != nil {
+		return &domain.ExecutionResult{
+			Status: domain.StatusInternalError,
+			Stderr: err.Error(),
+		}, nil
+	}
+	if req.NetworkPolicy != "" {
+		return networkUnsupported(req), nil
+	}
+	if req.StdinStream != nil {
+		return interactiveUnsupported(), nil
+	}
+	rootfs := filepath.Join(e.cfg.RootfsDir, string(spec.Name)+".ext4")
+	if _, err := os.Stat(rootfs); err != nil {
+		return nil, fmt.Errorf("rootfs for %s: %w", spec.Name, err)
+	}
+
+	setupStart := time.Now()
+	dir, err := os.MkdirTemp("", fmt.Sprintf("sentinel-fc-%s-*", req.JobID.String()))
+	if err != nil {
+		return nil, fmt.Errorf("create vm dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	outputLimit := maxOutputBytes
+	if req.MaxOutputBytes > 0 {
+		outputLimit = req.MaxOutputBytes
+	}
+	compileReq := compileRequest(req, spec)
+	jobDir := filepath.Join(dir, "job")
+	if err := writeGuestJob(jobDir, req, compileReq, spec, outputLimit); err != nil {
+		return nil, fmt.Errorf("write guest job: %w", err)
+	}
+	jobImage := filepath.Join(dir, "job.ext4")
+	if err := e.buildDrive(ctx, jobDir, jobImage); err != nil {
+		return nil, fmt.Errorf("build job drive: %w", err)
+	}
+
+	memoryKB := req.MemoryLimitKB
+	budget := e.cfg.BootTimeout + time.Duration(req.TimeLimitMs+2000)*time.Millisecond
+	if spec.IsCompiled() {
+		memoryKB = max(memoryKB, compileReq.MemoryLimitKB)
+		budget += time.Duration(compileReq.TimeLimitMs) * time.Millisecond
+	}
+	configPath := filepath.Join(dir, "vm.json")
+	if err := writeVMConfig(configPath, e.cfg, rootfs, jobImage, memoryKB); err != nil {
+		return nil, fmt.Errorf("write vm config: %w", err)
+	}
+
+	return e.runVM(ctx, req, configPath, budget, outputLimit, setupStart)
+}
+
+// runVM boots the microVM and reads its result. The work directory setup
+// timing covers preparing the drives, from setupStart, and the boot.
+func (e *FirecrackerExecutor) runVM(
+	ctx context.Context,
+	req *domain.ExecutionRequest,
+	configPath string,
+	budget time.Duration,
+	outputLimit int,
+	setupStart time.Time,
+) (*domain.ExecutionResult, error) {
+	runCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, e.cfg.FirecrackerPath,
+		"--no-api", "--config-file", configPath, "--id", req.JobID.String())
+	console, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("console pipe: %w", err)
+	}
+	var vmmLog limitedBuffer
+	vmmLog.limit = 16 * 1024
+	cmd.Stderr = &vmmLog
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		metrics.FirecrackerBootFailures.Inc()
+		return nil, fmt.Errorf("start firecracker: %w", err)
+	}
+
+	var (
+		booted  bool
+		readyAt time.Time
+		setup   time.Duration
+		result  *fcResult
+	)
+	scanner := bufio.NewScanner(console)
+	// The result line carries both outputs in base64.
+	scanner.Buffer(make([]byte, 64*1024), 3*outputLimit+64*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == fcReadyMarker && !booted:
+			booted = true
+			readyAt = time.Now()
+			setup = readyAt.Sub(setupStart)
+			boot := readyAt.Sub(start)
+			metrics.FirecrackerBootSeconds.WithLabelValues(string(req.Language)).Observe(boot.Seconds())
+			e.logger.Debug("microVM booted", zap.String("job_id", req.JobID.String()), zap.Duration("boot", boot))
+		case strings.HasPrefix(line, fcResultMarker) && result == nil:
+			var r fcResult
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, fcResultMarker)), &r); err != nil {
+				e.logger.Warn("Malformed microVM result", zap.String("job_id", req.JobID.String()), zap.Error(err))
+				continue
+			}
+			result = &r
+		}
+	}
+	scanErr := scanner.Err()
+	waitErr := cmd.Wait()
+
+	switch {
+	case result != nil:
+		res := result.toExecutionResult()
+		res.Timings = result.timings(setup)
+		if res.Status != domain.StatusCompilationError {
+			// The output only leaves the microVM once the run is over.
+			checkOutput(req, res)
+		}
+		return res, nil
+	case runCtx.Err() == context.DeadlineExceeded && booted:
+		// Without a result the compile cannot be told from the run.
+		return &domain.ExecutionResult{
+			Status:     domain.StatusTimeout,
+			ExitCode:   -1,
+			TimeUsedMs: int(time.Since(start).Milliseconds()),
+			Timings: domain.Timings{
+				WorkdirSetupMs: int(setup.Milliseconds()),
+				RunMs:          int(time.Since(readyAt).Milliseconds()),
+			},
+		}, nil
+	case !booted:
+		metrics.FirecrackerBootFailures.Inc()
+		return nil, fmt.Errorf("microVM did not boot (%v): %s", firstErr(waitErr, runCtx.Err()), vmmLog.String())
+	case scanErr != nil:
+		return nil, fmt.Errorf("read microVM console: %w", scanErr)
+	default:
+		return nil, fmt.Errorf("microVM exited without a result (%v): %s", waitErr, vmmLog.String())
+	}
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fcResult) toExecutionResult() *domain.ExecutionResult {
+	res := &domain.ExecutionResult{
+		Stdout:          truncateOutput(string(r.Stdout), r.StdoutTruncated),
+		Stderr:          string(r.Stderr),
+		ExitCode:        r.ExitCode,
+		TimeUsedMs:      r.TimeMs,
+		MemoryUsedKB:    r.MemoryKB,
+		CPUTimeUsedMs:   r.CPUTimeMs,
+		DiskUsedKB:      r.DiskKB,
+		StdoutTruncated: r.StdoutTruncated,
+	}
+	switch {
+	case r.Phase == "compile":
+		res.Status = domain.StatusCompilationError
+	case r.TimedOut:
+		res.Status = domain.StatusTimeout
+		res.ExitCode = -1
+	case r.OOMKilled:
+		res.Status = domain.StatusMemoryLimitExceeded
+	case r.ExitCode != 0:
+		res.Status = domain.StatusRuntimeError
+	default:
+		res.Status = domain.StatusSuccess
+	}
+	return res
+}
+
+// timings splits the guest's time between compile and run, after setup.
+func (r *fcResult) timings(setup time.Duration) domain.Timings {
+	t := domain.Timings{WorkdirSetupMs: int(setup.Milliseconds()), CompileMs: r.CompileMs}
+	if r.Phase == "compile" {
+		t.CompileMs = r.TimeMs
+	} else {
+		t.RunMs = r.TimeMs
+	}
+	return t
+}
+
+// writeGuestJob lays out the job drive read by sentinel-init: the work dir
+// contents, one shell script per phase and the phase limits.
+func writeGuestJob(dir string, req, compileReq *domain.ExecutionRequest, spec *language.Spec, outputLimit int) error {
+	workDir := filepath.Join(dir, "work")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(workDir, spec.SourceFile), []byte(req.SourceCode), 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "stdin.txt"), []byte(req.Stdin), 0o644); err != nil {
+		return err
+	}
+
+	if spec.IsCompiled() {
+		script := phaseScript(spec.EnvAt(language.SandboxWorkDir), nil, spec.CompileArgs(req.CompilerFlags))
+		if err := os.WriteFile(filepath.Join(dir, "compile.sh"), []byte(script), 0o755); err != nil {
+			return err
+		}
+	}
+	script := phaseScript(spec.EnvAt(language.SandboxWorkDir), req.Env, append(spec.RunArgs(), req.Args...))
+	if err := os.WriteFile(filepath.Join(dir, "run.sh"), []byte(script), 0o755); err != nil {
+		return err
+	}
+
+	limits := fmt.Sprintf("COMPILE_TIME_LIMIT_MS=%d\nCOMPILE_CPU_TIME_LIMIT_MS=%d\nCOMPILE_MEMORY_LIMIT_KB=%d\n"+
+		"TIME_LIMIT_MS=%d\nCPU_TIME_LIMIT_MS=%d\nMEMORY_LIMIT_KB=%d\nPIDS_LIMIT=%d\nMAX_OUTPUT_BYTES=%d\n",
+		compileReq.TimeLimitMs, compileReq.CPULimitMs(), compileReq.MemoryLimitKB,
+		req.TimeLimitMs, req.CPULimitMs(), req.MemoryLimitKB, req.PidsLimit, outputLimit)
+	return os.WriteFile(filepath.Join(dir, "limits"), []byte(limits), 0o644)
+}
+
+// phaseScript exports the environment, later maps winning, and execs argv.
+func phaseScript(specEnv, reqEnv map[string]string, argv []string) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	for _, env := range []map[string]string{specEnv, reqEnv} {
+		for _, name := range language.EnvNames(env) {
+			fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(env[name]))
+		}
+	}
+	b.WriteString("exec")
+	for _, arg := range argv {
+		b.WriteString(" " + shellQuote(arg))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// buildDrive packs dir into an ext4 image sized to its contents.
+func (e *FirecrackerExecutor) buildDrive(ctx context.Context, dir, image string) error {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sizeMiB := max(16, size>>20+8)
+
+	out, err := exec.CommandContext(ctx, e.cfg.MkfsPath,
+		"-q", "-F", "-d", dir, image, strconv.FormatInt(sizeMiB, 10)+"M").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", e.cfg.MkfsPath, err, out)
+	}
+	return nil
+}
+
+type fcVMConfig struct {
+	BootSource struct {
+		KernelImagePath string `json:"kernel_image_path"`
+		BootArgs        string `json:"boot_args"`
+	} `json:"boot-source"`
+	Drives        []fcDrive `json:"drives"`
+	MachineConfig struct {
+		VCPUCount  int `json:"vcpu_count"`
+		MemSizeMiB int `json:"mem_size_mib"`
+	} `json:"machine-config"`
+}
+
+type fcDrive struct {
+	DriveID      string `json:"drive_id"`
+	PathOnHost   string `json:"path_on_host"`
+	IsRootDevice bool   `json:"is_root_device"`
+	IsReadOnly   bool   `json:"is_read_only"`
+}
+
+// writeVMConfig writes the --config-file for a VM with the language rootfs
+// as /dev/vda and the job drive as /dev/vdb, both read-only.
+func writeVMConfig(path string, cfg FirecrackerConfig, rootfs, jobImage string, memoryKB int) error {
+	var c fcVMConfig
+	c.BootSource.KernelImagePath = cfg.KernelPath
+	c.BootSource.BootArgs = fcBootArgs
+	c.Drives = []fcDrive{
+		{DriveID: "rootfs", PathOnHost: rootfs, IsRootDevice: true, IsReadOnly: true},
+		{DriveID: "job", PathOnHost: jobImage, IsReadOnly: true},
+	}
+	c.MachineConfig.VCPUCount = cfg.VCPUs
+	c.MachineConfig.MemSizeMiB = (memoryKB+1023)/1024 + fcGuestOverheadMiB
+
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	return nil
+}
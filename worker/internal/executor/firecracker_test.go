@@ -0,0 +1,154 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// fakeFirecracker sets up a rootfs dir, an mkfs stand-in that copies the job
+// dir, and a firecracker stand-in running script after saving its config.
+func fakeFirecracker(t *testing.T, script string) (FirecrackerConfig, string) {
+	t.Helper()
+	dir := t.TempDir()
+	rootfsDir := filepath.Join(dir, "rootfs")
+	if err := os.Mkdir(rootfsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootfsDir, "python.ext4"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mkfs := filepath.Join(dir, "mkfs.ext4")
+	if err := os.WriteFile(mkfs, []byte("#!/bin/sh\ncp -r \"$4\" \"$5\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	fc := filepath.Join(dir, "firecracker")
+	body := "#!/bin/sh\ncp \"$3\" " + filepath.Join(dir, "vm.json") + "\n" + script
+	if err := os.WriteFile(fc, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return FirecrackerConfig{
+		FirecrackerPath: fc,
+		MkfsPath:        mkfs,
+		KernelPath:      "/boot/vmlinux",
+		RootfsDir:       rootfsDir,
+		BootTimeout:     5 * time.Second,
+	}, dir
+}
+
+func TestFirecracker_ReportsGuestResult(t *testing.T) {
+	cfg, dir := fakeFirecracker(t, `echo "[    0.1] booting"
+echo SENTINEL_READY
+echo 'SENTINEL_RESULT {"phase":"run","exit_code":0,"time_ms":12,"memory_kb":2048,"stdout":"aGVsbG8K","stderr":""}'
+`)
+	exe := NewFirecrackerExecutor(cfg, testLanguages(t), zap.NewNop())
+
+	res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print('hello')",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if res.Status != domain.StatusSuccess || res.Stdout != "hello\n" || res.TimeUsedMs != 12 || res.MemoryUsedKB != 2048 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if res.Timings.RunMs != 12 || res.Timings.CompileMs != 0 {
+		t.Errorf("timings = %+v, want the guest's run time", res.Timings)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "vm.json"))
+	if err != nil {
+		t.Fatalf("read vm config: %v", err)
+	}
+	var vm fcVMConfig
+	if err := json.Unmarshal(data, &vm); err != nil {
+		t.Fatalf("parse vm config: %v", err)
+	}
+	if vm.BootSource.KernelImagePath != "/boot/vmlinux" {
+		t.Errorf("kernel = %q", vm.BootSource.KernelImagePath)
+	}
+	if vm.MachineConfig.VCPUCount != 1 || vm.MachineConfig.MemSizeMiB != 64+fcGuestOverheadMiB {
+		t.Errorf("machine config = %+v", vm.MachineConfig)
+	}
+	if len(vm.Drives) != 2 || vm.Drives[0].PathOnHost != filepath.Join(cfg.RootfsDir, "python.ext4") ||
+		!vm.Drives[0].IsRootDevice || !vm.Drives[0].IsReadOnly || !vm.Drives[1].IsReadOnly {
+		t.Errorf("drives = %+v", vm.Drives)
+	}
+}
+
+func TestFirecracker_BootFailure(t *testing.T) {
+	cfg, _ := fakeFirecracker(t, "echo 'cannot open /dev/kvm' >&2\nexit 1\n")
+	exe := NewFirecrackerExecutor(cfg, testLanguages(t), zap.NewNop())
+
+	_, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	})
+	if err == nil || !strings.Contains(err.Error(), "did not boot") || !strings.Contains(err.Error(), "/dev/kvm") {
+		t.Errorf("expected boot error with the VMM log, got %v", err)
+	}
+}
+
+func TestFirecracker_ResultStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result fcResult
+		want   domain.ExecutionStatus
+	}{
+		{"success", fcResult{Phase: "run"}, domain.StatusSuccess},
+		{"compile error", fcResult{Phase: "compile", ExitCode: 1}, domain.StatusCompilationError},
+		{"timeout", fcResult{Phase: "run", ExitCode: 137, TimedOut: true}, domain.StatusTimeout},
+		{"oom", fcResult{Phase: "run", ExitCode: 137, OOMKilled: true}, domain.StatusMemoryLimitExceeded},
+		{"runtime error", fcResult{Phase: "run", ExitCode: 1}, domain.StatusRuntimeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.toExecutionResult().Status; got != tt.want {
+				t.Errorf("status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirecracker_ResultTimings(t *testing.T) {
+	run := fcResult{Phase: "run", TimeMs: 12, CompileMs: 30}
+	if got, want := run.timings(5*time.Millisecond), (domain.Timings{WorkdirSetupMs: 5, CompileMs: 30, RunMs: 12}); got != want {
+		t.Errorf("run timings = %+v, want %+v", got, want)
+	}
+	compile := fcResult{Phase: "compile", ExitCode: 1, TimeMs: 40}
+	if got, want := compile.timings(5*time.Millisecond), (domain.Timings{WorkdirSetupMs: 5, CompileMs: 40}); got != want {
+		t.Errorf("compile timings = %+v, want %+v", got, want)
+	}
+}
+
+func TestPhaseScript_QuotesArgsAndEnv(t *testing.T) {
+	got := phaseScript(
+		map[string]string{"LANG": "C"},
+		map[string]string{"LANG": "it's", "DEBUG": "1"},
+		[]string{"/usr/bin/python3", "/tmp/work/main.py", "a b"},
+	)
+	want := "#!/bin/sh\n" +
+		"export LANG='C'\n" +
+		"export DEBUG='1'\n" +
+		"export LANG='it'\\''s'\n" +
+		"exec '/usr/bin/python3' '/tmp/work/main.py' 'a b'\n"
+	if got != want {
+		t.Errorf("phaseScript =\n%s\nwant\n%s", got, want)
+	}
+}
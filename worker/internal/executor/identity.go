@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+)
+
+const (
+	// sandboxHostname is the hostname programs see inside the sandbox.
+	sandboxHostname = "sandbox"
+
+	// sandboxUID is the user and group ID programs run as inside the
+	// sandbox.
+	sandboxUID = 1000
+
+	// Randomized IDs fall in [randomIDMin, randomIDMax): clear of system
+	// accounts below and of nobody (65534) above.
+	randomIDMin = 10000
+	randomIDMax = 60000
+)
+
+// sandboxIdentity is what a program can tell about where it runs: the
+// hostname, its user and group IDs, and the path of its work directory.
+type sandboxIdentity struct {
+	Hostname string
+	UID      int
+	GID      int
+	WorkDir  string
+}
+
+// fixedIdentity is the identity every execution has unless randomized.
+var fixedIdentity = sandboxIdentity{
+	Hostname: sandboxHostname,
+	UID:      sandboxUID,
+	GID:      sandboxUID,
+	WorkDir:  language.SandboxWorkDir,
+}
+
+// randomIdentity returns an identity drawn afresh, so programs cannot
+// recognise the judge by it or find each other through it.
+func randomIdentity() (sandboxIdentity, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return sandboxIdentity{}, fmt.Errorf("randomize sandbox identity: %w", err)
+	}
+	uid, err := randomID()
+	if err != nil {
+		return sandboxIdentity{}, err
+	}
+	gid, err := randomID()
+	if err != nil {
+		return sandboxIdentity{}, err
+	}
+	return sandboxIdentity{
+		Hostname: hex.EncodeToString(b[:6]),
+		UID:      uid,
+		GID:      gid,
+		WorkDir:  "/tmp/" + hex.EncodeToString(b[6:]),
+	}, nil
+}
+
+func randomID() (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(randomIDMax-randomIDMin))
+	if err != nil {
+		return 0, fmt.Errorf("randomize sandbox identity: %w", err)
+	}
+	return randomIDMin + int(n.Int64()), nil
+}
+
+// SetRandomIdentity gives every execution its own hostname, user and group
+// IDs and work directory path inside the sandbox instead of the fixed ones.
+// Commands and env values must refer to the work directory through the
+// language registry's placeholders.
+func (e *SandboxExecutor) SetRandomIdentity(random bool) {
+	e.randomIdentity = random
+}
+
+// identity returns the identity of a new execution.
+func (e *SandboxExecutor) identity() (sandboxIdentity, error) {
+	if !e.randomIdentity {
+		return fixedIdentity, nil
+	}
+	return randomIdentity()
+}
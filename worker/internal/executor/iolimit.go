@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// IOLimitConfig is the disk throughput each sandbox run gets. Zero leaves a
+// direction unlimited.
+type IOLimitConfig struct {
+	ReadMBps  int
+	WriteMBps int
+	ReadIOPS  int
+	WriteIOPS int
+}
+
+// IOLimits caps the disk throughput of every sandbox run on the block
+// device holding the work directories, so a program writing as fast as it
+// can does not stall other runs and the host behind its I/O. Limits are
+// applied through the run's job cgroup's io.max.
+type IOLimits struct {
+	device string
+	max    string
+}
+
+// NewIOLimits limits I/O to the block device path is on. Partitions are
+// resolved to their disk, which is what io.max accepts.
+func NewIOLimits(path string, cfg IOLimitConfig) (*IOLimits, error) {
+	device, err := blockDevice(path)
+	if err != nil {
+		return nil, err
+	}
+	return newIOLimits(device, cfg), nil
+}
+
+func newIOLimits(device string, cfg IOLimitConfig) *IOLimits {
+	limit := func(v, scale int) string {
+		if v <= 0 {
+			return "max"
+		}
+		return strconv.Itoa(v * scale)
+	}
+	return &IOLimits{
+		device: device,
+		max: fmt.Sprintf("%s rbps=%s wbps=%s riops=%s wiops=%s", device,
+			limit(cfg.ReadMBps, 1<<20), limit(cfg.WriteMBps, 1<<20),
+			limit(cfg.ReadIOPS, 1), limit(cfg.WriteIOPS, 1)),
+	}
+}
+
+// Device is the MAJ:MIN number of the limited disk.
+func (l *IOLimits) Device() string {
+	return l.device
+}
+
+// blockDevice returns the MAJ:MIN number of the disk path is stored on.
+func blockDevice(path string) (string, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return "", fmt.Errorf("stat %s: %w", path, err)
+	}
+	device := fmt.Sprintf("%d:%d", unix.Major(st.Dev), unix.Minor(st.Dev))
+	sysDir := filepath.Join("/sys/dev/block", device)
+	if _, err := os.Stat(sysDir); err != nil {
+		// tmpfs, overlayfs and the like have no block device to limit.
+		return "", fmt.Errorf("%s is not on a block device", path)
+	}
+	if _, err := os.Stat(filepath.Join(sysDir, "partition")); err == nil {
+		data, err := os.ReadFile(filepath.Join(sysDir, "..", "dev"))
+		if err != nil {
+			return "", fmt.Errorf("find disk of partition %s: %w", device, err)
+		}
+		device = strings.TrimSpace(string(data))
+	}
+	return device, nil
+}
+
+// limitIO applies l to the run. The job cgroup's parent must have the io
+// controller enabled, see JobCgroups.EnableIO.
+func (g *jobCgroup) limitIO(l *IOLimits) error {
+	if err := os.WriteFile(filepath.Join(g.dir, "io.max"), []byte(l.max), 0o644); err != nil {
+		return fmt.Errorf("limit job cgroup I/O: %w", err)
+	}
+	return nil
+}
+
+// EnableIO makes job cgroups' I/O limitable. Like EnableCPUSet it is
+// separate from the controllers NewJobCgroups enables because not every host
+// delegates io.
+func (c *JobCgroups) EnableIO() error {
+	if err := os.WriteFile(filepath.Join(c.root, "cgroup.subtree_control"), []byte("+io"), 0o644); err != nil {
+		return fmt.Errorf("enable io controller in %s: %w", c.root, err)
+	}
+	return nil
+}
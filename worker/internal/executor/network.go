@@ -0,0 +1,336 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// AllowedEndpoint is one host and TCP port runs with the allowlist network
+// policy may connect to.
+type AllowedEndpoint struct {
+	Host string
+	Port int
+}
+
+// ParseNetworkAllowlist parses comma-separated host:port entries, such as
+// "pypi.org:443,10.0.0.5:8080". Hosts may be names or IPv4 addresses.
+func ParseNetworkAllowlist(s string) ([]AllowedEndpoint, error) {
+	var endpoints []AllowedEndpoint
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, portStr, err := net.SplitHostPort(entry)
+		if err != nil {
+			return nil, fmt.Errorf("network allowlist entry %q: %w", entry, err)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("network allowlist entry %q: invalid port", entry)
+		}
+		if host == "" || strings.ContainsAny(host, " \t\"") {
+			return nil, fmt.Errorf("network allowlist entry %q: invalid host", entry)
+		}
+		endpoints = append(endpoints, AllowedEndpoint{Host: host, Port: port})
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("network allowlist is empty")
+	}
+	return endpoints, nil
+}
+
+// NetworkConfig locates the tools JobNetworks drives and what it allows.
+type NetworkConfig struct {
+	IPPath  string
+	NftPath string
+	// Subnet is the IPv4 range the per-run veth pairs are numbered from,
+	// one /30 each; it must not be routed anywhere else.
+	Subnet    string
+	Allowlist []AllowedEndpoint
+}
+
+// JobNetworks gives every run with the allowlist network policy its own
+// network namespace, joined to the worker's by a veth pair and masqueraded
+// out of it. An nftables ruleset inside the namespace, which the sandboxed
+// program has no privilege to change, drops all traffic except TCP to the
+// allowlist. Names are resolved by the worker when the run starts and
+// written to the sandbox's /etc/hosts; the namespace has no DNS.
+type JobNetworks struct {
+	ipPath    string
+	nftPath   string
+	subnet    netip.Prefix
+	allowlist []AllowedEndpoint
+
+	mu    sync.Mutex
+	slots map[int]bool
+}
+
+// NewJobNetworks enables IPv4 forwarding and installs the masquerading
+// rule for the subnet. The worker needs CAP_NET_ADMIN.
+func NewJobNetworks(cfg NetworkConfig) (*JobNetworks, error) {
+	if cfg.IPPath == "" {
+		cfg.IPPath = "ip"
+	}
+	if cfg.NftPath == "" {
+		cfg.NftPath = "nft"
+	}
+	subnet, err := netip.ParsePrefix(cfg.Subnet)
+	if err != nil || !subnet.Addr().Is4() || subnet.Bits() > 29 {
+		return nil, fmt.Errorf("network subnet %q must be an IPv4 prefix of at most /29", cfg.Subnet)
+	}
+	if len(cfg.Allowlist) == 0 {
+		return nil, fmt.Errorf("network allowlist is empty")
+	}
+	n := &JobNetworks{
+		ipPath:    cfg.IPPath,
+		nftPath:   cfg.NftPath,
+		subnet:    subnet.Masked(),
+		allowlist: cfg.Allowlist,
+		slots:     make(map[int]bool),
+	}
+	if err := os.WriteFile("/proc/sys/net/ipv4/ip_forward", []byte("1"), 0o644); err != nil {
+		return nil, fmt.Errorf("enable ip forwarding: %w", err)
+	}
+	if err := n.run(context.Background(), hostRuleset(n.subnet), n.nftPath, "-f", "-"); err != nil {
+		return nil, fmt.Errorf("install masquerading: %w", err)
+	}
+	return n, nil
+}
+
+// networkUnsupported is the result of a run asking for a network policy
+// the executor does not offer.
+func networkUnsupported(req *domain.ExecutionRequest) *domain.ExecutionResult {
+	return &domain.ExecutionResult{
+		Status: domain.StatusInternalError,
+		Stderr: fmt.Sprintf("network policy %q is not available on this worker", req.NetworkPolicy),
+	}
+}
+
+// hostRuleset masquerades traffic from the runs' namespaces. Replacing the
+// table keeps a restarted worker from stacking rules.
+func hostRuleset(subnet netip.Prefix) string {
+	return fmt.Sprintf(`table ip sentinel_nat
+delete table ip sentinel_nat
+table ip sentinel_nat {
+	chain postrouting {
+		type nat hook postrouting priority srcnat; policy accept;
+		ip saddr %s masquerade
+	}
+}
+`, subnet)
+}
+
+// resolvedEndpoint is an allowlist entry with the addresses it resolved to.
+type resolvedEndpoint struct {
+	AllowedEndpoint
+	addrs []netip.Addr
+}
+
+// jailRuleset allows TCP to each resolved endpoint, replies to it and
+// loopback, and drops everything else leaving or entering the namespace,
+// counting what it drops leaving in the blocked counter.
+func jailRuleset(endpoints []resolvedEndpoint) string {
+	var b strings.Builder
+	b.WriteString(`table inet sentinel {
+	counter blocked {
+	}
+	chain output {
+		type filter hook output priority filter; policy drop;
+		oif "lo" accept
+		ct state established,related accept
+`)
+	for _, ep := range endpoints {
+		for _, addr := range ep.addrs {
+			fmt.Fprintf(&b, "\t\tip daddr %s tcp dport %d accept\n", addr, ep.Port)
+		}
+	}
+	// Whatever gets this far is dropped by the policy.
+	b.WriteString(`		counter name "blocked"
+	}
+	chain input {
+		type filter hook input priority filter; policy drop;
+		iif "lo" accept
+		ct state established,related accept
+	}
+}
+`)
+	return b.String()
+}
+
+// hostsFile maps the allowlist's names to their addresses.
+func hostsFile(endpoints []resolvedEndpoint) string {
+	var b strings.Builder
+	b.WriteString("127.0.0.1\tlocalhost\n")
+	for _, ep := range endpoints {
+		if _, err := netip.ParseAddr(ep.Host); err == nil {
+			continue
+		}
+		for _, addr := range ep.addrs {
+			fmt.Fprintf(&b, "%s\t%s\n", addr, ep.Host)
+		}
+	}
+	return b.String()
+}
+
+// slotAddrs returns the worker-side and namespace-side addresses of the
+// /30 numbered slot in subnet, or false when the subnet has no such slot.
+func slotAddrs(subnet netip.Prefix, slot int) (host, jail netip.Addr, ok bool) {
+	if slot >= 1<<(30-subnet.Bits()) {
+		return netip.Addr{}, netip.Addr{}, false
+	}
+	base := subnet.Addr().As4()
+	v := uint32(base[0])<<24 | uint32(base[1])<<16 | uint32(base[2])<<8 | uint32(base[3])
+	v += uint32(slot)*4 + 1
+	host = netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+	return host, host.Next(), true
+}
+
+// jobNetwork is the network namespace of one run.
+type jobNetwork struct {
+	name  string
+	hosts string
+	slot  int
+}
+
+// create resolves the allowlist and sets up a namespace for one run.
+func (n *JobNetworks) create(ctx context.Context) (_ *jobNetwork, err error) {
+	endpoints, err := n.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := n.acquireSlot()
+	if err != nil {
+		return nil, err
+	}
+	hostAddr, jailAddr, _ := slotAddrs(n.subnet, slot)
+	jn := &jobNetwork{name: fmt.Sprintf("sentinel-%d", slot), slot: slot}
+	hostIf, jailIf := fmt.Sprintf("snh%d", slot), fmt.Sprintf("snj%d", slot)
+	defer func() {
+		if err != nil {
+			n.remove(jn)
+		}
+	}()
+
+	hosts, err := os.CreateTemp("", "sentinel-hosts-*")
+	if err != nil {
+		return nil, fmt.Errorf("create hosts file: %w", err)
+	}
+	jn.hosts = hosts.Name()
+	_, err = hosts.WriteString(hostsFile(endpoints))
+	if closeErr := hosts.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("write hosts file: %w", err)
+	}
+	if err := os.Chmod(jn.hosts, 0o644); err != nil {
+		return nil, fmt.Errorf("chmod hosts file: %w", err)
+	}
+
+	steps := [][]string{
+		{n.ipPath, "netns", "add", jn.name},
+		{n.ipPath, "link", "add", hostIf, "type", "veth", "peer", "name", jailIf, "netns", jn.name},
+		{n.ipPath, "addr", "add", hostAddr.String() + "/30", "dev", hostIf},
+		{n.ipPath, "link", "set", hostIf, "up"},
+		{n.ipPath, "-n", jn.name, "addr", "add", jailAddr.String() + "/30", "dev", jailIf},
+		{n.ipPath, "-n", jn.name, "link", "set", jailIf, "up"},
+		{n.ipPath, "-n", jn.name, "link", "set", "lo", "up"},
+		{n.ipPath, "-n", jn.name, "route", "add", "default", "via", hostAddr.String()},
+	}
+	for _, step := range steps {
+		if err := n.run(ctx, "", step...); err != nil {
+			return nil, fmt.Errorf("set up run network: %w", err)
+		}
+	}
+	if err := n.run(ctx, jailRuleset(endpoints), n.ipPath, "netns", "exec", jn.name, n.nftPath, "-f", "-"); err != nil {
+		return nil, fmt.Errorf("install allowlist: %w", err)
+	}
+	return jn, nil
+}
+
+// resolve looks up the allowlist's names. A name that does not resolve
+// fails the run rather than silently narrowing the allowlist.
+func (n *JobNetworks) resolve(ctx context.Context) ([]resolvedEndpoint, error) {
+	endpoints := make([]resolvedEndpoint, 0, len(n.allowlist))
+	for _, ep := range n.allowlist {
+		r := resolvedEndpoint{AllowedEndpoint: ep}
+		if addr, err := netip.ParseAddr(ep.Host); err == nil {
+			r.addrs = []netip.Addr{addr}
+		} else {
+			addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip4", ep.Host)
+			if err != nil {
+				return nil, fmt.Errorf("resolve %s: %w", ep.Host, err)
+			}
+			r.addrs = addrs
+		}
+		endpoints = append(endpoints, r)
+	}
+	return endpoints, nil
+}
+
+func (n *JobNetworks) acquireSlot() (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for slot := 0; ; slot++ {
+		if _, _, ok := slotAddrs(n.subnet, slot); !ok {
+			return 0, fmt.Errorf("network subnet %s is exhausted", n.subnet)
+		}
+		if !n.slots[slot] {
+			n.slots[slot] = true
+			return slot, nil
+		}
+	}
+}
+
+// remove deletes the namespace, which takes the veth pair with it, and
+// frees its slot.
+func (n *JobNetworks) remove(jn *jobNetwork) error {
+	err := n.run(context.Background(), "", n.ipPath, "netns", "del", jn.name)
+	if jn.hosts != "" {
+		os.Remove(jn.hosts)
+	}
+	n.mu.Lock()
+	delete(n.slots, jn.slot)
+	n.mu.Unlock()
+	return err
+}
+
+// blocked returns how many packets the run sent outside the allowlist.
+func (n *JobNetworks) blocked(jn *jobNetwork) (int, error) {
+	argv := []string{n.ipPath, "netns", "exec", jn.name, n.nftPath, "list", "counter", "inet", "sentinel", "blocked"}
+	out, err := exec.Command(argv[0], argv[1:]...).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w: %s", strings.Join(argv, " "), err, strings.TrimSpace(string(out)))
+	}
+	fields := strings.Fields(string(out))
+	for i, f := range fields[:max(len(fields)-1, 0)] {
+		if f == "packets" {
+			return strconv.Atoi(fields[i+1])
+		}
+	}
+	return 0, fmt.Errorf("no packet count in %q", strings.TrimSpace(string(out)))
+}
+
+// run runs a command with stdin, returning its output in the error.
+func (n *JobNetworks) run(ctx context.Context, stdin string, argv ...string) error {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", strings.Join(argv, " "), err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestParseNetworkAllowlist(t *testing.T) {
+	got, err := ParseNetworkAllowlist(" pypi.org:443, 10.0.0.5:8080 ,")
+	if err != nil {
+		t.Fatalf("ParseNetworkAllowlist: %v", err)
+	}
+	want := []AllowedEndpoint{{Host: "pypi.org", Port: 443}, {Host: "10.0.0.5", Port: 8080}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	for _, bad := range []string{"", "pypi.org", "pypi.org:0", "pypi.org:https", ":443"} {
+		if _, err := ParseNetworkAllowlist(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestJailRuleset(t *testing.T) {
+	endpoints := []resolvedEndpoint{
+		{AllowedEndpoint: AllowedEndpoint{Host: "pypi.org", Port: 443}, addrs: []netip.Addr{netip.MustParseAddr("151.101.0.223"), netip.MustParseAddr("151.101.64.223")}},
+		{AllowedEndpoint: AllowedEndpoint{Host: "10.0.0.5", Port: 8080}, addrs: []netip.Addr{netip.MustParseAddr("10.0.0.5")}},
+	}
+	rules := jailRuleset(endpoints)
+	for _, want := range []string{
+		"policy drop;",
+		`counter name "blocked"`,
+		"ip daddr 151.101.0.223 tcp dport 443 accept",
+		"ip daddr 151.101.64.223 tcp dport 443 accept",
+		"ip daddr 10.0.0.5 tcp dport 8080 accept",
+	} {
+		if !strings.Contains(rules, want) {
+			t.Errorf("expected %q in ruleset:\n%s", want, rules)
+		}
+	}
+	hosts := hostsFile(endpoints)
+	if !strings.Contains(hosts, "151.101.64.223\tpypi.org\n") || strings.Contains(hosts, "\t10.0.0.5") {
+		t.Errorf("unexpected hosts file:\n%s", hosts)
+	}
+}
+
+func TestSlotAddrs(t *testing.T) {
+	subnet := netip.MustParsePrefix("10.200.0.0/16")
+	host, jail, ok := slotAddrs(subnet, 64)
+	if !ok || host.String() != "10.200.1.1" || jail.String() != "10.200.1.2" {
+		t.Errorf("slot 64 = %s, %s, %v", host, jail, ok)
+	}
+	if _, _, ok := slotAddrs(subnet, 1<<14); ok {
+		t.Error("expected the slot past the subnet to be refused")
+	}
+}
@@ -0,0 +1,200 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+)
+
+// nsjailBaseTemplate defines the blocks every language template uses.
+const nsjailBaseTemplate = "base.tmpl"
+
+// nsjailConfig is everything one nsjail run is configured with beyond its
+// language's profile. It is rendered into a template-generated config or,
+// without templates, turned into command-line overrides of the static profile.
+type nsjailConfig struct {
+	Name     string
+	Hostname string
+	// WorkDir is the host directory mounted at SandboxDir, which is also
+	// the program's working directory.
+	WorkDir    string
+	SandboxDir string
+	// UID and GID are the IDs the program runs as, mapped to the worker's.
+	UID            int
+	GID            int
+	TimeLimitSec   int
+	MemoryMaxBytes int
+	CPULimitSec    int
+	// PidsMax is zero to keep the profile's process limit.
+	PidsMax int
+	// CgroupPath is the job cgroup; empty without per-job cgroups.
+	CgroupPath string
+	// SeccompPolicy is empty to keep the profile's policy.
+	SeccompPolicy string
+	// NetworkNamespace is the namespace nsjail runs in instead of creating
+	// one; empty without network access.
+	NetworkNamespace string
+	Tmpfs            []nsjailTmpfs
+	Mounts           []domain.Mount
+	// Env holds NAME=value entries and bare names copied from the worker's
+	// environment, in increasing precedence.
+	Env []string
+}
+
+type nsjailTmpfs struct {
+	Target    string
+	SizeBytes int
+}
+
+// flags returns the arguments applying c on top of the static profile.
+func (c *nsjailConfig) flags(profile string) []string {
+	// Extra tmpfs mounts go before the work dir's bind mount so one at /tmp
+	// cannot hide it.
+	args := []string{"--config", profile}
+	for _, m := range c.Tmpfs {
+		args = append(args, "--mount", fmt.Sprintf("none:%s:tmpfs:size=%d", m.Target, m.SizeBytes))
+	}
+	args = append(args,
+		"--bindmount", c.WorkDir+":"+c.SandboxDir,
+		"--cwd", c.SandboxDir,
+		"--hostname", c.Hostname,
+		"--user", strconv.Itoa(c.UID),
+		"--group", strconv.Itoa(c.GID),
+		"--time_limit", strconv.Itoa(c.TimeLimitSec),
+		"--cgroup_mem_max", strconv.Itoa(c.MemoryMaxBytes),
+		// RLIMIT_CPU counts whole seconds; the job cgroup's cpu.stat judges
+		// the limit to the millisecond.
+		"--rlimit_cpu", strconv.Itoa(c.CPULimitSec),
+	)
+	if c.PidsMax > 0 {
+		args = append(args, "--cgroup_pids_max", strconv.Itoa(c.PidsMax))
+	}
+	if c.SeccompPolicy != "" {
+		args = append(args, "--seccomp_policy", c.SeccompPolicy)
+	}
+	for _, m := range c.Mounts {
+		args = append(args, "--bindmount_ro", m.Source+":"+m.Target)
+	}
+	if c.CgroupPath != "" {
+		args = append(args, "--use_cgroupv2", "--cgroupv2_mount", c.CgroupPath)
+	}
+	if c.NetworkNamespace != "" {
+		args = append(args, "--disable_clone_newnet")
+	}
+	// Passed after --config so they take precedence over the profile's
+	// envar entries.
+	for _, env := range c.Env {
+		args = append(args, "--env", env)
+	}
+	return args
+}
+
+// parseNsjailTemplate parses the template of the profile named profile,
+// <profile>.tmpl in dir, together with the shared blocks, and checks that it
+// renders.
+func parseNsjailTemplate(dir, profile string) (*template.Template, error) {
+	name := profile + ".tmpl"
+	tmpl, err := template.New(name).
+		Funcs(template.FuncMap{"quote": strconv.Quote}).
+		ParseFiles(filepath.Join(dir, nsjailBaseTemplate), filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	sample := &nsjailConfig{
+		Name:       "sentinel-check",
+		Hostname:   fixedIdentity.Hostname,
+		WorkDir:    "/tmp/work",
+		SandboxDir: fixedIdentity.WorkDir,
+		UID:        fixedIdentity.UID,
+		GID:        fixedIdentity.GID,
+		Tmpfs:      []nsjailTmpfs{{Target: "/check", SizeBytes: 1 << 20}},
+		Mounts:     []domain.Mount{{Source: "/check", Target: "/check"}},
+		Env:        []string{"CHECK=1"},
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// SetConfigTemplates makes every run use an nsjail config rendered from its
+// language's template in dir instead of the static profile plus command-line
+// overrides. It fails, naming each language, unless every registry language
+// has a template that renders. Registered runtimes without a template keep
+// their static profile.
+func (e *SandboxExecutor) SetConfigTemplates(dir string) error {
+	templates := make(map[string]*template.Template)
+	var errs []error
+	for _, spec := range e.languages.All() {
+		if _, ok := templates[spec.NsjailConfig]; ok {
+			continue
+		}
+		tmpl, err := parseNsjailTemplate(dir, spec.NsjailConfig)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("nsjail template for %s: %w", spec.Name, err))
+			continue
+		}
+		templates[spec.NsjailConfig] = tmpl
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	e.templateDir = dir
+	e.templates = templates
+	return nil
+}
+
+// configTemplate returns spec's template, or nil to use its static profile.
+func (e *SandboxExecutor) configTemplate(spec *language.Spec) (*template.Template, error) {
+	if e.templateDir == "" {
+		return nil, nil
+	}
+	if tmpl, ok := e.templates[spec.NsjailConfig]; ok {
+		return tmpl, nil
+	}
+	// A runtime registered through the API; read on every run like the
+	// runtime itself.
+	tmpl, err := parseNsjailTemplate(e.templateDir, spec.NsjailConfig)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nsjail template for %s: %w", spec.Name, err)
+	}
+	return tmpl, nil
+}
+
+// nsjailArgs returns the nsjail arguments applying cfg to spec and the
+// function that removes anything they reference.
+func (e *SandboxExecutor) nsjailArgs(spec *language.Spec, cfg *nsjailConfig) ([]string, func(), error) {
+	tmpl, err := e.configTemplate(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tmpl == nil {
+		return cfg.flags(filepath.Join(e.configDir, spec.NsjailConfig)), func() {}, nil
+	}
+	// Written outside the work dir, which the program can read.
+	f, err := os.CreateTemp("", "sentinel-nsjail-*.cfg")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create nsjail config: %w", err)
+	}
+	remove := func() { os.Remove(f.Name()) }
+	err = tmpl.Execute(f, cfg)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		remove()
+		return nil, nil, fmt.Errorf("render nsjail config: %w", err)
+	}
+	return []string{"--config", f.Name()}, remove, nil
+}
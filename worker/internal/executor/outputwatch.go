@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// maxWatchedLine caps the part of a line matched against kill patterns; the
+// rest of a longer line is not looked at.
+const maxWatchedLine = 4096
+
+// outputWatch matches a run's output against the request's kill patterns
+// line by line as it is written, and calls kill once when one matches.
+type outputWatch struct {
+	patterns []domain.KillPattern
+	kill     func()
+
+	mu        sync.Mutex
+	violation string
+}
+
+// newOutputWatch returns a watch for patterns, or nil when there are none.
+func newOutputWatch(patterns []domain.KillPattern, kill func()) *outputWatch {
+	if len(patterns) == 0 {
+		return nil
+	}
+	return &outputWatch{patterns: patterns, kill: kill}
+}
+
+// Violation returns what stopped the run, or "" if nothing did.
+func (w *outputWatch) Violation() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.violation
+}
+
+// stream returns the writer for one output stream, named in the violation.
+// Lines skip reports reject, such as nsjail's log lines, are not matched.
+// Close the writer after the run to match a last line without a newline.
+func (w *outputWatch) stream(name string, skip func(string) bool) io.WriteCloser {
+	return &lineWatch{watch: w, name: name, skip: skip}
+}
+
+func (w *outputWatch) report(violation string) {
+	w.mu.Lock()
+	first := w.violation == ""
+	if first {
+		w.violation = violation
+	}
+	w.mu.Unlock()
+	if first && w.kill != nil {
+		w.kill()
+	}
+}
+
+// lineWatch splits one stream into lines. A line the same as the one before
+// it is not matched again: it counts towards the repeats of the patterns the
+// first one matched.
+type lineWatch struct {
+	watch *outputWatch
+	name  string
+	skip  func(string) bool
+
+	line     []byte // the first maxWatchedLine bytes of the current line
+	lines    int
+	last     []byte
+	repeated int
+	matched  []int // patterns the last line matched
+}
+
+func (l *lineWatch) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i]
+		}
+		if room := maxWatchedLine - len(l.line); len(chunk) > room {
+			chunk = chunk[:room]
+		}
+		l.line = append(l.line, chunk...)
+		if i < 0 {
+			break
+		}
+		l.endLine()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Close matches the last line if it had no newline.
+func (l *lineWatch) Close() error {
+	if len(l.line) > 0 {
+		l.endLine()
+	}
+	return nil
+}
+
+func (l *lineWatch) endLine() {
+	line := l.line
+	l.line = l.line[:0]
+	if l.skip != nil && l.skip(string(line)) {
+		return
+	}
+	l.lines++
+
+	if l.lines > 1 && bytes.Equal(line, l.last) {
+		l.repeated++
+	} else {
+		l.last = append(l.last[:0], line...)
+		l.repeated = 1
+		l.matched = l.matched[:0]
+		for i, p := range l.watch.patterns {
+			if p.Pattern.Match(line) {
+				l.matched = append(l.matched, i)
+			}
+		}
+	}
+	for _, i := range l.matched {
+		p := l.watch.patterns[i]
+		if l.repeated < max(p.Repeats, 1) {
+			continue
+		}
+		msg := p.Message
+		if msg == "" {
+			msg = fmt.Sprintf("output matches kill pattern %q", p.Pattern.String())
+		}
+		l.watch.report(fmt.Sprintf("kill pattern %d, %s line %d: %s", i+1, l.name, l.lines, msg))
+		return
+	}
+}
+
+// checkOutput matches a finished run's output against the request's kill
+// patterns, for executors that only see the output once the run is over,
+// and gives a matching run StatusOutputViolation.
+func checkOutput(req *domain.ExecutionRequest, res *domain.ExecutionResult) {
+	w := newOutputWatch(req.KillPatterns, nil)
+	if w == nil {
+		return
+	}
+	for _, s := range []struct{ name, text string }{{"stdout", res.Stdout}, {"stderr", res.Stderr}} {
+		lw := w.stream(s.name, nil)
+		io.WriteString(lw, s.text)
+		lw.Close()
+	}
+	if v := w.Violation(); v != "" {
+		res.Status = domain.StatusOutputViolation
+		res.Violation = v
+		res.Flag(domain.SecurityAbuseFlag, v)
+	}
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// WorkDirQuota caps how much a run can write to its work directory by
+// mounting a size-limited tmpfs over the directory before the sandbox binds
+// it at /tmp/work. Writes past the limit fail with ENOSPC inside the sandbox
+// instead of filling the worker's disk.
+type WorkDirQuota struct {
+	sizeKB int
+}
+
+// NewWorkDirQuota checks that the worker may mount tmpfs (it needs
+// CAP_SYS_ADMIN) by mounting one under the system temp dir.
+func NewWorkDirQuota(sizeMB int) (*WorkDirQuota, error) {
+	if sizeMB <= 0 {
+		return nil, fmt.Errorf("work dir quota must be positive, got %d MB", sizeMB)
+	}
+	q := &WorkDirQuota{sizeKB: sizeMB * 1024}
+	dir, err := os.MkdirTemp("", "sentinel-quota-probe-*")
+	if err != nil {
+		return nil, fmt.Errorf("create quota probe dir: %w", err)
+	}
+	defer os.Remove(dir)
+	release, err := q.apply(dir)
+	if err != nil {
+		return nil, err
+	}
+	release()
+	return q, nil
+}
+
+// apply mounts the quota over dir, keeping its permissions, and returns the
+// function that unmounts it. dir must be empty, as a freshly created work
+// dir is: anything already in it is hidden by the mount.
+func (q *WorkDirQuota) apply(dir string) (release func(), err error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("stat work dir: %w", err)
+	}
+	opts := fmt.Sprintf("size=%dk,mode=%o", q.sizeKB, info.Mode().Perm())
+	if err := syscall.Mount("tmpfs", dir, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, opts); err != nil {
+		return nil, fmt.Errorf("mount work dir quota: %w", err)
+	}
+	return func() { _ = syscall.Unmount(dir, syscall.MNT_DETACH) }, nil
+}
+
+// diskUsageKB is the space the files under dir occupy, counted in allocated
+// blocks so sparse files count only what they really use.
+func diskUsageKB(dir string) int {
+	var bytes int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			bytes += st.Blocks * 512
+		} else {
+			bytes += info.Size()
+		}
+		return nil
+	})
+	return int((bytes + 1023) / 1024)
+}
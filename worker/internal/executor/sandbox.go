@@ -3,19 +3,26 @@ package executor
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strconv"
+	"slices"
 	"strings"
 	"syscall"
+	"text/template"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
 )
 
 const (
@@ -30,60 +37,193 @@ const (
 type SandboxExecutor struct {
 	nsjailPath string
 	configDir  string
+	languages  *language.Registry
 	logger     *zap.Logger
+	binaries   repository.BinaryCache
+	runtimes   repository.RuntimeRepository
+	workDirs   WorkDirSource
+	cgroups    *JobCgroups
+	quota      *WorkDirQuota
+	networks   *JobNetworks
+	cpus       *CPUPinning
+	io         *IOLimits
+	policyDir  string
+	// randomIdentity is set by SetRandomIdentity.
+	randomIdentity bool
+
+	// templateDir and templates are set by SetConfigTemplates.
+	templateDir string
+	templates   map[string]*template.Template
 }
 
-// NewSandboxExecutor creates a new sandbox executor.
-func NewSandboxExecutor(nsjailPath, configDir string, logger *zap.Logger) *SandboxExecutor {
+// WorkDirSource hands out empty work directories, typically created ahead of
+// time. The executor removes each directory after use.
+type WorkDirSource interface {
+	Acquire() (string, error)
+}
+
+// NewSandboxExecutor creates a new sandbox executor for the languages in the registry.
+func NewSandboxExecutor(nsjailPath, configDir string, languages *language.Registry, logger *zap.Logger) *SandboxExecutor {
 	return &SandboxExecutor{
 		nsjailPath: nsjailPath,
 		configDir:  configDir,
+		languages:  languages,
 		logger:     logger,
 	}
 }
 
+// SetBinaryCache enables reuse of compiled programs for languages with
+// cache_binary set.
+func (e *SandboxExecutor) SetBinaryCache(cache repository.BinaryCache) {
+	e.binaries = cache
+}
+
+// SetWorkDirSource makes the executor take work directories from src
+// instead of creating one per execution.
+func (e *SandboxExecutor) SetWorkDirSource(src WorkDirSource) {
+	e.workDirs = src
+}
+
+// SetJobCgroups runs every sandbox in its own cgroup, which is where
+// memory_used_kb and OOM kills are read from. Without it memory usage is
+// reported as zero.
+func (e *SandboxExecutor) SetJobCgroups(cgroups *JobCgroups) {
+	e.cgroups = cgroups
+}
+
+// SetPolicyDir makes every run use its language's seccomp policy from dir
+// instead of the one its nsjail profile names. It fails, naming each
+// language, unless every registry language has its policy file in dir.
+func (e *SandboxExecutor) SetPolicyDir(dir string) error {
+	var errs []error
+	for _, spec := range e.languages.All() {
+		if _, err := policyPath(dir, spec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	e.policyDir = dir
+	return nil
+}
+
+// policyPath returns the path of spec's seccomp policy in dir, checking that
+// it is a regular file.
+func policyPath(dir string, spec *language.Spec) (string, error) {
+	path := filepath.Join(dir, spec.SeccompPolicyFile())
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("seccomp policy for %s: %w", spec.Name, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("seccomp policy for %s: %s is not a regular file", spec.Name, path)
+	}
+	return path, nil
+}
+
+// SetWorkDirQuota limits each execution's work directory to the quota's
+// size. Without it a run can write until the worker's disk is full.
+func (e *SandboxExecutor) SetWorkDirQuota(quota *WorkDirQuota) {
+	e.quota = quota
+}
+
+// SetCPUPinning runs every sandbox on a core of its own. It takes effect
+// only together with SetJobCgroups, whose cgroups apply the cores.
+func (e *SandboxExecutor) SetCPUPinning(cpus *CPUPinning) {
+	e.cpus = cpus
+}
+
+// SetIOLimits caps every sandbox's disk throughput. Like SetCPUPinning it
+// takes effect only together with SetJobCgroups.
+func (e *SandboxExecutor) SetIOLimits(limits *IOLimits) {
+	e.io = limits
+}
+
+// SetJobNetworks lets runs with the allowlist network policy reach the
+// allowlist. Without it such runs fail with INTERNAL_ERROR.
+func (e *SandboxExecutor) SetJobNetworks(networks *JobNetworks) {
+	e.networks = networks
+}
+
+// SetRuntimes enables languages registered through the API. They are
+// looked up when a language is not in the registry.
+func (e *SandboxExecutor) SetRuntimes(runtimes repository.RuntimeRepository) {
+	e.runtimes = runtimes
+}
+
+// lookup resolves a language from the registry, then from registered
+// runtimes. Runtimes are read on every execution so API changes apply to the
+// next run without a worker restart.
+func (e *SandboxExecutor) lookup(ctx context.Context, lang domain.Language) (*language.Spec, bool, error) {
+	if spec, ok := e.languages.Lookup(lang); ok {
+		return spec, true, nil
+	}
+	if e.runtimes == nil {
+		return nil, false, nil
+	}
+	rt, ok, err := e.runtimes.GetRuntime(ctx, lang)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	spec, err := language.FromRuntime(rt)
+	if err != nil {
+		// The API validates runtimes, so this is a hand-edited row.
+		e.logger.Error("Invalid registered runtime", zap.String("language", string(lang)), zap.Error(err))
+		return nil, false, nil
+	}
+	return spec, true, nil
+}
+
 // Execute runs the given code in an nsjail sandbox and returns the result.
 func (e *SandboxExecutor) Execute(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
-	// Create an ephemeral working directory
-	workDir, err := os.MkdirTemp("", fmt.Sprintf("sentinel-%s-*", req.JobID.String()))
+	spec, ok, err := e.lookup(ctx, req.Language)
 	if err != nil {
-		return nil, fmt.Errorf("create work dir: %w", err)
+		return nil, fmt.Errorf("lookup language: %w", err)
 	}
-	defer os.RemoveAll(workDir)
-
-	switch req.Language {
-	case domain.LangPython:
-		return e.executePython(ctx, req, workDir)
-	case domain.LangCpp:
-		return e.executeCpp(ctx, req, workDir)
-	default:
+	if !ok {
 		return &domain.ExecutionResult{
 			Status: domain.StatusInternalError,
 			Stderr: "unsupported language: " + string(req.Language),
 		}, nil
 	}
-}
 
-func (e *SandboxExecutor) executePython(ctx context.Context, req *domain.ExecutionRequest, workDir string) (*domain.ExecutionResult, error) {
-	// Write source code to file
-	codePath := filepath.Join(workDir, "code.py")
-	if err := os.WriteFile(codePath, []byte(req.SourceCode), 0644); err != nil {
-		return nil, fmt.Errorf("write source: %w", err)
+	if err := spec.CheckFlags(req.CompilerFlags); err != nil {
+		return &domain.ExecutionResult{
+			Status:   domain.StatusCompilationError,
+			Stderr:   err.Error(),
+			ExitCode: 1,
+		}, nil
 	}
 
-	// Write stdin to file
-	stdinPath := filepath.Join(workDir, "stdin.txt")
-	if err := os.WriteFile(stdinPath, []byte(req.Stdin), 0644); err != nil {
-		return nil, fmt.Errorf("write stdin: %w", err)
+	// The API rejects these already; reaching here means the registries differ.
+	if err := spec.CheckEnv(req.Env); err != nil {
+		return &domain.ExecutionResult{
+			Status: domain.StatusInternalError,
+			Stderr: err.Error(),
+		}, nil
+	}
+	if req.NetworkPolicy != "" && (req.NetworkPolicy != domain.NetworkAllowlist || e.networks == nil) {
+		return networkUnsupported(req), nil
 	}
 
-	configPath := filepath.Join(e.configDir, "python.cfg")
-	return e.runNsjail(ctx, req, configPath, workDir, "/usr/bin/python3", "/tmp/work/code.py")
-}
+	// Create an ephemeral working directory
+	setupStart := time.Now()
+	workDir, err := e.newWorkDir(req)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+	if e.quota != nil {
+		release, err := e.quota.apply(workDir)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
 
-func (e *SandboxExecutor) executeCpp(ctx context.Context, req *domain.ExecutionRequest, workDir string) (*domain.ExecutionResult, error) {
 	// Write source code to file
-	codePath := filepath.Join(workDir, "code.cpp")
+	codePath := filepath.Join(workDir, spec.SourceFile)
 	if err := os.WriteFile(codePath, []byte(req.SourceCode), 0644); err != nil {
 		return nil, fmt.Errorf("write source: %w", err)
 	}
@@ -94,89 +234,449 @@ func (e *SandboxExecutor) executeCpp(ctx context.Context, req *domain.ExecutionR
 		return nil, fmt.Errorf("write stdin: %w", err)
 	}
 
-	configPath := filepath.Join(e.configDir, "cpp.cfg")
+	// Both phases see the same identity, so a compiled program finds
+	// itself where it was built.
+	id, err := e.identity()
+	if err != nil {
+		return nil, err
+	}
+	timings := domain.Timings{WorkdirSetupMs: int(time.Since(setupStart).Milliseconds())}
+
+	// Phase 1: Compile, unless the program was compiled before
+	compileTimeMs := 0
+	if spec.IsCompiled() {
+		compileStart := time.Now()
+		failed, ms, err := e.compileInto(ctx, req, spec, workDir, id)
+		timings.CompileMs = int(time.Since(compileStart).Milliseconds())
+		if failed != nil {
+			failed.Timings = timings
+		}
+		if err != nil || failed != nil {
+			return failed, err
+		}
+		compileTimeMs = ms
+	}
+
+	// Phase 2: Execute
+	runStart := time.Now()
+	result, err := e.runNsjail(ctx, req, spec, workDir, id, append(spec.RunArgsAt(id.WorkDir), req.Args...)...)
+	if err != nil {
+		return nil, err
+	}
+	timings.RunMs = int(time.Since(runStart).Milliseconds())
+	result.Timings = timings
+	result.CompileTimeMs = compileTimeMs
+	result.DiskUsedKB = diskUsageKB(workDir)
+	if result.Artifacts, err = collectArtifacts(workDir, req.OutputFiles); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Compile compiles req's source once, so the runs of a judged job can take
+// the binary as their Program instead of each compiling it.
+func (e *SandboxExecutor) Compile(ctx context.Context, req *domain.ExecutionRequest) (*domain.CompiledProgram, *domain.ExecutionResult, error) {
+	spec, ok, err := e.lookup(ctx, req.Language)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lookup language: %w", err)
+	}
+	// Execute reports unsupported languages, and rejects flags, the same way
+	// for every run.
+	if !ok || !spec.IsCompiled() || spec.CheckFlags(req.CompilerFlags) != nil {
+		return nil, nil, nil
+	}
 
-	// Phase 1: Compile
-	compileCtx, compileCancel := context.WithTimeout(ctx, 10*time.Second)
-	defer compileCancel()
+	workDir, err := e.newWorkDir(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(workDir)
+	if e.quota != nil {
+		release, err := e.quota.apply(workDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer release()
+	}
+	if err := os.WriteFile(filepath.Join(workDir, spec.SourceFile), []byte(req.SourceCode), 0644); err != nil {
+		return nil, nil, fmt.Errorf("write source: %w", err)
+	}
 
-	compileResult, err := e.runNsjail(compileCtx, req, configPath, workDir,
-		"/usr/bin/g++", "-std=c++17", "-O2", "-o", "/tmp/work/program", "/tmp/work/code.cpp")
+	id, err := e.identity()
+	if err != nil {
+		return nil, nil, err
+	}
+	compileReq := *req
+	compileReq.Program = nil
+	failed, ms, err := e.compileInto(ctx, &compileReq, spec, workDir, id)
+	if err != nil || failed != nil {
+		return nil, failed, err
+	}
+	binary, err := os.ReadFile(filepath.Join(workDir, language.BinaryFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read compiled binary: %w", err)
+	}
+	return &domain.CompiledProgram{Binary: binary, CompileTimeMs: ms}, nil, nil
+}
+
+// compileInto leaves req's compiled program in workDir: its Program, a
+// cached binary, or a freshly compiled one. A failed compile comes back as a
+// COMPILATION_ERROR result; compileTimeMs is zero unless compiling ran.
+func (e *SandboxExecutor) compileInto(ctx context.Context, req *domain.ExecutionRequest, spec *language.Spec, workDir string, id sandboxIdentity) (failed *domain.ExecutionResult, compileTimeMs int, err error) {
+	if req.Program != nil {
+		if err := os.WriteFile(filepath.Join(workDir, language.BinaryFile), req.Program.Binary, 0755); err != nil {
+			return nil, 0, fmt.Errorf("write compiled binary: %w", err)
+		}
+		return nil, 0, nil
+	}
+	if e.restoreBinary(ctx, req, spec, workDir) {
+		return nil, 0, nil
+	}
+	compileResult, err := e.runNsjail(ctx, compileRequest(req, spec), spec, workDir, id, spec.CompileArgsAt(id.WorkDir, req.CompilerFlags)...)
 	if err != nil {
-		return nil, fmt.Errorf("compile: %w", err)
+		return nil, 0, fmt.Errorf("compile: %w", err)
 	}
 	if compileResult.ExitCode != 0 {
 		compileResult.Status = domain.StatusCompilationError
-		return compileResult, nil
+		compileResult.CompileTimeMs = compileResult.TimeUsedMs
+		compileResult.DiskUsedKB = diskUsageKB(workDir)
+		return compileResult, 0, nil
 	}
+	e.storeBinary(ctx, req, spec, workDir)
+	return nil, compileResult.TimeUsedMs, nil
+}
 
-	// Phase 2: Execute
-	return e.runNsjail(ctx, req, configPath, workDir, "/tmp/work/program")
+func (e *SandboxExecutor) newWorkDir(req *domain.ExecutionRequest) (string, error) {
+	if e.workDirs != nil {
+		return e.workDirs.Acquire()
+	}
+	// Named without the job ID, which programs could otherwise read from
+	// the bind mount's root in /proc/self/mountinfo.
+	workDir, err := os.MkdirTemp("", "sentinel-*")
+	if err != nil {
+		return "", fmt.Errorf("create work dir: %w", err)
+	}
+	return workDir, nil
+}
+
+// binaryKey identifies a compiled program by everything that went into it.
+// The compile command template covers compiler upgrades that change it; the
+// version covers the rest.
+func binaryKey(req *domain.ExecutionRequest, spec *language.Spec) string {
+	h := sha256.New()
+	for _, part := range [][]string{
+		{string(spec.Name), spec.Version},
+		spec.Compile,
+		req.CompilerFlags,
+		{req.SourceCode},
+	} {
+		for _, s := range part {
+			fmt.Fprintf(h, "%d:%s", len(s), s)
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// restoreBinary copies a cached program into workDir, reporting whether the
+// compile phase can be skipped. Cache failures fall back to compiling.
+func (e *SandboxExecutor) restoreBinary(ctx context.Context, req *domain.ExecutionRequest, spec *language.Spec, workDir string) bool {
+	if e.binaries == nil || !spec.CacheBinary {
+		return false
+	}
+	binary, ok, err := e.binaries.Get(ctx, binaryKey(req, spec))
+	if err != nil {
+		e.logger.Warn("Failed to read cached binary", zap.Error(err), zap.String("job_id", req.JobID.String()))
+	}
+	if !ok {
+		metrics.BinaryCacheMisses.WithLabelValues(string(spec.Name)).Inc()
+		return false
+	}
+	if err := os.WriteFile(filepath.Join(workDir, language.BinaryFile), binary, 0755); err != nil {
+		e.logger.Warn("Failed to restore cached binary", zap.Error(err), zap.String("job_id", req.JobID.String()))
+		return false
+	}
+	metrics.BinaryCacheHits.WithLabelValues(string(spec.Name)).Inc()
+	return true
+}
+
+// storeBinary caches the program just compiled in workDir.
+func (e *SandboxExecutor) storeBinary(ctx context.Context, req *domain.ExecutionRequest, spec *language.Spec, workDir string) {
+	if e.binaries == nil || !spec.CacheBinary {
+		return
+	}
+	binary, err := os.ReadFile(filepath.Join(workDir, language.BinaryFile))
+	if err == nil {
+		err = e.binaries.Put(ctx, binaryKey(req, spec), binary)
+	}
+	if err != nil {
+		e.logger.Warn("Failed to cache compiled binary", zap.Error(err), zap.String("job_id", req.JobID.String()))
+	}
+}
+
+// compileRequest returns a copy of req carrying the compile-phase limits in
+// place of the runtime ones, so runNsjail applies them to the compiler. The
+// submission's environment and process limit are meant for the program and
+// are dropped, as are its network access and interactive streams.
+// Request overrides win over the registry, which wins over the runtime limits.
+func compileRequest(req *domain.ExecutionRequest, spec *language.Spec) *domain.ExecutionRequest {
+	compileReq := *req
+	if spec.CompileTimeLimitMs > 0 {
+		compileReq.TimeLimitMs = spec.CompileTimeLimitMs
+	}
+	if req.CompileTimeLimitMs > 0 {
+		compileReq.TimeLimitMs = req.CompileTimeLimitMs
+	}
+	if spec.CompileMemoryLimitKB > 0 {
+		compileReq.MemoryLimitKB = spec.CompileMemoryLimitKB
+	}
+	if req.CompileMemoryLimitKB > 0 {
+		compileReq.MemoryLimitKB = req.CompileMemoryLimitKB
+	}
+	compileReq.CPUTimeLimitMs = 0
+	compileReq.PidsLimit = 0
+	compileReq.Env = nil
+	compileReq.NetworkPolicy = ""
+	compileReq.StdinStream = nil
+	compileReq.LiveOutput = nil
+	compileReq.KillPatterns = nil
+	return &compileReq
+}
+
+// interactiveUnsupported is the result of an interactive run on an executor
+// that cannot stream its input.
+func interactiveUnsupported() *domain.ExecutionResult {
+	return &domain.ExecutionResult{
+		Status: domain.StatusInternalError,
+		Stderr: "interactive execution is not available on this worker",
+	}
 }
 
 func (e *SandboxExecutor) runNsjail(
 	ctx context.Context,
 	req *domain.ExecutionRequest,
-	configPath, workDir string,
+	spec *language.Spec,
+	workDir string,
+	id sandboxIdentity,
 	execArgs ...string,
 ) (*domain.ExecutionResult, error) {
-	// Build nsjail command
-	args := []string{
-		"--config", configPath,
-		"--bindmount", workDir + ":/tmp/work",
-		"--time_limit", fmt.Sprintf("%d", req.TimeLimitMs/1000+1),
-		"--cgroup_mem_max", fmt.Sprintf("%d", req.MemoryLimitKB*1024),
-		"--",
+	cfg := &nsjailConfig{
+		Name:           fmt.Sprintf("sentinel-%s-%s", spec.Name, req.JobID),
+		Hostname:       id.Hostname,
+		WorkDir:        workDir,
+		SandboxDir:     id.WorkDir,
+		UID:            id.UID,
+		GID:            id.GID,
+		TimeLimitSec:   req.TimeLimitMs/1000 + 1,
+		MemoryMaxBytes: req.MemoryLimitKB * 1024,
+		CPULimitSec:    cpuLimitSeconds(req),
+		PidsMax:        req.PidsLimit,
+		Mounts:         spec.Mounts,
+	}
+	for _, m := range spec.Tmpfs {
+		cfg.Tmpfs = append(cfg.Tmpfs, nsjailTmpfs{Target: m.Target, SizeBytes: m.SizeMB << 20})
+	}
+	if e.policyDir != "" {
+		// Registry languages were checked by SetPolicyDir; this catches
+		// runtimes registered since, whose policy may be missing.
+		policy, err := policyPath(e.policyDir, spec)
+		if err != nil {
+			return nil, err
+		}
+		cfg.SeccompPolicy = policy
+	}
+	// The submission's variables come last so they win over the spec's. A
+	// name without a value makes nsjail copy the worker's own variable.
+	cfg.Env = append(cfg.Env, spec.EnvPassthrough...)
+	specEnv := spec.EnvAt(id.WorkDir)
+	for _, name := range language.EnvNames(specEnv) {
+		cfg.Env = append(cfg.Env, name+"="+specEnv[name])
+	}
+	for _, name := range language.EnvNames(req.Env) {
+		cfg.Env = append(cfg.Env, name+"="+req.Env[name])
+	}
+	var cgroup *jobCgroup
+	if e.cgroups != nil {
+		var err error
+		// A randomized identity leaves the job ID out of the cgroup's
+		// name too.
+		name := req.JobID.String()
+		if e.randomIdentity {
+			name = "sandbox"
+		}
+		if cgroup, err = e.cgroups.create(name); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := cgroup.remove(); err != nil {
+				e.logger.Warn("Failed to remove job cgroup", zap.Error(err), zap.String("job_id", req.JobID.String()))
+			}
+		}()
+		cfg.CgroupPath = cgroup.dir
+		if e.cpus != nil {
+			cpu, err := e.cpus.acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer e.cpus.release(cpu)
+			if err := cgroup.pin(cpu); err != nil {
+				return nil, err
+			}
+		}
+		if e.io != nil {
+			if err := cgroup.limitIO(e.io); err != nil {
+				return nil, err
+			}
+		}
 	}
+	var network *jobNetwork
+	if req.NetworkPolicy == domain.NetworkAllowlist {
+		var err error
+		if network, err = e.networks.create(ctx); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := e.networks.remove(network); err != nil {
+				e.logger.Warn("Failed to remove run network", zap.Error(err), zap.String("job_id", req.JobID.String()))
+			}
+		}()
+		cfg.NetworkNamespace = network.name
+		cfg.Mounts = append(slices.Clip(cfg.Mounts), domain.Mount{Source: network.hosts, Target: "/etc/hosts"})
+	}
+	args, cleanup, err := e.nsjailArgs(spec, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	args = append(args, "--")
 	args = append(args, execArgs...)
+	command := e.nsjailPath
+	if cfg.NetworkNamespace != "" {
+		// nsjail cannot join a namespace itself, so it runs inside one.
+		args = append([]string{"netns", "exec", cfg.NetworkNamespace, e.nsjailPath}, args...)
+		command = e.networks.ipPath
+	}
 
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(req.TimeLimitMs+2000)*time.Millisecond)
 	defer cancel()
+	// A kill pattern match ends the run the way a timeout does.
+	runCtx, kill := context.WithCancel(timeoutCtx)
+	defer kill()
 
-	cmd := exec.CommandContext(timeoutCtx, e.nsjailPath, args...)
+	cmd := exec.CommandContext(runCtx, command, args...)
 
-	// Set up process group for clean termination
+	// Set up process group for clean termination. A timeout or a cancelled
+	// job kills the whole group, not only nsjail.
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
 
 	// Set up stdin from file
 	stdinFile := filepath.Join(workDir, "stdin.txt")
-	if stdinData, err := os.ReadFile(stdinFile); err == nil {
+	var stdinData []byte
+	if data, err := os.ReadFile(stdinFile); err == nil {
+		stdinData = data
 		cmd.Stdin = bytes.NewReader(stdinData)
 	}
+	// An interactive stream is fed through a pipe of our own: with it as
+	// cmd.Stdin, Wait would block on a client that never closes its input.
+	var stdinPipe io.WriteCloser
+	if req.StdinStream != nil {
+		cmd.Stdin = nil
+		if stdinPipe, err = cmd.StdinPipe(); err != nil {
+			return nil, fmt.Errorf("create stdin pipe: %w", err)
+		}
+	}
 
 	// Use limited writers to cap output size and prevent OOM on host
+	limit := maxOutputBytes
+	if req.MaxOutputBytes > 0 {
+		limit = req.MaxOutputBytes
+	}
 	var stdout, stderr limitedBuffer
-	stdout.limit = maxOutputBytes
-	stderr.limit = maxOutputBytes
+	stdout.limit = limit
+	stderr.limit = limit
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	// The watch and LiveOutput go first: the buffers' short writes past
+	// their limit would stop MultiWriter before them.
+	watch := newOutputWatch(req.KillPatterns, kill)
+	var watchStdout, watchStderr io.WriteCloser
+	if watch != nil {
+		watchStdout = watch.stream("stdout", nil)
+		watchStderr = watch.stream("stderr", func(line string) bool {
+			return isNsjailLogLine(strings.TrimSpace(line))
+		})
+		cmd.Stdout = io.MultiWriter(watchStdout, &stdout)
+		cmd.Stderr = io.MultiWriter(watchStderr, &stderr)
+	}
+	if req.LiveOutput != nil {
+		cmd.Stdout = io.MultiWriter(req.LiveOutput, cmd.Stdout)
+	}
 
 	startTime := time.Now()
-	err := cmd.Run()
+	err = cmd.Start()
+	if err == nil {
+		if stdinPipe != nil {
+			go func() {
+				// Ends with the run: Wait closes the pipe, failing the next
+				// write, and the caller closes the stream.
+				_, _ = io.Copy(stdinPipe, io.MultiReader(bytes.NewReader(stdinData), req.StdinStream))
+				stdinPipe.Close()
+			}()
+		}
+		err = cmd.Wait()
+	}
 	elapsed := time.Since(startTime)
+	if watch != nil {
+		watchStdout.Close()
+		watchStderr.Close()
+	}
 
 	// Separate nsjail log lines from actual program stderr.
 	// nsjail prefixes its log lines with "[I]", "[W]", "[E]", "[F]", "[D]".
 	progStderr, nsjailLog := separateNsjailLogs(stderr.String())
 
 	result := &domain.ExecutionResult{
-		Stdout:     truncateOutput(stdout.String(), stdout.truncated),
-		Stderr:     truncateOutput(progStderr, false),
-		ExitCode:   0,
-		TimeUsedMs: int(elapsed.Milliseconds()),
+		Stdout:          truncateOutput(stdout.String(), stdout.truncated),
+		Stderr:          truncateOutput(progStderr, false),
+		ExitCode:        0,
+		TimeUsedMs:      int(elapsed.Milliseconds()),
+		StdoutTruncated: stdout.truncated,
 	}
 
-	// Try to read memory usage from cgroup (if available)
-	result.MemoryUsedKB = readCgroupMemoryPeak(workDir)
+	var stats cgroupStats
+	if cgroup != nil {
+		stats = cgroup.stats()
+	}
+	result.MemoryUsedKB = stats.peakKB
+	result.CPUTimeUsedMs = stats.cpuMs
+	if network != nil {
+		if n, err := e.networks.blocked(network); err != nil {
+			e.logger.Warn("Failed to count blocked packets", zap.Error(err), zap.String("job_id", req.JobID.String()))
+		} else if n > 0 {
+			result.Flag(domain.SecurityNetworkBlocked, fmt.Sprintf("%d packets to destinations outside the allowlist dropped", n))
+		}
+	}
 
 	e.logger.Debug("nsjail execution completed",
 		zap.String("job_id", req.JobID.String()),
 		zap.Duration("elapsed", elapsed),
 		zap.Int("exit_code", result.ExitCode),
 		zap.Int("memory_used_kb", result.MemoryUsedKB),
+		zap.Int("cpu_time_used_ms", result.CPUTimeUsedMs),
 		zap.String("nsjail_log", nsjailLog),
 	)
 
+	if watch != nil && watch.Violation() != "" {
+		result.Status = domain.StatusOutputViolation
+		result.Violation = watch.Violation()
+		result.Flag(domain.SecurityAbuseFlag, result.Violation)
+		result.ExitCode = -1
+		return result, nil
+	}
+
 	if timeoutCtx.Err() == context.DeadlineExceeded {
 		// Kill entire process group
 		if cmd.Process != nil {
@@ -190,10 +690,21 @@ func (e *SandboxExecutor) runNsjail(
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			result.ExitCode = exitErr.ExitCode()
-			// Check if killed by OOM (exit code 137 = SIGKILL from cgroup OOM killer)
-			if isOOMKill(exitErr.ExitCode(), nsjailLog) {
+			if isSeccompKill(exitErr.ExitCode(), nsjailLog) {
+				result.Flag(domain.SecuritySeccompViolation, "killed by SIGSYS for a syscall outside the seccomp policy")
+			}
+			// The job cgroup counts OOM kills exactly; without one, guess
+			// from the exit code and nsjail's log.
+			oom := stats.oomKills > 0
+			if cgroup == nil {
+				oom = isOOMKill(exitErr.ExitCode(), nsjailLog)
+			}
+			switch {
+			case oom:
 				result.Status = domain.StatusMemoryLimitExceeded
-			} else {
+			case cpuLimitExceeded(req, result):
+				result.Status = domain.StatusTimeout
+			default:
 				result.Status = domain.StatusRuntimeError
 			}
 		} else {
@@ -205,9 +716,25 @@ func (e *SandboxExecutor) runNsjail(
 
 	result.ExitCode = 0
 	result.Status = domain.StatusSuccess
+	if cpuLimitExceeded(req, result) {
+		result.Status = domain.StatusTimeout
+	}
 	return result, nil
 }
 
+// cpuLimitSeconds rounds the request's CPU-time limit up to the whole
+// seconds RLIMIT_CPU takes.
+func cpuLimitSeconds(req *domain.ExecutionRequest) int {
+	return (req.CPULimitMs() + 999) / 1000
+}
+
+// cpuLimitExceeded reports whether a run used more CPU time than its limit
+// allows. The kernel only enforces whole seconds, so a run can finish
+// between the limit and the next second; it still exceeded the limit.
+func cpuLimitExceeded(req *domain.ExecutionRequest, result *domain.ExecutionResult) bool {
+	return result.CPUTimeUsedMs > req.CPULimitMs()
+}
+
 // ──────────────────────────────────────────────────────
 // Helper types and functions
 // ──────────────────────────────────────────────────────
@@ -281,6 +808,18 @@ func isNsjailLogLine(line string) bool {
 	return false
 }
 
+// isSeccompKill reports whether the program was killed for a syscall its
+// seccomp policy forbids. The policies' DEFAULT KILL action kills with
+// SIGSYS, which nsjail reports as exit code 159 (128 + 31).
+func isSeccompKill(exitCode int, nsjailLog string) bool {
+	if exitCode == 128+int(syscall.SIGSYS) {
+		return true
+	}
+	lowerLog := strings.ToLower(nsjailLog)
+	return strings.Contains(lowerLog, "seccomp violation") ||
+		strings.Contains(lowerLog, "bad system call")
+}
+
 // isOOMKill checks if the process was killed due to an OOM condition.
 // Exit code 137 = process received SIGKILL (128 + 9), which is the
 // standard OOM kill signal from cgroups. We also check nsjail logs
@@ -296,35 +835,6 @@ func isOOMKill(exitCode int, nsjailLog string) bool {
 		strings.Contains(lowerLog, "cgroup_mem")
 }
 
-// readCgroupMemoryPeak attempts to read the peak memory usage from cgroup v2.
-// Returns 0 if unavailable (e.g., not running inside a cgroup or no permissions).
-func readCgroupMemoryPeak(workDir string) int {
-	// cgroup v2: /sys/fs/cgroup/<path>/memory.peak
-	// We try common paths; in a container this may not be accessible.
-	paths := []string{
-		"/sys/fs/cgroup/memory.peak",
-		"/sys/fs/cgroup/memory/memory.max_usage_in_bytes", // cgroup v1 fallback
-	}
-
-	for _, path := range paths {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-		s := strings.TrimSpace(string(data))
-		if s == "max" || s == "" {
-			continue
-		}
-		val, err := strconv.ParseInt(s, 10, 64)
-		if err != nil {
-			continue
-		}
-		return int(val / 1024) // convert bytes to KB
-	}
-
-	return 0
-}
-
 // limitedReader wraps an io.Reader and caps reads at a byte limit.
 // Used for piping stdin if needed in the future.
 type limitedReader struct {
@@ -1,10 +1,17 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,15 +19,29 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository/mock"
 )
 
 // ──────────────────────────────────────────────────────
 // Pure unit tests — no nsjail binary needed
 // ──────────────────────────────────────────────────────
 
+// registryPath is the language registry shipped with the repository.
+const registryPath = "../../../sandbox/languages.yaml"
+
+func testLanguages(t testing.TB) *language.Registry {
+	t.Helper()
+	reg, err := language.Load(registryPath)
+	if err != nil {
+		t.Fatalf("load language registry: %v", err)
+	}
+	return reg
+}
+
 func TestNewSandboxExecutor(t *testing.T) {
 	logger := zap.NewNop()
-	exe := NewSandboxExecutor("/usr/bin/nsjail", "/etc/nsjail", logger)
+	exe := NewSandboxExecutor("/usr/bin/nsjail", "/etc/nsjail", testLanguages(t), logger)
 
 	if exe.nsjailPath != "/usr/bin/nsjail" {
 		t.Errorf("expected nsjailPath /usr/bin/nsjail, got %s", exe.nsjailPath)
@@ -35,7 +56,7 @@ func TestNewSandboxExecutor(t *testing.T) {
 
 func TestExecute_UnsupportedLanguage(t *testing.T) {
 	logger := zap.NewNop()
-	exe := NewSandboxExecutor("/usr/bin/nsjail", "/etc/nsjail", logger)
+	exe := NewSandboxExecutor("/usr/bin/nsjail", "/etc/nsjail", testLanguages(t), logger)
 
 	req := &domain.ExecutionRequest{
 		JobID:         uuid.New(),
@@ -60,7 +81,7 @@ func TestExecute_UnsupportedLanguage(t *testing.T) {
 func TestWorkdirCreationAndCleanup(t *testing.T) {
 	logger := zap.NewNop()
 	// Use a nonexistent nsjail path — execution will fail but workdir logic is testable
-	exe := NewSandboxExecutor("/nonexistent/nsjail", t.TempDir(), logger)
+	exe := NewSandboxExecutor("/nonexistent/nsjail", t.TempDir(), testLanguages(t), logger)
 
 	req := &domain.ExecutionRequest{
 		JobID:         uuid.New(),
@@ -92,7 +113,7 @@ func TestExecutePython_WritesFiles(t *testing.T) {
 		t.Fatalf("write dummy config: %v", err)
 	}
 
-	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, logger)
+	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, testLanguages(t), logger)
 
 	req := &domain.ExecutionRequest{
 		JobID:         uuid.New(),
@@ -124,7 +145,7 @@ func TestExecuteCpp_WritesFiles(t *testing.T) {
 		t.Fatalf("write dummy config: %v", err)
 	}
 
-	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, logger)
+	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, testLanguages(t), logger)
 
 	req := &domain.ExecutionRequest{
 		JobID:         uuid.New(),
@@ -144,6 +165,551 @@ func TestExecuteCpp_WritesFiles(t *testing.T) {
 	}
 }
 
+func TestExecuteJavaScript_WritesFiles(t *testing.T) {
+	logger := zap.NewNop()
+	configDir := t.TempDir()
+
+	// Create a dummy javascript.cfg
+	if err := os.WriteFile(filepath.Join(configDir, "javascript.cfg"), []byte("# dummy"), 0644); err != nil {
+		t.Fatalf("write dummy config: %v", err)
+	}
+
+	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, testLanguages(t), logger)
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangJS,
+		SourceCode:    "console.log('hello')",
+		TimeLimitMs:   5000,
+		MemoryLimitKB: 262144,
+	}
+
+	result, err := exe.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status == domain.StatusSuccess {
+		t.Error("expected non-success status with missing nsjail")
+	}
+}
+
+func TestExecuteGo_WritesFiles(t *testing.T) {
+	logger := zap.NewNop()
+	configDir := t.TempDir()
+
+	// Create a dummy golang.cfg
+	if err := os.WriteFile(filepath.Join(configDir, "golang.cfg"), []byte("# dummy"), 0644); err != nil {
+		t.Fatalf("write dummy config: %v", err)
+	}
+
+	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, testLanguages(t), logger)
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangGo,
+		SourceCode:    "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hello\") }",
+		TimeLimitMs:   5000,
+		MemoryLimitKB: 262144,
+	}
+
+	result, err := exe.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Missing nsjail fails the compile phase before a binary exists.
+	if result.Status == domain.StatusSuccess {
+		t.Error("expected non-success status with missing nsjail")
+	}
+}
+
+func TestExecuteRust_WritesFiles(t *testing.T) {
+	logger := zap.NewNop()
+	configDir := t.TempDir()
+
+	// Create a dummy rust.cfg
+	if err := os.WriteFile(filepath.Join(configDir, "rust.cfg"), []byte("# dummy"), 0644); err != nil {
+		t.Fatalf("write dummy config: %v", err)
+	}
+
+	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, testLanguages(t), logger)
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangRust,
+		SourceCode:    "fn main() { println!(\"hello\"); }",
+		TimeLimitMs:   5000,
+		MemoryLimitKB: 262144,
+	}
+
+	result, err := exe.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Missing nsjail fails the compile phase before a binary exists.
+	if result.Status == domain.StatusSuccess {
+		t.Error("expected non-success status with missing nsjail")
+	}
+}
+
+func TestCompileRequest_Limits(t *testing.T) {
+	reg := testLanguages(t)
+	rust, _ := reg.Lookup(domain.LangRust)
+	cpp, _ := reg.Lookup(domain.LangCpp)
+
+	tests := []struct {
+		name         string
+		spec         *language.Spec
+		req          domain.ExecutionRequest
+		wantTimeMs   int
+		wantMemoryKB int
+	}{
+		{
+			name:         "rust registry defaults",
+			spec:         rust,
+			req:          domain.ExecutionRequest{Language: domain.LangRust, TimeLimitMs: 1000, MemoryLimitKB: 65536},
+			wantTimeMs:   30000,
+			wantMemoryKB: 1048576,
+		},
+		{
+			name: "explicit compile limits",
+			spec: rust,
+			req: domain.ExecutionRequest{
+				Language: domain.LangRust, TimeLimitMs: 1000, MemoryLimitKB: 65536,
+				CompileTimeLimitMs: 45000, CompileMemoryLimitKB: 2097152,
+			},
+			wantTimeMs:   45000,
+			wantMemoryKB: 2097152,
+		},
+		{
+			name:         "cpp keeps runtime memory",
+			spec:         cpp,
+			req:          domain.ExecutionRequest{Language: domain.LangCpp, TimeLimitMs: 2000, MemoryLimitKB: 131072},
+			wantTimeMs:   10000,
+			wantMemoryKB: 131072,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compileRequest(&tt.req, tt.spec)
+			if got.TimeLimitMs != tt.wantTimeMs {
+				t.Errorf("compile time limit: got %d, want %d", got.TimeLimitMs, tt.wantTimeMs)
+			}
+			if got.MemoryLimitKB != tt.wantMemoryKB {
+				t.Errorf("compile memory limit: got %d, want %d", got.MemoryLimitKB, tt.wantMemoryKB)
+			}
+			if got == &tt.req {
+				t.Error("compileRequest must not modify the runtime request in place")
+			}
+		})
+	}
+
+	req := domain.ExecutionRequest{Language: domain.LangCpp, Env: map[string]string{"LANG": "C"}, PidsLimit: 512}
+	got := compileRequest(&req, cpp)
+	if got.Env != nil || req.Env == nil {
+		t.Error("expected the submission's environment to be dropped for the compiler only")
+	}
+	if got.PidsLimit != 0 || req.PidsLimit != 512 {
+		t.Error("expected the submission's process limit to be dropped for the compiler only")
+	}
+}
+
+func TestSetPolicyDir(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	reg := testLanguages(t)
+	exe := NewSandboxExecutor(nsjail, dir, reg, zap.NewNop())
+	if err := exe.SetPolicyDir("../../../sandbox/policies"); err != nil {
+		t.Errorf("shipped policies: %v", err)
+	}
+
+	policies := filepath.Join(dir, "policies")
+	if err := os.Mkdir(policies, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range reg.All() {
+		if spec.Name == domain.LangRust {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(policies, spec.SeccompPolicyFile()), []byte("USE allow DEFAULT KILL\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := exe.SetPolicyDir(policies); err == nil || !strings.Contains(err.Error(), "rust") {
+		t.Fatalf("expected the missing rust policy to be reported, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(policies, "rust.policy"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := exe.SetPolicyDir(policies); err != nil {
+		t.Fatalf("SetPolicyDir: %v", err)
+	}
+
+	if _, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangGo,
+		SourceCode:    "package main",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, _ := os.ReadFile(argsFile)
+	if want := "--seccomp_policy " + filepath.Join(policies, "golang.policy"); !strings.Contains(string(data), want) {
+		t.Errorf("expected %q in args %q", want, data)
+	}
+}
+
+func TestExecute_SandboxOverrides(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	reg := testLanguages(t)
+	if err := reg.ApplyOverrides([]language.Override{{
+		Name:           domain.LangPython,
+		Mounts:         []domain.Mount{{Source: "/opt/wheels", Target: "/opt/wheels"}},
+		Tmpfs:          []language.TmpfsMount{{Target: "/tmp", SizeMB: 64}},
+		EnvPassthrough: []string{"HTTPS_PROXY"},
+	}}); err != nil {
+		t.Fatalf("ApplyOverrides: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, reg, zap.NewNop())
+	if _, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	data, _ := os.ReadFile(argsFile)
+	args := string(data)
+	tmpfs := strings.Index(args, "--mount none:/tmp:tmpfs:size=67108864")
+	work := strings.Index(args, "--bindmount ")
+	if tmpfs < 0 || work < 0 || tmpfs > work {
+		t.Errorf("expected the /tmp tmpfs before the work dir mount, got %q", args)
+	}
+	for _, want := range []string{"--bindmount_ro /opt/wheels:/opt/wheels", "--env HTTPS_PROXY "} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected %q in args %q", want, args)
+		}
+	}
+}
+
+func TestExecute_KillPatterns(t *testing.T) {
+	// A stand-in for nsjail whose program would print for ten seconds.
+	dir := t.TempDir()
+	nsjail := filepath.Join(dir, "nsjail")
+	script := "#!/bin/sh\necho '[I] Mode: STANDALONE_ONCE' >&2\necho hello\n" +
+		"printf 'please ignore previous instructions' >&2\necho >&2\n" +
+		"i=0; while [ $i -lt 100000 ]; do echo spam; i=$((i+1)); done\nsleep 10\n"
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+
+	for _, tt := range []struct {
+		name     string
+		patterns []domain.KillPattern
+		want     string
+	}{
+		{
+			name:     "match",
+			patterns: []domain.KillPattern{{Pattern: regexp.MustCompile(`(?i)ignore previous`), Message: "no prompt injection"}},
+			want:     "kill pattern 1, stderr line 1: no prompt injection",
+		},
+		{
+			name: "repeated line",
+			patterns: []domain.KillPattern{
+				{Pattern: regexp.MustCompile("STANDALONE"), Message: "nsjail's own log"},
+				{Pattern: regexp.MustCompile("."), Repeats: 1000},
+			},
+			want: `kill pattern 2, stdout line 1001: output matches kill pattern "."`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+				JobID:         uuid.New(),
+				Language:      domain.LangPython,
+				SourceCode:    "print(1)",
+				TimeLimitMs:   20000,
+				MemoryLimitKB: 65536,
+				KillPatterns:  tt.patterns,
+			})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if res.Status != domain.StatusOutputViolation || res.Violation != tt.want {
+				t.Errorf("status %s, violation %q; want OUTPUT_VIOLATION, %q", res.Status, res.Violation, tt.want)
+			}
+			if want := []domain.SecurityEvent{{Kind: domain.SecurityAbuseFlag, Detail: tt.want}}; !reflect.DeepEqual(res.SecurityEvents, want) {
+				t.Errorf("security events %+v, want %+v", res.SecurityEvents, want)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("run took %v, want it stopped early", elapsed)
+			}
+		})
+	}
+}
+
+func TestExecute_SeccompViolation(t *testing.T) {
+	// A stand-in for nsjail whose program is killed with SIGSYS.
+	dir := t.TempDir()
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte("#!/bin/sh\nexit 159\n"), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+
+	res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   2000,
+		MemoryLimitKB: 65536,
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if res.Status != domain.StatusRuntimeError {
+		t.Errorf("status %s, want RUNTIME_ERROR", res.Status)
+	}
+	if len(res.SecurityEvents) != 1 || res.SecurityEvents[0].Kind != domain.SecuritySeccompViolation {
+		t.Errorf("security events %+v, want one seccomp violation", res.SecurityEvents)
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	req := &domain.ExecutionRequest{KillPatterns: []domain.KillPattern{{Pattern: regexp.MustCompile("^x+$"), Repeats: 3}}}
+	for _, tt := range []struct {
+		stdout string
+		want   domain.ExecutionStatus
+	}{
+		{"x\nx\nx", domain.StatusOutputViolation},
+		{"x\nx\ny\nx", domain.StatusSuccess},
+		{strings.Repeat("x", 3*maxWatchedLine) + "\n" + strings.Repeat("x", maxWatchedLine) + "y\nxx\n", domain.StatusSuccess},
+	} {
+		res := &domain.ExecutionResult{Status: domain.StatusSuccess, Stdout: tt.stdout}
+		checkOutput(req, res)
+		if res.Status != tt.want {
+			t.Errorf("stdout %.20q: status %s, want %s", tt.stdout, res.Status, tt.want)
+		}
+	}
+}
+
+func TestExecute_RandomIdentity(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	run := func() string {
+		t.Helper()
+		if _, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+			JobID:         uuid.New(),
+			Language:      domain.LangPython,
+			SourceCode:    "print(1)",
+			TimeLimitMs:   1000,
+			MemoryLimitKB: 65536,
+		}); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		data, _ := os.ReadFile(argsFile)
+		return string(data)
+	}
+
+	fixed := run()
+	for _, want := range []string{"--hostname sandbox", "--user 1000", "--group 1000", "--cwd /tmp/work", "/tmp/work/code.py"} {
+		if !strings.Contains(fixed, want) {
+			t.Errorf("expected %q in args %q", want, fixed)
+		}
+	}
+
+	exe.SetRandomIdentity(true)
+	first, second := run(), run()
+	if first == second {
+		t.Errorf("two runs got the same args %q", first)
+	}
+	for _, args := range []string{first, second} {
+		for _, fixedArg := range []string{"--hostname sandbox", "--user 1000", "--cwd /tmp/work "} {
+			if strings.Contains(args, fixedArg) {
+				t.Errorf("expected no %q in args %q", fixedArg, args)
+			}
+		}
+		f := strings.Fields(args)
+		i := slices.Index(f, "--cwd")
+		if i < 0 || i+1 >= len(f) || !slices.Contains(f, f[i+1]+"/code.py") {
+			t.Errorf("expected the run to use its work dir, got args %q", args)
+		}
+	}
+}
+
+func TestSetConfigTemplates(t *testing.T) {
+	// A stand-in for nsjail that records its arguments and the config it
+	// was given, which the executor removes afterwards.
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	configFile := filepath.Join(dir, "config")
+	nsjail := filepath.Join(dir, "nsjail")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncp \"$2\" " + configFile + "\n"
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	reg := testLanguages(t)
+	if err := reg.ApplyOverrides([]language.Override{{
+		Name:  domain.LangPython,
+		Tmpfs: []language.TmpfsMount{{Target: "/tmp", SizeMB: 64}},
+	}}); err != nil {
+		t.Fatalf("ApplyOverrides: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, reg, zap.NewNop())
+	if err := exe.SetConfigTemplates(dir); err == nil || !strings.Contains(err.Error(), "python") {
+		t.Fatalf("expected missing templates to be reported, got %v", err)
+	}
+	if err := exe.SetConfigTemplates("../../../sandbox/nsjail/templates"); err != nil {
+		t.Fatalf("shipped templates: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		pids int
+		want string
+	}{
+		{name: "profile default", pids: 0, want: "cgroup_pids_max: 64\n"},
+		{name: "override", pids: 300, want: "cgroup_pids_max: 300\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+				JobID:         uuid.New(),
+				Language:      domain.LangPython,
+				SourceCode:    "print(1)",
+				TimeLimitMs:   5000,
+				MemoryLimitKB: 65536,
+				PidsLimit:     tt.pids,
+				Env:           map[string]string{"PYTHONHASHSEED": "0"},
+			}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			args, _ := os.ReadFile(argsFile)
+			fields := strings.Fields(string(args))
+			if len(fields) < 3 || fields[0] != "--config" || fields[2] != "--" {
+				t.Fatalf("expected only the rendered config before the command, got %q", args)
+			}
+			if _, err := os.Stat(fields[1]); !os.IsNotExist(err) {
+				t.Errorf("expected the rendered config to be removed, got %v", err)
+			}
+			data, err := os.ReadFile(configFile)
+			if err != nil {
+				t.Fatalf("read rendered config: %v", err)
+			}
+			config := string(data)
+			for _, want := range []string{
+				tt.want,
+				"time_limit: 6\n",
+				"cgroup_mem_max: 67108864\n",
+				"rlimit_cpu: 5\n",
+				`envar: "PYTHONHASHSEED=0"`,
+				`seccomp_policy_file: "/etc/nsjail/policies/python.policy"`,
+			} {
+				if !strings.Contains(config, want) {
+					t.Errorf("expected %q in config:\n%s", want, config)
+				}
+			}
+			tmpfs := strings.Index(config, `dst: "/tmp"`+"\n    fstype: \"tmpfs\"\n    options: \"size=67108864\"")
+			work := strings.Index(config, `dst: "/tmp/work"`)
+			if tmpfs < 0 || work < 0 || tmpfs > work {
+				t.Errorf("expected the sized /tmp tmpfs before the work dir mount:\n%s", config)
+			}
+		})
+	}
+}
+
+func TestExecute_NetworkPolicyUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	result, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+		NetworkPolicy: domain.NetworkAllowlist,
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Status != domain.StatusInternalError || !strings.Contains(result.Stderr, "allowlist") {
+		t.Errorf("expected INTERNAL_ERROR naming the policy, got %s %q", result.Status, result.Stderr)
+	}
+	if _, err := os.Stat(argsFile); !os.IsNotExist(err) {
+		t.Error("expected nsjail not to run")
+	}
+
+	cfg := &nsjailConfig{WorkDir: "/w", NetworkNamespace: "sentinel-0"}
+	if args := strings.Join(cfg.flags("python.cfg"), " "); !strings.Contains(args, "--disable_clone_newnet") {
+		t.Errorf("expected nsjail to keep the run's namespace, got %q", args)
+	}
+}
+
+func TestExecute_PidsLimit(t *testing.T) {
+	// A stand-in for nsjail that records its arguments.
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+
+	for _, tt := range []struct {
+		name  string
+		pids  int
+		want  string
+		unset bool
+	}{
+		{name: "profile default", pids: 0, unset: true},
+		{name: "override", pids: 300, want: "--cgroup_pids_max 300"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+				JobID:         uuid.New(),
+				Language:      domain.LangPython,
+				SourceCode:    "print(1)",
+				TimeLimitMs:   1000,
+				MemoryLimitKB: 65536,
+				PidsLimit:     tt.pids,
+			})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			data, _ := os.ReadFile(argsFile)
+			args := string(data)
+			if tt.unset && strings.Contains(args, "--cgroup_pids_max") {
+				t.Errorf("expected the profile's pids limit, got args %q", args)
+			}
+			if !tt.unset && !strings.Contains(args, tt.want) {
+				t.Errorf("expected %q in args %q", tt.want, args)
+			}
+		})
+	}
+}
+
 func TestBuildNsjailArgs(t *testing.T) {
 	// Verify the arg construction logic by building args manually
 	// and checking expected values
@@ -205,7 +771,7 @@ func TestContextCancellation(t *testing.T) {
 		t.Fatalf("write dummy config: %v", err)
 	}
 
-	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, logger)
+	exe := NewSandboxExecutor("/nonexistent/nsjail", configDir, testLanguages(t), logger)
 
 	// Create an already-cancelled context
 	ctx, cancel := context.WithCancel(context.Background())
@@ -277,3 +843,613 @@ func TestTimeLimitCalculation(t *testing.T) {
 		t.Errorf("nsjail time_limit: got %d, want 6", nsjailTimeLimit)
 	}
 }
+
+func TestExecute_RejectsDisallowedCompilerFlags(t *testing.T) {
+	exe := NewSandboxExecutor("/nonexistent/nsjail", t.TempDir(), testLanguages(t), zap.NewNop())
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangCpp,
+		SourceCode:    "int main() {}",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+		CompilerFlags: []string{"-O0", "-fplugin=/tmp/work/evil.so"},
+	}
+
+	result, err := exe.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != domain.StatusCompilationError {
+		t.Errorf("expected COMPILATION_ERROR, got %s", result.Status)
+	}
+	if !strings.Contains(result.Stderr, "-fplugin") {
+		t.Errorf("expected the rejected flag in stderr, got %q", result.Stderr)
+	}
+}
+
+func TestExecute_RejectsDisallowedEnv(t *testing.T) {
+	exe := NewSandboxExecutor("/nonexistent/nsjail", t.TempDir(), testLanguages(t), zap.NewNop())
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+		Env:           map[string]string{"PYTHONHASHSEED": "0", "LD_PRELOAD": "/tmp/work/evil.so"},
+	}
+
+	result, err := exe.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != domain.StatusInternalError {
+		t.Errorf("expected INTERNAL_ERROR, got %s", result.Status)
+	}
+	if !strings.Contains(result.Stderr, "LD_PRELOAD") {
+		t.Errorf("expected the rejected variable in stderr, got %q", result.Stderr)
+	}
+}
+
+// memBinaryCache is an in-memory repository.BinaryCache.
+type memBinaryCache map[string][]byte
+
+func (m memBinaryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, ok := m[key]
+	return b, ok, nil
+}
+
+func (m memBinaryCache) Put(ctx context.Context, key string, binary []byte) error {
+	m[key] = binary
+	return nil
+}
+
+func TestExecute_BinaryCacheSkipsCompile(t *testing.T) {
+	// A stand-in for nsjail that logs its invocations and "compiles" by
+	// writing the program into the bind-mounted work directory.
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + logPath + `
+for a in "$@"; do case "$a" in *:/tmp/work) work="${a%:/tmp/work}";; esac; done
+case "$*" in *g++*) printf 'binary' > "$work/program";; esac
+exit 0
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	cache := memBinaryCache{}
+	exe.SetBinaryCache(cache)
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangCpp,
+		SourceCode:    "int main() {}",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	}
+	compiles := func() int {
+		data, _ := os.ReadFile(logPath)
+		return strings.Count(string(data), "g++")
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := exe.Execute(context.Background(), req)
+		if err != nil || res.Status != domain.StatusSuccess {
+			t.Fatalf("run %d: expected SUCCESS, got %+v (%v)", i+1, res, err)
+		}
+	}
+	if n := compiles(); n != 1 {
+		t.Errorf("expected one compilation for identical submissions, got %d", n)
+	}
+	if len(cache) != 1 {
+		t.Fatalf("expected one cached binary, got %d", len(cache))
+	}
+
+	req.CompilerFlags = []string{"-O0"}
+	if _, err := exe.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := compiles(); n != 2 {
+		t.Errorf("expected different flags to compile again, got %d compilations", n)
+	}
+}
+
+func TestCompile_ProgramSkipsCompile(t *testing.T) {
+	// As above, but each run also logs the program it finds in place.
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + logPath + `
+for a in "$@"; do case "$a" in *:/tmp/work) work="${a%:/tmp/work}";; esac; done
+case "$*" in *g++*) printf 'binary' > "$work/program";; *) echo "ran $(cat "$work/program")" >> ` + logPath + `;; esac
+exit 0
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangCpp,
+		SourceCode:    "int main() {}",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	}
+	program, failed, err := exe.Compile(context.Background(), req)
+	if err != nil || failed != nil || program == nil {
+		t.Fatalf("expected a compiled program, got %+v, %+v (%v)", program, failed, err)
+	}
+	if string(program.Binary) != "binary" {
+		t.Errorf("expected the compiled binary, got %q", program.Binary)
+	}
+
+	req.Program = program
+	for i := 0; i < 3; i++ {
+		res, err := exe.Execute(context.Background(), req)
+		if err != nil || res.Status != domain.StatusSuccess {
+			t.Fatalf("run %d: expected SUCCESS, got %+v (%v)", i+1, res, err)
+		}
+		if res.CompileTimeMs != 0 {
+			t.Errorf("run %d: expected no compile time for a precompiled program, got %d", i+1, res.CompileTimeMs)
+		}
+	}
+	data, _ := os.ReadFile(logPath)
+	if n := strings.Count(string(data), "g++"); n != 1 {
+		t.Errorf("expected one compilation for every run, got %d", n)
+	}
+	if n := strings.Count(string(data), "ran binary"); n != 3 {
+		t.Errorf("expected every run to find the program, got %d", n)
+	}
+
+	req.Language = domain.LangPython
+	req.Program = nil
+	if program, failed, err := exe.Compile(context.Background(), req); err != nil || failed != nil || program != nil {
+		t.Errorf("expected nothing to compile for Python, got %+v, %+v (%v)", program, failed, err)
+	}
+}
+
+func TestExecute_RegisteredRuntime(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$@" >> ` + logPath + `
+exit 0
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	runtimes := &mock.RuntimeRepository{Runtimes: map[domain.Language]*domain.Runtime{
+		"lua": {
+			Name:         "lua",
+			SourceFile:   "main.lua",
+			NsjailConfig: "lua.cfg",
+			Run:          []string{"/opt/lua/bin/lua", "{source}"},
+			Mounts:       []domain.Mount{{Source: "/opt/runtimes/lua", Target: "/opt/lua"}},
+			Env:          map[string]string{"LUA_PATH": "/opt/lua/share/?.lua"},
+		},
+	}}
+	exe.SetRuntimes(runtimes)
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      "lua",
+		SourceCode:    "print(1)",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	}
+	res, err := exe.Execute(context.Background(), req)
+	if err != nil || res.Status != domain.StatusSuccess {
+		t.Fatalf("expected SUCCESS, got %+v (%v)", res, err)
+	}
+	data, _ := os.ReadFile(logPath)
+	for _, want := range []string{
+		"--config " + filepath.Join(dir, "lua.cfg"),
+		"--bindmount_ro /opt/runtimes/lua:/opt/lua",
+		"--env LUA_PATH=/opt/lua/share/?.lua",
+		"-- /opt/lua/bin/lua /tmp/work/main.lua",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("nsjail args missing %q: %s", want, data)
+		}
+	}
+
+	req.Language = "fortran"
+	res, err = exe.Execute(context.Background(), req)
+	if err != nil || res.Status != domain.StatusInternalError {
+		t.Errorf("unknown language: expected INTERNAL_ERROR, got %+v (%v)", res, err)
+	}
+
+	runtimes.GetRuntimeFn = func(ctx context.Context, name domain.Language) (*domain.Runtime, bool, error) {
+		return nil, false, fmt.Errorf("connection refused")
+	}
+	if _, err := exe.Execute(context.Background(), req); err == nil {
+		t.Error("expected lookup failure to be returned as an error")
+	}
+}
+
+func TestExecute_JobCgroupAccounting(t *testing.T) {
+	// A stand-in for nsjail that fills in the job cgroup it was given the
+	// way the kernel would, then exits with the status under test.
+	dir := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do case "$1" in --cgroupv2_mount) cg="$2";; esac; shift; done
+echo 52428800 > "$cg/memory.peak"
+printf 'low 0\nhigh 0\nmax 2\noom 1\noom_kill %s\n' "$OOM_KILLS" > "$cg/memory.events"
+exit 137
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	cgroups, err := NewJobCgroups(filepath.Join(dir, "cgroup"))
+	if err != nil {
+		t.Fatalf("NewJobCgroups: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	exe.SetJobCgroups(cgroups)
+
+	for _, tt := range []struct {
+		oomKills string
+		want     domain.ExecutionStatus
+	}{
+		{"1", domain.StatusMemoryLimitExceeded},
+		{"0", domain.StatusRuntimeError},
+	} {
+		t.Setenv("OOM_KILLS", tt.oomKills)
+		res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+			JobID:         uuid.New(),
+			Language:      domain.LangPython,
+			SourceCode:    "x = ' ' * 10**9",
+			TimeLimitMs:   1000,
+			MemoryLimitKB: 65536,
+		})
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		if res.Status != tt.want || res.MemoryUsedKB != 51200 {
+			t.Errorf("oom_kill %s: got status %s, memory %d KB; want %s, 51200 KB", tt.oomKills, res.Status, res.MemoryUsedKB, tt.want)
+		}
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	got, err := ParseCPUList("2-4, 7")
+	if err != nil {
+		t.Fatalf("ParseCPUList: %v", err)
+	}
+	if fmt.Sprint(got) != "[2 3 4 7]" {
+		t.Errorf("got %v, want [2 3 4 7]", got)
+	}
+	for _, bad := range []string{"", "a", "4-2", "1,1", "-1"} {
+		if _, err := ParseCPUList(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestExecute_CPUPinning(t *testing.T) {
+	// A stand-in for nsjail that records the core its job cgroup was
+	// pinned to.
+	dir := t.TempDir()
+	out := filepath.Join(dir, "cpus")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do case "$1" in --cgroupv2_mount) cg="$2";; esac; shift; done
+cat "$cg/cpuset.cpus" >> ` + out + `
+echo >> ` + out + `
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	cgroups, err := NewJobCgroups(filepath.Join(dir, "cgroup"))
+	if err != nil {
+		t.Fatalf("NewJobCgroups: %v", err)
+	}
+	if err := cgroups.EnableCPUSet(); err != nil {
+		t.Fatalf("EnableCPUSet: %v", err)
+	}
+	pinning, err := NewCPUPinning("3")
+	if err != nil {
+		t.Fatalf("NewCPUPinning: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	exe.SetJobCgroups(cgroups)
+	exe.SetCPUPinning(pinning)
+
+	req := &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	}
+	// The single core is released after each run, so both get it.
+	for i := 0; i < 2; i++ {
+		if _, err := exe.Execute(context.Background(), req); err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+	}
+	if data, _ := os.ReadFile(out); string(data) != "3\n3\n" {
+		t.Errorf("pinned CPUs = %q, want both runs on 3", data)
+	}
+
+	// With the core taken, a run waits for it until its context ends.
+	cpu, _ := pinning.acquire(context.Background())
+	defer pinning.release(cpu)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := exe.Execute(ctx, req); err == nil || !strings.Contains(err.Error(), "free CPU") {
+		t.Errorf("expected the run to give up waiting for a CPU, got %v", err)
+	}
+}
+
+func TestExecute_IOLimits(t *testing.T) {
+	// A stand-in for nsjail that records its job cgroup's io.max.
+	dir := t.TempDir()
+	out := filepath.Join(dir, "io.max")
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do case "$1" in --cgroupv2_mount) cg="$2";; esac; shift; done
+cat "$cg/io.max" > ` + out + `
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	cgroups, err := NewJobCgroups(filepath.Join(dir, "cgroup"))
+	if err != nil {
+		t.Fatalf("NewJobCgroups: %v", err)
+	}
+	if err := cgroups.EnableIO(); err != nil {
+		t.Fatalf("EnableIO: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	exe.SetJobCgroups(cgroups)
+	exe.SetIOLimits(newIOLimits("8:0", IOLimitConfig{WriteMBps: 20, WriteIOPS: 500}))
+
+	_, err = exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := "8:0 rbps=max wbps=20971520 riops=max wiops=500"
+	if data, _ := os.ReadFile(out); string(data) != want {
+		t.Errorf("io.max = %q, want %q", data, want)
+	}
+}
+
+func TestExecute_CPUTimeLimit(t *testing.T) {
+	// A stand-in for nsjail that records its RLIMIT_CPU and reports the CPU
+	// time under test through the job cgroup.
+	dir := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do case "$1" in --cgroupv2_mount) cg="$2";; --rlimit_cpu) echo "$2" > "$RLIMIT_FILE";; esac; shift; done
+printf 'usage_usec %s\nuser_usec 0\nsystem_usec 0\n' "$CPU_USEC" > "$cg/cpu.stat"
+exit 0
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	cgroups, err := NewJobCgroups(filepath.Join(dir, "cgroup"))
+	if err != nil {
+		t.Fatalf("NewJobCgroups: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+	exe.SetJobCgroups(cgroups)
+	rlimitFile := filepath.Join(dir, "rlimit")
+	t.Setenv("RLIMIT_FILE", rlimitFile)
+
+	for _, tt := range []struct {
+		name       string
+		cpuLimitMs int
+		cpuUsec    string
+		wantRlimit string
+		want       domain.ExecutionStatus
+	}{
+		{"within limit", 1500, "1200000", "2", domain.StatusSuccess},
+		{"over limit below rlimit", 1500, "1700000", "2", domain.StatusTimeout},
+		{"wall limit applies", 0, "2500000", "3", domain.StatusSuccess},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CPU_USEC", tt.cpuUsec)
+			res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+				JobID:          uuid.New(),
+				Language:       domain.LangPython,
+				SourceCode:     "print(1)",
+				TimeLimitMs:    3000,
+				CPUTimeLimitMs: tt.cpuLimitMs,
+				MemoryLimitKB:  65536,
+			})
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			data, _ := os.ReadFile(rlimitFile)
+			if got := strings.TrimSpace(string(data)); got != tt.wantRlimit {
+				t.Errorf("--rlimit_cpu = %q, want %q", got, tt.wantRlimit)
+			}
+			if res.Status != tt.want {
+				t.Errorf("status = %s, want %s", res.Status, tt.want)
+			}
+			if want, _ := strconv.Atoi(tt.cpuUsec); res.CPUTimeUsedMs != want/1000 {
+				t.Errorf("CPUTimeUsedMs = %d, want %d", res.CPUTimeUsedMs, want/1000)
+			}
+		})
+	}
+}
+
+// diskNsjail writes a stand-in for nsjail that fills the bind-mounted work
+// dir with $WRITE_KB of data, failing like the program would on ENOSPC.
+func diskNsjail(t *testing.T, dir string) string {
+	t.Helper()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do case "$1" in --bindmount) work="${2%%:*}";; esac; shift; done
+dd if=/dev/zero of="$work/out.bin" bs=1024 count="$WRITE_KB" 2>/dev/null
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	return nsjail
+}
+
+func TestExecute_ReportsDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	exe := NewSandboxExecutor(diskNsjail(t, dir), dir, testLanguages(t), zap.NewNop())
+	t.Setenv("WRITE_KB", "300")
+
+	res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   3000,
+		MemoryLimitKB: 65536,
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if res.Status != domain.StatusSuccess {
+		t.Fatalf("status = %s, want SUCCESS", res.Status)
+	}
+	// The written file plus the source and stdin.
+	if res.DiskUsedKB < 300 || res.DiskUsedKB > 320 {
+		t.Errorf("DiskUsedKB = %d, want about 300", res.DiskUsedKB)
+	}
+}
+
+func TestExecute_WorkDirQuota(t *testing.T) {
+	quota, err := NewWorkDirQuota(1)
+	if err != nil {
+		t.Skipf("cannot mount tmpfs here: %v", err)
+	}
+	dir := t.TempDir()
+	exe := NewSandboxExecutor(diskNsjail(t, dir), dir, testLanguages(t), zap.NewNop())
+	exe.SetWorkDirQuota(quota)
+	t.Setenv("WRITE_KB", "4096")
+
+	res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   3000,
+		MemoryLimitKB: 65536,
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if res.Status != domain.StatusRuntimeError {
+		t.Errorf("status = %s, want RUNTIME_ERROR from the failed write", res.Status)
+	}
+	if res.DiskUsedKB == 0 || res.DiskUsedKB > 1024 {
+		t.Errorf("DiskUsedKB = %d, want at most the 1024 KB quota", res.DiskUsedKB)
+	}
+}
+
+func TestExecute_CollectsArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do case "$1" in --bindmount) work="${2%%:*}";; esac; shift; done
+printf 'answer=42\n' > "$work/result.txt"
+dd if=/dev/zero of="$work/big.bin" bs=1024 count=1100 2>/dev/null
+ln -s /etc/passwd "$work/link.txt"
+mkfifo "$work/pipe"
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+
+	res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(1)",
+		TimeLimitMs:   3000,
+		MemoryLimitKB: 65536,
+		OutputFiles:   []string{"result.txt", "big.bin", "link.txt", "pipe", "missing.txt", "../stdin.txt"},
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(res.Artifacts) != 2 {
+		t.Fatalf("collected %d artifacts, want result.txt and big.bin: %+v", len(res.Artifacts), res.Artifacts)
+	}
+	if a := res.Artifacts[0]; a.Name != "result.txt" || string(a.Content) != "answer=42\n" || a.Truncated {
+		t.Errorf("result.txt = %+v", a)
+	}
+	if a := res.Artifacts[1]; a.Name != "big.bin" || len(a.Content) != maxArtifactBytes || a.SizeBytes != 1100*1024 || !a.Truncated {
+		t.Errorf("big.bin: name %q, %d bytes of %d, truncated %v", a.Name, len(a.Content), a.SizeBytes, a.Truncated)
+	}
+}
+
+func TestExecute_InteractiveStreams(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+while read line; do echo "got $line"; [ "$line" = quit ] && exit 0; done
+echo eof
+`
+	nsjail := filepath.Join(dir, "nsjail")
+	if err := os.WriteFile(nsjail, []byte(script), 0755); err != nil {
+		t.Fatalf("write fake nsjail: %v", err)
+	}
+	exe := NewSandboxExecutor(nsjail, dir, testLanguages(t), zap.NewNop())
+
+	run := func(stream io.Reader) (*domain.ExecutionResult, string, error) {
+		var live bytes.Buffer
+		res, err := exe.Execute(context.Background(), &domain.ExecutionRequest{
+			JobID:         uuid.New(),
+			Language:      domain.LangPython,
+			SourceCode:    "print(1)",
+			Stdin:         "a\n",
+			TimeLimitMs:   3000,
+			MemoryLimitKB: 65536,
+			StdinStream:   stream,
+			LiveOutput:    &live,
+		})
+		return res, live.String(), err
+	}
+
+	// The submitted stdin comes first, then the stream until it ends.
+	res, live, err := run(strings.NewReader("b\n"))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if want := "got a\ngot b\neof\n"; res.Stdout != want || live != want {
+		t.Errorf("stdout %q, live output %q, want %q", res.Stdout, live, want)
+	}
+
+	// A stream that never ends must not outlive the program.
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go pw.Write([]byte("quit\n"))
+	done := make(chan *domain.ExecutionResult)
+	go func() {
+		res, _, err := run(pr)
+		if err != nil {
+			res = &domain.ExecutionResult{Stderr: err.Error()}
+		}
+		done <- res
+	}()
+	select {
+	case res := <-done:
+		if res.Status != domain.StatusSuccess || res.Stdout != "got a\ngot quit\n" {
+			t.Errorf("open stream: status %s, stdout %q", res.Status, res.Stdout)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Execute waited for the stream to end")
+	}
+}
@@ -0,0 +1,267 @@
+// Package judge contains the output-checking logic used to turn a program's
+// stdout into a verdict.
+package judge
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TokenKind is the inferred type of a whitespace-separated output token.
+type TokenKind int
+
+const (
+	KindText TokenKind = iota
+	KindInteger
+	KindFloat
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case KindInteger:
+		return "integer"
+	case KindFloat:
+		return "float"
+	default:
+		return "text"
+	}
+}
+
+var (
+	integerRe = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	// Decimal or scientific notation only — hex floats and digit separators
+	// accepted by strconv are deliberately not treated as numbers.
+	floatRe = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+)
+
+// InferKind classifies a token as an integer, a float (including scientific
+// notation, nan and inf) or plain text.
+func InferKind(tok string) TokenKind {
+	switch {
+	case integerRe.MatchString(tok):
+		return KindInteger
+	case floatRe.MatchString(tok), isSpecialFloat(tok):
+		return KindFloat
+	default:
+		return KindText
+	}
+}
+
+// isSpecialFloat reports whether tok spells NaN or infinity, optionally
+// signed ("-nan" is what glibc's printf emits for negative NaN).
+func isSpecialFloat(tok string) bool {
+	switch strings.ToLower(trimSign(tok)) {
+	case "nan", "inf", "infinity":
+		return true
+	}
+	return false
+}
+
+func trimSign(tok string) string {
+	if tok != "" && (tok[0] == '+' || tok[0] == '-') {
+		return tok[1:]
+	}
+	return tok
+}
+
+// Tolerance bounds how far a floating point token may deviate from the
+// expected value. A token is accepted if it is within either bound.
+type Tolerance struct {
+	// Abs is the maximum absolute difference |actual - expected|.
+	Abs float64
+	// Rel is the maximum difference relative to |expected|.
+	Rel float64
+}
+
+// DefaultTolerance accepts answers within 1e-6 absolute or relative error,
+// the usual contract for competitive-programming float output.
+var DefaultTolerance = Tolerance{Abs: 1e-6, Rel: 1e-6}
+
+// Comparator compares program output token by token.
+type Comparator struct {
+	Tolerance Tolerance
+}
+
+// NewComparator creates a Comparator with the given tolerance.
+func NewComparator(tol Tolerance) *Comparator {
+	return &Comparator{Tolerance: tol}
+}
+
+// Mismatch describes the first difference found between two outputs.
+type Mismatch struct {
+	// Index is the zero-based token position; -1 for a token count mismatch.
+	Index    int
+	Expected string
+	Actual   string
+	Reason   string
+}
+
+func (m *Mismatch) Error() string {
+	if m.Index < 0 {
+		return m.Reason
+	}
+	return fmt.Sprintf("token %d: expected %q, got %q (%s)", m.Index+1, m.Expected, m.Actual, m.Reason)
+}
+
+// Compare checks actual against expected, ignoring all whitespace differences.
+// It returns nil when the outputs match, otherwise the first mismatch.
+func (c *Comparator) Compare(expected, actual string) *Mismatch {
+	exp := strings.Fields(expected)
+	act := strings.Fields(actual)
+
+	n := len(exp)
+	if len(act) < n {
+		n = len(act)
+	}
+	for i := 0; i < n; i++ {
+		if ok, reason := c.CompareTokens(exp[i], act[i]); !ok {
+			return &Mismatch{Index: i, Expected: exp[i], Actual: act[i], Reason: reason}
+		}
+	}
+
+	if len(exp) != len(act) {
+		return &Mismatch{
+			Index:  -1,
+			Reason: fmt.Sprintf("expected %d tokens, got %d", len(exp), len(act)),
+		}
+	}
+	return nil
+}
+
+// CompareMode selects how CompareOutput matches outputs. The values must
+// match the API's domain.CompareMode.
+type CompareMode string
+
+const (
+	// CompareExact requires byte-for-byte equal output.
+	CompareExact CompareMode = "exact"
+	// CompareTrailingWhitespace ignores whitespace at the end of each line
+	// and blank lines at the end of the output.
+	CompareTrailingWhitespace CompareMode = "trailing_whitespace"
+	// CompareTokens is Compare.
+	CompareTokens CompareMode = "tokens"
+)
+
+// CompareOutput checks actual against expected in the given mode, returning
+// nil when they match. An empty or unknown mode compares tokens.
+func (c *Comparator) CompareOutput(mode CompareMode, expected, actual string) *Mismatch {
+	switch mode {
+	case CompareExact:
+		if expected == actual {
+			return nil
+		}
+		i := 0
+		for i < len(expected) && i < len(actual) && expected[i] == actual[i] {
+			i++
+		}
+		return &Mismatch{Index: -1, Reason: fmt.Sprintf("output differs at byte %d", i+1)}
+	case CompareTrailingWhitespace:
+		return compareLines(trimmedLines(expected), trimmedLines(actual))
+	default:
+		return c.Compare(expected, actual)
+	}
+}
+
+// trimmedLines splits s into lines without trailing whitespace, dropping
+// blank lines at the end.
+func trimmedLines(s string) []string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r\f\v")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func compareLines(expected, actual []string) *Mismatch {
+	for i := 0; i < len(expected) && i < len(actual); i++ {
+		if expected[i] != actual[i] {
+			return &Mismatch{Index: -1, Reason: fmt.Sprintf("line %d: expected %q, got %q", i+1, expected[i], actual[i])}
+		}
+	}
+	if len(expected) != len(actual) {
+		return &Mismatch{Index: -1, Reason: fmt.Sprintf("expected %d lines, got %d", len(expected), len(actual))}
+	}
+	return nil
+}
+
+// CompareTokens compares a single pair of tokens using the kind inferred from
+// both. It returns whether they match and, if not, a short reason.
+func (c *Comparator) CompareTokens(expected, actual string) (bool, string) {
+	if expected == actual {
+		return true, ""
+	}
+
+	ek, ak := InferKind(expected), InferKind(actual)
+	switch {
+	case ek == KindText || ak == KindText:
+		return false, "text differs"
+	case ek == KindInteger && ak == KindInteger:
+		if equalIntegers(expected, actual) {
+			return true, ""
+		}
+		return false, "integer differs"
+	default:
+		return c.compareFloats(expected, actual)
+	}
+}
+
+func (c *Comparator) compareFloats(expected, actual string) (bool, string) {
+	e, err := parseFloat(expected)
+	if err != nil {
+		return false, "invalid expected number"
+	}
+	a, err := parseFloat(actual)
+	if err != nil {
+		return false, "invalid number"
+	}
+
+	switch {
+	case math.IsNaN(e) || math.IsNaN(a):
+		if math.IsNaN(e) && math.IsNaN(a) {
+			return true, ""
+		}
+		return false, "nan mismatch"
+	case math.IsInf(e, 0) || math.IsInf(a, 0):
+		if e == a {
+			return true, ""
+		}
+		return false, "infinity mismatch"
+	}
+
+	diff := math.Abs(a - e)
+	if diff <= c.Tolerance.Abs || diff <= c.Tolerance.Rel*math.Abs(e) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("difference %g exceeds tolerance", diff)
+}
+
+// parseFloat parses a token already classified as numeric.
+func parseFloat(tok string) (float64, error) {
+	if strings.EqualFold(trimSign(tok), "nan") {
+		return math.NaN(), nil
+	}
+	v, err := strconv.ParseFloat(tok, 64)
+	if err != nil {
+		// Values that overflow float64 still compare sensibly as ±Inf.
+		if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+			return v, nil
+		}
+		return 0, err
+	}
+	return v, nil
+}
+
+// equalIntegers compares arbitrarily large integer tokens, so "+007", "7"
+// and "-0", "0" are considered equal.
+func equalIntegers(a, b string) bool {
+	x, okX := new(big.Int).SetString(strings.TrimPrefix(a, "+"), 10)
+	y, okY := new(big.Int).SetString(strings.TrimPrefix(b, "+"), 10)
+	return okX && okY && x.Cmp(y) == 0
+}
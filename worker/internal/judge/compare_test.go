@@ -0,0 +1,223 @@
+package judge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInferKind(t *testing.T) {
+	tests := []struct {
+		tok  string
+		want TokenKind
+	}{
+		{"0", KindInteger},
+		{"42", KindInteger},
+		{"-17", KindInteger},
+		{"+5", KindInteger},
+		{"0007", KindInteger},
+		{"123456789012345678901234567890", KindInteger},
+		{"3.14", KindFloat},
+		{"-0.5", KindFloat},
+		{".5", KindFloat},
+		{"5.", KindFloat},
+		{"1e9", KindFloat},
+		{"1E-9", KindFloat},
+		{"-2.5e+10", KindFloat},
+		{"nan", KindFloat},
+		{"NaN", KindFloat},
+		{"-nan", KindFloat},
+		{"inf", KindFloat},
+		{"-Inf", KindFloat},
+		{"+infinity", KindFloat},
+		{"hello", KindText},
+		{"YES", KindText},
+		{"0x1p-2", KindText},
+		{"1_000", KindText},
+		{"1e", KindText},
+		{"e5", KindText},
+		{".", KindText},
+		{"-", KindText},
+		{"1.2.3", KindText},
+		{"nanx", KindText},
+		{"--5", KindText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tok, func(t *testing.T) {
+			if got := InferKind(tt.tok); got != tt.want {
+				t.Errorf("InferKind(%q) = %s, want %s", tt.tok, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		tol      Tolerance
+		expected string
+		actual   string
+		want     bool
+	}{
+		// Exact and text
+		{"identical text", DefaultTolerance, "YES", "YES", true},
+		{"text case differs", DefaultTolerance, "YES", "yes", false},
+		{"text vs number", DefaultTolerance, "YES", "1", false},
+		{"number vs text", DefaultTolerance, "1", "one", false},
+
+		// Integers
+		{"equal integers", DefaultTolerance, "42", "42", true},
+		{"different integers", DefaultTolerance, "42", "43", false},
+		{"leading plus", DefaultTolerance, "7", "+7", true},
+		{"leading zeros", DefaultTolerance, "7", "007", true},
+		{"negative zero", DefaultTolerance, "0", "-0", true},
+		{"big integers equal", DefaultTolerance, "123456789012345678901234567890", "123456789012345678901234567890", true},
+		{"big integers differ in last digit", DefaultTolerance, "123456789012345678901234567890", "123456789012345678901234567891", false},
+		{"integers never use tolerance", Tolerance{Abs: 10}, "100", "105", false},
+
+		// Integer vs float mixes compare numerically
+		{"integer expected, float actual", DefaultTolerance, "3", "3.0000001", true},
+		{"float expected, integer actual", DefaultTolerance, "2.0000000", "2", true},
+		{"integer expected, float too far", DefaultTolerance, "3", "3.1", false},
+
+		// Absolute tolerance
+		{"within abs", Tolerance{Abs: 1e-6}, "0.000000", "0.0000009", true},
+		{"outside abs", Tolerance{Abs: 1e-6}, "0.000000", "0.000002", false},
+		{"abs boundary", Tolerance{Abs: 0.5}, "1.0", "1.5", true},
+		{"negative values", Tolerance{Abs: 1e-3}, "-1.0005", "-1.0", true},
+
+		// Relative tolerance
+		{"within rel", Tolerance{Rel: 1e-6}, "1000000000", "1000000500.0", true},
+		{"outside rel", Tolerance{Rel: 1e-6}, "1000000000", "1000002000.0", false},
+		{"rel with zero expected needs abs", Tolerance{Rel: 1e-6}, "0.0", "1e-12", false},
+		{"either bound suffices", Tolerance{Abs: 1e-9, Rel: 1e-3}, "1000.0", "1000.5", true},
+		{"zero tolerance exact value", Tolerance{}, "0.5", "0.50", true},
+		{"zero tolerance different value", Tolerance{}, "0.5", "0.5000001", false},
+
+		// Scientific notation
+		{"sci vs decimal", DefaultTolerance, "1e-3", "0.001", true},
+		{"decimal vs sci", DefaultTolerance, "12345.6", "1.23456E4", true},
+		{"sci with plus exponent", DefaultTolerance, "2.5e+3", "2500", true},
+		{"sci mismatch", DefaultTolerance, "1e10", "1e11", false},
+		{"tiny sci values within abs", DefaultTolerance, "1e-20", "3e-20", true},
+
+		// NaN
+		{"nan equals nan", DefaultTolerance, "nan", "nan", true},
+		{"nan case-insensitive", DefaultTolerance, "nan", "NaN", true},
+		{"signed nan", DefaultTolerance, "nan", "-nan", true},
+		{"nan vs number", DefaultTolerance, "nan", "0", false},
+		{"number vs nan", DefaultTolerance, "1.5", "nan", false},
+
+		// Infinity and overflow
+		{"inf equals inf", DefaultTolerance, "inf", "Infinity", true},
+		{"negative inf", DefaultTolerance, "-inf", "-INF", true},
+		{"inf sign mismatch", DefaultTolerance, "inf", "-inf", false},
+		{"inf vs large number", Tolerance{Rel: 1}, "inf", "1e308", false},
+		{"overflow compares as inf", DefaultTolerance, "inf", "1e400", true},
+		{"negative overflow", DefaultTolerance, "-inf", "-1e999", true},
+
+		// Forms strconv would accept but judges should not
+		{"hex float is text", DefaultTolerance, "0.25", "0x1p-2", false},
+		{"digit separators are text", DefaultTolerance, "1000", "1_000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparator(tt.tol)
+			got, reason := c.CompareTokens(tt.expected, tt.actual)
+			if got != tt.want {
+				t.Errorf("CompareTokens(%q, %q) = %v (%s), want %v", tt.expected, tt.actual, got, reason, tt.want)
+			}
+			if !got && reason == "" {
+				t.Error("expected a reason for a mismatch")
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  string
+		actual    string
+		wantMatch bool
+		wantIndex int
+	}{
+		{"identical", "1 2 3\n", "1 2 3\n", true, 0},
+		{"whitespace insensitive", "1 2\n3\n", "1\t2   3", true, 0},
+		{"trailing newlines", "3.14159\n", "3.1415900001\n\n\n", true, 0},
+		{"both empty", "", "\n  \n", true, 0},
+		{"mixed tokens", "Case #1: 0.333333 YES", "Case #1: 0.3333333333 YES", true, 0},
+		{"first token differs", "1 2 3", "0 2 3", false, 0},
+		{"middle token differs", "1 2.5 3", "1 2.6 3", false, 1},
+		{"missing token", "1 2 3", "1 2", false, -1},
+		{"extra token", "1 2", "1 2 3", false, -1},
+		{"differing token reported before count", "1 2 3", "1 9", false, 1},
+		{"empty actual", "42", "", false, -1},
+	}
+
+	c := NewComparator(DefaultTolerance)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := c.Compare(tt.expected, tt.actual)
+			if tt.wantMatch {
+				if m != nil {
+					t.Fatalf("expected match, got mismatch: %v", m)
+				}
+				return
+			}
+			if m == nil {
+				t.Fatal("expected mismatch, got match")
+			}
+			if m.Index != tt.wantIndex {
+				t.Errorf("mismatch index = %d, want %d (%v)", m.Index, tt.wantIndex, m)
+			}
+			if m.Error() == "" {
+				t.Error("expected non-empty mismatch description")
+			}
+		})
+	}
+}
+
+func TestComparator_CompareOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      CompareMode
+		expected  string
+		actual    string
+		wantMatch bool
+	}{
+		{"exact equal", CompareExact, "1 2\n", "1 2\n", true},
+		{"exact missing newline", CompareExact, "1 2\n", "1 2", false},
+		{"exact float", CompareExact, "0.5", "0.50", false},
+		{"trailing spaces", CompareTrailingWhitespace, "a\nb\n", "a  \r\nb\t\n\n", true},
+		{"leading spaces differ", CompareTrailingWhitespace, "a\nb", "a\n b", false},
+		{"line break differs", CompareTrailingWhitespace, "a b", "a\nb", false},
+		{"extra line", CompareTrailingWhitespace, "a", "a\nb", false},
+		{"tokens", CompareTokens, "a b\n0.5", "a\nb 0.5000000001", true},
+		{"empty mode compares tokens", "", "1  2", "1 2", true},
+	}
+
+	c := NewComparator(DefaultTolerance)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := c.CompareOutput(tt.mode, tt.expected, tt.actual)
+			if tt.wantMatch && m != nil {
+				t.Fatalf("expected match, got mismatch: %v", m)
+			}
+			if !tt.wantMatch && (m == nil || m.Error() == "") {
+				t.Fatalf("expected a described mismatch, got %v", m)
+			}
+		})
+	}
+}
+
+func TestMismatch_Error(t *testing.T) {
+	m := NewComparator(DefaultTolerance).Compare("1 2", "1 3")
+	if m == nil {
+		t.Fatal("expected mismatch")
+	}
+	if !strings.Contains(m.Error(), "token 2") {
+		t.Errorf("expected 1-based token position in %q", m.Error())
+	}
+}
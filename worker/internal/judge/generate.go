@@ -0,0 +1,87 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+// Limits for running a problem's input generator. They are fixed rather than
+// taken from the submission, whose limits are meant for the solution.
+const (
+	generatorTimeLimitMs    = 10000
+	generatorMemoryLimitKB  = 524288   // 512 MB
+	generatorMaxOutputBytes = 64 << 20 // 64 MB
+)
+
+// SetInputCache enables caching of generated inputs. Without a cache every
+// generated case runs the generator on each submission.
+func (j *Judge) SetInputCache(cache repository.InputCache) {
+	j.inputs = cache
+}
+
+// caseInputs returns the input of every case, running the generator for
+// cases that have a seed. A generator that fails is a problem-setup error,
+// reported as an error rather than a verdict on the submission.
+func (j *Judge) caseInputs(ctx context.Context, req *domain.ExecutionRequest, data *domain.TestData) ([]string, error) {
+	inputs := make([]string, len(data.Cases))
+	for i, tc := range data.Cases {
+		if tc.GeneratorSeed == nil {
+			inputs[i] = tc.Input
+			continue
+		}
+		if data.Generator == nil {
+			return nil, fmt.Errorf("test case %d has a generator seed but the problem has no generator", tc.Ordinal)
+		}
+		input, err := j.generate(ctx, req, data.Generator, *tc.GeneratorSeed)
+		if err != nil {
+			return nil, fmt.Errorf("test case %d: %w", tc.Ordinal, err)
+		}
+		inputs[i] = input
+	}
+	return inputs, nil
+}
+
+// generate returns the generator's output for seed, from the cache when
+// possible.
+func (j *Judge) generate(ctx context.Context, req *domain.ExecutionRequest, gen *domain.Generator, seed int64) (string, error) {
+	if j.inputs != nil {
+		input, ok, err := j.inputs.Get(ctx, gen.Hash, seed)
+		if err != nil {
+			j.logger.Warn("Failed to read cached input", zap.Error(err), zap.String("generator", gen.Hash), zap.Int64("seed", seed))
+		} else if ok {
+			return input, nil
+		}
+	}
+
+	res, err := j.executor.Execute(ctx, &domain.ExecutionRequest{
+		JobID:          req.JobID,
+		Language:       gen.Language,
+		SourceCode:     gen.SourceCode,
+		Args:           []string{strconv.FormatInt(seed, 10)},
+		TimeLimitMs:    generatorTimeLimitMs,
+		MemoryLimitKB:  generatorMemoryLimitKB,
+		MaxOutputBytes: generatorMaxOutputBytes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("run generator: %w", err)
+	}
+	if res.Status != domain.StatusSuccess {
+		return "", fmt.Errorf("generator with seed %d finished with %s: %s", seed, res.Status, res.Stderr)
+	}
+	if res.StdoutTruncated {
+		return "", fmt.Errorf("generator with seed %d produced more than %d bytes", seed, generatorMaxOutputBytes)
+	}
+
+	if j.inputs != nil {
+		if err := j.inputs.Put(ctx, gen.Hash, seed, res.Stdout); err != nil {
+			j.logger.Warn("Failed to cache generated input", zap.Error(err), zap.String("generator", gen.Hash), zap.Int64("seed", seed))
+		}
+	}
+	return res.Stdout, nil
+}
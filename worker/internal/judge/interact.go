@@ -0,0 +1,119 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// Limits for running a problem's interactor. Its time limit follows the
+// submission's, with grace to deliver its verdict after the submission ends.
+const (
+	interactorMemoryLimitKB = 524288 // 512 MB
+	interactorTimeGraceMs   = 1000
+	interactorMaxMessage    = 1024
+)
+
+// interactorReject is the exit code an interactor rejects the submission
+// with; it accepts with 0, and anything else is a fault of the interactor.
+const interactorReject = 1
+
+// interact runs the submission and the interactor side by side, each one's
+// stdout feeding the other's stdin. The interactor first reads a line with
+// the byte length of the case input, then the input itself, then whatever
+// the submission writes. A submission that exceeded its time or memory limit,
+// or was stopped by a kill pattern, keeps that verdict, since the interactor
+// then only sees its output end. A
+// rejection by the interactor comes next, ahead of a crash it may have caused
+// by closing the pipe. An interactor that fails any other way is at fault,
+// giving INTERNAL_ERROR; one that accepts leaves the submission's own status,
+// ACCEPTED if it exited cleanly. Time is accounted for the pair:
+// wall-clock time is the longer of the two runs and CPU time their sum.
+func (j *Judge) interact(ctx context.Context, inter *domain.Interactor, tc domain.TestCase, req *domain.ExecutionRequest) (*domain.ExecutionResult, domain.TestCaseResult, error) {
+	userIn, interOut := io.Pipe()
+	interIn, userOut := io.Pipe()
+
+	input := req.Stdin
+	userReq := *req
+	userReq.Stdin = ""
+	userReq.StdinStream = userIn
+	userReq.LiveOutput = userOut
+
+	interReq := &domain.ExecutionRequest{
+		JobID:         req.JobID,
+		Language:      inter.Language,
+		SourceCode:    inter.SourceCode,
+		Stdin:         fmt.Sprintf("%d\n%s", len(input), input),
+		StdinStream:   interIn,
+		LiveOutput:    interOut,
+		TimeLimitMs:   req.TimeLimitMs + interactorTimeGraceMs,
+		MemoryLimitKB: interactorMemoryLimitKB,
+	}
+
+	var interRes *domain.ExecutionResult
+	var interErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		interRes, interErr = j.executor.Execute(ctx, interReq)
+		// The submission now reads EOF, and its writes fail.
+		interOut.Close()
+		interIn.Close()
+	}()
+	res, err := j.executor.Execute(ctx, &userReq)
+	userOut.Close()
+	userIn.Close()
+	<-done
+
+	if err != nil {
+		return nil, domain.TestCaseResult{}, err
+	}
+	if interErr != nil {
+		return nil, domain.TestCaseResult{}, fmt.Errorf("run interactor: %w", interErr)
+	}
+	if interRes.Status == domain.StatusCompilationError {
+		return nil, domain.TestCaseResult{}, fmt.Errorf("interactor failed to compile: %s", interRes.Stderr)
+	}
+
+	combined := *res
+	combined.TimeUsedMs = max(res.TimeUsedMs, interRes.TimeUsedMs)
+	combined.CPUTimeUsedMs = res.CPUTimeUsedMs + interRes.CPUTimeUsedMs
+
+	cr := domain.TestCaseResult{
+		Ordinal:       tc.Ordinal,
+		Status:        res.Status,
+		TimeUsedMs:    combined.TimeUsedMs,
+		MemoryUsedKB:  res.MemoryUsedKB,
+		CPUTimeUsedMs: combined.CPUTimeUsedMs,
+		DiskUsedKB:    res.DiskUsedKB,
+		Message:       res.Violation,
+	}
+	switch {
+	case res.Status == domain.StatusCompilationError,
+		res.Status == domain.StatusTimeout,
+		res.Status == domain.StatusMemoryLimitExceeded,
+		res.Status == domain.StatusOutputViolation:
+		// Reported as is; a compilation error ends the whole job.
+	case interRes.Status == domain.StatusRuntimeError && interRes.ExitCode == interactorReject:
+		cr.Status = domain.StatusWrongAnswer
+		cr.Message = interactorMessage(interRes.Stderr)
+	case interRes.Status != domain.StatusSuccess:
+		cr.Status = domain.StatusInternalError
+		cr.Message = fmt.Sprintf("interactor finished with %s (exit code %d)", interRes.Status, interRes.ExitCode)
+	case res.Status == domain.StatusSuccess:
+		cr.Status = domain.StatusAccepted
+	}
+	return &combined, cr, nil
+}
+
+// interactorMessage is the interactor's stderr, trimmed to fit a case result.
+func interactorMessage(stderr string) string {
+	msg := strings.TrimSpace(stderr)
+	if len(msg) > interactorMaxMessage {
+		msg = msg[:interactorMaxMessage]
+	}
+	return msg
+}
@@ -0,0 +1,384 @@
+package judge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+// Judge runs a submission against every test case of a problem and turns the
+// outputs into per-case and overall verdicts.
+type Judge struct {
+	executor   repository.Executor
+	comparator *Comparator
+	logger     *zap.Logger
+	slots      *slots
+	inputs     repository.InputCache
+	retention  OutputRetention
+
+	// comparators runs problems' own comparators; without it such problems'
+	// cases fail as INTERNAL_ERROR.
+	comparators repository.ComparatorRunner
+}
+
+// NewJudge creates a Judge that executes cases with exec and checks their
+// output with comparator. Cases run one at a time until SetConcurrency is
+// called.
+func NewJudge(exec repository.Executor, comparator *Comparator, logger *zap.Logger) *Judge {
+	return &Judge{
+		executor:   exec,
+		comparator: comparator,
+		logger:     logger,
+		slots:      newSlots(1, nil),
+		retention:  RetainFailing,
+	}
+}
+
+// SetConcurrency lets up to n test cases run at once across every job judged
+// by j, with perLanguage further capping individual languages (0 or absent
+// means no extra cap). n below 1 is treated as 1.
+func (j *Judge) SetConcurrency(n int, perLanguage map[domain.Language]int) {
+	j.slots = newSlots(n, perLanguage)
+}
+
+// SetComparatorRunner lets problems replace the token comparison with a
+// comparator of their own, run by r.
+func (j *Judge) SetComparatorRunner(r repository.ComparatorRunner) {
+	j.comparators = r
+}
+
+// caseRun holds the outcome of one dispatched test case.
+type caseRun struct {
+	res     *domain.ExecutionResult
+	verdict domain.TestCaseResult
+}
+
+// Run executes req once per test case (stdin replaced by the case input, or
+// the generator's output for seeded cases, and limits replaced by any
+// per-case override). Cases are dispatched in order and
+// may run concurrently; results are aggregated in case order afterwards, so
+// the outcome does not depend on which case finished first. Cases ruled out by
+// the problem's termination strategy are reported as SKIPPED, even if they
+// were already running when an earlier case failed.
+// Compiled languages are compiled once, before any case runs, when the
+// executor is a repository.Compiler, and every case runs that binary.
+// A compilation error ends judging immediately since every case would fail the
+// same way. The returned error is reserved for sandbox infrastructure failures.
+func (j *Judge) Run(ctx context.Context, req *domain.ExecutionRequest, data *domain.TestData) (*domain.ExecutionResult, error) {
+	strategy := data.Strategy
+	if strategy == "" {
+		strategy = domain.TerminateRunAll
+	}
+
+	var program *domain.CompiledProgram
+	var timings domain.Timings
+	if compiler, ok := j.executor.(repository.Compiler); ok {
+		var compileErr *domain.ExecutionResult
+		var err error
+		compileStart := time.Now()
+		program, compileErr, err = compiler.Compile(ctx, req)
+		timings.CompileMs = int(time.Since(compileStart).Milliseconds())
+		if err != nil {
+			return nil, err
+		}
+		if compileErr != nil {
+			compileErr.Timings.CompileMs = timings.CompileMs
+			compileErr.TestDataVersion = data.Version
+			compileErr.TerminationStrategy = strategy
+			return compileErr, nil
+		}
+	}
+
+	inputs, err := j.caseInputs(ctx, req, data)
+	if err != nil {
+		return nil, err
+	}
+
+	caseReqs := make([]domain.ExecutionRequest, len(data.Cases))
+	for i, tc := range data.Cases {
+		caseReqs[i] = *req
+		caseReqs[i].Stdin = inputs[i]
+		caseReqs[i].Program = program
+		caseReqs[i].KillPatterns = data.KillPatterns
+		if tc.TimeLimitMs > 0 {
+			caseReqs[i].TimeLimitMs = tc.TimeLimitMs
+		}
+		if tc.MemoryLimitKB > 0 {
+			caseReqs[i].MemoryLimitKB = tc.MemoryLimitKB
+		}
+	}
+
+	runs, compileErr, err := j.dispatch(ctx, strategy, data, caseReqs)
+	if err != nil {
+		return nil, err
+	}
+	if compileErr != nil {
+		compileErr.Timings.CompileMs += timings.CompileMs
+		compileErr.TestDataVersion = data.Version
+		compileErr.TerminationStrategy = strategy
+		return compileErr, nil
+	}
+
+	overall := &domain.ExecutionResult{
+		Status:              domain.StatusAccepted,
+		TestDataVersion:     data.Version,
+		TestResults:         make([]domain.TestCaseResult, 0, len(data.Cases)),
+		TerminationStrategy: strategy,
+		Timings:             timings,
+	}
+	if program != nil {
+		overall.CompileTimeMs = program.CompileTimeMs
+	}
+
+	var firstFailure *domain.ExecutionResult
+	var last *domain.ExecutionResult
+	failedSubtasks := make(map[int]bool)
+	partial := partialSubtasks(data)
+
+	for i, tc := range data.Cases {
+		caseReq := &caseReqs[i]
+		if skip(strategy, tc, firstFailure != nil, failedSubtasks) {
+			overall.TestResults = append(overall.TestResults, domain.TestCaseResult{
+				Ordinal:       tc.Ordinal,
+				Status:        domain.StatusSkipped,
+				Subtask:       tc.Subtask,
+				TimeLimitMs:   caseReq.TimeLimitMs,
+				MemoryLimitKB: caseReq.MemoryLimitKB,
+			})
+			continue
+		}
+
+		// dispatch only leaves a case unrun when an earlier case failed,
+		// which the check above already turned into SKIPPED.
+		res, caseResult := runs[i].res, runs[i].verdict
+		overall.TestResults = append(overall.TestResults, caseResult)
+
+		if res.TimeUsedMs > overall.TimeUsedMs {
+			overall.TimeUsedMs = res.TimeUsedMs
+		}
+		if res.MemoryUsedKB > overall.MemoryUsedKB {
+			overall.MemoryUsedKB = res.MemoryUsedKB
+		}
+		if res.CPUTimeUsedMs > overall.CPUTimeUsedMs {
+			overall.CPUTimeUsedMs = res.CPUTimeUsedMs
+		}
+		if res.DiskUsedKB > overall.DiskUsedKB {
+			overall.DiskUsedKB = res.DiskUsedKB
+		}
+		// Cases only compile when the executor could not compile up front.
+		if res.CompileTimeMs > overall.CompileTimeMs {
+			overall.CompileTimeMs = res.CompileTimeMs
+		}
+		overall.Timings.WorkdirSetupMs += res.Timings.WorkdirSetupMs
+		overall.Timings.CompileMs += res.Timings.CompileMs
+		overall.Timings.RunMs += res.Timings.RunMs
+		overall.SecurityEvents = append(overall.SecurityEvents, res.SecurityEvents...)
+
+		res.Status = caseResult.Status
+		if caseResult.Status != domain.StatusAccepted {
+			if firstFailure == nil {
+				firstFailure = res
+			}
+			if !partial[tc.Subtask] {
+				failedSubtasks[tc.Subtask] = true
+			}
+		}
+		last = res
+	}
+
+	// Surface the output of the first failing case (or the last case when
+	// everything passed) so users see something actionable.
+	shown := firstFailure
+	if shown == nil {
+		shown = last
+	}
+	if shown != nil {
+		overall.Stdout = shown.Stdout
+		overall.Stderr = shown.Stderr
+		overall.ExitCode = shown.ExitCode
+		overall.Status = shown.Status
+	}
+
+	if total, max, subtasks := score(data, overall.TestResults); subtasks != nil {
+		overall.Score = &total
+		overall.MaxScore = &max
+		overall.SubtaskResults = subtasks
+	}
+
+	j.logger.Debug("Judging completed",
+		zap.String("job_id", req.JobID.String()),
+		zap.String("problem_id", data.ProblemID),
+		zap.Int("test_data_version", data.Version),
+		zap.Int("cases", len(data.Cases)),
+		zap.String("status", string(overall.Status)),
+		zap.String("termination_strategy", string(strategy)),
+	)
+
+	return overall, nil
+}
+
+// dispatch starts the cases in order as sandbox slots free up and waits for
+// them to finish. A case already known to be ruled out by an earlier failure
+// is not started. A compilation error is returned in place of the runs and
+// cancels the cases still in flight.
+func (j *Judge) dispatch(ctx context.Context, strategy domain.TerminationStrategy, data *domain.TestData, reqs []domain.ExecutionRequest) ([]caseRun, *domain.ExecutionResult, error) {
+	cases := data.Cases
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runs := make([]caseRun, len(cases))
+	failures := newFailureTracker(partialSubtasks(data))
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		firstErr   error
+		compileErr *domain.ExecutionResult
+	)
+	abort := func(err error, res *domain.ExecutionResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if res != nil && compileErr == nil {
+			compileErr = res
+		}
+		cancel()
+	}
+
+	for i, tc := range cases {
+		release, err := j.slots.acquire(runCtx, reqs[i].Language)
+		if err != nil {
+			break
+		}
+		if runCtx.Err() != nil {
+			release()
+			break
+		}
+		if failures.rulesOut(strategy, i, tc.Subtask) {
+			release()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, tc domain.TestCase) {
+			defer wg.Done()
+			defer release()
+
+			var (
+				res     *domain.ExecutionResult
+				verdict domain.TestCaseResult
+				err     error
+			)
+			if data.Interactor != nil {
+				res, verdict, err = j.interact(runCtx, data.Interactor, tc, &reqs[i])
+			} else {
+				res, err = j.executor.Execute(runCtx, &reqs[i])
+			}
+			if err != nil {
+				abort(fmt.Errorf("test case %d: %w", tc.Ordinal, err), nil)
+				return
+			}
+			if res.Status == domain.StatusCompilationError {
+				abort(nil, res)
+				return
+			}
+
+			if data.Interactor == nil {
+				verdict = j.verdict(runCtx, data.Comparator, tc, reqs[i].Stdin, res)
+			}
+			verdict.Subtask = tc.Subtask
+			verdict.TimeLimitMs = reqs[i].TimeLimitMs
+			verdict.MemoryLimitKB = reqs[i].MemoryLimitKB
+			j.retainOutput(&verdict, res.Stdout, reqs[i].Debug)
+			runs[i] = caseRun{res: res, verdict: verdict}
+			if verdict.Status != domain.StatusAccepted {
+				failures.record(i, tc.Subtask)
+			}
+		}(i, tc)
+	}
+	wg.Wait()
+
+	switch {
+	case compileErr != nil:
+		return nil, compileErr, nil
+	case firstErr != nil:
+		return nil, nil, firstErr
+	case ctx.Err() != nil:
+		return nil, nil, fmt.Errorf("judging interrupted: %w", ctx.Err())
+	}
+	return runs, nil, nil
+}
+
+// skip reports whether strategy rules out running tc given the failures seen
+// so far.
+func skip(strategy domain.TerminationStrategy, tc domain.TestCase, failed bool, failedSubtasks map[int]bool) bool {
+	switch strategy {
+	case domain.TerminateFirstFailure:
+		return failed
+	case domain.TerminatePerSubtask:
+		return tc.Subtask > 0 && failedSubtasks[tc.Subtask]
+	}
+	return false
+}
+
+// verdict maps one execution to a per-case result, comparing output only for
+// runs that exited cleanly. cmp, when set, does the comparing.
+func (j *Judge) verdict(ctx context.Context, cmp *domain.Comparator, tc domain.TestCase, input string, res *domain.ExecutionResult) domain.TestCaseResult {
+	cr := domain.TestCaseResult{
+		Ordinal:       tc.Ordinal,
+		Status:        res.Status,
+		TimeUsedMs:    res.TimeUsedMs,
+		MemoryUsedKB:  res.MemoryUsedKB,
+		CPUTimeUsedMs: res.CPUTimeUsedMs,
+		DiskUsedKB:    res.DiskUsedKB,
+		Message:       res.Violation,
+	}
+	if res.Status != domain.StatusSuccess {
+		return cr
+	}
+	if cmp != nil {
+		return j.customVerdict(ctx, cmp, cr, tc, input, res)
+	}
+	if m := j.comparator.Compare(tc.ExpectedOutput, res.Stdout); m != nil {
+		cr.Status = domain.StatusWrongAnswer
+		cr.Message = m.Error()
+		return cr
+	}
+	cr.Status = domain.StatusAccepted
+	return cr
+}
+
+// customVerdict checks a case's output with the problem's comparator. A
+// comparator that fails, or one this worker cannot run, fails the case as
+// INTERNAL_ERROR: the submission is not at fault.
+func (j *Judge) customVerdict(ctx context.Context, cmp *domain.Comparator, cr domain.TestCaseResult, tc domain.TestCase, input string, res *domain.ExecutionResult) domain.TestCaseResult {
+	if j.comparators == nil {
+		cr.Status = domain.StatusInternalError
+		cr.Message = "custom comparators are not available on this worker"
+		return cr
+	}
+	ok, msg, err := j.comparators.Check(ctx, cmp, input, tc.ExpectedOutput, res.Stdout)
+	switch {
+	case err != nil:
+		j.logger.Warn("Custom comparator failed", zap.String("comparator", cmp.Hash), zap.Int("case", tc.Ordinal), zap.Error(err))
+		cr.Status = domain.StatusInternalError
+		cr.Message = "comparator failed: " + err.Error()
+	case ok:
+		cr.Status = domain.StatusAccepted
+	default:
+		cr.Status = domain.StatusWrongAnswer
+		cr.Message = msg
+		if cr.Message == "" {
+			cr.Message = "rejected by the problem's comparator"
+		}
+	}
+	return cr
+}
@@ -0,0 +1,810 @@
+package judge_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/judge"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository/mock"
+)
+
+// echoExecutor returns the case input as stdout, optionally overridden per input.
+func echoExecutor(overrides map[string]*domain.ExecutionResult) *mock.Executor {
+	return &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if res, ok := overrides[req.Stdin]; ok {
+				copied := *res
+				return &copied, nil
+			}
+			return &domain.ExecutionResult{
+				Status:       domain.StatusSuccess,
+				Stdout:       req.Stdin,
+				TimeUsedMs:   len(req.Stdin),
+				MemoryUsedKB: 1024,
+			}, nil
+		},
+	}
+}
+
+func newJudge(exec *mock.Executor) *judge.Judge {
+	return judge.NewJudge(exec, judge.NewComparator(judge.DefaultTolerance), zap.NewNop())
+}
+
+func testData(cases ...[2]string) *domain.TestData {
+	data := &domain.TestData{ProblemID: "sum", Version: 3}
+	for i, c := range cases {
+		data.Cases = append(data.Cases, domain.TestCase{Ordinal: i + 1, Input: c[0], ExpectedOutput: c[1]})
+	}
+	return data
+}
+
+func newRequest() *domain.ExecutionRequest {
+	return &domain.ExecutionRequest{
+		JobID:         uuid.New(),
+		Language:      domain.LangPython,
+		SourceCode:    "print(input())",
+		TimeLimitMs:   1000,
+		MemoryLimitKB: 65536,
+	}
+}
+
+func TestJudge_AllAccepted(t *testing.T) {
+	exec := echoExecutor(nil)
+	data := testData([2]string{"1", "1\n"}, [2]string{"22", "22"}, [2]string{"333", "333"})
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusAccepted {
+		t.Errorf("expected ACCEPTED, got %s", res.Status)
+	}
+	if res.TestDataVersion != 3 {
+		t.Errorf("expected test data version 3, got %d", res.TestDataVersion)
+	}
+	if len(res.TestResults) != 3 {
+		t.Fatalf("expected 3 case results, got %d", len(res.TestResults))
+	}
+	if res.TimeUsedMs != 3 {
+		t.Errorf("expected max time 3ms, got %d", res.TimeUsedMs)
+	}
+	if len(exec.ExecuteCalls) != 3 {
+		t.Errorf("expected 3 executions, got %d", len(exec.ExecuteCalls))
+	}
+	if exec.ExecuteCalls[1].Stdin != "22" {
+		t.Errorf("expected case input as stdin, got %q", exec.ExecuteCalls[1].Stdin)
+	}
+}
+
+func TestJudge_WrongAnswerReportsFirstFailure(t *testing.T) {
+	exec := echoExecutor(map[string]*domain.ExecutionResult{
+		"b": {Status: domain.StatusSuccess, Stdout: "nope"},
+		"c": {Status: domain.StatusRuntimeError, ExitCode: 1, Stderr: "boom"},
+	})
+	data := testData([2]string{"a", "a"}, [2]string{"b", "b"}, [2]string{"c", "c"})
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusWrongAnswer {
+		t.Errorf("expected WRONG_ANSWER, got %s", res.Status)
+	}
+	if res.Stdout != "nope" {
+		t.Errorf("expected output of the first failing case, got %q", res.Stdout)
+	}
+
+	want := []domain.ExecutionStatus{domain.StatusAccepted, domain.StatusWrongAnswer, domain.StatusRuntimeError}
+	for i, w := range want {
+		if res.TestResults[i].Status != w {
+			t.Errorf("case %d: expected %s, got %s", i+1, w, res.TestResults[i].Status)
+		}
+	}
+	if !strings.Contains(res.TestResults[1].Message, "token 1") {
+		t.Errorf("expected mismatch detail on wrong answer, got %q", res.TestResults[1].Message)
+	}
+}
+
+func TestJudge_KillPatterns(t *testing.T) {
+	exec := echoExecutor(map[string]*domain.ExecutionResult{
+		"b": {Status: domain.StatusOutputViolation, ExitCode: -1, Violation: "kill pattern 1, stdout line 2: no prompts"},
+	})
+	data := testData([2]string{"a", "a"}, [2]string{"b", "b"})
+	data.KillPatterns = []domain.KillPattern{{Pattern: regexp.MustCompile("ignore"), Message: "no prompts"}}
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusOutputViolation {
+		t.Errorf("expected OUTPUT_VIOLATION, got %s", res.Status)
+	}
+	if got := res.TestResults[1]; got.Status != domain.StatusOutputViolation || got.Message != "kill pattern 1, stdout line 2: no prompts" {
+		t.Errorf("case 2 = %+v", got)
+	}
+	for _, call := range exec.ExecuteCalls {
+		if len(call.KillPatterns) != 1 {
+			t.Errorf("expected the kill patterns in every case request, got %v", call.KillPatterns)
+		}
+	}
+}
+
+func TestJudge_CompilationErrorStopsImmediately(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			return &domain.ExecutionResult{Status: domain.StatusCompilationError, Stderr: "error: expected ';'", ExitCode: 1}, nil
+		},
+	}
+	data := testData([2]string{"1", "1"}, [2]string{"2", "2"})
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusCompilationError {
+		t.Errorf("expected COMPILATION_ERROR, got %s", res.Status)
+	}
+	if len(exec.ExecuteCalls) != 1 {
+		t.Errorf("expected judging to stop after the compile failure, got %d executions", len(exec.ExecuteCalls))
+	}
+}
+
+func TestJudge_InfrastructureErrorPropagates(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			return nil, errors.New("nsjail exploded")
+		},
+	}
+
+	_, err := newJudge(exec).Run(context.Background(), newRequest(), testData([2]string{"1", "1"}))
+	if err == nil {
+		t.Fatal("expected infrastructure error to propagate")
+	}
+}
+
+func TestJudge_FloatTolerance(t *testing.T) {
+	exec := echoExecutor(map[string]*domain.ExecutionResult{
+		"pi": {Status: domain.StatusSuccess, Stdout: "3.1415926"},
+	})
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), testData([2]string{"pi", "3.14159265358979"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusAccepted {
+		t.Errorf("expected ACCEPTED within tolerance, got %s (%+v)", res.Status, res.TestResults)
+	}
+}
+
+func TestJudge_SubtaskScoring(t *testing.T) {
+	// Case 2 (subtask 1) prints the wrong answer; subtask 2 passes in full.
+	exec := echoExecutor(map[string]*domain.ExecutionResult{
+		"2": {Status: domain.StatusSuccess, Stdout: "20"},
+	})
+	data := testData(
+		[2]string{"0", "0"}, // sample, ungraded
+		[2]string{"1", "1"},
+		[2]string{"2", "2"},
+		[2]string{"3", "3"},
+		[2]string{"4", "4"},
+	)
+	for i, st := range []int{0, 1, 1, 2, 2} {
+		data.Cases[i].Subtask = st
+	}
+	data.Subtasks = []domain.Subtask{{Ordinal: 1, Points: 30}, {Ordinal: 2, Points: 70}}
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusWrongAnswer {
+		t.Errorf("expected WRONG_ANSWER verdict, got %s", res.Status)
+	}
+	if res.Score == nil || *res.Score != 70 {
+		t.Fatalf("expected score 70, got %v", res.Score)
+	}
+	if res.MaxScore == nil || *res.MaxScore != 100 {
+		t.Errorf("expected max score 100, got %v", res.MaxScore)
+	}
+	if len(res.SubtaskResults) != 2 {
+		t.Fatalf("expected 2 subtask results, got %d", len(res.SubtaskResults))
+	}
+	if st := res.SubtaskResults[0]; st.Status != domain.StatusWrongAnswer || st.Score != 0 {
+		t.Errorf("subtask 1: expected WRONG_ANSWER with 0 points, got %+v", st)
+	}
+	if st := res.SubtaskResults[1]; st.Status != domain.StatusAccepted || st.Score != 70 {
+		t.Errorf("subtask 2: expected ACCEPTED with 70 points, got %+v", st)
+	}
+	if res.TestResults[3].Subtask != 2 {
+		t.Errorf("expected case 4 to record subtask 2, got %d", res.TestResults[3].Subtask)
+	}
+}
+
+func TestJudge_PerTestSubtaskScoring(t *testing.T) {
+	// Case 1 fails in a per-test subtask of four cases; case 5 fails in an
+	// all-or-nothing subtask. Per-subtask termination must not cut the
+	// per-test subtask short.
+	exec := echoExecutor(map[string]*domain.ExecutionResult{
+		"1": {Status: domain.StatusSuccess, Stdout: "10"},
+		"5": {Status: domain.StatusSuccess, Stdout: "50"},
+	})
+	data := testData(
+		[2]string{"1", "1"},
+		[2]string{"2", "2"},
+		[2]string{"3", "3"},
+		[2]string{"4", "4"},
+		[2]string{"5", "5"},
+		[2]string{"6", "6"},
+	)
+	for i, st := range []int{1, 1, 1, 1, 2, 2} {
+		data.Cases[i].Subtask = st
+	}
+	data.Subtasks = []domain.Subtask{
+		{Ordinal: 1, Points: 40, Scoring: domain.ScorePerTest},
+		{Ordinal: 2, Points: 60, Scoring: domain.ScoreAllOrNothing},
+	}
+	data.Strategy = domain.TerminatePerSubtask
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Score == nil || *res.Score != 30 {
+		t.Fatalf("expected score 30, got %v", res.Score)
+	}
+	if st := res.SubtaskResults[0]; st.Status != domain.StatusWrongAnswer || st.Score != 30 {
+		t.Errorf("subtask 1: expected WRONG_ANSWER with 30 points, got %+v", st)
+	}
+	if st := res.SubtaskResults[1]; st.Status != domain.StatusWrongAnswer || st.Score != 0 {
+		t.Errorf("subtask 2: expected WRONG_ANSWER with 0 points, got %+v", st)
+	}
+	for i := 1; i < 4; i++ {
+		if got := res.TestResults[i].Status; got != domain.StatusAccepted {
+			t.Errorf("case %d: expected ACCEPTED, got %s", i+1, got)
+		}
+	}
+	if got := res.TestResults[5].Status; got != domain.StatusSkipped {
+		t.Errorf("case 6: expected SKIPPED, got %s", got)
+	}
+}
+
+func TestJudge_NoSubtasksIsUnscored(t *testing.T) {
+	res, err := newJudge(echoExecutor(nil)).Run(context.Background(), newRequest(), testData([2]string{"1", "1"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Score != nil || res.MaxScore != nil || res.SubtaskResults != nil {
+		t.Errorf("expected no score without subtasks, got score=%v max=%v", res.Score, res.MaxScore)
+	}
+}
+
+func TestJudge_PerCaseLimitOverrides(t *testing.T) {
+	exec := echoExecutor(nil)
+	data := testData([2]string{"small", "small"}, [2]string{"huge", "huge"})
+	data.Cases[1].TimeLimitMs = 10000
+	data.Cases[1].MemoryLimitKB = 524288
+
+	req := newRequest()
+	res, err := newJudge(exec).Run(context.Background(), req, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if exec.ExecuteCalls[0].TimeLimitMs != 1000 || exec.ExecuteCalls[0].MemoryLimitKB != 65536 {
+		t.Errorf("expected case 1 to inherit job limits, got %dms/%dKB",
+			exec.ExecuteCalls[0].TimeLimitMs, exec.ExecuteCalls[0].MemoryLimitKB)
+	}
+	if exec.ExecuteCalls[1].TimeLimitMs != 10000 || exec.ExecuteCalls[1].MemoryLimitKB != 524288 {
+		t.Errorf("expected case 2 to run with its overrides, got %dms/%dKB",
+			exec.ExecuteCalls[1].TimeLimitMs, exec.ExecuteCalls[1].MemoryLimitKB)
+	}
+	if req.TimeLimitMs != 1000 {
+		t.Errorf("expected the job request to stay untouched, got %dms", req.TimeLimitMs)
+	}
+
+	if res.TestResults[0].TimeLimitMs != 1000 || res.TestResults[1].TimeLimitMs != 10000 {
+		t.Errorf("expected reported time limits 1000/10000, got %d/%d",
+			res.TestResults[0].TimeLimitMs, res.TestResults[1].TimeLimitMs)
+	}
+	if res.TestResults[1].MemoryLimitKB != 524288 {
+		t.Errorf("expected reported memory limit 524288, got %d", res.TestResults[1].MemoryLimitKB)
+	}
+}
+
+func TestJudge_TerminationStrategies(t *testing.T) {
+	// Case 2 fails; cases 1-2 form subtask 1, case 3 is an ungraded sample
+	// and case 4 belongs to subtask 2.
+	newData := func(strategy domain.TerminationStrategy) *domain.TestData {
+		data := testData([2]string{"a", "a"}, [2]string{"b", "b"}, [2]string{"c", "c"}, [2]string{"d", "d"}, [2]string{"e", "e"})
+		data.Subtasks = []domain.Subtask{{Ordinal: 1, Points: 50}, {Ordinal: 2, Points: 50}}
+		for i, st := range []int{1, 1, 0, 2, 1} {
+			data.Cases[i].Subtask = st
+		}
+		data.Strategy = strategy
+		return data
+	}
+
+	skipped := domain.StatusSkipped
+	accepted := domain.StatusAccepted
+	wrong := domain.StatusWrongAnswer
+
+	tests := []struct {
+		strategy domain.TerminationStrategy
+		want     []domain.ExecutionStatus
+		runs     int
+		score    float64
+	}{
+		{strategy: "", want: []domain.ExecutionStatus{accepted, wrong, accepted, accepted, accepted}, runs: 5, score: 50},
+		{strategy: domain.TerminateRunAll, want: []domain.ExecutionStatus{accepted, wrong, accepted, accepted, accepted}, runs: 5, score: 50},
+		{strategy: domain.TerminateFirstFailure, want: []domain.ExecutionStatus{accepted, wrong, skipped, skipped, skipped}, runs: 2, score: 0},
+		{strategy: domain.TerminatePerSubtask, want: []domain.ExecutionStatus{accepted, wrong, accepted, accepted, skipped}, runs: 4, score: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			exec := echoExecutor(map[string]*domain.ExecutionResult{
+				"b": {Status: domain.StatusSuccess, Stdout: "nope"},
+			})
+			res, err := newJudge(exec).Run(context.Background(), newRequest(), newData(tt.strategy))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			wantStrategy := tt.strategy
+			if wantStrategy == "" {
+				wantStrategy = domain.TerminateRunAll
+			}
+			if res.TerminationStrategy != wantStrategy {
+				t.Errorf("expected strategy %q recorded, got %q", wantStrategy, res.TerminationStrategy)
+			}
+			if res.Status != domain.StatusWrongAnswer {
+				t.Errorf("expected WRONG_ANSWER, got %s", res.Status)
+			}
+			if len(exec.ExecuteCalls) != tt.runs {
+				t.Errorf("expected %d executions, got %d", tt.runs, len(exec.ExecuteCalls))
+			}
+			if len(res.TestResults) != len(tt.want) {
+				t.Fatalf("expected %d case results, got %d", len(tt.want), len(res.TestResults))
+			}
+			for i, w := range tt.want {
+				if res.TestResults[i].Status != w {
+					t.Errorf("case %d: expected %s, got %s", i+1, w, res.TestResults[i].Status)
+				}
+			}
+			if *res.Score != tt.score {
+				t.Errorf("expected score %v, got %v", tt.score, *res.Score)
+			}
+		})
+	}
+}
+
+// slowEchoExecutor echoes like echoExecutor but takes longer for earlier
+// cases, so concurrent runs finish in reverse order, and tracks the peak
+// number of executions in flight.
+func slowEchoExecutor(failing string) (*mock.Executor, *int32) {
+	var inFlight, peak int32
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+
+			delay, _ := strconv.Atoi(req.Stdin)
+			time.Sleep(time.Duration(20-delay) * time.Millisecond)
+
+			out := req.Stdin
+			if req.Stdin == failing {
+				out = "nope"
+			}
+			return &domain.ExecutionResult{Status: domain.StatusSuccess, Stdout: out, TimeUsedMs: delay}, nil
+		},
+	}
+	return exec, &peak
+}
+
+func numberedData(n int) *domain.TestData {
+	cases := make([][2]string, n)
+	for i := range cases {
+		s := strconv.Itoa(i)
+		cases[i] = [2]string{s, s}
+	}
+	return testData(cases...)
+}
+
+func TestJudge_ParallelResultsInCaseOrder(t *testing.T) {
+	exec, peak := slowEchoExecutor("")
+	j := newJudge(exec)
+	j.SetConcurrency(4, nil)
+
+	res, err := j.Run(context.Background(), newRequest(), numberedData(12))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusAccepted {
+		t.Errorf("expected ACCEPTED, got %s", res.Status)
+	}
+	for i, cr := range res.TestResults {
+		if cr.Ordinal != i+1 || cr.Status != domain.StatusAccepted {
+			t.Errorf("result %d: got ordinal %d status %s", i, cr.Ordinal, cr.Status)
+		}
+	}
+	if res.Stdout != "11" {
+		t.Errorf("expected the last case's output, got %q", res.Stdout)
+	}
+	if p := atomic.LoadInt32(peak); p < 2 || p > 4 {
+		t.Errorf("expected between 2 and 4 concurrent executions, peak was %d", p)
+	}
+}
+
+func TestJudge_ParallelRespectsLanguageLimit(t *testing.T) {
+	exec, peak := slowEchoExecutor("")
+	j := newJudge(exec)
+	j.SetConcurrency(8, map[domain.Language]int{domain.LangPython: 2})
+
+	if _, err := j.Run(context.Background(), newRequest(), numberedData(8)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := atomic.LoadInt32(peak); p > 2 {
+		t.Errorf("expected at most 2 concurrent python executions, peak was %d", p)
+	}
+}
+
+func TestJudge_ParallelFirstFailureIsDeterministic(t *testing.T) {
+	for run := 0; run < 5; run++ {
+		exec, _ := slowEchoExecutor("3")
+		j := newJudge(exec)
+		j.SetConcurrency(4, nil)
+		data := numberedData(10)
+		data.Strategy = domain.TerminateFirstFailure
+
+		res, err := j.Run(context.Background(), newRequest(), data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Stdout != "nope" || res.Status != domain.StatusWrongAnswer {
+			t.Fatalf("expected case 4 to be reported, got %s %q", res.Status, res.Stdout)
+		}
+		for i, cr := range res.TestResults {
+			want := domain.StatusAccepted
+			switch {
+			case i == 3:
+				want = domain.StatusWrongAnswer
+			case i > 3:
+				want = domain.StatusSkipped
+			}
+			if cr.Status != want {
+				t.Errorf("run %d case %d: expected %s, got %s", run, i+1, want, cr.Status)
+			}
+		}
+	}
+}
+
+func TestJudge_ParallelCompilationError(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			return &domain.ExecutionResult{Status: domain.StatusCompilationError, Stderr: "error"}, nil
+		},
+	}
+	j := newJudge(exec)
+	j.SetConcurrency(4, nil)
+
+	res, err := j.Run(context.Background(), newRequest(), numberedData(20))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusCompilationError || res.TestDataVersion != 3 {
+		t.Errorf("expected COMPILATION_ERROR on version 3, got %s on %d", res.Status, res.TestDataVersion)
+	}
+	if len(exec.ExecuteCalls) > 4 {
+		t.Errorf("expected dispatch to stop after the compile failure, got %d executions", len(exec.ExecuteCalls))
+	}
+}
+
+func TestJudge_CompilesOnceForEveryCase(t *testing.T) {
+	program := &domain.CompiledProgram{Binary: []byte("\x7fELF"), CompileTimeMs: 250}
+	exec := echoExecutor(nil)
+	exec.CompileFn = func(ctx context.Context, req *domain.ExecutionRequest) (*domain.CompiledProgram, *domain.ExecutionResult, error) {
+		return program, nil, nil
+	}
+	j := newJudge(exec)
+	j.SetConcurrency(4, nil)
+
+	res, err := j.Run(context.Background(), newRequest(), numberedData(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusAccepted {
+		t.Fatalf("expected ACCEPTED, got %s", res.Status)
+	}
+	if len(exec.CompileCalls) != 1 {
+		t.Errorf("expected one compile, got %d", len(exec.CompileCalls))
+	}
+	for _, req := range exec.ExecuteCalls {
+		if req.Program != program {
+			t.Errorf("case %q did not run the compiled program", req.Stdin)
+		}
+	}
+	if res.CompileTimeMs != 250 {
+		t.Errorf("expected the compile time reported once as 250ms, got %d", res.CompileTimeMs)
+	}
+}
+
+func TestJudge_CompileFailureRunsNoCases(t *testing.T) {
+	exec := echoExecutor(nil)
+	exec.CompileFn = func(ctx context.Context, req *domain.ExecutionRequest) (*domain.CompiledProgram, *domain.ExecutionResult, error) {
+		return nil, &domain.ExecutionResult{Status: domain.StatusCompilationError, Stderr: "error", CompileTimeMs: 80}, nil
+	}
+
+	res, err := newJudge(exec).Run(context.Background(), newRequest(), numberedData(3))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != domain.StatusCompilationError || res.TestDataVersion != 3 {
+		t.Errorf("expected COMPILATION_ERROR on version 3, got %s on %d", res.Status, res.TestDataVersion)
+	}
+	if len(exec.ExecuteCalls) != 0 {
+		t.Errorf("expected no case to run, got %d executions", len(exec.ExecuteCalls))
+	}
+}
+
+func TestJudge_GeneratedInputs(t *testing.T) {
+	var generatorRuns int32
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if req.Language == domain.LangCpp {
+				atomic.AddInt32(&generatorRuns, 1)
+				if req.MaxOutputBytes == 0 || len(req.Args) != 1 {
+					t.Errorf("expected generator to get a seed argument and a raised output cap, got %+v", req)
+				}
+				return &domain.ExecutionResult{Status: domain.StatusSuccess, Stdout: "seed-" + req.Args[0]}, nil
+			}
+			return &domain.ExecutionResult{Status: domain.StatusSuccess, Stdout: req.Stdin}, nil
+		},
+	}
+	seed := func(v int64) *int64 { return &v }
+
+	data := testData([2]string{"stored", "stored"}, [2]string{"", "seed-7"}, [2]string{"", "seed-42"})
+	data.Cases[1].GeneratorSeed = seed(7)
+	data.Cases[2].GeneratorSeed = seed(42)
+	data.Generator = &domain.Generator{Language: domain.LangCpp, SourceCode: "int main() {}", Hash: "abc"}
+
+	cache := &mock.InputCache{}
+	j := newJudge(exec)
+	j.SetInputCache(cache)
+
+	for run := 0; run < 2; run++ {
+		res, err := j.Run(context.Background(), newRequest(), data)
+		if err != nil {
+			t.Fatalf("run %d: unexpected error: %v", run, err)
+		}
+		if res.Status != domain.StatusAccepted {
+			t.Errorf("run %d: expected ACCEPTED, got %s", run, res.Status)
+		}
+	}
+	if n := atomic.LoadInt32(&generatorRuns); n != 2 {
+		t.Errorf("expected the generator to run once per seed, ran %d times", n)
+	}
+	if cache.Inputs["abc/42"] != "seed-42" {
+		t.Errorf("expected generated input to be cached, got %v", cache.Inputs)
+	}
+}
+
+func TestJudge_GeneratorFailureIsAnError(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			return &domain.ExecutionResult{Status: domain.StatusRuntimeError, ExitCode: 1, Stderr: "bad seed"}, nil
+		},
+	}
+	seed := int64(1)
+	data := testData([2]string{"", "x"})
+	data.Cases[0].GeneratorSeed = &seed
+	data.Generator = &domain.Generator{Language: domain.LangPython, SourceCode: "raise SystemExit(1)", Hash: "h"}
+
+	_, err := newJudge(exec).Run(context.Background(), newRequest(), data)
+	if err == nil || !strings.Contains(err.Error(), "bad seed") {
+		t.Errorf("expected generator failure to surface as an error, got %v", err)
+	}
+}
+
+func TestJudge_OutputRetention(t *testing.T) {
+	exec := echoExecutor(map[string]*domain.ExecutionResult{
+		"b": {Status: domain.StatusSuccess, Stdout: "nope"},
+	})
+	data := testData([2]string{"a", "a"}, [2]string{"b", "b"})
+
+	tests := []struct {
+		retention judge.OutputRetention
+		want      [2]string // stored output of case 1 and case 2
+	}{
+		{judge.RetainFailing, [2]string{"", "nope"}},
+		{judge.RetainAll, [2]string{"a", "nope"}},
+		{judge.RetainNone, [2]string{"", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.retention), func(t *testing.T) {
+			j := newJudge(exec)
+			j.SetOutputRetention(tt.retention)
+			res, err := j.Run(context.Background(), newRequest(), data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, cr := range res.TestResults {
+				if cr.Output != tt.want[i] {
+					t.Errorf("case %d: expected output %q, got %q", i+1, tt.want[i], cr.Output)
+				}
+				if len(cr.OutputHash) != 64 {
+					t.Errorf("case %d: expected a sha256 hex hash, got %q", i+1, cr.OutputHash)
+				}
+			}
+			if res.TestResults[0].OutputHash == res.TestResults[1].OutputHash {
+				t.Error("expected different hashes for different outputs")
+			}
+		})
+	}
+
+	j := newJudge(exec)
+	j.SetOutputRetention(judge.RetainNone)
+	req := newRequest()
+	req.Debug = true
+	res, err := j.Run(context.Background(), req, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.TestResults[0].Output != "a" {
+		t.Errorf("expected debug runs to keep every output, got %q", res.TestResults[0].Output)
+	}
+
+	if _, err := judge.ParseOutputRetention("sometimes"); err == nil {
+		t.Error("expected an unknown policy to be rejected")
+	}
+}
+
+func TestJudge_CustomComparator(t *testing.T) {
+	// Any permutation of the expected tokens is accepted.
+	runner := &mock.ComparatorRunner{
+		CheckFn: func(ctx context.Context, cmp *domain.Comparator, input, expected, output string) (bool, string, error) {
+			if output == "boom" {
+				return false, "", errors.New("check trapped")
+			}
+			got, want := strings.Fields(output), strings.Fields(expected)
+			sort.Strings(got)
+			sort.Strings(want)
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				return false, "not a permutation", nil
+			}
+			return true, "", nil
+		},
+	}
+	exec := echoExecutor(map[string]*domain.ExecutionResult{
+		"a": {Status: domain.StatusSuccess, Stdout: "3 2 1"},
+		"b": {Status: domain.StatusSuccess, Stdout: "1 1 2"},
+		"c": {Status: domain.StatusSuccess, Stdout: "boom"},
+	})
+	data := testData([2]string{"a", "1 2 3"}, [2]string{"b", "1 2 3"}, [2]string{"c", "1 2 3"})
+	data.Comparator = &domain.Comparator{Wasm: []byte("\x00asm"), Hash: "perm"}
+
+	j := newJudge(exec)
+	j.SetComparatorRunner(runner)
+	res, err := j.Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []domain.ExecutionStatus{domain.StatusAccepted, domain.StatusWrongAnswer, domain.StatusInternalError}
+	for i, w := range want {
+		if res.TestResults[i].Status != w {
+			t.Errorf("case %d: expected %s, got %s", i+1, w, res.TestResults[i].Status)
+		}
+	}
+	if res.TestResults[1].Message != "not a permutation" {
+		t.Errorf("expected the comparator's message, got %q", res.TestResults[1].Message)
+	}
+	if !strings.Contains(res.TestResults[2].Message, "check trapped") {
+		t.Errorf("expected the comparator's failure, got %q", res.TestResults[2].Message)
+	}
+
+	// A worker without a runner cannot judge the problem.
+	res, err = newJudge(echoExecutor(nil)).Run(context.Background(), newRequest(), data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.TestResults[0].Status != domain.StatusInternalError {
+		t.Errorf("expected INTERNAL_ERROR without a comparator runner, got %s", res.TestResults[0].Status)
+	}
+}
+
+// guessExecutor plays both sides of a guessing game: the interactor prompts
+// for a guess and accepts the secret from its case input; the submission
+// answers with the number after "guess " in its source.
+func guessExecutor() *mock.Executor {
+	return &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if req.SourceCode == "interactor" {
+				header, secret, _ := strings.Cut(req.Stdin, "\n")
+				if header != strconv.Itoa(len(secret)) || secret == "crash" {
+					return &domain.ExecutionResult{Status: domain.StatusRuntimeError, ExitCode: 3}, nil
+				}
+				io.WriteString(req.LiveOutput, "guess?\n")
+				guess, err := bufio.NewReader(req.StdinStream).ReadString('\n')
+				if err != nil || strings.TrimSpace(guess) != secret {
+					return &domain.ExecutionResult{
+						Status:   domain.StatusRuntimeError,
+						ExitCode: 1,
+						Stderr:   fmt.Sprintf("expected %s, got %q\n", secret, guess),
+					}, nil
+				}
+				return &domain.ExecutionResult{Status: domain.StatusSuccess, TimeUsedMs: 5, CPUTimeUsedMs: 2}, nil
+			}
+
+			if req.Stdin != "" {
+				return nil, errors.New("the submission was given the case input")
+			}
+			prompt, err := bufio.NewReader(req.StdinStream).ReadString('\n')
+			if err != nil || prompt != "guess?\n" {
+				return &domain.ExecutionResult{Status: domain.StatusRuntimeError, ExitCode: 2}, nil
+			}
+			guess := strings.TrimPrefix(req.SourceCode, "guess ")
+			if guess == "slowly" {
+				return &domain.ExecutionResult{Status: domain.StatusTimeout, TimeUsedMs: 1000}, nil
+			}
+			io.WriteString(req.LiveOutput, guess+"\n")
+			return &domain.ExecutionResult{Status: domain.StatusSuccess, Stdout: guess + "\n", TimeUsedMs: 10, CPUTimeUsedMs: 3}, nil
+		},
+	}
+}
+
+func TestJudge_Interactor(t *testing.T) {
+	data := testData([2]string{"42", ""}, [2]string{"7", ""}, [2]string{"crash", ""})
+	data.Interactor = &domain.Interactor{Language: domain.LangPython, SourceCode: "interactor"}
+
+	req := newRequest()
+	req.SourceCode = "guess 42"
+	res, err := newJudge(guessExecutor()).Run(context.Background(), req, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	accepted := res.TestResults[0]
+	if accepted.Status != domain.StatusAccepted {
+		t.Errorf("case 1: expected ACCEPTED, got %s (%s)", accepted.Status, accepted.Message)
+	}
+	if accepted.TimeUsedMs != 10 || accepted.CPUTimeUsedMs != 5 {
+		t.Errorf("case 1: expected the pair's 10ms wall and 5ms CPU time, got %dms and %dms", accepted.TimeUsedMs, accepted.CPUTimeUsedMs)
+	}
+	if rejected := res.TestResults[1]; rejected.Status != domain.StatusWrongAnswer || !strings.Contains(rejected.Message, "expected 7") {
+		t.Errorf("case 2: expected WRONG_ANSWER with the interactor's message, got %+v", rejected)
+	}
+	if faulty := res.TestResults[2]; faulty.Status != domain.StatusInternalError {
+		t.Errorf("case 3: expected INTERNAL_ERROR for a crashed interactor, got %s", faulty.Status)
+	}
+
+	// The interactor rejects a submission whose output ends, but a timeout
+	// is still reported as such.
+	req.SourceCode = "guess slowly"
+	res, err = newJudge(guessExecutor()).Run(context.Background(), req, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := res.TestResults[0].Status; got != domain.StatusTimeout {
+		t.Errorf("expected TIMEOUT, got %s", got)
+	}
+}
+
@@ -0,0 +1,58 @@
+package judge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// OutputRetention decides which per-case outputs are stored in full with the
+// judged job. Every case that ran records a hash of its output regardless,
+// so a stored verdict can still be checked against a rerun.
+type OutputRetention string
+
+const (
+	// RetainFailing stores the output of cases that were not accepted.
+	RetainFailing OutputRetention = "failing"
+	// RetainAll stores the output of every case that ran.
+	RetainAll OutputRetention = "all"
+	// RetainNone stores only output hashes.
+	RetainNone OutputRetention = "none"
+)
+
+// ParseOutputRetention validates a retention policy name.
+func ParseOutputRetention(s string) (OutputRetention, error) {
+	switch r := OutputRetention(s); r {
+	case RetainFailing, RetainAll, RetainNone:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown output retention %q (want failing, all or none)", s)
+}
+
+// SetOutputRetention chooses which case outputs are kept in full. The
+// default is RetainFailing.
+func (j *Judge) SetOutputRetention(r OutputRetention) {
+	j.retention = r
+}
+
+// retainOutput records the hash of stdout on cr and, if the policy keeps
+// this case, the output itself. Debug runs keep every output.
+func (j *Judge) retainOutput(cr *domain.TestCaseResult, stdout string, debug bool) {
+	sum := sha256.Sum256([]byte(stdout))
+	cr.OutputHash = hex.EncodeToString(sum[:])
+
+	retention := j.retention
+	if debug {
+		retention = RetainAll
+	}
+	switch retention {
+	case RetainAll:
+		cr.Output = stdout
+	case RetainFailing:
+		if cr.Status != domain.StatusAccepted {
+			cr.Output = stdout
+		}
+	}
+}
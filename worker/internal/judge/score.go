@@ -0,0 +1,64 @@
+package judge
+
+import "github.com/Harsh-BH/Sentinel/worker/internal/domain"
+
+// score awards each subtask its points: all of them when every one of its
+// cases was accepted (IOI-style group scoring), or for per-test subtasks the
+// share of its cases that were accepted. A subtask's status is that of its
+// first failing case, or ACCEPTED. Problems without subtasks are not scored;
+// the API guarantees every subtask has at least one case.
+func score(data *domain.TestData, results []domain.TestCaseResult) (total, max float64, subtasks []domain.SubtaskResult) {
+	if len(data.Subtasks) == 0 {
+		return 0, 0, nil
+	}
+
+	index := make(map[int]int, len(data.Subtasks))
+	subtasks = make([]domain.SubtaskResult, len(data.Subtasks))
+	accepted := make([]int, len(data.Subtasks))
+	cases := make([]int, len(data.Subtasks))
+	for i, st := range data.Subtasks {
+		index[st.Ordinal] = i
+		subtasks[i] = domain.SubtaskResult{
+			Ordinal: st.Ordinal,
+			Status:  domain.StatusAccepted,
+			Points:  st.Points,
+		}
+		max += st.Points
+	}
+
+	for _, r := range results {
+		i, ok := index[r.Subtask]
+		if !ok {
+			continue
+		}
+		cases[i]++
+		if r.Status == domain.StatusAccepted {
+			accepted[i]++
+		} else if subtasks[i].Status == domain.StatusAccepted {
+			subtasks[i].Status = r.Status
+		}
+	}
+
+	for i, st := range data.Subtasks {
+		switch {
+		case subtasks[i].Status == domain.StatusAccepted:
+			subtasks[i].Score = st.Points
+		case st.Scoring == domain.ScorePerTest && cases[i] > 0:
+			subtasks[i].Score = st.Points * float64(accepted[i]) / float64(cases[i])
+		}
+		total += subtasks[i].Score
+	}
+	return total, max, subtasks
+}
+
+// partialSubtasks returns the ordinals of the per-test subtasks, whose cases
+// keep running after one fails since each can still score.
+func partialSubtasks(data *domain.TestData) map[int]bool {
+	partial := make(map[int]bool)
+	for _, st := range data.Subtasks {
+		if st.Scoring == domain.ScorePerTest {
+			partial[st.Ordinal] = true
+		}
+	}
+	return partial
+}
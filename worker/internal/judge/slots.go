@@ -0,0 +1,102 @@
+package judge
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// slots bounds how many sandboxes the judge runs at once, overall and per
+// language.
+type slots struct {
+	total      chan struct{}
+	byLanguage map[domain.Language]chan struct{}
+}
+
+func newSlots(n int, perLanguage map[domain.Language]int) *slots {
+	if n < 1 {
+		n = 1
+	}
+	s := &slots{
+		total:      make(chan struct{}, n),
+		byLanguage: make(map[domain.Language]chan struct{}),
+	}
+	for lang, limit := range perLanguage {
+		if limit > 0 {
+			s.byLanguage[lang] = make(chan struct{}, limit)
+		}
+	}
+	return s
+}
+
+// acquire blocks until a slot for lang is free and returns the function that
+// gives it back. The language slot is taken first so a case waiting on its
+// language never holds one of the shared slots.
+func (s *slots) acquire(ctx context.Context, lang domain.Language) (func(), error) {
+	held := make([]chan struct{}, 0, 2)
+	release := func() {
+		for _, ch := range held {
+			<-ch
+		}
+	}
+
+	wanted := []chan struct{}{s.total}
+	if ch, ok := s.byLanguage[lang]; ok {
+		wanted = []chan struct{}{ch, s.total}
+	}
+	for _, ch := range wanted {
+		select {
+		case ch <- struct{}{}:
+			held = append(held, ch)
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// failureTracker records which cases have failed so far while cases run
+// concurrently, so dispatch can avoid starting cases that are bound to be
+// skipped.
+type failureTracker struct {
+	mu        sync.Mutex
+	first     int
+	bySubtask map[int]int
+	partial   map[int]bool
+}
+
+// newFailureTracker tracks failures for cases whose per-test subtasks, listed
+// in partial, are never cut short.
+func newFailureTracker(partial map[int]bool) *failureTracker {
+	return &failureTracker{first: -1, bySubtask: make(map[int]int), partial: partial}
+}
+
+// record notes that the case at index i (in subtask) failed.
+func (t *failureTracker) record(i, subtask int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.first < 0 || i < t.first {
+		t.first = i
+	}
+	if first, ok := t.bySubtask[subtask]; !ok || i < first {
+		t.bySubtask[subtask] = i
+	}
+}
+
+// rulesOut reports whether a failure recorded before index i already means
+// strategy will skip the case at i. Only failures at lower indexes count, so
+// the answer never disagrees with the in-order aggregation in Run.
+func (t *failureTracker) rulesOut(strategy domain.TerminationStrategy, i, subtask int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch strategy {
+	case domain.TerminateFirstFailure:
+		return t.first >= 0 && t.first < i
+	case domain.TerminatePerSubtask:
+		first, ok := t.bySubtask[subtask]
+		return subtask > 0 && !t.partial[subtask] && ok && first < i
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+// Package wasmcheck runs problems' own comparators, compiled to
+// WebAssembly, inside the worker process.
+//
+// A comparator module imports nothing and exports:
+//
+//	memory                     its linear memory
+//	alloc(len i32) i32         returns the offset of len writable bytes
+//	check(input_ptr, input_len, expected_ptr, expected_len,
+//	      output_ptr, output_len i32) i32
+//	                           1 accepts the output, 0 rejects it
+//
+// Every check runs in a fresh instance, so a comparator cannot carry state
+// from one case to the next.
+package wasmcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.ComparatorRunner = (*Runner)(nil)
+
+// maxCompiled caps the compiled modules kept between checks.
+const maxCompiled = 32
+
+// Config bounds each check.
+type Config struct {
+	// MemoryLimitMB caps a comparator's linear memory.
+	MemoryLimitMB int
+	// Timeout caps the time one check may run. wazero has no instruction
+	// metering, so this is the comparator's compute budget.
+	Timeout time.Duration
+}
+
+// Runner checks outputs with WebAssembly comparators.
+type Runner struct {
+	runtime wazero.Runtime
+	timeout time.Duration
+
+	mu       sync.Mutex
+	compiled map[string]wazero.CompiledModule
+}
+
+// New creates a Runner. Close releases its compiled modules.
+func New(ctx context.Context, cfg Config) *Runner {
+	rc := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(cfg.MemoryLimitMB) << 4). // 64 KB pages
+		WithCloseOnContextDone(true)
+	return &Runner{
+		runtime:  wazero.NewRuntimeWithConfig(ctx, rc),
+		timeout:  cfg.Timeout,
+		compiled: make(map[string]wazero.CompiledModule),
+	}
+}
+
+// Close releases the runtime and every compiled module.
+func (r *Runner) Close(ctx context.Context) error {
+	return r.runtime.Close(ctx)
+}
+
+// Check runs cmp's check over one case.
+func (r *Runner) Check(ctx context.Context, cmp *domain.Comparator, input, expected, output string) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	mod, err := r.instantiate(ctx, cmp)
+	if err != nil {
+		return false, "", err
+	}
+	defer mod.Close(context.Background())
+
+	memory := mod.ExportedMemory("memory")
+	alloc := mod.ExportedFunction("alloc")
+	check := mod.ExportedFunction("check")
+	if memory == nil || alloc == nil || check == nil {
+		return false, "", errors.New("comparator must export memory, alloc and check")
+	}
+
+	args := make([]uint64, 0, 6)
+	for _, s := range []string{input, expected, output} {
+		res, err := alloc.Call(ctx, uint64(len(s)))
+		if err != nil {
+			return false, "", callError(ctx, "alloc", err)
+		}
+		ptr := uint32(res[0])
+		if !memory.Write(ptr, []byte(s)) {
+			return false, "", fmt.Errorf("alloc returned %d bytes at %d, outside the comparator's memory", len(s), ptr)
+		}
+		args = append(args, uint64(ptr), uint64(len(s)))
+	}
+	res, err := check.Call(ctx, args...)
+	if err != nil {
+		return false, "", callError(ctx, "check", err)
+	}
+	switch v := int32(uint32(res[0])); v {
+	case 1:
+		return true, "", nil
+	case 0:
+		return false, "", nil
+	default:
+		return false, "", fmt.Errorf("check returned %d, want 1 or 0", v)
+	}
+}
+
+// instantiate creates a fresh instance of cmp, compiling it on first use.
+// Instances are created under the lock so a module is never evicted between
+// its lookup and its instantiation.
+func (r *Runner) instantiate(ctx context.Context, cmp *domain.Comparator) (api.Module, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	compiled, ok := r.compiled[cmp.Hash]
+	if !ok {
+		var err error
+		if compiled, err = r.runtime.CompileModule(ctx, cmp.Wasm); err != nil {
+			return nil, fmt.Errorf("compile comparator: %w", err)
+		}
+		if len(r.compiled) >= maxCompiled {
+			for hash, old := range r.compiled {
+				old.Close(ctx)
+				delete(r.compiled, hash)
+			}
+		}
+		r.compiled[cmp.Hash] = compiled
+	}
+	// Anonymous, so instances of one module can run side by side, and
+	// without start functions: check is the only entry point.
+	mod, err := r.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName("").WithStartFunctions())
+	if err != nil {
+		return nil, fmt.Errorf("instantiate comparator: %w", err)
+	}
+	return mod, nil
+}
+
+// callError reports a failed call, naming the budget when it ran out.
+func callError(ctx context.Context, fn string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s exceeded the comparator time limit", fn)
+	}
+	return fmt.Errorf("%s: %w", fn, err)
+}
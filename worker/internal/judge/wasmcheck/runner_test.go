@@ -0,0 +1,75 @@
+package wasmcheck
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// equalModule accepts output identical to the expected output. It bumps a
+// pointer from offset 1024 in one page of memory for alloc.
+var equalModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x10, 0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x06, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, 0x03, 0x03, 0x02, 0x00, 0x01, 0x05,
+	0x03, 0x01, 0x00, 0x01, 0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b, 0x07, 0x1a, 0x03,
+	0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x00,
+	0x00, 0x05, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x00, 0x01, 0x0a, 0x52, 0x02, 0x11, 0x01, 0x01, 0x7f,
+	0x23, 0x00, 0x21, 0x01, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x20, 0x01, 0x0b, 0x3e, 0x01,
+	0x01, 0x7f, 0x20, 0x03, 0x20, 0x05, 0x47, 0x04, 0x40, 0x41, 0x00, 0x0f, 0x0b, 0x02, 0x40, 0x03,
+	0x40, 0x20, 0x06, 0x20, 0x03, 0x4f, 0x0d, 0x01, 0x20, 0x02, 0x20, 0x06, 0x6a, 0x2d, 0x00, 0x00,
+	0x20, 0x04, 0x20, 0x06, 0x6a, 0x2d, 0x00, 0x00, 0x47, 0x04, 0x40, 0x41, 0x00, 0x0f, 0x0b, 0x20,
+	0x06, 0x41, 0x01, 0x6a, 0x21, 0x06, 0x0c, 0x00, 0x0b, 0x0b, 0x41, 0x01, 0x0b,
+}
+
+// spinModule's check never returns.
+var spinModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x10, 0x02, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x60, 0x06, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x7f, 0x01, 0x7f, 0x03, 0x03, 0x02, 0x00, 0x01, 0x05,
+	0x03, 0x01, 0x00, 0x01, 0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x08, 0x0b, 0x07, 0x1a, 0x03,
+	0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x02, 0x00, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x00,
+	0x00, 0x05, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x00, 0x01, 0x0a, 0x1f, 0x02, 0x11, 0x01, 0x01, 0x7f,
+	0x23, 0x00, 0x21, 0x01, 0x23, 0x00, 0x20, 0x00, 0x6a, 0x24, 0x00, 0x20, 0x01, 0x0b, 0x0b, 0x01,
+	0x01, 0x7f, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x41, 0x01, 0x0b,
+}
+
+func TestRunner_Check(t *testing.T) {
+	ctx := context.Background()
+	r := New(ctx, Config{MemoryLimitMB: 1, Timeout: 200 * time.Millisecond})
+	defer r.Close(ctx)
+	equal := &domain.Comparator{Wasm: equalModule, Hash: "equal"}
+
+	for _, tt := range []struct {
+		expected, output string
+		want             bool
+	}{
+		{"3\n", "3\n", true},
+		{"3\n", "4\n", false},
+		{"3\n", "3", false},
+	} {
+		ok, _, err := r.Check(ctx, equal, "1 2\n", tt.expected, tt.output)
+		if err != nil || ok != tt.want {
+			t.Errorf("Check(%q, %q) = %v, %v; want %v", tt.expected, tt.output, ok, err, tt.want)
+		}
+	}
+
+	// One page holds what alloc hands out; more is out of bounds.
+	if _, _, err := r.Check(ctx, equal, strings.Repeat("x", 70000), "", ""); err == nil {
+		t.Error("expected an error for input beyond the comparator's memory")
+	}
+
+	start := time.Now()
+	_, _, err := r.Check(ctx, &domain.Comparator{Wasm: spinModule, Hash: "spin"}, "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "time limit") {
+		t.Errorf("expected the time limit to stop a spinning comparator, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("spinning comparator ran for %v", elapsed)
+	}
+
+	if _, _, err := r.Check(ctx, &domain.Comparator{Wasm: []byte("not wasm"), Hash: "bad"}, "", "", ""); err == nil {
+		t.Error("expected an error for an invalid module")
+	}
+}
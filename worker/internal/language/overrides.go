@@ -0,0 +1,86 @@
+package language
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// TmpfsMount is a size-limited tmpfs mounted into the sandbox, e.g. to
+// resize the /tmp an nsjail profile mounts.
+type TmpfsMount struct {
+	Target string `mapstructure:"target" yaml:"target"`
+	SizeMB int    `mapstructure:"size_mb" yaml:"size_mb"`
+}
+
+// Override is a deployment's additions to one language, kept in a file of
+// its own so that small tweaks need no edits to the vendored registry or
+// nsjail profiles. Lists are appended to the language's; env entries replace
+// variables of the same name.
+type Override struct {
+	Name           domain.Language   `yaml:"name"`
+	Mounts         []domain.Mount    `yaml:"mounts"`
+	Tmpfs          []TmpfsMount      `yaml:"tmpfs"`
+	EnvPassthrough []string          `yaml:"env_passthrough"`
+	Env            map[string]string `yaml:"env"`
+}
+
+// LoadOverrides reads the YAML overrides file at path, a "languages" list
+// like the registry's, and applies it. The file is decoded without viper,
+// which would lowercase the env variable names.
+func (r *Registry) LoadOverrides(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read sandbox overrides: %w", err)
+	}
+	var file struct {
+		Languages []Override `yaml:"languages"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return fmt.Errorf("decode sandbox overrides: %w", err)
+	}
+	return r.ApplyOverrides(file.Languages)
+}
+
+// ApplyOverrides merges overrides into the registry's specs. Nothing is
+// changed unless every merged spec is valid.
+func (r *Registry) ApplyOverrides(overrides []Override) error {
+	merged := make(map[domain.Language]Spec, len(overrides))
+	for _, o := range overrides {
+		spec, ok := merged[o.Name]
+		if !ok {
+			current, known := r.specs[o.Name]
+			if !known {
+				return fmt.Errorf("sandbox overrides for unknown language %q", o.Name)
+			}
+			spec = *current
+		}
+		spec.Mounts = append(slices.Clone(spec.Mounts), o.Mounts...)
+		spec.Tmpfs = append(slices.Clone(spec.Tmpfs), o.Tmpfs...)
+		spec.EnvPassthrough = append(slices.Clone(spec.EnvPassthrough), o.EnvPassthrough...)
+		if len(o.Env) > 0 {
+			env := maps.Clone(spec.Env)
+			if env == nil {
+				env = make(map[string]string, len(o.Env))
+			}
+			maps.Copy(env, o.Env)
+			spec.Env = env
+		}
+		if err := validate(&spec); err != nil {
+			return fmt.Errorf("sandbox overrides for %q: %w", o.Name, err)
+		}
+		merged[o.Name] = spec
+	}
+	for name, spec := range merged {
+		*r.specs[name] = spec
+	}
+	return nil
+}
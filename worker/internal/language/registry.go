@@ -0,0 +1,341 @@
+package language
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// SandboxWorkDir is where the executor mounts the job's work directory in
+// the sandbox, unless it randomizes the path per execution.
+const SandboxWorkDir = "/tmp/work"
+
+// BinaryFile is the name of the compiled program in the job's work directory.
+const BinaryFile = "program"
+
+// flagsPlaceholder is a whole compile-template argument that expands to the
+// submission's compiler flags (zero or more arguments).
+const flagsPlaceholder = "{flags}"
+
+// workDirPlaceholder expands to the sandbox path of the work directory in
+// commands and env values.
+const workDirPlaceholder = "{workdir}"
+
+// Spec describes how to compile and run one language inside nsjail.
+type Spec struct {
+	Name                 domain.Language `mapstructure:"name"`
+	Version              string          `mapstructure:"version"`
+	Compiler             string          `mapstructure:"compiler"`
+	SourceFile           string          `mapstructure:"source_file"`
+	NsjailConfig         string          `mapstructure:"nsjail_config"`
+	SeccompPolicy        string          `mapstructure:"seccomp_policy"`
+	Compile              []string        `mapstructure:"compile"`
+	Run                  []string        `mapstructure:"run"`
+	CompileTimeLimitMs   int             `mapstructure:"compile_time_limit_ms"`
+	CompileMemoryLimitKB int             `mapstructure:"compile_memory_limit_kb"`
+
+	// AllowedFlags lists the compiler flags a submission may request. Flags
+	// are matched exactly; a language without an allowlist accepts none.
+	AllowedFlags []string `mapstructure:"allowed_flags"`
+
+	// AllowedEnv lists the environment variables a submission may set for
+	// its program; a language without an allowlist accepts none.
+	AllowedEnv []string `mapstructure:"allowed_env"`
+
+	// CacheBinary lets the worker reuse the compiled program of an identical
+	// earlier submission instead of compiling again.
+	CacheBinary bool `mapstructure:"cache_binary"`
+
+	// MaxConcurrency caps how many sandboxes of this language the judge runs
+	// at once; 0 leaves only the worker-wide limit.
+	MaxConcurrency int `mapstructure:"max_concurrency"`
+
+	// Mounts are host paths bind-mounted read-only into the sandbox in
+	// addition to what the nsjail profile mounts, e.g. an interpreter tree.
+	Mounts []domain.Mount `mapstructure:"mounts"`
+
+	// Env is set in the sandbox for both phases. Submission variables of the
+	// same name take precedence when running.
+	Env map[string]string `mapstructure:"env"`
+
+	// Tmpfs are size-limited tmpfs mounts added to the profile's; one at
+	// /tmp replaces the profile's /tmp.
+	Tmpfs []TmpfsMount `mapstructure:"tmpfs"`
+
+	// EnvPassthrough names worker environment variables copied into the
+	// sandbox for both phases, e.g. a proxy or license server address.
+	EnvPassthrough []string `mapstructure:"env_passthrough"`
+}
+
+// FromRuntime builds and validates a spec for a runtime registered through
+// the API.
+func FromRuntime(rt *domain.Runtime) (*Spec, error) {
+	spec := &Spec{
+		Name:         rt.Name,
+		Version:      rt.Version,
+		SourceFile:   rt.SourceFile,
+		NsjailConfig: rt.NsjailConfig,
+		Compile:      rt.Compile,
+		Run:          rt.Run,
+		Mounts:       rt.Mounts,
+		Env:          rt.Env,
+	}
+	if err := validate(spec); err != nil {
+		return nil, fmt.Errorf("runtime %q: %w", rt.Name, err)
+	}
+	return spec, nil
+}
+
+// SeccompPolicyFile returns the name of the language's Kafel seccomp policy:
+// seccomp_policy, or else the nsjail profile's name with a .policy extension.
+func (s *Spec) SeccompPolicyFile() string {
+	if s.SeccompPolicy != "" {
+		return s.SeccompPolicy
+	}
+	return strings.TrimSuffix(s.NsjailConfig, path.Ext(s.NsjailConfig)) + ".policy"
+}
+
+// IsCompiled reports whether the language has a separate compile phase.
+func (s *Spec) IsCompiled() bool {
+	return len(s.Compile) > 0
+}
+
+// CompileArgs returns the compile command with placeholders expanded and
+// flags in place of {flags}. Flags must have passed CheckFlags.
+func (s *Spec) CompileArgs(flags []string) []string {
+	return s.CompileArgsAt(SandboxWorkDir, flags)
+}
+
+// CompileArgsAt is CompileArgs for a work directory mounted at workDir.
+func (s *Spec) CompileArgsAt(workDir string, flags []string) []string {
+	args := make([]string, 0, len(s.Compile)+len(flags))
+	for _, arg := range s.expand(s.Compile, workDir) {
+		if arg == flagsPlaceholder {
+			args = append(args, flags...)
+			continue
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// CheckFlags returns an error naming the first flag not in the allowlist.
+func (s *Spec) CheckFlags(flags []string) error {
+	for _, flag := range flags {
+		if !slices.Contains(s.AllowedFlags, flag) {
+			return fmt.Errorf("compiler flag %q is not allowed for %s", flag, s.Name)
+		}
+	}
+	return nil
+}
+
+// CheckEnv returns an error naming the first variable not in the allowlist.
+func (s *Spec) CheckEnv(env map[string]string) error {
+	for _, name := range EnvNames(env) {
+		if !slices.Contains(s.AllowedEnv, name) {
+			return fmt.Errorf("environment variable %q is not allowed for %s", name, s.Name)
+		}
+	}
+	return nil
+}
+
+// EnvNames returns the variable names of env in sorted order, so sandbox
+// command lines are deterministic.
+func EnvNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// RunArgs returns the run command with placeholders expanded.
+func (s *Spec) RunArgs() []string {
+	return s.RunArgsAt(SandboxWorkDir)
+}
+
+// RunArgsAt is RunArgs for a work directory mounted at workDir.
+func (s *Spec) RunArgsAt(workDir string) []string {
+	return s.expand(s.Run, workDir)
+}
+
+// EnvAt returns Env with {workdir} in its values expanded to workDir, for
+// variables such as a build cache that live in the work directory.
+func (s *Spec) EnvAt(workDir string) map[string]string {
+	if len(s.Env) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(s.Env))
+	for name, value := range s.Env {
+		env[name] = strings.ReplaceAll(value, workDirPlaceholder, workDir)
+	}
+	return env
+}
+
+func (s *Spec) expand(tmpl []string, workDir string) []string {
+	r := strings.NewReplacer(
+		"{source}", workDir+"/"+s.SourceFile,
+		"{binary}", workDir+"/"+BinaryFile,
+		workDirPlaceholder, workDir,
+	)
+	args := make([]string, len(tmpl))
+	for i, arg := range tmpl {
+		args[i] = r.Replace(arg)
+	}
+	return args
+}
+
+// Registry is the set of languages the worker can execute, in file order.
+type Registry struct {
+	specs map[domain.Language]*Spec
+	order []*Spec
+}
+
+// NewRegistry validates specs and builds a registry from them.
+func NewRegistry(specs []Spec) (*Registry, error) {
+	reg := &Registry{specs: make(map[domain.Language]*Spec, len(specs))}
+	for i := range specs {
+		spec := &specs[i]
+		if err := validate(spec); err != nil {
+			return nil, fmt.Errorf("language %d (%q): %w", i+1, spec.Name, err)
+		}
+		if _, dup := reg.specs[spec.Name]; dup {
+			return nil, fmt.Errorf("language %q defined twice", spec.Name)
+		}
+		reg.specs[spec.Name] = spec
+		reg.order = append(reg.order, spec)
+	}
+	if len(reg.order) == 0 {
+		return nil, fmt.Errorf("language registry is empty")
+	}
+	return reg, nil
+}
+
+// Load reads the registry from a YAML file.
+func Load(path string) (*Registry, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read language registry: %w", err)
+	}
+	var specs []Spec
+	if err := v.UnmarshalKey("languages", &specs); err != nil {
+		return nil, fmt.Errorf("decode language registry: %w", err)
+	}
+
+	// viper lowercases map keys, so the env variables are read again with
+	// their names as written.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read language registry: %w", err)
+	}
+	var env struct {
+		Languages []struct {
+			Env map[string]string `yaml:"env"`
+		} `yaml:"languages"`
+	}
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("decode language registry: %w", err)
+	}
+	for i := range specs {
+		if i < len(env.Languages) {
+			specs[i].Env = env.Languages[i].Env
+		}
+	}
+	return NewRegistry(specs)
+}
+
+// Lookup returns the spec for lang.
+func (r *Registry) Lookup(lang domain.Language) (*Spec, bool) {
+	spec, ok := r.specs[lang]
+	return spec, ok
+}
+
+// IsCompiled reports whether lang is registered and has a compile phase.
+func (r *Registry) IsCompiled(lang domain.Language) bool {
+	spec, ok := r.specs[lang]
+	return ok && spec.IsCompiled()
+}
+
+// All returns every registered spec in file order.
+func (r *Registry) All() []*Spec {
+	return r.order
+}
+
+// ConcurrencyLimits returns the languages that cap their concurrent sandboxes.
+func (r *Registry) ConcurrencyLimits() map[domain.Language]int {
+	limits := make(map[domain.Language]int)
+	for _, spec := range r.order {
+		if spec.MaxConcurrency > 0 {
+			limits[spec.Name] = spec.MaxConcurrency
+		}
+	}
+	return limits
+}
+
+func validate(s *Spec) error {
+	switch {
+	case s.Name == "":
+		return fmt.Errorf("name is required")
+	case s.SourceFile == "" || strings.ContainsAny(s.SourceFile, "/\\"):
+		return fmt.Errorf("source_file must be a plain file name")
+	case s.NsjailConfig == "" || strings.ContainsAny(s.NsjailConfig, "/\\"):
+		return fmt.Errorf("nsjail_config must be a file name in the sandbox config dir")
+	case strings.ContainsAny(s.SeccompPolicy, "/\\"):
+		return fmt.Errorf("seccomp_policy must be a file name in the policy dir")
+	case len(s.Run) == 0:
+		return fmt.Errorf("run command is required")
+	case s.CompileTimeLimitMs < 0 || s.CompileMemoryLimitKB < 0:
+		return fmt.Errorf("compile limits must not be negative")
+	case s.MaxConcurrency < 0:
+		return fmt.Errorf("max_concurrency must not be negative")
+	case s.CacheBinary && !s.IsCompiled():
+		return fmt.Errorf("cache_binary needs a compile command")
+	case len(s.AllowedFlags) > 0 && !slices.Contains(s.Compile, flagsPlaceholder):
+		return fmt.Errorf("allowed_flags needs a %s argument in the compile command", flagsPlaceholder)
+	}
+	for _, m := range s.Mounts {
+		if !isCleanAbsPath(m.Source) || !isCleanAbsPath(m.Target) || m.Target == "/" {
+			return fmt.Errorf("mount %q -> %q must use clean absolute paths", m.Source, m.Target)
+		}
+		if m.Target == SandboxWorkDir || strings.HasPrefix(m.Target, SandboxWorkDir+"/") {
+			return fmt.Errorf("mount target %q overlaps %s", m.Target, SandboxWorkDir)
+		}
+	}
+	for name, value := range s.Env {
+		if name == "" || strings.ContainsAny(name, "=\x00") || strings.ContainsRune(value, 0) {
+			return fmt.Errorf("invalid env entry %q", name)
+		}
+	}
+	for _, m := range s.Tmpfs {
+		if !isCleanAbsPath(m.Target) || m.Target == "/" {
+			return fmt.Errorf("tmpfs target %q must be a clean absolute path", m.Target)
+		}
+		if m.Target == SandboxWorkDir || strings.HasPrefix(m.Target, SandboxWorkDir+"/") {
+			return fmt.Errorf("tmpfs target %q overlaps %s", m.Target, SandboxWorkDir)
+		}
+		if m.SizeMB <= 0 {
+			return fmt.Errorf("tmpfs %q needs a positive size_mb", m.Target)
+		}
+	}
+	for _, name := range s.EnvPassthrough {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			return fmt.Errorf("invalid env_passthrough name %q", name)
+		}
+	}
+	return nil
+}
+
+// isCleanAbsPath rejects paths nsjail would misread: relative ones, ones
+// with dot segments, and ones containing its src:dst separator.
+func isCleanAbsPath(p string) bool {
+	return path.IsAbs(p) && path.Clean(p) == p && !strings.ContainsAny(p, ":\x00")
+}
@@ -0,0 +1,242 @@
+package language_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+)
+
+func TestLoad_ShippedRegistry(t *testing.T) {
+	reg, err := language.Load("../../../sandbox/languages.yaml")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	tests := []struct {
+		lang     domain.Language
+		compiled bool
+	}{
+		{domain.LangPython, false},
+		{domain.LangCpp, true},
+		{domain.LangGo, true},
+		{domain.LangJS, false},
+		{domain.LangRust, true},
+	}
+	for _, tt := range tests {
+		if _, ok := reg.Lookup(tt.lang); !ok {
+			t.Errorf("%s: not registered", tt.lang)
+			continue
+		}
+		if got := reg.IsCompiled(tt.lang); got != tt.compiled {
+			t.Errorf("%s: IsCompiled() = %v, want %v", tt.lang, got, tt.compiled)
+		}
+	}
+	// Env names keep their case, and the work dir is a placeholder so
+	// randomized sandbox paths find it.
+	goSpec, _ := reg.Lookup(domain.LangGo)
+	if got := goSpec.EnvAt("/tmp/work")["GOCACHE"]; got != "/tmp/work/.gocache" {
+		t.Errorf("go GOCACHE = %q, want /tmp/work/.gocache", got)
+	}
+	if reg.IsCompiled(domain.Language("ruby")) {
+		t.Error("unregistered language must not be compiled")
+	}
+
+	limits := reg.ConcurrencyLimits()
+	if limits[domain.LangRust] != 2 {
+		t.Errorf("expected rust to be capped at 2 concurrent cases, got %d", limits[domain.LangRust])
+	}
+	if _, ok := limits[domain.LangPython]; ok {
+		t.Error("python should have no per-language cap")
+	}
+}
+
+func TestSpec_ExpandsTemplates(t *testing.T) {
+	spec := language.Spec{
+		Name:       "cpp",
+		SourceFile: "code.cpp",
+		Compile:    []string{"/usr/bin/g++", "-o", "{binary}", "{source}"},
+		Run:        []string{"{binary}"},
+	}
+
+	if got, want := spec.CompileArgs(nil), []string{"/usr/bin/g++", "-o", "/tmp/work/program", "/tmp/work/code.cpp"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CompileArgs() = %v, want %v", got, want)
+	}
+	if got, want := spec.RunArgs(), []string{"/tmp/work/program"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RunArgs() = %v, want %v", got, want)
+	}
+	if got, want := spec.RunArgsAt("/tmp/3f9c1a2b"), []string{"/tmp/3f9c1a2b/program"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RunArgsAt() = %v, want %v", got, want)
+	}
+	spec.Env = map[string]string{"GOCACHE": "{workdir}/.gocache", "TZ": "UTC"}
+	if got, want := spec.EnvAt("/tmp/3f9c1a2b"), map[string]string{"GOCACHE": "/tmp/3f9c1a2b/.gocache", "TZ": "UTC"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvAt() = %v, want %v", got, want)
+	}
+	// The template itself must stay untouched.
+	if spec.Compile[2] != "{binary}" {
+		t.Errorf("template was modified: %v", spec.Compile)
+	}
+}
+
+func TestSpec_CompilerFlags(t *testing.T) {
+	spec := language.Spec{
+		Name:         "cpp",
+		SourceFile:   "code.cpp",
+		Compile:      []string{"/usr/bin/g++", "-O2", "{flags}", "-o", "{binary}", "{source}"},
+		AllowedFlags: []string{"-O0", "-g", "-std=c++20"},
+	}
+
+	if err := spec.CheckFlags([]string{"-O0", "-g"}); err != nil {
+		t.Errorf("expected allowed flags to pass, got %v", err)
+	}
+	for _, bad := range [][]string{{"-fplugin=evil.so"}, {"-g", "-O0 -g"}, {"-G"}} {
+		if err := spec.CheckFlags(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+
+	got := spec.CompileArgs([]string{"-O0", "-g"})
+	want := []string{"/usr/bin/g++", "-O2", "-O0", "-g", "-o", "/tmp/work/program", "/tmp/work/code.cpp"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompileArgs() = %v, want %v", got, want)
+	}
+	if got := spec.CompileArgs(nil); len(got) != 5 {
+		t.Errorf("expected {flags} to expand to nothing, got %v", got)
+	}
+
+	interpreted := language.Spec{Name: "python"}
+	if err := interpreted.CheckFlags([]string{"-O"}); err == nil {
+		t.Error("expected a language without an allowlist to reject flags")
+	}
+}
+
+func TestSpec_CheckEnv(t *testing.T) {
+	spec := language.Spec{Name: "python", AllowedEnv: []string{"LANG", "PYTHONHASHSEED"}}
+
+	if err := spec.CheckEnv(map[string]string{"LANG": "C.UTF-8", "PYTHONHASHSEED": "0"}); err != nil {
+		t.Errorf("expected allowed variables to pass, got %v", err)
+	}
+	if err := spec.CheckEnv(map[string]string{"LANG": "C", "LD_PRELOAD": "/tmp/work/evil.so"}); err == nil {
+		t.Error("expected LD_PRELOAD to be rejected")
+	}
+	if err := (&language.Spec{Name: "cpp"}).CheckEnv(map[string]string{"LANG": "C"}); err == nil {
+		t.Error("expected a language without an allowlist to reject variables")
+	}
+
+	got := language.EnvNames(map[string]string{"TZ": "UTC", "LANG": "C", "LC_ALL": "C"})
+	if want := []string{"LANG", "LC_ALL", "TZ"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvNames() = %v, want %v", got, want)
+	}
+}
+
+func TestNewRegistry_Validation(t *testing.T) {
+	valid := language.Spec{Name: "python", SourceFile: "code.py", NsjailConfig: "python.cfg", Run: []string{"python3", "{source}"}}
+
+	tests := []struct {
+		name    string
+		mutate  func(s *language.Spec)
+		dup     bool
+		wantErr string
+	}{
+		{name: "missing name", mutate: func(s *language.Spec) { s.Name = "" }, wantErr: "name"},
+		{name: "source path", mutate: func(s *language.Spec) { s.SourceFile = "../code.py" }, wantErr: "source_file"},
+		{name: "config path", mutate: func(s *language.Spec) { s.NsjailConfig = "/etc/x.cfg" }, wantErr: "nsjail_config"},
+		{name: "policy path", mutate: func(s *language.Spec) { s.SeccompPolicy = "../x.policy" }, wantErr: "seccomp_policy"},
+		{name: "missing run", mutate: func(s *language.Spec) { s.Run = nil }, wantErr: "run"},
+		{name: "negative limit", mutate: func(s *language.Spec) { s.CompileTimeLimitMs = -1 }, wantErr: "negative"},
+		{name: "negative concurrency", mutate: func(s *language.Spec) { s.MaxConcurrency = -1 }, wantErr: "max_concurrency"},
+		{name: "cache without compile", mutate: func(s *language.Spec) { s.Compile = nil; s.CacheBinary = true }, wantErr: "cache_binary"},
+		{name: "flags without placeholder", mutate: func(s *language.Spec) { s.AllowedFlags = []string{"-g"} }, wantErr: "{flags}"},
+		{name: "relative mount", mutate: func(s *language.Spec) { s.Mounts = []domain.Mount{{Source: "opt/lua", Target: "/opt/lua"}} }, wantErr: "mount"},
+		{name: "mount over work dir", mutate: func(s *language.Spec) { s.Mounts = []domain.Mount{{Source: "/opt/lua", Target: "/tmp/work"}} }, wantErr: "/tmp/work"},
+		{name: "duplicate", dup: true, wantErr: "twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := valid
+			if tt.mutate != nil {
+				tt.mutate(&spec)
+			}
+			specs := []language.Spec{spec}
+			if tt.dup {
+				specs = append(specs, valid)
+			}
+			_, err := language.NewRegistry(specs)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := language.NewRegistry(nil); err == nil {
+		t.Error("expected error for empty registry")
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	reg, err := language.Load("../../../sandbox/languages.yaml")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	overrides := `languages:
+  - name: python
+    mounts:
+      - source: /opt/wheels
+        target: /opt/wheels
+    tmpfs:
+      - target: /tmp
+        size_mb: 128
+    env_passthrough: [HTTPS_PROXY]
+    env:
+      PYTHONWARNINGS: ignore
+`
+	if err := os.WriteFile(path, []byte(overrides), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.LoadOverrides(path); err != nil {
+		t.Fatalf("LoadOverrides: %v", err)
+	}
+	py, _ := reg.Lookup(domain.LangPython)
+	if len(py.Mounts) != 1 || py.Mounts[0].Target != "/opt/wheels" {
+		t.Errorf("mounts = %v", py.Mounts)
+	}
+	if len(py.Tmpfs) != 1 || py.Tmpfs[0] != (language.TmpfsMount{Target: "/tmp", SizeMB: 128}) {
+		t.Errorf("tmpfs = %v", py.Tmpfs)
+	}
+	if !reflect.DeepEqual(py.EnvPassthrough, []string{"HTTPS_PROXY"}) {
+		t.Errorf("env_passthrough = %v", py.EnvPassthrough)
+	}
+	if py.Env["PYTHONWARNINGS"] != "ignore" {
+		t.Errorf("env = %v, want the name's case kept", py.Env)
+	}
+	if all := reg.All(); all[0] != py {
+		t.Error("registry order must see the merged spec")
+	}
+
+	for _, tt := range []struct {
+		name     string
+		override language.Override
+		wantErr  string
+	}{
+		{"unknown language", language.Override{Name: "cobol"}, "unknown language"},
+		{"tmpfs over work dir", language.Override{Name: domain.LangPython, Tmpfs: []language.TmpfsMount{{Target: "/tmp/work", SizeMB: 8}}}, "/tmp/work"},
+		{"tmpfs without size", language.Override{Name: domain.LangPython, Tmpfs: []language.TmpfsMount{{Target: "/scratch"}}}, "size_mb"},
+		{"passthrough with value", language.Override{Name: domain.LangPython, EnvPassthrough: []string{"A=b"}}, "env_passthrough"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := reg.ApplyOverrides([]language.Override{{Name: domain.LangCpp, EnvPassthrough: []string{"CCACHE_DIR"}}, tt.override})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if cpp, _ := reg.Lookup(domain.LangCpp); len(cpp.EnvPassthrough) != 0 {
+				t.Error("a rejected override file must not change any language")
+			}
+		})
+	}
+}
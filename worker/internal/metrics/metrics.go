@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// Metrics records what the worker pool and the execute usecase measure.
+// Prometheus exports it on /metrics; other sinks, and Recorder in tests,
+// implement it without the call sites changing.
+type Metrics interface {
+	// ActiveWorkers adds delta to the number of workers running a job.
+	ActiveWorkers(delta int)
+	// Execution counts a finished execution by language and status.
+	Execution(language, status string)
+	// ExecutionDuration records how long an execution took.
+	ExecutionDuration(language string, d time.Duration)
+	// JobSeen marks a combination of tenant tier, language and backend as
+	// having run on this worker.
+	JobSeen(tier, language, backend string)
+	// TierJob counts a finished job by tenant tier and status.
+	TierJob(tier, status string)
+	// TierExecutionTime charges execution time to a tenant tier.
+	TierExecutionTime(tier string, d time.Duration)
+	// ResultStored records the size of a stored result, stdout plus
+	// stderr, by tenant tier and tenant, and whether stdout was truncated.
+	ResultStored(tier, tenant string, size int, truncated bool)
+	// SandboxFailure counts a sandbox infrastructure failure.
+	SandboxFailure()
+	// WorkDirPoolAcquire counts a work directory taken from the pool, or
+	// created on demand when hit is false.
+	WorkDirPoolAcquire(hit bool)
+	// HostUsage reports the sampled usage of a host resource: memory or
+	// disk as a share of 1, or cpu as the load per CPU.
+	HostUsage(resource string, value float64)
+	// PressureRequeue counts a job requeued because resource was over its
+	// limit.
+	PressureRequeue(resource string)
+	// DeadLettered counts a job dead-lettered by failure class.
+	DeadLettered(class string)
+	// Preemption counts a job stopped and requeued for a higher-priority
+	// one, by language.
+	Preemption(language string)
+}
+
+// Prometheus records metrics in the worker's Prometheus collectors.
+type Prometheus struct{}
+
+// Ensure Prometheus implements Metrics.
+var _ Metrics = Prometheus{}
+
+func (Prometheus) ActiveWorkers(delta int) {
+	WorkersActive.Add(float64(delta))
+}
+
+func (Prometheus) Execution(language, status string) {
+	ExecutionsTotal.WithLabelValues(language, status).Inc()
+}
+
+func (Prometheus) ExecutionDuration(language string, d time.Duration) {
+	ExecutionDuration.WithLabelValues(language).Observe(d.Seconds())
+}
+
+func (Prometheus) JobSeen(tier, language, backend string) {
+	JobInfo.WithLabelValues(tier, language, backend).Set(1)
+}
+
+func (Prometheus) TierJob(tier, status string) {
+	TierJobsTotal.WithLabelValues(tier, status).Inc()
+}
+
+func (Prometheus) TierExecutionTime(tier string, d time.Duration) {
+	TierExecutionSeconds.WithLabelValues(tier).Add(d.Seconds())
+}
+
+func (Prometheus) ResultStored(tier, tenant string, size int, truncated bool) {
+	ResultBytes.WithLabelValues(tier).Observe(float64(size))
+	TenantResultBytes.WithLabelValues(tenant).Add(float64(size))
+	TenantResults.WithLabelValues(tenant, strconv.FormatBool(truncated)).Inc()
+}
+
+func (Prometheus) SandboxFailure() {
+	SandboxFailures.Inc()
+}
+
+func (Prometheus) WorkDirPoolAcquire(hit bool) {
+	if hit {
+		WorkDirPoolHits.Inc()
+	} else {
+		WorkDirPoolMisses.Inc()
+	}
+}
+
+func (Prometheus) HostUsage(resource string, value float64) {
+	switch resource {
+	case "memory":
+		HostMemoryUsed.Set(value)
+	case "disk":
+		HostDiskUsed.Set(value)
+	case "cpu":
+		HostLoadPerCPU.Set(value)
+	}
+}
+
+func (Prometheus) PressureRequeue(resource string) {
+	PressureRequeues.WithLabelValues(resource).Inc()
+}
+
+func (Prometheus) DeadLettered(class string) {
+	DeadLetteredJobs.WithLabelValues(class).Inc()
+}
+
+func (Prometheus) Preemption(language string) {
+	Preemptions.WithLabelValues(language).Inc()
+}
@@ -33,6 +33,194 @@ var (
 		},
 	)
 
+	// BinaryCacheHits counts compilations skipped thanks to the binary cache.
+	BinaryCacheHits = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_binary_cache_hits_total",
+			Help: "Total number of compiled binaries served from the cache",
+		},
+		[]string{"language"},
+	)
+
+	// BinaryCacheMisses counts cacheable compilations that had to run.
+	BinaryCacheMisses = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_binary_cache_misses_total",
+			Help: "Total number of cacheable compilations not found in the cache",
+		},
+		[]string{"language"},
+	)
+
+	// JobInfo is an info-style metric set to 1 for every combination of
+	// tenant tier, language and backend that has run on this worker.
+	JobInfo = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sentinel_job_info",
+			Help: "Combinations of tenant tier, language and backend seen by this worker (always 1)",
+		},
+		[]string{"tenant_tier", "language", "backend"},
+	)
+
+	// TierJobsTotal counts finished jobs by tenant tier and status.
+	TierJobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_tenant_tier_jobs_total",
+			Help: "Total number of jobs by tenant tier and status",
+		},
+		[]string{"tier", "status"},
+	)
+
+	// TierExecutionSeconds sums the execution time charged to tenants, by tier.
+	TierExecutionSeconds = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_tenant_tier_execution_seconds_total",
+			Help: "Total execution time in seconds charged to tenants, by tenant tier",
+		},
+		[]string{"tier"},
+	)
+
+	// WorkDirPoolHits counts sandbox work dirs taken from the work dir pool.
+	WorkDirPoolHits = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sentinel_workdir_pool_hits_total",
+			Help: "Total number of sandbox work directories served from the work dir pool",
+		},
+	)
+
+	// WorkDirPoolMisses counts sandbox work dirs created on demand because the pool was empty.
+	WorkDirPoolMisses = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sentinel_workdir_pool_misses_total",
+			Help: "Total number of sandbox work directories created on demand",
+		},
+	)
+
+	// FirecrackerBootSeconds tracks how long microVMs take from launch until
+	// the guest agent starts.
+	FirecrackerBootSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sentinel_firecracker_boot_seconds",
+			Help:    "Time from launching a Firecracker microVM until its guest agent is ready",
+			Buckets: []float64{0.05, 0.1, 0.125, 0.15, 0.2, 0.3, 0.5, 1, 2, 5},
+		},
+		[]string{"language"},
+	)
+
+	// FirecrackerBootFailures counts microVMs that failed to start or boot.
+	FirecrackerBootFailures = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "sentinel_firecracker_boot_failures_total",
+			Help: "Total number of Firecracker microVMs that failed to boot",
+		},
+	)
+
+	// ExecutorSelections counts executions per executor backend.
+	ExecutorSelections = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_executor_selections_total",
+			Help: "Total number of executions per executor backend",
+		},
+		[]string{"backend"},
+	)
+
+	// DeadLetteredJobs counts jobs dead-lettered by the worker, by failure class.
+	DeadLetteredJobs = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_dead_lettered_jobs_total",
+			Help: "Total number of jobs dead-lettered or quarantined by the worker",
+		},
+		[]string{"failure_class"},
+	)
+
+	// BrokerEndpointUp reports whether each configured RabbitMQ endpoint,
+	// by host, was reachable when last tried.
+	BrokerEndpointUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sentinel_rabbitmq_endpoint_up",
+			Help: "Whether a RabbitMQ endpoint was reachable when last dialed or in use (1) or not (0)",
+		},
+		[]string{"endpoint"},
+	)
+
+	// DLQWebhookDeliveries counts dead-letter webhook deliveries by outcome.
+	DLQWebhookDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_dlq_webhook_deliveries_total",
+			Help: "Total number of dead-letter webhook deliveries, by result (delivered, rejected, failed)",
+		},
+		[]string{"result"},
+	)
+
+	// ResultBytes tracks the size of stored results (stdout plus stderr), by
+	// tenant tier.
+	ResultBytes = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sentinel_result_bytes",
+			Help:    "Size of stored execution results (stdout plus stderr) in bytes, by tenant tier",
+			Buckets: prometheus.ExponentialBuckets(256, 4, 8), // 256 B to 4 MB
+		},
+		[]string{"tenant_tier"},
+	)
+
+	// TenantResultBytes sums stored result sizes per tenant, and
+	// TenantResults counts results per tenant by whether stdout was
+	// truncated. Tenants without an assigned tier are reported as "other".
+	TenantResultBytes = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_tenant_result_bytes_total",
+			Help: "Total bytes of stored execution results (stdout plus stderr), by tenant",
+		},
+		[]string{"tenant"},
+	)
+	TenantResults = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_tenant_results_total",
+			Help: "Total number of stored execution results, by tenant and whether stdout was truncated",
+		},
+		[]string{"tenant", "truncated"},
+	)
+
+	// HostMemoryUsed, HostDiskUsed and HostLoadPerCPU are the host usage the
+	// worker's pressure guard last sampled.
+	HostMemoryUsed = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sentinel_host_memory_used_ratio",
+			Help: "Share of host memory in use, as sampled by the pressure guard",
+		},
+	)
+	HostDiskUsed = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sentinel_host_disk_used_ratio",
+			Help: "Share of the work directories' filesystem in use, as sampled by the pressure guard",
+		},
+	)
+	HostLoadPerCPU = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "sentinel_host_load_per_cpu",
+			Help: "1-minute load average divided by the CPU count, as sampled by the pressure guard",
+		},
+	)
+
+	// PressureRequeues counts jobs requeued because the host was over a
+	// pressure limit, by resource.
+	PressureRequeues = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_pressure_requeues_total",
+			Help: "Total number of jobs requeued under host pressure, by resource (memory, disk, cpu)",
+		},
+		[]string{"resource"},
+	)
+
+	// Preemptions counts jobs stopped and requeued for higher-priority
+	// jobs, by language.
+	Preemptions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sentinel_preemptions_total",
+			Help: "Total number of running jobs preempted and requeued for higher-priority jobs, by language",
+		},
+		[]string{"language"},
+	)
+
 	// SandboxFailures counts sandbox infrastructure failures (not user code errors).
 	SandboxFailures = promauto.NewCounter(
 		prometheus.CounterOpts{
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder keeps the metrics recorded through it in memory, so tests can
+// assert on them without reading global collectors. Each recording is kept
+// under the name of the Metrics method and its arguments other than the
+// value, in order; durations are kept in seconds. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	values map[string]float64
+	counts map[string]int
+}
+
+// Ensure Recorder implements Metrics.
+var _ Metrics = (*Recorder)(nil)
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{values: make(map[string]float64), counts: make(map[string]int)}
+}
+
+// Value returns the sum of the values recorded under method and labels, or
+// the last one for JobSeen and HostUsage, which set gauges. Calls without
+// a value, such as counts, record 1.
+func (r *Recorder) Value(method string, labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[recorderKey(method, labels)]
+}
+
+// Count returns how many times method was called with labels.
+func (r *Recorder) Count(method string, labels ...string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[recorderKey(method, labels)]
+}
+
+func recorderKey(method string, labels []string) string {
+	return method + "{" + strings.Join(labels, ",") + "}"
+}
+
+func (r *Recorder) add(value float64, method string, labels ...string) {
+	key := recorderKey(method, labels)
+	r.mu.Lock()
+	r.values[key] += value
+	r.counts[key]++
+	r.mu.Unlock()
+}
+
+func (r *Recorder) set(value float64, method string, labels ...string) {
+	key := recorderKey(method, labels)
+	r.mu.Lock()
+	r.values[key] = value
+	r.counts[key]++
+	r.mu.Unlock()
+}
+
+func (r *Recorder) ActiveWorkers(delta int) {
+	r.add(float64(delta), "ActiveWorkers")
+}
+
+func (r *Recorder) Execution(language, status string) {
+	r.add(1, "Execution", language, status)
+}
+
+func (r *Recorder) ExecutionDuration(language string, d time.Duration) {
+	r.add(d.Seconds(), "ExecutionDuration", language)
+}
+
+func (r *Recorder) JobSeen(tier, language, backend string) {
+	r.set(1, "JobSeen", tier, language, backend)
+}
+
+func (r *Recorder) TierJob(tier, status string) {
+	r.add(1, "TierJob", tier, status)
+}
+
+func (r *Recorder) TierExecutionTime(tier string, d time.Duration) {
+	r.add(d.Seconds(), "TierExecutionTime", tier)
+}
+
+func (r *Recorder) ResultStored(tier, tenant string, size int, truncated bool) {
+	r.add(float64(size), "ResultStored", tier, tenant, strconv.FormatBool(truncated))
+}
+
+func (r *Recorder) SandboxFailure() {
+	r.add(1, "SandboxFailure")
+}
+
+func (r *Recorder) WorkDirPoolAcquire(hit bool) {
+	r.add(1, "WorkDirPoolAcquire", strconv.FormatBool(hit))
+}
+
+func (r *Recorder) HostUsage(resource string, value float64) {
+	r.set(value, "HostUsage", resource)
+}
+
+func (r *Recorder) PressureRequeue(resource string) {
+	r.add(1, "PressureRequeue", resource)
+}
+
+func (r *Recorder) DeadLettered(class string) {
+	r.add(1, "DeadLettered", class)
+}
+
+func (r *Recorder) Preemption(language string) {
+	r.add(1, "Preemption", language)
+}
@@ -2,6 +2,7 @@ package pool
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
 	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
 	"github.com/Harsh-BH/Sentinel/worker/internal/usecase"
 )
 
@@ -19,6 +21,37 @@ type WorkerPool struct {
 	executeUC *usecase.ExecuteJobUsecase
 	logger    *zap.Logger
 	wg        sync.WaitGroup
+
+	// Optional work dir pool started and stopped with the workers.
+	workDirs *WorkDirPool
+
+	// deadLetters, when set, is told about every job the pool dead-letters.
+	deadLetters repository.DeadLetterNotifier
+	// maxDeliveries quarantines messages delivered more often; 0 disables it.
+	maxDeliveries int
+
+	// pressure, when set, holds jobs for up to pressureWait while the host
+	// is over its limits, then requeues them.
+	pressure     *PressureGuard
+	pressureWait time.Duration
+
+	// metrics records the pool's work; Prometheus unless SetMetrics
+	// changes it.
+	metrics metrics.Metrics
+
+	// preemptPriority, when above zero, lets jobs of at least that priority
+	// preempt a running job of lower priority while every worker is busy.
+	// running holds the job each busy worker runs.
+	preemptPriority int
+	mu              sync.Mutex
+	running         map[int]*runningJob
+}
+
+// runningJob is a job a worker is running.
+type runningJob struct {
+	job       *domain.Job
+	started   time.Time
+	preempted bool
 }
 
 // NewWorkerPool creates a new fixed-size worker pool.
@@ -28,26 +61,90 @@ func NewWorkerPool(size int, jobs <-chan *domain.JobMessage, executeUC *usecase.
 		jobs:      jobs,
 		executeUC: executeUC,
 		logger:    logger,
+		metrics:   metrics.Prometheus{},
+
+		running: make(map[int]*runningJob),
 	}
 }
 
+// SetMetrics records the pool's work in m instead of the Prometheus
+// collectors.
+func (p *WorkerPool) SetMetrics(m metrics.Metrics) {
+	p.metrics = m
+}
+
+// SetWorkDirPool ties w's lifetime to the workers': it is filled on Start
+// and drained on Stop. The executor must be set to take directories from it.
+func (p *WorkerPool) SetWorkDirPool(w *WorkDirPool) {
+	p.workDirs = w
+}
+
+// SetDeadLetterNotifier reports dead-lettered and quarantined jobs to their
+// tenants through n.
+func (p *WorkerPool) SetDeadLetterNotifier(n repository.DeadLetterNotifier) {
+	p.deadLetters = n
+}
+
+// SetMaxDeliveries quarantines messages the broker has delivered more than
+// n times: the job is failed and dead-lettered without running. Messages only
+// come back when a worker died holding them, so such a job most likely
+// crashes whoever runs it.
+func (p *WorkerPool) SetMaxDeliveries(n int) {
+	p.maxDeliveries = n
+}
+
+// SetPressureGuard stops workers from starting jobs while g finds the host
+// over its limits. A job is held for up to wait for the pressure to ease and
+// then requeued. Requeues count as deliveries, so wait should keep a job from
+// reaching the delivery limit while pressure lasts.
+func (p *WorkerPool) SetPressureGuard(g *PressureGuard, wait time.Duration) {
+	p.pressure = g
+	p.pressureWait = wait
+}
+
+// SetPreemption lets jobs of priority or higher preempt running jobs of
+// lower priority when every worker is busy: the lowest-priority job is
+// stopped and requeued, to run again from the start, and the worker takes
+// the waiting job. Preemptions do not count toward the delivery limit.
+// Only jobs that have reached the pool can preempt, so the consumer must
+// fetch at least one message more than the pool runs.
+func (p *WorkerPool) SetPreemption(priority int) {
+	p.preemptPriority = priority
+}
+
 // Start launches all worker goroutines. Call Stop to wait for them to finish.
 func (p *WorkerPool) Start(ctx context.Context) {
 	p.logger.Info("Starting worker pool", zap.Int("pool_size", p.size))
+	if p.workDirs != nil {
+		p.workDirs.Start(ctx)
+	}
+	if p.pressure != nil {
+		go p.pressure.watch(ctx)
+	}
+
+	jobs := p.jobs
+	if p.preemptPriority > 0 {
+		work := make(chan *domain.JobMessage)
+		go p.dispatch(ctx, work)
+		jobs = work
+	}
 
 	for i := 0; i < p.size; i++ {
 		p.wg.Add(1)
-		go p.worker(ctx, i)
+		go p.worker(ctx, i, jobs)
 	}
 }
 
 // Stop waits for all workers to finish their current jobs and exit.
 func (p *WorkerPool) Stop() {
 	p.wg.Wait()
+	if p.workDirs != nil {
+		p.workDirs.Stop()
+	}
 	p.logger.Info("Worker pool stopped")
 }
 
-func (p *WorkerPool) worker(ctx context.Context, id int) {
+func (p *WorkerPool) worker(ctx context.Context, id int, jobs <-chan *domain.JobMessage) {
 	defer p.wg.Done()
 	defer func() {
 		if r := recover(); r != nil {
@@ -65,7 +162,7 @@ func (p *WorkerPool) worker(ctx context.Context, id int) {
 		case <-ctx.Done():
 			p.logger.Debug("Worker shutting down", zap.Int("worker_id", id))
 			return
-		case msg, ok := <-p.jobs:
+		case msg, ok := <-jobs:
 			if !ok {
 				p.logger.Debug("Job channel closed", zap.Int("worker_id", id))
 				return
@@ -79,15 +176,30 @@ func (p *WorkerPool) worker(ctx context.Context, id int) {
 				zap.String("language", string(job.Language)),
 			)
 
+			if p.maxDeliveries > 0 && msg.Deliveries > p.maxDeliveries {
+				p.quarantine(ctx, msg)
+				continue
+			}
+
+			if !p.admit(ctx, msg) {
+				continue
+			}
+
 			// Track active workers gauge.
-			metrics.WorkersActive.Inc()
+			p.metrics.ActiveWorkers(1)
 			startTime := time.Now()
 
+			p.started(id, job)
 			isDuplicate, err := p.executeUC.Execute(ctx, job)
-			elapsed := time.Since(startTime).Seconds()
+			p.finished(id)
+			elapsed := time.Since(startTime)
 
-			metrics.WorkersActive.Dec()
+			p.metrics.ActiveWorkers(-1)
 
+			if errors.Is(err, domain.ErrJobPreempted) {
+				p.requeuePreempted(msg)
+				continue
+			}
 			if err != nil {
 				p.logger.Error("Job execution failed",
 					zap.Int("worker_id", id),
@@ -103,9 +215,10 @@ func (p *WorkerPool) worker(ctx context.Context, id int) {
 						zap.Error(nackErr),
 					)
 				}
+				p.deadLettered(ctx, msg, domain.EventJobDeadLettered, domain.FailureClassOf(err))
 
-				metrics.ExecutionsTotal.WithLabelValues(string(job.Language), "error").Inc()
-				metrics.ExecutionDuration.WithLabelValues(string(job.Language)).Observe(elapsed)
+				p.metrics.Execution(string(job.Language), "error")
+				p.metrics.ExecutionDuration(string(job.Language), elapsed)
 				continue
 			}
 
@@ -132,7 +245,197 @@ func (p *WorkerPool) worker(ctx context.Context, id int) {
 				)
 			}
 
-			metrics.ExecutionDuration.WithLabelValues(string(job.Language)).Observe(elapsed)
+			p.metrics.ExecutionDuration(string(job.Language), elapsed)
+		}
+	}
+}
+
+// dispatch hands the jobs the consumer delivers to the workers, first
+// preempting a running job for one of high enough priority that finds
+// every worker busy.
+func (p *WorkerPool) dispatch(ctx context.Context, work chan<- *domain.JobMessage) {
+	for {
+		var msg *domain.JobMessage
+		select {
+		case <-ctx.Done():
+			return
+		case m, ok := <-p.jobs:
+			if !ok {
+				close(work)
+				return
+			}
+			msg = m
+		}
+
+		select {
+		case work <- msg:
+			continue
+		default:
+		}
+		if msg.Job.Priority >= p.preemptPriority {
+			p.preempt(msg.Job)
+		}
+		select {
+		case work <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// preempt stops the lowest-priority running job below the preempting
+// range, the most recently started of those, when every worker is busy.
+func (p *WorkerPool) preempt(by *domain.Job) {
+	p.mu.Lock()
+	if len(p.running) < p.size {
+		p.mu.Unlock()
+		return
+	}
+	var victim *runningJob
+	for _, r := range p.running {
+		if r.preempted || r.job.Priority >= p.preemptPriority {
+			continue
+		}
+		if victim == nil || r.job.Priority < victim.job.Priority ||
+			r.job.Priority == victim.job.Priority && r.started.After(victim.started) {
+			victim = r
 		}
 	}
+	if victim != nil {
+		victim.preempted = true
+	}
+	p.mu.Unlock()
+
+	if victim == nil {
+		return
+	}
+	if !p.executeUC.Preempt(victim.job.JobID) {
+		// Not running yet, or done already.
+		p.mu.Lock()
+		victim.preempted = false
+		p.mu.Unlock()
+		return
+	}
+	p.logger.Info("Preempting job for higher-priority job",
+		zap.String("job_id", victim.job.JobID.String()),
+		zap.Int("priority", victim.job.Priority),
+		zap.String("preempted_by", by.JobID.String()),
+		zap.Int("preempted_by_priority", by.Priority),
+	)
+	p.metrics.Preemption(string(victim.job.Language))
+}
+
+// started and finished track the job worker id runs, for preempt.
+func (p *WorkerPool) started(id int, job *domain.Job) {
+	p.mu.Lock()
+	p.running[id] = &runningJob{job: job, started: time.Now()}
+	p.mu.Unlock()
+}
+
+func (p *WorkerPool) finished(id int) {
+	p.mu.Lock()
+	delete(p.running, id)
+	p.mu.Unlock()
+}
+
+// admit waits until the host has headroom for msg's job, for up to
+// pressureWait, and requeues the message if it does not get it. It reports
+// whether to run the job.
+func (p *WorkerPool) admit(ctx context.Context, msg *domain.JobMessage) bool {
+	if p.pressure == nil {
+		return true
+	}
+	deadline := time.Now().Add(p.pressureWait)
+	for {
+		sample, err := p.pressure.Sample()
+		if err != nil {
+			// A broken probe must not stop the worker.
+			p.logger.Warn("Failed to sample host pressure", zap.Error(err))
+			return true
+		}
+		resource := p.pressure.Exceeded(sample)
+		if resource == "" {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			p.logger.Warn("Requeuing job under host pressure",
+				zap.String("job_id", msg.Job.JobID.String()),
+				zap.String("resource", resource),
+				zap.Float64("memory_percent", sample.MemoryPercent),
+				zap.Float64("disk_percent", sample.DiskPercent),
+				zap.Float64("load_per_cpu", sample.LoadPerCPU),
+			)
+			p.metrics.PressureRequeue(resource)
+			p.requeue(msg)
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			p.requeue(msg)
+			return false
+		case <-time.After(pressureRetryInterval):
+		}
+	}
+}
+
+func (p *WorkerPool) requeue(msg *domain.JobMessage) {
+	if err := msg.Nack(true); err != nil {
+		p.logger.Error("Failed to requeue message", zap.String("job_id", msg.Job.JobID.String()), zap.Error(err))
+	}
+}
+
+// requeuePreempted returns a preempted job's message to the queue without
+// charging it the delivery, so a job preempted more often than the delivery
+// limit is not quarantined. It falls back to requeuing with Nack.
+func (p *WorkerPool) requeuePreempted(msg *domain.JobMessage) {
+	if msg.Requeue != nil {
+		err := msg.Requeue()
+		if err == nil {
+			return
+		}
+		p.logger.Warn("Failed to requeue preempted message, requeuing with NACK",
+			zap.String("job_id", msg.Job.JobID.String()), zap.Error(err))
+	}
+	p.requeue(msg)
+}
+
+// quarantine fails and dead-letters a message that exceeded the delivery limit.
+func (p *WorkerPool) quarantine(ctx context.Context, msg *domain.JobMessage) {
+	job := msg.Job
+	p.logger.Warn("Quarantining repeatedly delivered job",
+		zap.String("job_id", job.JobID.String()),
+		zap.Int("deliveries", msg.Deliveries),
+	)
+	if err := p.executeUC.Quarantine(ctx, job, msg.Deliveries); err != nil {
+		p.logger.Error("Failed to mark quarantined job", zap.String("job_id", job.JobID.String()), zap.Error(err))
+	}
+	if err := msg.Nack(false); err != nil {
+		p.logger.Error("Failed to NACK quarantined message", zap.String("job_id", job.JobID.String()), zap.Error(err))
+	}
+	p.deadLettered(ctx, msg, domain.EventJobQuarantined, domain.FailureRedeliveryLimit)
+}
+
+// deadLettered counts a dead-lettered job and notifies its tenant. Messages
+// for missing jobs are only counted: their tenant is whatever the message
+// claims.
+func (p *WorkerPool) deadLettered(ctx context.Context, msg *domain.JobMessage, event string, class domain.FailureClass) {
+	p.metrics.DeadLettered(string(class))
+	if p.deadLetters == nil || class == domain.FailureJobMissing {
+		return
+	}
+	job := msg.Job
+	tenantID := job.TenantID
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	p.deadLetters.Notify(ctx, &domain.DeadLetterEvent{
+		Event:        event,
+		JobID:        job.JobID,
+		TenantID:     tenantID,
+		Language:     job.Language,
+		ProblemID:    job.ProblemID,
+		FailureClass: class,
+		Deliveries:   msg.Deliveries,
+		OccurredAt:   time.Now().UTC(),
+	})
 }
@@ -2,6 +2,10 @@ package pool_test
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -10,18 +14,32 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
 	"github.com/Harsh-BH/Sentinel/worker/internal/pool"
 	"github.com/Harsh-BH/Sentinel/worker/internal/repository/mock"
 	"github.com/Harsh-BH/Sentinel/worker/internal/usecase"
 )
 
+// registryPath is the language registry shipped with the repository.
+const registryPath = "../../../sandbox/languages.yaml"
+
+func testLanguages(t testing.TB) *language.Registry {
+	t.Helper()
+	reg, err := language.Load(registryPath)
+	if err != nil {
+		t.Fatalf("load language registry: %v", err)
+	}
+	return reg
+}
+
 func newTestPool(t *testing.T, poolSize int, exec *mock.Executor) (chan *domain.JobMessage, *pool.WorkerPool, context.CancelFunc) {
 	t.Helper()
 
 	logger := zap.NewNop()
 	repo := &mock.JobRepository{}
 	idem := &mock.IdempotencyStore{}
-	uc := usecase.NewExecuteJobUsecase(repo, idem, exec, logger)
+	uc := usecase.NewExecuteJobUsecase(repo, idem, exec, testLanguages(t), logger)
 
 	ch := make(chan *domain.JobMessage, 16)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -101,6 +119,291 @@ func TestPool_NacksOnFailure(t *testing.T) {
 	}
 }
 
+// Test: dead-lettered jobs are reported with their failure class, and
+// messages past the delivery limit are quarantined without running.
+func TestPool_DeadLettersAndQuarantine(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			return nil, context.DeadlineExceeded
+		},
+	}
+	repo := &mock.JobRepository{}
+	uc := usecase.NewExecuteJobUsecase(repo, &mock.IdempotencyStore{}, exec, testLanguages(t), zap.NewNop())
+	notifier := &mock.DeadLetterNotifier{}
+
+	ch := make(chan *domain.JobMessage, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := pool.NewWorkerPool(1, ch, uc, zap.NewNop())
+	wp.SetDeadLetterNotifier(notifier)
+	wp.SetMaxDeliveries(3)
+	rec := metrics.NewRecorder()
+	wp.SetMetrics(rec)
+	wp.Start(ctx)
+
+	var acked, nacked atomic.Int32
+	sendJob(ch, &acked, &nacked)
+	// Delivered too often: quarantined.
+	ch <- &domain.JobMessage{
+		Job:        &domain.Job{JobID: uuid.New(), Language: domain.LangPython, SourceCode: "print('test')"},
+		Ack:        func() error { acked.Add(1); return nil },
+		Nack:       func(requeue bool) error { nacked.Add(1); return nil },
+		Deliveries: 4,
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	wp.Stop()
+
+	if nacked.Load() != 2 || acked.Load() != 0 {
+		t.Fatalf("expected 2 NACKs and no ACKs, got %d and %d", nacked.Load(), acked.Load())
+	}
+	events := notifier.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 dead-letter events, got %d", len(events))
+	}
+	if events[0].Event != domain.EventJobDeadLettered || events[0].FailureClass != domain.FailureSandbox || events[0].TenantID != domain.DefaultTenantID {
+		t.Errorf("unexpected dead-letter event: %+v", events[0])
+	}
+	if events[1].Event != domain.EventJobQuarantined || events[1].FailureClass != domain.FailureRedeliveryLimit || events[1].Deliveries != 4 {
+		t.Errorf("unexpected quarantine event: %+v", events[1])
+	}
+	if len(exec.ExecuteCalls) != 1 {
+		t.Errorf("expected the quarantined job not to run, got %d executions", len(exec.ExecuteCalls))
+	}
+	if len(repo.Results) != 1 || repo.Results[0].Result.Status != domain.StatusInternalError {
+		t.Errorf("expected the quarantined job to be failed, got %+v", repo.Results)
+	} else if ev := repo.Results[0].Result.SecurityEvents; len(ev) != 1 || ev[0].Kind != domain.SecurityQuarantine {
+		t.Errorf("expected a quarantine security event, got %+v", ev)
+	}
+	if rec.Count("DeadLettered", string(domain.FailureSandbox)) != 1 || rec.Count("DeadLettered", string(domain.FailureRedeliveryLimit)) != 1 {
+		t.Errorf("expected one job dead-lettered per failure class")
+	}
+	if rec.Count("Execution", string(domain.LangPython), "error") != 1 || rec.Count("ActiveWorkers") != 2 || rec.Value("ActiveWorkers") != 0 {
+		t.Errorf("expected the failed run counted once and no worker left active")
+	}
+}
+
+// Test: jobs are requeued instead of run while host memory is over its limit.
+func TestPool_RequeuesUnderPressure(t *testing.T) {
+	procDir := t.TempDir()
+	setMemory := func(availableKB int) {
+		meminfo := fmt.Sprintf("MemTotal:       1000000 kB\nMemFree:          10000 kB\nMemAvailable:   %7d kB\n", availableKB)
+		if err := os.WriteFile(filepath.Join(procDir, "meminfo"), []byte(meminfo), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setMemory(50000) // 95% used
+
+	guard := pool.NewPressureGuard(pool.PressureLimits{MemoryPercent: 90, ProcDir: procDir})
+	rec := metrics.NewRecorder()
+	guard.SetMetrics(rec)
+	if p, err := guard.Sample(); err != nil || guard.Exceeded(p) != "memory" {
+		t.Fatalf("Sample() = %+v, %v; want memory over its limit", p, err)
+	}
+
+	exec := &mock.Executor{}
+	uc := usecase.NewExecuteJobUsecase(&mock.JobRepository{}, &mock.IdempotencyStore{}, exec, testLanguages(t), zap.NewNop())
+	ch := make(chan *domain.JobMessage, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := pool.NewWorkerPool(1, ch, uc, zap.NewNop())
+	wp.SetPressureGuard(guard, 0)
+	wp.SetMetrics(rec)
+	wp.Start(ctx)
+
+	var acked, requeued atomic.Int32
+	send := func() {
+		ch <- &domain.JobMessage{
+			Job: &domain.Job{JobID: uuid.New(), Language: domain.LangPython, SourceCode: "print('test')"},
+			Ack: func() error { acked.Add(1); return nil },
+			Nack: func(requeue bool) error {
+				if requeue {
+					requeued.Add(1)
+				}
+				return nil
+			},
+		}
+	}
+	send()
+	time.Sleep(100 * time.Millisecond)
+	setMemory(500000) // 50% used
+	send()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	wp.Stop()
+
+	if requeued.Load() != 1 || acked.Load() != 1 {
+		t.Fatalf("expected 1 requeue and 1 ACK, got %d and %d", requeued.Load(), acked.Load())
+	}
+	if len(exec.ExecuteCalls) != 1 {
+		t.Errorf("expected only the job after the pressure eased to run, got %d executions", len(exec.ExecuteCalls))
+	}
+	if rec.Count("PressureRequeue", "memory") != 1 || rec.Value("HostUsage", "memory") != 0.5 {
+		t.Errorf("expected one requeue for memory and the last sample at 0.5, got %d and %v",
+			rec.Count("PressureRequeue", "memory"), rec.Value("HostUsage", "memory"))
+	}
+}
+
+// Test: with every worker busy, a job of the preempting priority stops and
+// requeues the running job of lower priority; other jobs wait their turn.
+func TestPool_PreemptsForPriorityJobs(t *testing.T) {
+	bulk := uuid.New()
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if req.JobID == bulk {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(300 * time.Millisecond):
+				}
+			}
+			return &domain.ExecutionResult{Status: domain.StatusSuccess}, nil
+		},
+	}
+	repo := &mock.JobRepository{}
+	uc := usecase.NewExecuteJobUsecase(repo, &mock.IdempotencyStore{}, exec, testLanguages(t), zap.NewNop())
+	rec := metrics.NewRecorder()
+	ch := make(chan *domain.JobMessage, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := pool.NewWorkerPool(1, ch, uc, zap.NewNop())
+	wp.SetPreemption(8)
+	wp.SetMetrics(rec)
+	wp.Start(ctx)
+
+	var mu sync.Mutex
+	outcomes := map[uuid.UUID]string{}
+	send := func(id uuid.UUID, priority int) {
+		record := func(outcome string) error {
+			mu.Lock()
+			outcomes[id] = outcome
+			mu.Unlock()
+			return nil
+		}
+		ch <- &domain.JobMessage{
+			Job: &domain.Job{JobID: id, Language: domain.LangPython, SourceCode: "print('test')", Priority: priority},
+			Ack: func() error { return record("acked") },
+			Nack: func(requeue bool) error {
+				return record(fmt.Sprintf("nacked, requeue=%v", requeue))
+			},
+		}
+	}
+
+	// A job below the preempting priority waits for the bulk job.
+	send(bulk, 0)
+	time.Sleep(50 * time.Millisecond)
+	normal := uuid.New()
+	send(normal, 5)
+	time.Sleep(400 * time.Millisecond)
+	mu.Lock()
+	if outcomes[bulk] != "acked" || outcomes[normal] != "acked" {
+		t.Errorf("outcomes = %v, want both jobs acked in turn", outcomes)
+	}
+	mu.Unlock()
+
+	send(bulk, 0)
+	time.Sleep(50 * time.Millisecond)
+	urgent := uuid.New()
+	send(urgent, 9)
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	wp.Stop()
+
+	if outcomes[bulk] != "nacked, requeue=true" || outcomes[urgent] != "acked" {
+		t.Errorf("outcomes = %v, want the bulk job requeued and the urgent one acked", outcomes)
+	}
+	if n := rec.Count("Preemption", "python"); n != 1 {
+		t.Errorf("expected 1 preemption, got %d", n)
+	}
+	if len(repo.Requeues) != 1 || repo.Requeues[0].ID != bulk {
+		t.Errorf("requeues = %+v, want the bulk job's", repo.Requeues)
+	}
+}
+
+// Test: a job preempted more often than the delivery limit is requeued
+// each time without being charged the delivery, and finally runs instead of
+// being quarantined.
+func TestPool_PreemptionDoesNotCountAsDelivery(t *testing.T) {
+	bulk := uuid.New()
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if req.JobID == bulk {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(300 * time.Millisecond):
+				}
+			}
+			return &domain.ExecutionResult{Status: domain.StatusSuccess}, nil
+		},
+	}
+	repo := &mock.JobRepository{}
+	uc := usecase.NewExecuteJobUsecase(repo, &mock.IdempotencyStore{}, exec, testLanguages(t), zap.NewNop())
+	notifier := &mock.DeadLetterNotifier{}
+	rec := metrics.NewRecorder()
+	ch := make(chan *domain.JobMessage, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	wp := pool.NewWorkerPool(1, ch, uc, zap.NewNop())
+	wp.SetPreemption(8)
+	wp.SetMaxDeliveries(2)
+	wp.SetDeadLetterNotifier(notifier)
+	wp.SetMetrics(rec)
+	wp.Start(ctx)
+
+	// Like the consumer, Requeue puts the message back with the same count
+	// and a requeuing Nack counts another delivery.
+	var mu sync.Mutex
+	outcomes := map[uuid.UUID]string{}
+	var requeues int
+	var send func(id uuid.UUID, priority, deliveries int)
+	send = func(id uuid.UUID, priority, deliveries int) {
+		record := func(outcome string) error {
+			mu.Lock()
+			outcomes[id] = outcome
+			mu.Unlock()
+			return nil
+		}
+		ch <- &domain.JobMessage{
+			Job: &domain.Job{JobID: id, Language: domain.LangPython, SourceCode: "print('test')", Priority: priority},
+			Ack: func() error { return record("acked") },
+			Nack: func(requeue bool) error {
+				if requeue {
+					send(id, priority, deliveries+1)
+				}
+				return record(fmt.Sprintf("nacked, requeue=%v", requeue))
+			},
+			Requeue: func() error {
+				mu.Lock()
+				requeues++
+				mu.Unlock()
+				send(id, priority, deliveries)
+				return nil
+			},
+			Deliveries: deliveries,
+		}
+	}
+
+	send(bulk, 0, 1)
+	for i := 0; i < 4; i++ {
+		time.Sleep(50 * time.Millisecond)
+		send(uuid.New(), 9, 1)
+		time.Sleep(50 * time.Millisecond)
+	}
+	time.Sleep(400 * time.Millisecond)
+	cancel()
+	wp.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if outcomes[bulk] != "acked" || requeues != 4 {
+		t.Errorf("bulk job %q after %d requeues, want acked after 4", outcomes[bulk], requeues)
+	}
+	if n := rec.Count("Preemption", "python"); n != 4 {
+		t.Errorf("expected 4 preemptions, got %d", n)
+	}
+	if events := notifier.Events(); len(events) != 0 {
+		t.Errorf("expected nothing quarantined, got %+v", events)
+	}
+}
+
 // Test: pool shuts down gracefully (context cancellation).
 func TestPool_GracefulShutdown(t *testing.T) {
 	exec := &mock.Executor{}
@@ -135,7 +438,7 @@ func TestPool_DuplicateIsAcked(t *testing.T) {
 			return false, nil // duplicate
 		},
 	}
-	uc := usecase.NewExecuteJobUsecase(repo, idem, exec, logger)
+	uc := usecase.NewExecuteJobUsecase(repo, idem, exec, testLanguages(t), logger)
 
 	ch := make(chan *domain.JobMessage, 8)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -156,3 +459,45 @@ func TestPool_DuplicateIsAcked(t *testing.T) {
 		t.Errorf("expected 0 NACKs, got %d", nacked.Load())
 	}
 }
+
+func TestWorkDirPool_PrecreatesAndCleansUp(t *testing.T) {
+	base := t.TempDir()
+	workDirs := pool.NewWorkDirPool(base, 3, zap.NewNop())
+	rec := metrics.NewRecorder()
+	workDirs.SetMetrics(rec)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	workDirs.Start(ctx)
+
+	// The replenisher fills the pool and holds one more directory while it
+	// waits for room.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		entries, _ := os.ReadDir(base)
+		if len(entries) == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected pool to fill, have %d dirs", len(entries))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	dir, err := workDirs.Acquire()
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected an empty work dir, got %d entries", len(entries))
+	}
+	if rec.Count("WorkDirPoolAcquire", "true") != 1 {
+		t.Errorf("expected the acquire counted as a hit")
+	}
+
+	cancel()
+	workDirs.Stop()
+	entries, _ := os.ReadDir(base)
+	if len(entries) != 1 || filepath.Join(base, entries[0].Name()) != dir {
+		t.Errorf("expected only the acquired dir to remain, got %v", entries)
+	}
+}
@@ -0,0 +1,189 @@
+package pool
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+)
+
+const (
+	// pressureSampleInterval is how often the gauges are refreshed while
+	// no job is waiting.
+	pressureSampleInterval = 5 * time.Second
+
+	// pressureRetryInterval is how often a held job re-checks for headroom.
+	pressureRetryInterval = time.Second
+)
+
+// PressureLimits are the host usage levels above which the pool starts no
+// new runs. A zero limit disables its check.
+type PressureLimits struct {
+	// MemoryPercent is the share of host memory in use, counting what the
+	// kernel could reclaim as free.
+	MemoryPercent float64
+	// DiskPercent is the share of DiskPath's filesystem in use.
+	DiskPercent float64
+	DiskPath    string
+	// LoadPerCPU is the 1-minute load average divided by the CPU count.
+	LoadPerCPU float64
+
+	// ProcDir is where procfs is mounted, /proc when empty.
+	ProcDir string
+}
+
+// HostPressure is one sample of the host's usage.
+type HostPressure struct {
+	MemoryPercent float64
+	DiskPercent   float64
+	LoadPerCPU    float64
+}
+
+// PressureGuard keeps the pool from starting runs while the host is short of
+// memory, disk or CPU, so a burst of jobs running up to their memory limits
+// cannot push the host into swapping or OOM kills that fail every run on it.
+// Jobs that arrive meanwhile are held briefly and then requeued for a worker
+// with headroom.
+type PressureGuard struct {
+	limits  PressureLimits
+	cpus    int
+	metrics metrics.Metrics
+}
+
+// NewPressureGuard checks the host against limits.
+func NewPressureGuard(limits PressureLimits) *PressureGuard {
+	if limits.ProcDir == "" {
+		limits.ProcDir = "/proc"
+	}
+	if limits.DiskPath == "" {
+		limits.DiskPath = os.TempDir()
+	}
+	return &PressureGuard{limits: limits, cpus: runtime.NumCPU(), metrics: metrics.Prometheus{}}
+}
+
+// SetMetrics publishes samples to m instead of the Prometheus collectors.
+func (g *PressureGuard) SetMetrics(m metrics.Metrics) {
+	g.metrics = m
+}
+
+// Sample reads the host's current usage and publishes it as gauges.
+// Resources whose check is disabled are not read.
+func (g *PressureGuard) Sample() (HostPressure, error) {
+	var p HostPressure
+	var err error
+	if g.limits.MemoryPercent > 0 {
+		if p.MemoryPercent, err = memoryPercent(g.limits.ProcDir); err != nil {
+			return p, err
+		}
+		g.metrics.HostUsage("memory", p.MemoryPercent/100)
+	}
+	if g.limits.DiskPercent > 0 {
+		if p.DiskPercent, err = diskPercent(g.limits.DiskPath); err != nil {
+			return p, err
+		}
+		g.metrics.HostUsage("disk", p.DiskPercent/100)
+	}
+	if g.limits.LoadPerCPU > 0 {
+		load, err := loadAverage(g.limits.ProcDir)
+		if err != nil {
+			return p, err
+		}
+		p.LoadPerCPU = load / float64(g.cpus)
+		g.metrics.HostUsage("cpu", p.LoadPerCPU)
+	}
+	return p, nil
+}
+
+// Exceeded returns the resource over its limit in p, or "" if none is.
+func (g *PressureGuard) Exceeded(p HostPressure) string {
+	switch {
+	case g.limits.MemoryPercent > 0 && p.MemoryPercent > g.limits.MemoryPercent:
+		return "memory"
+	case g.limits.DiskPercent > 0 && p.DiskPercent > g.limits.DiskPercent:
+		return "disk"
+	case g.limits.LoadPerCPU > 0 && p.LoadPerCPU > g.limits.LoadPerCPU:
+		return "cpu"
+	}
+	return ""
+}
+
+// watch keeps the gauges current until ctx is cancelled.
+func (g *PressureGuard) watch(ctx context.Context) {
+	ticker := time.NewTicker(pressureSampleInterval)
+	defer ticker.Stop()
+	for {
+		_, _ = g.Sample()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// memoryPercent derives used memory from MemAvailable, which counts
+// reclaimable caches as free.
+func memoryPercent(procDir string) (float64, error) {
+	f, err := os.Open(filepath.Join(procDir, "meminfo"))
+	if err != nil {
+		return 0, fmt.Errorf("read meminfo: %w", err)
+	}
+	defer f.Close()
+	var total, available float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total, _ = strconv.ParseFloat(fields[1], 64)
+		case "MemAvailable:":
+			available, _ = strconv.ParseFloat(fields[1], 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("read meminfo: %w", err)
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("meminfo has no MemTotal")
+	}
+	return 100 * (1 - available/total), nil
+}
+
+func diskPercent(path string) (float64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("statfs %s: %w", path, err)
+	}
+	if st.Blocks == 0 {
+		return 0, nil
+	}
+	return 100 * (1 - float64(st.Bavail)/float64(st.Blocks)), nil
+}
+
+func loadAverage(procDir string) (float64, error) {
+	data, err := os.ReadFile(filepath.Join(procDir, "loadavg"))
+	if err != nil {
+		return 0, fmt.Errorf("read loadavg: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("loadavg is empty")
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse loadavg: %w", err)
+	}
+	return load, nil
+}
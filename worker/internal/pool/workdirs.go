@@ -0,0 +1,111 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+)
+
+// workDirRetryDelay is how long the replenisher waits after failing to
+// create a work directory, e.g. while the disk is full.
+const workDirRetryDelay = time.Second
+
+// WorkDirPool pre-allocates empty sandbox work directories. A background
+// goroutine refills the pool as directories are taken; when it is empty,
+// Acquire creates one on demand.
+//
+// It only moves a directory's creation off the execution path; sandboxes
+// are not pre-forked, and nsjail starts as it would without the pool.
+type WorkDirPool struct {
+	baseDir string
+	ready   chan string
+	logger  *zap.Logger
+	metrics metrics.Metrics
+	wg      sync.WaitGroup
+}
+
+// NewWorkDirPool creates a pool holding up to size directories under
+// baseDir (the system temp dir when empty). Call Start to fill it.
+func NewWorkDirPool(baseDir string, size int, logger *zap.Logger) *WorkDirPool {
+	return &WorkDirPool{
+		baseDir: baseDir,
+		ready:   make(chan string, size),
+		logger:  logger,
+		metrics: metrics.Prometheus{},
+	}
+}
+
+// SetMetrics counts hits and misses in m instead of the Prometheus
+// collectors.
+func (w *WorkDirPool) SetMetrics(m metrics.Metrics) {
+	w.metrics = m
+}
+
+// Start fills the pool and keeps it full until ctx is cancelled.
+func (w *WorkDirPool) Start(ctx context.Context) {
+	w.wg.Add(1)
+	go w.replenish(ctx)
+}
+
+// Stop waits for the replenisher to exit and removes the directories that
+// were never used. Cancel the context passed to Start first.
+func (w *WorkDirPool) Stop() {
+	w.wg.Wait()
+	for {
+		select {
+		case dir := <-w.ready:
+			os.RemoveAll(dir)
+		default:
+			return
+		}
+	}
+}
+
+// Acquire returns an empty work directory. The caller owns it and must
+// remove it when done.
+func (w *WorkDirPool) Acquire() (string, error) {
+	select {
+	case dir := <-w.ready:
+		w.metrics.WorkDirPoolAcquire(true)
+		return dir, nil
+	default:
+		w.metrics.WorkDirPoolAcquire(false)
+		return w.create()
+	}
+}
+
+func (w *WorkDirPool) create() (string, error) {
+	dir, err := os.MkdirTemp(w.baseDir, "sentinel-work-*")
+	if err != nil {
+		return "", fmt.Errorf("create work dir: %w", err)
+	}
+	return dir, nil
+}
+
+func (w *WorkDirPool) replenish(ctx context.Context) {
+	defer w.wg.Done()
+	for {
+		dir, err := w.create()
+		if err != nil {
+			w.logger.Error("Failed to pre-create sandbox work dir", zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(workDirRetryDelay):
+				continue
+			}
+		}
+		select {
+		case w.ready <- dir:
+		case <-ctx.Done():
+			os.RemoveAll(dir)
+			return
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package disk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.BinaryCache = (*diskBinaryCache)(nil)
+
+// diskBinaryCache keeps compiled programs as files in one directory, so
+// identical submissions skip the compiler.
+type diskBinaryCache struct {
+	store *store
+}
+
+// NewDiskBinaryCache creates a cache in dir holding at most maxBytes of
+// binaries, evicting the least recently used entries beyond that.
+func NewDiskBinaryCache(dir string, maxBytes int64) (repository.BinaryCache, error) {
+	s, err := newStore(dir, ".bin", maxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("disk: binary cache: %w", err)
+	}
+	return &diskBinaryCache{store: s}, nil
+}
+
+// Get reads a cached binary and marks it as recently used.
+func (c *diskBinaryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, ok, err := c.store.get(key)
+	if err != nil {
+		return nil, false, fmt.Errorf("disk: read cached binary: %w", err)
+	}
+	return data, ok, nil
+}
+
+// Put writes a binary and evicts old entries if the cache grew too large.
+func (c *diskBinaryCache) Put(ctx context.Context, key string, binary []byte) error {
+	if err := c.store.put(key, binary); err != nil {
+		return fmt.Errorf("disk: store cached binary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package disk_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository/disk"
+)
+
+func TestInputCache_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cache, err := disk.NewDiskInputCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+
+	if _, ok, err := cache.Get(ctx, "gen", 1); ok || err != nil {
+		t.Fatalf("expected a miss on an empty cache, got ok=%v err=%v", ok, err)
+	}
+	if err := cache.Put(ctx, "gen", 1, "1 2 3\n"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	got, ok, err := cache.Get(ctx, "gen", 1)
+	if err != nil || !ok || got != "1 2 3\n" {
+		t.Errorf("expected cached input, got %q ok=%v err=%v", got, ok, err)
+	}
+	if _, ok, _ := cache.Get(ctx, "gen", 2); ok {
+		t.Error("expected a different seed to miss")
+	}
+	if _, ok, _ := cache.Get(ctx, "other", 1); ok {
+		t.Error("expected a different generator to miss")
+	}
+}
+
+func TestInputCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cache, err := disk.NewDiskInputCache(dir, 25)
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+
+	entry := strings.Repeat("x", 10)
+	for seed := int64(1); seed <= 2; seed++ {
+		if err := cache.Put(ctx, "gen", seed, entry); err != nil {
+			t.Fatalf("put %d: %v", seed, err)
+		}
+	}
+	// Age both entries, then touch seed 1 so seed 2 is the oldest.
+	old := time.Now().Add(-time.Hour)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.in"))
+	for _, f := range files {
+		os.Chtimes(f, old, old)
+	}
+	if _, ok, _ := cache.Get(ctx, "gen", 1); !ok {
+		t.Fatal("expected seed 1 to be cached")
+	}
+
+	if err := cache.Put(ctx, "gen", 3, entry); err != nil {
+		t.Fatalf("put 3: %v", err)
+	}
+	if _, ok, _ := cache.Get(ctx, "gen", 2); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for _, seed := range []int64{1, 3} {
+		if _, ok, _ := cache.Get(ctx, "gen", seed); !ok {
+			t.Errorf("expected seed %d to survive eviction", seed)
+		}
+	}
+}
+
+func TestBinaryCache_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	cache, err := disk.NewDiskBinaryCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+
+	if _, ok, err := cache.Get(ctx, "key"); ok || err != nil {
+		t.Fatalf("expected a miss on an empty cache, got ok=%v err=%v", ok, err)
+	}
+	if err := cache.Put(ctx, "key", []byte("\x7fELF")); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	got, ok, err := cache.Get(ctx, "key")
+	if err != nil || !ok || string(got) != "\x7fELF" {
+		t.Errorf("expected cached binary, got %q ok=%v err=%v", got, ok, err)
+	}
+}
@@ -0,0 +1,48 @@
+package disk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.InputCache = (*diskInputCache)(nil)
+
+// diskInputCache keeps generated inputs as files in one directory. Generated
+// inputs can be far larger than what belongs in Redis, and losing the cache
+// only costs a regeneration.
+type diskInputCache struct {
+	store *store
+}
+
+// NewDiskInputCache creates a cache in dir holding at most maxBytes of
+// inputs, evicting the least recently used entries beyond that.
+func NewDiskInputCache(dir string, maxBytes int64) (repository.InputCache, error) {
+	s, err := newStore(dir, ".in", maxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("disk: input cache: %w", err)
+	}
+	return &diskInputCache{store: s}, nil
+}
+
+func inputKey(generatorHash string, seed int64) string {
+	return fmt.Sprintf("%s/%d", generatorHash, seed)
+}
+
+// Get reads a cached input and marks it as recently used.
+func (c *diskInputCache) Get(ctx context.Context, generatorHash string, seed int64) (string, bool, error) {
+	data, ok, err := c.store.get(inputKey(generatorHash, seed))
+	if err != nil {
+		return "", false, fmt.Errorf("disk: read cached input: %w", err)
+	}
+	return string(data), ok, nil
+}
+
+// Put writes an input and evicts old entries if the cache grew too large.
+func (c *diskInputCache) Put(ctx context.Context, generatorHash string, seed int64, input string) error {
+	if err := c.store.put(inputKey(generatorHash, seed), []byte(input)); err != nil {
+		return fmt.Errorf("disk: store cached input: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package disk implements worker repositories backed by the local filesystem.
+package disk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// store keeps entries as files with one extension in a directory, evicting
+// the least recently used ones (by modification time) beyond maxBytes. Reads
+// and writes are safe for concurrent use because entries are published with
+// an atomic rename.
+type store struct {
+	dir      string
+	ext      string
+	maxBytes int64
+
+	// mu serialises eviction.
+	mu sync.Mutex
+}
+
+func newStore(dir, ext string, maxBytes int64) (*store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	return &store{dir: dir, ext: ext, maxBytes: maxBytes}, nil
+}
+
+func (s *store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+s.ext)
+}
+
+// get reads an entry and marks it as recently used.
+func (s *store) get(key string) ([]byte, bool, error) {
+	p := s.path(key)
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return data, true, nil
+}
+
+// put writes an entry and evicts old ones if the store grew too large.
+// Entries larger than the whole store are dropped.
+func (s *store) put(key string, data []byte) error {
+	if int64(len(data)) > s.maxBytes {
+		return nil
+	}
+	tmp, err := os.CreateTemp(s.dir, "put-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return s.evict()
+}
+
+// evict removes the least recently used entries until the store fits in
+// maxBytes.
+func (s *store) evict() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := filepath.Glob(filepath.Join(s.dir, "*"+s.ext))
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	files := make([]entry, 0, len(entries))
+	var total int64
+	for _, p := range entries {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue // evicted or replaced concurrently
+		}
+		files = append(files, entry{path: p, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	if total <= s.maxBytes {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= s.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		total -= f.size
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -9,12 +11,66 @@ import (
 )
 
 // JobRepository defines the interface for updating job state in the database.
+// Writes come from one run of a job, the one for its judge revision
+// revision, and apply only while the job is on that revision and has no
+// result yet. Otherwise they change nothing and return
+// domain.ErrRunSuperseded, so a redelivered or stale message cannot
+// reopen or overwrite a finished job.
 type JobRepository interface {
-	// UpdateStatus atomically updates the status of a job.
-	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error
+	// UpdateStatus atomically updates the status of a job. It returns
+	// domain.ErrJobCancelled, changing nothing, if the job was cancelled.
+	UpdateStatus(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error
 
-	// SetResult stores the execution result for a completed job.
-	SetResult(ctx context.Context, id uuid.UUID, result *domain.ExecutionResult) error
+	// SetResult stores the execution result for a completed job, with its
+	// status, artifacts and security events, in one transaction. The status change's
+	// timeline event and notification are written by triggers in the same
+	// transaction, so a crash mid-write leaves none of them.
+	SetResult(ctx context.Context, id uuid.UUID, revision int, result *domain.ExecutionResult) error
+
+	// Requeue puts a job that stopped without a result back to QUEUED, with
+	// reason recorded on the timeline event of the change.
+	Requeue(ctx context.Context, id uuid.UUID, revision int, reason string) error
+}
+
+// ProblemRepository defines read access to problems' versioned test data.
+type ProblemRepository interface {
+	// GetTestData returns the current test-data version and cases of a problem.
+	GetTestData(ctx context.Context, problemID string) (*domain.TestData, error)
+}
+
+// RuntimeRepository defines read access to runtimes registered through the API.
+type RuntimeRepository interface {
+	// GetRuntime returns the runtime named name, reporting false if there is none.
+	GetRuntime(ctx context.Context, name domain.Language) (*domain.Runtime, bool, error)
+}
+
+// InputCache stores generated test inputs by generator hash and seed.
+// Implementations are best-effort: callers regenerate on a miss or error.
+type InputCache interface {
+	// Get returns the cached input, reporting false on a miss.
+	Get(ctx context.Context, generatorHash string, seed int64) (string, bool, error)
+
+	// Put stores a generated input.
+	Put(ctx context.Context, generatorHash string, seed int64, input string) error
+}
+
+// BinaryCache stores compiled programs by a key derived from everything that
+// went into compiling them. Implementations are best-effort: callers compile
+// on a miss or error.
+type BinaryCache interface {
+	// Get returns the cached binary, reporting false on a miss.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Put stores a compiled binary.
+	Put(ctx context.Context, key string, binary []byte) error
+}
+
+// ComparatorRunner runs problems' own comparators over test case outputs.
+type ComparatorRunner interface {
+	// Check reports whether output answers input correctly given the
+	// expected output, with a message explaining a rejection. An error means
+	// the comparator itself failed rather than the submission.
+	Check(ctx context.Context, cmp *domain.Comparator, input, expected, output string) (bool, string, error)
 }
 
 // IdempotencyStore defines the interface for distributed deduplication locks.
@@ -25,9 +81,65 @@ type IdempotencyStore interface {
 
 	// ReleaseLock releases the processing lock with a TTL for eventual cleanup.
 	ReleaseLock(ctx context.Context, jobID uuid.UUID) error
+
+	// Unlock removes the processing lock at once, so the job's next
+	// delivery runs it instead of being skipped as a duplicate.
+	Unlock(ctx context.Context, jobID uuid.UUID) error
+}
+
+// QueuePauses reports the languages operators paused through the API.
+type QueuePauses interface {
+	// Paused reports whether jobs in lang are to be held back.
+	Paused(ctx context.Context, lang domain.Language) (bool, error)
+}
+
+// QuotaTracker records per-tenant execution time against the daily quota window.
+type QuotaTracker interface {
+	// AddUsage adds the execution time consumed by one job to the tenant's counter.
+	AddUsage(ctx context.Context, tenantID string, used time.Duration) error
+}
+
+// InteractiveIO connects interactive jobs to the clients driving them.
+type InteractiveIO interface {
+	// Stdin returns the input clients send the job. It ends when a client
+	// closes the job's stdin; Close stops reading early.
+	Stdin(ctx context.Context, jobID uuid.UUID) io.ReadCloser
+
+	// Output returns a writer publishing the job's output to its clients.
+	// Publishing is best-effort, so writes never fail.
+	Output(ctx context.Context, jobID uuid.UUID) io.Writer
+}
+
+// CancelWatcher delivers requests to cancel running jobs.
+type CancelWatcher interface {
+	// Watch returns a context derived from ctx that is cancelled, with
+	// domain.ErrJobCancelled as its cause, once cancelling the job is
+	// requested, including before Watch was called. stop releases it.
+	Watch(ctx context.Context, jobID uuid.UUID) (watched context.Context, stop func())
+}
+
+// WebhookRepository defines read access to tenants' webhook endpoints.
+type WebhookRepository interface {
+	// GetDLQWebhook returns the tenant's dead-letter webhook, reporting false if it has none.
+	GetDLQWebhook(ctx context.Context, tenantID string) (*domain.DLQWebhook, bool, error)
+}
+
+// DeadLetterNotifier tells tenants about their dead-lettered jobs.
+// Implementations are best-effort and must not block the caller for long.
+type DeadLetterNotifier interface {
+	Notify(ctx context.Context, event *domain.DeadLetterEvent)
 }
 
 // Executor defines the interface for running code in a sandbox.
 type Executor interface {
 	Execute(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error)
 }
+
+// Compiler is implemented by executors that can compile a submission once
+// for several runs, each passed the program as the request's Program.
+type Compiler interface {
+	// Compile compiles req's source. It returns a nil program for languages
+	// that are not compiled, and a COMPILATION_ERROR result when compiling
+	// fails. The returned error is reserved for sandbox failures.
+	Compile(ctx context.Context, req *domain.ExecutionRequest) (*domain.CompiledProgram, *domain.ExecutionResult, error)
+}
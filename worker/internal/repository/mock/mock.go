@@ -2,7 +2,11 @@ package mock
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -18,40 +22,60 @@ var _ repository.JobRepository = (*JobRepository)(nil)
 type JobRepository struct {
 	mu sync.Mutex
 
-	UpdateStatusFn func(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error
-	SetResultFn    func(ctx context.Context, id uuid.UUID, result *domain.ExecutionResult) error
+	UpdateStatusFn func(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error
+	SetResultFn    func(ctx context.Context, id uuid.UUID, revision int, result *domain.ExecutionResult) error
+	RequeueFn      func(ctx context.Context, id uuid.UUID, revision int, reason string) error
 
 	// Recorded calls for assertions.
 	StatusUpdates []StatusUpdate
 	Results       []ResultUpdate
+	Requeues      []Requeue
 }
 
 type StatusUpdate struct {
-	ID     uuid.UUID
-	Status domain.ExecutionStatus
+	ID       uuid.UUID
+	Revision int
+	Status   domain.ExecutionStatus
+}
+
+type Requeue struct {
+	ID       uuid.UUID
+	Revision int
+	Reason   string
 }
 
 type ResultUpdate struct {
-	ID     uuid.UUID
-	Result *domain.ExecutionResult
+	ID       uuid.UUID
+	Revision int
+	Result   *domain.ExecutionResult
 }
 
-func (m *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error {
+func (m *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error {
 	m.mu.Lock()
-	m.StatusUpdates = append(m.StatusUpdates, StatusUpdate{ID: id, Status: status})
+	m.StatusUpdates = append(m.StatusUpdates, StatusUpdate{ID: id, Revision: revision, Status: status})
 	m.mu.Unlock()
 	if m.UpdateStatusFn != nil {
-		return m.UpdateStatusFn(ctx, id, status)
+		return m.UpdateStatusFn(ctx, id, revision, status)
 	}
 	return nil
 }
 
-func (m *JobRepository) SetResult(ctx context.Context, id uuid.UUID, result *domain.ExecutionResult) error {
+func (m *JobRepository) SetResult(ctx context.Context, id uuid.UUID, revision int, result *domain.ExecutionResult) error {
 	m.mu.Lock()
-	m.Results = append(m.Results, ResultUpdate{ID: id, Result: result})
+	m.Results = append(m.Results, ResultUpdate{ID: id, Revision: revision, Result: result})
 	m.mu.Unlock()
 	if m.SetResultFn != nil {
-		return m.SetResultFn(ctx, id, result)
+		return m.SetResultFn(ctx, id, revision, result)
+	}
+	return nil
+}
+
+func (m *JobRepository) Requeue(ctx context.Context, id uuid.UUID, revision int, reason string) error {
+	m.mu.Lock()
+	m.Requeues = append(m.Requeues, Requeue{ID: id, Revision: revision, Reason: reason})
+	m.mu.Unlock()
+	if m.RequeueFn != nil {
+		return m.RequeueFn(ctx, id, revision, reason)
 	}
 	return nil
 }
@@ -66,9 +90,11 @@ type IdempotencyStore struct {
 
 	AcquireLockFn func(ctx context.Context, jobID uuid.UUID) (bool, error)
 	ReleaseLockFn func(ctx context.Context, jobID uuid.UUID) error
+	UnlockFn      func(ctx context.Context, jobID uuid.UUID) error
 
 	AcquireCalls []uuid.UUID
 	ReleaseCalls []uuid.UUID
+	UnlockCalls  []uuid.UUID
 }
 
 func (m *IdempotencyStore) AcquireLock(ctx context.Context, jobID uuid.UUID) (bool, error) {
@@ -91,17 +117,44 @@ func (m *IdempotencyStore) ReleaseLock(ctx context.Context, jobID uuid.UUID) err
 	return nil
 }
 
+func (m *IdempotencyStore) Unlock(ctx context.Context, jobID uuid.UUID) error {
+	m.mu.Lock()
+	m.UnlockCalls = append(m.UnlockCalls, jobID)
+	m.mu.Unlock()
+	if m.UnlockFn != nil {
+		return m.UnlockFn(ctx, jobID)
+	}
+	return nil
+}
+
 // ---- Executor mock ----
 
-var _ repository.Executor = (*Executor)(nil)
+var (
+	_ repository.Executor = (*Executor)(nil)
+	_ repository.Compiler = (*Executor)(nil)
+)
 
-// Executor is a test double for repository.Executor.
+// Executor is a test double for repository.Executor and
+// repository.Compiler. Without a CompileFn it compiles nothing, leaving
+// every run to compile for itself.
 type Executor struct {
 	mu sync.Mutex
 
 	ExecuteFn func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error)
+	CompileFn func(ctx context.Context, req *domain.ExecutionRequest) (*domain.CompiledProgram, *domain.ExecutionResult, error)
 
 	ExecuteCalls []*domain.ExecutionRequest
+	CompileCalls []*domain.ExecutionRequest
+}
+
+func (m *Executor) Compile(ctx context.Context, req *domain.ExecutionRequest) (*domain.CompiledProgram, *domain.ExecutionResult, error) {
+	m.mu.Lock()
+	m.CompileCalls = append(m.CompileCalls, req)
+	m.mu.Unlock()
+	if m.CompileFn != nil {
+		return m.CompileFn(ctx, req)
+	}
+	return nil, nil, nil
 }
 
 func (m *Executor) Execute(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
@@ -118,3 +171,288 @@ func (m *Executor) Execute(ctx context.Context, req *domain.ExecutionRequest) (*
 		TimeUsedMs: 42,
 	}, nil
 }
+
+// ---- QuotaTracker mock ----
+
+var _ repository.QuotaTracker = (*QuotaTracker)(nil)
+
+// QuotaTracker is a test double for repository.QuotaTracker.
+type QuotaTracker struct {
+	mu sync.Mutex
+
+	AddUsageFn func(ctx context.Context, tenantID string, used time.Duration) error
+
+	Usage map[string]time.Duration
+}
+
+func (m *QuotaTracker) AddUsage(ctx context.Context, tenantID string, used time.Duration) error {
+	m.mu.Lock()
+	if m.Usage == nil {
+		m.Usage = make(map[string]time.Duration)
+	}
+	m.Usage[tenantID] += used
+	m.mu.Unlock()
+	if m.AddUsageFn != nil {
+		return m.AddUsageFn(ctx, tenantID, used)
+	}
+	return nil
+}
+
+// ---- ProblemRepository mock ----
+
+var _ repository.ProblemRepository = (*ProblemRepository)(nil)
+
+// ProblemRepository is a test double for repository.ProblemRepository.
+type ProblemRepository struct {
+	GetTestDataFn func(ctx context.Context, problemID string) (*domain.TestData, error)
+
+	// TestData is returned by GetTestData when no hook is set.
+	TestData map[string]*domain.TestData
+}
+
+func (m *ProblemRepository) GetTestData(ctx context.Context, problemID string) (*domain.TestData, error) {
+	if m.GetTestDataFn != nil {
+		return m.GetTestDataFn(ctx, problemID)
+	}
+	data, ok := m.TestData[problemID]
+	if !ok {
+		return nil, fmt.Errorf("problem not found: %s", problemID)
+	}
+	return data, nil
+}
+
+// ---- RuntimeRepository mock ----
+
+var _ repository.RuntimeRepository = (*RuntimeRepository)(nil)
+
+// RuntimeRepository is a test double for repository.RuntimeRepository.
+type RuntimeRepository struct {
+	GetRuntimeFn func(ctx context.Context, name domain.Language) (*domain.Runtime, bool, error)
+
+	// Runtimes is searched by GetRuntime when no hook is set.
+	Runtimes map[domain.Language]*domain.Runtime
+}
+
+func (m *RuntimeRepository) GetRuntime(ctx context.Context, name domain.Language) (*domain.Runtime, bool, error) {
+	if m.GetRuntimeFn != nil {
+		return m.GetRuntimeFn(ctx, name)
+	}
+	rt, ok := m.Runtimes[name]
+	return rt, ok, nil
+}
+
+// ---- InputCache mock ----
+
+var _ repository.InputCache = (*InputCache)(nil)
+
+// InputCache is an in-memory test double for repository.InputCache.
+type InputCache struct {
+	mu sync.Mutex
+
+	GetFn func(ctx context.Context, generatorHash string, seed int64) (string, bool, error)
+	PutFn func(ctx context.Context, generatorHash string, seed int64, input string) error
+
+	// Inputs holds stored entries keyed by "<hash>/<seed>".
+	Inputs map[string]string
+}
+
+func (m *InputCache) Get(ctx context.Context, generatorHash string, seed int64) (string, bool, error) {
+	if m.GetFn != nil {
+		return m.GetFn(ctx, generatorHash, seed)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	input, ok := m.Inputs[fmt.Sprintf("%s/%d", generatorHash, seed)]
+	return input, ok, nil
+}
+
+func (m *InputCache) Put(ctx context.Context, generatorHash string, seed int64, input string) error {
+	if m.PutFn != nil {
+		return m.PutFn(ctx, generatorHash, seed, input)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Inputs == nil {
+		m.Inputs = make(map[string]string)
+	}
+	m.Inputs[fmt.Sprintf("%s/%d", generatorHash, seed)] = input
+	return nil
+}
+
+// ---- WebhookRepository mock ----
+
+var _ repository.WebhookRepository = (*WebhookRepository)(nil)
+
+// WebhookRepository is a test double for repository.WebhookRepository.
+type WebhookRepository struct {
+	GetDLQWebhookFn func(ctx context.Context, tenantID string) (*domain.DLQWebhook, bool, error)
+
+	// Hooks is searched by GetDLQWebhook when no hook is set.
+	Hooks map[string]*domain.DLQWebhook
+}
+
+func (m *WebhookRepository) GetDLQWebhook(ctx context.Context, tenantID string) (*domain.DLQWebhook, bool, error) {
+	if m.GetDLQWebhookFn != nil {
+		return m.GetDLQWebhookFn(ctx, tenantID)
+	}
+	hook, ok := m.Hooks[tenantID]
+	return hook, ok, nil
+}
+
+// ---- DeadLetterNotifier mock ----
+
+var _ repository.DeadLetterNotifier = (*DeadLetterNotifier)(nil)
+
+// DeadLetterNotifier records the events it is asked to deliver.
+type DeadLetterNotifier struct {
+	mu     sync.Mutex
+	events []*domain.DeadLetterEvent
+}
+
+func (m *DeadLetterNotifier) Notify(ctx context.Context, event *domain.DeadLetterEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+// Events returns the events notified so far.
+func (m *DeadLetterNotifier) Events() []*domain.DeadLetterEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*domain.DeadLetterEvent(nil), m.events...)
+}
+
+// ---- InteractiveIO mock ----
+
+var _ repository.InteractiveIO = (*InteractiveIO)(nil)
+
+// InteractiveIO is a test double for repository.InteractiveIO. Each job's
+// stdin is read from Input; its output is collected for Output.
+type InteractiveIO struct {
+	mu sync.Mutex
+
+	Input map[uuid.UUID]string
+
+	output map[uuid.UUID]*strings.Builder
+	closed map[uuid.UUID]bool
+}
+
+func (m *InteractiveIO) Stdin(ctx context.Context, jobID uuid.UUID) io.ReadCloser {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &mockStdin{Reader: strings.NewReader(m.Input[jobID]), close: func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.closed == nil {
+			m.closed = make(map[uuid.UUID]bool)
+		}
+		m.closed[jobID] = true
+	}}
+}
+
+func (m *InteractiveIO) Output(ctx context.Context, jobID uuid.UUID) io.Writer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.output == nil {
+		m.output = make(map[uuid.UUID]*strings.Builder)
+	}
+	if m.output[jobID] == nil {
+		m.output[jobID] = &strings.Builder{}
+	}
+	return m.output[jobID]
+}
+
+// Published returns the output written for the job.
+func (m *InteractiveIO) Published(jobID uuid.UUID) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.output[jobID] == nil {
+		return ""
+	}
+	return m.output[jobID].String()
+}
+
+// Closed reports whether the job's stdin was closed.
+func (m *InteractiveIO) Closed(jobID uuid.UUID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed[jobID]
+}
+
+type mockStdin struct {
+	*strings.Reader
+	close func()
+}
+
+func (s *mockStdin) Close() error {
+	s.close()
+	return nil
+}
+
+// ---- ComparatorRunner mock ----
+
+var _ repository.ComparatorRunner = (*ComparatorRunner)(nil)
+
+// ComparatorRunner is a test double for repository.ComparatorRunner. Without
+// a hook it accepts output equal to the expected output.
+type ComparatorRunner struct {
+	CheckFn func(ctx context.Context, cmp *domain.Comparator, input, expected, output string) (bool, string, error)
+}
+
+func (m *ComparatorRunner) Check(ctx context.Context, cmp *domain.Comparator, input, expected, output string) (bool, string, error) {
+	if m.CheckFn != nil {
+		return m.CheckFn(ctx, cmp, input, expected, output)
+	}
+	return output == expected, "", nil
+}
+
+// ---- CancelWatcher mock ----
+
+var _ repository.CancelWatcher = (*CancelWatcher)(nil)
+
+// CancelWatcher is a test double for repository.CancelWatcher. Cancel
+// requests a job's cancellation, whether or not it is being watched yet.
+type CancelWatcher struct {
+	mu        sync.Mutex
+	cancelled map[uuid.UUID]bool
+	watches   map[uuid.UUID]context.CancelCauseFunc
+
+	// Watching, if set, is called as each job starts being watched.
+	Watching func(jobID uuid.UUID)
+}
+
+func (m *CancelWatcher) Watch(ctx context.Context, jobID uuid.UUID) (context.Context, func()) {
+	watched, cancel := context.WithCancelCause(ctx)
+	m.mu.Lock()
+	if m.watches == nil {
+		m.watches = make(map[uuid.UUID]context.CancelCauseFunc)
+	}
+	m.watches[jobID] = cancel
+	if m.cancelled[jobID] {
+		cancel(domain.ErrJobCancelled)
+	}
+	watching := m.Watching
+	m.mu.Unlock()
+	if watching != nil {
+		watching(jobID)
+	}
+	return watched, func() {
+		m.mu.Lock()
+		delete(m.watches, jobID)
+		m.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Cancel requests the job's cancellation.
+func (m *CancelWatcher) Cancel(jobID uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancelled == nil {
+		m.cancelled = make(map[uuid.UUID]bool)
+	}
+	m.cancelled[jobID] = true
+	if cancel, ok := m.watches[jobID]; ok {
+		cancel(domain.ErrJobCancelled)
+	}
+}
@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
@@ -15,42 +18,187 @@ import (
 var _ repository.JobRepository = (*pgJobRepo)(nil)
 
 type pgJobRepo struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	retry RetryPolicy
 }
 
-// NewPostgresJobRepository creates a new PostgreSQL-backed job repository for the worker.
-func NewPostgresJobRepository(pool *pgxpool.Pool) repository.JobRepository {
-	return &pgJobRepo{pool: pool}
+// NewPostgresJobRepository creates a new PostgreSQL-backed job repository
+// for the worker. Writes that fail because the database went away are
+// retried under retry, so a failover delays a job instead of failing it.
+func NewPostgresJobRepository(pool *pgxpool.Pool, retry RetryPolicy) repository.JobRepository {
+	return &pgJobRepo{pool: pool, retry: retry}
 }
 
-func (r *pgJobRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error {
-	query := `UPDATE execution_jobs SET status = $1, updated_at = $2 WHERE job_id = $3`
-	tag, err := r.pool.Exec(ctx, query, status, time.Now().UTC(), id)
+// runGuard limits a write to the run of the job's current judge revision,
+// while the job has no result. Its parameters follow the write's own.
+func runGuard(id, revision int) string {
+	return fmt.Sprintf(`job_id = $%d AND judge_revision = $%d AND status IN ('QUEUED', 'COMPILING', 'RUNNING')`, id, revision)
+}
+
+// rowQuerier is a pool or a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// missedGuard explains why a write under runGuard changed nothing.
+func missedGuard(ctx context.Context, q rowQuerier, id uuid.UUID) error {
+	var status domain.ExecutionStatus
+	err := q.QueryRow(ctx, `SELECT status FROM execution_jobs WHERE job_id = $1`, id).Scan(&status)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("%w: %s", domain.ErrJobNotFound, id)
+	case err != nil:
+		return err
+	case status == domain.StatusCancelled:
+		return fmt.Errorf("%w: %s", domain.ErrJobCancelled, id)
+	}
+	return fmt.Errorf("%w: %s", domain.ErrRunSuperseded, id)
+}
+
+func (r *pgJobRepo) UpdateStatus(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error {
+	return r.retry.do(ctx, r.pool, func() error { return r.updateStatus(ctx, id, revision, status) })
+}
+
+func (r *pgJobRepo) updateStatus(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error {
+	// A job cancelled while queued keeps its status, so the worker picking
+	// it up does not start it; a finished or rejudged one keeps its result.
+	query := `UPDATE execution_jobs SET status = $1, updated_at = $2 WHERE ` + runGuard(3, 4)
+	tag, err := r.pool.Exec(ctx, query, status, time.Now().UTC(), id, revision)
 	if err != nil {
 		return fmt.Errorf("postgres: update status: %w", err)
 	}
-	if tag.RowsAffected() == 0 {
-		return fmt.Errorf("postgres: job not found: %s", id)
+	if tag.RowsAffected() > 0 {
+		return nil
 	}
-	return nil
+	return fmt.Errorf("postgres: update status: %w", missedGuard(ctx, r.pool, id))
 }
 
-func (r *pgJobRepo) SetResult(ctx context.Context, id uuid.UUID, result *domain.ExecutionResult) error {
+func (r *pgJobRepo) SetResult(ctx context.Context, id uuid.UUID, revision int, result *domain.ExecutionResult) error {
+	start := time.Now()
 	query := `
 		UPDATE execution_jobs
 		SET stdout = $1, stderr = $2, status = $3, exit_code = $4,
-		    time_used_ms = $5, memory_used_kb = $6, updated_at = $7
-		WHERE job_id = $8`
+		    time_used_ms = $5, memory_used_kb = $6, updated_at = $7,
+		    test_data_version = $8, test_results = $9,
+		    score = $10, max_score = $11, subtask_results = $12,
+		    termination_strategy = $13, cpu_time_used_ms = $14, disk_used_kb = $15,
+		    compile_time_ms = $16, timings = $17
+		WHERE ` + runGuard(18, 19)
 
-	tag, err := r.pool.Exec(ctx, query,
-		result.Stdout, result.Stderr, result.Status, result.ExitCode,
-		result.TimeUsedMs, result.MemoryUsedKB, time.Now().UTC(), id,
-	)
-	if err != nil {
-		return fmt.Errorf("postgres: set result: %w", err)
+	// Plain executions leave the judging columns NULL.
+	var testDataVersion *int
+	var strategy *string
+	var testResults, subtaskResults []byte
+	// Zero means the backend did not measure CPU time.
+	var cpuTimeUsed *int
+	if result.CPUTimeUsedMs > 0 {
+		cpuTimeUsed = &result.CPUTimeUsedMs
+	}
+	var diskUsed *int
+	if result.DiskUsedKB > 0 {
+		diskUsed = &result.DiskUsedKB
+	}
+	// Zero means nothing was compiled, or the binary was cached.
+	var compileTime *int
+	if result.CompileTimeMs > 0 {
+		compileTime = &result.CompileTimeMs
 	}
-	if tag.RowsAffected() == 0 {
-		return fmt.Errorf("postgres: job not found: %s", id)
+	if result.TestDataVersion > 0 {
+		testDataVersion = &result.TestDataVersion
+		if result.TerminationStrategy != "" {
+			s := string(result.TerminationStrategy)
+			strategy = &s
+		}
+		encoded, err := json.Marshal(result.TestResults)
+		if err != nil {
+			return fmt.Errorf("postgres: encode test results: %w", err)
+		}
+		testResults = encoded
 	}
-	return nil
+	if result.SubtaskResults != nil {
+		encoded, err := json.Marshal(result.SubtaskResults)
+		if err != nil {
+			return fmt.Errorf("postgres: encode subtask results: %w", err)
+		}
+		subtaskResults = encoded
+	}
+	timings, err := json.Marshal(result.Timings)
+	if err != nil {
+		return fmt.Errorf("postgres: encode timings: %w", err)
+	}
+
+	// The artifacts are replaced with the result, so a rerun that no longer
+	// produces a file does not keep serving the old one. The transaction is
+	// all or nothing, so a retry after a lost connection starts clean. A
+	// retry after a commit whose reply was lost finds the result stored and
+	// reports domain.ErrRunSuperseded; the result stands either way.
+	return r.retry.do(ctx, r.pool, func() error {
+		return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+			tag, err := tx.Exec(ctx, query,
+				result.Stdout, result.Stderr, result.Status, result.ExitCode,
+				result.TimeUsedMs, result.MemoryUsedKB, time.Now().UTC(),
+				testDataVersion, testResults,
+				result.Score, result.MaxScore, subtaskResults, strategy, cpuTimeUsed, diskUsed, compileTime, timings, id, revision,
+			)
+			if err != nil {
+				return fmt.Errorf("postgres: set result: %w", err)
+			}
+			if tag.RowsAffected() == 0 {
+				return fmt.Errorf("postgres: set result: %w", missedGuard(ctx, tx, id))
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM job_artifacts WHERE job_id = $1`, id); err != nil {
+				return fmt.Errorf("postgres: clear artifacts: %w", err)
+			}
+			for _, a := range result.Artifacts {
+				_, err := tx.Exec(ctx,
+					`INSERT INTO job_artifacts (job_id, name, content, size_bytes, truncated) VALUES ($1, $2, $3, $4, $5)`,
+					id, a.Name, a.Content, a.SizeBytes, a.Truncated,
+				)
+				if err != nil {
+					return fmt.Errorf("postgres: store artifact %s: %w", a.Name, err)
+				}
+			}
+			for _, e := range result.SecurityEvents {
+				_, err := tx.Exec(ctx, `
+					INSERT INTO security_events (job_id, tenant_id, kind, detail)
+					SELECT job_id, tenant_id, $2, $3 FROM execution_jobs WHERE job_id = $1`,
+					id, e.Kind, e.Detail,
+				)
+				if err != nil {
+					return fmt.Errorf("postgres: store security event: %w", err)
+				}
+			}
+			// Storing takes until here, retries included; only the commit
+			// is left out.
+			_, err = tx.Exec(ctx,
+				`UPDATE execution_jobs SET timings = jsonb_set(timings, '{result_store_ms}', to_jsonb($2::INTEGER)) WHERE job_id = $1`,
+				id, time.Since(start).Milliseconds(),
+			)
+			if err != nil {
+				return fmt.Errorf("postgres: store timings: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+func (r *pgJobRepo) Requeue(ctx context.Context, id uuid.UUID, revision int, reason string) error {
+	// The timeline trigger reads the reason from the transaction's
+	// sentinel.event_reason setting.
+	query := `UPDATE execution_jobs SET status = 'QUEUED', updated_at = $1 WHERE ` + runGuard(2, 3)
+	return r.retry.do(ctx, r.pool, func() error {
+		return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `SELECT set_config('sentinel.event_reason', $1, true)`, reason); err != nil {
+				return fmt.Errorf("postgres: requeue: %w", err)
+			}
+			tag, err := tx.Exec(ctx, query, time.Now().UTC(), id, revision)
+			if err != nil {
+				return fmt.Errorf("postgres: requeue: %w", err)
+			}
+			if tag.RowsAffected() == 0 {
+				return fmt.Errorf("postgres: requeue: %w", missedGuard(ctx, tx, id))
+			}
+			return nil
+		})
+	})
 }
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig sizes the connection pool and says how long to wait for and
+// keep connections. Zero values keep pgx's defaults.
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	ConnectTimeout    time.Duration
+	HealthCheckPeriod time.Duration
+	MaxConnLifetime   time.Duration
+	Retry             RetryPolicy
+}
+
+// NewPool connects to url and pings the server, retrying under
+// cfg.Retry. Unless url sets target_session_attrs, connections are only
+// kept to a server that accepts writes, so a pool pointed at a failed-over
+// address never settles on the demoted primary; a url listing several
+// hosts then finds the new primary among them.
+func NewPool(ctx context.Context, url string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: parse database url: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolConfig.MinConns = cfg.MinConns
+	}
+	if cfg.ConnectTimeout > 0 {
+		poolConfig.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if !strings.Contains(url, "target_session_attrs") {
+		poolConfig.ConnConfig.ValidateConnect = pgconn.ValidateConnectTargetSessionAttrsReadWrite
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: create pool: %w", err)
+	}
+	if err := cfg.Retry.do(ctx, pool, func() error { return pool.Ping(ctx) }); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("postgres: ping: %w", err)
+	}
+	return pool, nil
+}
+
+// RetryPolicy retries database operations that failed because the server
+// went away, as it does during a failover, with exponential backoff. The
+// zero policy tries once.
+type RetryPolicy struct {
+	// Attempts is the most times an operation is tried.
+	Attempts int
+	// Backoff is the wait before the first retry; it doubles after each
+	// one, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// do runs op until it succeeds, fails with an error a retry cannot help,
+// or has been tried Attempts times. An error from a server that no longer
+// accepts writes means the pool holds connections to a demoted primary;
+// they are all dropped so that the retry connects afresh. op must be safe
+// to run again after a failure whose outcome is unknown.
+func (p RetryPolicy) do(ctx context.Context, pool *pgxpool.Pool, op func() error) error {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= p.Attempts || !transient(err) || ctx.Err() != nil {
+			return err
+		}
+		if demoted(err) && pool != nil {
+			pool.Reset()
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// transient reports whether err came from losing or failing to reach the
+// server, rather than from the statement itself.
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case strings.HasPrefix(pgErr.Code, "08"): // connection_exception
+			return true
+		case pgErr.Code == "57P01", // admin_shutdown
+			pgErr.Code == "57P02", // crash_shutdown
+			pgErr.Code == "57P03": // cannot_connect_now
+			return true
+		}
+		return demoted(err)
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return pgconn.SafeToRetry(err) ||
+		errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// demoted reports whether err says the server is read-only, as a primary
+// is once it has been demoted to a standby.
+func demoted(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "25006" // read_only_sql_transaction
+}
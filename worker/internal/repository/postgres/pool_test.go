@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"eof", fmt.Errorf("postgres: update status: %w", io.ErrUnexpectedEOF), true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"read only", fmt.Errorf("postgres: set result: %w", &pgconn.PgError{Code: "25006"}), true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"canceled", context.Canceled, false},
+		{"other", errors.New("job not found"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transient(tt.err); got != tt.want {
+				t.Errorf("transient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{Attempts: 4, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	ctx := context.Background()
+
+	t.Run("recovers", func(t *testing.T) {
+		calls := 0
+		err := policy.do(ctx, nil, func() error {
+			calls++
+			if calls < 3 {
+				return &pgconn.PgError{Code: "57P01"}
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Fatalf("err = %v after %d calls, want success after 3", err, calls)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		calls := 0
+		err := policy.do(ctx, nil, func() error {
+			calls++
+			return io.EOF
+		})
+		if !errors.Is(err, io.EOF) || calls != 4 {
+			t.Fatalf("err = %v after %d calls, want EOF after 4", err, calls)
+		}
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		calls := 0
+		err := policy.do(ctx, nil, func() error {
+			calls++
+			return &pgconn.PgError{Code: "23505"}
+		})
+		if err == nil || calls != 1 {
+			t.Fatalf("err = %v after %d calls, want failure after 1", err, calls)
+		}
+	})
+
+	t.Run("zero policy tries once", func(t *testing.T) {
+		calls := 0
+		_ = RetryPolicy{}.do(ctx, nil, func() error {
+			calls++
+			return io.EOF
+		})
+		if calls != 1 {
+			t.Fatalf("calls = %d, want 1", calls)
+		}
+	})
+}
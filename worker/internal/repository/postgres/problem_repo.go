@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.ProblemRepository = (*pgProblemRepo)(nil)
+
+type pgProblemRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresProblemRepository creates a PostgreSQL-backed problem repository for the worker.
+func NewPostgresProblemRepository(pool *pgxpool.Pool) repository.ProblemRepository {
+	return &pgProblemRepo{pool: pool}
+}
+
+// GetTestData reads the problem's current version and its cases in one
+// snapshot so a concurrent test-data update can't mix versions.
+func (r *pgProblemRepo) GetTestData(ctx context.Context, problemID string) (*domain.TestData, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("postgres: begin test data tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	data := &domain.TestData{ProblemID: problemID}
+	err = tx.QueryRow(ctx, `
+		SELECT test_data_version, termination_strategy
+		FROM problems
+		WHERE problem_id = $1`, problemID,
+	).Scan(&data.Version, &data.Strategy)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("postgres: problem not found: %s", problemID)
+		}
+		return nil, fmt.Errorf("postgres: get problem version: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT ordinal, input, expected_output, COALESCE(subtask, 0),
+		       COALESCE(time_limit_ms, 0), COALESCE(memory_limit_kb, 0), generator_seed
+		FROM problem_test_cases
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY ordinal`, problemID, data.Version)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get test cases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tc domain.TestCase
+		if err := rows.Scan(&tc.Ordinal, &tc.Input, &tc.ExpectedOutput, &tc.Subtask, &tc.TimeLimitMs, &tc.MemoryLimitKB, &tc.GeneratorSeed); err != nil {
+			return nil, fmt.Errorf("postgres: scan test case: %w", err)
+		}
+		data.Cases = append(data.Cases, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: iterate test cases: %w", err)
+	}
+
+	subtasks, err := tx.Query(ctx, `
+		SELECT ordinal, points, scoring
+		FROM problem_subtasks
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY ordinal`, problemID, data.Version)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get subtasks: %w", err)
+	}
+	defer subtasks.Close()
+
+	for subtasks.Next() {
+		var st domain.Subtask
+		if err := subtasks.Scan(&st.Ordinal, &st.Points, &st.Scoring); err != nil {
+			return nil, fmt.Errorf("postgres: scan subtask: %w", err)
+		}
+		data.Subtasks = append(data.Subtasks, st)
+	}
+	if err := subtasks.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: iterate subtasks: %w", err)
+	}
+
+	gen := &domain.Generator{}
+	err = tx.QueryRow(ctx, `
+		SELECT language, source_code, source_hash
+		FROM problem_generators
+		WHERE problem_id = $1 AND version = $2`, problemID, data.Version,
+	).Scan(&gen.Language, &gen.SourceCode, &gen.Hash)
+	switch {
+	case err == nil:
+		data.Generator = gen
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("postgres: get generator: %w", err)
+	}
+
+	cmp := &domain.Comparator{}
+	err = tx.QueryRow(ctx, `
+		SELECT wasm, wasm_hash
+		FROM problem_comparators
+		WHERE problem_id = $1 AND version = $2`, problemID, data.Version,
+	).Scan(&cmp.Wasm, &cmp.Hash)
+	switch {
+	case err == nil:
+		data.Comparator = cmp
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("postgres: get comparator: %w", err)
+	}
+
+	inter := &domain.Interactor{}
+	err = tx.QueryRow(ctx, `
+		SELECT language, source_code
+		FROM problem_interactors
+		WHERE problem_id = $1 AND version = $2`, problemID, data.Version,
+	).Scan(&inter.Language, &inter.SourceCode)
+	switch {
+	case err == nil:
+		data.Interactor = inter
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("postgres: get interactor: %w", err)
+	}
+
+	patterns, err := tx.Query(ctx, `
+		SELECT pattern, repeats, message
+		FROM problem_kill_patterns
+		WHERE problem_id = $1 AND version = $2
+		ORDER BY ordinal`, problemID, data.Version)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: get kill patterns: %w", err)
+	}
+	defer patterns.Close()
+
+	for patterns.Next() {
+		var (
+			pattern string
+			p       domain.KillPattern
+		)
+		if err := patterns.Scan(&pattern, &p.Repeats, &p.Message); err != nil {
+			return nil, fmt.Errorf("postgres: scan kill pattern: %w", err)
+		}
+		// Validated by the API on upload.
+		if p.Pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("postgres: kill pattern %q: %w", pattern, err)
+		}
+		data.KillPatterns = append(data.KillPatterns, p)
+	}
+	if err := patterns.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: iterate kill patterns: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.RuntimeRepository = (*pgRuntimeRepo)(nil)
+
+type pgRuntimeRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRuntimeRepository creates a PostgreSQL-backed runtime repository for the worker.
+func NewPostgresRuntimeRepository(pool *pgxpool.Pool) repository.RuntimeRepository {
+	return &pgRuntimeRepo{pool: pool}
+}
+
+// GetRuntime reads a runtime registered through the API.
+func (r *pgRuntimeRepo) GetRuntime(ctx context.Context, name domain.Language) (*domain.Runtime, bool, error) {
+	rt := &domain.Runtime{}
+	var mounts, env []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT name, version, source_file, nsjail_config, compile_cmd, run_cmd, mounts, env
+		FROM runtimes
+		WHERE name = $1`, name,
+	).Scan(&rt.Name, &rt.Version, &rt.SourceFile, &rt.NsjailConfig, &rt.Compile, &rt.Run, &mounts, &env)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("postgres: get runtime: %w", err)
+	}
+	if err := json.Unmarshal(mounts, &rt.Mounts); err != nil {
+		return nil, false, fmt.Errorf("postgres: decode runtime mounts: %w", err)
+	}
+	if err := json.Unmarshal(env, &rt.Env); err != nil {
+		return nil, false, fmt.Errorf("postgres: decode runtime env: %w", err)
+	}
+	return rt, true, nil
+}
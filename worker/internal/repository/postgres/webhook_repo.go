@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.WebhookRepository = (*pgWebhookRepo)(nil)
+
+type pgWebhookRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresWebhookRepository creates a PostgreSQL-backed webhook repository for the worker.
+func NewPostgresWebhookRepository(pool *pgxpool.Pool) repository.WebhookRepository {
+	return &pgWebhookRepo{pool: pool}
+}
+
+// GetDLQWebhook reads a tenant's dead-letter webhook set through the API.
+func (r *pgWebhookRepo) GetDLQWebhook(ctx context.Context, tenantID string) (*domain.DLQWebhook, bool, error) {
+	hook := &domain.DLQWebhook{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT url, secret
+		FROM tenant_dlq_webhooks
+		WHERE tenant_id = $1`, tenantID,
+	).Scan(&hook.URL, &hook.Secret)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("postgres: get dlq webhook: %w", err)
+	}
+	return hook, true, nil
+}
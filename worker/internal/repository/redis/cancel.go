@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.CancelWatcher = (*redisCancelWatcher)(nil)
+
+const (
+	// cancelChannel and cancelKeyPrefix must match what the API publishes
+	// cancellation requests on and marks cancelled jobs with.
+	cancelChannel   = "sentinel:cancel"
+	cancelKeyPrefix = "sentinel:cancel:"
+)
+
+type redisCancelWatcher struct {
+	client *goredis.Client
+
+	mu      sync.Mutex
+	watches map[uuid.UUID]*cancelWatch
+}
+
+type cancelWatch struct {
+	cancel context.CancelCauseFunc
+}
+
+// NewRedisCancelWatcher creates a watcher listening for cancellation
+// requests on a Redis channel until ctx is done. A request published before
+// its job was watched is found by the key the API sets alongside it.
+func NewRedisCancelWatcher(ctx context.Context, client *goredis.Client) repository.CancelWatcher {
+	w := &redisCancelWatcher{
+		client:  client,
+		watches: make(map[uuid.UUID]*cancelWatch),
+	}
+	sub := client.Subscribe(ctx, cancelChannel)
+	go w.listen(ctx, sub)
+	return w
+}
+
+// listen cancels watched jobs as requests arrive. The subscription
+// reconnects by itself; requests published while it is down are missed.
+func (w *redisCancelWatcher) listen(ctx context.Context, sub *goredis.PubSub) {
+	defer sub.Close()
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			id, err := uuid.Parse(msg.Payload)
+			if err != nil {
+				continue
+			}
+			w.mu.Lock()
+			if watch, ok := w.watches[id]; ok {
+				watch.cancel(domain.ErrJobCancelled)
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+func (w *redisCancelWatcher) Watch(ctx context.Context, jobID uuid.UUID) (context.Context, func()) {
+	watched, cancel := context.WithCancelCause(ctx)
+	watch := &cancelWatch{cancel: cancel}
+	w.mu.Lock()
+	w.watches[jobID] = watch
+	w.mu.Unlock()
+
+	// Checked after registering, so a request is either seen here or
+	// delivered to listen. A failed check only loses the early request.
+	if n, err := w.client.Exists(ctx, cancelKeyPrefix+jobID.String()).Result(); err == nil && n > 0 {
+		cancel(domain.ErrJobCancelled)
+	}
+
+	return watched, func() {
+		w.mu.Lock()
+		if w.watches[jobID] == watch {
+			delete(w.watches, jobID)
+		}
+		w.mu.Unlock()
+		cancel(nil)
+	}
+}
@@ -42,3 +42,11 @@ func (r *redisIdempotency) ReleaseLock(ctx context.Context, jobID uuid.UUID) err
 	key := lockKeyPrefix + jobID.String()
 	return r.client.Expire(ctx, key, lockTTL).Err()
 }
+
+// Unlock deletes the lock key.
+func (r *redisIdempotency) Unlock(ctx context.Context, jobID uuid.UUID) error {
+	if err := r.client.Del(ctx, lockKeyPrefix+jobID.String()).Err(); err != nil {
+		return fmt.Errorf("redis: unlock: %w", err)
+	}
+	return nil
+}
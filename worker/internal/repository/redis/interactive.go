@@ -0,0 +1,148 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.InteractiveIO = (*redisInteractiveIO)(nil)
+
+const (
+	// interactiveKeyPrefix must match the prefix the API writes stdin to and
+	// reads output from.
+	interactiveKeyPrefix = "sentinel:interactive:"
+
+	// interactiveKeyTTL and interactiveMaxEntries match the API's stdin
+	// stream.
+	interactiveKeyTTL     = time.Hour
+	interactiveMaxEntries = 1000
+
+	// interactiveMaxOutput caps what one run publishes; its stored stdout
+	// has a limit of its own.
+	interactiveMaxOutput = 256 << 10
+
+	// interactivePollBlock bounds each wait for stdin, so a closed reader
+	// stops within it.
+	interactivePollBlock = time.Second
+)
+
+type redisInteractiveIO struct {
+	client *goredis.Client
+}
+
+// NewRedisInteractiveIO creates interactive job I/O over the Redis streams
+// the API's WebSocket handler reads and writes.
+func NewRedisInteractiveIO(client *goredis.Client) repository.InteractiveIO {
+	return &redisInteractiveIO{client: client}
+}
+
+func interactiveKey(id uuid.UUID, stream string) string {
+	return interactiveKeyPrefix + id.String() + ":" + stream
+}
+
+func (r *redisInteractiveIO) Stdin(ctx context.Context, jobID uuid.UUID) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	return &stdinStream{
+		client: r.client,
+		key:    interactiveKey(jobID, "stdin"),
+		cursor: "0",
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (r *redisInteractiveIO) Output(ctx context.Context, jobID uuid.UUID) io.Writer {
+	return &outputStream{
+		client:    r.client,
+		key:       interactiveKey(jobID, "stdout"),
+		ctx:       ctx,
+		remaining: interactiveMaxOutput,
+	}
+}
+
+// stdinStream reads the stdin stream from its start, so input sent before
+// the job ran is not lost.
+type stdinStream struct {
+	client *goredis.Client
+	key    string
+	cursor string
+	buf    []byte
+	eof    bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *stdinStream) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.eof || s.ctx.Err() != nil {
+			return 0, io.EOF
+		}
+		streams, err := s.client.XRead(s.ctx, &goredis.XReadArgs{
+			Streams: []string{s.key, s.cursor},
+			Count:   100,
+			Block:   interactivePollBlock,
+		}).Result()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				s.cursor = msg.ID
+				if _, ok := msg.Values["eof"]; ok {
+					s.eof = true
+					break
+				}
+				if data, ok := msg.Values["data"].(string); ok {
+					s.buf = append(s.buf, data...)
+				}
+			}
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+func (s *stdinStream) Close() error {
+	s.cancel()
+	return nil
+}
+
+// outputStream appends each write to the stdout stream, dropping output
+// past interactiveMaxOutput and any Redis errors.
+type outputStream struct {
+	client    *goredis.Client
+	key       string
+	ctx       context.Context
+	remaining int
+}
+
+func (o *outputStream) Write(p []byte) (int, error) {
+	data := p
+	if len(data) > o.remaining {
+		data = data[:o.remaining]
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+	o.remaining -= len(data)
+	pipe := o.client.TxPipeline()
+	pipe.XAdd(o.ctx, &goredis.XAddArgs{Stream: o.key, MaxLen: interactiveMaxEntries, Values: map[string]interface{}{"data": string(data)}})
+	pipe.Expire(o.ctx, o.key, interactiveKeyTTL)
+	_, _ = pipe.Exec(o.ctx)
+	return len(p), nil
+}
@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.QueuePauses = (*redisQueuePauses)(nil)
+
+// queuePausesKey must match the hash the API records paused languages in.
+const queuePausesKey = "sentinel:queue:paused"
+
+type redisQueuePauses struct {
+	client *goredis.Client
+}
+
+// NewRedisQueuePauses creates a reader of the languages paused through the
+// API.
+func NewRedisQueuePauses(client *goredis.Client) repository.QueuePauses {
+	return &redisQueuePauses{client: client}
+}
+
+func (r *redisQueuePauses) Paused(ctx context.Context, lang domain.Language) (bool, error) {
+	paused, err := r.client.HExists(ctx, queuePausesKey, string(lang)).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis: check pause of %s: %w", lang, err)
+	}
+	return paused, nil
+}
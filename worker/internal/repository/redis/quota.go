@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+var _ repository.QuotaTracker = (*redisQuotaTracker)(nil)
+
+const (
+	// quotaKeyPrefix must match the prefix the API reads usage from.
+	quotaKeyPrefix = "sentinel:quota:"
+
+	// quotaKeyTTL keeps a day's counter around a little past its window.
+	quotaKeyTTL = 48 * time.Hour
+)
+
+type redisQuotaTracker struct {
+	client *goredis.Client
+}
+
+// NewRedisQuotaTracker creates a Redis-backed quota tracker using one
+// millisecond counter per tenant per UTC day.
+func NewRedisQuotaTracker(client *goredis.Client) repository.QuotaTracker {
+	return &redisQuotaTracker{client: client}
+}
+
+// AddUsage increments the tenant's counter for the current UTC day.
+func (r *redisQuotaTracker) AddUsage(ctx context.Context, tenantID string, used time.Duration) error {
+	key := quotaKey(tenantID, time.Now())
+	pipe := r.client.TxPipeline()
+	pipe.IncrBy(ctx, key, used.Milliseconds())
+	pipe.Expire(ctx, key, quotaKeyTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis: add quota usage: %w", err)
+	}
+	return nil
+}
+
+func quotaKey(tenantID string, t time.Time) string {
+	return quotaKeyPrefix + tenantID + ":" + t.UTC().Format("20060102")
+}
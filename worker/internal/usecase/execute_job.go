@@ -2,11 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/judge"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
 	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
 	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
 )
@@ -16,7 +22,38 @@ type ExecuteJobUsecase struct {
 	repo       repository.JobRepository
 	idempotent repository.IdempotencyStore
 	executor   repository.Executor
+	languages  *language.Registry
 	logger     *zap.Logger
+
+	// quota is optional; when set, execution time is charged to the job's tenant.
+	quota repository.QuotaTracker
+
+	// problems and judge handle jobs submitted against a problem's test data.
+	problems repository.ProblemRepository
+	judge    *judge.Judge
+
+	// metrics records executions; Prometheus unless SetMetrics changes it.
+	metrics metrics.Metrics
+	// tiers labels the per-tier metrics; a nil map reports DefaultTier.
+	tiers TenantTiers
+
+	// maxPids caps a job's pids_limit; zero leaves it uncapped.
+	maxPids int
+
+	// interactive is optional; when set, interactive jobs stream stdin and
+	// stdout through it.
+	interactive repository.InteractiveIO
+
+	// cancels is optional; when set, jobs stop running once their
+	// cancellation is requested.
+	cancels repository.CancelWatcher
+
+	// outputs checks the stdout of jobs submitted with an expected output.
+	outputs *judge.Comparator
+
+	// preemptions stop the runs in progress, by job, for Preempt.
+	mu          sync.Mutex
+	preemptions map[uuid.UUID]context.CancelCauseFunc
 }
 
 // NewExecuteJobUsecase creates a new ExecuteJobUsecase.
@@ -24,16 +61,56 @@ func NewExecuteJobUsecase(
 	repo repository.JobRepository,
 	idempotent repository.IdempotencyStore,
 	exec repository.Executor,
+	languages *language.Registry,
 	logger *zap.Logger,
 ) *ExecuteJobUsecase {
 	return &ExecuteJobUsecase{
 		repo:       repo,
 		idempotent: idempotent,
 		executor:   exec,
+		languages:  languages,
 		logger:     logger,
+		metrics:    metrics.Prometheus{},
+		outputs:    judge.NewComparator(judge.DefaultTolerance),
+
+		preemptions: make(map[uuid.UUID]context.CancelCauseFunc),
 	}
 }
 
+// SetMetrics records executions in m instead of the Prometheus collectors.
+func (uc *ExecuteJobUsecase) SetMetrics(m metrics.Metrics) {
+	uc.metrics = m
+}
+
+// SetMaxPids caps the process limit jobs may ask for. Larger requests run
+// with the cap rather than failing.
+func (uc *ExecuteJobUsecase) SetMaxPids(n int) {
+	uc.maxPids = n
+}
+
+// SetQuotaTracker enables charging execution time to per-tenant quotas.
+func (uc *ExecuteJobUsecase) SetQuotaTracker(quota repository.QuotaTracker) {
+	uc.quota = quota
+}
+
+// SetInteractive enables streaming the stdin and stdout of interactive
+// jobs. Without it they run with only their submitted stdin.
+func (uc *ExecuteJobUsecase) SetInteractive(interactive repository.InteractiveIO) {
+	uc.interactive = interactive
+}
+
+// SetCancelWatcher enables cancelling running jobs. Without it a requested
+// cancellation takes effect only for jobs that have not started.
+func (uc *ExecuteJobUsecase) SetCancelWatcher(cancels repository.CancelWatcher) {
+	uc.cancels = cancels
+}
+
+// SetJudge enables judging of problem submissions against stored test data.
+func (uc *ExecuteJobUsecase) SetJudge(problems repository.ProblemRepository, j *judge.Judge) {
+	uc.problems = problems
+	uc.judge = j
+}
+
 // Execute processes a single job: idempotency check → status update → sandbox run → store result.
 // Returns (isDuplicate, error).
 func (uc *ExecuteJobUsecase) Execute(ctx context.Context, job *domain.Job) (bool, error) {
@@ -41,70 +118,310 @@ func (uc *ExecuteJobUsecase) Execute(ctx context.Context, job *domain.Job) (bool
 	start := time.Now()
 
 	// Step 1: Idempotency check
-	acquired, err := uc.idempotent.AcquireLock(ctx, job.JobID)
+	acquired, err := uc.idempotent.AcquireLock(ctx, job.LockID())
 	if err != nil {
 		uc.logger.Error("Failed to acquire idempotency lock", zap.Error(err), zap.String("job_id", job.JobID.String()))
-		metrics.ExecutionsTotal.WithLabelValues(lang, "error").Inc()
-		return false, err
+		uc.observe(job, "error", nil)
+		return false, &domain.JobFailure{Class: domain.FailureLock, Err: err}
 	}
 	if !acquired {
 		uc.logger.Info("Duplicate message detected, skipping", zap.String("job_id", job.JobID.String()))
 		return true, nil
 	}
 
-	// Step 2: Update status to COMPILING (C++) or RUNNING (Python)
+	// Watch for cancellation before the job is visibly started, so a
+	// request made once the API sees it running is not missed. Only the run
+	// stops; the result is still stored with ctx.
+	runCtx := ctx
+	if uc.cancels != nil {
+		var stop func()
+		runCtx, stop = uc.cancels.Watch(ctx, job.JobID)
+		defer stop()
+	}
+	runCtx, unregister := uc.preemptible(runCtx, job.JobID)
+	defer unregister()
+
+	// Step 2: Update status to COMPILING (compiled languages) or RUNNING (interpreted)
 	var initialStatus domain.ExecutionStatus
-	if job.Language == domain.LangCpp {
+	if uc.languages.IsCompiled(job.Language) {
 		initialStatus = domain.StatusCompiling
 	} else {
 		initialStatus = domain.StatusRunning
 	}
-	if err := uc.repo.UpdateStatus(ctx, job.JobID, initialStatus); err != nil {
+	if err := uc.repo.UpdateStatus(ctx, job.JobID, job.JudgeRevision, initialStatus); err != nil {
+		if errors.Is(err, domain.ErrJobCancelled) {
+			uc.logger.Info("Job cancelled before it started, skipping", zap.String("job_id", job.JobID.String()))
+			_ = uc.idempotent.ReleaseLock(ctx, job.LockID())
+			uc.observe(job, string(domain.StatusCancelled), nil)
+			return false, nil
+		}
+		if errors.Is(err, domain.ErrRunSuperseded) {
+			uc.logger.Info("Job already finished or rejudged, skipping",
+				zap.String("job_id", job.JobID.String()),
+				zap.Int("judge_revision", job.JudgeRevision),
+			)
+			_ = uc.idempotent.ReleaseLock(ctx, job.LockID())
+			return true, nil
+		}
 		uc.logger.Error("Failed to update job status", zap.Error(err), zap.String("job_id", job.JobID.String()))
-		metrics.ExecutionsTotal.WithLabelValues(lang, "error").Inc()
-		return false, err
+		uc.observe(job, "error", nil)
+		class := domain.FailureStatusUpdate
+		if errors.Is(err, domain.ErrJobNotFound) {
+			class = domain.FailureJobMissing
+		}
+		return false, &domain.JobFailure{Class: class, Err: err}
 	}
 
 	// Step 3: Execute in sandbox
 	req := &domain.ExecutionRequest{
-		JobID:         job.JobID,
-		Language:      job.Language,
-		SourceCode:    job.SourceCode,
-		Stdin:         job.Stdin,
-		TimeLimitMs:   job.TimeLimitMs,
-		MemoryLimitKB: job.MemoryLimitKB,
+		JobID:          job.JobID,
+		TenantID:       job.TenantID,
+		SandboxTier:    job.SandboxTier,
+		NetworkPolicy:  job.NetworkPolicy,
+		OutputFiles:    job.OutputFiles,
+		Language:       job.Language,
+		SourceCode:     job.SourceCode,
+		Stdin:          job.Stdin,
+		TimeLimitMs:    job.TimeLimitMs,
+		MemoryLimitKB:  job.MemoryLimitKB,
+		CPUTimeLimitMs: job.CPUTimeLimitMs,
+		PidsLimit:      job.PidsLimit,
+		CompilerFlags:  job.CompilerFlags,
+		Args:           job.Args,
+		Env:            job.Env,
+		Debug:          job.Debug,
 	}
 
-	result, err := uc.executor.Execute(ctx, req)
+	if uc.maxPids > 0 && req.PidsLimit > uc.maxPids {
+		req.PidsLimit = uc.maxPids
+	}
+
+	if job.Interactive && uc.interactive != nil {
+		stdin := uc.interactive.Stdin(runCtx, job.JobID)
+		defer stdin.Close()
+		req.StdinStream = stdin
+		req.LiveOutput = uc.interactive.Output(ctx, job.JobID)
+	}
+
+	runStart := time.Now()
+	result, err := uc.run(runCtx, job, req)
+	runTime := time.Since(runStart)
+	unregister()
+	if errors.Is(context.Cause(runCtx), domain.ErrJobPreempted) {
+		// Whatever the stopped run returned, the job runs again.
+		return false, uc.requeue(ctx, job)
+	}
+	if errors.Is(context.Cause(runCtx), domain.ErrJobCancelled) {
+		// Whatever the killed run returned, the job was cancelled.
+		result, err = cancelledResult(result), nil
+	}
 	if err != nil {
 		uc.logger.Error("Sandbox execution failed", zap.Error(err), zap.String("job_id", job.JobID.String()))
 		// Set status to INTERNAL_ERROR
-		_ = uc.repo.UpdateStatus(ctx, job.JobID, domain.StatusInternalError)
-		metrics.ExecutionsTotal.WithLabelValues(lang, string(domain.StatusInternalError)).Inc()
-		metrics.SandboxFailures.Inc()
-		return false, err
+		_ = uc.repo.UpdateStatus(ctx, job.JobID, job.JudgeRevision, domain.StatusInternalError)
+		uc.observe(job, string(domain.StatusInternalError), nil)
+		uc.metrics.SandboxFailure()
+		return false, &domain.JobFailure{Class: domain.FailureSandbox, Err: err}
 	}
 
-	// Step 4: Store result
-	if err := uc.repo.SetResult(ctx, job.JobID, result); err != nil {
+	uc.addTimings(job, result, start, runTime)
+
+	// Step 4: Store result. Another run may have stored the job's result
+	// first, for a message redelivered while this one ran; that result
+	// stands, and this run counts as the duplicate.
+	if err := uc.repo.SetResult(ctx, job.JobID, job.JudgeRevision, result); err != nil {
+		if errors.Is(err, domain.ErrRunSuperseded) || errors.Is(err, domain.ErrJobCancelled) {
+			uc.logger.Info("Job result already stored, discarding this run's",
+				zap.String("job_id", job.JobID.String()),
+				zap.String("status", string(result.Status)),
+			)
+			_ = uc.idempotent.ReleaseLock(ctx, job.LockID())
+			return true, nil
+		}
 		uc.logger.Error("Failed to store result", zap.Error(err), zap.String("job_id", job.JobID.String()))
-		metrics.ExecutionsTotal.WithLabelValues(lang, "error").Inc()
-		return false, err
+		uc.observe(job, "error", nil)
+		return false, &domain.JobFailure{Class: domain.FailureResultStore, Err: err}
 	}
 
-	// Step 5: Release idempotency lock (set TTL for eventual cleanup)
-	_ = uc.idempotent.ReleaseLock(ctx, job.JobID)
+	// Step 5: Charge the execution time to the tenant's daily quota.
+	uc.chargeQuota(ctx, job, result)
+
+	// Step 6: Release idempotency lock (set TTL for eventual cleanup)
+	_ = uc.idempotent.ReleaseLock(ctx, job.LockID())
 
-	elapsed := time.Since(start).Seconds()
-	metrics.ExecutionsTotal.WithLabelValues(lang, string(result.Status)).Inc()
-	metrics.ExecutionDuration.WithLabelValues(lang).Observe(elapsed)
+	elapsed := time.Since(start)
+	uc.observe(job, string(result.Status), result)
+	uc.metrics.ExecutionDuration(lang, elapsed)
 
 	uc.logger.Info("Job executed successfully",
 		zap.String("job_id", job.JobID.String()),
 		zap.String("status", string(result.Status)),
 		zap.Int("time_ms", result.TimeUsedMs),
-		zap.Float64("wall_seconds", elapsed),
+		zap.Float64("wall_seconds", elapsed.Seconds()),
 	)
 
 	return false, nil
 }
+
+// addTimings fills in the phases of result's timings the executor cannot
+// see: how long job waited in the queue before start, and, for executors
+// that do not break down their run, the whole run as run time. The
+// repository adds the time storing the result takes.
+func (uc *ExecuteJobUsecase) addTimings(job *domain.Job, result *domain.ExecutionResult, start time.Time, runTime time.Duration) {
+	queuedAt := job.QueuedAt
+	if queuedAt.IsZero() {
+		queuedAt = job.CreatedAt
+	}
+	if !queuedAt.IsZero() {
+		result.Timings.QueueWaitMs = max(int(start.Sub(queuedAt).Milliseconds()), 0)
+	}
+	if t := &result.Timings; t.WorkdirSetupMs == 0 && t.CompileMs == 0 && t.RunMs == 0 {
+		t.RunMs = int(runTime.Milliseconds())
+	}
+}
+
+// Preempt stops the run of job id, if one is in progress, reporting
+// whether it did. Execute then returns domain.ErrJobPreempted, having put
+// the job back to QUEUED, and the caller requeues its message.
+func (uc *ExecuteJobUsecase) Preempt(id uuid.UUID) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	stop, ok := uc.preemptions[id]
+	if ok {
+		stop(domain.ErrJobPreempted)
+		delete(uc.preemptions, id)
+	}
+	return ok
+}
+
+// preemptible returns ctx for a run of job id that Preempt can stop, and
+// a func ending that, which may be called more than once.
+func (uc *ExecuteJobUsecase) preemptible(ctx context.Context, id uuid.UUID) (context.Context, func()) {
+	ctx, stop := context.WithCancelCause(ctx)
+	uc.mu.Lock()
+	uc.preemptions[id] = stop
+	uc.mu.Unlock()
+	return ctx, func() {
+		uc.mu.Lock()
+		delete(uc.preemptions, id)
+		uc.mu.Unlock()
+		stop(nil)
+	}
+}
+
+// requeue makes a preempted job run again from the start: its lock is
+// removed, so the requeued message is not taken for a duplicate, and it is
+// put back to QUEUED. It returns domain.ErrJobPreempted for the caller to
+// requeue the message, or nil if the job was cancelled or finished by
+// another run meanwhile and the message is done with.
+func (uc *ExecuteJobUsecase) requeue(ctx context.Context, job *domain.Job) error {
+	uc.logger.Info("Job preempted, requeuing", zap.String("job_id", job.JobID.String()), zap.Int("priority", job.Priority))
+	if err := uc.idempotent.Unlock(ctx, job.LockID()); err != nil {
+		uc.logger.Error("Failed to unlock preempted job", zap.Error(err), zap.String("job_id", job.JobID.String()))
+		return &domain.JobFailure{Class: domain.FailureLock, Err: err}
+	}
+	err := uc.repo.Requeue(ctx, job.JobID, job.JudgeRevision, domain.EventReasonPreempted)
+	switch {
+	case errors.Is(err, domain.ErrRunSuperseded) || errors.Is(err, domain.ErrJobCancelled):
+		return nil
+	case err != nil:
+		// The next run moves the job on from the status it was left in.
+		uc.logger.Warn("Failed to requeue preempted job", zap.Error(err), zap.String("job_id", job.JobID.String()))
+	}
+	return domain.ErrJobPreempted
+}
+
+// Quarantine fails a job without running it, for a message the broker kept
+// redelivering. The caller dead-letters the message.
+func (uc *ExecuteJobUsecase) Quarantine(ctx context.Context, job *domain.Job, deliveries int) error {
+	result := &domain.ExecutionResult{
+		Status: domain.StatusInternalError,
+		Stderr: fmt.Sprintf("job quarantined after %d deliveries", deliveries),
+	}
+	result.Flag(domain.SecurityQuarantine, result.Stderr)
+	err := uc.repo.SetResult(ctx, job.JobID, job.JudgeRevision, result)
+	if errors.Is(err, domain.ErrRunSuperseded) || errors.Is(err, domain.ErrJobCancelled) {
+		return nil // the job has its result already
+	}
+	if err != nil {
+		return fmt.Errorf("store quarantine result: %w", err)
+	}
+	uc.observe(job, string(domain.StatusInternalError), nil)
+	return nil
+}
+
+// cancelledResult turns what a cancelled run produced, if anything, into
+// the job's CANCELLED result.
+func cancelledResult(partial *domain.ExecutionResult) *domain.ExecutionResult {
+	result := &domain.ExecutionResult{Stderr: "job cancelled"}
+	if partial != nil {
+		result.Stdout = partial.Stdout
+		result.StdoutTruncated = partial.StdoutTruncated
+		result.TimeUsedMs = partial.TimeUsedMs
+		result.CPUTimeUsedMs = partial.CPUTimeUsedMs
+		result.MemoryUsedKB = partial.MemoryUsedKB
+		result.SecurityEvents = partial.SecurityEvents
+		result.Timings = partial.Timings
+	}
+	result.Status = domain.StatusCancelled
+	return result
+}
+
+// chargeQuota adds the job's execution time to its tenant's quota counter.
+// Failures are logged only: the result is already stored and must not be retried.
+func (uc *ExecuteJobUsecase) chargeQuota(ctx context.Context, job *domain.Job, result *domain.ExecutionResult) {
+	if uc.quota == nil || result.TimeUsedMs <= 0 {
+		return
+	}
+	tenantID := job.TenantID
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	used := time.Duration(result.TimeUsedMs) * time.Millisecond
+	if err := uc.quota.AddUsage(ctx, tenantID, used); err != nil {
+		uc.logger.Warn("Failed to record quota usage",
+			zap.Error(err),
+			zap.String("job_id", job.JobID.String()),
+			zap.String("tenant_id", tenantID),
+		)
+	}
+}
+
+// checkOutput turns a clean run of a job with an expected output into
+// ACCEPTED or WRONG_ANSWER. Other statuses already say what went wrong.
+func (uc *ExecuteJobUsecase) checkOutput(job *domain.Job, result *domain.ExecutionResult) {
+	if result.Status != domain.StatusSuccess {
+		return
+	}
+	m := uc.outputs.CompareOutput(judge.CompareMode(job.CompareMode), *job.ExpectedOutput, result.Stdout)
+	if m == nil {
+		result.Status = domain.StatusAccepted
+		return
+	}
+	result.Status = domain.StatusWrongAnswer
+	uc.logger.Debug("Output differs from expected output",
+		zap.String("job_id", job.JobID.String()),
+		zap.String("mismatch", m.Error()),
+	)
+}
+
+// run executes the request directly, or judges it against the problem's
+// current test data when the job belongs to a problem.
+func (uc *ExecuteJobUsecase) run(ctx context.Context, job *domain.Job, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+	if job.ProblemID == "" {
+		result, err := uc.executor.Execute(ctx, req)
+		if err == nil && job.ExpectedOutput != nil {
+			uc.checkOutput(job, result)
+		}
+		return result, err
+	}
+	if uc.problems == nil || uc.judge == nil {
+		return nil, fmt.Errorf("job %s references problem %q but judging is not configured", job.JobID, job.ProblemID)
+	}
+
+	data, err := uc.problems.GetTestData(ctx, job.ProblemID)
+	if err != nil {
+		return nil, fmt.Errorf("load test data: %w", err)
+	}
+	return uc.judge.Run(ctx, req, data)
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+)
+
+// DefaultTier is reported for tenants without an assigned tier.
+const DefaultTier = "standard"
+
+var tierPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// TenantTiers maps tenant IDs to customer tiers for metrics. Tiers come from
+// configuration, so labels keyed by tier stay bounded however many tenants
+// submit jobs.
+type TenantTiers map[string]string
+
+// ParseTenantTiers parses a comma-separated list of tenant=tier pairs.
+func ParseTenantTiers(s string) (TenantTiers, error) {
+	tiers := make(TenantTiers)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tenant, tier, ok := strings.Cut(pair, "=")
+		tenant, tier = strings.TrimSpace(tenant), strings.TrimSpace(tier)
+		if !ok || tenant == "" || !tierPattern.MatchString(tier) {
+			return nil, fmt.Errorf("invalid tenant tier %q (want tenant=tier, tier of lowercase letters, digits, '_' or '-')", pair)
+		}
+		if _, dup := tiers[tenant]; dup {
+			return nil, fmt.Errorf("tenant %q assigned a tier twice", tenant)
+		}
+		tiers[tenant] = tier
+	}
+	return tiers, nil
+}
+
+// Tier returns the tenant's tier, or DefaultTier.
+func (t TenantTiers) Tier(tenantID string) string {
+	if tier, ok := t[tenantID]; ok {
+		return tier
+	}
+	return DefaultTier
+}
+
+// otherTenant labels per-tenant metrics of tenants without an assigned tier.
+const otherTenant = "other"
+
+// MetricTenant returns the tenant label of per-tenant metrics: the tenant's
+// ID if it has an assigned tier, otherTenant if not. Tenant IDs are sent by
+// clients, so only configured ones may become label values.
+func (t TenantTiers) MetricTenant(tenantID string) string {
+	if _, ok := t[tenantID]; ok {
+		return tenantID
+	}
+	return otherTenant
+}
+
+// SetTenantTiers assigns tenants to tiers for the per-tier metrics. Without
+// it every tenant is reported as DefaultTier.
+func (uc *ExecuteJobUsecase) SetTenantTiers(tiers TenantTiers) {
+	uc.tiers = tiers
+}
+
+// jobBackend names what ran the job: the judge for problem submissions, the
+// sandbox directly otherwise.
+func jobBackend(job *domain.Job) string {
+	if job.ProblemID != "" {
+		return "judge"
+	}
+	return "sandbox"
+}
+
+// observe records a finished job in the per-language and per-tier metrics.
+// result is nil when the job failed before producing one.
+func (uc *ExecuteJobUsecase) observe(job *domain.Job, status string, result *domain.ExecutionResult) {
+	lang := string(job.Language)
+	tenantID := job.TenantID
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	tier := uc.tiers.Tier(tenantID)
+
+	uc.metrics.Execution(lang, status)
+	uc.metrics.JobSeen(tier, lang, jobBackend(job))
+	uc.metrics.TierJob(tier, status)
+	if result != nil && result.TimeUsedMs > 0 {
+		uc.metrics.TierExecutionTime(tier, time.Duration(result.TimeUsedMs)*time.Millisecond)
+	}
+	if result != nil {
+		size := len(result.Stdout) + len(result.Stderr)
+		uc.metrics.ResultStored(tier, uc.tiers.MetricTenant(tenantID), size, result.StdoutTruncated)
+	}
+}
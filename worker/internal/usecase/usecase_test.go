@@ -3,19 +3,33 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/judge"
+	"github.com/Harsh-BH/Sentinel/worker/internal/language"
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
 	"github.com/Harsh-BH/Sentinel/worker/internal/repository/mock"
 	"github.com/Harsh-BH/Sentinel/worker/internal/usecase"
 )
 
+// registryPath is the language registry shipped with the repository.
+const registryPath = "../../../sandbox/languages.yaml"
+
 func newTestUsecase(repo *mock.JobRepository, idem *mock.IdempotencyStore, exec *mock.Executor) *usecase.ExecuteJobUsecase {
 	logger := zap.NewNop()
-	return usecase.NewExecuteJobUsecase(repo, idem, exec, logger)
+	languages, err := language.Load(registryPath)
+	if err != nil {
+		panic("load language registry: " + err.Error())
+	}
+	return usecase.NewExecuteJobUsecase(repo, idem, exec, languages, logger)
 }
 
 func newTestJob() *domain.Job {
@@ -113,6 +127,25 @@ func TestExecute_Success_Cpp(t *testing.T) {
 	}
 }
 
+// Test: Go jobs start in COMPILING like other compiled languages.
+func TestExecute_Success_Go(t *testing.T) {
+	repo := &mock.JobRepository{}
+	idem := &mock.IdempotencyStore{}
+	exec := &mock.Executor{}
+
+	uc := newTestUsecase(repo, idem, exec)
+	job := newTestJob()
+	job.Language = domain.LangGo
+	job.SourceCode = "package main\nfunc main() {}"
+
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.StatusUpdates[0].Status != domain.StatusCompiling {
+		t.Errorf("expected COMPILING status for Go, got %s", repo.StatusUpdates[0].Status)
+	}
+}
+
 // Test: duplicate message is detected and skipped.
 func TestExecute_Duplicate(t *testing.T) {
 	repo := &mock.JobRepository{}
@@ -163,6 +196,9 @@ func TestExecute_LockError(t *testing.T) {
 	if err.Error() != "redis connection refused" {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if class := domain.FailureClassOf(err); class != domain.FailureLock {
+		t.Errorf("failure class = %s, want %s", class, domain.FailureLock)
+	}
 }
 
 // Test: sandbox execution returns infrastructure error.
@@ -198,7 +234,7 @@ func TestExecute_SandboxFailure(t *testing.T) {
 // Test: UpdateStatus DB failure.
 func TestExecute_DBUpdateStatusError(t *testing.T) {
 	repo := &mock.JobRepository{
-		UpdateStatusFn: func(ctx context.Context, id uuid.UUID, status domain.ExecutionStatus) error {
+		UpdateStatusFn: func(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error {
 			return errors.New("connection refused")
 		},
 	}
@@ -212,12 +248,183 @@ func TestExecute_DBUpdateStatusError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error from DB failure")
 	}
+	if class := domain.FailureClassOf(err); class != domain.FailureStatusUpdate {
+		t.Errorf("expected class %s, got %s", domain.FailureStatusUpdate, class)
+	}
+}
+
+// Test: a message for a job Postgres does not have is classed as job_missing.
+func TestExecute_MissingJob(t *testing.T) {
+	repo := &mock.JobRepository{
+		UpdateStatusFn: func(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error {
+			return fmt.Errorf("postgres: %w: %s", domain.ErrJobNotFound, id)
+		},
+	}
+	exec := &mock.Executor{}
+	uc := newTestUsecase(repo, &mock.IdempotencyStore{}, exec)
+
+	_, err := uc.Execute(context.Background(), newTestJob())
+	if class := domain.FailureClassOf(err); class != domain.FailureJobMissing {
+		t.Fatalf("expected class %s, got %s (err %v)", domain.FailureJobMissing, class, err)
+	}
+	if len(exec.ExecuteCalls) != 0 {
+		t.Error("a missing job must not be run")
+	}
+}
+
+func TestExecute_CancelledBeforeStart(t *testing.T) {
+	repo := &mock.JobRepository{
+		UpdateStatusFn: func(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error {
+			return fmt.Errorf("postgres: %w: %s", domain.ErrJobCancelled, id)
+		},
+	}
+	idem := &mock.IdempotencyStore{}
+	exec := &mock.Executor{}
+	uc := newTestUsecase(repo, idem, exec)
+
+	isDup, err := uc.Execute(context.Background(), newTestJob())
+	if err != nil || isDup {
+		t.Fatalf("Execute = %v, %v; want the message acknowledged", isDup, err)
+	}
+	if len(exec.ExecuteCalls) != 0 || len(repo.Results) != 0 {
+		t.Error("a job cancelled while queued must not be run or given a result")
+	}
+	if len(idem.ReleaseCalls) != 1 {
+		t.Errorf("expected the lock released, got %d releases", len(idem.ReleaseCalls))
+	}
+}
+
+func TestExecute_RunSuperseded(t *testing.T) {
+	superseded := func(id uuid.UUID) error {
+		return fmt.Errorf("postgres: %w: %s", domain.ErrRunSuperseded, id)
+	}
+
+	// A message for a job that already has its result is not run again.
+	repo := &mock.JobRepository{
+		UpdateStatusFn: func(ctx context.Context, id uuid.UUID, revision int, status domain.ExecutionStatus) error {
+			return superseded(id)
+		},
+	}
+	idem := &mock.IdempotencyStore{}
+	exec := &mock.Executor{}
+	uc := newTestUsecase(repo, idem, exec)
+	job := newTestJob()
+	job.JudgeRevision = 2
+	isDup, err := uc.Execute(context.Background(), job)
+	if err != nil || !isDup {
+		t.Fatalf("Execute = %v, %v; want a duplicate", isDup, err)
+	}
+	if len(exec.ExecuteCalls) != 0 {
+		t.Error("a finished job must not be run again")
+	}
+	if got := repo.StatusUpdates[0].Revision; got != 2 {
+		t.Errorf("status update for revision %d, want the message's 2", got)
+	}
+
+	// A run whose result lost the race to another run's is discarded.
+	repo = &mock.JobRepository{
+		SetResultFn: func(ctx context.Context, id uuid.UUID, revision int, result *domain.ExecutionResult) error {
+			return superseded(id)
+		},
+	}
+	idem = &mock.IdempotencyStore{}
+	uc = newTestUsecase(repo, idem, &mock.Executor{})
+	isDup, err = uc.Execute(context.Background(), newTestJob())
+	if err != nil || !isDup {
+		t.Fatalf("Execute = %v, %v; want a duplicate", isDup, err)
+	}
+	if len(idem.ReleaseCalls) != 1 {
+		t.Errorf("expected the lock released, got %d releases", len(idem.ReleaseCalls))
+	}
+}
+
+func TestExecute_CancelledWhileRunning(t *testing.T) {
+	repo := &mock.JobRepository{}
+	cancels := &mock.CancelWatcher{}
+	job := newTestJob()
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			cancels.Cancel(req.JobID)
+			<-ctx.Done() // the sandbox kills the process group
+			return &domain.ExecutionResult{Status: domain.StatusRuntimeError, ExitCode: -1, Stdout: "partial", TimeUsedMs: 120}, nil
+		},
+	}
+	uc := newTestUsecase(repo, &mock.IdempotencyStore{}, exec)
+	uc.SetCancelWatcher(cancels)
+
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(repo.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(repo.Results))
+	}
+	got := repo.Results[0].Result
+	if got.Status != domain.StatusCancelled || got.Stdout != "partial" || got.TimeUsedMs != 120 {
+		t.Errorf("result = %+v, want CANCELLED keeping the partial output", got)
+	}
+
+	// A request made before the worker got to the job also stops it.
+	job = newTestJob()
+	cancels.Cancel(job.JobID)
+	exec.ExecuteFn = func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+		return nil, ctx.Err()
+	}
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if got := repo.Results[1].Result; got.Status != domain.StatusCancelled {
+		t.Errorf("result = %+v, want CANCELLED", got)
+	}
+}
+
+// Test: a preempted run stores no result; the job goes back to QUEUED with
+// its lock removed, so the requeued message runs it again.
+func TestExecute_PreemptedWhileRunning(t *testing.T) {
+	repo := &mock.JobRepository{}
+	idem := &mock.IdempotencyStore{}
+	job := newTestJob()
+	var uc *usecase.ExecuteJobUsecase
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if !uc.Preempt(req.JobID) {
+				t.Error("Preempt() = false for a running job")
+			}
+			<-ctx.Done()
+			return &domain.ExecutionResult{Status: domain.StatusRuntimeError, ExitCode: -1}, nil
+		},
+	}
+	uc = newTestUsecase(repo, idem, exec)
+
+	isDup, err := uc.Execute(context.Background(), job)
+	if !errors.Is(err, domain.ErrJobPreempted) || isDup {
+		t.Fatalf("Execute() = %v, %v; want domain.ErrJobPreempted", isDup, err)
+	}
+	if len(repo.Results) != 0 {
+		t.Errorf("expected no result, got %+v", repo.Results[0].Result)
+	}
+	if len(repo.Requeues) != 1 || repo.Requeues[0].Reason != domain.EventReasonPreempted {
+		t.Errorf("requeues = %+v, want one with reason %q", repo.Requeues, domain.EventReasonPreempted)
+	}
+	if len(idem.UnlockCalls) != 1 || idem.UnlockCalls[0] != job.LockID() {
+		t.Errorf("unlocked %v, want the job's lock", idem.UnlockCalls)
+	}
+	if uc.Preempt(job.JobID) {
+		t.Error("Preempt() = true after the run ended")
+	}
+
+	// A job cancelled while it was being preempted is done with.
+	repo.RequeueFn = func(ctx context.Context, id uuid.UUID, revision int, reason string) error {
+		return fmt.Errorf("postgres: requeue: %w: %s", domain.ErrJobCancelled, id)
+	}
+	if _, err := uc.Execute(context.Background(), newTestJob()); err != nil {
+		t.Errorf("Execute() of a job cancelled meanwhile = %v, want nil", err)
+	}
 }
 
 // Test: SetResult DB failure.
 func TestExecute_DBSetResultError(t *testing.T) {
 	repo := &mock.JobRepository{
-		SetResultFn: func(ctx context.Context, id uuid.UUID, result *domain.ExecutionResult) error {
+		SetResultFn: func(ctx context.Context, id uuid.UUID, revision int, result *domain.ExecutionResult) error {
 			return errors.New("disk full")
 		},
 	}
@@ -234,6 +441,9 @@ func TestExecute_DBSetResultError(t *testing.T) {
 	if err.Error() != "disk full" {
 		t.Errorf("unexpected error: %v", err)
 	}
+	if class := domain.FailureClassOf(err); class != domain.FailureResultStore {
+		t.Errorf("failure class = %s, want %s", class, domain.FailureResultStore)
+	}
 }
 
 // Test: executor receives correct request fields.
@@ -250,6 +460,8 @@ func TestExecute_CorrectRequestFields(t *testing.T) {
 		Stdin:         "input data",
 		TimeLimitMs:   3000,
 		MemoryLimitKB: 131072,
+		Args:          []string{"--mode", "fast"},
+		Env:           map[string]string{"TZ": "UTC"},
 	}
 
 	_, err := uc.Execute(context.Background(), job)
@@ -279,4 +491,343 @@ func TestExecute_CorrectRequestFields(t *testing.T) {
 	if req.MemoryLimitKB != job.MemoryLimitKB {
 		t.Errorf("memory limit mismatch")
 	}
+	if len(req.Args) != 2 || req.Args[0] != "--mode" || req.Args[1] != "fast" {
+		t.Errorf("args mismatch: %v", req.Args)
+	}
+	if req.Env["TZ"] != "UTC" {
+		t.Errorf("env mismatch: %v", req.Env)
+	}
+}
+
+// Test: a job's pids_limit is passed on, capped by SetMaxPids.
+func TestExecute_PidsLimitCapped(t *testing.T) {
+	for _, tt := range []struct {
+		pids, max, want int
+	}{
+		{pids: 0, max: 256, want: 0},
+		{pids: 128, max: 256, want: 128},
+		{pids: 1024, max: 256, want: 256},
+		{pids: 1024, max: 0, want: 1024},
+	} {
+		exec := &mock.Executor{}
+		uc := newTestUsecase(&mock.JobRepository{}, &mock.IdempotencyStore{}, exec)
+		uc.SetMaxPids(tt.max)
+		job := newTestJob()
+		job.PidsLimit = tt.pids
+
+		if _, err := uc.Execute(context.Background(), job); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := exec.ExecuteCalls[0].PidsLimit; got != tt.want {
+			t.Errorf("pids %d with cap %d: got %d, want %d", tt.pids, tt.max, got, tt.want)
+		}
+	}
+}
+
+// Test: interactive jobs stream through the InteractiveIO; others do not.
+func TestExecute_InteractiveStreams(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if req.StdinStream == nil {
+				return &domain.ExecutionResult{Status: domain.StatusSuccess}, nil
+			}
+			input, _ := io.ReadAll(req.StdinStream)
+			fmt.Fprintf(req.LiveOutput, "got %s", input)
+			return &domain.ExecutionResult{Status: domain.StatusSuccess}, nil
+		},
+	}
+	interactive := &mock.InteractiveIO{Input: make(map[uuid.UUID]string)}
+	uc := newTestUsecase(&mock.JobRepository{}, &mock.IdempotencyStore{}, exec)
+	uc.SetInteractive(interactive)
+
+	job := newTestJob()
+	job.Interactive = true
+	interactive.Input[job.JobID] = "7\n"
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := interactive.Published(job.JobID); got != "got 7\n" {
+		t.Errorf("published output %q, want %q", got, "got 7\n")
+	}
+	if !interactive.Closed(job.JobID) {
+		t.Error("stdin stream was not closed after the run")
+	}
+
+	plain := newTestJob()
+	if _, err := uc.Execute(context.Background(), plain); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req := exec.ExecuteCalls[1]; req.StdinStream != nil || req.LiveOutput != nil {
+		t.Error("non-interactive job was given interactive streams")
+	}
+}
+
+func TestExecute_ExpectedOutput(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if req.Stdin == "crash" {
+				return &domain.ExecutionResult{Status: domain.StatusRuntimeError, ExitCode: 1}, nil
+			}
+			return &domain.ExecutionResult{Status: domain.StatusSuccess, Stdout: "3 \n"}, nil
+		},
+	}
+	repo := &mock.JobRepository{}
+	uc := newTestUsecase(repo, &mock.IdempotencyStore{}, exec)
+
+	tests := []struct {
+		expected, mode, stdin string
+		want                  domain.ExecutionStatus
+	}{
+		{expected: "3", want: domain.StatusAccepted},
+		{expected: "3\n", mode: "trailing_whitespace", want: domain.StatusAccepted},
+		{expected: "3\n", mode: "exact", want: domain.StatusWrongAnswer},
+		{expected: "4", want: domain.StatusWrongAnswer},
+		{expected: "3", stdin: "crash", want: domain.StatusRuntimeError},
+	}
+	for i, tt := range tests {
+		job := newTestJob()
+		job.Stdin = tt.stdin
+		job.ExpectedOutput = &tt.expected
+		job.CompareMode = tt.mode
+		if _, err := uc.Execute(context.Background(), job); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := repo.Results[i].Result.Status; got != tt.want {
+			t.Errorf("expected %q (mode %q): got %s, want %s", tt.expected, tt.mode, got, tt.want)
+		}
+	}
+
+	if _, err := uc.Execute(context.Background(), newTestJob()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := repo.Results[len(tests)].Result.Status; got != domain.StatusSuccess {
+		t.Errorf("job without expected output: got %s, want SUCCESS", got)
+	}
+}
+
+// Test: execution time is charged to the job's tenant quota.
+func TestExecute_ChargesTenantQuota(t *testing.T) {
+	repo := &mock.JobRepository{}
+	idem := &mock.IdempotencyStore{}
+	exec := &mock.Executor{}
+	quota := &mock.QuotaTracker{}
+
+	uc := newTestUsecase(repo, idem, exec)
+	uc.SetQuotaTracker(quota)
+
+	job := newTestJob()
+	job.TenantID = "acme"
+
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := quota.Usage["acme"]; got != 42*time.Millisecond {
+		t.Errorf("expected 42ms charged to acme, got %v", got)
+	}
+
+	// Jobs without a tenant are charged to the default tenant.
+	if _, err := uc.Execute(context.Background(), newTestJob()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := quota.Usage[domain.DefaultTenantID]; got != 42*time.Millisecond {
+		t.Errorf("expected 42ms charged to default tenant, got %v", got)
+	}
+}
+
+// Test: the stored result carries the queue wait, and the executor's
+// phases, or the whole run as run time when it does not break it down.
+func TestExecute_RecordsTimings(t *testing.T) {
+	repo := &mock.JobRepository{}
+	idem := &mock.IdempotencyStore{}
+	phases := domain.Timings{WorkdirSetupMs: 3, CompileMs: 120, RunMs: 40}
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			if req.Language == domain.LangCpp {
+				return &domain.ExecutionResult{Status: domain.StatusSuccess, Timings: phases}, nil
+			}
+			time.Sleep(20 * time.Millisecond)
+			return &domain.ExecutionResult{Status: domain.StatusSuccess}, nil
+		},
+	}
+	uc := newTestUsecase(repo, idem, exec)
+
+	job := newTestJob()
+	job.Language = domain.LangCpp
+	job.CreatedAt = time.Now().Add(-time.Hour)
+	job.QueuedAt = time.Now().Add(-2 * time.Second)
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := repo.Results[0].Result.Timings
+	if got.QueueWaitMs < 2000 || got.QueueWaitMs > 3000 {
+		t.Errorf("queue wait = %dms, want about 2000ms since the message was published", got.QueueWaitMs)
+	}
+	got.QueueWaitMs = 0
+	if got != phases {
+		t.Errorf("timings = %+v, want the executor's %+v", got, phases)
+	}
+
+	// Without a broker timestamp the wait counts from creation.
+	job = newTestJob()
+	job.CreatedAt = time.Now().Add(-time.Second)
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = repo.Results[1].Result.Timings
+	if got.QueueWaitMs < 1000 || got.QueueWaitMs > 2000 {
+		t.Errorf("queue wait = %dms, want about 1000ms since creation", got.QueueWaitMs)
+	}
+	if got.RunMs < 20 || got.WorkdirSetupMs != 0 || got.CompileMs != 0 {
+		t.Errorf("timings = %+v, want the whole run of at least 20ms as run time", got)
+	}
+}
+
+// Test: problem submissions are judged against the problem's test data.
+func TestExecute_ProblemSubmissionIsJudged(t *testing.T) {
+	repo := &mock.JobRepository{}
+	idem := &mock.IdempotencyStore{}
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			return &domain.ExecutionResult{Status: domain.StatusSuccess, Stdout: req.Stdin + "\n"}, nil
+		},
+	}
+	problems := &mock.ProblemRepository{TestData: map[string]*domain.TestData{
+		"echo": {ProblemID: "echo", Version: 2, Cases: []domain.TestCase{
+			{Ordinal: 1, Input: "a", ExpectedOutput: "a"},
+			{Ordinal: 2, Input: "b", ExpectedOutput: "b"},
+		}},
+	}}
+
+	uc := newTestUsecase(repo, idem, exec)
+	uc.SetJudge(problems, judge.NewJudge(exec, judge.NewComparator(judge.DefaultTolerance), zap.NewNop()))
+
+	job := newTestJob()
+	job.ProblemID = "echo"
+
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.Results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(repo.Results))
+	}
+	result := repo.Results[0].Result
+	if result.Status != domain.StatusAccepted {
+		t.Errorf("expected ACCEPTED, got %s", result.Status)
+	}
+	if result.TestDataVersion != 2 {
+		t.Errorf("expected judged test data version 2, got %d", result.TestDataVersion)
+	}
+	if len(result.TestResults) != 2 {
+		t.Errorf("expected 2 case results, got %d", len(result.TestResults))
+	}
+}
+
+// Test: a rejudge (higher revision) is not mistaken for a duplicate delivery.
+func TestExecute_RejudgeUsesDistinctLock(t *testing.T) {
+	repo := &mock.JobRepository{}
+	idem := &mock.IdempotencyStore{}
+	uc := newTestUsecase(repo, idem, &mock.Executor{})
+
+	job := newTestJob()
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	job.JudgeRevision = 1
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(idem.AcquireCalls) != 2 {
+		t.Fatalf("expected 2 acquire calls, got %d", len(idem.AcquireCalls))
+	}
+	if idem.AcquireCalls[0] != job.JobID {
+		t.Error("expected the original run to lock on the job ID")
+	}
+	if idem.AcquireCalls[0] == idem.AcquireCalls[1] {
+		t.Error("expected the rejudge to use a different lock ID")
+	}
+}
+
+func TestParseTenantTiers(t *testing.T) {
+	tiers, err := usecase.ParseTenantTiers(" acme=enterprise, globex=pro ,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := tiers.Tier("acme"); got != "enterprise" {
+		t.Errorf("expected acme to be enterprise, got %q", got)
+	}
+	if got := tiers.Tier("initech"); got != usecase.DefaultTier {
+		t.Errorf("expected unlisted tenant in %q, got %q", usecase.DefaultTier, got)
+	}
+
+	for _, bad := range []string{"acme", "acme=", "=pro", "acme=Gold Plus", "acme=pro,acme=free"} {
+		if _, err := usecase.ParseTenantTiers(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestExecute_RecordsTierMetrics(t *testing.T) {
+	uc := newTestUsecase(&mock.JobRepository{}, &mock.IdempotencyStore{}, &mock.Executor{})
+	tiers, _ := usecase.ParseTenantTiers("acme=tiertest")
+	uc.SetTenantTiers(tiers)
+	rec := metrics.NewRecorder()
+	uc.SetMetrics(rec)
+
+	job := newTestJob()
+	job.TenantID = "acme"
+	if _, err := uc.Execute(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := rec.Value("TierJob", "tiertest", string(domain.StatusSuccess)); got != 1 {
+		t.Errorf("expected one tiertest job recorded, got %v", got)
+	}
+	if got := rec.Value("TierExecutionTime", "tiertest"); got != 0.042 {
+		t.Errorf("expected 0.042s charged to tiertest, got %v", got)
+	}
+	if got := rec.Value("JobSeen", "tiertest", string(job.Language), "sandbox"); got != 1 {
+		t.Errorf("expected job info series set to 1, got %v", got)
+	}
+	if got := rec.Count("ExecutionDuration", string(job.Language)); got != 1 {
+		t.Errorf("expected one execution duration recorded, got %d", got)
+	}
+}
+
+// Test: stored result sizes are recorded per tenant, with untiered tenants
+// pooled as "other".
+func TestExecute_RecordsResultSizeMetrics(t *testing.T) {
+	exec := &mock.Executor{
+		ExecuteFn: func(ctx context.Context, req *domain.ExecutionRequest) (*domain.ExecutionResult, error) {
+			return &domain.ExecutionResult{
+				Status:          domain.StatusSuccess,
+				Stdout:          strings.Repeat("x", 100),
+				Stderr:          "warn\n",
+				StdoutTruncated: req.TenantID == "sizetest",
+			}, nil
+		},
+	}
+	uc := newTestUsecase(&mock.JobRepository{}, &mock.IdempotencyStore{}, exec)
+	tiers, _ := usecase.ParseTenantTiers("sizetest=pro")
+	uc.SetTenantTiers(tiers)
+	rec := metrics.NewRecorder()
+	uc.SetMetrics(rec)
+
+	for _, tenant := range []string{"sizetest", "unlisted-tenant"} {
+		job := newTestJob()
+		job.TenantID = tenant
+		if _, err := uc.Execute(context.Background(), job); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := rec.Value("ResultStored", "pro", "sizetest", "true"); got != 105 {
+		t.Errorf("expected 105 result bytes for sizetest, got %v", got)
+	}
+	if got := rec.Count("ResultStored", "pro", "sizetest", "true"); got != 1 {
+		t.Errorf("expected one truncated sizetest result, got %d", got)
+	}
+	if got := rec.Count("ResultStored", usecase.DefaultTier, "other", "false"); got != 1 {
+		t.Errorf("expected the untiered tenant's result under other, got %d", got)
+	}
 }
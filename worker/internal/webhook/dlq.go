@@ -0,0 +1,130 @@
+// Package webhook notifies tenants' endpoints about their jobs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/metrics"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository"
+)
+
+// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the request
+// body, keyed with the tenant's webhook secret.
+const SignatureHeader = "X-Sentinel-Signature"
+
+// dlqAttempts is how often a delivery is tried before it is given up.
+const dlqAttempts = 3
+
+var _ repository.DeadLetterNotifier = (*DLQNotifier)(nil)
+
+// DLQNotifier posts dead-letter events to the webhook each tenant set
+// through the API. Deliveries run in the background and are retried on
+// network errors and 5xx or 429 responses; tenants without a webhook are
+// skipped. Delivery is best-effort: nothing is persisted across restarts.
+type DLQNotifier struct {
+	hooks   repository.WebhookRepository
+	client  *http.Client
+	logger  *zap.Logger
+	backoff time.Duration
+	wg      sync.WaitGroup
+}
+
+// NewDLQNotifier creates a notifier whose requests time out after timeout.
+func NewDLQNotifier(hooks repository.WebhookRepository, timeout time.Duration, logger *zap.Logger) *DLQNotifier {
+	return &DLQNotifier{
+		hooks:   hooks,
+		client:  &http.Client{Timeout: timeout},
+		logger:  logger,
+		backoff: time.Second,
+	}
+}
+
+// Notify delivers event in the background. It outlives ctx, so a shutdown
+// does not drop notifications already started; see Wait.
+func (n *DLQNotifier) Notify(ctx context.Context, event *domain.DeadLetterEvent) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.deliver(event)
+	}()
+}
+
+// Wait blocks until every started delivery has finished.
+func (n *DLQNotifier) Wait() {
+	n.wg.Wait()
+}
+
+func (n *DLQNotifier) deliver(event *domain.DeadLetterEvent) {
+	log := n.logger.With(zap.String("job_id", event.JobID.String()), zap.String("tenant_id", event.TenantID))
+
+	hook, ok, err := n.hooks.GetDLQWebhook(context.Background(), event.TenantID)
+	if err != nil {
+		log.Warn("Failed to look up DLQ webhook", zap.Error(err))
+		metrics.DLQWebhookDeliveries.WithLabelValues("failed").Inc()
+		return
+	}
+	if !ok {
+		return
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error("Failed to encode dead-letter event", zap.Error(err))
+		return
+	}
+	mac := hmac.New(sha256.New, []byte(hook.Secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(hook.URL, signature, body)
+		switch {
+		case err == nil:
+			metrics.DLQWebhookDeliveries.WithLabelValues("delivered").Inc()
+			return
+		case !retry:
+			log.Warn("DLQ webhook rejected the event", zap.Error(err))
+			metrics.DLQWebhookDeliveries.WithLabelValues("rejected").Inc()
+			return
+		case attempt == dlqAttempts:
+			log.Warn("DLQ webhook delivery failed", zap.Error(err), zap.Int("attempts", attempt))
+			metrics.DLQWebhookDeliveries.WithLabelValues("failed").Inc()
+			return
+		}
+		time.Sleep(n.backoff << (attempt - 1))
+	}
+}
+
+// post sends one attempt, reporting whether a failure is worth retrying.
+func (n *DLQNotifier) post(url, signature string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook responded %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+}
@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Harsh-BH/Sentinel/worker/internal/domain"
+	"github.com/Harsh-BH/Sentinel/worker/internal/repository/mock"
+)
+
+func TestDLQNotifier_SignsAndRetries(t *testing.T) {
+	const secret = "0123456789abcdef"
+	var calls atomic.Int32
+	var got domain.DeadLetterEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); r.Header.Get(SignatureHeader) != want {
+			t.Errorf("signature = %q, want %q", r.Header.Get(SignatureHeader), want)
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("decode event: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	hooks := &mock.WebhookRepository{Hooks: map[string]*domain.DLQWebhook{
+		"acme": {URL: srv.URL, Secret: secret},
+	}}
+	n := NewDLQNotifier(hooks, time.Second, zap.NewNop())
+	n.backoff = time.Millisecond
+
+	event := &domain.DeadLetterEvent{
+		Event:        domain.EventJobQuarantined,
+		JobID:        uuid.New(),
+		TenantID:     "acme",
+		Language:     domain.LangPython,
+		FailureClass: domain.FailureRedeliveryLimit,
+		Deliveries:   6,
+	}
+	n.Notify(context.Background(), event)
+	// A tenant without a webhook is skipped.
+	n.Notify(context.Background(), &domain.DeadLetterEvent{TenantID: "other"})
+	n.Wait()
+
+	if calls.Load() != 2 {
+		t.Fatalf("expected one retry after the 502, got %d calls", calls.Load())
+	}
+	if got.JobID != event.JobID || got.FailureClass != domain.FailureRedeliveryLimit || got.Event != domain.EventJobQuarantined {
+		t.Errorf("unexpected event delivered: %+v", got)
+	}
+}
+
+func TestDLQNotifier_DoesNotRetryRejections(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	hooks := &mock.WebhookRepository{Hooks: map[string]*domain.DLQWebhook{
+		"acme": {URL: srv.URL, Secret: "0123456789abcdef"},
+	}}
+	n := NewDLQNotifier(hooks, time.Second, zap.NewNop())
+	n.backoff = time.Millisecond
+	n.Notify(context.Background(), &domain.DeadLetterEvent{TenantID: "acme", FailureClass: domain.FailureSandbox})
+	n.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected a single attempt for a 410, got %d", calls.Load())
+	}
+}